@@ -0,0 +1,148 @@
+package notionapi
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// ActivityBlockCreated is a kind of ActivityEdit for a new block
+	ActivityBlockCreated = "block-created"
+	// ActivityBlockEdited is a kind of ActivityEdit for a changed block
+	ActivityBlockEdited = "block-changed"
+	// ActivityBlockDeleted is a kind of ActivityEdit for a deleted block
+	ActivityBlockDeleted = "block-deleted"
+	// ActivityPropertyChanged is a kind of ActivityEdit for a changed
+	// property of a row or a changed column of a database
+	ActivityPropertyChanged = "collection-property-changed"
+)
+
+// ActivityEdit is a single change that is part of an Activity
+type ActivityEdit struct {
+	// ActivityBlockCreated, ActivityBlockEdited, ActivityBlockDeleted,
+	// ActivityPropertyChanged or another kind sent by the server
+	Kind string
+	// id of the changed block, if known
+	BlockID string
+	// ids of users who made the change
+	AuthorIDs []string
+	// time of the change in milliseconds, 0 if not known
+	Timestamp int64
+}
+
+// Time returns the time of the change, zero time if not known
+func (e *ActivityEdit) Time() time.Time {
+	return notionTime(e.Timestamp)
+}
+
+// Activity is a group of changes in a page shown in page history, see
+// Client.GetPageActivity
+type Activity struct {
+	ID string
+	// type of activity as sent by the server e.g. "block-edited" or
+	// "commented"
+	Type   string
+	PageID string
+	// ids of users who did it, in order
+	ActorIDs []string
+	// users who did it, if the server sent their records
+	Actors []*User
+	Edits  []*ActivityEdit
+	// when the activity started and ended, in milliseconds
+	StartTime int64
+	EndTime   int64
+}
+
+// StartedOn returns the time the activity started
+func (a *Activity) StartedOn() time.Time {
+	return notionTime(a.StartTime)
+}
+
+// EndedOn returns the time the activity ended
+func (a *Activity) EndedOn() time.Time {
+	return notionTime(a.EndTime)
+}
+
+// BlockIDs returns ids of blocks changed in the activity, without
+// duplicates
+func (a *Activity) BlockIDs() []string {
+	var res []string
+	for _, e := range a.Edits {
+		if e.BlockID != "" && !containsString(res, e.BlockID) {
+			res = append(res, e.BlockID)
+		}
+	}
+	return res
+}
+
+// /api/v3/getActivityLog request
+type getActivityLogRequest struct {
+	SpaceID          string `json:"spaceId,omitempty"`
+	NavigableBlockID string `json:"navigableBlockId"`
+	Limit            int    `json:"limit"`
+}
+
+type getActivityLogResponse struct {
+	ActivityIDs []string          `json:"activityIds"`
+	RecordMap   activityRecordMap `json:"recordMap"`
+}
+
+// newActivity creates Activity from a record sent by the server
+func newActivity(a *activityRecord, idToUser map[string]*User) *Activity {
+	res := &Activity{
+		ID:     a.ID,
+		Type:   a.Type,
+		PageID: a.NavigableBlockID,
+	}
+	res.StartTime, _ = a.StartTime.Int64()
+	res.EndTime, _ = a.EndTime.Int64()
+	res.ActorIDs, res.Actors = a.actors(idToUser)
+	for _, e := range a.Edits {
+		edit := &ActivityEdit{
+			Kind:    e.Type,
+			BlockID: e.BlockID,
+		}
+		edit.Timestamp, _ = e.Timestamp.Int64()
+		for _, author := range e.Authors {
+			edit.AuthorIDs = append(edit.AuthorIDs, author.ID)
+		}
+		res.Edits = append(res.Edits, edit)
+	}
+	return res
+}
+
+// GetPageActivity returns up to limit most recent activities in a page
+// (what Notion shows in page history), newest first. Default limit is 20
+func (c *Client) GetPageActivity(pageID string, limit int) ([]*Activity, error) {
+	return c.GetPageActivityCtx(context.Background(), pageID, limit)
+}
+
+// GetPageActivityCtx is like GetPageActivity but can be canceled with ctx
+func (c *Client) GetPageActivityCtx(ctx context.Context, pageID string, limit int) ([]*Activity, error) {
+	id, err := ToDashID(pageID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	req := &getActivityLogRequest{
+		SpaceID:          c.knownSpaceID(),
+		NavigableBlockID: id,
+		Limit:            limit,
+	}
+	var rsp getActivityLogResponse
+	err = doNotionAPI(ctx, c, "/api/v3/getActivityLog", req, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	rm := &rsp.RecordMap
+	idToUser := rm.users()
+	var res []*Activity
+	for _, id := range rsp.ActivityIDs {
+		if a := rm.activity(id); a != nil {
+			res = append(res, newActivity(a, idToUser))
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,57 @@
+package notionapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const activityLogJSON = `{
+	"activityIds": ["a1", "missing"],
+	"recordMap": {
+		"activity": {
+			"a1": {
+				"role": "editor",
+				"value": {
+					"id": "a1",
+					"type": "block-edited",
+					"navigable_block_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+					"start_time": "1600000000000",
+					"end_time": "1600000120000",
+					"edits": [
+						{ "type": "block-created", "block_id": "b1", "timestamp": 1600000000000, "authors": [{ "id": "u1", "table": "notion_user" }] },
+						{ "type": "block-changed", "block_id": "b2", "timestamp": "1600000060000", "authors": [{ "id": "u1", "table": "notion_user" }] },
+						{ "type": "block-deleted", "block_id": "b1", "timestamp": 1600000120000, "authors": [{ "id": "u2", "table": "notion_user" }] }
+					]
+				}
+			}
+		},
+		"notion_user": {
+			"u2": { "role": "reader", "value": { "id": "u2", "given_name": "Jane" } }
+		}
+	}
+}`
+
+func TestGetPageActivity(t *testing.T) {
+	c, transport := newStaticClient(200, activityLogJSON)
+	_, err := c.GetPageActivity("not an id", 10)
+	assert.Error(t, err)
+
+	res, err := c.GetPageActivity("4c6a54c68b3e4ea2af9cfaabcc88d58d", 10)
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(transport.requests[0], `"navigableBlockId":"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d","limit":10`), transport.requests[0])
+	assert.Equal(t, 1, len(res))
+	a := res[0]
+	assert.Equal(t, "block-edited", a.Type)
+	assert.Equal(t, []string{"u1", "u2"}, a.ActorIDs)
+	assert.Equal(t, "Jane", a.Actors[0].FullName())
+	assert.Equal(t, int64(1600000120), a.EndedOn().Unix())
+	assert.Equal(t, 3, len(a.Edits))
+	assert.Equal(t, ActivityBlockCreated, a.Edits[0].Kind)
+	assert.Equal(t, ActivityBlockEdited, a.Edits[1].Kind)
+	assert.Equal(t, int64(1600000060), a.Edits[1].Time().Unix())
+	assert.Equal(t, ActivityBlockDeleted, a.Edits[2].Kind)
+	assert.Equal(t, []string{"u2"}, a.Edits[2].AuthorIDs)
+	assert.Equal(t, []string{"b1", "b2"}, a.BlockIDs())
+}
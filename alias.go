@@ -0,0 +1,47 @@
+package notionapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// setAliasTarget sets AliasTitle and AliasIcon of BlockAlias from the
+// target page, if we have it
+func setAliasTarget(block *Block, target *Block) {
+	if target == nil || target.Type != BlockPage {
+		return
+	}
+	if target.Title == "" && target.InlineContent == nil {
+		// not yet parsed
+		_ = parseProperties(target)
+		_ = parseFormat(target)
+	}
+	block.AliasTitle = target.Title
+	if target.FormatPage != nil {
+		block.AliasIcon = target.FormatPage.PageIcon
+	}
+}
+
+// ResolveAlias sets AliasTitle and AliasIcon of a link to a page
+// (see Block.AliasPageID) by getting the target page. DownloadPage
+// only sets them if the page was downloaded together with the link
+func (c *Client) ResolveAlias(block *Block) error {
+	return c.ResolveAliasCtx(context.Background(), block)
+}
+
+// ResolveAliasCtx is like ResolveAlias but can be canceled with ctx
+func (c *Client) ResolveAliasCtx(ctx context.Context, block *Block) error {
+	if block.AliasPageID == "" {
+		return fmt.Errorf("block %s is not a link to a page", block.ID)
+	}
+	rsp, err := c.GetRecordValuesCtx(ctx, []RecordRequest{{Table: TableBlock, ID: block.AliasPageID}})
+	if err != nil {
+		return err
+	}
+	target := rsp.Results[0].Block
+	if target == nil {
+		return fmt.Errorf("couldn't retrieve page with id %s: %w", block.AliasPageID, ErrNotFound)
+	}
+	setAliasTarget(block, target)
+	return nil
+}
@@ -0,0 +1,54 @@
+package notionapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAlias(t *testing.T) {
+	alias := &Block{
+		ID:        "alias",
+		Type:      BlockAlias,
+		Alive:     true,
+		FormatRaw: []byte(`{"alias_pointer":{"id":"target","table":"block","spaceId":"space"}}`),
+	}
+	target := &Block{
+		ID:         "target",
+		Type:       BlockPage,
+		Alive:      true,
+		ParentID:   "other",
+		Properties: map[string]interface{}{"title": []interface{}{[]interface{}{"Target"}}},
+		FormatRaw:  []byte(`{"page_icon":"🎯"}`),
+	}
+	root := &Block{ID: "root", Type: BlockPage, ContentIDs: []string{"alias", "target"}}
+	idToBlock := map[string]*Block{"root": root, "alias": alias, "target": target}
+	err := resolveBlocks(root, idToBlock, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "target", alias.AliasPageID)
+	assert.Equal(t, "space", alias.FormatAlias.AliasPointer.SpaceID)
+	assert.Equal(t, "Target", alias.AliasTitle)
+	assert.Equal(t, "🎯", alias.AliasIcon)
+	// the page is a child of another block so it's also a link
+	assert.Equal(t, "target", target.AliasPageID)
+
+	page := &Page{ID: "root", Root: root}
+	got := string(ToMarkdown(page, &MarkdownOptions{SkipSubPages: true}))
+	assert.Equal(t, 2, strings.Count(got, "[Target](https://www.notion.so/target)"))
+	got = string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<p class="page-link"><a href="https://www.notion.so/target">🎯 Target</a></p>`)
+}
+
+func TestResolveAlias(t *testing.T) {
+	c, _ := newStaticClient(200, toRecordMap(TableBlock, topLevelPagesJSON))
+	block := &Block{ID: "alias", Type: BlockAlias, AliasPageID: "300db9dc-27c8-4958-a08b-8d0c37f4cfe5"}
+	err := c.ResolveAlias(block)
+	assert.NoError(t, err)
+	assert.Equal(t, "Notes", block.AliasTitle)
+	assert.Equal(t, "📝", block.AliasIcon)
+
+	err = c.ResolveAlias(&Block{ID: "text", Type: BlockText})
+	assert.Error(t, err)
+}
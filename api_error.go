@@ -0,0 +1,105 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrNotFound is returned (wrapped) when a page or a record doesn't
+	// exist or we don't have access to it. Use IsNotFound to check
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized is returned (wrapped) when Client.AuthToken is not
+	// set and the page is not public or the server wants us to log in.
+	// Use IsUnauthorized to check
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrOffline is returned (wrapped) when Client.Offline is set and we
+	// would have to send a request over the network, e.g. for a page that
+	// is not in the cache of CachingClient
+	ErrOffline = errors.New("offline")
+)
+
+// APIError is returned when the server responds with an error
+type APIError struct {
+	// url of the endpoint e.g. https://www.notion.so/api/v3/loadPageChunk
+	Endpoint   string
+	StatusCode int
+	// values from the body of error response, if the server sent them
+	ErrorID string `json:"errorId"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	s := fmt.Sprintf("%s returned status code %d", e.Endpoint, e.StatusCode)
+	if e.Name != "" {
+		s += " " + e.Name
+	}
+	if e.Message != "" {
+		s += ": " + e.Message
+	}
+	return s
+}
+
+// newAPIError creates APIError from body of the error response
+func newAPIError(endpoint string, statusCode int, body []byte) *APIError {
+	res := &APIError{}
+	// the body is not always JSON
+	_ = json.Unmarshal(body, res)
+	res.Endpoint = endpoint
+	res.StatusCode = statusCode
+	return res
+}
+
+// TransportError is returned when we failed to send a request or get
+// a response e.g. because of network errors
+type TransportError struct {
+	Endpoint string
+	Err      error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("request to %s failed: %s", e.Endpoint, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// IsNotFound returns true if err says that the page or a record doesn't
+// exist or we don't have access to it
+func IsNotFound(err error) bool {
+	if errors.Is(err, ErrNotFound) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusNotFound
+	}
+	return false
+}
+
+// IsUnauthorized returns true if err says that Client.AuthToken is
+// missing, invalid or expired
+func IsUnauthorized(err error) bool {
+	if errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrNoAuthToken) || errors.Is(err, ErrUnauthorized) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden || apiErr.Name == "UnauthorizedError"
+	}
+	return false
+}
+
+// IsRateLimited returns true if err says that we sent too many requests
+func IsRateLimited(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.Name == "RateLimitedError"
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package notionapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	c, _ := newStaticClient(401, `{"errorId":"5a2b","name":"UnauthorizedError","message":"Token was invalid or expired."}`)
+	_, err := c.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, 401, apiErr.StatusCode)
+	assert.Equal(t, "UnauthorizedError", apiErr.Name)
+	assert.Equal(t, "5a2b", apiErr.ErrorID)
+	assert.Equal(t, "https://www.notion.so/api/v3/syncRecordValues", apiErr.Endpoint)
+	assert.Equal(t, "https://www.notion.so/api/v3/syncRecordValues returned status code 401 UnauthorizedError: Token was invalid or expired.", err.Error())
+	assert.True(t, IsUnauthorized(err))
+	assert.False(t, IsNotFound(err))
+	assert.False(t, IsRateLimited(err))
+
+	c, _ = newStaticClient(429, `rate limited`)
+	_, err = c.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	assert.True(t, IsRateLimited(err))
+
+	c, _ = newStaticClient(200, `{"results":[{"role":"none"}]}`)
+	_, err = c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.True(t, IsNotFound(err))
+	assert.True(t, IsNotFound(fmt.Errorf("wrapped: %w", &APIError{StatusCode: 404})))
+}
+
+func TestTransportError(t *testing.T) {
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: failingTransport{}},
+		RetryPolicy: &NoRetryPolicy,
+	}
+	_, err := c.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	var transportErr *TransportError
+	assert.True(t, errors.As(err, &transportErr))
+	assert.Equal(t, "https://www.notion.so/api/v3/syncRecordValues", transportErr.Endpoint)
+	var apiErr *APIError
+	assert.False(t, errors.As(err, &apiErr))
+}
@@ -0,0 +1,127 @@
+package notionapi
+
+import (
+	"context"
+)
+
+const (
+	// BacklinkMention is a mention of the page in text of a block
+	BacklinkMention = "mention"
+	// BacklinkLinkToPage is a link to page block (BlockAlias)
+	BacklinkLinkToPage = "link_to_page"
+	// BacklinkRelation is a relation column of a row that points to
+	// the page
+	BacklinkRelation = "relation"
+)
+
+// Backlink describes a block that refers to a page, see Client.GetBacklinks
+type Backlink struct {
+	// id of the block that refers to the page
+	BlockID string
+	// id of the page that has the block. The same as BlockID for rows
+	// with a relation
+	PageID string
+	// BacklinkMention, BacklinkLinkToPage, BacklinkRelation or, for
+	// others, the type sent by the server
+	Type string
+	// for BacklinkRelation, id of the relation column
+	PropertyID string
+	// the block, if the server sent it
+	Block *Block
+}
+
+// /api/v3/getBacklinksForBlock request
+type getBacklinksForBlockRequest struct {
+	Block struct {
+		ID      string `json:"id"`
+		SpaceID string `json:"spaceId,omitempty"`
+	} `json:"block"`
+}
+
+type getBacklinksForBlockResponse struct {
+	Backlinks []struct {
+		BlockID       string `json:"block_id"`
+		MentionedFrom struct {
+			Type       string `json:"type"`
+			BlockID    string `json:"block_id"`
+			PropertyID string `json:"property_id"`
+		} `json:"mentioned_from"`
+	} `json:"backlinks"`
+	RecordMap RecordMap `json:"recordMap"`
+}
+
+// backlinkType returns Backlink.Type for type of mentioned_from
+func backlinkType(typ string) string {
+	switch typ {
+	case "property_mention":
+		return BacklinkMention
+	case "alias":
+		return BacklinkLinkToPage
+	case "collection_relation", "relation":
+		return BacklinkRelation
+	}
+	return typ
+}
+
+// containingPageID returns id of the page that has block id, going up
+// parents in idToBlock. Returns "" if we don't know
+func containingPageID(id string, idToBlock map[string]*BlockWithRole) string {
+	// protect against cycles
+	for i := 0; i < 64; i++ {
+		v := idToBlock[id]
+		if v == nil || v.Value == nil {
+			return ""
+		}
+		b := v.Value
+		if b.Type == BlockPage || b.ParentTable != TableBlock {
+			return b.ID
+		}
+		id = b.ParentID
+	}
+	return ""
+}
+
+// GetBacklinks returns blocks that link to a page or a block: mentions,
+// link to page blocks and relations, as shown in "backlinks" section in
+// Notion
+func (c *Client) GetBacklinks(blockID string) ([]*Backlink, error) {
+	return c.GetBacklinksCtx(context.Background(), blockID)
+}
+
+// GetBacklinksCtx is like GetBacklinks but can be canceled with ctx
+func (c *Client) GetBacklinksCtx(ctx context.Context, blockID string) ([]*Backlink, error) {
+	id, err := ToDashID(blockID)
+	if err != nil {
+		return nil, err
+	}
+	req := &getBacklinksForBlockRequest{}
+	req.Block.ID = id
+	req.Block.SpaceID = c.knownSpaceID()
+	var rsp getBacklinksForBlockResponse
+	err = doNotionAPI(ctx, c, "/api/v3/getBacklinksForBlock", req, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	blocks := rsp.RecordMap.Blocks
+	var res []*Backlink
+	for _, bl := range rsp.Backlinks {
+		from := bl.MentionedFrom.BlockID
+		if from == "" {
+			from = bl.BlockID
+		}
+		link := &Backlink{
+			BlockID:    from,
+			Type:       backlinkType(bl.MentionedFrom.Type),
+			PropertyID: bl.MentionedFrom.PropertyID,
+			PageID:     containingPageID(from, blocks),
+		}
+		if v := blocks[from]; v != nil && v.Value != nil {
+			link.Block = v.Value
+			if err := parseProperties(link.Block); err != nil {
+				return nil, err
+			}
+		}
+		res = append(res, link)
+	}
+	return res, nil
+}
@@ -0,0 +1,52 @@
+package notionapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const backlinksJSON = `{
+	"backlinks": [
+		{ "block_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", "mentioned_from": { "type": "property_mention", "block_id": "text1", "property_id": "title" } },
+		{ "block_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", "mentioned_from": { "type": "alias", "block_id": "alias1" } },
+		{ "block_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", "mentioned_from": { "type": "collection_relation", "block_id": "row1", "property_id": "rel" } }
+	],
+	"recordMap": {
+		"block": {
+			"page1": { "role": "reader", "value": { "id": "page1", "type": "page", "parent_table": "space", "alive": true } },
+			"toggle1": { "role": "reader", "value": { "id": "toggle1", "type": "toggle", "parent_id": "page1", "parent_table": "block", "alive": true } },
+			"text1": { "role": "reader", "value": { "id": "text1", "type": "text", "parent_id": "toggle1", "parent_table": "block", "alive": true, "properties": { "title": [["see "], ["‣", [["p", "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"]]]] } } },
+			"row1": { "role": "reader", "value": { "id": "row1", "type": "page", "parent_id": "coll1", "parent_table": "collection", "alive": true } }
+		}
+	}
+}`
+
+func TestGetBacklinks(t *testing.T) {
+	c, transport := newStaticClient(200, backlinksJSON)
+	links, err := c.GetBacklinks("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(transport.requests[0], `"block":{"id":"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"}`), transport.requests[0])
+	assert.Equal(t, 3, len(links))
+
+	l := links[0]
+	assert.Equal(t, BacklinkMention, l.Type)
+	assert.Equal(t, "text1", l.BlockID)
+	assert.Equal(t, "page1", l.PageID)
+	assert.Equal(t, "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", l.Block.InlineContent[1].PageID)
+
+	l = links[1]
+	assert.Equal(t, BacklinkLinkToPage, l.Type)
+	// the server didn't send the block
+	assert.Equal(t, "", l.PageID)
+	assert.Nil(t, l.Block)
+
+	l = links[2]
+	assert.Equal(t, BacklinkRelation, l.Type)
+	assert.Equal(t, "row1", l.PageID)
+	assert.Equal(t, "rel", l.PropertyID)
+
+	_, err = c.GetBacklinks("foo")
+	assert.Error(t, err)
+}
@@ -0,0 +1,186 @@
+package notionapi
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultBatchDelay is how long TransactionBatcher that doesn't have
+	// Delay set waits for more operations before submitting them
+	DefaultBatchDelay = 50 * time.Millisecond
+	// DefaultBatchMaxOperations is the number of operations at which
+	// TransactionBatcher that doesn't have MaxOperations set submits them
+	// without waiting for Delay
+	DefaultBatchMaxOperations = 100
+)
+
+var (
+	// ErrBatcherClosed is returned by TransactionBatcher.Submit after
+	// Close was called
+	ErrBatcherClosed = errors.New("transaction batcher is closed")
+)
+
+// batchedOps are operations of a single Submit call
+type batchedOps struct {
+	ops  []*Operation
+	done chan error
+}
+
+// TransactionBatcher combines operations submitted by different callers
+// (e.g. concurrent request handlers) into a single SubmitTransaction
+// request, sent after Delay or when there are MaxOperations operations.
+// Operations of a single Submit call are always sent in the same
+// transaction. Transactions are sent one at a time, in the order of Submit
+// calls, so operations on the same block are applied in the order they
+// were submitted.
+// If Client.Batcher is set to a batcher, SubmitTransaction (and methods
+// that modify pages) send operations with it and return when they were
+// committed. Call Close before the program exits to send pending operations
+type TransactionBatcher struct {
+	Client *Client
+	// maximum time operations wait for more operations before they're
+	// sent. If not set, DefaultBatchDelay
+	Delay time.Duration
+	// operations are sent without waiting for Delay when there are at
+	// least that many. It's also the maximum number of operations in a
+	// transaction, except that operations of a single Submit call are
+	// not split. If not set, DefaultBatchMaxOperations
+	MaxOperations int
+
+	mu       sync.Mutex
+	pending  []*batchedOps
+	nPending int
+	timer    *time.Timer
+	closed   bool
+	// held while sending so that transactions are sent in order
+	sendMu sync.Mutex
+}
+
+// NewTransactionBatcher returns a batcher that submits transactions with
+// client. To batch all changes made with client, set client.Batcher to it
+func NewTransactionBatcher(client *Client) *TransactionBatcher {
+	return &TransactionBatcher{
+		Client: client,
+	}
+}
+
+func (b *TransactionBatcher) delay() time.Duration {
+	if b.Delay > 0 {
+		return b.Delay
+	}
+	return DefaultBatchDelay
+}
+
+func (b *TransactionBatcher) maxOperations() int {
+	if b.MaxOperations > 0 {
+		return b.MaxOperations
+	}
+	return DefaultBatchMaxOperations
+}
+
+// Submit queues operations to be sent in a transaction. The returned
+// channel receives nil when they were committed or the error of the
+// transaction (*TransactionError) with which they were sent
+func (b *TransactionBatcher) Submit(ops []*Operation) <-chan error {
+	done := make(chan error, 1)
+	if len(ops) == 0 {
+		done <- nil
+		return done
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		done <- ErrBatcherClosed
+		return done
+	}
+	b.pending = append(b.pending, &batchedOps{ops: ops, done: done})
+	b.nPending += len(ops)
+	if b.nPending >= b.maxOperations() {
+		b.stopTimer()
+		go b.flush()
+	} else if b.timer == nil {
+		b.timer = time.AfterFunc(b.delay(), func() { b.flush() })
+	}
+	return done
+}
+
+// SubmitCtx is like Submit but waits until operations were committed. If
+// ctx is canceled, it returns without waiting but the operations are
+// still sent
+func (b *TransactionBatcher) SubmitCtx(ctx context.Context, ops []*Operation) error {
+	select {
+	case err := <-b.Submit(ops):
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// must be called with mu locked
+func (b *TransactionBatcher) stopTimer() {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+}
+
+// Flush sends pending operations without waiting for Delay and returns
+// when they were sent. It returns the first error of the transactions
+func (b *TransactionBatcher) Flush() error {
+	return b.flush()
+}
+
+// Close sends pending operations, like Flush. After Close, Submit fails
+// with ErrBatcherClosed
+func (b *TransactionBatcher) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	return b.flush()
+}
+
+// flush sends pending operations in transactions of up to MaxOperations
+// operations
+func (b *TransactionBatcher) flush() error {
+	b.sendMu.Lock()
+	defer b.sendMu.Unlock()
+
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = nil
+	b.nPending = 0
+	b.stopTimer()
+	b.mu.Unlock()
+
+	var firstErr error
+	max := b.maxOperations()
+	for len(pending) > 0 {
+		n := 1
+		nOps := len(pending[0].ops)
+		for n < len(pending) && nOps+len(pending[n].ops) <= max {
+			nOps += len(pending[n].ops)
+			n++
+		}
+		batch := pending[:n]
+		pending = pending[n:]
+
+		ops := make([]*Operation, 0, nOps)
+		for _, p := range batch {
+			ops = append(ops, p.ops...)
+		}
+		err := b.Client.submitTransactionNow(context.Background(), ops)
+		if err != nil {
+			logWarn(b.Client, "TransactionBatcher: transaction with %d operations failed with '%s'\n", len(ops), err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		for _, p := range batch {
+			p.done <- err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,138 @@
+package notionapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// batchTransport records ids of blocks of operations of submitTransaction
+// requests. Requests fail with 400 if fail is set
+type batchTransport struct {
+	mu           sync.Mutex
+	fail         bool
+	transactions [][]string
+}
+
+func (t *batchTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, _ := readRequestBody(r)
+	var req submitTransactionRequest
+	json.Unmarshal([]byte(body), &req)
+	var ids []string
+	for _, op := range req.Operations {
+		ids = append(ids, op.ID)
+	}
+	t.mu.Lock()
+	t.transactions = append(t.transactions, ids)
+	code := 200
+	if t.fail {
+		code = 400
+	}
+	t.mu.Unlock()
+	return &http.Response{
+		StatusCode: code,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+		Request:    r,
+	}, nil
+}
+
+func newBatcherClient(transport *batchTransport) *Client {
+	return &Client{
+		AuthToken:   "token",
+		SpaceID:     "space",
+		HTTPClient:  &http.Client{Transport: transport},
+		RetryPolicy: &NoRetryPolicy,
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+}
+
+func batchOps(ids ...string) []*Operation {
+	var res []*Operation
+	for _, id := range ids {
+		res = append(res, buildSetTitleOp(id, "title"))
+	}
+	return res
+}
+
+func TestTransactionBatcher(t *testing.T) {
+	transport := &batchTransport{}
+	client := newBatcherClient(transport)
+	b := NewTransactionBatcher(client)
+	b.Delay = time.Hour
+	b.MaxOperations = 3
+
+	c1 := b.Submit(batchOps("a"))
+	c2 := b.Submit(batchOps("b", "a"))
+	// not sent until Delay or MaxOperations
+	assert.Equal(t, 0, len(transport.transactions))
+	assert.NoError(t, b.Flush())
+	assert.NoError(t, <-c1)
+	assert.NoError(t, <-c2)
+	assert.Equal(t, [][]string{{"a", "b", "a"}}, transport.transactions)
+
+	// operations of a single Submit are not split
+	transport.transactions = nil
+	c1 = b.Submit(batchOps("a", "b"))
+	c2 = b.Submit(batchOps("c", "d"))
+	assert.NoError(t, <-c1)
+	assert.NoError(t, <-c2)
+	assert.Equal(t, [][]string{{"a", "b"}, {"c", "d"}}, transport.transactions)
+
+	transport.fail = true
+	transport.transactions = nil
+	c1 = b.Submit(batchOps("a"))
+	assert.Error(t, b.Close())
+	err := <-c1
+	var txErr *TransactionError
+	assert.True(t, errors.As(err, &txErr))
+	assert.Equal(t, 1, len(transport.transactions))
+
+	err = <-b.Submit(batchOps("a"))
+	assert.Equal(t, ErrBatcherClosed, err)
+}
+
+func TestTransactionBatcherConcurrent(t *testing.T) {
+	transport := &batchTransport{}
+	client := newBatcherClient(transport)
+	client.Batcher = NewTransactionBatcher(client)
+	client.Batcher.Delay = 10 * time.Millisecond
+
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("block-%d", i%4)
+			errs[i] = client.SubmitTransaction(batchOps(id, id))
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, client.Batcher.Close())
+
+	nOps := 0
+	transport.mu.Lock()
+	defer transport.mu.Unlock()
+	for _, ids := range transport.transactions {
+		nOps += len(ids)
+		// operations of a single call stay together
+		for i := 0; i < len(ids); i += 2 {
+			assert.Equal(t, ids[i], ids[i+1])
+		}
+	}
+	assert.Equal(t, 2*n, nOps)
+	assert.True(t, len(transport.transactions) < n)
+}
@@ -0,0 +1,70 @@
+package notionapi
+
+import "sort"
+
+// setBlockIndex builds index used by BlockByID. idToBlock are all blocks
+// we got from the server, some might not be reachable from the root.
+// Blocks are indexed by id in the format of mustNormalizeID
+func (p *Page) setBlockIndex(idToBlock map[string]*Block) {
+	p.blocks = map[string]*Block{}
+	p.orphans = map[string]bool{}
+	var add func(b *Block)
+	add = func(b *Block) {
+		if b == nil || p.blocks[mustNormalizeID(b.ID)] != nil {
+			return
+		}
+		p.blocks[mustNormalizeID(b.ID)] = b
+		for _, child := range b.Content {
+			add(child)
+		}
+		// templates of BlockFactory are not in Content
+		for _, child := range b.TemplateContent {
+			add(child)
+		}
+		for _, view := range b.CollectionViews {
+			for _, row := range view.CollectionRows {
+				add(row)
+			}
+		}
+	}
+	if p.Root != nil {
+		add(p.Root)
+	}
+	for id, b := range idToBlock {
+		id = mustNormalizeID(id)
+		if b != nil && p.blocks[id] == nil {
+			p.blocks[id] = b
+			p.orphans[id] = true
+		}
+	}
+}
+
+// BlockByID returns a block of the page, a row of a table in the page or
+// an orphan block (see OrphanBlocks) with a given id, nil if the page
+// doesn't have it. id can be with or without dashes, in any case
+func (p *Page) BlockByID(id string) *Block {
+	if p.blocks == nil {
+		// page wasn't created by DownloadPage or LoadPage
+		p.setBlockIndex(nil)
+	}
+	return p.blocks[mustNormalizeID(id)]
+}
+
+// OrphanBlocks returns blocks that the server sent with the page but that
+// are not reachable from the root, e.g. because they were moved to
+// another page. They're sorted by id
+func (p *Page) OrphanBlocks() []*Block {
+	var res []*Block
+	for id := range p.orphans {
+		res = append(res, p.blocks[id])
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+// IsOrphan returns true if a block with a given id is in OrphanBlocks
+func (p *Page) IsOrphan(id string) bool {
+	return p.orphans[mustNormalizeID(id)]
+}
@@ -0,0 +1,82 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockByID(t *testing.T) {
+	pageJSON := `{
+	"recordMap": {
+		"block": {
+			"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d": {
+				"role": "reader",
+				"value": {
+					"alive": true,
+					"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+					"type": "page",
+					"content": ["a1b1c1d1-0000-4000-8000-000000000001"],
+					"version": 1
+				}
+			},
+			"a1b1c1d1-0000-4000-8000-000000000001": {
+				"role": "reader",
+				"value": {
+					"alive": true,
+					"id": "a1b1c1d1-0000-4000-8000-000000000001",
+					"parent_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+					"parent_table": "block",
+					"type": "text",
+					"version": 1
+				}
+			},
+			"a1b1c1d1-0000-4000-8000-000000000002": {
+				"role": "reader",
+				"value": {
+					"alive": true,
+					"id": "a1b1c1d1-0000-4000-8000-000000000002",
+					"parent_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+					"parent_table": "block",
+					"type": "text",
+					"version": 1
+				}
+			}
+		}
+	},
+	"cursor": { "stack": [] }
+}`
+	c, _ := newStaticClient(200, pageJSON)
+	page, err := c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	assert.Equal(t, page.Root, page.BlockByID("4c6a54c68b3e4ea2af9cfaabcc88d58d"))
+	child := page.BlockByID("a1b1c1d1-0000-4000-8000-000000000001")
+	assert.Equal(t, page.Root.Content[0], child)
+	assert.Equal(t, child, page.BlockByID("a1b1c1d1000040008000000000000001"))
+	assert.Equal(t, page.Root, page.BlockByID("4C6A54C68B3E4EA2AF9CFAABCC88D58D"))
+	assert.Equal(t, child, page.BlockByID("A1B1C1D1-0000-4000-8000-000000000001"))
+	assert.False(t, page.IsOrphan(child.ID))
+
+	orphan := page.BlockByID("a1b1c1d1000040008000000000000002")
+	assert.NotNil(t, orphan)
+	assert.True(t, page.IsOrphan(orphan.ID))
+	assert.Equal(t, []*Block{orphan}, page.OrphanBlocks())
+	assert.Nil(t, page.BlockByID("a1b1c1d1-0000-4000-8000-000000000003"))
+}
+
+func TestBlockByIDWithoutIndex(t *testing.T) {
+	page := testTraversePage()
+	assert.Equal(t, "in-toggle", page.BlockByID("in-toggle").ID)
+	assert.Equal(t, "row2", page.BlockByID("row2").ID)
+	assert.Equal(t, 0, len(page.OrphanBlocks()))
+}
+
+func TestBlockByIDMixedCase(t *testing.T) {
+	// ids of blocks not in the canonical format are still found
+	child := &Block{ID: "A1B1C1D1000040008000000000000001", Type: BlockText}
+	root := &Block{ID: "4C6A54C6-8B3E-4EA2-AF9C-FAABCC88D58D", Type: BlockPage, Content: []*Block{child}}
+	page := &Page{Root: root}
+	assert.Equal(t, root, page.BlockByID("4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"))
+	assert.Equal(t, child, page.BlockByID("a1b1c1d1-0000-4000-8000-000000000001"))
+	assert.Equal(t, child, page.BlockByID("a1b1c1d1000040008000000000000001"))
+}
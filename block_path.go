@@ -0,0 +1,92 @@
+package notionapi
+
+import "strings"
+
+// PathToBlock returns blocks from the root of the page to b, including
+// both. Returns nil if b is not in the page
+func (p *Page) PathToBlock(b *Block) []*Block {
+	if p.Root == nil || b == nil {
+		return nil
+	}
+	// fast path using Block.Parent
+	var res []*Block
+	for curr := b; curr != nil && !curr.IsStub; curr = curr.Parent {
+		res = append(res, curr)
+		if curr == p.Root {
+			reverseBlocks(res)
+			return res
+		}
+	}
+	// Parent is not set e.g. for pages not created by DownloadPage
+	res = nil
+	var stack []*Block
+	p.ForEachBlockWithParent(func(curr, parent *Block) bool {
+		if res != nil {
+			return false
+		}
+		for len(stack) > 0 && stack[len(stack)-1] != parent {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, curr)
+		if curr == b {
+			res = append([]*Block{}, stack...)
+		}
+		return true
+	})
+	return res
+}
+
+func reverseBlocks(a []*Block) {
+	for i, j := 0, len(a)-1; i < j; i, j = i+1, j-1 {
+		a[i], a[j] = a[j], a[i]
+	}
+}
+
+// BreadcrumbOptions describes options for Block.BreadcrumbWithOptions
+type BreadcrumbOptions struct {
+	// if true, blocks like toggles and columns that contain the block are
+	// also included, not only pages
+	IncludeStructural bool
+	// separator of parts, " / " if empty
+	Separator string
+}
+
+// Breadcrumb returns titles of pages that contain the block, starting
+// with the top-most, separated with " / ". Uses Block.Parent
+func (b *Block) Breadcrumb() string {
+	return b.BreadcrumbWithOptions(nil)
+}
+
+// BreadcrumbWithOptions is like Breadcrumb but with options
+func (b *Block) BreadcrumbWithOptions(opts *BreadcrumbOptions) string {
+	if opts == nil {
+		opts = &BreadcrumbOptions{}
+	}
+	sep := opts.Separator
+	if sep == "" {
+		sep = " / "
+	}
+	var parts []string
+	for curr := b.Parent; curr != nil && !curr.IsStub; curr = curr.Parent {
+		if curr.Type == BlockPage {
+			title := curr.Title
+			if title == "" {
+				title = "Untitled"
+			}
+			parts = append(parts, title)
+			continue
+		}
+		if !opts.IncludeStructural {
+			continue
+		}
+		s := strings.TrimSpace(InlineToText(curr.InlineContent))
+		if s == "" {
+			s = curr.Type
+		}
+		parts = append(parts, s)
+	}
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, sep)
+}
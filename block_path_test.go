@@ -0,0 +1,59 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathToBlock(t *testing.T) {
+	page := testTraversePage()
+	ids := func(blocks []*Block) []string {
+		var res []string
+		for _, b := range blocks {
+			res = append(res, b.ID)
+		}
+		return res
+	}
+	inToggle := page.BlockByID("in-toggle")
+	exp := []string{page.Root.ID, "list", "col1", "toggle", "in-toggle"}
+	assert.Equal(t, exp, ids(page.PathToBlock(inToggle)))
+	assert.Equal(t, []string{page.Root.ID}, ids(page.PathToBlock(page.Root)))
+	assert.Nil(t, page.PathToBlock(&Block{ID: "elsewhere"}))
+}
+
+func TestBreadcrumb(t *testing.T) {
+	idToBlock := map[string]*Block{}
+	add := func(id string, typ string, parentID string, title string, content ...string) *Block {
+		b := &Block{
+			ID:          id,
+			Type:        typ,
+			Alive:       true,
+			ContentIDs:  content,
+			ParentID:    parentID,
+			ParentTable: TableBlock,
+			Properties:  map[string]interface{}{"title": []interface{}{[]interface{}{title}}},
+		}
+		idToBlock[id] = b
+		return b
+	}
+	root := add("root", BlockPage, "", "Handbook", "sub")
+	add("sub", BlockPage, "root", "Onboarding", "toggle")
+	add("toggle", BlockToggle, "sub", "First day", "list")
+	add("list", BlockColumnList, "toggle", "", "col")
+	add("col", BlockColumn, "list", "", "text")
+	text := add("text", BlockText, "col", "Get a laptop")
+	err := resolveBlocks(root, idToBlock, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Handbook / Onboarding", text.Breadcrumb())
+	opts := &BreadcrumbOptions{IncludeStructural: true, Separator: " > "}
+	assert.Equal(t, "Handbook > Onboarding > First day > column_list > column", text.BreadcrumbWithOptions(opts))
+	assert.Equal(t, "", root.Breadcrumb())
+
+	page := &Page{ID: "root", Root: root}
+	path := page.PathToBlock(text)
+	assert.Equal(t, 6, len(path))
+	assert.Equal(t, root, path[0])
+	assert.Equal(t, text, path[5])
+}
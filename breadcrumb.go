@@ -0,0 +1,105 @@
+package notionapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// protects against loops in parent ids
+const maxAncestors = 64
+
+// Ancestor is a page, a database or a workspace that contains a page
+type Ancestor struct {
+	// TableBlock, TableCollection or TableSpace
+	Table string `json:"table"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	// emoji or url of the icon, if it has one
+	Icon string `json:"icon,omitempty"`
+}
+
+// GetAncestors returns pages, databases and the workspace that contain
+// the page, starting with the workspace. The page itself is not included.
+// Ancestors we don't have access to, and their ancestors, are skipped
+func (c *Client) GetAncestors(pageID string) ([]*Ancestor, error) {
+	return c.GetAncestorsCtx(context.Background(), pageID)
+}
+
+// GetAncestorsCtx is like GetAncestors but can be canceled with ctx
+func (c *Client) GetAncestorsCtx(ctx context.Context, pageID string) ([]*Ancestor, error) {
+	normalizedID, err := ToDashID(pageID)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := c.GetRecordValuesCtx(ctx, []RecordRequest{{Table: TableBlock, ID: normalizedID}})
+	if err != nil {
+		return nil, err
+	}
+	block := rsp.Results[0].Block
+	if block == nil {
+		return nil, fmt.Errorf("couldn't retrieve page with id %s: %w", pageID, ErrNotFound)
+	}
+	return c.getAncestors(ctx, block)
+}
+
+func (c *Client) getAncestors(ctx context.Context, block *Block) ([]*Ancestor, error) {
+	var res []*Ancestor
+	table, id := block.ParentTable, block.ParentID
+	for i := 0; id != "" && i < maxAncestors; i++ {
+		rsp, err := c.GetRecordValuesCtx(ctx, []RecordRequest{{Table: table, ID: id}})
+		if err != nil {
+			return nil, err
+		}
+		rv := rsp.Results[0]
+		switch {
+		case rv.Block != nil:
+			b := rv.Block
+			// blocks like columns or toggles are not shown
+			if b.Type == BlockPage {
+				a := &Ancestor{Table: TableBlock, ID: b.ID, Title: b.Title}
+				if b.FormatPage != nil {
+					a.Icon = b.FormatPage.PageIcon
+				}
+				res = append(res, a)
+			}
+			table, id = b.ParentTable, b.ParentID
+		case rv.Collection != nil:
+			coll := rv.Collection
+			res = append(res, &Ancestor{Table: TableCollection, ID: coll.ID, Title: coll.Name(), Icon: coll.Icon})
+			table, id = coll.ParentTable, coll.ParentID
+		case rv.Space != nil:
+			res = append(res, &Ancestor{Table: TableSpace, ID: rv.Space.ID, Title: rv.Space.Name})
+			id = ""
+		default:
+			// we don't have access to the record
+			id = ""
+		}
+	}
+	// we collected them from the page up
+	for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+		res[i], res[j] = res[j], res[i]
+	}
+	return res, nil
+}
+
+// hasBlockType returns true if blocks or their children have a block
+// of a given type. Sub-pages are not checked
+func hasBlockType(blocks []*Block, typ string) bool {
+	for _, b := range blocks {
+		if b == nil || b.Type == BlockPage {
+			continue
+		}
+		if b.Type == typ || hasBlockType(b.Content, typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// ancestorTitle returns title of a for showing in a breadcrumb
+func ancestorTitle(a *Ancestor) string {
+	if a.Title == "" {
+		return "Untitled"
+	}
+	return a.Title
+}
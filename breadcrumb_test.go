@@ -0,0 +1,70 @@
+package notionapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const ancestorsJSON = `{
+	"recordMap": {
+		"block": {
+			"300db9dc-27c8-4958-a08b-8d0c37f4cfe5": {
+				"role": "editor",
+				"value": {
+					"alive": true,
+					"id": "300db9dc-27c8-4958-a08b-8d0c37f4cfe5",
+					"type": "page",
+					"properties": { "title": [["Parent"]] },
+					"format": { "page_icon": "📝" },
+					"parent_table": "space",
+					"parent_id": "bc202e06-6caa-4e3f-81eb-f226ab5deef7"
+				}
+			},
+			"0367c2db-381a-4f8b-9ce3-60f388a6b2e3": {
+				"role": "editor",
+				"value": {
+					"alive": true,
+					"id": "0367c2db-381a-4f8b-9ce3-60f388a6b2e3",
+					"type": "column",
+					"parent_table": "block",
+					"parent_id": "300db9dc-27c8-4958-a08b-8d0c37f4cfe5"
+				}
+			}
+		},
+		"space": {
+			"bc202e06-6caa-4e3f-81eb-f226ab5deef7": {
+				"role": "editor",
+				"value": {
+					"id": "bc202e06-6caa-4e3f-81eb-f226ab5deef7",
+					"name": "Work"
+				}
+			}
+		}
+	}
+}`
+
+func TestAncestors(t *testing.T) {
+	c, _ := newStaticClient(200, ancestorsJSON)
+	page := testPage(&Block{Type: BlockBreadcrumb})
+	page.Root.ParentTable = TableBlock
+	page.Root.ParentID = "0367c2db-381a-4f8b-9ce3-60f388a6b2e3"
+	assert.True(t, hasBlockType(page.Root.Content, BlockBreadcrumb))
+
+	ancestors, err := c.getAncestors(context.Background(), page.Root)
+	assert.NoError(t, err)
+	// the column is skipped
+	assert.Equal(t, []*Ancestor{
+		{Table: TableSpace, ID: "bc202e06-6caa-4e3f-81eb-f226ab5deef7", Title: "Work"},
+		{Table: TableBlock, ID: "300db9dc-27c8-4958-a08b-8d0c37f4cfe5", Title: "Parent", Icon: "📝"},
+	}, ancestors)
+
+	page.Ancestors = ancestors
+	md := string(ToMarkdown(page, nil))
+	assert.True(t, strings.Contains(md, "Work / [Parent](https://www.notion.so/300db9dc27c84958a08b8d0c37f4cfe5) / Test page\n"), md)
+	html := string(ToHTML(page, nil))
+	assert.True(t, strings.Contains(html, `<nav class="breadcrumb">Work / <a href="https://www.notion.so/300db9dc27c84958a08b8d0c37f4cfe5">Parent</a> / Test page</nav>`), html)
+	assert.Equal(t, "Test page\n", page.ToText())
+}
@@ -0,0 +1,334 @@
+package notionapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// cacheFormatVersion is the version of the format of files with
+	// cached pages. In version 1 the payload is json written by SavePage,
+	// in version 2 it's cacheEntry
+	cacheFormatVersion = 2
+	// magic, version of the format and crc32 of the payload
+	cacheHeaderSize = 4 + 1 + 4
+)
+
+// files with cached pages start with this
+var cacheMagic = []byte("NAPC")
+
+// CacheStats has totals of pages downloaded with CachingClient
+type CacheStats struct {
+	// number of pages served from the cache because they didn't change
+	Hits int64
+	// number of pages downloaded because they were not in the cache or
+	// changed since they were cached
+	Misses int64
+}
+
+// cacheOptions are DownloadPageOptions that change what a downloaded page
+// contains. A cached page is only used if it was downloaded with the same
+// options
+type cacheOptions struct {
+	IncludeTemplates   bool `json:"include_templates,omitempty"`
+	IncludeDeleted     bool `json:"include_deleted,omitempty"`
+	IncludeDiscussions bool `json:"include_discussions,omitempty"`
+}
+
+func newCacheOptions(opts *DownloadPageOptions) cacheOptions {
+	return cacheOptions{
+		IncludeTemplates:   opts.IncludeTemplates,
+		IncludeDeleted:     opts.IncludeDeleted,
+		IncludeDiscussions: opts.IncludeDiscussions,
+	}
+}
+
+// cacheEntry is the payload of a cache file
+type cacheEntry struct {
+	Options cacheOptions    `json:"options"`
+	Page    json.RawMessage `json:"page"`
+}
+
+// CachingClient downloads pages with Client and caches them in CacheDir,
+// in the format of SavePage compressed with gzip, with a checksum so that
+// a damaged file (e.g. written by a killed process) is downloaded again.
+// Before using a cached page it checks if the version of its root block
+// changed, which is cheap. Only the root block is checked, like in
+// WatchPage. A page is also downloaded again if it was cached with
+// different IncludeTemplates, IncludeDeleted or IncludeDiscussions options. Uncompressed .json files written by older versions are read
+// and converted to the current format
+type CachingClient struct {
+	Client *Client
+	// directory with cached pages. It's created if it doesn't exist
+	CacheDir string
+
+	mu    sync.Mutex
+	stats CacheStats
+}
+
+// NewCachingClient returns a client that caches pages downloaded with
+// client in cacheDir
+func NewCachingClient(client *Client, cacheDir string) *CachingClient {
+	return &CachingClient{
+		Client:   client,
+		CacheDir: cacheDir,
+	}
+}
+
+// cachePaths returns path of the file with cached page with a given id
+// and of the file in the format of older versions
+func (c *CachingClient) cachePaths(pageID string) (string, string, error) {
+	id, err := ToNoDashID(pageID)
+	if err != nil {
+		return "", "", err
+	}
+	path := filepath.Join(c.CacheDir, id+".page")
+	legacyPath := filepath.Join(c.CacheDir, id+".json")
+	return path, legacyPath, nil
+}
+
+// encodeCacheEntry returns content of a cache file with json d
+func encodeCacheEntry(d []byte) ([]byte, error) {
+	payload, err := gzipBytes(d)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]byte, cacheHeaderSize, cacheHeaderSize+len(payload))
+	copy(res, cacheMagic)
+	res[4] = cacheFormatVersion
+	binary.BigEndian.PutUint32(res[5:], crc32.ChecksumIEEE(payload))
+	return append(res, payload...), nil
+}
+
+// decodeCacheEntry returns json from content of a cache file and the
+// version of its format
+func decodeCacheEntry(d []byte) ([]byte, int, error) {
+	if len(d) < cacheHeaderSize || !bytes.HasPrefix(d, cacheMagic) {
+		return nil, 0, errors.New("not a cache file")
+	}
+	version := int(d[4])
+	if version < 1 || version > cacheFormatVersion {
+		return nil, 0, fmt.Errorf("unsupported version %d of cache file", version)
+	}
+	payload := d[cacheHeaderSize:]
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(d[5:]) {
+		return nil, 0, errors.New("checksum mismatch")
+	}
+	r, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, 0, err
+	}
+	d, err = ioutil.ReadAll(r)
+	return d, version, err
+}
+
+// loadCached returns a page from the cache and options it was downloaded
+// with, nil if it's not cached or the cached file can't be read. legacy is
+// true if the page is in a file in the format of older versions
+func (c *CachingClient) loadCached(path, legacyPath string) (page *Page, opts cacheOptions, legacy bool) {
+	d, err := ioutil.ReadFile(path)
+	if err == nil {
+		var version int
+		d, version, err = decodeCacheEntry(d)
+		if err == nil && version == cacheFormatVersion {
+			var entry cacheEntry
+			err = json.Unmarshal(d, &entry)
+			d, opts = entry.Page, entry.Options
+		}
+		if err != nil {
+			// not fatal, we'll download the page again
+			logWarn(c.Client, "CachingClient: invalid cache file '%s': '%s'\n", path, err)
+			return nil, opts, false
+		}
+		legacy = version != cacheFormatVersion
+	} else {
+		d, err = ioutil.ReadFile(legacyPath)
+		if err != nil {
+			return nil, opts, false
+		}
+		path = legacyPath
+		legacy = true
+	}
+	page, err = LoadPage(bytes.NewReader(d))
+	if err != nil {
+		logWarn(c.Client, "CachingClient: LoadPage('%s') failed with '%s'\n", path, err)
+		return nil, opts, false
+	}
+	// unlike pages loaded with LoadPage, cached pages can be modified
+	page.client = c.Client
+	for _, t := range page.Tables {
+		t.client = c.Client
+	}
+	return page, opts, legacy
+}
+
+// save writes page downloaded with opts to the cache, replacing a file in
+// the format of older versions
+func (c *CachingClient) save(path, legacyPath string, page *Page, opts cacheOptions) error {
+	var buf bytes.Buffer
+	if err := SavePage(&buf, page); err != nil {
+		return err
+	}
+	d, err := json.Marshal(&cacheEntry{Options: opts, Page: buf.Bytes()})
+	if err != nil {
+		return err
+	}
+	d, err = encodeCacheEntry(d)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.CacheDir, 0755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(path, d); err != nil {
+		return err
+	}
+	err = os.Remove(legacyPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// DownloadPage returns a page from the cache if it didn't change since it
+// was cached. Otherwise it downloads the page and caches it
+func (c *CachingClient) DownloadPage(pageID string) (*Page, error) {
+	return c.DownloadPageCtx(context.Background(), pageID)
+}
+
+// DownloadPageCtx is like DownloadPage but can be canceled with ctx
+func (c *CachingClient) DownloadPageCtx(ctx context.Context, pageID string) (*Page, error) {
+	return c.DownloadPageWithOptionsCtx(ctx, pageID, nil)
+}
+
+// DownloadPageWithOptions is like DownloadPage but the page is downloaded
+// with options, like Client.DownloadPageWithOptions. Options that limit or
+// report downloading (e.g. MaxBlocks, ProgressFunc) only apply when the
+// page is downloaded and not when it's returned from the cache
+func (c *CachingClient) DownloadPageWithOptions(pageID string, opts *DownloadPageOptions) (*Page, error) {
+	return c.DownloadPageWithOptionsCtx(context.Background(), pageID, opts)
+}
+
+// DownloadPageWithOptionsCtx is like DownloadPageWithOptions but can be
+// canceled with ctx
+func (c *CachingClient) DownloadPageWithOptionsCtx(ctx context.Context, pageID string, opts *DownloadPageOptions) (*Page, error) {
+	if opts == nil {
+		opts = &DownloadPageOptions{}
+	}
+	id, err := pageIDArg(pageID)
+	if err != nil {
+		return nil, err
+	}
+	path, legacyPath, err := c.cachePaths(id)
+	if err != nil {
+		return nil, err
+	}
+	cached, cachedOpts, legacy := c.loadCached(path, legacyPath)
+	if cached != nil && cachedOpts != newCacheOptions(opts) {
+		cached = nil
+	}
+	if cached == nil && c.Client.Offline {
+		return nil, fmt.Errorf("page %s is not in the cache: %w", id, ErrOffline)
+	}
+	if cached != nil && !c.Client.Offline {
+		versions, err := c.Client.GetBlockVersionsCtx(ctx, []string{id})
+		if err != nil {
+			return nil, err
+		}
+		if version, ok := versions[id]; !ok || version != cached.Root.Version {
+			cached = nil
+		}
+	}
+	if cached != nil {
+		c.mu.Lock()
+		c.stats.Hits++
+		c.mu.Unlock()
+		if legacy {
+			if err := c.save(path, legacyPath, cached, cachedOpts); err != nil {
+				logWarn(c.Client, "CachingClient: failed to convert cached page %s: '%s'\n", id, err)
+			}
+		}
+		if opts.DropFormatRaw {
+			cached.dropFormatRaw()
+		}
+		return cached, nil
+	}
+	// the cache must have complete formats so we drop them after saving
+	downloadOpts := *opts
+	downloadOpts.DropFormatRaw = false
+	page, err := c.Client.DownloadPageWithOptionsCtx(ctx, id, &downloadOpts)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.stats.Misses++
+	c.mu.Unlock()
+	if err := c.save(path, legacyPath, page, newCacheOptions(opts)); err != nil {
+		// not fatal, we'll download the page again next time
+		logWarn(c.Client, "CachingClient: failed to cache page %s: '%s'\n", id, err)
+	}
+	if opts.DropFormatRaw {
+		page.dropFormatRaw()
+	}
+	return page, nil
+}
+
+// SetOffline sets Client.Offline. When offline, cached pages are used
+// without checking if they changed and downloading pages that are not
+// cached fails with ErrOffline. Files can be loaded from the cache of
+// Downloader (see Downloader.CacheDir)
+func (c *CachingClient) SetOffline(offline bool) {
+	c.Client.Offline = offline
+}
+
+// WarmCache downloads a page and all pages reachable from it, like
+// Client.DownloadPagesRecursively, and caches them so that they can later
+// be used offline (see SetOffline). Pages are downloaded with
+// opts.DownloadPageOptions and limits of opts, like in
+// DownloadPagesRecursively. Pages that didn't change since they were
+// cached are not downloaded again
+func (c *CachingClient) WarmCache(rootID string, opts *RecursiveOptions) ([]*Page, error) {
+	return c.WarmCacheCtx(context.Background(), rootID, opts)
+}
+
+// WarmCacheCtx is like WarmCache but can be canceled with ctx
+func (c *CachingClient) WarmCacheCtx(ctx context.Context, rootID string, opts *RecursiveOptions) ([]*Page, error) {
+	return downloadPagesRecursively(ctx, c.Client, rootID, opts, c.DownloadPageWithOptionsCtx)
+}
+
+// Flush removes a page from the cache so that it'll be downloaded the next
+// time. It's not an error if the page is not cached
+func (c *CachingClient) Flush(pageID string) error {
+	id, err := pageIDArg(pageID)
+	if err != nil {
+		return err
+	}
+	path, legacyPath, err := c.cachePaths(id)
+	if err != nil {
+		return err
+	}
+	for _, p := range []string{path, legacyPath} {
+		err = os.Remove(p)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stats returns the number of cache hits and misses so far
+func (c *CachingClient) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
@@ -0,0 +1,280 @@
+package notionapi
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachingClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notionapi-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	transport := &watchTransport{version: 1}
+	client := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+	c := NewCachingClient(client, filepath.Join(dir, "cache"))
+
+	page, err := c.DownloadPage(watchPageID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.Root.Version)
+	assert.Equal(t, CacheStats{Misses: 1}, c.Stats())
+	path := filepath.Join(dir, "cache", "4c6a54c68b3e4ea2af9cfaabcc88d58d.page")
+	assert.FileExists(t, path)
+
+	// unchanged page only costs checking the version
+	requests := client.Stats().Requests
+	page, err = c.DownloadPage("4C6A54C68B3E4EA2AF9CFAABCC88D58D")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.Root.Version)
+	assert.Equal(t, 1, len(page.Root.Content))
+	assert.Equal(t, page.client, client)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1}, c.Stats())
+	assert.Equal(t, requests+1, client.Stats().Requests)
+
+	transport.setVersion(2)
+	page, err = c.DownloadPage(watchPageID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), page.Root.Version)
+	assert.Equal(t, 2, len(page.Root.Content))
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2}, c.Stats())
+
+	_, err = c.DownloadPage(watchPageID)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 2}, c.Stats())
+
+	assert.NoError(t, c.Flush(watchPageID))
+	// not an error if not cached
+	assert.NoError(t, c.Flush(watchPageID))
+	_, err = c.DownloadPage(watchPageID)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 3}, c.Stats())
+
+	// a truncated cache file is not fatal
+	d, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(path, d[:len(d)/2], 0644)
+	assert.NoError(t, err)
+	_, err = c.DownloadPage(watchPageID)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 4}, c.Stats())
+}
+
+func TestCacheEntry(t *testing.T) {
+	js := []byte(`{"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"}`)
+	d, err := encodeCacheEntry(js)
+	assert.NoError(t, err)
+	got, version, err := decodeCacheEntry(d)
+	assert.NoError(t, err)
+	assert.Equal(t, js, got)
+	assert.Equal(t, cacheFormatVersion, version)
+
+	// any change of the payload is detected
+	for _, i := range []int{cacheHeaderSize, len(d) - 1} {
+		damaged := append([]byte{}, d...)
+		damaged[i] ^= 1
+		_, _, err = decodeCacheEntry(damaged)
+		assert.Error(t, err)
+	}
+	_, _, err = decodeCacheEntry(d[:len(d)-1])
+	assert.Error(t, err)
+	_, _, err = decodeCacheEntry(js)
+	assert.Error(t, err)
+}
+
+func TestCachingClientOptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notionapi-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	transport := &watchTransport{version: 1}
+	client := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+	c := NewCachingClient(client, dir)
+	withDiscussions := &DownloadPageOptions{IncludeDiscussions: true}
+
+	_, err = c.DownloadPage(watchPageID)
+	assert.NoError(t, err)
+	// cached without discussions
+	page, err := c.DownloadPageWithOptions(watchPageID, withDiscussions)
+	assert.NoError(t, err)
+	assert.True(t, page.discussionsLoaded)
+	assert.Equal(t, CacheStats{Misses: 2}, c.Stats())
+
+	page, err = c.DownloadPageWithOptions(watchPageID, withDiscussions)
+	assert.NoError(t, err)
+	assert.True(t, page.discussionsLoaded)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2}, c.Stats())
+
+	_, err = c.DownloadPageWithOptions(watchPageID, &DownloadPageOptions{IncludeDeleted: true})
+	assert.NoError(t, err)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 3}, c.Stats())
+
+	// files in version 1 of the format have pages downloaded without
+	// options and are converted
+	var buf bytes.Buffer
+	assert.NoError(t, SavePage(&buf, page))
+	d, err := encodeCacheEntry(buf.Bytes())
+	assert.NoError(t, err)
+	d[4] = 1
+	path := filepath.Join(dir, "4c6a54c68b3e4ea2af9cfaabcc88d58d.page")
+	assert.NoError(t, ioutil.WriteFile(path, d, 0644))
+	_, err = c.DownloadPage(watchPageID)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheStats{Hits: 2, Misses: 3}, c.Stats())
+	d, err = ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, byte(cacheFormatVersion), d[4])
+}
+
+func TestCachingClientLegacyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notionapi-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	transport := &watchTransport{version: 1}
+	client := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+	page, err := client.DownloadPage(watchPageID)
+	assert.NoError(t, err)
+	// older versions saved pages uncompressed in .json files
+	var buf bytes.Buffer
+	assert.NoError(t, SavePage(&buf, page))
+	legacyPath := filepath.Join(dir, "4c6a54c68b3e4ea2af9cfaabcc88d58d.json")
+	assert.NoError(t, ioutil.WriteFile(legacyPath, buf.Bytes(), 0644))
+
+	c := NewCachingClient(client, dir)
+	page, err = c.DownloadPage(watchPageID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), page.Root.Version)
+	assert.Equal(t, CacheStats{Hits: 1}, c.Stats())
+	// converted to the current format
+	assert.FileExists(t, filepath.Join(dir, "4c6a54c68b3e4ea2af9cfaabcc88d58d.page"))
+	_, err = os.Stat(legacyPath)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = c.DownloadPage(watchPageID)
+	assert.NoError(t, err)
+	assert.Equal(t, CacheStats{Hits: 2}, c.Stats())
+}
+
+func TestCachingClientOffline(t *testing.T) {
+	const (
+		root = "00000000-0000-0000-0000-000000000001"
+		a    = "00000000-0000-0000-0000-00000000000a"
+		b    = "00000000-0000-0000-0000-00000000000b"
+	)
+	dir, err := ioutil.TempDir("", "notionapi-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	transport := &pageTreeTransport{
+		children: map[string][]string{
+			root: {a, b},
+			a:    {},
+			b:    {},
+		},
+		downloaded: map[string]int{},
+	}
+	client := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+	c := NewCachingClient(client, filepath.Join(dir, "cache"))
+	pages, err := c.WarmCache(root, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(pages))
+	assert.Equal(t, CacheStats{Misses: 3}, c.Stats())
+	assert.NoError(t, c.Flush(b))
+
+	c.SetOffline(true)
+	requests := client.Stats().Requests
+	page, err := c.DownloadPage(root)
+	assert.NoError(t, err)
+	assert.Equal(t, root, page.ID)
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 3}, c.Stats())
+	_, err = c.DownloadPage(b)
+	assert.True(t, errors.Is(err, ErrOffline))
+	_, err = client.DownloadPage(root)
+	assert.True(t, errors.Is(err, ErrOffline))
+
+	// sub-pages that are not cached don't fail the export
+	exportDir := filepath.Join(dir, "export")
+	res, err := ExportPageTree(client, root, exportDir, ExportFormatMarkdown, &ExportTreeOptions{Cache: c})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(res.Pages))
+	assert.Equal(t, 1, len(res.MissingPages))
+	assert.Equal(t, b, res.MissingPages[0].PageID)
+	assert.True(t, errors.Is(res.MissingPages[0], ErrOffline))
+	assert.Equal(t, requests, client.Stats().Requests)
+
+	// the root page must be cached
+	assert.NoError(t, c.Flush(root))
+	_, err = ExportPageTree(client, root, exportDir, ExportFormatMarkdown, &ExportTreeOptions{Cache: c})
+	assert.True(t, errors.Is(err, ErrOffline))
+}
+
+func TestCachingClientWarmCacheOptions(t *testing.T) {
+	const (
+		root = "00000000-0000-0000-0000-000000000001"
+		a    = "00000000-0000-0000-0000-00000000000a"
+		b    = "00000000-0000-0000-0000-00000000000b"
+	)
+	dir, err := ioutil.TempDir("", "notionapi-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	transport := &pageTreeTransport{
+		children: map[string][]string{
+			root: {a, b},
+			a:    {},
+			b:    {},
+		},
+		downloaded: map[string]int{},
+	}
+	client := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+	c := NewCachingClient(client, dir)
+
+	// limits of RecursiveOptions apply to pages downloaded for the cache
+	pages, err := c.WarmCache(root, &RecursiveOptions{MaxBlocksPerPage: 1})
+	assert.Empty(t, pages)
+	var limitErr *LimitExceededError
+	if assert.True(t, errors.As(err, &limitErr)) {
+		assert.Equal(t, "MaxBlocks", limitErr.Limit)
+		assert.Equal(t, root, limitErr.PageID)
+	}
+	assert.Equal(t, CacheStats{}, c.Stats())
+
+	// pages were not cached so they're downloaded with options
+	var finished []string
+	opts := &RecursiveOptions{
+		DownloadPageOptions: &DownloadPageOptions{
+			ProgressFunc: func(ev ProgressEvent) {
+				if ev.Type == ProgressPageFinished {
+					finished = append(finished, ev.PageID)
+				}
+			},
+		},
+	}
+	pages, err = c.WarmCache(root, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(pages))
+	assert.Equal(t, 3, len(finished))
+	assert.Equal(t, CacheStats{Misses: 3}, c.Stats())
+}
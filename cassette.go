@@ -0,0 +1,241 @@
+package notionapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// CassetteMode tells if Cassette is recording or replaying http requests
+type CassetteMode int
+
+const (
+	// CassetteRecord sends requests to the server and records them
+	CassetteRecord CassetteMode = iota + 1
+	// CassetteReplay doesn't talk to the server. Responses come from
+	// recorded interactions
+	CassetteReplay
+)
+
+var (
+	// ErrCassetteNoMatch is returned in replay mode for requests that were
+	// not recorded
+	ErrCassetteNoMatch = errors.New("no recorded response matches the request")
+)
+
+// Interaction describes a recorded http request and its response
+type Interaction struct {
+	Method          string      `json:"method"`
+	URL             string      `json:"url"`
+	RequestHeaders  http.Header `json:"request_headers,omitempty"`
+	RequestBody     string      `json:"request_body,omitempty"`
+	StatusCode      int         `json:"status_code"`
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	ResponseBody    string      `json:"response_body"`
+}
+
+// Cassette records http requests sent by the Client and replays them
+// to make tests deterministic and not require access to Notion.
+// To use, set Client.Cassette
+type Cassette struct {
+	Mode         CassetteMode   `json:"-"`
+	Interactions []*Interaction `json:"interactions"`
+
+	mu sync.Mutex
+	// in replay mode, for a request key, how many matching interactions
+	// were already used. Allows replaying the same request returning
+	// different responses
+	nUsed map[string]int
+}
+
+// NewCassette returns an empty cassette in record mode
+func NewCassette() *Cassette {
+	return &Cassette{
+		Mode: CassetteRecord,
+	}
+}
+
+// LoadCassette loads a cassette saved with Save, in replay mode
+func LoadCassette(path string) (*Cassette, error) {
+	d, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	err = json.Unmarshal(d, &c)
+	if err != nil {
+		return nil, err
+	}
+	c.Mode = CassetteReplay
+	return &c, nil
+}
+
+// Save saves recorded interactions as JSON file
+func (c *Cassette) Save(path string) error {
+	c.mu.Lock()
+	d, err := json.MarshalIndent(c, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, d, 0644)
+}
+
+var rxTokenV2 = regexp.MustCompile(`token_v2=[^;]*`)
+
+// scrubHeaders returns a copy of headers with secrets removed
+func scrubHeaders(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	res := http.Header{}
+	for name, values := range h {
+		if http.CanonicalHeaderKey(name) == "Set-Cookie" {
+			continue
+		}
+		if http.CanonicalHeaderKey(name) == "Authorization" {
+			res.Set(name, "REDACTED")
+			continue
+		}
+		for _, v := range values {
+			v = rxTokenV2.ReplaceAllString(v, "token_v2=REDACTED")
+			res.Add(name, v)
+		}
+	}
+	return res
+}
+
+// normalizeJSON returns JSON in canonical form (sorted keys, no
+// whitespace) so that bodies that only differ in formatting match.
+// Returns s unchanged if it's not valid JSON
+func normalizeJSON(s string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return s
+	}
+	d, err := json.Marshal(v)
+	if err != nil {
+		return s
+	}
+	return string(d)
+}
+
+func interactionKey(method, uri, body string) string {
+	return method + " " + uri + "\n" + normalizeJSON(body)
+}
+
+// readRequestBody returns the (uncompressed) body of req without
+// consuming it
+func readRequestBody(req *http.Request) (string, error) {
+	body, err := readRawRequestBody(req)
+	if err != nil || req.Header.Get("Content-Encoding") != "gzip" {
+		return body, err
+	}
+	r, err := gzip.NewReader(strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	d, err := ioutil.ReadAll(r)
+	return string(d), err
+}
+
+func readRawRequestBody(req *http.Request) (string, error) {
+	if req.Body == nil {
+		return "", nil
+	}
+	if req.GetBody == nil {
+		d, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return "", err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(d))
+		return string(d), nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	d, err := ioutil.ReadAll(body)
+	return string(d), err
+}
+
+// replay returns recorded response for a request
+func (c *Cassette) replay(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	key := interactionKey(req.Method, req.URL.String(), body)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var matching []*Interaction
+	for _, i := range c.Interactions {
+		if interactionKey(i.Method, i.URL, i.RequestBody) == key {
+			matching = append(matching, i)
+		}
+	}
+	if len(matching) == 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrCassetteNoMatch, req.Method, req.URL)
+	}
+	if c.nUsed == nil {
+		c.nUsed = map[string]int{}
+	}
+	// replay in the order of recording and repeat the last one
+	n := c.nUsed[key]
+	if n >= len(matching) {
+		n = len(matching) - 1
+	}
+	c.nUsed[key] = n + 1
+	i := matching[n]
+	header := http.Header{}
+	for name, values := range i.ResponseHeaders {
+		header[name] = append([]string(nil), values...)
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", i.StatusCode, http.StatusText(i.StatusCode)),
+		StatusCode: i.StatusCode,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(i.ResponseBody)),
+		Request:    req,
+	}, nil
+}
+
+// record records the response. It reads the whole body of rsp and
+// replaces it so that it can still be read by the caller
+func (c *Cassette) record(req *http.Request, rsp *http.Response) error {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return err
+	}
+	d, err := ioutil.ReadAll(rsp.Body)
+	rsp.Body.Close()
+	if err != nil {
+		return err
+	}
+	rsp.Body = ioutil.NopCloser(bytes.NewReader(d))
+	if strings.HasSuffix(req.URL.Path, strings.TrimPrefix(apiLoginWithEmail, apiPathPrefix)) {
+		// don't save the password
+		reqBody = ""
+	}
+	i := &Interaction{
+		Method:          req.Method,
+		URL:             req.URL.String(),
+		RequestHeaders:  scrubHeaders(req.Header),
+		RequestBody:     reqBody,
+		StatusCode:      rsp.StatusCode,
+		ResponseHeaders: scrubHeaders(rsp.Header),
+		ResponseBody:    string(d),
+	}
+	c.mu.Lock()
+	c.Interactions = append(c.Interactions, i)
+	c.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,72 @@
+package notionapi
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return nil, errors.New("unexpected request to " + r.URL.String())
+}
+
+func TestCassetteRecordReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notionapi-cassette")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	cassette := NewCassette()
+	c := &Client{
+		AuthToken:  "secret-token",
+		HTTPClient: &http.Client{Transport: &recordingTransport{}},
+		Cassette:   cassette,
+	}
+	page, err := c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(cassette.Interactions))
+	err = cassette.Save(path)
+	assert.NoError(t, err)
+	d, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(d), "secret-token"))
+	assert.True(t, strings.Contains(string(d), "token_v2=REDACTED"))
+
+	cassette, err = LoadCassette(path)
+	assert.NoError(t, err)
+	assert.Equal(t, CassetteReplay, cassette.Mode)
+	c = &Client{
+		HTTPClient: &http.Client{Transport: failingTransport{}},
+		Cassette:   cassette,
+	}
+	replayed, err := c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	assert.Equal(t, page.Root.ID, replayed.Root.ID)
+
+	_, err = c.GetBlockRecords([]string{"00000000-0000-0000-0000-000000000000"})
+	assert.True(t, errors.Is(err, ErrCassetteNoMatch))
+}
+
+func TestNormalizeJSON(t *testing.T) {
+	assert.Equal(t, `{"a":1,"b":[1,2]}`, normalizeJSON("{ \"b\": [1, 2],\n \"a\": 1 }"))
+	assert.Equal(t, "not json", normalizeJSON("not json"))
+}
+
+func TestScrubHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Cookie", "foo=bar; token_v2=secret; other=1")
+	h.Set("Set-Cookie", "token_v2=secret")
+	h.Set("Content-Type", "application/json")
+	got := scrubHeaders(h)
+	assert.Equal(t, "foo=bar; token_v2=REDACTED; other=1", got.Get("Cookie"))
+	assert.Equal(t, "", got.Get("Set-Cookie"))
+	assert.Equal(t, "application/json", got.Get("Content-Type"))
+}
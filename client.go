@@ -2,16 +2,25 @@ package notionapi
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	notionHost = "https://www.notion.so"
+	// DefaultBaseURL is the url of the API used if Client.BaseURL is not set
+	DefaultBaseURL = "https://www.notion.so/api/v3"
+	// prefix of apiURL arguments of doNotionAPI
+	apiPathPrefix = "/api/v3/"
 	// modern Chrome
 	userAgent  = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_13_5) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/69.0.3483.0 Safari/537.36"
 	acceptLang = "en-US,en;q=0.9"
@@ -36,7 +45,22 @@ type HTTPInterceptor interface {
 type Client struct {
 	// AuthToken allows accessing non-public pages.
 	AuthToken string
-	// HTTPClient allows over-riding http.Client
+	// SpaceID is the id of the workspace used by calls that need one
+	// (e.g. Search). If not set, the first workspace of the user (see
+	// GetSpaces) is used
+	SpaceID string
+	// IntegrationToken, if set, makes DownloadPage use the official API
+	// (https://developers.notion.com) authenticated with this token of
+	// an integration. The page is converted to the same structures, but
+	// changing pages is not supported
+	IntegrationToken string
+	// OfficialAPIBaseURL is the url of the official API. Default is
+	// OfficialAPIBaseURL
+	OfficialAPIBaseURL string
+	// HTTPClient allows over-riding http.Client e.g. to use custom
+	// http.RoundTripper, proxy or TLS config. It's used for all requests,
+	// including downloading files. If not set, we use a client with
+	// reasonable timeouts
 	HTTPClient *http.Client
 	// HTTPIntercept allows intercepting http requests
 	// e.g. to implement caching
@@ -46,9 +70,167 @@ type Client struct {
 	Logger io.Writer
 	// DebugLog enables debug logging
 	DebugLog bool
+	// LeveledLogger, if set, receives debug messages, warnings and errors.
+	// By default nothing is logged
+	LeveledLogger LeveledLogger
+	// RetryPolicy describes how failed requests are retried. If not set,
+	// DefaultRetryPolicy is used. Use &NoRetryPolicy to disable retries
+	RetryPolicy *RetryPolicy
+	// RateLimiter limits the rate of requests sent to the server. Share
+	// it between clients to limit the rate for all of them. If not set,
+	// we use a TokenBucket with DefaultRequestsPerSecond
+	RateLimiter RateLimiter
+	// MaxConcurrentRequests, if > 0, limits the number of http requests,
+	// including downloading files, in flight at the same time. A request
+	// is in flight until the body of its response is closed.
+	// DownloadPagesRecursively, Downloader and queries of collections
+	// don't download more things at the same time. Must be set before
+	// the first request
+	MaxConcurrentRequests int
+	// RequestTimeout limits the time of a single API request, including
+	// reading the response. Retries get a new timeout. Default is
+	// DefaultRequestTimeout, negative value disables the timeout
+	RequestTimeout time.Duration
+	// FileDownloadTimeout is like RequestTimeout but for downloading files,
+	// which can be large. Default is DefaultFileDownloadTimeout
+	FileDownloadTimeout time.Duration
+	// GzipRequests enables compressing large request bodies (e.g.
+	// submitTransaction with many operations). Responses are always
+	// decompressed if the server compressed them
+	GzipRequests bool
+	// BaseURL is the url of the API e.g. to use a debugging proxy.
+	// Default is DefaultBaseURL
+	BaseURL string
+	// Compat has switches for parts of the API that changed in
+	// incompatible ways
+	Compat Compat
+	// Metrics, if set, is told about every http request
+	Metrics Metrics
+	// Cassette, if set, records http requests and responses or replays
+	// previously recorded responses instead of talking to the server
+	Cassette *Cassette
+	// RecordCache, if set, keeps users and collections downloaded with
+	// the client so that pages share them and users missing in a page
+	// are found without a request to the server
+	RecordCache *RecordCache
+	// if true, no requests are sent over the network, they fail with
+	// ErrOffline. Responses replayed from Cassette or returned by
+	// HTTPIntercept still work
+	Offline bool
+	// Batcher, if set, combines operations of SubmitTransaction calls
+	// made at about the same time into fewer transactions
+	Batcher *TransactionBatcher
+	// UserAgent, if set, is sent as User-Agent header of all requests
+	// instead of the default that mimics a browser
+	UserAgent string
+	// Headers are added to all requests, including file downloads,
+	// replacing headers set by us with the same name
+	Headers http.Header
+	// BeforeRequest, if set, is called before sending every request,
+	// including retries, after all headers are set. It can modify the
+	// request e.g. to add a short-lived token
+	BeforeRequest func(*http.Request)
+
+	// switches turned on because of server responses
+	detectedCompat Compat
+	// totals returned by Stats
+	stats ClientStats
+	// id of the first workspace of the user, resolved when needed
+	defaultSpaceID string
+	// semaphore enforcing MaxConcurrentRequests, created when needed
+	requestSemOnce sync.Once
+	requestSem     chan struct{}
+}
+
+// we don't set http.Client.Timeout because it also limits the time of
+// reading the body and downloading large files can take a long time
+var defaultHTTPClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		// all api requests go to the same host so we keep enough idle
+		// connections to it for parallel downloads. Default is 2
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   32,
+		ForceAttemptHTTP2:     true,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	},
+}
+
+// prepareRequest applies Client.UserAgent, Client.Headers and
+// Client.BeforeRequest to req
+func (c *Client) prepareRequest(req *http.Request) {
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	for name, values := range c.Headers {
+		req.Header.Del(name)
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	if c.BeforeRequest != nil {
+		c.BeforeRequest(req)
+	}
+}
+
+func (c *Client) getHTTPClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return defaultHTTPClient
+}
+
+// joinURL joins base url and a path making sure there's exactly one
+// slash between them
+func joinURL(base string, path string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// apiEndpointURL returns full url of api endpoint given as
+// /api/v3/${name}
+func (c *Client) apiEndpointURL(apiURL string) string {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	return joinURL(base, strings.TrimPrefix(apiURL, apiPathPrefix))
 }
 
-func doNotionAPI(c *Client, apiURL string, requestData interface{}, result interface{}) error {
+// isAPIHost returns true if uri is on the same host as notion server or
+// api server, so that we can send cookies to it
+func (c *Client) isAPIHost(uri string) bool {
+	if strings.HasPrefix(uri, notionHost+"/") {
+		return true
+	}
+	if c.BaseURL == "" {
+		return false
+	}
+	api, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return false
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == api.Scheme && u.Host == api.Host
+}
+
+func doNotionAPI(ctx context.Context, c *Client, apiURL string, requestData interface{}, result interface{}) error {
+	canRetry := !nonIdempotentAPIs[apiURL] || c.getRetryPolicy().RetryTransactions
+	return doNotionAPIWithRetry(ctx, c, apiURL, requestData, result, canRetry)
+}
+
+// doNotionAPIWithRetry is like doNotionAPI but the caller decides if
+// the request can be retried
+func doNotionAPIWithRetry(ctx context.Context, c *Client, apiURL string, requestData interface{}, result interface{}, canRetry bool) error {
 	var js []byte
 	var err error
 	if requestData != nil {
@@ -57,68 +239,101 @@ func doNotionAPI(c *Client, apiURL string, requestData interface{}, result inter
 			return err
 		}
 	}
-	uri := notionHost + apiURL
-	body := bytes.NewBuffer(js)
+	uri := c.apiEndpointURL(apiURL)
 	log(c, "POST %s\n", uri)
 	if len(js) > 0 {
 		logJSON(c, js)
 	}
 
-	req, err := http.NewRequest("POST", uri, body)
-	if err != nil {
-		return err
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("User-Agent", userAgent)
-	req.Header.Set("Accept-Language", acceptLang)
-	if c.AuthToken != "" {
-		req.Header.Set("cookie", fmt.Sprintf("token_v2=%v", c.AuthToken))
-	}
-	var rsp *http.Response
-	if c.HTTPIntercept != nil {
-		rsp = c.HTTPIntercept.OnRequest(req)
-	}
-
-	realHTTPRequest := false
-	if rsp == nil {
-		realHTTPRequest = true
-		httpClient := c.HTTPClient
-		if httpClient == nil {
-			httpClient = http.DefaultClient
+	body, contentEncoding := c.gzipRequestBody(js)
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", uri, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Encoding", "gzip")
+		if contentEncoding != "" {
+			req.Header.Set("Content-Encoding", contentEncoding)
 		}
-		rsp, err = httpClient.Do(req)
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("Accept-Language", acceptLang)
+		if c.AuthToken != "" {
+			req.Header.Set("cookie", fmt.Sprintf("token_v2=%v", c.AuthToken))
+		}
+		return req, nil
 	}
-
+	rsp, err := c.doHTTP(ctx, newRequest, canRetry, c.getRequestTimeout())
 	if err != nil {
-		log(c, "http.DefaultClient.Do() failed with %s\n", err)
 		return err
 	}
-	if c.HTTPIntercept != nil && realHTTPRequest {
-		c.HTTPIntercept.OnResponse(rsp)
-	}
 	defer rsp.Body.Close()
 	if rsp.StatusCode != 200 {
 		d, _ := ioutil.ReadAll(rsp.Body)
-		log(c, "Error: status code %s\nBody:\n%s\n", rsp.Status, ppJSON(d))
-		return fmt.Errorf("http.Post('%s') returned non-200 status code of %d", uri, rsp.StatusCode)
+		logError(c, "Error: status code %s\nBody:\n%s\n", rsp.Status, ppJSON(d))
+		return newAPIError(uri, rsp.StatusCode, d)
+	}
+	if sd, ok := result.(streamDecoder); ok && !isLogging(c) {
+		return decodeStreamResponse(c, uri, rsp, sd)
 	}
 	d, err := ioutil.ReadAll(rsp.Body)
 	if err != nil {
-		log(c, "Error: ioutil.ReadAll() failed with %s\n", err)
+		logError(c, "Error: ioutil.ReadAll() failed with %s\n", err)
 		return err
 	}
 	logJSON(c, d)
 	err = json.Unmarshal(d, result)
 	if err != nil {
-		log(c, "Error: json.Unmarshal() failed with %s\n. Body:\n%s\n", err, string(d))
+		logError(c, "Error: json.Unmarshal() failed with %s\n. Body:\n%s\n", err, string(d))
+		// instead of an error, the server sometimes sends a login page
+		if isHTML(rsp, d) {
+			return fmt.Errorf("%s returned html page instead of json: %w", uri, ErrUnauthorized)
+		}
 	}
 	return err
 }
 
+func isHTML(rsp *http.Response, d []byte) bool {
+	if strings.HasPrefix(rsp.Header.Get("Content-Type"), "text/html") {
+		return true
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(d), []byte("<"))
+}
+
+// httpGet sends GET request with the same headers and cookies as API calls
+// so that private files can be accessed. The cookies are only sent to
+// notion server. Caller must close response body.
+func (c *Client) httpGet(ctx context.Context, uri string) (*http.Response, error) {
+	sendAuth := c.isAPIHost(uri)
+	log(c, "GET %s\n", uri)
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", uri, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("Accept-Language", acceptLang)
+		if sendAuth && c.AuthToken != "" {
+			req.Header.Set("cookie", fmt.Sprintf("token_v2=%v", c.AuthToken))
+		}
+		return req, nil
+	}
+	rsp, err := c.doHTTP(ctx, newRequest, true, c.getFileDownloadTimeout())
+	if err != nil {
+		return nil, err
+	}
+	if rsp.StatusCode != 200 {
+		discardBody(rsp)
+		return nil, &APIError{Endpoint: uri, StatusCode: rsp.StatusCode}
+	}
+	return rsp, nil
+}
+
 var segments = []int{8, 4, 4, 4}
 
 // NormalizeID converts 2131b10cebf64938a1277089ff02dbe4
-// 2131b10c-ebf6-4938-a127-7089ff02dbe4
+// 2131b10c-ebf6-4938-a127-7089ff02dbe4. It only checks the length, use
+// ToDashID to also validate the id
 func NormalizeID(s string) (string, bool) {
 	s = strings.Replace(s, "-", "", -1)
 	if len(s) != 32 {
@@ -134,17 +349,148 @@ func NormalizeID(s string) (string, bool) {
 	return strings.Join(parts[:], "-"), true
 }
 
-func resolveBlocks(block *Block, idToBlock map[string]*Block) error {
+// resolveBlocks parses properties and format of the block and its children.
+// Problems that don't prevent using the block, like invalid format, are
+// added to warnings, if not nil
+func resolveBlocks(block *Block, idToBlock map[string]*Block, warnings *[]*Warning) error {
+	err := resolveBlocksRec(block, idToBlock, warnings, map[string]bool{})
+	setParents(block, idToBlock)
+	setFactoryIDs(idToBlock)
+	setPositions(block)
+	return err
+}
+
+// setFactoryIDs sets FactoryID of blocks copied from templates of
+// BlockFactory blocks in idToBlock
+func setFactoryIDs(idToBlock map[string]*Block) {
+	templateToFactory := map[string]string{}
+	var addTemplates func(factoryID string, blocks []*Block)
+	addTemplates = func(factoryID string, blocks []*Block) {
+		for _, b := range blocks {
+			if b != nil && templateToFactory[b.ID] == "" {
+				templateToFactory[b.ID] = factoryID
+				addTemplates(factoryID, b.Content)
+			}
+		}
+	}
+	for _, b := range idToBlock {
+		if b != nil && b.Type == BlockFactory {
+			addTemplates(b.ID, b.TemplateContent)
+		}
+	}
+	if len(templateToFactory) == 0 {
+		return
+	}
+	for _, b := range idToBlock {
+		if b == nil {
+			continue
+		}
+		if id := templateToFactory[b.CopiedFromID()]; id != "" {
+			b.FactoryID = id
+		}
+	}
+}
+
+// setParents sets Parent of blocks in idToBlock. idToBlock might have
+// a different copy of root. Blocks whose parent we don't have get a stub
+func setParents(root *Block, idToBlock map[string]*Block) {
+	stubs := map[string]*Block{}
+	for _, b := range idToBlock {
+		if b == nil || b == root || b.ID == root.ID || b.ParentTable != TableBlock || b.ParentID == "" {
+			continue
+		}
+		if b.ParentID == root.ID {
+			b.Parent = root
+			continue
+		}
+		parent := idToBlock[b.ParentID]
+		if parent == nil {
+			parent = stubs[b.ParentID]
+			if parent == nil {
+				parent = &Block{ID: b.ParentID, IsStub: true}
+				stubs[b.ParentID] = parent
+			}
+		}
+		b.Parent = parent
+	}
+}
+
+// transclusionReferenceID returns id of BlockTransclusionContainer
+// that a BlockTransclusionReference points to
+func transclusionReferenceID(block *Block) string {
+	if block.Type != BlockTransclusionReference {
+		return ""
+	}
+	f := block.FormatTransclusionReference
+	if f == nil && len(block.FormatRaw) > 0 {
+		// format is parsed in resolveBlocks, which might not have happened yet
+		f = &FormatTransclusionReference{}
+		if block.DecodeFormat(f) != nil {
+			return ""
+		}
+	}
+	if f == nil || f.TransclusionReferencePointer == nil {
+		return ""
+	}
+	return f.TransclusionReferencePointer.ID
+}
+
+// resolveBlocksRec resolves block, inProgress are ids of blocks being
+// resolved i.e. the block's ancestors. Synced blocks can reference their
+// ancestors which would otherwise make us recurse forever
+func resolveBlocksRec(block *Block, idToBlock map[string]*Block, warnings *[]*Warning, inProgress map[string]bool) error {
 	err := parseProperties(block)
 	if err != nil {
 		return err
 	}
+	// invalid format is not fatal, the block just doesn't have it
 	err = parseFormat(block)
-	if err != nil {
-		return err
+	if err != nil && warnings != nil {
+		w := &Warning{
+			BlockID: block.ID,
+			Message: fmt.Sprintf("invalid format: %s, format: '%s'", err, string(block.FormatRaw)),
+			ParseWarning: &ParseWarning{
+				BlockID:   block.ID,
+				BlockType: block.Type,
+				Raw:       block.FormatRaw,
+				Err:       err,
+			},
+		}
+		*warnings = append(*warnings, w)
 	}
 
-	if block.Content != nil || len(block.ContentIDs) == 0 {
+	if block.IsUnknownType() && warnings != nil {
+		*warnings = append(*warnings, &Warning{
+			BlockID: block.ID,
+			Message: fmt.Sprintf("unknown block type '%s'", block.Type),
+		})
+	}
+	if block.Type == BlockAlias {
+		setAliasTarget(block, idToBlock[block.AliasPageID])
+	}
+	if block.Type == BlockTransclusionReference && block.Content == nil {
+		id := transclusionReferenceID(block)
+		container := idToBlock[id]
+		switch {
+		case container == nil:
+			// we don't have access to the original
+		case inProgress[id] || inProgress[block.ID]:
+			if warnings != nil {
+				*warnings = append(*warnings, &Warning{
+					BlockID: block.ID,
+					Message: fmt.Sprintf("synced block references its ancestor %s", id),
+				})
+			}
+		default:
+			inProgress[block.ID] = true
+			resolveBlocksRec(container, idToBlock, warnings, inProgress)
+			delete(inProgress, block.ID)
+			block.Content = container.Content
+		}
+		return nil
+	}
+
+	if block.Content != nil || block.TemplateContent != nil || len(block.ContentIDs) == 0 {
 		return nil
 	}
 	n := len(block.ContentIDs)
@@ -159,9 +505,24 @@ func resolveBlocks(block *Block, idToBlock map[string]*Block) error {
 			continue
 		}
 		block.Content[i] = resolved
-		resolveBlocks(resolved, idToBlock)
+		if resolved.Type == BlockPage && resolved.ParentID != "" && resolved.ParentID != block.ID {
+			// a link to a page that is a child of another block
+			resolved.AliasPageID = resolved.ID
+		}
+		if isListItem(resolved) && isListItem(block) {
+			resolved.ListNesting = block.ListNesting + 1
+		}
+		inProgress[block.ID] = true
+		resolveBlocksRec(resolved, idToBlock, warnings, inProgress)
+		delete(inProgress, block.ID)
 	}
 	// remove blocks that are not resolved
+	if len(notResolved) > 0 {
+		block.allContentIDs = append([]string{}, block.ContentIDs...)
+		for _, i := range notResolved {
+			block.MissingContentIDs = append(block.MissingContentIDs, block.ContentIDs[i])
+		}
+	}
 	for idx, toRemove := range notResolved {
 		i := toRemove - idx
 		{
@@ -173,9 +534,69 @@ func resolveBlocks(block *Block, idToBlock map[string]*Block) error {
 			block.Content = append(a[:i], a[i+1:]...)
 		}
 	}
+	setListIndexes(block.Content)
+	if block.Type == BlockColumnList {
+		setColumnRatios(block)
+	}
+	if block.Type == BlockFactory {
+		block.TemplateContent, block.Content = block.Content, nil
+	}
 	return nil
 }
 
+func isListItem(block *Block) bool {
+	return block.Type == BlockBulletedList || block.Type == BlockNumberedList
+}
+
+// setListIndexes sets ListIndex of numbered list items in blocks
+func setListIndexes(blocks []*Block) {
+	n := 0
+	for _, b := range blocks {
+		if b.Type != BlockNumberedList {
+			n = 0
+			continue
+		}
+		n++
+		if n == 1 && b.FormatList != nil && b.FormatList.ListStartIndex > 0 {
+			n = b.FormatList.ListStartIndex
+		}
+		b.ListIndex = n
+	}
+}
+
+// countColumns returns number of BlockColumn children of column list
+func countColumns(list *Block) int {
+	n := 0
+	for _, col := range list.Content {
+		if col != nil && col.Type == BlockColumn {
+			n++
+		}
+	}
+	return n
+}
+
+// columnRatio returns column_ratio of a column or, for columns that were
+// never resized and don't have it, 1/nColumns
+func columnRatio(col *Block, nColumns int) float64 {
+	if col.FormatColumn != nil && col.FormatColumn.ColumnRation > 0 {
+		return float64(col.FormatColumn.ColumnRation)
+	}
+	if nColumns < 1 {
+		nColumns = 1
+	}
+	return 1 / float64(nColumns)
+}
+
+// setColumnRatios sets EffectiveRatio of columns of BlockColumnList
+func setColumnRatios(list *Block) {
+	n := countColumns(list)
+	for _, col := range list.Content {
+		if col != nil && col.Type == BlockColumn {
+			col.EffectiveRatio = columnRatio(col, n)
+		}
+	}
+}
+
 // recursively find blocks that we don't have yet
 func findMissingBlocks(startIds []string, idToBlock map[string]*Block, blocksToSkip map[string]struct{}) []string {
 	var missing []string
@@ -203,6 +624,11 @@ func findMissingBlocks(startIds []string, idToBlock map[string]*Block, blocksToS
 		switch block.Type {
 		case BlockPage:
 		// skip those blocks
+		case BlockTransclusionReference:
+			// the content is in the original synced block
+			if id := transclusionReferenceID(block); id != "" {
+				toCheck = append(toCheck, id)
+			}
 		default:
 			toCheck = append(toCheck, block.ContentIDs...)
 		}
@@ -210,27 +636,158 @@ func findMissingBlocks(startIds []string, idToBlock map[string]*Block, blocksToS
 	return missing
 }
 
+// DownloadPageOptions allows customizing DownloadPageWithOptions
+type DownloadPageOptions struct {
+	// if true, rows of tables that are templates (see
+	// Collection.TemplateIDs) are included in Table.Data
+	IncludeTemplates bool
+	// maximum number of blocks requested in a single loadPageChunk call.
+	// Default is the same as notion website: 50 in first call, 30 in
+	// subsequent calls
+	ChunkSize int
+	// Progress, if set, is called after each request for page blocks with
+	// the number of blocks downloaded so far and, after the last one,
+	// with done set to true
+	Progress func(downloadedBlocks int, done bool)
+	// ProgressFunc, if set, is called when the download of the page starts
+	// and finishes and after each request for page blocks. It's called
+	// synchronously, from the goroutine downloading the page, so it should
+	// be fast. DownloadPagesRecursively calls it for every page, but never
+	// from multiple goroutines at the same time
+	ProgressFunc func(ev ProgressEvent)
+	// if true, blocks that were deleted but are still returned by the
+	// server (Block.Alive is false) are part of Content. By default
+	// they're skipped
+	IncludeDeleted bool
+	// if true, discussions and comments on blocks of the page are
+	// downloaded to Page.Discussions. It needs additional requests. Not
+	// supported with Client.IntegrationToken
+	IncludeDiscussions bool
+	// if true, Block.FormatRaw is released after it was decoded into
+	// a typed format (FormatPage, FormatText etc.), which uses less memory
+	// when keeping many pages. It's kept for blocks without a typed format
+	// and those whose format failed to decode. DecodeFormat and
+	// FormatValue of other blocks return nothing and DiffPages and
+	// SavePage don't see their format
+	DropFormatRaw bool
+	// if > 0, the download fails with *LimitExceededError as soon as
+	// the page has more blocks
+	MaxBlocks int
+	// maximum number of collection views (tables) queried at the same
+	// time. Default is DefaultQueryConcurrency or
+	// Client.MaxConcurrentRequests, if set. Requests are still limited by
+	// Client.RateLimiter
+	QueryConcurrency int
+}
+
+// checkMaxBlocks returns *LimitExceededError if a page has more than
+// MaxBlocks blocks
+func (opts *DownloadPageOptions) checkMaxBlocks(pageID string, blocks int) error {
+	if opts.MaxBlocks > 0 && blocks > opts.MaxBlocks {
+		return &LimitExceededError{
+			Limit:  "MaxBlocks",
+			Max:    opts.MaxBlocks,
+			PageID: pageID,
+		}
+	}
+	return nil
+}
+
+// collectionBlocks returns blocks whose collection views are resolved
+// when downloading the page: blocks at the top level of the page and, for
+// a full page database, the root
+func (p *Page) collectionBlocks() []*Block {
+	var res []*Block
+	if p.Root.Type == BlockCollectionViewPage {
+		res = append(res, p.Root)
+	}
+	for _, block := range p.Root.Content {
+		if block != nil {
+			res = append(res, block)
+		}
+	}
+	return res
+}
+
+// dropFormatRaw releases Block.FormatRaw of blocks that have a typed
+// format (see DownloadPageOptions.DropFormatRaw)
+func (p *Page) dropFormatRaw() {
+	for _, b := range p.blocks {
+		if b.hasTypedFormat() {
+			b.FormatRaw = nil
+		}
+	}
+}
+
 // DownloadPage returns Notion page data given its id
 func (c *Client) DownloadPage(pageID string) (*Page, error) {
-	normalizedPageID, ok := NormalizeID(pageID)
-	if !ok {
-		return nil, fmt.Errorf("%s is not a valid Notion page id", pageID)
+	return c.DownloadPageWithOptionsCtx(context.Background(), pageID, nil)
+}
+
+// DownloadPageCtx is like DownloadPage but can be canceled with ctx
+func (c *Client) DownloadPageCtx(ctx context.Context, pageID string) (*Page, error) {
+	return c.DownloadPageWithOptionsCtx(ctx, pageID, nil)
+}
+
+// DownloadPageWithOptions returns Notion page data given its id
+func (c *Client) DownloadPageWithOptions(pageID string, opts *DownloadPageOptions) (*Page, error) {
+	return c.DownloadPageWithOptionsCtx(context.Background(), pageID, opts)
+}
+
+// DownloadPageWithOptionsCtx is like DownloadPageWithOptions but can be
+// canceled with ctx. A page is downloaded with many requests and we stop
+// sending them as soon as ctx is done, returning ctx.Err()
+func (c *Client) DownloadPageWithOptionsCtx(ctx context.Context, pageID string, opts *DownloadPageOptions) (*Page, error) {
+	if opts == nil {
+		opts = &DownloadPageOptions{}
+	}
+	if opts.ProgressFunc == nil {
+		return c.downloadPage(ctx, pageID, opts)
+	}
+	p := &pageProgress{
+		fn:     opts.ProgressFunc,
+		pageID: pageID,
+	}
+	if id, err := pageIDArg(pageID); err == nil {
+		p.pageID = id
+	}
+	p.send(ProgressPageStarted, nil)
+	page, err := c.downloadPage(withPageProgress(ctx, p), pageID, opts)
+	p.send(ProgressPageFinished, err)
+	return page, err
+}
+
+func (c *Client) downloadPage(ctx context.Context, pageID string, opts *DownloadPageOptions) (*Page, error) {
+	normalizedPageID, err := pageIDArg(pageID)
+	if err != nil {
+		return nil, err
 	}
 	pageID = normalizedPageID
+	if c.IntegrationToken != "" {
+		return c.downloadPageOfficial(ctx, pageID, opts)
+	}
 
 	page := &Page{
 		client: c,
 	}
 
 	{
-		recVals, err := c.GetRecordValues([]string{pageID})
+		recVals, err := c.GetBlockRecordsCtx(ctx, []string{pageID})
 		if err != nil {
 			return nil, err
 		}
 		res := recVals.Results[0]
 		// this might happen e.g. when a page is not publicly visible
 		if res.Value == nil {
-			return nil, fmt.Errorf("Couldn't retrieve page with id %s", pageID)
+			if c.AuthToken == "" {
+				// without a token we can't tell a private page from
+				// a page that doesn't exist
+				return nil, fmt.Errorf("Couldn't retrieve page with id %s, it's not public or doesn't exist: %w (%w)", pageID, ErrUnauthorized, ErrNotFound)
+			}
+			if c.isCollectionViewID(ctx, pageID) {
+				return nil, fmt.Errorf("Couldn't retrieve page with id %s, it's id of a database view (?v= in the url), not a page. Use id of the database page: %w", pageID, ErrNotFound)
+			}
+			return nil, fmt.Errorf("Couldn't retrieve page with id %s: %w", pageID, ErrNotFound)
 		}
 		pageID = res.Value.ID
 		page.ID = pageID
@@ -246,13 +803,16 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 	chunkNo := 0
 	var cur *cursor
 	for {
-		rsp, err := c.LoadPageChunk(pageID, chunkNo, cur)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		rsp, err := c.loadPageChunk(ctx, pageID, chunkNo, cur, opts.ChunkSize)
 		chunkNo++
 		if err != nil {
 			return nil, err
 		}
 		for id, v := range rsp.RecordMap.Blocks {
-			if v.Value.Alive {
+			if v.Value.Alive || opts.IncludeDeleted {
 				idToBlock[id] = v.Value
 			} else {
 				blocksToSkip[id] = struct{}{}
@@ -260,7 +820,10 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 		}
 		for id, v := range rsp.RecordMap.Collections {
 			if v.Value.Alive {
-				idToCollection[id] = v.Value
+				// collections in RecordCache are shared with other
+				// pages so they must be parsed before sharing
+				parseCollection(v.Value)
+				idToCollection[id] = c.RecordCache.shareCollection(v.Value)
 			}
 			// TODO: what to do for not alive?
 		}
@@ -271,12 +834,23 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 			// TODO: what to do for not alive?
 		}
 		for id, v := range rsp.RecordMap.Users {
-			idToUser[id] = v.Value
+			idToUser[id] = c.RecordCache.shareUser(v.Value)
+		}
+		for id, v := range rsp.RecordMap.Bots {
+			if v.Value != nil {
+				v.Value.IsBot = true
+				idToUser[id] = c.RecordCache.shareUser(v.Value)
+			}
+		}
+
+		opts.chunkFetched(ctx, len(idToBlock))
+		if err := opts.checkMaxBlocks(pageID, len(idToBlock)); err != nil {
+			return nil, err
 		}
 
 		cursor := rsp.Cursor
 		//dbg("GetPaDownloadPagegeInfo: len(cursor.Stack)=%d\n", len(cursor.Stack))
-		if len(cursor.Stack) == 0 {
+		if cursor.isExhausted() {
 			break
 		}
 		cur = &rsp.Cursor
@@ -304,7 +878,10 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 				missing = nil
 			}
 
-			recVals, err := c.GetRecordValues(toGet)
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			recVals, err := c.GetBlockRecordsCtx(ctx, toGet)
 			if err != nil {
 				return nil, err
 			}
@@ -315,7 +892,7 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 				if block == nil {
 					expectedID := toGet[n]
 					blocksToSkip[expectedID] = struct{}{}
-					if n > 0 {
+					if n > 0 && recVals.Results[n-1].Value != nil {
 						prevBlock := recVals.Results[n-1]
 						prevBlockID := prevBlock.Value.ID
 						dbg(c, "block is nil at position n = %v with expected id %s. Prev block id: %s\n", n, expectedID, prevBlockID)
@@ -326,8 +903,23 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 				}
 
 				id := block.ID
+				if !block.Alive && !opts.IncludeDeleted {
+					blocksToSkip[id] = struct{}{}
+					continue
+				}
 				idToBlock[id] = block
 			}
+			// make sure we don't ask again for blocks the server
+			// didn't return, otherwise we would loop forever
+			for _, id := range toGet {
+				if _, ok := idToBlock[id]; !ok {
+					blocksToSkip[id] = struct{}{}
+				}
+			}
+			opts.chunkFetched(ctx, len(idToBlock))
+			if err := opts.checkMaxBlocks(pageID, len(idToBlock)); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -335,13 +927,30 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 		page.Users = append(page.Users, v)
 	}
 
-	err := resolveBlocks(page.Root, idToBlock)
+	err = resolveBlocks(page.Root, idToBlock, &page.Warnings)
 	if err != nil {
 		return nil, err
 	}
+	if hasBlockType(page.Root.Content, BlockBreadcrumb) {
+		page.Ancestors, err = c.getAncestors(ctx, page.Root)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// breadcrumb is not important enough to fail the download
+			page.Warnings = append(page.Warnings, &Warning{
+				BlockID: page.ID,
+				Message: fmt.Sprintf("failed to get ancestors for breadcrumb: %s", err),
+			})
+		}
+	}
+	for _, w := range page.Warnings {
+		logWarn(c, "DownloadPage: %s\n", w)
+	}
 
-	for _, block := range page.Root.Content {
-		if block.Type != BlockCollectionView {
+	var queries []*collectionQuery
+	for _, block := range page.collectionBlocks() {
+		if block.Type != BlockCollectionView && block.Type != BlockCollectionViewPage {
 			continue
 		}
 		if len(block.ViewIDs) == 0 {
@@ -354,37 +963,101 @@ func (c *Client) DownloadPage(pageID string) (*Page, error) {
 
 		collectionID := block.CollectionID
 		for _, collectionViewID := range block.ViewIDs {
-			user := page.Users[0]
 			collectionView, ok := idToCollectionView[collectionViewID]
 			if !ok {
 				return nil, fmt.Errorf("Didn't find collection_view with id '%s'", collectionViewID)
 			}
 			collection, ok := idToCollection[collectionID]
 			if !ok {
+				collection, _ = c.RecordCache.get(TableCollection, collectionID).(*Collection)
+			}
+			if collection == nil {
 				return nil, fmt.Errorf("Didn't find collection with id '%s'", collectionID)
 			}
-			var agg []*AggregateQuery
-			if collectionView.Query != nil {
-				agg = collectionView.Query.Aggregate
+			parseCollectionView(collectionView)
+			queries = append(queries, &collectionQuery{
+				block:            block,
+				collectionID:     collectionID,
+				collectionViewID: collectionViewID,
+				collection:       collection,
+				collectionView:   collectionView,
+				user:             page.Users[0],
+			})
+		}
+	}
+	c.queryCollections(ctx, queries, opts.QueryConcurrency)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, q := range queries {
+		collection := q.collection
+		collInfo := &CollectionViewInfo{
+			CollectionView: q.collectionView,
+			Collection:     collection,
+		}
+		table := &Table{
+			CollectionView: q.collectionView,
+			Collection:     collection,
+			Err:            q.err,
+			client:         c,
+		}
+		if q.err != nil {
+			// other tables are still useful
+			w := &Warning{
+				BlockID: q.block.ID,
+				Message: fmt.Sprintf("failed to query collection view %s: %s", q.collectionViewID, q.err),
 			}
-			res, err := c.QueryCollection(collectionID, collectionViewID, agg, user)
-			if err != nil {
-				return nil, err
+			logWarn(c, "DownloadPage: %s\n", w)
+			page.Warnings = append(page.Warnings, w)
+			q.block.CollectionViews = append(q.block.CollectionViews, collInfo)
+			page.Tables = append(page.Tables, table)
+			continue
+		}
+		res := q.res
+		for _, id := range res.Result.RowIDs() {
+			rowBlock, ok := res.RecordMap.Blocks[id]
+			if !ok {
+				return nil, fmt.Errorf("didn't find block with id '%s' for collection view with id '%s'", id, q.collectionViewID)
+			}
+			if !opts.IncludeTemplates && collection.isTemplate(rowBlock.Value) {
+				continue
 			}
-			blockIds := res.Result.BlockIDS
-			collInfo := &CollectionViewInfo{
-				CollectionView: collectionView,
-				Collection:     collection,
+			collInfo.CollectionRows = append(collInfo.CollectionRows, rowBlock.Value)
+		}
+		q.block.CollectionViews = append(q.block.CollectionViews, collInfo)
+		table.Data = collInfo.CollectionRows
+		for _, g := range res.Result.Groups() {
+			group := &TableGroup{
+				Type:  g.Type,
+				Value: g.Value,
 			}
-			for _, id := range blockIds {
+			for _, id := range g.BlockIDs {
 				rowBlock, ok := res.RecordMap.Blocks[id]
 				if !ok {
-					return nil, fmt.Errorf("didn't find block with id '%s' for collection view with id '%s'", id, collectionViewID)
+					continue
+				}
+				if !opts.IncludeTemplates && collection.isTemplate(rowBlock.Value) {
+					continue
 				}
-				collInfo.CollectionRows = append(collInfo.CollectionRows, rowBlock.Value)
+				group.Data = append(group.Data, rowBlock.Value)
 			}
-			block.CollectionViews = append(block.CollectionViews, collInfo)
+			table.Groups = append(table.Groups, group)
 		}
+		page.Tables = append(page.Tables, table)
+	}
+	page.setBlockIndex(idToBlock)
+	if opts.DropFormatRaw {
+		page.dropFormatRaw()
+	}
+	if opts.IncludeDiscussions {
+		page.Discussions, err = c.getDiscussions(ctx, page)
+		if err != nil {
+			return nil, err
+		}
+		page.discussionsLoaded = true
+	}
+	if opts.Progress != nil {
+		opts.Progress(len(idToBlock), true)
 	}
 	return page, nil
 }
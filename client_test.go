@@ -0,0 +1,566 @@
+package notionapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	getRecordValuesEmptyPageJSON = `{
+	"results": [
+		{
+			"role": "reader",
+			"value": {
+				"alive": true,
+				"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+				"parent_table": "block",
+				"type": "page",
+				"version": 1
+			}
+		}
+	]
+}`
+
+	loadPageChunkWithCursorJSON = `{
+	"recordMap": {},
+	"cursor": {
+		"stack": [[{"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", "index": 0, "table": "block"}]]
+	}
+}`
+)
+
+// syncRecordValues response for the same page as getRecordValuesEmptyPageJSON
+var syncRecordValuesEmptyPageJSON = toRecordMap(TableBlock, getRecordValuesEmptyPageJSON)
+
+// cancelingInterceptor answers API calls with canned responses and cancels
+// the context after the first loadPageChunk request
+type cancelingInterceptor struct {
+	cancel     func()
+	nChunkReqs int
+}
+
+func (i *cancelingInterceptor) OnRequest(r *http.Request) *http.Response {
+	s := toRecordMap(TableBlock, getRecordValuesJSON1)
+	if strings.HasSuffix(r.URL.Path, "/loadPageChunk") {
+		i.nChunkReqs++
+		i.cancel()
+		s = loadPageChunkWithCursorJSON
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(s)),
+	}
+}
+
+func (i *cancelingInterceptor) OnResponse(*http.Response) {
+}
+
+func TestDownloadPageCtxCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := &Client{}
+	_, err := c.DownloadPageCtx(ctx, "4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestDownloadPageCtxCanceledWhilePaginating(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	intercept := &cancelingInterceptor{cancel: cancel}
+	c := &Client{
+		HTTPIntercept: intercept,
+	}
+	_, err := c.DownloadPageCtx(ctx, "4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.Equal(t, context.Canceled, err)
+	// the cursor asks for more chunks but we must stop after the first one
+	assert.Equal(t, 1, intercept.nChunkReqs)
+}
+
+// recordingTransport is a http.RoundTripper that records urls of requests
+// and answers them with canned responses
+type recordingTransport struct {
+	urls []string
+	// response for getRecordValues, getRecordValuesEmptyPageJSON if not set
+	getRecordValues string
+}
+
+func (t *recordingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.urls = append(t.urls, r.URL.String())
+	s := `{"recordMap": {}, "cursor": {"stack": []}}`
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/getRecordValues"), strings.HasSuffix(r.URL.Path, "/syncRecordValues"):
+		s = getRecordValuesEmptyPageJSON
+		if t.getRecordValues != "" {
+			s = t.getRecordValues
+		}
+		if strings.HasSuffix(r.URL.Path, "/syncRecordValues") {
+			s = toRecordMap(TableBlock, s)
+		}
+	case r.Method == "GET":
+		s = "file content"
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(s)),
+		Request:    r,
+	}, nil
+}
+
+func TestCustomHTTPClient(t *testing.T) {
+	transport := &recordingTransport{}
+	c := &Client{
+		HTTPClient: &http.Client{Transport: transport},
+	}
+	_, err := c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	file, err := NewDownloader(c).Get("https://example.com/image.png", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "file content", string(file.Data))
+	exp := []string{
+		"https://www.notion.so/api/v3/syncRecordValues",
+		"https://www.notion.so/api/v3/loadPageChunk",
+		"https://example.com/image.png",
+	}
+	assert.Equal(t, exp, transport.urls)
+}
+
+func TestDownloadPageWithGetRecordValues(t *testing.T) {
+	transport := &recordingTransport{}
+	c := &Client{
+		HTTPClient: &http.Client{Transport: transport},
+		Compat:     Compat{UseGetRecordValues: true},
+	}
+	page, err := c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	assert.Equal(t, "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", page.ID)
+	exp := []string{
+		"https://www.notion.so/api/v3/getRecordValues",
+		"https://www.notion.so/api/v3/loadPageChunk",
+	}
+	assert.Equal(t, exp, transport.urls)
+}
+
+type testLogger struct {
+	debug []string
+	warn  []string
+	err   []string
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) {
+	l.debug = append(l.debug, fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Warnf(format string, args ...interface{}) {
+	l.warn = append(l.warn, fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Errorf(format string, args ...interface{}) {
+	l.err = append(l.err, fmt.Sprintf(format, args...))
+}
+
+func TestInvalidFormatWarning(t *testing.T) {
+	pageJSON := `{
+	"results": [
+		{
+			"role": "reader",
+			"value": {
+				"alive": true,
+				"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+				"format": "not an object",
+				"type": "page",
+				"version": 1
+			}
+		}
+	]
+}`
+	transport := &recordingTransport{getRecordValues: pageJSON}
+	logger := &testLogger{}
+	c := &Client{
+		HTTPClient:    &http.Client{Transport: transport},
+		LeveledLogger: logger,
+	}
+	page, err := c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(page.Warnings))
+	assert.Equal(t, "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", page.Warnings[0].BlockID)
+	assert.Equal(t, 1, len(logger.warn))
+	assert.True(t, strings.Contains(logger.warn[0], "invalid format"))
+	assert.Equal(t, 0, len(logger.err))
+	assert.True(t, len(logger.debug) > 0)
+
+	assert.Nil(t, page.Root.FormatPage)
+	warnings := page.ParseWarnings()
+	assert.Equal(t, 1, len(warnings))
+	assert.Equal(t, BlockPage, warnings[0].BlockType)
+	assert.Equal(t, `"not an object"`, string(warnings[0].Raw))
+	assert.Error(t, warnings[0].Err)
+}
+
+// staticTransport answers every request with the same response
+type staticTransport struct {
+	statusCode int
+	body       string
+	// bodies of received requests
+	requests []string
+}
+
+func (t *staticTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, _ := readRequestBody(r)
+	t.requests = append(t.requests, body)
+	code := t.statusCode
+	if code == 0 {
+		code = 200
+	}
+	return &http.Response{
+		StatusCode: code,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(t.body)),
+		Request:    r,
+	}, nil
+}
+
+func newStaticClient(statusCode int, body string) (*Client, *staticTransport) {
+	transport := &staticTransport{statusCode: statusCode, body: body}
+	c := &Client{
+		AuthToken:   "token",
+		HTTPClient:  &http.Client{Transport: transport},
+		RetryPolicy: &NoRetryPolicy,
+	}
+	return c, transport
+}
+
+func TestJoinURL(t *testing.T) {
+	assert.Equal(t, "https://a.com/api/v3/search", joinURL("https://a.com/api/v3", "search"))
+	assert.Equal(t, "https://a.com/api/v3/search", joinURL("https://a.com/api/v3/", "/search"))
+	assert.Equal(t, "https://a.com/api/v3/search", joinURL("https://a.com/api/v3//", "search"))
+}
+
+func TestBaseURL(t *testing.T) {
+	var paths []string
+	var cookies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		cookies = append(cookies, r.Header.Get("cookie"))
+		if strings.HasSuffix(r.URL.Path, "/syncRecordValues") {
+			w.Write([]byte(syncRecordValuesEmptyPageJSON))
+			return
+		}
+		w.Write([]byte(`{"recordMap": {}, "cursor": {"stack": []}}`))
+	}))
+	defer server.Close()
+
+	c := &Client{
+		AuthToken: "token",
+		BaseURL:   server.URL + "/api/v3/",
+	}
+	assert.Equal(t, server.URL+"/api/v3/loadPageChunk", c.apiEndpointURL("/api/v3/loadPageChunk"))
+	page, err := c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	assert.Equal(t, "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", page.ID)
+	assert.Equal(t, []string{"/api/v3/syncRecordValues", "/api/v3/loadPageChunk"}, paths)
+	assert.Equal(t, []string{"token_v2=token", "token_v2=token"}, cookies)
+
+	// files from api server get cookies, other files don't
+	_, err = c.DownloadFile(server.URL+"/file.png", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "token_v2=token", cookies[2])
+}
+
+func TestImageProxyURL(t *testing.T) {
+	prev := ImageProxyURL
+	defer func() {
+		ImageProxyURL = prev
+	}()
+	ImageProxyURL = "https://proxy.example.com/image"
+	assert.Equal(t, "https://proxy.example.com/image/https:%2F%2Fwww.notion.so%2Fimages%2Fcover.jpg", makeImageURL("/images/cover.jpg"))
+	assert.Equal(t, "https://proxy.example.com/image/foo", makeImageURL("https://proxy.example.com/image/foo"))
+}
+
+const publicPageChunkJSON = `{
+	"recordMap": {
+		"block": {
+			"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d": {
+				"role": "reader",
+				"value": {
+					"alive": true,
+					"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+					"parent_table": "space",
+					"type": "page",
+					"version": 1,
+					"properties": { "title": [["Public page"]] },
+					"content": ["00000000-0000-0000-0000-000000000001"]
+				}
+			},
+			"00000000-0000-0000-0000-000000000001": {
+				"role": "reader",
+				"value": {
+					"alive": true,
+					"id": "00000000-0000-0000-0000-000000000001",
+					"parent_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+					"parent_table": "block",
+					"type": "text",
+					"version": 1,
+					"properties": { "title": [["hello"]] }
+				}
+			}
+		}
+	},
+	"cursor": { "stack": [] }
+}`
+
+// anonymousTransport serves a public page and records cookies
+type anonymousTransport struct {
+	cookies []string
+	// response for syncRecordValues
+	syncRecordValues string
+	contentType      string
+}
+
+func (t *anonymousTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.cookies = append(t.cookies, r.Header.Get("cookie"))
+	s := publicPageChunkJSON
+	if strings.HasSuffix(r.URL.Path, "/syncRecordValues") {
+		s = t.syncRecordValues
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{t.contentType}},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(s)),
+		Request:    r,
+	}, nil
+}
+
+func TestDownloadPublicPage(t *testing.T) {
+	transport := &anonymousTransport{syncRecordValues: publicPageChunkJSON, contentType: "application/json"}
+	c := &Client{
+		HTTPClient: &http.Client{Transport: transport},
+	}
+	page, err := c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	assert.Equal(t, "Public page", page.Root.Title)
+	assert.Equal(t, 1, len(page.Root.Content))
+	assert.Equal(t, "hello", page.Root.Content[0].InlineContent[0].Text)
+	// cookie is not sent at all
+	assert.Equal(t, []string{"", ""}, transport.cookies)
+
+	// the server doesn't return private pages to anonymous users
+	transport.syncRecordValues = `{"recordMap":{"block":{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d":{"role":"none"}}}}`
+	_, err = c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.True(t, IsUnauthorized(err))
+	assert.True(t, IsNotFound(err))
+
+	transport.syncRecordValues = "<html><body>Log in</body></html>"
+	transport.contentType = "text/html; charset=utf-8"
+	_, err = c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.True(t, IsUnauthorized(err))
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+}
+
+func TestCustomHeaders(t *testing.T) {
+	var userAgents []string
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		tokens = append(tokens, strings.Join(r.Header.Values("X-Gateway-Token"), ","))
+		if strings.HasSuffix(r.URL.Path, "/syncRecordValues") {
+			w.Write([]byte(syncRecordValuesEmptyPageJSON))
+			return
+		}
+		w.Write([]byte(`{"recordMap": {}, "cursor": {"stack": []}}`))
+	}))
+	defer server.Close()
+
+	n := 0
+	c := &Client{
+		BaseURL:   server.URL + "/api/v3",
+		UserAgent: "notion-backup/1.0",
+		Headers:   http.Header{"X-Gateway-Token": []string{"static"}},
+		BeforeRequest: func(r *http.Request) {
+			n++
+			r.Header.Add("X-Gateway-Token", fmt.Sprintf("dynamic-%d", n))
+		},
+	}
+	_, err := c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	_, err = c.DownloadFile(server.URL+"/file.png", nil)
+	assert.NoError(t, err)
+	exp := []string{"notion-backup/1.0", "notion-backup/1.0", "notion-backup/1.0"}
+	assert.Equal(t, exp, userAgents)
+	exp = []string{"static,dynamic-1", "static,dynamic-2", "static,dynamic-3"}
+	assert.Equal(t, exp, tokens)
+}
+
+func TestDownloadPageSkipsDeletedBlocks(t *testing.T) {
+	pageJSON := `{
+	"recordMap": {
+		"block": {
+			"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d": {
+				"role": "reader",
+				"value": {
+					"alive": true,
+					"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+					"type": "page",
+					"content": ["a1b1c1d1-0000-4000-8000-000000000001", "a1b1c1d1-0000-4000-8000-000000000002"],
+					"properties": { "title": [["Page"]] },
+					"version": 1
+				}
+			},
+			"a1b1c1d1-0000-4000-8000-000000000001": {
+				"role": "reader",
+				"value": {
+					"alive": true,
+					"id": "a1b1c1d1-0000-4000-8000-000000000001",
+					"parent_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+					"parent_table": "block",
+					"type": "text",
+					"properties": { "title": [["visible"]] },
+					"version": 1
+				}
+			},
+			"a1b1c1d1-0000-4000-8000-000000000002": {
+				"role": "reader",
+				"value": {
+					"alive": false,
+					"id": "a1b1c1d1-0000-4000-8000-000000000002",
+					"parent_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+					"parent_table": "block",
+					"type": "text",
+					"properties": { "title": [["trashed"]] },
+					"version": 1
+				}
+			}
+		}
+	},
+	"cursor": { "stack": [] }
+}`
+	c, _ := newStaticClient(200, pageJSON)
+	page, err := c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(page.Root.Content))
+	child := page.Root.Content[0]
+	assert.Equal(t, page.Root, child.Parent)
+	assert.Nil(t, page.Root.Parent)
+	got := string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "visible")
+	assert.NotContains(t, got, "trashed")
+
+	opts := &DownloadPageOptions{IncludeDeleted: true}
+	page, err = c.DownloadPageWithOptions("4c6a54c68b3e4ea2af9cfaabcc88d58d", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(page.Root.Content))
+	assert.False(t, page.Root.Content[1].Alive)
+}
+
+// formatPageJSON returns loadPageChunk response with a page with n text
+// blocks that have format, a block of unknown type and a block with
+// format that fails to decode
+func formatPageJSON(n int) []byte {
+	const pageID = "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"
+	blocks := map[string]interface{}{}
+	var content []string
+	add := func(id string, typ string, format interface{}) {
+		content = append(content, id)
+		blocks[id] = map[string]interface{}{
+			"role": RoleReader,
+			"value": map[string]interface{}{
+				"alive": true, "id": id, "type": typ, "version": 1,
+				"parent_id": pageID, "parent_table": TableBlock,
+				"properties": map[string]interface{}{
+					"title": [][]string{{fmt.Sprintf("Paragraph %s", id)}},
+				},
+				"format": format,
+			},
+		}
+	}
+	for i := 0; i < n; i++ {
+		add(fmt.Sprintf("0d6b5fcc-3f80-4b8c-8b76-%012d", i), BlockText, map[string]interface{}{
+			"block_color":    "red_background",
+			"copied_from_id": "d2b7a38b-6a4c-4b4e-8d83-5f0e5a9f8a77",
+		})
+	}
+	add("1d6b5fcc-3f80-4b8c-8b76-000000000001", "future_block", map[string]interface{}{"future": true})
+	add("1d6b5fcc-3f80-4b8c-8b76-000000000002", BlockText, map[string]interface{}{"block_color": 5})
+	blocks[pageID] = map[string]interface{}{
+		"role": RoleReader,
+		"value": map[string]interface{}{
+			"alive": true, "id": pageID, "type": BlockPage, "version": 1, "content": content,
+			"format": map[string]interface{}{"page_icon": "🎉", "page_full_width": true},
+		},
+	}
+	d, _ := json.Marshal(map[string]interface{}{
+		"recordMap": map[string]interface{}{"block": blocks},
+		"cursor":    map[string]interface{}{"stack": []interface{}{}},
+	})
+	return d
+}
+
+func TestDownloadPageDropFormatRaw(t *testing.T) {
+	c, _ := newStaticClient(200, string(formatPageJSON(2)))
+	page, err := c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	// by default FormatRaw is kept
+	for _, b := range page.Root.Content {
+		assert.NotEmpty(t, b.FormatRaw)
+	}
+
+	opts := &DownloadPageOptions{DropFormatRaw: true}
+	page, err = c.DownloadPageWithOptions("4c6a54c68b3e4ea2af9cfaabcc88d58d", opts)
+	assert.NoError(t, err)
+	assert.Empty(t, page.Root.FormatRaw)
+	assert.Equal(t, "🎉", page.Root.FormatPage.PageIcon)
+	text := page.Root.Content[0]
+	assert.Empty(t, text.FormatRaw)
+	assert.Equal(t, ColorRedBackground, text.BlockColor())
+	unknown := page.BlockByID("1d6b5fcc-3f80-4b8c-8b76-000000000001")
+	v, ok := unknown.FormatValue("future")
+	assert.True(t, ok)
+	assert.Equal(t, true, v)
+	invalid := page.BlockByID("1d6b5fcc-3f80-4b8c-8b76-000000000002")
+	assert.Nil(t, invalid.FormatText)
+	assert.NotEmpty(t, invalid.FormatRaw)
+}
+
+// measures memory retained by a downloaded page with and without
+// DownloadPageOptions.DropFormatRaw
+func BenchmarkDownloadPageFormatRaw(b *testing.B) {
+	d := formatPageJSON(5000)
+	bench := func(b *testing.B, opts *DownloadPageOptions) {
+		c := &Client{
+			HTTPClient:  &http.Client{Transport: &largePageTransport{body: d}},
+			RateLimiter: NewTokenBucket(1e9, 1000),
+		}
+		var retained uint64
+		for i := 0; i < b.N; i++ {
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+			page, err := c.DownloadPageWithOptions("4c6a54c68b3e4ea2af9cfaabcc88d58d", opts)
+			if err != nil {
+				b.Fatal(err)
+			}
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			retained += after.HeapAlloc - before.HeapAlloc
+			runtime.KeepAlive(page)
+		}
+		b.ReportMetric(float64(retained)/float64(b.N), "retained-bytes/op")
+	}
+	b.Run("keep", func(b *testing.B) {
+		bench(b, nil)
+	})
+	b.Run("drop", func(b *testing.B) {
+		bench(b, &DownloadPageOptions{DropFormatRaw: true})
+	})
+}
@@ -0,0 +1,125 @@
+package notionapi
+
+import "reflect"
+
+// CloneOptions describes options for Page.CloneWithOptions
+type CloneOptions struct {
+	// if true, the copy doesn't have the client the page was downloaded
+	// with, so it's like a page loaded with LoadPage
+	WithoutClient bool
+}
+
+// cloner makes deep copies of values. A pointer that is in many places
+// (e.g. a block that is in Content of its parent and in Parent of its
+// children) is copied once so the copy has the same shape as the original
+type cloner struct {
+	pointers map[clonedPointer]reflect.Value
+}
+
+type clonedPointer struct {
+	typ  reflect.Type
+	addr uintptr
+}
+
+// clone returns a deep copy of v. Unexported fields of structs are not
+// copied deeply, only assigned
+func (c *cloner) clone(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		key := clonedPointer{typ: v.Type(), addr: v.Pointer()}
+		if res, ok := c.pointers[key]; ok {
+			return res
+		}
+		res := reflect.New(v.Type().Elem())
+		c.pointers[key] = res
+		res.Elem().Set(c.clone(v.Elem()))
+		return res
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.Set(c.clone(v.Elem()))
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return res
+	case reflect.Array:
+		res := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(c.clone(v.Index(i)))
+		}
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			res.SetMapIndex(iter.Key(), c.clone(iter.Value()))
+		}
+		return res
+	case reflect.Struct:
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath != "" {
+				// unexported
+				continue
+			}
+			res.Field(i).Set(c.clone(v.Field(i)))
+		}
+		return res
+	}
+	return v
+}
+
+func (c *cloner) cloneBlock(b *Block) *Block {
+	return c.clone(reflect.ValueOf(b)).Interface().(*Block)
+}
+
+// Clone returns a deep copy of the page: blocks with their properties and
+// formats, tables and users. Parent, Content etc. of blocks in the copy
+// point to blocks in the copy so it can be changed without affecting the
+// original. The copy uses the same client
+func (p *Page) Clone() *Page {
+	return p.CloneWithOptions(nil)
+}
+
+// CloneWithOptions is like Clone but with options
+func (p *Page) CloneWithOptions(opts *CloneOptions) *Page {
+	if opts == nil {
+		opts = &CloneOptions{}
+	}
+	c := &cloner{
+		pointers: map[clonedPointer]reflect.Value{},
+	}
+	res := c.clone(reflect.ValueOf(p)).Interface().(*Page)
+	if p.blocks != nil {
+		res.blocks = map[string]*Block{}
+		for id, b := range p.blocks {
+			res.blocks[id] = c.cloneBlock(b)
+		}
+		res.orphans = map[string]bool{}
+		for id := range p.orphans {
+			res.orphans[id] = true
+		}
+	}
+	if opts.WithoutClient {
+		res.client = nil
+		for _, t := range res.Tables {
+			t.client = nil
+		}
+	}
+	return res
+}
@@ -0,0 +1,58 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageClone(t *testing.T) {
+	page := testTraversePage()
+	text := page.Root.Content[0]
+	text.Parent = page.Root
+	text.Content[0].Parent = text
+	text.Properties = map[string]interface{}{
+		"title": []interface{}{[]interface{}{"text", []interface{}{[]interface{}{"b"}}}},
+	}
+	text.InlineContent = []*InlineBlock{{Text: "text", AttrFlags: AttrBold}}
+	color := ColorRed
+	page.Root.FormatPage = &FormatPage{BlockColor: &color}
+	cv := page.Root.Content[2]
+	page.Tables = []*Table{{Data: cv.CollectionViews[0].CollectionRows, client: &Client{}}}
+	page.Users = []*User{{ID: "user"}}
+	page.client = &Client{}
+	page.setBlockIndex(map[string]*Block{"orphan": {ID: "orphan"}})
+
+	clone := page.Clone()
+	assert.Equal(t, page.Root, clone.Root)
+	assert.True(t, page.Root != clone.Root)
+	assert.Equal(t, page.client, clone.client)
+
+	ctext := clone.Root.Content[0]
+	assert.True(t, ctext != text)
+	assert.Equal(t, ctext, ctext.Content[0].Parent)
+	assert.Equal(t, clone.Root, ctext.Parent)
+
+	// changes in the copy don't affect the original
+	ctext.Properties["title"].([]interface{})[0].([]interface{})[0] = "changed"
+	ctext.InlineContent[0].Text = "changed"
+	*clone.Root.FormatPage.BlockColor = ColorBlue
+	clone.Users[0].ID = "other"
+	assert.Equal(t, "text", text.Properties["title"].([]interface{})[0].([]interface{})[0])
+	assert.Equal(t, "text", text.InlineContent[0].Text)
+	assert.Equal(t, ColorRed, *page.Root.FormatPage.BlockColor)
+	assert.Equal(t, "user", page.Users[0].ID)
+
+	// rows shared by tables and views are the same block in the copy
+	crow := clone.Tables[0].Data[0]
+	assert.True(t, crow != page.Tables[0].Data[0])
+	assert.True(t, crow == clone.Root.Content[2].CollectionViews[1].CollectionRows[0])
+	assert.True(t, crow == clone.BlockByID("row1"))
+	assert.True(t, clone.IsOrphan("orphan"))
+	assert.True(t, clone.BlockByID("orphan") != page.BlockByID("orphan"))
+
+	clone = page.CloneWithOptions(&CloneOptions{WithoutClient: true})
+	assert.Nil(t, clone.client)
+	assert.Nil(t, clone.Tables[0].client)
+	assert.NotNil(t, page.Tables[0].client)
+}
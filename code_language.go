@@ -0,0 +1,127 @@
+package notionapi
+
+import "strings"
+
+// maps lowercased names of languages used by Notion in code blocks to names
+// understood by syntax highlighters like highlight.js or chroma. Languages
+// whose lowercased name is already a valid highlighter name are not listed
+var codeLanguageToHighlightName = map[string]string{
+	"c++":          "cpp",
+	"c#":           "csharp",
+	"f#":           "fsharp",
+	"plain text":   "plaintext",
+	"shell":        "bash",
+	"objective-c":  "objectivec",
+	"vb.net":       "vbnet",
+	"visual basic": "vbnet",
+	"docker":       "dockerfile",
+	"markup":       "xml",
+	"flow":         "javascript",
+	"reason":       "reasonml",
+	"webassembly":  "wasm",
+}
+
+// CodeLanguageToHighlightName converts a language of a code block (e.g.
+// "C++" or "Plain Text") to a name used by syntax highlighters (e.g. "cpp"
+// or "plaintext"). Unknown languages are lowercased with spaces converted
+// to dashes
+func CodeLanguageToHighlightName(notionLang string) string {
+	return codeLanguageName(notionLang, nil)
+}
+
+// codeLanguageName is like CodeLanguageToHighlightName but first checks
+// overrides, which are keyed by Notion language name
+func codeLanguageName(notionLang string, overrides map[string]string) string {
+	if v, ok := overrides[notionLang]; ok {
+		return v
+	}
+	lang := strings.ToLower(strings.TrimSpace(notionLang))
+	for k, v := range overrides {
+		if strings.ToLower(k) == lang {
+			return v
+		}
+	}
+	if v, ok := codeLanguageToHighlightName[lang]; ok {
+		return v
+	}
+	return strings.Join(strings.Fields(lang), "-")
+}
+
+// names of languages of code blocks, as used by Notion
+var notionCodeLanguages = []string{
+	"ABAP", "Arduino", "Bash", "BASIC", "C", "Clojure", "CoffeeScript",
+	"C++", "C#", "CSS", "Dart", "Diff", "Docker", "Elixir", "Elm", "Erlang",
+	"Flow", "Fortran", "F#", "Gherkin", "GLSL", "Go", "GraphQL", "Groovy",
+	"Haskell", "HTML", "Java", "JavaScript", "JSON", "Julia", "Kotlin",
+	"LaTeX", "Less", "Lisp", "LiveScript", "Lua", "Makefile", "Markdown",
+	"Markup", "MATLAB", "Mermaid", "Nix", "Objective-C", "OCaml", "Pascal",
+	"Perl", "PHP", "Plain Text", "PowerShell", "Prolog", "Protobuf", "Python",
+	"R", "Reason", "Ruby", "Rust", "Sass", "Scala", "Scheme", "SCSS",
+	"Shell", "SQL", "Swift", "TypeScript", "VB.Net", "Verilog", "VHDL",
+	"Visual Basic", "WebAssembly", "XML", "YAML",
+}
+
+// CodeLanguageFromHighlightName is the reverse of CodeLanguageToHighlightName.
+// It converts a name used by syntax highlighters (e.g. "cpp") to a language
+// of Notion code block (e.g. "C++"). Returns "Plain Text" for empty name and
+// name itself if it's not a known language
+func CodeLanguageFromHighlightName(name string) string {
+	lang := strings.ToLower(strings.TrimSpace(name))
+	if lang == "" {
+		return "Plain Text"
+	}
+	for _, notionLang := range notionCodeLanguages {
+		if strings.ToLower(notionLang) == lang {
+			return notionLang
+		}
+	}
+	for _, notionLang := range notionCodeLanguages {
+		if CodeLanguageToHighlightName(notionLang) == lang {
+			return notionLang
+		}
+	}
+	return name
+}
+
+// CodeBlock is a BlockCode with its full code and language
+type CodeBlock struct {
+	Block *Block
+	// all of the code. Block.Code is only the first part if the code has
+	// formatting or is long
+	Code string
+	// Block.CodeLanguage converted with CodeLanguageToHighlightName
+	Language string
+}
+
+// blockCode returns code of BlockCode with all the inline parts
+func blockCode(b *Block) string {
+	if inline := getInlineProp(b, "title"); inline != nil {
+		return InlineToText(inline)
+	}
+	return b.Code
+}
+
+// CodeBlocks returns code blocks of the page, in document order. If
+// languages are given, only code blocks in those languages are returned.
+// Languages are compared after CodeLanguageToHighlightName so "C++" and
+// "cpp" are the same
+func (p *Page) CodeBlocks(languages ...string) []*CodeBlock {
+	want := map[string]bool{}
+	for _, lang := range languages {
+		want[CodeLanguageToHighlightName(lang)] = true
+	}
+	var res []*CodeBlock
+	for _, b := range p.BlocksOfType(BlockCode) {
+		lang := CodeLanguageToHighlightName(b.CodeLanguage)
+		if len(want) > 0 && !want[lang] {
+			continue
+		}
+		code := &CodeBlock{
+			Block:    b,
+			Code:     blockCode(b),
+			Language: lang,
+		}
+		res = append(res, code)
+	}
+	return res
+}
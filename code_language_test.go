@@ -0,0 +1,79 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeLanguageToHighlightName(t *testing.T) {
+	var tests = []struct {
+		s   string
+		exp string
+	}{
+		{"C++", "cpp"},
+		{"Plain Text", "plaintext"},
+		{"F#", "fsharp"},
+		{"Shell", "bash"},
+		{"Go", "go"},
+		{"JavaScript", "javascript"},
+		{"Some New Lang", "some-new-lang"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		got := CodeLanguageToHighlightName(test.s)
+		assert.Equal(t, test.exp, got, "s: %s", test.s)
+	}
+	overrides := map[string]string{
+		"shell": "sh",
+	}
+	assert.Equal(t, "sh", codeLanguageName("Shell", overrides))
+	assert.Equal(t, "cpp", codeLanguageName("C++", overrides))
+}
+
+func TestCodeLanguageFromHighlightName(t *testing.T) {
+	var tests = []struct {
+		s   string
+		exp string
+	}{
+		{"cpp", "C++"},
+		{"go", "Go"},
+		{"bash", "Bash"},
+		{"plaintext", "Plain Text"},
+		{"csharp", "C#"},
+		{"", "Plain Text"},
+		{"brainfuck", "brainfuck"},
+	}
+	for _, test := range tests {
+		got := CodeLanguageFromHighlightName(test.s)
+		assert.Equal(t, test.exp, got, "s: %s", test.s)
+	}
+}
+
+func TestCodeBlocks(t *testing.T) {
+	goCode := parseTestBlock(t, `{
+		"id": "go",
+		"type": "code",
+		"properties": {
+			"title": [["package main\n"], ["func", [["b"]]], [" main() {}"]],
+			"language": [["Go"]]
+		}
+	}`)
+	cpp := &Block{ID: "cpp", Type: BlockCode, Code: "int main();", CodeLanguage: "C++"}
+	page := testPage(goCode, textBlock(BlockToggle, "toggle", cpp), textBlock(BlockText, "text"))
+
+	all := page.CodeBlocks()
+	assert.Equal(t, 2, len(all))
+	assert.Equal(t, "package main\nfunc main() {}", all[0].Code)
+	assert.Equal(t, "go", all[0].Language)
+	assert.Equal(t, goCode, all[0].Block)
+	assert.Equal(t, "int main();", all[1].Code)
+	assert.Equal(t, "cpp", all[1].Language)
+
+	got := page.CodeBlocks("Go", "python")
+	assert.Equal(t, 1, len(got))
+	assert.Equal(t, "go", got[0].Block.ID)
+	got = page.CodeBlocks("cpp")
+	assert.Equal(t, 1, len(got))
+	assert.Equal(t, "cpp", got[0].Block.ID)
+}
@@ -0,0 +1,112 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// Compat has switches for parts of the API that changed in incompatible
+// ways. Notion doesn't announce changes to its (unofficial) API so when
+// it breaks something, changing one of those is often enough to make the
+// library work again without waiting for a new version.
+// The default (zero value) uses the newest API we know about and adapts
+// to what the server returns
+type Compat struct {
+	// UseGetRecordValues makes the client get records with deprecated
+	// /api/v3/getRecordValues api instead of /api/v3/syncRecordValues
+	UseGetRecordValues bool
+	// UseLoadCachedPageChunk makes the client get page content with
+	// /api/v3/loadCachedPageChunk instead of /api/v3/loadPageChunk
+	UseLoadCachedPageChunk bool
+	// UseReducerQuery makes the client send queryCollection requests with
+	// "reducer" loader (used by newer versions of notion website) instead
+	// of "table" loader. Both kinds of responses are understood regardless
+	UseReducerQuery bool
+	// DisableAutoDetect disables switching to a different api when the
+	// server rejects a request or sends a response in a different shape.
+	// Only the switches above are used
+	DisableAutoDetect bool
+}
+
+// protects Client.detectedCompat
+var compatMu sync.Mutex
+
+// getCompat returns switches to use, which are Client.Compat and those we
+// detected from server responses
+func (c *Client) getCompat() Compat {
+	compatMu.Lock()
+	defer compatMu.Unlock()
+	res := c.Compat
+	if res.DisableAutoDetect {
+		return res
+	}
+	d := c.detectedCompat
+	res.UseGetRecordValues = res.UseGetRecordValues || d.UseGetRecordValues
+	res.UseLoadCachedPageChunk = res.UseLoadCachedPageChunk || d.UseLoadCachedPageChunk
+	res.UseReducerQuery = res.UseReducerQuery || d.UseReducerQuery
+	return res
+}
+
+// DetectedCompat returns switches that the client turned on because of
+// server responses. Copy them to Client.Compat to skip detection in
+// future runs
+func (c *Client) DetectedCompat() Compat {
+	compatMu.Lock()
+	defer compatMu.Unlock()
+	return c.detectedCompat
+}
+
+// detectCompat turns on a switch in detectedCompat with fn, unless auto
+// detection is disabled. Returns true if the switch was turned on and
+// the caller should retry the request
+func (c *Client) detectCompat(err error, apiURL string, fn func(*Compat)) bool {
+	compatMu.Lock()
+	defer compatMu.Unlock()
+	if c.Compat.DisableAutoDetect {
+		return false
+	}
+	before := c.detectedCompat
+	fn(&c.detectedCompat)
+	if before == c.detectedCompat {
+		return false
+	}
+	logWarn(c, "%s failed with '%s', trying different api\n", apiURL, err)
+	return true
+}
+
+// isAPIGone returns true if err says that the server doesn't know the
+// endpoint anymore
+func isAPIGone(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusNotFound || apiErr.StatusCode == http.StatusGone
+	}
+	return false
+}
+
+// isAPIRejected returns true if err says that the server didn't understand
+// the request, which is what happens when the shape of a request changes
+func isAPIRejected(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusBadRequest || apiErr.Name == "ValidationError"
+	}
+	return false
+}
+
+// unwrapRecord handles newer shape of records in recordMap where role
+// and value are nested inside "value" i.e.:
+// {"spaceId": "...", "value": {"role": "reader", "value": {...}}}
+func unwrapRecord(rec *recordWithRole) *recordWithRole {
+	if rec.Role != "" || len(rec.Value) == 0 {
+		return rec
+	}
+	var nested recordWithRole
+	err := json.Unmarshal(rec.Value, &nested)
+	if err != nil || nested.Role == "" {
+		return rec
+	}
+	return &nested
+}
@@ -0,0 +1,121 @@
+package notionapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// apiGoneTransport answers requests to endpoints in statusCodes with those
+// status codes and all other requests with {} and records names of
+// endpoints and request bodies
+type apiGoneTransport struct {
+	statusCodes map[string]int
+	endpoints   []string
+	requests    []string
+}
+
+func (t *apiGoneTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	endpoint := path.Base(r.URL.Path)
+	body, _ := readRequestBody(r)
+	t.endpoints = append(t.endpoints, endpoint)
+	t.requests = append(t.requests, body)
+	code := 200
+	if c, ok := t.statusCodes[endpoint]; ok {
+		code = c
+	}
+	return &http.Response{
+		StatusCode: code,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+		Request:    r,
+	}, nil
+}
+
+func newAPIGoneClient(statusCodes map[string]int) (*Client, *apiGoneTransport) {
+	transport := &apiGoneTransport{statusCodes: statusCodes}
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RetryPolicy: &NoRetryPolicy,
+	}
+	return c, transport
+}
+
+func TestCompatLoadCachedPageChunk(t *testing.T) {
+	c, transport := newAPIGoneClient(map[string]int{"loadPageChunk": 404})
+	_, err := c.LoadPageChunk("4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", 0, nil)
+	assert.NoError(t, err)
+	_, err = c.LoadPageChunk("4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", 1, nil)
+	assert.NoError(t, err)
+	// after detecting we don't try loadPageChunk again
+	assert.Equal(t, []string{"loadPageChunk", "loadCachedPageChunk", "loadCachedPageChunk"}, transport.endpoints)
+	assert.True(t, c.DetectedCompat().UseLoadCachedPageChunk)
+	var req loadCachedPageChunkRequest
+	err = json.Unmarshal([]byte(transport.requests[1]), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", req.Page.ID)
+
+	c, transport = newAPIGoneClient(map[string]int{"loadPageChunk": 404})
+	c.Compat.DisableAutoDetect = true
+	_, err = c.LoadPageChunk("4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", 0, nil)
+	assert.True(t, IsNotFound(err))
+	assert.Equal(t, []string{"loadPageChunk"}, transport.endpoints)
+}
+
+func TestCompatGetRecordValues(t *testing.T) {
+	c, transport := newAPIGoneClient(map[string]int{"syncRecordValues": 410})
+	_, err := c.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"syncRecordValues", "getRecordValues"}, transport.endpoints)
+	assert.Equal(t, Compat{UseGetRecordValues: true}, c.DetectedCompat())
+}
+
+func TestCompatReducerQuery(t *testing.T) {
+	c, transport := newAPIGoneClient(nil)
+	c.Compat.UseReducerQuery = true
+	_, err := c.QueryCollection("collection", "view", nil, &User{})
+	assert.NoError(t, err)
+	var req queryCollectionRequest
+	err = json.Unmarshal([]byte(transport.requests[0]), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, "reducer", req.Loader.Type)
+	assert.Equal(t, 70, req.Loader.Reducers[reducerCollectionGroupResults].Limit)
+
+	c, transport = newAPIGoneClient(map[string]int{"queryCollection": 400})
+	_, err = c.QueryCollection("collection", "view", nil, &User{})
+	// the server rejects both kinds of requests
+	assert.Error(t, err)
+	assert.Equal(t, 2, len(transport.requests))
+	assert.True(t, c.DetectedCompat().UseReducerQuery)
+}
+
+func TestCompatNestedRecords(t *testing.T) {
+	c, _ := newStaticClient(200, `{
+	"recordMap": {
+		"block": {
+			"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d": {
+				"spaceId": "bc202e06-6caa-4e3f-81eb-f226ab5deef7",
+				"value": {
+					"role": "reader",
+					"value": {
+						"alive": true,
+						"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+						"type": "page",
+						"version": 3
+					}
+				}
+			}
+		}
+	}
+}`)
+	rsp, err := c.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	assert.NoError(t, err)
+	assert.Equal(t, RoleReader, rsp.Results[0].Role)
+	assert.Equal(t, BlockPage, rsp.Results[0].Value.Type)
+	assert.Equal(t, int64(3), rsp.Results[0].Value.Version)
+}
@@ -0,0 +1,58 @@
+package notionapi
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// releaseOnClose frees a slot of Client.MaxConcurrentRequests when the body
+// of response is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.release)
+	return err
+}
+
+// acquireRequestSlot waits until there are less than MaxConcurrentRequests
+// requests in flight. release must be called when the request, including
+// reading the body of the response, is finished
+func (c *Client) acquireRequestSlot(ctx context.Context) (release func(), err error) {
+	c.requestSemOnce.Do(func() {
+		if c.MaxConcurrentRequests > 0 {
+			c.requestSem = make(chan struct{}, c.MaxConcurrentRequests)
+		}
+	})
+	if c.requestSem == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.requestSem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return func() { <-c.requestSem }, nil
+}
+
+// concurrency returns how many things (pages, files) to download at the
+// same time when n was asked for and def is the default. There's no point
+// in doing more than MaxConcurrentRequests at the same time
+func (c *Client) concurrency(n int, def int) int {
+	max := c.MaxConcurrentRequests
+	if n <= 0 {
+		n = def
+		if max > 0 {
+			n = max
+		}
+	}
+	if max > 0 && n > max {
+		n = max
+	}
+	return n
+}
@@ -0,0 +1,167 @@
+package notionapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	var mu sync.Mutex
+	requests, active, maxActive := 0, 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+		// give other requests a chance to start
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/api/v3/") {
+			w.Write([]byte(`{"recordMap": {}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("content of " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	const maxRequests = 3
+	c := &Client{
+		BaseURL:               server.URL + "/api/v3",
+		RetryPolicy:           &NoRetryPolicy,
+		RateLimiter:           NewTokenBucket(1000, 100),
+		MaxConcurrentRequests: maxRequests,
+	}
+	d := NewDownloader(c)
+	// capped by MaxConcurrentRequests
+	d.Concurrency = 10
+	assert.Equal(t, maxRequests, d.concurrency())
+
+	var reqs []DownloadRequest
+	for i := 0; i < 20; i++ {
+		reqs = append(reqs, DownloadRequest{URL: fmt.Sprintf("%s/%d.png", server.URL, i)})
+	}
+	var wg sync.WaitGroup
+	// api requests made at the same time as downloads share the limit
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.GetBlockVersions([]string{transactionBlockID})
+		}()
+	}
+	for _, res := range d.GetAll(reqs) {
+		assert.NoError(t, res.Err)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 40, requests)
+	assert.True(t, maxActive <= maxRequests, "%d requests at the same time", maxActive)
+	assert.True(t, maxActive > 1)
+}
+
+func TestClientConcurrency(t *testing.T) {
+	c := &Client{}
+	assert.Equal(t, 4, c.concurrency(0, 4))
+	assert.Equal(t, 10, c.concurrency(10, 4))
+	c.MaxConcurrentRequests = 8
+	assert.Equal(t, 8, c.concurrency(0, 4))
+	assert.Equal(t, 2, c.concurrency(2, 4))
+	assert.Equal(t, 8, c.concurrency(10, 4))
+}
+
+func TestMaxConcurrentRequestsRecursive(t *testing.T) {
+	const (
+		root = "00000000-0000-0000-0000-000000000001"
+		a    = "00000000-0000-0000-0000-00000000000a"
+		b    = "00000000-0000-0000-0000-00000000000b"
+	)
+	transport := &pageTreeTransport{
+		children: map[string][]string{
+			root: {a, b},
+			a:    {b},
+			b:    {},
+		},
+		downloaded: map[string]int{},
+	}
+	// a single request at a time is enough, pages don't wait for each other
+	client := &Client{
+		HTTPClient:            &http.Client{Transport: transport},
+		RateLimiter:           NewTokenBucket(1000, 100),
+		MaxConcurrentRequests: 1,
+	}
+	pages, err := client.DownloadPagesRecursively(root, &RecursiveOptions{Concurrency: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(pages))
+}
+
+// closeTrackingBody counts bodies of responses that were not closed
+type closeTrackingBody struct {
+	io.Reader
+	open *int32
+	once sync.Once
+}
+
+func (b *closeTrackingBody) Close() error {
+	b.once.Do(func() { atomic.AddInt32(b.open, -1) })
+	return nil
+}
+
+type closeTrackingTransport struct {
+	open int32
+}
+
+func (t *closeTrackingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	atomic.AddInt32(&t.open, 1)
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       &closeTrackingBody{Reader: strings.NewReader(`{}`), open: &t.open},
+		Request:    r,
+	}, nil
+}
+
+func TestMaxConcurrentRequestsFailedRecording(t *testing.T) {
+	transport := &closeTrackingTransport{}
+	c := &Client{
+		HTTPClient:            &http.Client{Transport: transport},
+		RetryPolicy:           &RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond},
+		RateLimiter:           NewTokenBucket(1000, 100),
+		MaxConcurrentRequests: 1,
+		Cassette:              NewCassette(),
+		// body of the request is not gzipped so recording it fails
+		Headers: http.Header{"Content-Encoding": {"gzip"}},
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			_, err := c.GetBlockVersions([]string{transactionBlockID})
+			var transportErr *TransportError
+			assert.True(t, errors.As(err, &transportErr))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("requests didn't get a free slot of MaxConcurrentRequests")
+	}
+	assert.Equal(t, int32(0), atomic.LoadInt32(&transport.open))
+}
@@ -0,0 +1,177 @@
+package notionapi
+
+import "fmt"
+
+const (
+	// IssueDanglingContentID is ConsistencyIssue.Type of an id in
+	// ContentIDs of a block whose record we didn't get
+	IssueDanglingContentID = "dangling content id"
+	// IssueOrphanRecord is ConsistencyIssue.Type of a block that the
+	// server sent but that is not in Content of any block of the page
+	IssueOrphanRecord = "orphan record"
+	// IssueDuplicateMembership is ConsistencyIssue.Type of a block that
+	// is in Content of more than one block, or twice in the same block
+	IssueDuplicateMembership = "duplicate membership"
+)
+
+// RecoveredBlocksTitle is the title of the toggle that Page.Repair adds
+// for orphan blocks
+const RecoveredBlocksTitle = "Recovered blocks"
+
+// ConsistencyIssue describes a problem with content of a page, see
+// Page.CheckConsistency
+type ConsistencyIssue struct {
+	// IssueDanglingContentID, IssueOrphanRecord or IssueDuplicateMembership
+	Type string
+	// the dangling id, the orphan block or the block that is in many places
+	BlockID string
+	// for IssueDanglingContentID, the block with the id in ContentIDs. For
+	// IssueDuplicateMembership, the block with another copy of the block
+	ParentID string
+	Message  string
+}
+
+// duplicateMemberships returns blocks that are in Content of more than
+// one block. The first one in document order is the expected place
+func (p *Page) duplicateMemberships() []ConsistencyIssue {
+	var res []ConsistencyIssue
+	// id of a block to the id of its first parent
+	parents := map[string]string{}
+	var visit func(b *Block)
+	visit = func(b *Block) {
+		if b.Type == BlockTransclusionReference {
+			// its Content is Content of the synced block
+			return
+		}
+		for _, child := range b.Content {
+			if child == nil {
+				continue
+			}
+			if first, ok := parents[child.ID]; ok {
+				res = append(res, ConsistencyIssue{
+					Type:     IssueDuplicateMembership,
+					BlockID:  child.ID,
+					ParentID: b.ID,
+					Message:  fmt.Sprintf("block %s is in %s and %s", child.ID, first, b.ID),
+				})
+				continue
+			}
+			parents[child.ID] = b.ID
+			visit(child)
+		}
+	}
+	if p.Root != nil {
+		parents[p.Root.ID] = ""
+		visit(p.Root)
+	}
+	return res
+}
+
+// CheckConsistency returns problems with content of the page that can
+// happen e.g. when the page is edited while we download it: ids in
+// ContentIDs of blocks that we didn't get (see Block.MissingContentIDs),
+// blocks that we got but that are not part of content (see OrphanBlocks)
+// and blocks that are in content of many blocks
+func (p *Page) CheckConsistency() []ConsistencyIssue {
+	var res []ConsistencyIssue
+	p.ForEachBlock(func(b *Block) {
+		for _, id := range b.MissingContentIDs {
+			res = append(res, ConsistencyIssue{
+				Type:     IssueDanglingContentID,
+				BlockID:  id,
+				ParentID: b.ID,
+				Message:  fmt.Sprintf("block %s has child %s that we don't have", b.ID, id),
+			})
+		}
+	})
+	for _, b := range p.OrphanBlocks() {
+		res = append(res, ConsistencyIssue{
+			Type:    IssueOrphanRecord,
+			BlockID: b.ID,
+			Message: fmt.Sprintf("block %s with parent %s is not in content of the page", b.ID, b.ParentID),
+		})
+	}
+	return append(res, p.duplicateMemberships()...)
+}
+
+// removeChild removes child with a given id from Content and ContentIDs
+// of b. If it's there many times, only the last one is removed
+func removeChild(b *Block, id string) {
+	for i := len(b.Content) - 1; i >= 0; i-- {
+		if b.Content[i] != nil && b.Content[i].ID == id {
+			b.Content = append(b.Content[:i:i], b.Content[i+1:]...)
+			break
+		}
+	}
+	for i := len(b.ContentIDs) - 1; i >= 0; i-- {
+		if b.ContentIDs[i] == id {
+			b.ContentIDs = append(b.ContentIDs[:i:i], b.ContentIDs[i+1:]...)
+			break
+		}
+	}
+}
+
+// Repair fixes problems found by CheckConsistency and returns them:
+// dangling ids are dropped, blocks that are in many places are only kept
+// in the first one, in document order, and orphan blocks are added to a
+// "Recovered blocks" toggle at the end of the page. Changes are only made
+// to the page in memory, not saved to Notion
+func (p *Page) Repair() []ConsistencyIssue {
+	issues := p.CheckConsistency()
+	if len(issues) == 0 {
+		return nil
+	}
+	p.ForEachBlock(func(b *Block) {
+		b.MissingContentIDs = nil
+		b.allContentIDs = nil
+	})
+	for _, issue := range issues {
+		if issue.Type == IssueDuplicateMembership {
+			if parent := p.BlockByID(issue.ParentID); parent != nil {
+				removeChild(parent, issue.BlockID)
+			}
+		}
+	}
+
+	// orphans in content of another orphan stay there
+	var orphans []*Block
+	for _, b := range p.OrphanBlocks() {
+		if parent := p.BlockByID(b.ParentID); parent == nil || !p.IsOrphan(parent.ID) || !containsString(parent.ContentIDs, b.ID) {
+			orphans = append(orphans, b)
+		}
+	}
+	if len(orphans) > 0 && p.Root != nil {
+		recovered := &Block{
+			ID:          newBlockID(),
+			Type:        BlockToggle,
+			Alive:       true,
+			ParentID:    p.Root.ID,
+			ParentTable: TableBlock,
+			Properties: map[string]interface{}{
+				"title": []interface{}{[]interface{}{RecoveredBlocksTitle}},
+			},
+			InlineContent: []*InlineBlock{{Text: RecoveredBlocksTitle}},
+			Parent:        p.Root,
+		}
+		for _, b := range orphans {
+			resolveBlocksRec(b, p.blocks, &p.Warnings, map[string]bool{})
+			b.Parent = recovered
+			recovered.Content = append(recovered.Content, b)
+			recovered.ContentIDs = append(recovered.ContentIDs, b.ID)
+		}
+		p.Root.Content = append(p.Root.Content, recovered)
+		p.Root.ContentIDs = append(p.Root.ContentIDs, recovered.ID)
+	}
+	p.setBlockIndex(nil)
+	p.UpdatePositions()
+	return issues
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConsistency(t *testing.T) {
+	root := &Block{ID: "root", Type: BlockPage, ContentIDs: []string{"a", "missing", "b"}}
+	idToBlock := map[string]*Block{root.ID: root}
+	add := func(id string, parentID string, typ string, content ...string) *Block {
+		b := &Block{ID: id, Type: typ, ParentID: parentID, ParentTable: TableBlock, Alive: true, ContentIDs: content}
+		b.Properties = map[string]interface{}{"title": []interface{}{[]interface{}{id}}}
+		idToBlock[id] = b
+		return b
+	}
+	add("a", "root", BlockToggle, "shared")
+	add("b", "root", BlockToggle, "shared")
+	add("shared", "a", BlockText)
+	add("orphan", "root", BlockToggle, "in-orphan")
+	add("in-orphan", "orphan", BlockText)
+	assert.NoError(t, resolveBlocks(root, idToBlock, nil))
+	page := &Page{ID: root.ID, Root: root}
+	page.setBlockIndex(idToBlock)
+
+	issues := page.CheckConsistency()
+	var got []string
+	for _, issue := range issues {
+		got = append(got, issue.Type+" "+issue.BlockID+" "+issue.ParentID)
+	}
+	exp := []string{
+		IssueDanglingContentID + " missing root",
+		IssueOrphanRecord + " in-orphan ",
+		IssueOrphanRecord + " orphan ",
+		IssueDuplicateMembership + " shared b",
+	}
+	assert.Equal(t, exp, got)
+
+	assert.Equal(t, issues, page.Repair())
+	assert.Empty(t, page.CheckConsistency())
+	assert.Nil(t, page.Repair())
+
+	assert.Equal(t, 3, len(root.Content))
+	assert.Equal(t, []string{"shared"}, idToBlock["a"].ContentIDs)
+	assert.Empty(t, idToBlock["b"].Content)
+	assert.Empty(t, idToBlock["b"].ContentIDs)
+	recovered := root.Content[2]
+	assert.Equal(t, RecoveredBlocksTitle, InlineToText(recovered.InlineContent))
+	assert.Equal(t, recovered.ID, root.ContentIDs[2])
+	assert.Equal(t, 1, len(recovered.Content))
+	orphan := recovered.Content[0]
+	assert.Equal(t, "orphan", orphan.ID)
+	assert.Equal(t, recovered, orphan.Parent)
+	assert.Equal(t, 2, orphan.Depth)
+	// properties of recovered blocks are parsed
+	assert.Equal(t, "in-orphan", InlineToText(orphan.Content[0].InlineContent))
+	assert.False(t, page.IsOrphan("in-orphan"))
+}
@@ -5,6 +5,8 @@ const (
 	PermissionTypeUser = "user_permission"
 	// PermissionTypePublic describes permissions for public
 	PermissionTypePublic = "public_permission"
+	// PermissionTypeSpace describes permissions for members of the space
+	PermissionTypeSpace = "space_permission"
 )
 
 const (
@@ -45,26 +47,145 @@ const (
 	BlockColumnList = "column_list"
 	// BlockColumn is a child of TypeColumnList
 	BlockColumn = "column"
-	// BlockTable is a table block
+	// BlockTable is a simple table block (not a database). Its Content are
+	// BlockTableRow blocks, see Block.TableCells
 	BlockTable = "table"
+	// BlockTableRow is a row of BlockTable
+	BlockTableRow = "table_row"
 	// BlockCollectionView is a collection view block
 	BlockCollectionView = "collection_view"
+	// BlockCollectionViewPage is a page that is a database (a full page
+	// collection view)
+	BlockCollectionViewPage = "collection_view_page"
 	// BlockVideo is youtube video embed
 	BlockVideo = "video"
 	// BlockFile is an embedded file
 	BlockFile = "file"
+	// BlockAudio is an uploaded or embedded audio file
+	BlockAudio = "audio"
+	// BlockPDF is an uploaded or embedded pdf file
+	BlockPDF = "pdf"
+	// BlockDrive is an embedded Google Drive file
+	BlockDrive = "drive"
+	// BlockFigma is an embedded Figma design
+	BlockFigma = "figma"
+	// BlockTweet is an embedded tweet
+	BlockTweet = "tweet"
+	// BlockMaps is an embedded Google Maps map
+	BlockMaps = "maps"
+	// BlockCodepen is an embedded Codepen pen
+	BlockCodepen = "codepen"
+	// BlockFactory is a template button. Blocks it creates are in
+	// Block.TemplateContent
+	BlockFactory = "factory"
+	// BlockAlias is a link to another page (see Block.AliasPageID)
+	BlockAlias = "alias"
+	// BlockTransclusionContainer is the original of a synced block
+	BlockTransclusionContainer = "transclusion_container"
+	// BlockTransclusionReference is a copy of a synced block. Its Content
+	// is the Content of the BlockTransclusionContainer it points to
+	BlockTransclusionReference = "transclusion_reference"
 	// BlockEmbed is a generic oembed link
 	BlockEmbed = "embed"
+	// BlockCallout is a text block with an icon
+	BlockCallout = "callout"
+	// BlockEquation is a block with LaTeX equation
+	BlockEquation = "equation"
+	// BlockTableOfContents shows links to headers of the page
+	BlockTableOfContents = "table_of_contents"
+	// BlockBreadcrumb shows pages that contain the page (see Page.Ancestors)
+	BlockBreadcrumb = "breadcrumb"
 )
 
+// embedBlockTypes are types of embeds of specific services that don't
+// have their own constant. They have the same format as BlockEmbed (see
+// Block.IsEmbedBlock)
+var embedBlockTypes = map[string]bool{
+	"abstract":   true,
+	"deepnote":   true,
+	"excalidraw": true,
+	"framer":     true,
+	"hex":        true,
+	"invision":   true,
+	"loom":       true,
+	"lucidchart": true,
+	"miro":       true,
+	"pitch":      true,
+	"replit":     true,
+	"sketch":     true,
+	"typeform":   true,
+	"whimsical":  true,
+}
+
+// knownBlockTypes are block types this library knows about, in addition
+// to embedBlockTypes
+var knownBlockTypes = map[string]bool{
+	BlockPage:                  true,
+	BlockText:                  true,
+	BlockBookmark:              true,
+	BlockGist:                  true,
+	BlockBulletedList:          true,
+	BlockNumberedList:          true,
+	BlockToggle:                true,
+	BlockTodo:                  true,
+	BlockDivider:               true,
+	BlockImage:                 true,
+	BlockHeader:                true,
+	BlockSubHeader:             true,
+	BlockSubSubHeader:          true,
+	BlockQuote:                 true,
+	BlockComment:               true,
+	BlockCode:                  true,
+	BlockColumnList:            true,
+	BlockColumn:                true,
+	BlockTable:                 true,
+	BlockTableRow:              true,
+	BlockCollectionView:        true,
+	BlockCollectionViewPage:    true,
+	BlockVideo:                 true,
+	BlockFile:                  true,
+	BlockAudio:                 true,
+	BlockPDF:                   true,
+	BlockDrive:                 true,
+	BlockFigma:                 true,
+	BlockTweet:                 true,
+	BlockMaps:                  true,
+	BlockCodepen:               true,
+	BlockFactory:               true,
+	BlockAlias:                 true,
+	BlockTransclusionContainer: true,
+	BlockTransclusionReference: true,
+	BlockEmbed:                 true,
+	BlockCallout:               true,
+	BlockEquation:              true,
+	BlockTableOfContents:       true,
+	BlockBreadcrumb:            true,
+}
+
 // for CollectionColumnInfo.Type
 const (
 	// ColumnMultiSelect is multi-select column
 	ColumnMultiSelect = "multi_select"
 	ColumnTypeNumber  = "number"
 	ColumnTypeTitle   = "title"
-	// TODO: text, select, date, person, Files&Media, checkbox, URL, Email, phone
-	// formula, relaion, created time, created by, last edited time, last edited by
+	// ColumnTypeText is a text column
+	ColumnTypeText = "text"
+	// ColumnTypeSelect is a single select column
+	ColumnTypeSelect = "select"
+	// ColumnTypeDate is a date column
+	ColumnTypeDate = "date"
+	// ColumnTypePerson is a column with one or more users
+	ColumnTypePerson = "person"
+	// ColumnTypeCheckbox is a checkbox column
+	ColumnTypeCheckbox = "checkbox"
+	// ColumnTypeURL is an url column
+	ColumnTypeURL = "url"
+	// ColumnTypeEmail is an email column
+	ColumnTypeEmail = "email"
+	// ColumnTypePhoneNumber is a phone number column
+	ColumnTypePhoneNumber = "phone_number"
+	// TODO: Files&Media, formula, relaion, created time, created by,
+	// last edited time, last edited by
 )
 
 const (
@@ -72,6 +193,19 @@ const (
 	TableSpace = "space"
 	// TableBlock represents a Notion block
 	TableBlock = "block"
+	// TableCollection represents a Notion collection
+	TableCollection = "collection"
+	// TableCollectionView represents a view of Notion collection
+	TableCollectionView = "collection_view"
+	// TableUser represents a Notion user
+	TableUser = "notion_user"
+	// TableBot represents a bot e.g. of an integration using the
+	// official API
+	TableBot = "bot"
+	// TableDiscussion represents a thread of comments on a block
+	TableDiscussion = "discussion"
+	// TableComment represents a comment in a discussion
+	TableComment = "comment"
 )
 
 const (
@@ -79,6 +213,13 @@ const (
 	RoleReader = "reader"
 	// RoleEditor represents an editor
 	RoleEditor = "editor"
+	// RoleReadAndWrite represents a user who can edit content but not
+	// share it
+	RoleReadAndWrite = "read_and_write"
+	// RoleCommentOnly represents a user who can read and comment
+	RoleCommentOnly = "comment_only"
+	// RoleNone represents no access
+	RoleNone = "none"
 )
 
 const (
@@ -87,3 +228,28 @@ const (
 	// DateTypeDateTime represents a datetime in Date.Type
 	DateTypeDateTime = "datetime"
 )
+
+// Colors of blocks (e.g. FormatText.BlockColor) and of highlighted inline
+// text (InlineBlock.Highlight). A color is either a text color or, with
+// "_background" suffix, a background color
+const (
+	ColorDefault          = "default"
+	ColorGray             = "gray"
+	ColorBrown            = "brown"
+	ColorOrange           = "orange"
+	ColorYellow           = "yellow"
+	ColorTeal             = "teal" // shown as green
+	ColorBlue             = "blue"
+	ColorPurple           = "purple"
+	ColorPink             = "pink"
+	ColorRed              = "red"
+	ColorGrayBackground   = "gray_background"
+	ColorBrownBackground  = "brown_background"
+	ColorOrangeBackground = "orange_background"
+	ColorYellowBackground = "yellow_background"
+	ColorTealBackground   = "teal_background"
+	ColorBlueBackground   = "blue_background"
+	ColorPurpleBackground = "purple_background"
+	ColorPinkBackground   = "pink_background"
+	ColorRedBackground    = "red_background"
+)
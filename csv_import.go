@@ -0,0 +1,199 @@
+package notionapi
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// CSVImportOptions allows customizing Table.ImportCSV
+type CSVImportOptions struct {
+	// Comma is the field delimiter. Default is ','
+	Comma rune
+	// if true, csv columns that don't exist in the collection are created
+	// as text columns. By default they are ignored
+	CreateMissingColumns bool
+	// if true, we only convert the values and report rows (see Report)
+	// but don't create anything
+	DryRun bool
+	// if true, rows with values that can't be converted to the type of
+	// the column are skipped. By default the import is aborted and
+	// nothing is created
+	SkipInvalidRows bool
+	// Report, if set, is called for every record in csv file
+	Report func(row *CSVImportRow)
+}
+
+// CSVImportRow describes the result of importing a single csv record
+type CSVImportRow struct {
+	// line number in csv file, header is line 1
+	Line int
+	// maps column name to a value in csv file, for non-empty values of
+	// known columns
+	Values map[string]string
+	// error converting one of the values. If set, row was not created
+	Err error
+}
+
+// newColumnID returns a random id for a new column in collection schema
+func newColumnID() string {
+	const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	var b [4]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		panic(err.Error())
+	}
+	for i := range b {
+		b[i] = chars[int(b[i])%len(chars)]
+	}
+	return string(b[:])
+}
+
+func buildCreateColumnOp(collectionID string, col *CollectionColumnInfo) *Operation {
+	return &Operation{
+		ID:      collectionID,
+		Table:   TableCollection,
+		Path:    []string{"schema", col.ID},
+		Command: "set",
+		Args: map[string]interface{}{
+			"name": col.Name,
+			"type": col.Type,
+		},
+	}
+}
+
+func buildCreateRowOp(collectionID string, props map[string]interface{}) *Operation {
+	id := newBlockID()
+	now := notionTimeNow()
+	return &Operation{
+		ID:      id,
+		Table:   TableBlock,
+		Path:    []string{},
+		Command: "set",
+		Args: map[string]interface{}{
+			"id":               id,
+			"type":             BlockPage,
+			"version":          1,
+			"alive":            true,
+			"parent_id":        collectionID,
+			"parent_table":     TableCollection,
+			"created_time":     now,
+			"last_edited_time": now,
+			"properties":       props,
+		},
+	}
+}
+
+// ImportCSV creates a row in the collection for every record in csv file.
+// The first record is a header whose values are matched with names of
+// columns in the collection. Values are converted to the type of the column.
+// Returns the number of rows created (or that would be created in DryRun
+// mode)
+func (t *Table) ImportCSV(r io.Reader, opts *CSVImportOptions) (int, error) {
+	if opts == nil {
+		opts = &CSVImportOptions{}
+	}
+	if t.Collection == nil {
+		return 0, errors.New("table doesn't have a collection")
+	}
+	if t.client == nil && !opts.DryRun {
+		return 0, ErrOfflinePage
+	}
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read csv header: %s", err)
+	}
+
+	var ops []*Operation
+	// columns[i] is the column for i-th csv field, nil if ignored
+	columns := make([]*CollectionColumnInfo, len(header))
+	var newColumns []*CollectionColumnInfo
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		for id, col := range t.Collection.CollectionSchema {
+			if strings.EqualFold(col.Name, name) {
+				col.ID = id
+				columns[i] = col
+				break
+			}
+		}
+		if columns[i] == nil && opts.CreateMissingColumns {
+			col := &CollectionColumnInfo{
+				ID:   newColumnID(),
+				Name: name,
+				Type: ColumnTypeText,
+			}
+			columns[i] = col
+			newColumns = append(newColumns, col)
+			ops = append(ops, buildCreateColumnOp(t.Collection.ID, col))
+		}
+	}
+
+	nRows := 0
+	line := 1
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, err
+		}
+		line++
+		row := &CSVImportRow{
+			Line:   line,
+			Values: map[string]string{},
+		}
+		props := map[string]interface{}{}
+		for i, s := range record {
+			if i >= len(columns) || columns[i] == nil || strings.TrimSpace(s) == "" {
+				continue
+			}
+			col := columns[i]
+			row.Values[col.Name] = s
+			v, err := encodePropertyValue(col, s)
+			if err != nil {
+				row.Err = fmt.Errorf("column '%s': %s", col.Name, err)
+				break
+			}
+			props[col.ID] = v
+		}
+		if opts.Report != nil {
+			opts.Report(row)
+		}
+		if row.Err != nil {
+			if opts.SkipInvalidRows {
+				continue
+			}
+			return 0, fmt.Errorf("line %d: %s", line, row.Err)
+		}
+		ops = append(ops, buildCreateRowOp(t.Collection.ID, props))
+		nRows++
+	}
+
+	if opts.DryRun {
+		return nRows, nil
+	}
+	err = t.client.submitOperations(ops)
+	if err != nil {
+		return 0, err
+	}
+	for _, col := range newColumns {
+		if t.Collection.CollectionSchema == nil {
+			t.Collection.CollectionSchema = map[string]*CollectionColumnInfo{}
+		}
+		t.Collection.CollectionSchema[col.ID] = col
+	}
+	return nRows, nil
+}
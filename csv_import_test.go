@@ -0,0 +1,91 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodePropertyValue(t *testing.T) {
+	toJSON := func(col *CollectionColumnInfo, s string) string {
+		v, err := encodePropertyValue(col, s)
+		assert.NoError(t, err)
+		d, err := json.Marshal(v)
+		assert.NoError(t, err)
+		return string(d)
+	}
+	text := &CollectionColumnInfo{Type: ColumnTypeText}
+	assert.Equal(t, `[["hello"]]`, toJSON(text, " hello "))
+	assert.Equal(t, `null`, toJSON(text, ""))
+
+	checkbox := &CollectionColumnInfo{Type: ColumnTypeCheckbox}
+	assert.Equal(t, `[["Yes"]]`, toJSON(checkbox, "true"))
+	assert.Equal(t, `[["No"]]`, toJSON(checkbox, "no"))
+
+	multi := &CollectionColumnInfo{Type: ColumnMultiSelect}
+	assert.Equal(t, `[["a,b"]]`, toJSON(multi, "a, b,"))
+
+	date := &CollectionColumnInfo{Type: ColumnTypeDate}
+	assert.Equal(t, `[["‣",[["d",{"date_format":"relative","start_date":"2019-01-02","start_time":"15:04","type":"datetime"}]]]]`, toJSON(date, "2019-01-02 15:04"))
+
+	number := &CollectionColumnInfo{Type: ColumnTypeNumber}
+	_, err := encodePropertyValue(number, "abc")
+	assert.Error(t, err)
+	_, err = encodePropertyValue(date, "tomorrow")
+	assert.Error(t, err)
+}
+
+const csvToImport = `name;status;notes;extra
+First;Todo;some notes;x
+Second;Done;;
+`
+
+func TestImportCSVDryRun(t *testing.T) {
+	table := parseTestTable(t)
+	var rows []*CSVImportRow
+	opts := &CSVImportOptions{
+		Comma:  ';',
+		DryRun: true,
+		Report: func(row *CSVImportRow) {
+			rows = append(rows, row)
+		},
+	}
+	n, err := table.ImportCSV(strings.NewReader(csvToImport), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 2, len(rows))
+	assert.Equal(t, 2, rows[0].Line)
+	exp := map[string]string{"Name": "First", "Status": "Todo", "Notes": "some notes"}
+	assert.Equal(t, exp, rows[0].Values)
+	assert.Equal(t, map[string]string{"Name": "Second", "Status": "Done"}, rows[1].Values)
+
+	// offline table can't be modified
+	_, err = table.ImportCSV(strings.NewReader(csvToImport), &CSVImportOptions{Comma: ';'})
+	assert.Equal(t, ErrOfflinePage, err)
+}
+
+func TestImportCSVInvalidRows(t *testing.T) {
+	table := parseTestTable(t)
+	table.Collection.CollectionSchema["Ma{X"].Type = ColumnTypeNumber
+	csv := "Name,Notes\na,1\nb,not a number\nc,3\n"
+	_, err := table.ImportCSV(strings.NewReader(csv), &CSVImportOptions{DryRun: true})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3")
+
+	var skipped []int
+	opts := &CSVImportOptions{
+		DryRun:          true,
+		SkipInvalidRows: true,
+		Report: func(row *CSVImportRow) {
+			if row.Err != nil {
+				skipped = append(skipped, row.Line)
+			}
+		},
+	}
+	n, err := table.ImportCSV(strings.NewReader(csv), opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []int{3}, skipped)
+}
@@ -5,6 +5,14 @@ import (
 	"fmt"
 )
 
+// LeveledLogger receives messages logged by the library. All messages
+// are also written to Client.Logger, if set
+type LeveledLogger interface {
+	Debugf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
 func dbg(client *Client, format string, args ...interface{}) {
 	if !client.DebugLog {
 		return
@@ -13,6 +21,27 @@ func dbg(client *Client, format string, args ...interface{}) {
 }
 
 func log(client *Client, format string, args ...interface{}) {
+	if client.LeveledLogger != nil {
+		client.LeveledLogger.Debugf(format, args...)
+	}
+	logToWriter(client, format, args...)
+}
+
+func logWarn(client *Client, format string, args ...interface{}) {
+	if client.LeveledLogger != nil {
+		client.LeveledLogger.Warnf(format, args...)
+	}
+	logToWriter(client, format, args...)
+}
+
+func logError(client *Client, format string, args ...interface{}) {
+	if client.LeveledLogger != nil {
+		client.LeveledLogger.Errorf(format, args...)
+	}
+	logToWriter(client, format, args...)
+}
+
+func logToWriter(client *Client, format string, args ...interface{}) {
 	if client.Logger == nil {
 		return
 	}
@@ -35,5 +64,9 @@ func ppJSON(js []byte) []byte {
 
 // log JSON after pretty printing it
 func logJSON(client *Client, js []byte) {
+	// pretty-printing large responses is expensive
+	if !isLogging(client) {
+		return
+	}
 	log(client, "%s\n", string(ppJSON(js)))
 }
@@ -0,0 +1,424 @@
+package notionapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Discussion is a thread of comments on a block
+type Discussion struct {
+	ID      string `json:"id"`
+	Version int64  `json:"version"`
+	// id of the block the discussion is attached to
+	ParentID    string `json:"parent_id"`
+	ParentTable string `json:"parent_table"`
+	SpaceID     string `json:"space_id"`
+	// true if the discussion was resolved
+	Resolved bool `json:"resolved"`
+	Alive    bool `json:"alive"`
+	// ids of comments, in order
+	CommentIDs []string `json:"comments"`
+
+	// id of the block the discussion is anchored to
+	BlockID string `json:"-"`
+	// comments in the same order as CommentIDs. Comments we couldn't get
+	// are skipped
+	Comments []*Comment `json:"-"`
+}
+
+// Comment is a single comment in a Discussion
+type Comment struct {
+	ID      string `json:"id"`
+	Version int64  `json:"version"`
+	Alive   bool   `json:"alive"`
+	// id of the discussion
+	ParentID       string `json:"parent_id"`
+	ParentTable    string `json:"parent_table"`
+	SpaceID        string `json:"space_id"`
+	CreatedByID    string `json:"created_by_id"`
+	CreatedByTable string `json:"created_by_table"`
+	CreatedTime    int64  `json:"created_time"`
+	LastEditedTime int64  `json:"last_edited_time"`
+	// text of the comment as sent by the server
+	TextRaw interface{} `json:"text"`
+
+	// text of the comment, parsed from TextRaw
+	Text []*InlineBlock `json:"-"`
+}
+
+// parseComment sets Text from TextRaw
+func parseComment(c *Comment) error {
+	if c.TextRaw == nil {
+		return nil
+	}
+	text, err := parseInlineBlocks(c.TextRaw)
+	if err != nil {
+		return fmt.Errorf("failed to parse text of comment '%s': %s", c.ID, err)
+	}
+	c.Text = text
+	return nil
+}
+
+// CreatedOn returns the time the comment was created, zero time if not known
+func (c *Comment) CreatedOn() time.Time {
+	return notionTime(c.CreatedTime)
+}
+
+// PlainText returns text of the comment without formatting
+func (c *Comment) PlainText() string {
+	return InlineToText(c.Text)
+}
+
+// CreatedByUser returns author of the comment, nil if it's not in p.Users
+func (c *Comment) CreatedByUser(p *Page) *User {
+	return p.findUser(c.CreatedByID)
+}
+
+// UnresolvedDiscussions returns discussions of the page that were not
+// resolved. Page.Discussions is only set if the page was downloaded with
+// DownloadPageOptions.IncludeDiscussions
+func (p *Page) UnresolvedDiscussions() []*Discussion {
+	var res []*Discussion
+	for _, d := range p.Discussions {
+		if !d.Resolved {
+			res = append(res, d)
+		}
+	}
+	return res
+}
+
+// getDiscussions returns alive discussions of blocks in the page, with
+// their comments
+func (c *Client) getDiscussions(ctx context.Context, page *Page) ([]*Discussion, error) {
+	var requests []RecordRequest
+	idToBlockID := map[string]string{}
+	page.ForEachBlock(func(b *Block) {
+		for _, id := range b.DiscussionIDs {
+			if _, ok := idToBlockID[id]; ok {
+				continue
+			}
+			idToBlockID[id] = b.ID
+			requests = append(requests, RecordRequest{Table: TableDiscussion, ID: id})
+		}
+	})
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	rsp, err := c.GetRecordValuesCtx(ctx, requests)
+	if err != nil {
+		return nil, err
+	}
+	var res []*Discussion
+	var commentRequests []RecordRequest
+	for i, rv := range rsp.Results {
+		d := rv.Discussion
+		if d == nil || !d.Alive {
+			continue
+		}
+		d.BlockID = idToBlockID[requests[i].ID]
+		res = append(res, d)
+		for _, id := range d.CommentIDs {
+			commentRequests = append(commentRequests, RecordRequest{Table: TableComment, ID: id})
+		}
+	}
+	if len(commentRequests) == 0 {
+		return res, nil
+	}
+	rsp, err = c.GetRecordValuesCtx(ctx, commentRequests)
+	if err != nil {
+		return nil, err
+	}
+	idToComment := map[string]*Comment{}
+	for i, rv := range rsp.Results {
+		if rv.Comment != nil && rv.Comment.Alive {
+			idToComment[commentRequests[i].ID] = rv.Comment
+		}
+	}
+	for _, d := range res {
+		for _, id := range d.CommentIDs {
+			if comment := idToComment[id]; comment != nil {
+				d.Comments = append(d.Comments, comment)
+			}
+		}
+	}
+	return res, nil
+}
+
+// buildCreateDiscussionOp creates a discussion on block blockID. It must
+// be followed by buildAppendDiscussionOp to attach it to the block
+func buildCreateDiscussionOp(id string, blockID string) *Operation {
+	return &Operation{
+		ID:      id,
+		Table:   TableDiscussion,
+		Path:    []string{},
+		Command: "set",
+		Args: map[string]interface{}{
+			"id":           id,
+			"version":      1,
+			"alive":        true,
+			"parent_id":    blockID,
+			"parent_table": TableBlock,
+			"resolved":     false,
+			"comments":     []string{},
+		},
+	}
+}
+
+// buildAppendDiscussionOp adds discussion id to discussions of blockID
+func buildAppendDiscussionOp(blockID string, id string) *Operation {
+	return &Operation{
+		ID:      blockID,
+		Table:   TableBlock,
+		Path:    []string{"discussion"},
+		Command: "listAfter",
+		Args: map[string]interface{}{
+			"id": id,
+		},
+	}
+}
+
+// buildCreateCommentOp creates a comment in discussion discussionID. It
+// must be followed by buildAppendCommentOp
+func buildCreateCommentOp(id string, discussionID string, text []*InlineBlock, now int64) *Operation {
+	return &Operation{
+		ID:      id,
+		Table:   TableComment,
+		Path:    []string{},
+		Command: "set",
+		Args: map[string]interface{}{
+			"id":               id,
+			"version":          1,
+			"alive":            true,
+			"parent_id":        discussionID,
+			"parent_table":     TableDiscussion,
+			"text":             InlineBlocksToJSON(text),
+			"created_time":     now,
+			"last_edited_time": now,
+		},
+	}
+}
+
+// buildAppendCommentOp adds comment id at the end of comments of
+// discussionID
+func buildAppendCommentOp(discussionID string, id string) *Operation {
+	return &Operation{
+		ID:      discussionID,
+		Table:   TableDiscussion,
+		Path:    []string{"comments"},
+		Command: "listAfter",
+		Args: map[string]interface{}{
+			"id": id,
+		},
+	}
+}
+
+// addComment adds a comment to discussion d of block b or, if d is nil,
+// to a new discussion. Returns the comment and the discussion
+func (c *Client) addComment(ctx context.Context, b *Block, d *Discussion, text []*InlineBlock) (*Comment, *Discussion, error) {
+	if len(text) == 0 {
+		return nil, nil, fmt.Errorf("comment is empty")
+	}
+	var ops []*Operation
+	isNew := d == nil
+	if isNew {
+		d = &Discussion{
+			ID:          newBlockID(),
+			Version:     1,
+			ParentID:    b.ID,
+			ParentTable: TableBlock,
+			Alive:       true,
+			BlockID:     b.ID,
+		}
+		ops = append(ops, buildCreateDiscussionOp(d.ID, b.ID))
+		ops = append(ops, buildAppendDiscussionOp(b.ID, d.ID))
+	}
+	now := notionTimeNow()
+	comment := &Comment{
+		ID:             newBlockID(),
+		Version:        1,
+		Alive:          true,
+		ParentID:       d.ID,
+		ParentTable:    TableDiscussion,
+		CreatedTime:    now,
+		LastEditedTime: now,
+		TextRaw:        InlineBlocksToJSON(text),
+		Text:           text,
+	}
+	ops = append(ops, buildCreateCommentOp(comment.ID, d.ID, text, now))
+	ops = append(ops, buildAppendCommentOp(d.ID, comment.ID))
+	if err := c.SubmitTransactionCtx(ctx, ops); err != nil {
+		return nil, nil, err
+	}
+	if isNew {
+		b.DiscussionIDs = append(b.DiscussionIDs, d.ID)
+	}
+	d.CommentIDs = append(d.CommentIDs, comment.ID)
+	d.Comments = append(d.Comments, comment)
+	return comment, d, nil
+}
+
+// openDiscussion returns the first unresolved discussion of the block,
+// nil if it doesn't have one
+func (c *Client) openDiscussion(ctx context.Context, b *Block) (*Discussion, error) {
+	if len(b.DiscussionIDs) == 0 {
+		return nil, nil
+	}
+	var requests []RecordRequest
+	for _, id := range b.DiscussionIDs {
+		requests = append(requests, RecordRequest{Table: TableDiscussion, ID: id})
+	}
+	rsp, err := c.GetRecordValuesCtx(ctx, requests)
+	if err != nil {
+		return nil, err
+	}
+	for _, rv := range rsp.Results {
+		if d := rv.Discussion; d != nil && d.Alive && !d.Resolved {
+			d.BlockID = b.ID
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+// AddComment adds a comment to a block. Like the web client, the comment
+// is added to the first unresolved discussion of the block or, if there
+// isn't one, to a new discussion
+func (c *Client) AddComment(blockID string, text []*InlineBlock) (*Comment, error) {
+	return c.AddCommentCtx(context.Background(), blockID, text)
+}
+
+// AddCommentCtx is like AddComment but can be canceled with ctx
+func (c *Client) AddCommentCtx(ctx context.Context, blockID string, text []*InlineBlock) (*Comment, error) {
+	rsp, err := c.GetBlockRecordsCtx(ctx, []string{blockID})
+	if err != nil {
+		return nil, err
+	}
+	b := rsp.Results[0].Value
+	if b == nil {
+		return nil, fmt.Errorf("couldn't retrieve block with id %s: %w", blockID, ErrNotFound)
+	}
+	d, err := c.openDiscussion(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	comment, _, err := c.addComment(ctx, b, d, text)
+	return comment, err
+}
+
+// AddCommentText is like AddComment but with a plain text comment
+func (c *Client) AddCommentText(blockID string, text string) (*Comment, error) {
+	return c.AddCommentCtx(context.Background(), blockID, []*InlineBlock{{Text: text}})
+}
+
+// AddComment adds a plain text comment to block b of the page, see
+// Client.AddComment. If the page was downloaded with
+// DownloadPageOptions.IncludeDiscussions, the comment is added to
+// Discussions
+func (p *Page) AddComment(b *Block, text string) (*Comment, error) {
+	return p.AddCommentCtx(context.Background(), b, []*InlineBlock{{Text: text}})
+}
+
+// AddCommentCtx is like AddComment but with formatted text and can be
+// canceled with ctx
+func (p *Page) AddCommentCtx(ctx context.Context, b *Block, text []*InlineBlock) (*Comment, error) {
+	if p.client == nil {
+		return nil, ErrOfflinePage
+	}
+	var d *Discussion
+	var err error
+	if p.discussionsLoaded {
+		for _, v := range p.Discussions {
+			if v.BlockID == b.ID && !v.Resolved {
+				d = v
+				break
+			}
+		}
+	} else {
+		d, err = p.client.openDiscussion(ctx, b)
+		if err != nil {
+			return nil, err
+		}
+	}
+	isNew := d == nil
+	comment, d, err := p.client.addComment(ctx, b, d, text)
+	if err != nil {
+		return nil, err
+	}
+	if isNew && p.discussionsLoaded {
+		p.Discussions = append(p.Discussions, d)
+	}
+	return comment, nil
+}
+
+// buildSetResolvedOp resolves or reopens discussion id
+func buildSetResolvedOp(id string, resolved bool) *Operation {
+	return &Operation{
+		ID:      id,
+		Table:   TableDiscussion,
+		Path:    []string{},
+		Command: "update",
+		Args: map[string]interface{}{
+			"resolved": resolved,
+		},
+	}
+}
+
+// ResolveDiscussion marks a discussion as resolved
+func (c *Client) ResolveDiscussion(discussionID string) error {
+	return c.setDiscussionResolved(context.Background(), discussionID, true)
+}
+
+// ResolveDiscussionCtx is like ResolveDiscussion but can be canceled with ctx
+func (c *Client) ResolveDiscussionCtx(ctx context.Context, discussionID string) error {
+	return c.setDiscussionResolved(ctx, discussionID, true)
+}
+
+// ReopenDiscussion marks a resolved discussion as not resolved
+func (c *Client) ReopenDiscussion(discussionID string) error {
+	return c.setDiscussionResolved(context.Background(), discussionID, false)
+}
+
+// ReopenDiscussionCtx is like ReopenDiscussion but can be canceled with ctx
+func (c *Client) ReopenDiscussionCtx(ctx context.Context, discussionID string) error {
+	return c.setDiscussionResolved(ctx, discussionID, false)
+}
+
+// setDiscussionResolved resolves or reopens a discussion
+func (c *Client) setDiscussionResolved(ctx context.Context, discussionID string, resolved bool) error {
+	id, err := ToDashID(discussionID)
+	if err != nil {
+		return err
+	}
+	ops := []*Operation{
+		buildSetResolvedOp(id, resolved),
+	}
+	return c.SubmitTransactionCtx(ctx, ops)
+}
+
+// ResolveDiscussion is like Client.ResolveDiscussion but also updates
+// the discussion in Discussions
+func (p *Page) ResolveDiscussion(discussionID string) error {
+	return p.setDiscussionResolved(context.Background(), discussionID, true)
+}
+
+// ReopenDiscussion is like Client.ReopenDiscussion but also updates
+// the discussion in Discussions
+func (p *Page) ReopenDiscussion(discussionID string) error {
+	return p.setDiscussionResolved(context.Background(), discussionID, false)
+}
+
+func (p *Page) setDiscussionResolved(ctx context.Context, discussionID string, resolved bool) error {
+	if p.client == nil {
+		return ErrOfflinePage
+	}
+	if err := p.client.setDiscussionResolved(ctx, discussionID, resolved); err != nil {
+		return err
+	}
+	for _, d := range p.Discussions {
+		if SameID(d.ID, discussionID) {
+			d.Resolved = resolved
+		}
+	}
+	return nil
+}
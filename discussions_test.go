@@ -0,0 +1,154 @@
+package notionapi
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const discussionsJSON = `{
+	"recordMap": {
+		"discussion": {
+			"d1000000-0000-0000-0000-000000000001": {
+				"role": "editor",
+				"value": {
+					"id": "d1000000-0000-0000-0000-000000000001",
+					"parent_id": "b1000000-0000-0000-0000-000000000001",
+					"parent_table": "block",
+					"resolved": false,
+					"alive": true,
+					"comments": ["c1000000-0000-0000-0000-000000000001", "c1000000-0000-0000-0000-000000000002"]
+				}
+			},
+			"d1000000-0000-0000-0000-000000000002": {
+				"role": "editor",
+				"value": {
+					"id": "d1000000-0000-0000-0000-000000000002",
+					"parent_id": "b1000000-0000-0000-0000-000000000001",
+					"parent_table": "block",
+					"resolved": true,
+					"alive": true,
+					"comments": []
+				}
+			}
+		},
+		"comment": {
+			"c1000000-0000-0000-0000-000000000001": {
+				"role": "editor",
+				"value": {
+					"id": "c1000000-0000-0000-0000-000000000001",
+					"parent_id": "d1000000-0000-0000-0000-000000000001",
+					"parent_table": "discussion",
+					"alive": true,
+					"created_by_id": "u1000000-0000-0000-0000-000000000001",
+					"created_time": 1600000000000,
+					"text": [["Please fix "], ["this", [["b"]]]]
+				}
+			},
+			"c1000000-0000-0000-0000-000000000002": {
+				"role": "editor",
+				"value": {
+					"id": "c1000000-0000-0000-0000-000000000002",
+					"parent_id": "d1000000-0000-0000-0000-000000000001",
+					"parent_table": "discussion",
+					"alive": false,
+					"text": [["deleted"]]
+				}
+			}
+		}
+	}
+}`
+
+func TestGetDiscussions(t *testing.T) {
+	c, _ := newStaticClient(200, discussionsJSON)
+	b := textBlock(BlockText, "text")
+	b.ID = "b1000000-0000-0000-0000-000000000001"
+	b.DiscussionIDs = []string{"d1000000-0000-0000-0000-000000000001", "d1000000-0000-0000-0000-000000000002"}
+	page := testPage(b, textBlock(BlockText, "no comments"))
+	page.client = c
+
+	discussions, err := c.getDiscussions(context.Background(), page)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(discussions))
+	d := discussions[0]
+	assert.Equal(t, b.ID, d.BlockID)
+	assert.False(t, d.Resolved)
+	// deleted comments are skipped
+	assert.Equal(t, 1, len(d.Comments))
+	comment := d.Comments[0]
+	assert.Equal(t, "Please fix this", comment.PlainText())
+	assert.Equal(t, AttrBold, comment.Text[1].AttrFlags)
+	assert.Equal(t, "u1000000-0000-0000-0000-000000000001", comment.CreatedByID)
+	assert.Equal(t, int64(1600000000), comment.CreatedOn().Unix())
+	assert.True(t, discussions[1].Resolved)
+
+	page.Discussions = discussions
+	unresolved := page.UnresolvedDiscussions()
+	assert.Equal(t, []*Discussion{d}, unresolved)
+}
+
+func TestPageAddComment(t *testing.T) {
+	c, transport := newStaticClient(200, `{}`)
+	b := textBlock(BlockText, "text")
+	b.ID = "b1000000-0000-0000-0000-000000000001"
+	page := testPage(b)
+	_, err := page.AddComment(b, "hello")
+	assert.Equal(t, ErrOfflinePage, err)
+
+	page.client = c
+	page.discussionsLoaded = true
+	comment, err := page.AddComment(b, "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", comment.PlainText())
+	assert.Equal(t, 1, len(transport.requests))
+	body := transport.requests[0]
+	assert.True(t, strings.Contains(body, `"table":"discussion","path":[],"command":"set"`), body)
+	assert.True(t, strings.Contains(body, `"path":["discussion"],"command":"listAfter"`), body)
+	assert.True(t, strings.Contains(body, `"path":["comments"],"command":"listAfter","args":{"id":"`+comment.ID+`"}`), body)
+	assert.True(t, strings.Contains(body, `"parent_id":"`+comment.ParentID+`","parent_table":"discussion"`), body)
+	assert.Equal(t, 1, len(page.Discussions))
+	d := page.Discussions[0]
+	assert.Equal(t, []string{d.ID}, b.DiscussionIDs)
+	assert.Equal(t, d.ID, comment.ParentID)
+
+	// second comment goes to the same discussion
+	comment, err = page.AddComment(b, "again")
+	assert.NoError(t, err)
+	body = transport.requests[1]
+	assert.False(t, strings.Contains(body, `"table":"discussion","path":[]`), body)
+	assert.Equal(t, 1, len(page.Discussions))
+	assert.Equal(t, 2, len(d.Comments))
+	assert.Equal(t, comment, d.Comments[1])
+
+	// resolved discussions are not reused
+	d.Resolved = true
+	_, err = page.AddComment(b, "new thread")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(page.Discussions))
+	assert.Equal(t, 2, len(b.DiscussionIDs))
+}
+
+func TestResolveDiscussion(t *testing.T) {
+	c, transport := newStaticClient(200, `{}`)
+	assert.Error(t, c.ResolveDiscussion("not an id"))
+	assert.Equal(t, 0, len(transport.requests))
+
+	err := c.ResolveDiscussion("d1000000000000000000000000000001")
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(transport.requests[0], `{"id":"d1000000-0000-0000-0000-000000000001","table":"discussion","path":[],"command":"update","args":{"resolved":true}}`), transport.requests[0])
+
+	d1 := &Discussion{ID: "d1000000-0000-0000-0000-000000000001"}
+	d2 := &Discussion{ID: "d1000000-0000-0000-0000-000000000002"}
+	page := testPage()
+	page.Discussions = []*Discussion{d1, d2}
+	assert.Equal(t, ErrOfflinePage, page.ResolveDiscussion(d1.ID))
+
+	page.client = c
+	assert.NoError(t, page.ResolveDiscussion(d1.ID))
+	assert.Equal(t, []*Discussion{d2}, page.UnresolvedDiscussions())
+	assert.NoError(t, page.ReopenDiscussion("d1000000000000000000000000000001"))
+	assert.Equal(t, []*Discussion{d1, d2}, page.UnresolvedDiscussions())
+	assert.True(t, strings.Contains(transport.requests[2], `"args":{"resolved":false}`), transport.requests[2])
+}
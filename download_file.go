@@ -0,0 +1,111 @@
+package notionapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// FileDownload describes a downloaded file
+type FileDownload struct {
+	// content of the file. Not set by DownloadFileTo
+	Data []byte
+	// value of Content-Type header
+	ContentType string
+	// url from which the file was downloaded, after signing and
+	// following redirects
+	URL string
+	// size of the file in bytes
+	Size int64
+}
+
+// isNotionProxyURL returns true for images proxied via notion server
+// i.e. https://www.notion.so/image/${url}
+func isNotionProxyURL(uri string) bool {
+	return strings.HasPrefix(uri, ImageProxyURL)
+}
+
+// resolveDownloadURL returns url from which uri can be downloaded
+func (c *Client) resolveDownloadURL(ctx context.Context, uri string, block *Block) (string, error) {
+	if isNotionProxyURL(uri) && block != nil {
+		// the proxy needs to know which block the image belongs
+		// to for images that are not public
+		u, err := url.Parse(uri)
+		if err != nil {
+			return "", err
+		}
+		q := u.Query()
+		if q.Get("id") == "" {
+			q.Set("table", TableBlock)
+			q.Set("id", block.ID)
+			u.RawQuery = q.Encode()
+		}
+		return u.String(), nil
+	}
+	// urls on notion server (like proxied or /signed/ urls) are
+	// authenticated with cookies
+	if isNotionAttachment(uri) && !isNotionProxyURL(uri) && !c.isAPIHost(uri) {
+		if block == nil {
+			return "", errors.New("a block is needed to download file uploaded to Notion")
+		}
+		urls, err := c.GetSignedURLsCtx(ctx, []string{uri}, []string{block.ID})
+		if err != nil {
+			return "", err
+		}
+		return urls[0], nil
+	}
+	return uri, nil
+}
+
+// DownloadFile downloads a file or an image. uri can be a public url,
+// an image proxied via notion server or a file uploaded to Notion (which
+// requires block that owns the file, so that we can sign the url).
+func (c *Client) DownloadFile(uri string, block *Block) (*FileDownload, error) {
+	return c.DownloadFileCtx(context.Background(), uri, block)
+}
+
+// DownloadFileCtx is like DownloadFile but can be canceled with ctx
+func (c *Client) DownloadFileCtx(ctx context.Context, uri string, block *Block) (*FileDownload, error) {
+	var buf bytes.Buffer
+	res, err := c.DownloadFileToCtx(ctx, &buf, uri, block)
+	if err != nil {
+		return nil, err
+	}
+	res.Data = buf.Bytes()
+	return res, nil
+}
+
+// DownloadFileTo is like DownloadFile but writes content of the file to w
+// instead of keeping it in memory, which is better for large files
+func (c *Client) DownloadFileTo(w io.Writer, uri string, block *Block) (*FileDownload, error) {
+	return c.DownloadFileToCtx(context.Background(), w, uri, block)
+}
+
+// DownloadFileToCtx is like DownloadFileTo but can be canceled with ctx
+func (c *Client) DownloadFileToCtx(ctx context.Context, w io.Writer, uri string, block *Block) (*FileDownload, error) {
+	uri, err := c.resolveDownloadURL(ctx, uri, block)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := c.httpGet(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	defer rsp.Body.Close()
+	n, err := io.Copy(w, rsp.Body)
+	if err != nil {
+		return nil, err
+	}
+	res := &FileDownload{
+		ContentType: rsp.Header.Get("Content-Type"),
+		URL:         uri,
+		Size:        n,
+	}
+	if rsp.Request != nil && rsp.Request.URL != nil {
+		res.URL = rsp.Request.URL.String()
+	}
+	return res, nil
+}
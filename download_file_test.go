@@ -0,0 +1,86 @@
+package notionapi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fileTransport serves files and signs urls of files uploaded to Notion
+type fileTransport struct {
+	// urls of GET requests
+	gets []string
+	// cookies sent with GET requests
+	cookies []string
+}
+
+func (t *fileTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	s := "file content"
+	header := http.Header{}
+	if r.Method == "POST" {
+		s = `{"signedUrls":["https://s3.example.com/doc.pdf?sig=1"]}`
+	} else {
+		t.gets = append(t.gets, r.URL.String())
+		t.cookies = append(t.cookies, r.Header.Get("cookie"))
+		header.Set("Content-Type", "application/pdf")
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(s)),
+		Request:    r,
+	}, nil
+}
+
+func TestDownloadFile(t *testing.T) {
+	transport := &fileTransport{}
+	c := &Client{
+		AuthToken:  "token",
+		HTTPClient: &http.Client{Transport: transport},
+	}
+	block := &Block{
+		ID:   "e802296a-b0dc-41a8-8aa3-cf4212c3da0b",
+		Type: BlockFile,
+	}
+
+	file, err := c.DownloadFile("https://example.com/doc.pdf", block)
+	assert.NoError(t, err)
+	assert.Equal(t, "file content", string(file.Data))
+	assert.Equal(t, "application/pdf", file.ContentType)
+	assert.Equal(t, "https://example.com/doc.pdf", file.URL)
+	assert.Equal(t, int64(12), file.Size)
+
+	file, err = c.DownloadFile("https://s3-us-west-2.amazonaws.com/secure.notion-static.com/8b3930e3/doc.pdf", block)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://s3.example.com/doc.pdf?sig=1", file.URL)
+
+	_, err = c.DownloadFile("https://www.notion.so/image/https%3A%2F%2Fexample.com%2Fimage.png", block)
+	assert.NoError(t, err)
+
+	exp := []string{
+		"https://example.com/doc.pdf",
+		"https://s3.example.com/doc.pdf?sig=1",
+		"https://www.notion.so/image/https%3A%2F%2Fexample.com%2Fimage.png?id=e802296a-b0dc-41a8-8aa3-cf4212c3da0b&table=block",
+	}
+	assert.Equal(t, exp, transport.gets)
+	// auth cookie is only sent to notion server
+	assert.Equal(t, []string{"", "", "token_v2=token"}, transport.cookies)
+
+	_, err = c.DownloadFile("https://s3-us-west-2.amazonaws.com/secure.notion-static.com/8b3930e3/doc.pdf", nil)
+	assert.Error(t, err)
+}
+
+func TestDownloadFileTo(t *testing.T) {
+	c := &Client{
+		HTTPClient: &http.Client{Transport: &fileTransport{}},
+	}
+	var buf bytes.Buffer
+	file, err := c.DownloadFileTo(&buf, "https://example.com/doc.pdf", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, file.Data)
+	assert.Equal(t, "file content", buf.String())
+	assert.Equal(t, int64(12), file.Size)
+}
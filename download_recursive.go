@@ -0,0 +1,291 @@
+package notionapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultRecursiveConcurrency is the default number of pages
+	// downloaded at the same time by DownloadPagesRecursively
+	DefaultRecursiveConcurrency = 4
+	// DefaultRecursiveMaxPages is the default maximum number of pages
+	// downloaded by DownloadPagesRecursively
+	DefaultRecursiveMaxPages = 1000
+	// DefaultRecursiveMaxDepth is the default maximum depth of pages,
+	// relative to the root page, downloaded by DownloadPagesRecursively
+	DefaultRecursiveMaxDepth = 20
+	// DefaultRecursiveMaxBlocksPerPage is the default maximum number of
+	// blocks of a page downloaded by DownloadPagesRecursively
+	DefaultRecursiveMaxBlocksPerPage = 100000
+)
+
+// RecursiveOptions allows customizing DownloadPagesRecursively
+type RecursiveOptions struct {
+	// maximum number of pages downloaded at the same time. Default is
+	// DefaultRecursiveConcurrency or Client.MaxConcurrentRequests, if set.
+	// Requests are still limited by Client.RateLimiter
+	Concurrency int
+	// if true, pages that are rows of tables are also downloaded
+	IncludeCollectionRows bool
+	// options used for downloading each page
+	DownloadPageOptions *DownloadPageOptions
+	// if true, children of blocks that are missing from downloaded pages
+	// are loaded with Block.LoadChildren
+	LoadMissingChildren bool
+	// maximum number of pages to download. Default is
+	// DefaultRecursiveMaxPages, negative means no limit
+	MaxPages int
+	// maximum depth of downloaded pages, the root page is at depth 0 and
+	// its sub-pages at depth 1. Default is DefaultRecursiveMaxDepth,
+	// negative means no limit
+	MaxDepth int
+	// maximum number of blocks of a page, used if
+	// DownloadPageOptions.MaxBlocks is not set. Default is
+	// DefaultRecursiveMaxBlocksPerPage, negative means no limit
+	MaxBlocksPerPage int
+	// ShouldDescend, if set, is called for every sub-page, linked page and
+	// row before downloading it. If it returns false, the page is skipped.
+	// It can be called from multiple goroutines at the same time
+	ShouldDescend func(ref *SubPageRef) bool
+}
+
+// recursiveLimit returns the value of the limit, or def if it's not set.
+// 0 means no limit
+func recursiveLimit(v int, def int) int {
+	if v == 0 {
+		return def
+	}
+	if v < 0 {
+		return 0
+	}
+	return v
+}
+
+// LimitExceededError is returned when a download is stopped because it
+// exceeded one of the limits in RecursiveOptions or
+// DownloadPageOptions.MaxBlocks
+type LimitExceededError struct {
+	// name of the option with the limit e.g. "MaxPages"
+	Limit string
+	// value of the limit
+	Max int
+	// id of the first page that wasn't downloaded because of the limit
+	PageID string
+}
+
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("%s limit of %d exceeded by page %s", e.Limit, e.Max, e.PageID)
+}
+
+// PageDownloadError describes a failure to download one of the pages
+type PageDownloadError struct {
+	PageID string
+	Err    error
+}
+
+func (e *PageDownloadError) Error() string {
+	return fmt.Sprintf("failed to download page %s: %s", e.PageID, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *PageDownloadError) Unwrap() error {
+	return e.Err
+}
+
+// DownloadErrors is returned by DownloadPagesRecursively when some pages
+// couldn't be downloaded
+type DownloadErrors []*PageDownloadError
+
+func (e DownloadErrors) Error() string {
+	var parts []string
+	for _, err := range e {
+		parts = append(parts, err.Error())
+	}
+	return fmt.Sprintf("%d pages failed to download: %s", len(e), strings.Join(parts, "; "))
+}
+
+// Unwrap returns errors of the pages, so that errors.As can find
+// e.g. *LimitExceededError
+func (e DownloadErrors) Unwrap() []error {
+	res := make([]error, len(e))
+	for i, err := range e {
+		res[i] = err
+	}
+	return res
+}
+
+// subPagesToDownload returns pages reachable from the page
+func subPagesToDownload(page *Page, opts *RecursiveOptions) []*SubPageRef {
+	res := page.SubPages()
+	if opts.IncludeCollectionRows {
+		res = append(res, page.RowPages()...)
+	}
+	if opts.ShouldDescend == nil {
+		return res
+	}
+	var filtered []*SubPageRef
+	for _, ref := range res {
+		if opts.ShouldDescend(ref) {
+			filtered = append(filtered, ref)
+		}
+	}
+	return filtered
+}
+
+// DownloadPagesRecursively downloads a page and all pages reachable from
+// it: sub-pages, linked pages and, optionally, rows of tables. Each page
+// is downloaded only once, even if it's reachable in multiple ways.
+// Pages are returned in the order they were discovered. If some pages
+// fail to download, we return the pages that were downloaded and
+// DownloadErrors.
+// The number of pages, their depth and size are limited (see
+// RecursiveOptions.MaxPages etc.). Pages that exceed the limits are not
+// downloaded and are in DownloadErrors with *LimitExceededError, which
+// can be checked with errors.As
+func (c *Client) DownloadPagesRecursively(rootID string, opts *RecursiveOptions) ([]*Page, error) {
+	return c.DownloadPagesRecursivelyCtx(context.Background(), rootID, opts)
+}
+
+// DownloadPagesRecursivelyCtx is like DownloadPagesRecursively but can be
+// canceled with ctx. After cancellation no new requests are sent and we
+// return pages that were fully downloaded so far and ctx.Err()
+func (c *Client) DownloadPagesRecursivelyCtx(ctx context.Context, rootID string, opts *RecursiveOptions) ([]*Page, error) {
+	return downloadPagesRecursively(ctx, c, rootID, opts, c.DownloadPageWithOptionsCtx)
+}
+
+// downloadPageFunc downloads a single page for downloadPagesRecursively
+type downloadPageFunc func(ctx context.Context, pageID string, opts *DownloadPageOptions) (*Page, error)
+
+func downloadPagesRecursively(ctx context.Context, c *Client, rootID string, opts *RecursiveOptions, download downloadPageFunc) ([]*Page, error) {
+	if opts == nil {
+		opts = &RecursiveOptions{}
+	}
+	id, err := ToDashID(rootID)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := c.concurrency(opts.Concurrency, DefaultRecursiveConcurrency)
+
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		sem   = make(chan struct{}, concurrency)
+		order []string
+		seen  = map[string]bool{}
+		pages = map[string]*Page{}
+		errs  = map[string]error{}
+		// first page skipped because of a limit, by name of the limit
+		limitErrs = map[string]*LimitExceededError{}
+	)
+	maxPages := recursiveLimit(opts.MaxPages, DefaultRecursiveMaxPages)
+	maxDepth := recursiveLimit(opts.MaxDepth, DefaultRecursiveMaxDepth)
+
+	pageOpts := &DownloadPageOptions{}
+	if opts.DownloadPageOptions != nil {
+		*pageOpts = *opts.DownloadPageOptions
+	}
+	pageOpts.ProgressFunc = serializeProgress(pageOpts.ProgressFunc)
+	if pageOpts.MaxBlocks == 0 {
+		pageOpts.MaxBlocks = recursiveLimit(opts.MaxBlocksPerPage, DefaultRecursiveMaxBlocksPerPage)
+	}
+
+	var visit func(pageID string, depth int)
+	visit = func(pageID string, depth int) {
+		// after cancellation we don't start downloading new pages
+		if ctx.Err() != nil {
+			return
+		}
+		mu.Lock()
+		if seen[pageID] {
+			mu.Unlock()
+			return
+		}
+		seen[pageID] = true
+		limit, max := "", 0
+		if maxDepth > 0 && depth > maxDepth {
+			limit, max = "MaxDepth", maxDepth
+		} else if maxPages > 0 && len(order) >= maxPages {
+			limit, max = "MaxPages", maxPages
+		}
+		if limit != "" {
+			if limitErrs[limit] == nil {
+				limitErrs[limit] = &LimitExceededError{
+					Limit:  limit,
+					Max:    max,
+					PageID: pageID,
+				}
+			}
+			mu.Unlock()
+			return
+		}
+		order = append(order, pageID)
+		mu.Unlock()
+
+		// we only start a goroutine when a slot is free so that pages
+		// with many sub-pages don't create many waiting goroutines.
+		// Goroutines free their slot before visiting sub-pages
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			errs[pageID] = ctx.Err()
+			mu.Unlock()
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			page, err := download(ctx, pageID, pageOpts)
+			if err == nil && opts.LoadMissingChildren {
+				err = page.loadMissingChildren(ctx, c)
+			}
+			<-sem
+			mu.Lock()
+			if err != nil {
+				errs[pageID] = err
+			} else {
+				pages[pageID] = page
+			}
+			mu.Unlock()
+			if err != nil {
+				return
+			}
+			for _, ref := range subPagesToDownload(page, opts) {
+				visit(ref.ID, depth+1)
+			}
+		}()
+	}
+	visit(id, 0)
+	wg.Wait()
+
+	var res []*Page
+	var downloadErrors DownloadErrors
+	for _, pageID := range order {
+		if page := pages[pageID]; page != nil {
+			res = append(res, page)
+			continue
+		}
+		downloadErrors = append(downloadErrors, &PageDownloadError{
+			PageID: pageID,
+			Err:    errs[pageID],
+		})
+	}
+	for _, limit := range []string{"MaxDepth", "MaxPages"} {
+		if e := limitErrs[limit]; e != nil {
+			downloadErrors = append(downloadErrors, &PageDownloadError{
+				PageID: e.PageID,
+				Err:    e,
+			})
+		}
+	}
+	if ctx.Err() != nil {
+		return res, ctx.Err()
+	}
+	if len(downloadErrors) > 0 {
+		return res, downloadErrors
+	}
+	return res, nil
+}
@@ -0,0 +1,282 @@
+package notionapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pageTreeTransport serves pages which only contain sub-pages.
+// Pages not in children don't exist
+type pageTreeTransport struct {
+	children map[string][]string
+	// maps id of a BlockAlias to id of the page it links to
+	aliases map[string]string
+
+	mu         sync.Mutex
+	downloaded map[string]int
+}
+
+func (t *pageTreeTransport) pageBlock(id string) map[string]interface{} {
+	if target, ok := t.aliases[id]; ok {
+		return map[string]interface{}{
+			"alive":        true,
+			"id":           id,
+			"type":         BlockAlias,
+			"parent_table": TableBlock,
+			"format": map[string]interface{}{
+				"alias_pointer": map[string]interface{}{"id": target, "table": TableBlock},
+			},
+		}
+	}
+	return map[string]interface{}{
+		"alive":        true,
+		"id":           id,
+		"type":         BlockPage,
+		"parent_table": TableBlock,
+		"content":      t.children[id],
+		"properties": map[string]interface{}{
+			"title": [][]string{{"page " + id[:4]}},
+		},
+	}
+}
+
+func (t *pageTreeTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, _ := readRequestBody(r)
+	var rsp interface{}
+	if strings.HasSuffix(r.URL.Path, "/loadPageChunk") {
+		var req loadPageChunkRequest
+		json.Unmarshal([]byte(body), &req)
+		blocks := map[string]interface{}{}
+		for _, id := range t.children[req.PageID] {
+			blocks[id] = map[string]interface{}{
+				"role":  RoleReader,
+				"value": t.pageBlock(id),
+			}
+		}
+		rsp = map[string]interface{}{
+			"recordMap": map[string]interface{}{"block": blocks},
+			"cursor":    map[string]interface{}{"stack": []interface{}{}},
+		}
+	} else {
+		var req syncRecordValuesRequest
+		json.Unmarshal([]byte(body), &req)
+		blocks := map[string]interface{}{}
+		for _, r := range req.Requests {
+			id := r.Pointer.ID
+			if _, ok := t.children[id]; !ok {
+				blocks[id] = map[string]interface{}{"role": "none"}
+				continue
+			}
+			t.mu.Lock()
+			t.downloaded[id]++
+			t.mu.Unlock()
+			blocks[id] = map[string]interface{}{
+				"role":  RoleReader,
+				"value": t.pageBlock(id),
+			}
+		}
+		rsp = map[string]interface{}{
+			"recordMap": map[string]interface{}{"block": blocks},
+		}
+	}
+	d, _ := json.Marshal(rsp)
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewReader(d)),
+		Request:    r,
+	}, nil
+}
+
+func TestDownloadPagesRecursively(t *testing.T) {
+	const (
+		root    = "00000000-0000-0000-0000-000000000001"
+		a       = "00000000-0000-0000-0000-00000000000a"
+		b       = "00000000-0000-0000-0000-00000000000b"
+		c       = "00000000-0000-0000-0000-00000000000c"
+		missing = "00000000-0000-0000-0000-0000000000ff"
+	)
+	transport := &pageTreeTransport{
+		children: map[string][]string{
+			root: {a, b},
+			// b is reachable from root and from a
+			a: {b, c},
+			b: {missing},
+			// link back to root
+			c: {root},
+		},
+		downloaded: map[string]int{},
+	}
+	client := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+	pages, err := client.DownloadPagesRecursively(root, &RecursiveOptions{Concurrency: 2})
+	var ids []string
+	for _, p := range pages {
+		ids = append(ids, p.ID)
+	}
+	sort.Strings(ids)
+	assert.Equal(t, []string{root, a, b, c}, ids)
+	assert.Equal(t, pages[0].ID, root)
+	for _, id := range ids {
+		assert.Equal(t, 1, transport.downloaded[id])
+	}
+
+	downloadErrors, ok := err.(DownloadErrors)
+	assert.True(t, ok)
+	assert.Equal(t, 1, len(downloadErrors))
+	assert.Equal(t, missing, downloadErrors[0].PageID)
+}
+
+func TestDownloadPagesRecursivelyProgress(t *testing.T) {
+	const (
+		root    = "00000000-0000-0000-0000-000000000001"
+		a       = "00000000-0000-0000-0000-00000000000a"
+		missing = "00000000-0000-0000-0000-0000000000ff"
+	)
+	newClient := func() *Client {
+		transport := &pageTreeTransport{
+			children: map[string][]string{
+				root: {a},
+				a:    {missing},
+			},
+			downloaded: map[string]int{},
+		}
+		return &Client{
+			HTTPClient:  &http.Client{Transport: transport},
+			RateLimiter: NewTokenBucket(1000, 100),
+		}
+	}
+
+	// ProgressFunc is never called concurrently so we don't need a lock
+	var events []ProgressEvent
+	opts := &RecursiveOptions{
+		Concurrency: 2,
+		DownloadPageOptions: &DownloadPageOptions{
+			ProgressFunc: func(ev ProgressEvent) {
+				events = append(events, ev)
+			},
+		},
+	}
+	_, err := newClient().DownloadPagesRecursively(root, opts)
+	_, ok := err.(DownloadErrors)
+	assert.True(t, ok)
+	finished := map[string]ProgressEvent{}
+	for _, ev := range events {
+		if ev.Type == ProgressPageFinished {
+			finished[ev.PageID] = ev
+		}
+	}
+	assert.Equal(t, 3, len(finished))
+	assert.Nil(t, finished[root].Err)
+	assert.Equal(t, 1, finished[root].Blocks)
+	assert.True(t, finished[root].Bytes > 0)
+	assert.True(t, IsNotFound(finished[missing].Err))
+	assert.Equal(t, ProgressPageStarted, events[0].Type)
+	assert.Equal(t, root, events[0].PageID)
+
+	// canceling after the root page was downloaded doesn't start
+	// downloading its sub-pages
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var started []string
+	opts.DownloadPageOptions.ProgressFunc = func(ev ProgressEvent) {
+		switch ev.Type {
+		case ProgressPageStarted:
+			started = append(started, ev.PageID)
+		case ProgressPageFinished:
+			cancel()
+		}
+	}
+	pages, err := newClient().DownloadPagesRecursivelyCtx(ctx, root, opts)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, len(pages))
+	assert.Equal(t, []string{root}, started)
+}
+
+func TestDownloadPagesRecursivelyLimits(t *testing.T) {
+	const (
+		root = "00000000-0000-0000-0000-000000000001"
+		a    = "00000000-0000-0000-0000-00000000000a"
+		b    = "00000000-0000-0000-0000-00000000000b"
+		c    = "00000000-0000-0000-0000-00000000000c"
+	)
+	download := func(opts *RecursiveOptions) ([]string, error) {
+		transport := &pageTreeTransport{
+			children: map[string][]string{
+				root: {a, b},
+				a:    {c},
+				b:    {},
+				c:    {},
+			},
+			downloaded: map[string]int{},
+		}
+		client := &Client{
+			HTTPClient:  &http.Client{Transport: transport},
+			RateLimiter: NewTokenBucket(1000, 100),
+		}
+		// downloading one page at a time makes the order deterministic
+		opts.Concurrency = 1
+		pages, err := client.DownloadPagesRecursively(root, opts)
+		var ids []string
+		for _, p := range pages {
+			ids = append(ids, p.ID)
+		}
+		sort.Strings(ids)
+		return ids, err
+	}
+	limitErr := func(err error) *LimitExceededError {
+		var e *LimitExceededError
+		if errors.As(err, &e) {
+			return e
+		}
+		return nil
+	}
+
+	ids, err := download(&RecursiveOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{root, a, b, c}, ids)
+
+	ids, err = download(&RecursiveOptions{MaxDepth: 1})
+	assert.Equal(t, []string{root, a, b}, ids)
+	e := limitErr(err)
+	if assert.NotNil(t, e) {
+		assert.Equal(t, "MaxDepth", e.Limit)
+		assert.Equal(t, 1, e.Max)
+		assert.Equal(t, c, e.PageID)
+	}
+
+	ids, err = download(&RecursiveOptions{MaxPages: 2})
+	assert.Equal(t, 2, len(ids))
+	e = limitErr(err)
+	if assert.NotNil(t, e) {
+		assert.Equal(t, "MaxPages", e.Limit)
+	}
+
+	ids, err = download(&RecursiveOptions{MaxBlocksPerPage: 1})
+	assert.Empty(t, ids)
+	e = limitErr(err)
+	if assert.NotNil(t, e) {
+		assert.Equal(t, "MaxBlocks", e.Limit)
+		assert.Equal(t, root, e.PageID)
+	}
+
+	ids, err = download(&RecursiveOptions{
+		ShouldDescend: func(ref *SubPageRef) bool {
+			return ref.ID != a
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{root, b}, ids)
+}
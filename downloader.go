@@ -0,0 +1,269 @@
+package notionapi
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultDownloaderConcurrency is the number of files downloaded at
+	// the same time by Downloader that doesn't have Concurrency set
+	DefaultDownloaderConcurrency = 4
+)
+
+// DownloadRequest describes a file to download with Downloader.GetAll
+type DownloadRequest struct {
+	URL string
+	// block with the file, needed for files uploaded to Notion (see
+	// Client.DownloadFile)
+	Block *Block
+}
+
+// DownloadResult is the result of downloading a file with Downloader
+type DownloadResult struct {
+	URL   string
+	Block *Block
+	// nil if Err is set
+	File *FileDownload
+	// true if the file was already downloaded, or is in CacheDir
+	Cached bool
+	Err    error
+}
+
+// downloadEntry is a file being downloaded or already downloaded
+type downloadEntry struct {
+	done chan struct{}
+	file *FileDownload
+	err  error
+}
+
+// Downloader downloads files, like images of pages, with Client using
+// up to Concurrency downloads at the same time. A url is only downloaded
+// once, even if many blocks refer to it or it's requested while being
+// downloaded. If CacheDir is set, downloaded files are also saved there
+// and re-used by later runs.
+// Files on notion server are downloaded respecting Client.RateLimiter,
+// files on other hosts are not limited
+type Downloader struct {
+	Client *Client
+	// maximum number of files downloaded at the same time. If not set,
+	// DefaultDownloaderConcurrency or Client.MaxConcurrentRequests, if set
+	Concurrency int
+	// optional directory where downloaded files are cached. Files are
+	// named by sha1 of their content so a file referenced by different
+	// urls is only saved once
+	CacheDir string
+	// Progress, if set, is called after every file requested with Get or
+	// GetAll is downloaded or failed to download. It can be called from
+	// multiple goroutines at the same time
+	Progress func(res *DownloadResult)
+
+	mu      sync.Mutex
+	sem     chan struct{}
+	entries map[string]*downloadEntry
+}
+
+// NewDownloader returns a downloader that downloads files with client
+func NewDownloader(client *Client) *Downloader {
+	return &Downloader{
+		Client: client,
+	}
+}
+
+// Get downloads a file, like Client.DownloadFile, or returns it from the
+// cache if it was already downloaded
+func (d *Downloader) Get(uri string, block *Block) (*FileDownload, error) {
+	return d.GetCtx(context.Background(), uri, block)
+}
+
+// GetCtx is like Get but can be canceled with ctx
+func (d *Downloader) GetCtx(ctx context.Context, uri string, block *Block) (*FileDownload, error) {
+	res := d.get(ctx, uri, block)
+	return res.File, res.Err
+}
+
+// GetAll downloads files in parallel. Results are in the order of reqs
+func (d *Downloader) GetAll(reqs []DownloadRequest) []*DownloadResult {
+	return d.GetAllCtx(context.Background(), reqs)
+}
+
+// GetAllCtx is like GetAll but can be canceled with ctx
+func (d *Downloader) GetAllCtx(ctx context.Context, reqs []DownloadRequest) []*DownloadResult {
+	res := make([]*DownloadResult, len(reqs))
+	// a worker per download slot instead of a goroutine per file
+	next := make(chan int)
+	var wg sync.WaitGroup
+	n := d.concurrency()
+	if n > len(reqs) {
+		n = len(reqs)
+	}
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				res[i] = d.get(ctx, reqs[i].URL, reqs[i].Block)
+			}
+		}()
+	}
+	for i := range reqs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return res
+}
+
+func (d *Downloader) concurrency() int {
+	return d.Client.concurrency(d.Concurrency, DefaultDownloaderConcurrency)
+}
+
+func (d *Downloader) get(ctx context.Context, uri string, block *Block) *DownloadResult {
+	file, cached, err := d.download(ctx, uri, block)
+	res := &DownloadResult{
+		URL:    uri,
+		Block:  block,
+		File:   file,
+		Cached: cached,
+		Err:    err,
+	}
+	if d.Progress != nil {
+		d.Progress(res)
+	}
+	return res
+}
+
+// download returns a file that was already downloaded or is being
+// downloaded or downloads it. cached is true if we didn't download it
+func (d *Downloader) download(ctx context.Context, uri string, block *Block) (*FileDownload, bool, error) {
+	d.mu.Lock()
+	if d.entries == nil {
+		d.entries = map[string]*downloadEntry{}
+		d.sem = make(chan struct{}, d.concurrency())
+	}
+	if e := d.entries[uri]; e != nil {
+		d.mu.Unlock()
+		select {
+		case <-e.done:
+			return e.file, e.err == nil, e.err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+	e := &downloadEntry{done: make(chan struct{})}
+	d.entries[uri] = e
+	d.mu.Unlock()
+
+	cached := false
+	e.file = d.loadCached(uri)
+	if e.file != nil {
+		cached = true
+	} else {
+		e.file, e.err = d.downloadLimited(ctx, uri, block)
+		if e.err == nil {
+			if err := d.saveCached(uri, e.file); err != nil {
+				// not fatal, we'll download the file again next time
+				logWarn(d.Client, "Downloader: failed to cache '%s': '%s'\n", uri, err)
+			}
+		}
+	}
+	if e.err != nil {
+		// failed downloads are tried again by the next Get
+		d.mu.Lock()
+		delete(d.entries, uri)
+		d.mu.Unlock()
+	}
+	close(e.done)
+	return e.file, cached, e.err
+}
+
+// downloadLimited downloads uri when one of Concurrency slots is free
+func (d *Downloader) downloadLimited(ctx context.Context, uri string, block *Block) (*FileDownload, error) {
+	select {
+	case d.sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-d.sem }()
+	return d.Client.DownloadFileCtx(ctx, uri, block)
+}
+
+// cacheIndexPath returns path of the file in CacheDir that has the name
+// of the cached file with content of uri, and its content type
+func (d *Downloader) cacheIndexPath(uri string) string {
+	sha := sha1.Sum([]byte(uri))
+	return filepath.Join(d.CacheDir, "urls", hex.EncodeToString(sha[:]))
+}
+
+// loadCached returns a file downloaded from uri from CacheDir, nil if
+// it's not there
+func (d *Downloader) loadCached(uri string) *FileDownload {
+	if d.CacheDir == "" {
+		return nil
+	}
+	idx, err := ioutil.ReadFile(d.cacheIndexPath(uri))
+	if err != nil {
+		return nil
+	}
+	// index is: name of the file with content, content type
+	parts := strings.SplitN(string(idx), "\n", 2)
+	if len(parts) != 2 || parts[0] != filepath.Base(parts[0]) {
+		return nil
+	}
+	data, err := ioutil.ReadFile(filepath.Join(d.CacheDir, parts[0]))
+	if err != nil {
+		return nil
+	}
+	return &FileDownload{
+		Data:        data,
+		ContentType: parts[1],
+		URL:         uri,
+		Size:        int64(len(data)),
+	}
+}
+
+// saveCached saves a file downloaded from uri to CacheDir
+func (d *Downloader) saveCached(uri string, file *FileDownload) error {
+	if d.CacheDir == "" {
+		return nil
+	}
+	name := assetFileName(file.Data, uri, file.ContentType)
+	idxPath := d.cacheIndexPath(uri)
+	if err := os.MkdirAll(filepath.Dir(idxPath), 0755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(d.CacheDir, name), file.Data); err != nil {
+		return err
+	}
+	return writeFileAtomic(idxPath, []byte(name+"\n"+file.ContentType))
+}
+
+// writeFileAtomic writes a file via a temporary file so that a partially
+// written file is never visible under path
+func writeFileAtomic(path string, d []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(d)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		// TempFile creates files only readable by the owner
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package notionapi
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// countingLimiter is a RateLimiter that counts requests
+type countingLimiter struct {
+	mu    sync.Mutex
+	waits int
+}
+
+func (l *countingLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	l.waits++
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *countingLimiter) Throttled(retryAfter time.Duration) {}
+
+func TestDownloader(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	active, maxActive := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+		// give other downloads a chance to start
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+		if r.URL.Path == "/missing.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("content of " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	limiter := &countingLimiter{}
+	c := &Client{RetryPolicy: &NoRetryPolicy, RateLimiter: limiter}
+	d := NewDownloader(c)
+	d.Concurrency = 2
+	var progressMu sync.Mutex
+	var progress []string
+	d.Progress = func(res *DownloadResult) {
+		progressMu.Lock()
+		progress = append(progress, res.URL)
+		progressMu.Unlock()
+	}
+
+	var reqs []DownloadRequest
+	for i := 0; i < 5; i++ {
+		reqs = append(reqs, DownloadRequest{URL: server.URL + "/a.png"})
+	}
+	reqs = append(reqs, DownloadRequest{URL: server.URL + "/b.png"})
+	reqs = append(reqs, DownloadRequest{URL: server.URL + "/c.png"})
+	reqs = append(reqs, DownloadRequest{URL: server.URL + "/missing.png"})
+	results := d.GetAll(reqs)
+	assert.Equal(t, len(reqs), len(results))
+	for i, res := range results[:7] {
+		assert.NoError(t, res.Err)
+		assert.Equal(t, reqs[i].URL, res.URL)
+	}
+	assert.Equal(t, "content of /a.png", string(results[0].File.Data))
+	assert.Equal(t, "content of /c.png", string(results[6].File.Data))
+	assert.Equal(t, "image/png", results[6].File.ContentType)
+	assert.Error(t, results[7].Err)
+	assert.Nil(t, results[7].File)
+	assert.Equal(t, map[string]int{"/a.png": 1, "/b.png": 1, "/c.png": 1, "/missing.png": 1}, hits)
+	assert.True(t, maxActive <= 2)
+	assert.Equal(t, len(reqs), len(progress))
+	// other hosts are not rate limited
+	assert.Equal(t, 0, limiter.waits)
+
+	// completed downloads are re-used, failed are tried again
+	file, err := d.Get(server.URL+"/b.png", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "content of /b.png", string(file.Data))
+	_, err = d.Get(server.URL+"/missing.png", nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, hits["/b.png"])
+	assert.Equal(t, 2, hits["/missing.png"])
+}
+
+func TestDownloaderCacheDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notionapi-downloader")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("same content"))
+	}))
+	defer server.Close()
+
+	c := &Client{RetryPolicy: &NoRetryPolicy}
+	d := NewDownloader(c)
+	d.CacheDir = dir
+	res := d.GetAll([]DownloadRequest{{URL: server.URL + "/a.png"}, {URL: server.URL + "/b"}})
+	assert.NoError(t, res[0].Err)
+	assert.NoError(t, res[1].Err)
+	assert.False(t, res[0].Cached)
+	assert.Equal(t, 2, hits)
+	// the same content is stored once, next to the index of urls
+	files, _ := ioutil.ReadDir(dir)
+	var names []string
+	for _, fi := range files {
+		names = append(names, fi.Name())
+	}
+	assert.Equal(t, []string{"6352ab6b14233af72836a02b08190a493089eb9a.png", "urls"}, names)
+
+	// a new downloader uses files cached by the previous one
+	d = NewDownloader(c)
+	d.CacheDir = dir
+	res = d.GetAll([]DownloadRequest{{URL: server.URL + "/a.png"}, {URL: server.URL + "/b"}})
+	assert.Equal(t, 2, hits)
+	for _, r := range res {
+		assert.NoError(t, r.Err)
+		assert.True(t, r.Cached)
+		assert.Equal(t, "same content", string(r.File.Data))
+		assert.Equal(t, "image/png", r.File.ContentType)
+	}
+}
@@ -0,0 +1,65 @@
+package notionapi
+
+import "fmt"
+
+// MathDelimiters describes how equations are marked in exported markdown
+// and html so that KaTeX or MathJax can typeset them
+type MathDelimiters struct {
+	// e.g. "$" and "$" for inline equations
+	InlineStart string
+	InlineEnd   string
+	// e.g. "$$" and "$$" for equation blocks
+	BlockStart string
+	BlockEnd   string
+}
+
+var (
+	// MathDelimitersDollars uses $...$ and $$...$$. It's the default
+	// for markdown
+	MathDelimitersDollars = &MathDelimiters{
+		InlineStart: "$",
+		InlineEnd:   "$",
+		BlockStart:  "$$",
+		BlockEnd:    "$$",
+	}
+	// MathDelimitersLaTeX uses \(...\) and \[...\]. It's the default
+	// for html
+	MathDelimitersLaTeX = &MathDelimiters{
+		InlineStart: `\(`,
+		InlineEnd:   `\)`,
+		BlockStart:  `\[`,
+		BlockEnd:    `\]`,
+	}
+)
+
+// LaTeX is emitted verbatim in markdown because escaping would change
+// the equation
+func inlineEquationToMarkdown(latex string, d *MathDelimiters) string {
+	if d == nil {
+		d = MathDelimitersDollars
+	}
+	return d.InlineStart + latex + d.InlineEnd
+}
+
+func blockEquationToMarkdown(latex string, d *MathDelimiters) string {
+	if d == nil {
+		d = MathDelimitersDollars
+	}
+	return d.BlockStart + "\n" + latex + "\n" + d.BlockEnd
+}
+
+func inlineEquationToHTML(latex string, d *MathDelimiters) string {
+	if d == nil {
+		d = MathDelimitersLaTeX
+	}
+	s := escapeHTML(latex)
+	return fmt.Sprintf(`<span class="katex-src" data-latex="%s">%s%s%s</span>`, s, escapeHTML(d.InlineStart), s, escapeHTML(d.InlineEnd))
+}
+
+func blockEquationToHTML(latex string, d *MathDelimiters) string {
+	if d == nil {
+		d = MathDelimitersLaTeX
+	}
+	s := escapeHTML(latex)
+	return fmt.Sprintf(`<div class="equation katex-src" data-latex="%s">%s%s%s</div>`, s, escapeHTML(d.BlockStart), s, escapeHTML(d.BlockEnd))
+}
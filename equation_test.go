@@ -0,0 +1,64 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseInlineEquation(t *testing.T) {
+	raw := []interface{}{
+		[]interface{}{"area: "},
+		[]interface{}{InlineEquation, []interface{}{[]interface{}{"e", `\pi r^{2}`}}},
+	}
+	blocks, err := parseInlineBlocks(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(blocks))
+	assert.Equal(t, `\pi r^{2}`, blocks[1].Equation)
+	assert.False(t, blocks[1].IsPlain())
+}
+
+func TestEquationExport(t *testing.T) {
+	text := &Block{
+		Type: BlockText,
+		InlineContent: []*InlineBlock{
+			{Text: "area: "},
+			{Text: InlineEquation, Equation: `\pi r^{2} < x_1`},
+		},
+	}
+	eq := &Block{
+		ID:       "1",
+		Type:     BlockEquation,
+		Equation: `\sum_{i=0}^{n} i`,
+	}
+	page := testPage(text, eq)
+
+	got := string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "area: $\\pi r^{2} < x_1$\n")
+	assert.Contains(t, got, "$$\n\\sum_{i=0}^{n} i\n$$\n")
+
+	got = string(ToMarkdown(page, &MarkdownOptions{Math: MathDelimitersLaTeX}))
+	assert.Contains(t, got, "area: \\(\\pi r^{2} < x_1\\)\n")
+	assert.Contains(t, got, "\\[\n\\sum_{i=0}^{n} i\n\\]\n")
+
+	got = string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<p>area: <span class="katex-src" data-latex="\pi r^{2} &lt; x_1">\(\pi r^{2} &lt; x_1\)</span></p>`)
+	assert.Contains(t, got, `<div class="equation katex-src" data-latex="\sum_{i=0}^{n} i">\[\sum_{i=0}^{n} i\]</div>`)
+
+	assert.Equal(t, "Test page\narea: \\pi r^{2} < x_1\n\\sum_{i=0}^{n} i\n", page.ToText())
+}
+
+func TestEmptyEquation(t *testing.T) {
+	block := parseTestBlock(t, `{"id": "1", "type": "equation", "alive": true}`)
+	assert.Equal(t, "", block.Equation)
+	block = parseTestBlock(t, `{"id": "1", "type": "equation", "alive": true, "properties": {"title": [["E=mc^2"]]}}`)
+	assert.Equal(t, "E=mc^2", block.Equation)
+	assert.Nil(t, block.InlineContent)
+
+	page := testPage(&Block{ID: "1", Type: BlockEquation}, textBlock(BlockText, "after"))
+	assert.Equal(t, "# Test page\n\nafter\n\n", string(ToMarkdown(page, nil)))
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.NotContains(t, got, "equation")
+	got = string(ToHTML(page, &HTMLOptions{Fragment: true, NotionCompatible: true}))
+	assert.NotContains(t, got, "equation")
+}
@@ -0,0 +1,512 @@
+package notionapi
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// ExportFormatMarkdown is for exporting pages as markdown
+	ExportFormatMarkdown = "markdown"
+	// ExportFormatHTML is for exporting pages as html
+	ExportFormatHTML = "html"
+)
+
+// ExportTreeOptions allows customizing ExportPageTree
+type ExportTreeOptions struct {
+	// Progress, if set, is called after a page has been written to disk
+	Progress func(page *ExportedPage)
+	// if true, images and file attachments are downloaded to assets
+	// directory and exported pages link to local copies
+	DownloadAssets bool
+	// optional Downloader used to download assets, e.g. to share
+	// downloaded files between exports or to cache them on disk
+	Downloader *Downloader
+	// if true, we use manifest of the previous export to dir and only
+	// download and write pages whose version changed. Files of pages that
+	// were deleted or are no longer reachable are removed.
+	// Note: a new sub-page nested in a block of an unchanged page (e.g.
+	// in a toggle) is not detected because it doesn't change page version
+	Incremental bool
+	// optional CachingClient used to download pages. With
+	// CachingClient.SetOffline, pages are exported from the cache and
+	// sub-pages that are not cached are reported in
+	// ExportTreeResult.MissingPages instead of failing the export
+	Cache *CachingClient
+}
+
+// AssetError describes a failure to download an image or a file
+type AssetError struct {
+	PageID  string
+	BlockID string
+	URL     string
+	Err     error
+}
+
+func (e *AssetError) Error() string {
+	return fmt.Sprintf("failed to download '%s' from block %s: %s", e.URL, e.BlockID, e.Err)
+}
+
+// ExportedPage describes a page written to disk by ExportPageTree
+type ExportedPage struct {
+	ID    string
+	Title string
+	// path of the file, relative to export directory, with "/" separators
+	Path string
+	// id of the page from which we reached this page, "" for root page
+	ParentID string
+	// 0 for root page
+	Depth int
+	// version of the root block of the page
+	Version        int64
+	LastEditedTime int64
+	// sha1 of the content of exported file
+	ContentHash string
+	// true if the page didn't change since previous export and was not
+	// downloaded (Page is nil) or re-written
+	Skipped bool
+
+	Page *Page
+}
+
+// ExportTreeResult describes the result of ExportPageTree
+type ExportTreeResult struct {
+	// exported pages in the order they were downloaded, root page first
+	Pages []*ExportedPage
+	// images and files we failed to download. Exported pages link to
+	// their original urls
+	AssetErrors []*AssetError
+	// in incremental mode, ids of pages from previous export that were
+	// deleted or are no longer reachable. Their files were removed
+	Removed []string
+	// sub-pages that were not exported because we're offline and they're
+	// not in the cache (see ExportTreeOptions.Cache). Links to them point
+	// to notion.so and in incremental mode their files are kept
+	MissingPages []*PageDownloadError
+}
+
+// Slugify converts a title to a string that can be used in file names
+// and urls: lowercase letters and digits separated by "-"
+func Slugify(s string) string {
+	var b strings.Builder
+	prevDash := true
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			prevDash = false
+			continue
+		}
+		if !prevDash {
+			b.WriteByte('-')
+			prevDash = true
+		}
+	}
+	res := strings.TrimSuffix(b.String(), "-")
+	// keep file names reasonably short
+	const maxLen = 64
+	if len(res) > maxLen {
+		n := 0
+		for i := range res {
+			if i > maxLen {
+				break
+			}
+			n = i
+		}
+		res = strings.TrimSuffix(res[:n], "-")
+	}
+	return res
+}
+
+func exportFileExt(format string) (string, error) {
+	switch format {
+	case ExportFormatMarkdown, "md":
+		return ".md", nil
+	case ExportFormatHTML:
+		return ".html", nil
+	}
+	return "", fmt.Errorf("'%s' is not a valid export format", format)
+}
+
+// pageFileName returns a file name that is stable across exports even if
+// several pages have the same title
+func pageFileName(page *Page, ext string) string {
+	id := strings.Replace(page.ID, "-", "", -1)
+	slug := Slugify(page.Root.Title)
+	if slug == "" {
+		return id + ext
+	}
+	return slug + "-" + id + ext
+}
+
+// relativeURL returns url of file at path to relative to file at path from.
+// Both paths use "/" separators
+func relativeURL(from, to string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(to))
+	if err != nil {
+		return to
+	}
+	return filepath.ToSlash(rel)
+}
+
+// ExportPageTree downloads a page with a given id and all pages reachable
+// from it via sub-pages and links to pages, converts them to format
+// (ExportFormatMarkdown or ExportFormatHTML) and saves them under dir.
+// Sub-pages are saved in a directory named like the file of the page
+// from which we reached them. Links between exported pages are relative.
+// index.md (or index.html) with links to all pages and manifest.json (see
+// ExportManifest) are written to dir.
+func ExportPageTree(client *Client, rootID string, dir string, format string, opts *ExportTreeOptions) (*ExportTreeResult, error) {
+	if opts == nil {
+		opts = &ExportTreeOptions{}
+	}
+	ext, err := exportFileExt(format)
+	if err != nil {
+		return nil, err
+	}
+	id, err := ToDashID(rootID)
+	if err != nil {
+		return nil, err
+	}
+
+	var prev *ExportManifest
+	versions := map[string]int64{}
+	if opts.Incremental {
+		prev, err = readPreviousManifest(dir, id, ext)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if prev != nil {
+		var ids []string
+		for pageID := range prev.Pages {
+			ids = append(ids, pageID)
+		}
+		sort.Strings(ids)
+		versions, err = client.GetBlockVersions(ids)
+		if errors.Is(err, ErrOffline) {
+			// we can't tell which pages changed so we export all pages
+			// from the cache
+			versions, err = map[string]int64{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	download := client.DownloadPage
+	if opts.Cache != nil {
+		download = opts.Cache.DownloadPage
+	}
+
+	res := &ExportTreeResult{}
+	idToExported := map[string]*ExportedPage{}
+	missing := map[string]bool{}
+	// pages are downloaded in breadth-first order so that pages are saved
+	// as close to the root as possible
+	toVisit := []*ExportedPage{{ID: id}}
+	for len(toVisit) > 0 {
+		exp := toVisit[0]
+		toVisit = toVisit[1:]
+		if _, ok := idToExported[exp.ID]; ok || missing[exp.ID] {
+			// already reached via a different page or a cycle
+			continue
+		}
+		var prevPage *ExportManifestPage
+		if prev != nil {
+			prevPage = prev.Pages[exp.ID]
+		}
+		if v, ok := versions[exp.ID]; ok && prevPage != nil && v == prevPage.Version {
+			exp.Skipped = true
+			exp.Title = prevPage.Title
+			exp.Path = prevPage.Path
+			exp.Version = prevPage.Version
+			exp.LastEditedTime = prevPage.LastEditedTime
+			exp.ContentHash = prevPage.ContentHash
+			idToExported[exp.ID] = exp
+			res.Pages = append(res.Pages, exp)
+			// we didn't download the page so we use sub-pages from
+			// previous export
+			for _, subID := range prev.children(exp.ID) {
+				sub := &ExportedPage{
+					ID:       subID,
+					ParentID: exp.ID,
+					Depth:    exp.Depth + 1,
+				}
+				toVisit = append(toVisit, sub)
+			}
+			continue
+		}
+
+		page, err := download(exp.ID)
+		if err != nil && exp.Depth > 0 && errors.Is(err, ErrOffline) {
+			missing[exp.ID] = true
+			res.MissingPages = append(res.MissingPages, &PageDownloadError{
+				PageID: exp.ID,
+				Err:    err,
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		exp.Page = page
+		exp.Title = page.Root.Title
+		exp.Version = page.Root.Version
+		exp.LastEditedTime = page.Root.LastEditedTime
+		name := pageFileName(page, ext)
+		if prevPage != nil {
+			// keep the path so that links from unchanged pages still work
+			exp.Path = prevPage.Path
+		} else if parent := idToExported[exp.ParentID]; parent != nil {
+			parentDir := strings.TrimSuffix(parent.Path, ext)
+			exp.Path = parentDir + "/" + name
+		} else {
+			exp.Path = name
+		}
+		idToExported[exp.ID] = exp
+		// page.ID is what the server returned, which might differ
+		// in formatting from the id we requested
+		idToExported[page.ID] = exp
+		res.Pages = append(res.Pages, exp)
+
+		for _, ref := range page.SubPages() {
+			if _, ok := idToExported[ref.ID]; ok {
+				continue
+			}
+			sub := &ExportedPage{
+				ID:       ref.ID,
+				ParentID: exp.ID,
+				Depth:    exp.Depth + 1,
+			}
+			toVisit = append(toVisit, sub)
+		}
+	}
+
+	// maps block id to path of the downloaded image or file
+	blockToAsset := map[string]string{}
+	if opts.DownloadAssets {
+		downloader := opts.Downloader
+		if downloader == nil {
+			downloader = NewDownloader(client)
+		}
+		blockToAsset = downloadAssets(downloader, res, dir)
+	}
+	assetURL := func(from string) func(block *Block, uri string) string {
+		return func(block *Block, uri string) string {
+			if assetPath, ok := blockToAsset[block.ID]; ok {
+				return relativeURL(from, assetPath)
+			}
+			return uri
+		}
+	}
+
+	for _, exp := range res.Pages {
+		if exp.Skipped {
+			continue
+		}
+		from := exp.Path
+		pageURL := func(pageID string) string {
+			if to, ok := idToExported[pageID]; ok {
+				return relativeURL(from, to.Path)
+			}
+			return "https://www.notion.so/" + strings.Replace(pageID, "-", "", -1)
+		}
+		var d []byte
+		if ext == ".html" {
+			htmlOpts := &HTMLOptions{
+				PageURL:         pageURL,
+				RewriteImageURL: assetURL(from),
+				RewriteFileURL:  assetURL(from),
+			}
+			d = ToHTML(exp.Page, htmlOpts)
+		} else {
+			mdOpts := &MarkdownOptions{
+				PageURL:         pageURL,
+				RewriteImageURL: assetURL(from),
+				RewriteFileURL:  assetURL(from),
+			}
+			d = ToMarkdown(exp.Page, mdOpts)
+		}
+		sha := sha1.Sum(d)
+		exp.ContentHash = hex.EncodeToString(sha[:])
+		filePath := filepath.Join(dir, filepath.FromSlash(exp.Path))
+		err = os.MkdirAll(filepath.Dir(filePath), 0755)
+		if err != nil {
+			return nil, err
+		}
+		err = ioutil.WriteFile(filePath, d, 0644)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Progress != nil {
+			opts.Progress(exp)
+		}
+	}
+	if prev != nil {
+		res.Removed, err = removeUnexportedPages(dir, prev, idToExported, missing)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = writeExportIndex(dir, format, ext, res.Pages)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// readPreviousManifest returns manifest of the previous export of the same
+// root page in the same format or nil if there isn't one
+func readPreviousManifest(dir string, rootID string, ext string) (*ExportManifest, error) {
+	m, err := ReadExportManifest(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if prevExt, _ := exportFileExt(m.Format); prevExt != ext || m.RootID != rootID {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// removeUnexportedPages removes files of pages from previous export that
+// were not exported this time, except pages missing from the cache.
+// Returns their ids
+func removeUnexportedPages(dir string, prev *ExportManifest, exported map[string]*ExportedPage, missing map[string]bool) ([]string, error) {
+	var res []string
+	for id, p := range prev.Pages {
+		if _, ok := exported[id]; ok || missing[id] {
+			continue
+		}
+		err := os.Remove(filepath.Join(dir, filepath.FromSlash(p.Path)))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		res = append(res, id)
+	}
+	sort.Strings(res)
+	return res, nil
+}
+
+const assetsDir = "assets"
+
+// assetDownloadURL returns url from which we can download an image or
+// a file in the block or "" if block doesn't have an asset
+func assetDownloadURL(block *Block) string {
+	switch block.Type {
+	case BlockImage:
+		if isNotionAttachment(block.Source) {
+			return notionSignedURL(block.Source, block.ID)
+		}
+		return block.ImageURL
+	case BlockVideo:
+		if block.FormatVideo == nil || !block.FormatVideo.IsUpload {
+			return ""
+		}
+		return notionSignedURL(block.FormatVideo.VideoURL, block.ID)
+	case BlockFile, BlockAudio, BlockPDF:
+		uri := block.Source
+		if isNotionAttachment(uri) {
+			uri = notionSignedURL(uri, block.ID)
+		}
+		return uri
+	}
+	return ""
+}
+
+func findAssetBlocks(blocks []*Block, res []*Block) []*Block {
+	for _, block := range blocks {
+		if block == nil || block.Type == BlockPage {
+			continue
+		}
+		if assetDownloadURL(block) != "" {
+			res = append(res, block)
+		}
+		res = findAssetBlocks(block.Content, res)
+	}
+	return res
+}
+
+// assetFileName returns a name based on a hash of content so that the
+// same file referenced from multiple pages is only saved once
+func assetFileName(d []byte, uri string, contentType string) string {
+	sha := sha1.Sum(d)
+	name := hex.EncodeToString(sha[:])
+	ext := ""
+	if u, err := url.Parse(uri); err == nil {
+		ext = path.Ext(u.Path)
+	}
+	if ext == "" && contentType != "" {
+		if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+			ext = exts[0]
+		}
+	}
+	return name + strings.ToLower(ext)
+}
+
+// downloadAssets downloads images and files in exported pages, in
+// parallel, and saves them in assets directory. Returns a map from block
+// id to path of the saved file. Failures are recorded in res.AssetErrors.
+func downloadAssets(downloader *Downloader, res *ExportTreeResult, dir string) map[string]string {
+	var reqs []DownloadRequest
+	var pageIDs []string
+	var blocks []*Block
+	for _, exp := range res.Pages {
+		if exp.Page == nil {
+			continue
+		}
+		for _, block := range findAssetBlocks(exp.Page.Root.Content, nil) {
+			reqs = append(reqs, DownloadRequest{URL: assetDownloadURL(block)})
+			pageIDs = append(pageIDs, exp.ID)
+			blocks = append(blocks, block)
+		}
+	}
+	results := downloader.GetAll(reqs)
+
+	blockToAsset := map[string]string{}
+	uriToAsset := map[string]string{}
+	for i, r := range results {
+		block := blocks[i]
+		uri := r.URL
+		if assetPath, ok := uriToAsset[uri]; ok {
+			blockToAsset[block.ID] = assetPath
+			continue
+		}
+		err := r.Err
+		if err == nil {
+			name := assetFileName(r.File.Data, block.Source, r.File.ContentType)
+			assetPath := assetsDir + "/" + name
+			filePath := filepath.Join(dir, assetsDir, name)
+			err = os.MkdirAll(filepath.Dir(filePath), 0755)
+			if err == nil {
+				err = ioutil.WriteFile(filePath, r.File.Data, 0644)
+			}
+			if err == nil {
+				uriToAsset[uri] = assetPath
+				blockToAsset[block.ID] = assetPath
+			}
+		}
+		if err != nil {
+			assetErr := &AssetError{
+				PageID:  pageIDs[i],
+				BlockID: block.ID,
+				URL:     uri,
+				Err:     err,
+			}
+			res.AssetErrors = append(res.AssetErrors, assetErr)
+		}
+	}
+	return blockToAsset
+}
@@ -0,0 +1,205 @@
+package notionapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ExportManifestFileName is the name of the manifest file written by
+	// ExportPageTree in export directory
+	ExportManifestFileName = "manifest.json"
+
+	exportManifestVersion = 1
+)
+
+// ExportManifest describes all pages exported by ExportPageTree
+type ExportManifest struct {
+	Version int    `json:"version"`
+	Format  string `json:"format"`
+	RootID  string `json:"root_id"`
+	// maps page id to information about exported page
+	Pages map[string]*ExportManifestPage `json:"pages"`
+}
+
+// ExportManifestPage describes a single page in ExportManifest
+type ExportManifestPage struct {
+	// path of the file, relative to export directory, with "/" separators
+	Path     string `json:"path"`
+	Title    string `json:"title"`
+	ParentID string `json:"parent_id,omitempty"`
+	Depth    int    `json:"depth"`
+	// version of the page block, changes every time the block is modified
+	Version        int64 `json:"version"`
+	LastEditedTime int64 `json:"last_edited_time"`
+	// sha1 of the content of exported file
+	ContentHash string `json:"content_hash,omitempty"`
+}
+
+func buildExportManifest(format string, pages []*ExportedPage) *ExportManifest {
+	res := &ExportManifest{
+		Version: exportManifestVersion,
+		Format:  format,
+		Pages:   map[string]*ExportManifestPage{},
+	}
+	for _, exp := range pages {
+		if exp.Depth == 0 {
+			res.RootID = exp.ID
+		}
+		mp := &ExportManifestPage{
+			Path:           exp.Path,
+			Title:          exp.Title,
+			ParentID:       exp.ParentID,
+			Depth:          exp.Depth,
+			Version:        exp.Version,
+			LastEditedTime: exp.LastEditedTime,
+			ContentHash:    exp.ContentHash,
+		}
+		res.Pages[exp.ID] = mp
+	}
+	return res
+}
+
+// ReadExportManifest reads manifest written by ExportPageTree to dir
+func ReadExportManifest(dir string) (*ExportManifest, error) {
+	d, err := ioutil.ReadFile(filepath.Join(dir, ExportManifestFileName))
+	if err != nil {
+		return nil, err
+	}
+	var res ExportManifest
+	err = json.Unmarshal(d, &res)
+	if err != nil {
+		return nil, err
+	}
+	if res.Version != exportManifestVersion {
+		return nil, fmt.Errorf("unsupported version %d of export manifest", res.Version)
+	}
+	return &res, nil
+}
+
+// children returns ids of pages exported as children of page with a given
+// id, sorted by path
+func (m *ExportManifest) children(id string) []string {
+	var res []string
+	for childID, p := range m.Pages {
+		if p.ParentID == id {
+			res = append(res, childID)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return m.Pages[res[i]].Path < m.Pages[res[j]].Path
+	})
+	return res
+}
+
+func writeExportManifest(dir string, m *ExportManifest) error {
+	d, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, ExportManifestFileName), d, 0644)
+}
+
+// exportedChildren returns a map from page id to pages exported as its
+// children, in export order
+func exportedChildren(pages []*ExportedPage) map[string][]*ExportedPage {
+	res := map[string][]*ExportedPage{}
+	for _, exp := range pages {
+		if exp.Depth > 0 {
+			res[exp.ParentID] = append(res[exp.ParentID], exp)
+		}
+	}
+	return res
+}
+
+func exportedPageTitle(exp *ExportedPage) string {
+	if exp.Title == "" {
+		return "Untitled"
+	}
+	return exp.Title
+}
+
+func exportedPageEditedOn(exp *ExportedPage) string {
+	if exp.LastEditedTime == 0 {
+		return ""
+	}
+	return time.Unix(exp.LastEditedTime/1000, 0).UTC().Format("2006-01-02 15:04")
+}
+
+// buildIndexMarkdown returns index.md with a nested list of links to all
+// exported pages
+func buildIndexMarkdown(pages []*ExportedPage) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Index\n\n")
+	children := exportedChildren(pages)
+	var visit func(exp *ExportedPage)
+	visit = func(exp *ExportedPage) {
+		indent := strings.Repeat("    ", exp.Depth)
+		fmt.Fprintf(&buf, "%s- [%s](%s)", indent, escapeMarkdown(exportedPageTitle(exp)), exp.Path)
+		if s := exportedPageEditedOn(exp); s != "" {
+			fmt.Fprintf(&buf, " (edited %s)", s)
+		}
+		buf.WriteString("\n")
+		for _, child := range children[exp.ID] {
+			visit(child)
+		}
+	}
+	for _, exp := range pages {
+		if exp.Depth == 0 {
+			visit(exp)
+		}
+	}
+	return buf.Bytes()
+}
+
+// buildIndexHTML returns index.html with a nested list of links to all
+// exported pages
+func buildIndexHTML(pages []*ExportedPage) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<!doctype html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>Index</title>\n</head>\n<body>\n<h1>Index</h1>\n")
+	children := exportedChildren(pages)
+	var visit func(exp *ExportedPage)
+	visit = func(exp *ExportedPage) {
+		fmt.Fprintf(&buf, "<li><a href=\"%s\">%s</a>", escapeHTML(exp.Path), escapeHTML(exportedPageTitle(exp)))
+		if s := exportedPageEditedOn(exp); s != "" {
+			fmt.Fprintf(&buf, " <time>%s</time>", s)
+		}
+		if kids := children[exp.ID]; len(kids) > 0 {
+			buf.WriteString("\n<ul>\n")
+			for _, child := range kids {
+				visit(child)
+			}
+			buf.WriteString("</ul>\n")
+		}
+		buf.WriteString("</li>\n")
+	}
+	buf.WriteString("<ul>\n")
+	for _, exp := range pages {
+		if exp.Depth == 0 {
+			visit(exp)
+		}
+	}
+	buf.WriteString("</ul>\n</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+// writeExportIndex writes index file and manifest for exported pages
+func writeExportIndex(dir string, format string, ext string, pages []*ExportedPage) error {
+	var d []byte
+	if ext == ".html" {
+		d = buildIndexHTML(pages)
+	} else {
+		d = buildIndexMarkdown(pages)
+	}
+	err := ioutil.WriteFile(filepath.Join(dir, "index"+ext), d, 0644)
+	if err != nil {
+		return err
+	}
+	return writeExportManifest(dir, buildExportManifest(format, pages))
+}
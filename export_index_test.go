@@ -0,0 +1,84 @@
+package notionapi
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testExportedPages() []*ExportedPage {
+	// 2019-01-01 00:00 UTC
+	const edited = 1546300800000
+	return []*ExportedPage{
+		{ID: "1", Title: "Root", Path: "root-1.md", Version: 10, LastEditedTime: edited},
+		{ID: "2", Title: "Child", Path: "root-1/child-2.md", ParentID: "1", Depth: 1, Version: 11, LastEditedTime: edited},
+		{ID: "3", Title: "Other [child]", Path: "root-1/other-child-3.md", ParentID: "1", Depth: 1, Version: 12, LastEditedTime: edited},
+		{ID: "4", Title: "", Path: "root-1/child-2/4.md", ParentID: "2", Depth: 2},
+	}
+}
+
+func TestBuildIndexMarkdown(t *testing.T) {
+	got := string(buildIndexMarkdown(testExportedPages()))
+	exp := `# Index
+
+- [Root](root-1.md) (edited 2019-01-01 00:00)
+    - [Child](root-1/child-2.md) (edited 2019-01-01 00:00)
+        - [Untitled](root-1/child-2/4.md)
+    - [Other \[child\]](root-1/other-child-3.md) (edited 2019-01-01 00:00)
+`
+	assert.Equal(t, exp, got)
+}
+
+func TestBuildIndexHTML(t *testing.T) {
+	got := string(buildIndexHTML(testExportedPages()))
+	assert.Contains(t, got, "<li><a href=\"root-1/child-2.md\">Child</a> <time>2019-01-01 00:00</time>\n<ul>\n<li><a href=\"root-1/child-2/4.md\">Untitled</a></li>\n</ul>\n</li>\n")
+}
+
+func TestExportManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notionapi-export")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	err = writeExportIndex(dir, ExportFormatMarkdown, ".md", testExportedPages())
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "index.md"))
+	assert.NoError(t, err)
+
+	m, err := ReadExportManifest(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "1", m.RootID)
+	assert.Equal(t, ExportFormatMarkdown, m.Format)
+	assert.Equal(t, 4, len(m.Pages))
+	p := m.Pages["2"]
+	assert.Equal(t, "root-1/child-2.md", p.Path)
+	assert.Equal(t, "Child", p.Title)
+	assert.Equal(t, "1", p.ParentID)
+	assert.Equal(t, int64(11), p.Version)
+	assert.Equal(t, []string{"2", "3"}, m.children("1"))
+	assert.Equal(t, []string{"4"}, m.children("2"))
+}
+
+func TestRemoveUnexportedPages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "notionapi-export")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	pages := testExportedPages()
+	for _, exp := range pages {
+		path := filepath.Join(dir, filepath.FromSlash(exp.Path))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, []byte("x"), 0644))
+	}
+	prev := buildExportManifest(ExportFormatMarkdown, pages)
+	exported := map[string]*ExportedPage{"1": pages[0], "2": pages[1]}
+	removed, err := removeUnexportedPages(dir, prev, exported, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3", "4"}, removed)
+	_, err = os.Stat(filepath.Join(dir, "root-1", "other-child-3.md"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "root-1", "child-2.md"))
+	assert.NoError(t, err)
+}
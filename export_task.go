@@ -0,0 +1,208 @@
+package notionapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// delays between checking the status of export task
+	exportPollMinDelay = 500 * time.Millisecond
+	exportPollMaxDelay = 5 * time.Second
+
+	taskStateSuccess = "success"
+	taskStateFailure = "failure"
+)
+
+// ExportOptions allows customizing Client.RequestExport
+type ExportOptions struct {
+	// ExportFormatMarkdown (default) or ExportFormatHTML
+	ExportType string
+	// if true, sub-pages are exported too
+	Recursive bool
+	// if true, images and files uploaded to Notion are included in the zip
+	IncludeFiles bool
+	// used to format dates. Default is "UTC"
+	TimeZone string
+	// Default is "en"
+	Locale string
+}
+
+type exportOptions struct {
+	ExportType      string `json:"exportType"`
+	TimeZone        string `json:"timeZone"`
+	Locale          string `json:"locale"`
+	IncludeContents string `json:"includeContents,omitempty"`
+}
+
+type exportBlockRequest struct {
+	BlockID       string        `json:"blockId"`
+	Recursive     bool          `json:"recursive"`
+	ExportOptions exportOptions `json:"exportOptions"`
+}
+
+type exportTask struct {
+	EventName string              `json:"eventName"`
+	Request   *exportBlockRequest `json:"request"`
+}
+
+// /api/v3/enqueueTask request
+type enqueueTaskRequest struct {
+	Task *exportTask `json:"task"`
+}
+
+// /api/v3/enqueueTask response
+type enqueueTaskResponse struct {
+	TaskID string `json:"taskId"`
+}
+
+// /api/v3/getTasks request
+type getTasksRequest struct {
+	TaskIDs []string `json:"taskIds"`
+}
+
+type taskStatus struct {
+	// "progress" or "complete"
+	Type          string `json:"type"`
+	PagesExported int    `json:"pagesExported"`
+	ExportURL     string `json:"exportURL"`
+}
+
+type taskResult struct {
+	ID string `json:"id"`
+	// "not_started", "in_progress", "success" or "failure"
+	State  string      `json:"state"`
+	Status *taskStatus `json:"status"`
+	Error  string      `json:"error"`
+}
+
+// /api/v3/getTasks response
+type getTasksResponse struct {
+	Results []*taskResult `json:"results"`
+}
+
+// RequestExport asks the server to export a page as a zip file of markdown
+// or html files and returns the id of the export task. Use WaitForExport to
+// get the url of the zip file
+func (c *Client) RequestExport(blockID string, opts *ExportOptions) (string, error) {
+	return c.RequestExportCtx(context.Background(), blockID, opts)
+}
+
+// RequestExportCtx is like RequestExport but can be canceled with ctx
+func (c *Client) RequestExportCtx(ctx context.Context, blockID string, opts *ExportOptions) (string, error) {
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	blockID = mustNormalizeID(blockID)
+	exportType := opts.ExportType
+	switch exportType {
+	case "":
+		exportType = ExportFormatMarkdown
+	case ExportFormatMarkdown, ExportFormatHTML:
+	default:
+		return "", fmt.Errorf("'%s' is not a valid export type", exportType)
+	}
+	eo := exportOptions{
+		ExportType: exportType,
+		TimeZone:   opts.TimeZone,
+		Locale:     opts.Locale,
+	}
+	if eo.TimeZone == "" {
+		eo.TimeZone = "UTC"
+	}
+	if eo.Locale == "" {
+		eo.Locale = "en"
+	}
+	if !opts.IncludeFiles {
+		eo.IncludeContents = "no_files"
+	}
+	req := &enqueueTaskRequest{
+		Task: &exportTask{
+			EventName: "exportBlock",
+			Request: &exportBlockRequest{
+				BlockID:       blockID,
+				Recursive:     opts.Recursive,
+				ExportOptions: eo,
+			},
+		},
+	}
+	apiURL := "/api/v3/enqueueTask"
+	var rsp enqueueTaskResponse
+	err := doNotionAPI(ctx, c, apiURL, req, &rsp)
+	if err != nil {
+		return "", err
+	}
+	if rsp.TaskID == "" {
+		return "", fmt.Errorf("server didn't return id of export task for block %s", blockID)
+	}
+	return rsp.TaskID, nil
+}
+
+// WaitForExport waits until export task started with RequestExport
+// finishes and returns url of the zip file. The url can be downloaded
+// with DownloadFile
+func (c *Client) WaitForExport(taskID string) (string, error) {
+	return c.WaitForExportCtx(context.Background(), taskID)
+}
+
+// WaitForExportCtx is like WaitForExport but can be canceled with ctx
+func (c *Client) WaitForExportCtx(ctx context.Context, taskID string) (string, error) {
+	delay := exportPollMinDelay
+	for {
+		req := &getTasksRequest{
+			TaskIDs: []string{taskID},
+		}
+		apiURL := "/api/v3/getTasks"
+		var rsp getTasksResponse
+		err := doNotionAPI(ctx, c, apiURL, req, &rsp)
+		if err != nil {
+			return "", err
+		}
+		if len(rsp.Results) == 0 {
+			return "", fmt.Errorf("export task %s: %w", taskID, ErrNotFound)
+		}
+		task := rsp.Results[0]
+		switch task.State {
+		case taskStateSuccess:
+			if task.Status == nil || task.Status.ExportURL == "" {
+				return "", fmt.Errorf("export task %s finished without url of the export", taskID)
+			}
+			return task.Status.ExportURL, nil
+		case taskStateFailure:
+			return "", fmt.Errorf("export task %s failed: %s", taskID, task.Error)
+		}
+		if task.Status != nil {
+			dbg(c, "export task %s: %d pages exported\n", taskID, task.Status.PagesExported)
+		}
+		err = sleepCtx(ctx, delay)
+		if err != nil {
+			return "", err
+		}
+		delay = delay * 3 / 2
+		if delay > exportPollMaxDelay {
+			delay = exportPollMaxDelay
+		}
+	}
+}
+
+// DownloadExport exports the page with RequestExport, waits for the export
+// to finish and writes the zip file to w
+func (c *Client) DownloadExport(w io.Writer, blockID string, opts *ExportOptions) error {
+	return c.DownloadExportCtx(context.Background(), w, blockID, opts)
+}
+
+// DownloadExportCtx is like DownloadExport but can be canceled with ctx
+func (c *Client) DownloadExportCtx(ctx context.Context, w io.Writer, blockID string, opts *ExportOptions) error {
+	taskID, err := c.RequestExportCtx(ctx, blockID, opts)
+	if err != nil {
+		return err
+	}
+	uri, err := c.WaitForExportCtx(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	_, err = c.DownloadFileToCtx(ctx, w, uri, nil)
+	return err
+}
@@ -0,0 +1,100 @@
+package notionapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const exportZipURL = "https://s3.us-west-2.amazonaws.com/temporary.notion-static.com/export.zip"
+
+// exportTransport serves enqueueTask, getTasks that is in progress for the
+// first time and the exported file
+type exportTransport struct {
+	enqueueReq map[string]interface{}
+	getTasks   int
+	failed     bool
+}
+
+func (t *exportTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	s := ""
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/enqueueTask"):
+		d, _ := ioutil.ReadAll(r.Body)
+		json.Unmarshal(d, &t.enqueueReq)
+		s = `{"taskId":"task-1"}`
+	case strings.HasSuffix(r.URL.Path, "/getTasks"):
+		t.getTasks++
+		if t.getTasks == 1 {
+			s = `{"results":[{"id":"task-1","state":"in_progress","status":{"type":"progress","pagesExported":1}}]}`
+		} else if t.failed {
+			s = `{"results":[{"id":"task-1","state":"failure","error":"Export failed"}]}`
+		} else {
+			s = `{"results":[{"id":"task-1","state":"success","status":{"type":"complete","pagesExported":2,"exportURL":"` + exportZipURL + `"}}]}`
+		}
+	case r.URL.String() == exportZipURL:
+		s = "zip content"
+	default:
+		return &http.Response{
+			StatusCode: 404,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader("")),
+			Request:    r,
+		}, nil
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader(s)),
+		Request:    r,
+	}, nil
+}
+
+func TestDownloadExport(t *testing.T) {
+	transport := &exportTransport{}
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+	var buf bytes.Buffer
+	opts := &ExportOptions{
+		ExportType: ExportFormatHTML,
+		Recursive:  true,
+	}
+	err := c.DownloadExport(&buf, "4c6a54c68b3e4ea2af9cfaabcc88d58d", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "zip content", buf.String())
+	assert.Equal(t, 2, transport.getTasks)
+
+	task := transport.enqueueReq["task"].(map[string]interface{})
+	assert.Equal(t, "exportBlock", task["eventName"])
+	req := task["request"].(map[string]interface{})
+	assert.Equal(t, "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", req["blockId"])
+	assert.Equal(t, true, req["recursive"])
+	exportOpts := req["exportOptions"].(map[string]interface{})
+	assert.Equal(t, ExportFormatHTML, exportOpts["exportType"])
+	assert.Equal(t, "UTC", exportOpts["timeZone"])
+	assert.Equal(t, "no_files", exportOpts["includeContents"])
+}
+
+func TestWaitForExportFailed(t *testing.T) {
+	transport := &exportTransport{failed: true}
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+	taskID, err := c.RequestExport("4c6a54c68b3e4ea2af9cfaabcc88d58d", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "task-1", taskID)
+	_, err = c.WaitForExport(taskID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Export failed")
+
+	_, err = c.RequestExport("4c6a54c68b3e4ea2af9cfaabcc88d58d", &ExportOptions{ExportType: "pdf"})
+	assert.Error(t, err)
+}
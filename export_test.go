@@ -0,0 +1,90 @@
+package notionapi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	var tests = []struct {
+		s   string
+		exp string
+	}{
+		{"Hello, World!", "hello-world"},
+		{"  Test   page  ", "test-page"},
+		{"Zażółć gęślą", "zażółć-gęślą"},
+		{"!!!", ""},
+		{"a very long title that is going to be cut because it is longer than the limit", "a-very-long-title-that-is-going-to-be-cut-because-it-is-longer-t"},
+	}
+	for _, test := range tests {
+		got := Slugify(test.s)
+		assert.Equal(t, test.exp, got, "s: %s", test.s)
+	}
+}
+
+func TestRelativeURL(t *testing.T) {
+	assert.Equal(t, "root/child.md", relativeURL("root.md", "root/child.md"))
+	assert.Equal(t, "../root.md", relativeURL("root/child.md", "root.md"))
+	assert.Equal(t, "../other/x.md", relativeURL("root/child.md", "other/x.md"))
+}
+
+func TestAssetFileName(t *testing.T) {
+	d := []byte("hello")
+	// sha1 of "hello"
+	sha := "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d"
+	assert.Equal(t, sha+".png", assetFileName(d, "https://example.com/foo/Image.PNG?x=1", ""))
+	assert.Equal(t, sha+".pdf", assetFileName(d, "https://example.com/download", "application/pdf"))
+	assert.Equal(t, sha, assetFileName(d, "https://example.com/download", ""))
+}
+
+func TestAssetDownloadURL(t *testing.T) {
+	file := &Block{
+		ID:     "e802296a-b0dc-41a8-8aa3-cf4212c3da0b",
+		Type:   BlockFile,
+		Source: "https://s3-us-west-2.amazonaws.com/secure.notion-static.com/8b3930e3/doc.pdf",
+	}
+	exp := "https://www.notion.so/signed/https:%2F%2Fs3-us-west-2.amazonaws.com%2Fsecure.notion-static.com%2F8b3930e3%2Fdoc.pdf?table=block&id=e802296a-b0dc-41a8-8aa3-cf4212c3da0b"
+	assert.Equal(t, exp, assetDownloadURL(file))
+	assert.Equal(t, "", assetDownloadURL(&Block{Type: BlockText}))
+}
+
+func TestExportPageTreeAlias(t *testing.T) {
+	const (
+		root  = "00000000-0000-0000-0000-000000000001"
+		a     = "00000000-0000-0000-0000-00000000000a"
+		b     = "00000000-0000-0000-0000-00000000000b"
+		alias = "00000000-0000-0000-0000-0000000000a1"
+	)
+	dir, err := ioutil.TempDir("", "notionapi-export")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	transport := &pageTreeTransport{
+		children: map[string][]string{
+			root: {a, alias},
+			a:    {},
+			b:    {},
+		},
+		// b is not a child of any exported page, only linked from root
+		aliases:    map[string]string{alias: b},
+		downloaded: map[string]int{},
+	}
+	client := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+	res, err := ExportPageTree(client, root, dir, ExportFormatMarkdown, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(res.Pages))
+	rootPage, linked := res.Pages[0], res.Pages[2]
+	assert.Equal(t, b, linked.ID)
+	d, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(rootPage.Path)))
+	assert.NoError(t, err)
+	assert.Contains(t, string(d), "]("+relativeURL(rootPage.Path, linked.Path)+")")
+	assert.NotContains(t, string(d), "notion.so")
+}
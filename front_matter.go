@@ -0,0 +1,143 @@
+package notionapi
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// yamlString returns s as a double-quoted yaml string. Quoting every
+// string is the simplest way to handle titles with ':', '#', quotes etc.
+func yamlString(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(&buf, `\x%02x`, r)
+				continue
+			}
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+func yamlList(a []string) string {
+	var quoted []string
+	for _, s := range a {
+		quoted = append(quoted, yamlString(s))
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func notionTimeToString(t int64) string {
+	return time.Unix(t/1000, 0).UTC().Format(time.RFC3339)
+}
+
+// yamlColumnValue returns value of a column of a row in yaml or "" if
+// the row doesn't have a value
+func yamlColumnValue(row *Row, col *CollectionColumnInfo) string {
+	switch col.Type {
+	case ColumnTypeCheckbox:
+		return strconv.FormatBool(row.Checkbox(col.ID))
+	case ColumnTypeNumber:
+		if n, ok := row.Number(col.ID); ok {
+			return strconv.FormatFloat(n, 'f', -1, 64)
+		}
+		return ""
+	case ColumnMultiSelect:
+		if values := row.MultiSelect(col.ID); len(values) > 0 {
+			return yamlList(values)
+		}
+		return ""
+	case ColumnTypePerson:
+		if ids := row.Persons(col.ID); len(ids) > 0 {
+			return yamlList(ids)
+		}
+		return ""
+	case ColumnTypeDate:
+		if d := row.Date(col.ID); d != nil {
+			return yamlString(dateToString(d))
+		}
+		return ""
+	}
+	if s := row.Text(col.ID); s != "" {
+		return yamlString(s)
+	}
+	return ""
+}
+
+// rowForPage returns a row of the table for root block of the page or nil
+// if the page is not in the table's collection
+func rowForPage(table *Table, page *Page) *Row {
+	if table == nil || table.Collection == nil {
+		return nil
+	}
+	root := page.Root
+	if root.ParentTable != TableCollection || root.ParentID != table.Collection.ID {
+		return nil
+	}
+	return &Row{Table: table, Block: root}
+}
+
+// FrontMatter returns yaml front matter (delimited by "---" lines) with
+// title, id, icon, cover, timestamps and, if they're in page.Users,
+// authors of the page. If the page is a row
+// in a collection of table, values of all columns are included in
+// "properties"
+func FrontMatter(page *Page, table *Table) []byte {
+	var buf bytes.Buffer
+	root := page.Root
+	buf.WriteString("---\n")
+	fmt.Fprintf(&buf, "title: %s\n", yamlString(root.Title))
+	fmt.Fprintf(&buf, "id: %s\n", yamlString(root.ID))
+	if f := root.FormatPage; f != nil {
+		if f.PageIcon != "" {
+			fmt.Fprintf(&buf, "icon: %s\n", yamlString(f.PageIcon))
+		}
+		if f.PageCoverURL != "" {
+			fmt.Fprintf(&buf, "cover: %s\n", yamlString(f.PageCoverURL))
+		}
+	}
+	if root.CreatedTime != 0 {
+		fmt.Fprintf(&buf, "created: %s\n", yamlString(notionTimeToString(root.CreatedTime)))
+	}
+	if root.LastEditedTime != 0 {
+		fmt.Fprintf(&buf, "last_edited: %s\n", yamlString(notionTimeToString(root.LastEditedTime)))
+	}
+	if u := page.findUser(root.creatorID()); u != nil {
+		fmt.Fprintf(&buf, "created_by: %s\n", yamlString(u.FullName()))
+	}
+	if u := page.findUser(root.lastEditorID()); u != nil {
+		fmt.Fprintf(&buf, "last_edited_by: %s\n", yamlString(u.FullName()))
+	}
+	if row := rowForPage(table, page); row != nil {
+		var lines []string
+		for _, col := range allColumns(table.Collection.CollectionSchema) {
+			if v := yamlColumnValue(row, col); v != "" {
+				lines = append(lines, fmt.Sprintf("  %s: %s\n", yamlString(col.Name), v))
+			}
+		}
+		if len(lines) > 0 {
+			buf.WriteString("properties:\n")
+			buf.WriteString(strings.Join(lines, ""))
+		}
+	}
+	buf.WriteString("---\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,48 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestYAMLString(t *testing.T) {
+	assert.Equal(t, `"plain"`, yamlString("plain"))
+	assert.Equal(t, `"Title: \"quoted\" # not a comment"`, yamlString(`Title: "quoted" # not a comment`))
+	assert.Equal(t, `"back\\slash\nnew line"`, yamlString("back\\slash\nnew line"))
+}
+
+func TestFrontMatter(t *testing.T) {
+	table := parseTestTable(t)
+	root := testRow("2", "Done")
+	root.Title = `Row: "2"`
+	root.ParentID = table.Collection.ID
+	root.ParentTable = TableCollection
+	root.CreatedTime = 1546300800000
+	root.LastEditedTime = 1546304400000
+	root.FormatPage = &FormatPage{PageIcon: "🚀"}
+	root.CreatedByID = "user"
+	page := &Page{ID: root.ID, Root: root, Users: []*User{{ID: "user", GivenName: "Jane", FamilyName: "Doe"}}}
+
+	got := string(FrontMatter(page, table))
+	exp := `---
+title: "Row: \"2\""
+id: "2"
+icon: "🚀"
+created: "2019-01-01T00:00:00Z"
+last_edited: "2019-01-01T01:00:00Z"
+created_by: "Jane Doe"
+properties:
+  "Name": "Row 2"
+  "Status": "Done"
+---
+`
+	assert.Equal(t, exp, got)
+
+	// pages that are not rows of the table don't have properties
+	root.ParentTable = TableBlock
+	assert.NotContains(t, string(FrontMatter(page, table)), "properties")
+
+	md := string(ToMarkdown(page, &MarkdownOptions{FrontMatter: true}))
+	assert.Contains(t, md, "---\n\n# Row: \"2\"\n")
+}
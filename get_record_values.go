@@ -1,7 +1,13 @@
 package notionapi
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -30,16 +36,25 @@ type BlockWithRole struct {
 // Block describes a block
 type Block struct {
 	// values that come from JSON
-	// if false, the page is deleted
+	// if false, the block is deleted (e.g. in trash)
 	Alive bool `json:"alive"`
 	// List of block ids for that make up content of this block
 	// Use Content to get corresponding block (they are in the same order)
-	ContentIDs   []string `json:"content,omitempty"`
-	CopiedFrom   string   `json:"copied_from,omitempty"`
-	CollectionID string   `json:"collection_id,omitempty"` // for BlockCollectionView
+	ContentIDs []string `json:"content,omitempty"`
+	// id of the block this block was duplicated from, e.g. a template
+	// page of a collection or a template of BlockFactory. Use CopiedFromID
+	CopiedFrom string `json:"copied_from,omitempty"`
+	// newer records point to the original block with CopiedFromPointer
+	CopiedFromPointer *BlockPointer `json:"copied_from_pointer,omitempty"`
+	CollectionID      string        `json:"collection_id,omitempty"` // for BlockCollectionView
+	// ID of the user who created this block. Only in older records, use
+	// CreatedByUser or CreatedByID
+	CreatedBy string `json:"created_by"`
 	// ID of the user who created this block
-	CreatedBy   string `json:"created_by"`
-	CreatedTime int64  `json:"created_time"`
+	CreatedByID string `json:"created_by_id,omitempty"`
+	// TableUser or TableBot, depending on who created this block
+	CreatedByTable string `json:"created_by_table,omitempty"`
+	CreatedTime    int64  `json:"created_time"`
 	// List of block ids with discussion content
 	DiscussionIDs []string `json:"discussion,omitempty"`
 	// those ids seem to map to storage in s3
@@ -52,14 +67,23 @@ type Block struct {
 	// TODO: don't know what this means
 	IgnoreBlockCount bool `json:"ignore_block_count,omitempty"`
 
+	// true if this is a template for rows of a collection
+	IsTemplate bool `json:"is_template,omitempty"`
+
+	// ID of the user who last edited this block. Only in older records,
+	// use LastEditedByUser or LastEditedByID
+	LastEditedBy string `json:"last_edited_by"`
 	// ID of the user who last edited this block
-	LastEditedBy   string `json:"last_edited_by"`
-	LastEditedTime int64  `json:"last_edited_time"`
-	// ID of parent Block
+	LastEditedByID string `json:"last_edited_by_id,omitempty"`
+	// TableUser or TableBot, depending on who last edited this block
+	LastEditedByTable string `json:"last_edited_by_table,omitempty"`
+	LastEditedTime    int64  `json:"last_edited_time"`
+	// ID of parent record, a Block, Collection or Space depending
+	// on ParentTable
 	ParentID    string `json:"parent_id"`
 	ParentTable string `json:"parent_table"`
 	// not always available
-	Permissions *[]Permission          `json:"permissions,omitempty"`
+	Permissions []Permission           `json:"permissions,omitempty"`
 	Properties  map[string]interface{} `json:"properties,omitempty"`
 	// type of the block e.g. TypeText, TypePage etc.
 	Type string `json:"type"`
@@ -69,8 +93,18 @@ type Block struct {
 
 	// Values calculated by us
 
+	// block with ParentID if ParentTable is TableBlock. If we didn't
+	// download the parent, it's a stub with only ID and IsStub set.
+	// nil for the root of the page and rows of collections
+	Parent *Block `json:"-"`
+	// true for Parent of a block if we don't have the parent record
+	IsStub bool `json:"-"`
 	// maps ContentIDs array
 	Content []*Block `json:"content_resolved,omitempty"`
+	// for BlockFactory, maps ContentIDs array. Those are templates of
+	// blocks created by the button, not content of the page, so Content
+	// is empty
+	TemplateContent []*Block `json:"template_content_resolved,omitempty"`
 	// this is for some types like TypePage, TypeText, TypeHeader etc.
 	InlineContent []*InlineBlock `json:"inline_content,omitempty"`
 
@@ -79,6 +113,40 @@ type Block struct {
 
 	// For BlockTodo, a checked state
 	IsChecked bool `json:"is_checked,omitempty"`
+	// For BlockTodo, true if the block has "checked" property. To-dos
+	// that were never checked don't have it, unchecked ones have "No"
+	HasCheckedProperty bool `json:"has_checked_property,omitempty"`
+
+	// for BlockNumberedList, number of the item in its list, starting
+	// with 1 (or FormatList.ListStartIndex). The list is restarted by
+	// any other block
+	ListIndex int `json:"list_index,omitempty"`
+	// for BlockBulletedList and BlockNumberedList, 0 for items of a top
+	// level list, 1 for items nested in another list item etc.
+	ListNesting int `json:"list_nesting,omitempty"`
+
+	// for blocks created by clicking BlockFactory, id of the factory
+	// whose template they were copied from. Only set if the factory
+	// is in the same page
+	FactoryID string `json:"factory_id,omitempty"`
+
+	// number of ancestors of the block in the page, 0 for the root.
+	// Blocks in a column are 2 levels deeper than the column list
+	Depth int `json:"depth,omitempty"`
+	// index of the block in Content of its parent
+	SiblingIndex int `json:"sibling_index,omitempty"`
+
+	// for BlockColumn, part of the width of BlockColumnList taken by the
+	// column: FormatColumn.ColumnRation or, for columns that were never
+	// resized, 1/n where n is the number of columns in the list
+	EffectiveRatio float64 `json:"effective_ratio,omitempty"`
+
+	// ids of children that are not in Content because we didn't get their
+	// records, e.g. because they weren't in the downloaded chunks. They're
+	// removed from ContentIDs. See LoadChildren
+	MissingContentIDs []string `json:"-"`
+	// ContentIDs sent by the server, including MissingContentIDs
+	allContentIDs []string
 
 	// for BlockBookmark
 	Description string `json:"description,omitempty"`
@@ -89,10 +157,35 @@ type Block struct {
 	// fot BlockImage it's url of the image, but use ImageURL instead
 	// because Source is sometimes not accessible
 	// for BlockFile it's url of the file
+	// for BlockAudio it's url of the audio file
+	// for BlockPDF it's url of the pdf file
 	// for BlockEmbed it's url of the embed
+	// for BlockFigma it's url of the design in Figma
+	// for BlockTweet it's url of the tweet
+	// for BlockMaps it's url of the map
+	// for BlockCodepen it's url of the pen
 	Source string `json:"source,omitempty"`
 
-	// for BlockFile
+	// for BlockTweet, parsed from Source
+	TweetID string `json:"tweet_id,omitempty"`
+
+	// for BlockAlias and for BlockPage that is a link to a page in another
+	// place, id of the page it links to
+	AliasPageID string `json:"alias_page_id,omitempty"`
+	// for BlockAlias, title and icon of the page it links to. Set by
+	// DownloadPage if it downloaded the page, see Client.ResolveAlias
+	AliasTitle string `json:"alias_title,omitempty"`
+	AliasIcon  string `json:"alias_icon,omitempty"`
+
+	// for blocks of unknown type (see IsUnknownType), JSON of the record
+	// as sent by the server
+	RawJSON json.RawMessage `json:"-"`
+
+	// for BlockMaps, coordinates parsed from Source if it has them
+	Lat *float64 `json:"lat,omitempty"`
+	Lng *float64 `json:"lng,omitempty"`
+
+	// for BlockFile, BlockAudio and BlockPDF
 	FileSize string `json:"file_size,omitempty"`
 
 	// for BlockImage it's an URL built from Source that is always accessible
@@ -102,6 +195,9 @@ type Block struct {
 	Code         string `json:"code,omitempty"`
 	CodeLanguage string `json:"code_language,omitempty"`
 
+	// for BlockEquation it's LaTeX of the equation
+	Equation string `json:"equation,omitempty"`
+
 	// for BlockCollectionView
 	// It looks like the info about which view is selected is stored in browser
 	CollectionViews []*CollectionViewInfo `json:"collection_views,omitempty"`
@@ -111,9 +207,20 @@ type Block struct {
 	FormatImage    *FormatImage    `json:"format_image,omitempty"`
 	FormatColumn   *FormatColumn   `json:"format_column,omitempty"`
 	FormatText     *FormatText     `json:"format_text,omitempty"`
+	FormatHeader   *FormatHeader   `json:"format_header,omitempty"`
 	FormatTable    *FormatTable    `json:"format_table,omitempty"`
 	FormatVideo    *FormatVideo    `json:"format_video,omitempty"`
+	FormatAudio    *FormatAudio    `json:"format_audio,omitempty"`
+	FormatPDF      *FormatPDF      `json:"format_pdf,omitempty"`
+	FormatDrive    *FormatDrive    `json:"format_drive,omitempty"`
+	FormatAlias    *FormatAlias    `json:"format_alias,omitempty"`
 	FormatEmbed    *FormatEmbed    `json:"format_embed,omitempty"`
+	FormatCallout  *FormatCallout  `json:"format_callout,omitempty"`
+	FormatList     *FormatList     `json:"format_list,omitempty"`
+
+	FormatTableOfContents       *FormatTableOfContents       `json:"format_table_of_contents,omitempty"`
+	FormatTransclusionReference *FormatTransclusionReference `json:"format_transclusion_reference,omitempty"`
+	FormatTableBlock            *FormatTableBlock            `json:"format_table_block,omitempty"`
 }
 
 // CollectionViewInfo describes a particular view of the collection
@@ -123,14 +230,25 @@ type CollectionViewInfo struct {
 	CollectionRows []*Block
 }
 
-// CreatedOn return the time the page was created
+// notionTime converts time in milliseconds since epoch, as sent by Notion,
+// to time.Time. 0 means the time is not known and returns zero time
+func notionTime(ms int64) time.Time {
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+}
+
+// CreatedOn return the time the block was created (CreatedTime), zero time
+// if not known
 func (b *Block) CreatedOn() time.Time {
-	return time.Unix(b.CreatedTime/1000, 0)
+	return notionTime(b.CreatedTime)
 }
 
-// UpdatedOn returns the time the page was last updated
+// UpdatedOn returns the time the block was last updated (LastEditedTime),
+// zero time if not known
 func (b *Block) UpdatedOn() time.Time {
-	return time.Unix(b.LastEditedTime/1000, 0)
+	return notionTime(b.LastEditedTime)
 }
 
 // IsLinkToPage returns true if block element is a link to existing page
@@ -148,6 +266,200 @@ func (b *Block) IsPage() bool {
 	return b.Type == BlockPage
 }
 
+// IsToggleable returns true if block can be collapsed to hide its content.
+// It's true for BlockToggle and for toggleable headers
+func (b *Block) IsToggleable() bool {
+	switch b.Type {
+	case BlockToggle:
+		return true
+	case BlockHeader, BlockSubHeader, BlockSubSubHeader:
+		return b.FormatHeader != nil && b.FormatHeader.Toggleable
+	}
+	return false
+}
+
+// BookmarkURL returns url of the page bookmarked in BlockBookmark
+func (b *Block) BookmarkURL() string {
+	if b.Link != "" {
+		return b.Link
+	}
+	return b.Source
+}
+
+// hasBookmarkMetadata returns false if Notion failed to fetch title,
+// description etc. of the bookmarked page
+func (b *Block) hasBookmarkMetadata() bool {
+	return len(b.InlineContent) > 0 || b.Description != "" || b.FormatBookmark != nil
+}
+
+// fileName returns name of the file in BlockFile, BlockPDF etc. Notion
+// stores it as a title, if not we use the last part of Source
+func (b *Block) fileName() string {
+	if s := InlineToText(b.InlineContent); s != "" {
+		return s
+	}
+	if u, err := url.Parse(b.Source); err == nil {
+		if name := path.Base(u.Path); name != "/" && name != "." {
+			return name
+		}
+	}
+	return b.Source
+}
+
+// DecodeFormat decodes format of the block (FormatRaw) into v, which
+// should be a pointer to a struct like FormatPage. It's useful for block
+// types that don't have their own Format struct. Does nothing if the
+// block doesn't have format
+func (b *Block) DecodeFormat(v interface{}) error {
+	if len(b.FormatRaw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b.FormatRaw, v)
+}
+
+// FormatValue returns value of a single key in format of the block,
+// decoded like json.Unmarshal does into interface{}
+func (b *Block) FormatValue(key string) (interface{}, bool) {
+	var m map[string]interface{}
+	if b.DecodeFormat(&m) != nil {
+		return nil, false
+	}
+	v, ok := m[key]
+	return v, ok
+}
+
+// IsUnknownType returns true if block is of a type that this library
+// doesn't know about, probably added by Notion after it was written.
+// JSON of such blocks is in RawJSON
+func (b *Block) IsUnknownType() bool {
+	return b.Type != "" && !knownBlockTypes[b.Type] && !embedBlockTypes[b.Type]
+}
+
+// UnmarshalJSON decodes block and, if it's of unknown type, remembers
+// its JSON in RawJSON
+func (b *Block) UnmarshalJSON(d []byte) error {
+	// blockNoMethods doesn't have UnmarshalJSON, which avoids infinite recursion
+	type blockNoMethods Block
+	err := json.Unmarshal(d, (*blockNoMethods)(b))
+	if err == nil && b.IsUnknownType() {
+		b.RawJSON = append(json.RawMessage(nil), d...)
+	}
+	return err
+}
+
+// IsEmbedBlock returns true if block embeds content from another service
+// (BlockEmbed, BlockFigma, BlockTweet etc.). Such blocks have FormatEmbed
+func (b *Block) IsEmbedBlock() bool {
+	switch b.Type {
+	case BlockEmbed, BlockFigma, BlockTweet, BlockGist, BlockMaps, BlockCodepen:
+		return true
+	}
+	return embedBlockTypes[b.Type]
+}
+
+// embedURL returns url that can be shown in an iframe for an embed block
+func (b *Block) embedURL() string {
+	if b.FormatEmbed != nil && b.FormatEmbed.DisplaySource != "" {
+		return b.FormatEmbed.DisplaySource
+	}
+	return b.Source
+}
+
+// CopiedFromID returns id of the block this block was duplicated from,
+// "" if it wasn't
+func (b *Block) CopiedFromID() string {
+	if b.CopiedFrom != "" {
+		return b.CopiedFrom
+	}
+	if b.CopiedFromPointer != nil {
+		return b.CopiedFromPointer.ID
+	}
+	return ""
+}
+
+// BlockColor returns color of text or background of a block, one of Color*
+// constants, or "" if the block doesn't have a color
+func (b *Block) BlockColor() string {
+	var color *string
+	switch {
+	case b.FormatText != nil:
+		color = b.FormatText.BlockColor
+	case b.FormatHeader != nil:
+		color = b.FormatHeader.BlockColor
+	case b.FormatList != nil:
+		color = b.FormatList.BlockColor
+	case b.FormatCallout != nil:
+		color = b.FormatCallout.BlockColor
+	case b.FormatTableOfContents != nil:
+		color = b.FormatTableOfContents.BlockColor
+	case b.FormatPage != nil:
+		color = b.FormatPage.BlockColor
+	}
+	if color == nil {
+		return ""
+	}
+	return *color
+}
+
+// GistURL returns url of the gist in BlockGist
+func (b *Block) GistURL() string {
+	if b.Source != "" {
+		return b.Source
+	}
+	if b.FormatEmbed != nil {
+		return b.FormatEmbed.DisplaySource
+	}
+	return ""
+}
+
+// driveProperties returns properties of a file in BlockDrive, nil if
+// Notion didn't fetch them
+func (b *Block) driveProperties() *DriveProperties {
+	if b.FormatDrive == nil {
+		return nil
+	}
+	return b.FormatDrive.DriveProperties
+}
+
+// driveTitleAndURL returns title and url of the file in BlockDrive
+func (b *Block) driveTitleAndURL() (string, string) {
+	title, uri := "", b.Source
+	if p := b.driveProperties(); p != nil {
+		title = p.Title
+		if p.URL != "" {
+			uri = p.URL
+		}
+	}
+	if title == "" {
+		title = uri
+	}
+	return title, uri
+}
+
+// TableCells returns cells of a simple BlockTable as rows of columns.
+// Each cell is a formatted text
+func (b *Block) TableCells() [][][]*InlineBlock {
+	if b.Type != BlockTable {
+		return nil
+	}
+	var cols []string
+	if b.FormatTableBlock != nil {
+		cols = b.FormatTableBlock.TableBlockColumnOrder
+	}
+	var res [][][]*InlineBlock
+	for _, row := range b.Content {
+		if row == nil || row.Type != BlockTableRow {
+			continue
+		}
+		cells := make([][]*InlineBlock, len(cols))
+		for i, col := range cols {
+			cells[i] = getInlineProp(row, col)
+		}
+		res = append(res, cells)
+	}
+	return res
+}
+
 // IsImage returns true if block represents an image
 func (b *Block) IsImage() bool {
 	return b.Type == BlockImage
@@ -170,25 +482,76 @@ type FormatPage struct {
 	// or emoji like "✉️"
 	PageIcon      string `json:"page_icon"`
 	PageSmallText bool   `json:"page_small_text"`
+	// e.g. "gray" or "blue_background"
+	BlockColor *string `json:"block_color,omitempty"`
+	// if true, the page can't be edited until it's unlocked
+	BlockLocked bool `json:"block_locked,omitempty"`
+	// id of the user who locked the page
+	BlockLockedBy         string                 `json:"block_locked_by,omitempty"`
+	PageSectionVisibility *PageSectionVisibility `json:"page_section_visibility,omitempty"`
 
 	// calculated by us
 	PageCoverURL string `json:"page_cover_url,omitempty"`
+	// PageIcon proxied via notion server if it's an url, empty for emoji
+	PageIconURL string `json:"page_icon_url,omitempty"`
+}
+
+// PageSectionVisibility describes how sections of a page are shown:
+// "section", "minimized" or "off"
+type PageSectionVisibility struct {
+	Backlinks string `json:"backlinks,omitempty"`
+	Comments  string `json:"comments,omitempty"`
 }
 
 // FormatBookmark describes format for BlockBookmark
 type FormatBookmark struct {
+	// url of favicon of the bookmarked page
 	BookmarkIcon string `json:"bookmark_icon"`
+	// url of preview image of the bookmarked page
+	BookmarkCover string `json:"bookmark_cover,omitempty"`
+
+	// calculated by us: BookmarkCover proxied via notion server
+	BookmarkCoverURL string `json:"bookmark_cover_url,omitempty"`
+}
+
+// Number is a float64 that can be decoded from a JSON number or from
+// a string with a number. Notion sends sizes of blocks as either,
+// depending on the client that edited the block. null and "" are 0
+type Number float64
+
+// UnmarshalJSON decodes Number from a number, a string or null
+func (n *Number) UnmarshalJSON(d []byte) error {
+	s := string(d)
+	if s == "null" {
+		return nil
+	}
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(d, &s); err != nil {
+			return err
+		}
+		s = strings.TrimSpace(s)
+		if s == "" {
+			*n = 0
+			return nil
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("%s is not a number", string(d))
+	}
+	*n = Number(f)
+	return nil
 }
 
 // FormatImage describes format for BlockImage
 type FormatImage struct {
 	// comes from notion API
-	BlockAspectRatio   float64 `json:"block_aspect_ratio"`
-	BlockFullWidth     bool    `json:"block_full_width"`
-	BlockPageWidth     bool    `json:"block_page_width"`
-	BlockPreserveScale bool    `json:"block_preserve_scale"`
-	BlockWidth         float64 `json:"block_width"`
-	DisplaySource      string  `json:"display_source,omitempty"`
+	BlockAspectRatio   Number `json:"block_aspect_ratio"`
+	BlockFullWidth     bool   `json:"block_full_width"`
+	BlockPageWidth     bool   `json:"block_page_width"`
+	BlockPreserveScale bool   `json:"block_preserve_scale"`
+	BlockWidth         Number `json:"block_width"`
+	DisplaySource      string `json:"display_source,omitempty"`
 
 	// calculated by us
 	ImageURL string `json:"image_url,omitempty"`
@@ -196,27 +559,135 @@ type FormatImage struct {
 
 // FormatVideo describes fromat form BlockVideo
 type FormatVideo struct {
-	BlockWidth         int64   `json:"block_width"`
-	BlockHeight        int64   `json:"block_height"`
-	DisplaySource      string  `json:"display_source"`
-	BlockFullWidth     bool    `json:"block_full_width"`
-	BlockPageWidth     bool    `json:"block_page_width"`
-	BlockAspectRatio   float64 `json:"block_aspect_ratio"`
-	BlockPreserveScale bool    `json:"block_preserve_scale"`
+	BlockWidth  Number `json:"block_width"`
+	BlockHeight Number `json:"block_height"`
+	// for videos from youtube, vimeo etc. it's the url that can be
+	// embedded in an iframe. For uploaded videos it's the same as Source
+	DisplaySource      string `json:"display_source"`
+	BlockFullWidth     bool   `json:"block_full_width"`
+	BlockPageWidth     bool   `json:"block_page_width"`
+	BlockAspectRatio   Number `json:"block_aspect_ratio"`
+	BlockPreserveScale bool   `json:"block_preserve_scale"`
+
+	// calculated by us: url of an uploaded video file (see IsUpload) or
+	// of the embed. Unlike images, it's not proxied via notion server
+	VideoURL string `json:"video_url,omitempty"`
+	// true if the video was uploaded to Notion, as opposed to embedded
+	// from youtube etc.
+	IsUpload bool `json:"is_upload,omitempty"`
+}
+
+// FormatAudio describes format for BlockAudio
+type FormatAudio struct {
+	DisplaySource string `json:"display_source,omitempty"`
+}
+
+// FormatPDF describes format for BlockPDF
+type FormatPDF struct {
+	BlockWidth         Number `json:"block_width"`
+	BlockHeight        Number `json:"block_height"`
+	DisplaySource      string `json:"display_source"`
+	BlockFullWidth     bool   `json:"block_full_width"`
+	BlockPageWidth     bool   `json:"block_page_width"`
+	BlockAspectRatio   Number `json:"block_aspect_ratio"`
+	BlockPreserveScale bool   `json:"block_preserve_scale"`
+}
+
+// FormatDrive describes format for BlockDrive
+type FormatDrive struct {
+	DriveProperties *DriveProperties `json:"drive_properties,omitempty"`
+}
+
+// DriveProperties describes a Google Drive file in BlockDrive
+type DriveProperties struct {
+	FileID string `json:"file_id"`
+	// url of the icon of the file type
+	Icon  string `json:"icon"`
+	Title string `json:"title"`
+	// url of a preview image of the file
+	Thumbnail string `json:"thumbnail"`
+	URL       string `json:"url"`
+	// unix time in milliseconds
+	ModifiedTime int64  `json:"modified_time"`
+	UserName     string `json:"user_name"`
+	Trashed      bool   `json:"trashed"`
+
+	// calculated by us
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+// FormatAlias describes format for BlockAlias
+type FormatAlias struct {
+	AliasPointer *BlockPointer `json:"alias_pointer,omitempty"`
+}
+
+// FormatTransclusionReference describes format for
+// BlockTransclusionReference
+type FormatTransclusionReference struct {
+	TransclusionReferencePointer *BlockPointer `json:"transclusion_reference_pointer,omitempty"`
+}
+
+// BlockPointer points to a block from another block, like the page linked
+// by BlockAlias
+type BlockPointer struct {
+	ID      string `json:"id"`
+	Table   string `json:"table"`
+	SpaceID string `json:"spaceId,omitempty"`
 }
 
-// FormatText describes format for BlockText
-// TODO: possibly more?
+// FormatText describes format for BlockText, BlockToggle and BlockQuote
 type FormatText struct {
+	// one of Color* constants, see Block.BlockColor
 	BlockColor *string `json:"block_color,omitempty"`
 }
 
+// FormatTableOfContents describes format for BlockTableOfContents
+type FormatTableOfContents struct {
+	BlockColor *string `json:"block_color,omitempty"`
+}
+
+// FormatList describes format for BlockBulletedList and BlockNumberedList
+type FormatList struct {
+	BlockColor *string `json:"block_color,omitempty"`
+	// for the first item of BlockNumberedList, number of the item if it
+	// doesn't start with 1
+	ListStartIndex int `json:"list_start_index,omitempty"`
+	// for BlockNumberedList "numbers", "letters" or "roman"
+	ListFormat string `json:"list_format,omitempty"`
+}
+
+// FormatCallout describes format for BlockCallout
+type FormatCallout struct {
+	// emoji or url of an image
+	PageIcon   string  `json:"page_icon"`
+	BlockColor *string `json:"block_color,omitempty"`
+}
+
+// FormatHeader describes format for BlockHeader, BlockSubHeader and
+// BlockSubSubHeader
+type FormatHeader struct {
+	BlockColor *string `json:"block_color,omitempty"`
+	// if true, the header can be collapsed to hide blocks in Content
+	Toggleable bool `json:"toggleable"`
+}
+
 // FormatTable describes format for BlockTable
 type FormatTable struct {
 	TableWrap       bool             `json:"table_wrap"`
 	TableProperties []*TableProperty `json:"table_properties"`
 }
 
+// FormatTableBlock describes format for a simple BlockTable
+type FormatTableBlock struct {
+	// ids of columns in the order they are shown. Cells of BlockTableRow
+	// are properties with those names
+	TableBlockColumnOrder []string `json:"table_block_column_order"`
+	// if true, the first row is a header
+	TableBlockColumnHeader bool `json:"table_block_column_header"`
+	// if true, the first column is a header
+	TableBlockRowHeader bool `json:"table_block_row_header"`
+}
+
 // TableProperty describes property of a table
 type TableProperty struct {
 	Width    int    `json:"width"`
@@ -226,42 +697,128 @@ type TableProperty struct {
 
 // FormatColumn describes format for BlockColumn
 type FormatColumn struct {
-	ColumnRation float64 `json:"column_ratio"` // e.g. 0.5 for half-sized column
+	ColumnRation Number `json:"column_ratio"` // e.g. 0.5 for half-sized column
 }
 
-// FormatEmbed describes format for BlockEmbed
+// FormatEmbed describes format for BlockEmbed and embeds of specific
+// services, like BlockFigma, BlockTweet, BlockGist, BlockMaps or BlockCodepen
 type FormatEmbed struct {
-	BlockFullWidth     bool    `json:"block_full_width"`
-	BlockHeight        float64 `json:"block_height"`
-	BlockPageWidth     bool    `json:"block_page_width"`
-	BlockPreserveScale bool    `json:"block_preserve_scale"`
-	DisplaySource      string  `json:"display_source"`
+	BlockFullWidth     bool   `json:"block_full_width"`
+	BlockHeight        Number `json:"block_height"`
+	BlockPageWidth     bool   `json:"block_page_width"`
+	BlockPreserveScale bool   `json:"block_preserve_scale"`
+	DisplaySource      string `json:"display_source"`
 }
 
-// Permission describes user permissions
+// Permission describes who can access a block, usually set on the root
+// block of a page
 type Permission struct {
-	Role   string  `json:"role"`
-	Type   string  `json:"type"`
-	UserID *string `json:"user_id,omitempty"`
+	// RoleEditor, RoleReader etc.
+	Role string `json:"role"`
+	// PermissionTypeUser, PermissionTypePublic or PermissionTypeSpace
+	Type string `json:"type"`
+	// for PermissionTypeUser
+	UserID string `json:"user_id,omitempty"`
+	// for PermissionTypePublic, if true search engines can index the page
+	AllowSearchEngineIndexing bool `json:"allow_search_engine_indexing,omitempty"`
+	// for PermissionTypePublic, if true anyone can duplicate the page as
+	// a template
+	AllowDuplicateAsTemplate bool `json:"allow_duplicate,omitempty"`
+}
+
+// UnmarshalJSON decodes a Permission. Some roles are objects describing
+// access in detail instead of a name, Role is empty for them
+func (p *Permission) UnmarshalJSON(d []byte) error {
+	type permission Permission
+	var v struct {
+		permission
+		Role json.RawMessage `json:"role"`
+	}
+	if err := json.Unmarshal(d, &v); err != nil {
+		return err
+	}
+	*p = Permission(v.permission)
+	p.Role = ""
+	if len(v.Role) > 0 {
+		// not a string is not an error
+		json.Unmarshal(v.Role, &p.Role)
+	}
+	return nil
 }
 
-// GetRecordValues executes a raw API call /api/v3/getRecordValues
-func (c *Client) GetRecordValues(ids []string) (*GetRecordValuesResponse, error) {
-	req := &getRecordValuesRequest{}
+// GetBlockRecords returns blocks with given ids using /api/v3/syncRecordValues
+// api (or /api/v3/getRecordValues, see Client.UseGetRecordValues).
+// Results are in the same order as ids
+func (c *Client) GetBlockRecords(ids []string) (*GetRecordValuesResponse, error) {
+	return c.GetBlockRecordsCtx(context.Background(), ids)
+}
 
+// GetBlockRecordsCtx is like GetBlockRecords but can be canceled with ctx
+func (c *Client) GetBlockRecordsCtx(ctx context.Context, ids []string) (*GetRecordValuesResponse, error) {
+	var requests []RecordRequest
 	for _, id := range ids {
-		v := getRecordValuesRequestInner{
+		v := RecordRequest{
 			Table: TableBlock,
 			ID:    id,
 		}
-		req.Requests = append(req.Requests, v)
+		requests = append(requests, v)
 	}
 
-	apiURL := "/api/v3/getRecordValues"
-	var rsp GetRecordValuesResponse
-	err := doNotionAPI(c, apiURL, req, &rsp)
+	records, err := c.getRecords(ctx, requests)
 	if err != nil {
 		return nil, err
 	}
-	return &rsp, nil
+	rsp := &GetRecordValuesResponse{}
+	for _, r := range records {
+		res := &BlockWithRole{}
+		if r != nil {
+			res.Role = r.Role
+			if len(r.Value) > 0 && string(r.Value) != "null" {
+				var block Block
+				err = json.Unmarshal(r.Value, &block)
+				if err != nil {
+					return nil, err
+				}
+				res.Value = &block
+			}
+		}
+		rsp.Results = append(rsp.Results, res)
+	}
+	return rsp, nil
+}
+
+// GetBlockVersions returns versions of blocks with given ids. It's a cheap
+// way to check if blocks changed without downloading their content.
+// Blocks that don't exist or were deleted are not in the result
+func (c *Client) GetBlockVersions(ids []string) (map[string]int64, error) {
+	return c.GetBlockVersionsCtx(context.Background(), ids)
+}
+
+// GetBlockVersionsCtx is like GetBlockVersions but can be canceled with ctx
+func (c *Client) GetBlockVersionsCtx(ctx context.Context, ids []string) (map[string]int64, error) {
+	// limit the size of a single request
+	const maxIDs = 100
+	res := map[string]int64{}
+	for len(ids) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n := len(ids)
+		if n > maxIDs {
+			n = maxIDs
+		}
+		rsp, err := c.GetBlockRecordsCtx(ctx, ids[:n])
+		if err != nil {
+			return nil, err
+		}
+		// results are in the same order as requested ids
+		for i, r := range rsp.Results {
+			if i >= n || r == nil || r.Value == nil || !r.Value.Alive {
+				continue
+			}
+			res[ids[i]] = r.Value.Version
+		}
+		ids = ids[n:]
+	}
+	return res, nil
 }
@@ -1,8 +1,12 @@
 package notionapi
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -55,3 +59,856 @@ func TestGetRecordValues1(t *testing.T) {
 		assert.Equal(t, int64(34), v.Version)
 	}
 }
+
+// parseTestBlock parses block from its JSON the way DownloadPage does
+func parseTestBlock(t *testing.T, s string) *Block {
+	var block Block
+	err := json.Unmarshal([]byte(s), &block)
+	assert.NoError(t, err)
+	assert.NoError(t, parseProperties(&block))
+	assert.NoError(t, parseFormat(&block))
+	return &block
+}
+
+func TestParseToggle(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "toggle",
+	"alive": true,
+	"content": ["00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"],
+	"properties": { "title": [["Click "], ["me", [["b"]]]] },
+	"format": { "block_color": "blue_background" }
+}`)
+	assert.True(t, block.IsToggleable())
+	assert.Equal(t, 2, len(block.InlineContent))
+	assert.Equal(t, "me", block.InlineContent[1].Text)
+	assert.Equal(t, AttrBold, block.InlineContent[1].AttrFlags)
+	assert.Equal(t, "blue_background", *block.FormatText.BlockColor)
+	assert.Equal(t, []string{"00000000-0000-0000-0000-000000000001", "00000000-0000-0000-0000-000000000002"}, block.ContentIDs)
+}
+
+func TestParseFormatPage(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "3b9e8f5c-2a1d-4c3b-8e7f-6a5b4c3d2e1f",
+	"type": "page",
+	"alive": true,
+	"properties": { "title": [["Themed"]] },
+	"format": {
+		"page_icon": "https://example.com/icon.png",
+		"page_cover": "/images/page-cover/gradients_11.jpg",
+		"page_cover_position": 0.6,
+		"page_font": "serif",
+		"block_color": "brown",
+		"block_locked": true,
+		"block_locked_by": "bb760e2d-d679-4b64-b2a9-03005b21870a",
+		"page_section_visibility": { "backlinks": "section", "comments": "off" }
+	}
+}`)
+	f := block.FormatPage
+	assert.Equal(t, 0.6, f.PageCoverPosition)
+	assert.Equal(t, "serif", f.PageFont)
+	assert.Equal(t, "brown", *f.BlockColor)
+	assert.True(t, f.BlockLocked)
+	assert.Equal(t, "bb760e2d-d679-4b64-b2a9-03005b21870a", f.BlockLockedBy)
+	assert.Equal(t, "section", f.PageSectionVisibility.Backlinks)
+	assert.Equal(t, "off", f.PageSectionVisibility.Comments)
+	assert.Equal(t, "https://www.notion.so/image/https:%2F%2Fexample.com%2Ficon.png", f.PageIconURL)
+
+	block = parseTestBlock(t, `{
+	"id": "3b9e8f5c-2a1d-4c3b-8e7f-6a5b4c3d2e1f",
+	"type": "page",
+	"format": { "page_icon": "📝" }
+}`)
+	assert.Equal(t, "📝", block.FormatPage.PageIcon)
+	assert.Equal(t, "", block.FormatPage.PageIconURL)
+	assert.False(t, block.FormatPage.BlockLocked)
+}
+
+func TestParseCallout(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "callout",
+	"alive": true,
+	"properties": { "title": [["Remember to "], ["commit", [["i"]]]] },
+	"format": { "page_icon": "💡", "block_color": "gray_background" }
+}`)
+	assert.Equal(t, "💡", block.FormatCallout.PageIcon)
+	assert.Equal(t, "gray_background", *block.FormatCallout.BlockColor)
+	assert.Equal(t, 2, len(block.InlineContent))
+	assert.Equal(t, AttrItalic, block.InlineContent[1].AttrFlags)
+
+	page := testPage(block)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, "<div class=\"callout\"><span class=\"icon\">💡</span> Remember to <em>commit</em>\n</div>\n")
+	got = string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "> 💡 Remember to _commit_\n")
+}
+
+func TestParseQuote(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "quote",
+	"alive": true,
+	"properties": { "title": [["To be "], ["or", [["b"]]], [" not", [["a", "https://en.wikipedia.org/wiki/Hamlet"]]]] },
+	"format": { "block_color": "brown" }
+}`)
+	assert.Equal(t, 3, len(block.InlineContent))
+	assert.Equal(t, AttrBold, block.InlineContent[1].AttrFlags)
+	assert.Equal(t, "https://en.wikipedia.org/wiki/Hamlet", block.InlineContent[2].Link)
+	assert.Equal(t, "brown", *block.FormatText.BlockColor)
+
+	block.Content = []*Block{textBlock(BlockText, "nested")}
+	page := testPage(block)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, "<blockquote class=\"block-color-brown\">To be <strong>or</strong><a href=\"https://en.wikipedia.org/wiki/Hamlet\"> not</a>\n<p>nested</p>\n</blockquote>\n")
+	got = string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "> To be **or** [not](https://en.wikipedia.org/wiki/Hamlet)\n>\n> nested\n\n")
+}
+
+func TestParseDivider(t *testing.T) {
+	for _, s := range []string{
+		`{"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c", "type": "divider", "alive": true}`,
+		`{"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c", "type": "divider", "alive": true, "properties": {}, "format": {}}`,
+	} {
+		block := parseTestBlock(t, s)
+		assert.Equal(t, BlockDivider, block.Type)
+		assert.Nil(t, block.InlineContent)
+		page := testPage(block)
+		assert.Contains(t, string(ToHTML(page, &HTMLOptions{Fragment: true})), "<hr>\n")
+		assert.Contains(t, string(ToMarkdown(page, nil)), "---\n")
+	}
+}
+
+func TestParseToggleableHeaders(t *testing.T) {
+	for _, typ := range []string{BlockHeader, BlockSubHeader, BlockSubSubHeader} {
+		block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "`+typ+`",
+	"alive": true,
+	"content": ["00000000-0000-0000-0000-000000000001"],
+	"properties": { "title": [["Details"]] },
+	"format": { "toggleable": true, "block_color": "red" }
+}`)
+		assert.True(t, block.IsToggleable())
+		assert.Equal(t, "red", *block.FormatHeader.BlockColor)
+		assert.Equal(t, "Details", block.InlineContent[0].Text)
+	}
+	block := parseTestBlock(t, `{"id": "1", "type": "sub_sub_header", "properties": { "title": [["Plain"]] }}`)
+	assert.False(t, block.IsToggleable())
+}
+
+func TestListIndexes(t *testing.T) {
+	idToBlock := map[string]*Block{}
+	add := func(id string, typ string, format string, content ...string) *Block {
+		b := &Block{
+			ID:         id,
+			Type:       typ,
+			Alive:      true,
+			ContentIDs: content,
+			Properties: map[string]interface{}{"title": []interface{}{[]interface{}{id}}},
+		}
+		if format != "" {
+			b.FormatRaw = json.RawMessage(format)
+		}
+		idToBlock[id] = b
+		return b
+	}
+	root := add("root", BlockPage, "", "n1", "n2", "text", "n3", "n4")
+	add("n1", BlockNumberedList, "")
+	add("n2", BlockNumberedList, "", "n2a", "n2b", "n2c")
+	add("n2a", BlockNumberedList, "")
+	add("n2b", BlockNumberedList, "", "n2b1")
+	add("n2b1", BlockBulletedList, `{"block_color":"red"}`)
+	add("n2c", BlockNumberedList, "")
+	add("text", BlockText, "")
+	add("n3", BlockNumberedList, `{"list_start_index":5,"list_format":"letters"}`)
+	add("n4", BlockNumberedList, "")
+	err := resolveBlocks(root, idToBlock, nil)
+	assert.NoError(t, err)
+
+	for id, exp := range map[string][2]int{
+		"n1": {1, 0}, "n2": {2, 0}, "n2a": {1, 1}, "n2b": {2, 1}, "n2b1": {0, 2},
+		"n2c": {3, 1}, "text": {0, 0}, "n3": {5, 0}, "n4": {6, 0},
+	} {
+		b := idToBlock[id]
+		assert.Equal(t, exp[0], b.ListIndex, "ListIndex of %s", id)
+		assert.Equal(t, exp[1], b.ListNesting, "ListNesting of %s", id)
+	}
+	assert.Equal(t, "red", *idToBlock["n2b1"].FormatList.BlockColor)
+	assert.Equal(t, "letters", idToBlock["n3"].FormatList.ListFormat)
+
+	page := &Page{ID: "root", Root: root}
+	got := string(ToMarkdown(page, nil))
+	exp := `# root
+
+1. n1
+2. n2
+    1. n2a
+    2. n2b
+        - n2b1
+
+    3. n2c
+
+text
+
+5. n3
+6. n4
+
+`
+	assert.Equal(t, exp, got)
+	got = string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, "<ol start=\"5\">\n<li>n3</li>\n<li>n4</li>\n</ol>\n")
+}
+
+func TestParseAudio(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "audio",
+	"alive": true,
+	"properties": {
+		"source": [["https://s3-us-west-2.amazonaws.com/secure.notion-static.com/b5d3/intro.mp3"]],
+		"size": [["1.2MB"]],
+		"title": [["intro.mp3"]]
+	},
+	"format": { "display_source": "https://s3-us-west-2.amazonaws.com/secure.notion-static.com/b5d3/intro.mp3" }
+}`)
+	src := "https://s3-us-west-2.amazonaws.com/secure.notion-static.com/b5d3/intro.mp3"
+	assert.Equal(t, src, block.Source)
+	assert.Equal(t, "1.2MB", block.FileSize)
+	assert.Equal(t, src, block.FormatAudio.DisplaySource)
+	// private uploads are downloaded via signed url
+	assert.Equal(t, notionSignedURL(src, block.ID), assetDownloadURL(block))
+
+	page := testPage(block)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, "<audio controls src=\""+src+"\"></audio>")
+}
+
+func TestParseVideo(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "video",
+	"alive": true,
+	"properties": { "source": [["https://www.youtube.com/watch?v=dQw4w9WgXcQ"]] },
+	"format": {
+		"block_width": 640,
+		"block_height": 360,
+		"block_full_width": false,
+		"block_page_width": true,
+		"block_aspect_ratio": 0.5625,
+		"block_preserve_scale": true,
+		"display_source": "https://www.youtube.com/embed/dQw4w9WgXcQ"
+	}
+}`)
+	f := block.FormatVideo
+	assert.True(t, f.BlockPageWidth)
+	assert.False(t, f.IsUpload)
+	// not proxied via notion server
+	assert.Equal(t, "https://www.youtube.com/embed/dQw4w9WgXcQ", f.VideoURL)
+	assert.Equal(t, "", assetDownloadURL(block))
+	page := testPage(block)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<figure class="video">
+<iframe src="https://www.youtube.com/embed/dQw4w9WgXcQ" width="640" height="360" allowfullscreen></iframe>
+</figure>`)
+
+	src := "https://s3-us-west-2.amazonaws.com/secure.notion-static.com/c7e1/demo.mp4"
+	block = parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "video",
+	"alive": true,
+	"properties": { "source": [["`+src+`"]] },
+	"format": { "display_source": "`+src+`" }
+}`)
+	assert.True(t, block.FormatVideo.IsUpload)
+	assert.Equal(t, src, block.FormatVideo.VideoURL)
+	assert.Equal(t, notionSignedURL(src, block.ID), assetDownloadURL(block))
+	page = testPage(block)
+	got = string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<video controls src="`+src+`"></video>`)
+	got = string(ToHTML(page, &HTMLOptions{NotionCompatible: true}))
+	assert.Contains(t, got, `<div class="source"><video controls src="`+src+`"></video></div>`)
+}
+
+func TestParsePDF(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "pdf",
+	"alive": true,
+	"properties": {
+		"source": [["https://s3-us-west-2.amazonaws.com/secure.notion-static.com/8b3930e3/report.pdf"]],
+		"size": [["340KB"]]
+	},
+	"format": { "block_height": 600, "block_full_width": false, "block_page_width": true }
+}`)
+	src := "https://s3-us-west-2.amazonaws.com/secure.notion-static.com/8b3930e3/report.pdf"
+	assert.Equal(t, src, block.Source)
+	assert.Equal(t, "340KB", block.FileSize)
+	assert.Equal(t, Number(600), block.FormatPDF.BlockHeight)
+	assert.True(t, block.FormatPDF.BlockPageWidth)
+	assert.Equal(t, "report.pdf", block.fileName())
+	assert.Equal(t, notionSignedURL(src, block.ID), assetDownloadURL(block))
+
+	page := testPage(block)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, "<embed src=\""+src+"\" type=\"application/pdf\" height=\"600\">\n<figcaption><a href=\""+src+"\">report.pdf</a></figcaption>")
+	got = string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "[report.pdf]("+src+")\n")
+}
+
+func TestParseDrive(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "drive",
+	"alive": true,
+	"format": {
+		"drive_properties": {
+			"file_id": "1a2b3c",
+			"icon": "https://drive-thirdparty.googleusercontent.com/16/type/application/vnd.google-apps.document",
+			"modified_time": 1588875580000,
+			"thumbnail": "https://lh3.googleusercontent.com/thumb",
+			"title": "Roadmap",
+			"trashed": false,
+			"url": "https://docs.google.com/document/d/1a2b3c/edit",
+			"user_name": "Jane"
+		}
+	}
+}`)
+	p := block.FormatDrive.DriveProperties
+	assert.Equal(t, "1a2b3c", p.FileID)
+	assert.Equal(t, "Roadmap", p.Title)
+	assert.Equal(t, "Jane", p.UserName)
+	assert.Equal(t, int64(1588875580000), p.ModifiedTime)
+	assert.Equal(t, makeImageURL("https://lh3.googleusercontent.com/thumb"), p.ThumbnailURL)
+
+	page := testPage(block)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<a class="drive" href="https://docs.google.com/document/d/1a2b3c/edit">`)
+	assert.Contains(t, got, `Roadmap</div>`)
+	got = string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "[Roadmap](https://docs.google.com/document/d/1a2b3c/edit)\n")
+}
+
+func TestParseFigma(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "figma",
+	"alive": true,
+	"properties": { "source": [["https://www.figma.com/file/abc/Design?node-id=0%3A1"]] },
+	"format": {
+		"block_height": 450,
+		"block_page_width": true,
+		"display_source": "https://www.figma.com/file/abc/Design?node-id=0%3A1"
+	}
+}`)
+	src := "https://www.figma.com/file/abc/Design?node-id=0%3A1"
+	assert.Equal(t, src, block.Source)
+	assert.Equal(t, Number(450), block.FormatEmbed.BlockHeight)
+	assert.Equal(t, src, block.FormatEmbed.DisplaySource)
+
+	page := testPage(block)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<iframe src="https://www.figma.com/embed?embed_host=notion&amp;url=https%3A%2F%2Fwww.figma.com%2Ffile%2Fabc%2FDesign%3Fnode-id%3D0%253A1" height="450" allowfullscreen></iframe>`)
+	got = string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "("+src+")\n")
+}
+
+func TestParseTweet(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "tweet",
+	"alive": true,
+	"properties": { "source": [["https://twitter.com/kjk/status/1258491361371246593?s=20"]] },
+	"format": { "display_source": "https://twitter.com/kjk/status/1258491361371246593?s=20" }
+}`)
+	src := "https://twitter.com/kjk/status/1258491361371246593?s=20"
+	assert.Equal(t, src, block.Source)
+	assert.Equal(t, "1258491361371246593", block.TweetID)
+	assert.Equal(t, src, block.FormatEmbed.DisplaySource)
+	assert.Equal(t, "", tweetIDFromURL("https://twitter.com/kjk"))
+
+	page := testPage(block)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<blockquote class="twitter-tweet"><a href="https://twitter.com/kjk/status/1258491361371246593?s=20">`)
+	got = string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "("+src+")\n")
+}
+
+func TestParseGist(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "gist",
+	"alive": true,
+	"properties": { "source": [["https://gist.github.com/kjk/7278df6c7b12a1b7ea5e4891e3a3f49e"]] },
+	"format": { "display_source": "https://gist.github.com/kjk/7278df6c7b12a1b7ea5e4891e3a3f49e" }
+}`)
+	uri := "https://gist.github.com/kjk/7278df6c7b12a1b7ea5e4891e3a3f49e"
+	assert.Equal(t, uri, block.GistURL())
+	assert.Equal(t, uri, block.FormatEmbed.DisplaySource)
+
+	page := testPage(block)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<script src="`+uri+`.js"></script>`+"\n"+`<noscript><p class="gist"><a href="`+uri+`">`)
+	got = string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "("+uri+")\n")
+
+	// older gist blocks don't have format
+	block = parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "gist",
+	"alive": true,
+	"properties": { "source": [["https://gist.github.com/kjk/7278df6c7b12a1b7ea5e4891e3a3f49e"]] }
+}`)
+	assert.Equal(t, uri, block.GistURL())
+}
+
+func TestParseMaps(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "maps",
+	"alive": true,
+	"properties": { "source": [["https://www.google.com/maps/place/Eiffel+Tower/@48.8583701,2.2922926,17z"]] },
+	"format": {
+		"block_height": 300,
+		"display_source": "https://www.google.com/maps/embed?pb=!1m18"
+	}
+}`)
+	assert.Equal(t, 48.8583701, *block.Lat)
+	assert.Equal(t, 2.2922926, *block.Lng)
+	assert.Equal(t, Number(300), block.FormatEmbed.BlockHeight)
+
+	lat, lng := mapCoordinatesFromURL("https://maps.google.com/?q=-33.85,151.21")
+	assert.Equal(t, -33.85, *lat)
+	assert.Equal(t, 151.21, *lng)
+	lat, lng = mapCoordinatesFromURL("https://www.google.com/maps/place/Paris")
+	assert.Nil(t, lat)
+	assert.Nil(t, lng)
+
+	page := testPage(block)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<figure class="maps">`+"\n"+`<iframe src="https://www.google.com/maps/embed?pb=!1m18" height="300" allowfullscreen></iframe>`)
+}
+
+func TestParseCodepen(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "codepen",
+	"alive": true,
+	"properties": { "source": [["https://codepen.io/kjk/pen/KKwEGmg"]] },
+	"format": {
+		"block_height": 400,
+		"display_source": "https://codepen.io/kjk/pen/KKwEGmg"
+	}
+}`)
+	assert.Equal(t, "https://codepen.io/kjk/pen/KKwEGmg", block.Source)
+	assert.Equal(t, Number(400), block.FormatEmbed.BlockHeight)
+	assert.Equal(t, "https://codepen.io/kjk", codepenEmbedURL("https://codepen.io/kjk"))
+
+	page := testPage(block)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<iframe src="https://codepen.io/kjk/embed/KKwEGmg?default-tab=result" height="400" allowfullscreen></iframe>`)
+	got = string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "(https://codepen.io/kjk/pen/KKwEGmg)\n")
+}
+
+func TestParseGenericEmbed(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "loom",
+	"alive": true,
+	"properties": { "source": [["https://www.loom.com/share/0281766fa2d04bb788eaf19e65135184"]] },
+	"format": {
+		"block_height": 360,
+		"display_source": "https://www.loom.com/embed/0281766fa2d04bb788eaf19e65135184"
+	}
+}`)
+	assert.True(t, block.IsEmbedBlock())
+	assert.Equal(t, "https://www.loom.com/embed/0281766fa2d04bb788eaf19e65135184", block.FormatEmbed.DisplaySource)
+	assert.False(t, (&Block{Type: BlockText}).IsEmbedBlock())
+
+	page := testPage(block)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<figure class="loom">`+"\n"+`<iframe src="https://www.loom.com/embed/0281766fa2d04bb788eaf19e65135184" height="360" allowfullscreen></iframe>`)
+	got = string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "(https://www.loom.com/share/0281766fa2d04bb788eaf19e65135184)\n")
+}
+
+func TestParseFactory(t *testing.T) {
+	idToBlock := map[string]*Block{}
+	add := func(id string, typ string, title string, content ...string) *Block {
+		b := &Block{
+			ID:         id,
+			Type:       typ,
+			Alive:      true,
+			ContentIDs: content,
+			Properties: map[string]interface{}{"title": []interface{}{[]interface{}{title}}},
+		}
+		idToBlock[id] = b
+		return b
+	}
+	root := add("root", BlockPage, "Tasks", "factory")
+	factory := add("factory", BlockFactory, "Add task", "todo")
+	add("todo", BlockTodo, "New task")
+	err := resolveBlocks(root, idToBlock, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "Add task", factory.InlineContent[0].Text)
+	assert.Nil(t, factory.Content)
+	assert.Equal(t, []*Block{idToBlock["todo"]}, factory.TemplateContent)
+	assert.Equal(t, []string{"todo"}, factory.ContentIDs)
+
+	page := &Page{ID: "root", Root: root}
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<p class="factory"><button>Add task</button></p>`)
+	assert.NotContains(t, got, "New task")
+	got = string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "Add task\n")
+	assert.NotContains(t, got, "New task")
+
+	// SavePage keeps the template
+	var buf bytes.Buffer
+	err = SavePage(&buf, page)
+	assert.NoError(t, err)
+	page2, err := LoadPage(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "todo", page2.Root.Content[0].TemplateContent[0].ID)
+}
+
+func TestCopiedFrom(t *testing.T) {
+	idToBlock := map[string]*Block{}
+	add := func(id string, typ string, copiedFrom string, content ...string) *Block {
+		b := &Block{
+			ID:         id,
+			Type:       typ,
+			Alive:      true,
+			ContentIDs: content,
+			CopiedFrom: copiedFrom,
+		}
+		idToBlock[id] = b
+		return b
+	}
+	root := add("root", BlockPage, "", "factory", "task1", "task2", "note")
+	add("factory", BlockFactory, "", "template")
+	add("template", BlockTodo, "", "template-child")
+	add("template-child", BlockText, "")
+	add("task1", BlockTodo, "template", "task1-child")
+	add("task1-child", BlockText, "template-child")
+	// copied from a block that isn't a template
+	add("task2", BlockTodo, "note")
+	note := add("note", BlockText, "")
+	note.CopiedFromPointer = &BlockPointer{ID: "elsewhere", Table: TableBlock}
+	err := resolveBlocks(root, idToBlock, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "template", idToBlock["task1"].CopiedFromID())
+	assert.Equal(t, "factory", idToBlock["task1"].FactoryID)
+	assert.Equal(t, "factory", idToBlock["task1-child"].FactoryID)
+	assert.Equal(t, "", idToBlock["task2"].FactoryID)
+	assert.Equal(t, "elsewhere", note.CopiedFromID())
+	assert.Equal(t, "", note.FactoryID)
+	assert.Equal(t, "", root.CopiedFromID())
+}
+
+func TestSyncedBlocks(t *testing.T) {
+	idToBlock := map[string]*Block{}
+	add := func(id string, typ string, format string, content ...string) *Block {
+		b := &Block{
+			ID:         id,
+			Type:       typ,
+			Alive:      true,
+			ContentIDs: content,
+			FormatRaw:  json.RawMessage(format),
+		}
+		if typ == BlockText {
+			b.Properties = map[string]interface{}{"title": []interface{}{[]interface{}{id}}}
+		}
+		idToBlock[id] = b
+		return b
+	}
+	pointer := func(id string) string {
+		return `{"transclusion_reference_pointer":{"id":"` + id + `","table":"block"}}`
+	}
+	root := add("root", BlockPage, "{}", "container", "ref", "loop")
+	add("container", BlockTransclusionContainer, "{}", "text")
+	add("text", BlockText, "{}")
+	ref := add("ref", BlockTransclusionReference, pointer("container"))
+	// a synced block that contains a reference to itself
+	add("loop", BlockTransclusionContainer, "{}", "loop-ref")
+	loopRef := add("loop-ref", BlockTransclusionReference, pointer("loop"))
+
+	// the original is downloaded with the reference
+	assert.Equal(t, []string{"container2"}, findMissingBlocks([]string{"ref2"}, map[string]*Block{
+		"ref2": {ID: "ref2", Type: BlockTransclusionReference, FormatRaw: json.RawMessage(pointer("container2"))},
+	}, nil))
+
+	var warnings []*Warning
+	err := resolveBlocks(root, idToBlock, &warnings)
+	assert.NoError(t, err)
+	assert.Equal(t, "container", ref.FormatTransclusionReference.TransclusionReferencePointer.ID)
+	assert.Equal(t, []*Block{idToBlock["text"]}, ref.Content)
+	assert.Nil(t, loopRef.Content)
+	assert.Equal(t, 1, len(warnings))
+	assert.Equal(t, "loop-ref", warnings[0].BlockID)
+
+	page := &Page{ID: "root", Root: root}
+	got := string(ToMarkdown(page, nil))
+	assert.Equal(t, "text\n\ntext\n\n", got)
+}
+
+func TestSimpleTable(t *testing.T) {
+	table := parseTestBlock(t, `{
+	"id": "table",
+	"type": "table",
+	"alive": true,
+	"content": ["row1", "row2"],
+	"format": {
+		"table_block_column_order": ["a:Bc", "xYz="],
+		"table_block_column_header": true
+	}
+}`)
+	row1 := parseTestBlock(t, `{"id": "row1", "type": "table_row", "alive": true, "properties": {"a:Bc": [["Name"]], "xYz=": [["Size"]]}}`)
+	row2 := parseTestBlock(t, `{"id": "row2", "type": "table_row", "alive": true, "properties": {"a:Bc": [["big", [["b"]]], [" file"]], "xYz=": [["1|2"]]}}`)
+	table.Content = []*Block{row1, row2}
+
+	assert.Equal(t, []string{"a:Bc", "xYz="}, table.FormatTableBlock.TableBlockColumnOrder)
+	assert.True(t, table.FormatTableBlock.TableBlockColumnHeader)
+	assert.False(t, table.FormatTableBlock.TableBlockRowHeader)
+	cells := table.TableCells()
+	assert.Equal(t, 2, len(cells))
+	assert.Equal(t, "Size", InlineToText(cells[0][1]))
+	assert.Equal(t, AttrBold, cells[1][0][0].AttrFlags)
+
+	page := testPage(table)
+	got := string(ToMarkdown(page, nil))
+	assert.Contains(t, got, "| Name | Size |\n| --- | --- |\n| **big** file | 1\\|2 |\n")
+	got = string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, "<table class=\"simple-table\">\n<thead>\n<tr><th>Name</th><th>Size</th></tr>\n</thead>\n<tr><td><strong>big</strong> file</td><td>1|2</td></tr>\n</table>\n")
+	assert.Equal(t, "Test page\nName\tSize\nbig file\t1|2\n", page.ToText())
+}
+
+func TestUnknownBlockType(t *testing.T) {
+	s := `{"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c", "type": "hologram", "alive": true, "format": {"beam": 3}}`
+	block := parseTestBlock(t, s)
+	assert.True(t, block.IsUnknownType())
+	assert.Equal(t, s, string(block.RawJSON))
+	assert.False(t, parseTestBlock(t, `{"id": "1", "type": "text"}`).IsUnknownType())
+	assert.Nil(t, parseTestBlock(t, `{"id": "1", "type": "text"}`).RawJSON)
+
+	var warnings []*Warning
+	root := &Block{ID: "root", Type: BlockPage, ContentIDs: []string{block.ID}}
+	err := resolveBlocks(root, map[string]*Block{block.ID: block}, &warnings)
+	assert.NoError(t, err)
+	assert.Equal(t, "unknown block type 'hologram'", warnings[0].Message)
+
+	page := &Page{ID: "root", Root: root}
+	assert.Equal(t, []string{"hologram"}, page.UnknownTypes())
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, "<!-- unsupported block type 'hologram'")
+	opts := &HTMLOptions{
+		Fragment: true,
+		RenderUnknownBlock: func(w io.Writer, b *Block) {
+			fmt.Fprintf(w, "<div class=\"unknown\">%s</div>\n", b.Type)
+		},
+	}
+	got = string(ToHTML(page, opts))
+	assert.Contains(t, got, "<div class=\"unknown\">hologram</div>\n")
+	got = string(ToMarkdown(page, &MarkdownOptions{RenderUnknownBlock: opts.RenderUnknownBlock}))
+	assert.Contains(t, got, "<div class=\"unknown\">hologram</div>\n")
+}
+
+func TestDecodeFormat(t *testing.T) {
+	block := parseTestBlock(t, `{"id": "1", "type": "hologram", "format": {"beam": 3, "color": "red"}}`)
+	var format struct {
+		Beam  int    `json:"beam"`
+		Color string `json:"color"`
+	}
+	assert.NoError(t, block.DecodeFormat(&format))
+	assert.Equal(t, 3, format.Beam)
+	v, ok := block.FormatValue("color")
+	assert.True(t, ok)
+	assert.Equal(t, "red", v)
+	_, ok = block.FormatValue("missing")
+	assert.False(t, ok)
+
+	// blocks without format don't change v
+	block = parseTestBlock(t, `{"id": "1", "type": "hologram"}`)
+	assert.NoError(t, block.DecodeFormat(&format))
+	assert.Equal(t, 3, format.Beam)
+	_, ok = block.FormatValue("color")
+	assert.False(t, ok)
+}
+
+func TestBlockTimes(t *testing.T) {
+	block := parseTestBlock(t, `{"id": "1", "type": "text", "created_time": 1531024380041, "last_edited_time": 1531024387094}`)
+	assert.Equal(t, int64(1531024380041), block.CreatedOn().UnixNano()/int64(time.Millisecond))
+	assert.Equal(t, int64(1531024387094), block.UpdatedOn().UnixNano()/int64(time.Millisecond))
+	assert.True(t, (&Block{}).CreatedOn().IsZero())
+	assert.True(t, (&Block{}).UpdatedOn().IsZero())
+
+	page := testPage(block, &Block{Type: BlockPage, LastEditedTime: 1600000000000})
+	page.Root.LastEditedTime = 1531024380000
+	// sub-pages are not part of the page
+	assert.Equal(t, block.UpdatedOn(), page.LastEdited())
+	assert.True(t, testPage().LastEdited().IsZero())
+}
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp Number
+	}{
+		{`{"block_width": 240.5}`, 240.5},
+		{`{"block_width": 240}`, 240},
+		{`{"block_width": "240.5"}`, 240.5},
+		{`{"block_width": " 240 "}`, 240},
+		{`{"block_width": ""}`, 0},
+		{`{"block_width": null}`, 0},
+		{`{}`, 0},
+	}
+	for _, test := range tests {
+		var f FormatImage
+		err := json.Unmarshal([]byte(test.s), &f)
+		assert.NoError(t, err, test.s)
+		assert.Equal(t, test.exp, f.BlockWidth, test.s)
+	}
+	var f FormatImage
+	err := json.Unmarshal([]byte(`{"block_width": "wide"}`), &f)
+	assert.Error(t, err)
+
+	// a string doesn't fail the whole format
+	block := parseTestBlock(t, `{
+	"id": "0b2f4b55-9a1a-4a6e-8f5e-4b3f0c1d2e3f",
+	"type": "column",
+	"format": { "column_ratio": "0.25" }
+}`)
+	assert.Equal(t, Number(0.25), block.FormatColumn.ColumnRation)
+	block = parseTestBlock(t, `{
+	"id": "0b2f4b55-9a1a-4a6e-8f5e-4b3f0c1d2e3f",
+	"type": "video",
+	"format": { "block_width": "640", "block_height": 360, "block_aspect_ratio": "0.5625" }
+}`)
+	assert.Equal(t, Number(640), block.FormatVideo.BlockWidth)
+	assert.Equal(t, Number(360), block.FormatVideo.BlockHeight)
+	assert.Equal(t, Number(0.5625), block.FormatVideo.BlockAspectRatio)
+	block = parseTestBlock(t, `{
+	"id": "0b2f4b55-9a1a-4a6e-8f5e-4b3f0c1d2e3f",
+	"type": "embed",
+	"format": { "block_height": "480" }
+}`)
+	assert.Equal(t, Number(480), block.FormatEmbed.BlockHeight)
+}
+
+func TestColumnRatios(t *testing.T) {
+	idToBlock := map[string]*Block{}
+	add := func(id string, typ string, format string, content ...string) *Block {
+		b := &Block{
+			ID:         id,
+			Type:       typ,
+			Alive:      true,
+			ContentIDs: content,
+			FormatRaw:  json.RawMessage(format),
+		}
+		idToBlock[id] = b
+		return b
+	}
+	root := add("root", BlockPage, "", "list1", "list2")
+	add("list1", BlockColumnList, "", "c1", "c2", "c3")
+	add("c1", BlockColumn, "")
+	add("c2", BlockColumn, "")
+	add("c3", BlockColumn, "")
+	add("list2", BlockColumnList, "", "c4", "c5")
+	add("c4", BlockColumn, `{"column_ratio": 0.25}`)
+	add("c5", BlockColumn, `{"column_ratio": "0.75"}`)
+	err := resolveBlocks(root, idToBlock, nil)
+	assert.NoError(t, err)
+
+	assert.InDelta(t, 1.0/3, idToBlock["c1"].EffectiveRatio, 1e-9)
+	assert.InDelta(t, 1.0/3, idToBlock["c3"].EffectiveRatio, 1e-9)
+	assert.Equal(t, 0.25, idToBlock["c4"].EffectiveRatio)
+	assert.Equal(t, 0.75, idToBlock["c5"].EffectiveRatio)
+
+	got := string(ToHTML(&Page{ID: "root", Root: root}, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<div class="column" style="width:33.33%">`)
+	assert.Contains(t, got, `<div class="column" style="width:75%">`)
+}
+
+func TestBlockColor(t *testing.T) {
+	block := parseTestBlock(t, `{
+	"id": "6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "text",
+	"properties": { "title": [["warning: ", [["b"]]], ["read this", [["h", "red_background"]]]] },
+	"format": { "block_color": "yellow_background" }
+}`)
+	assert.Equal(t, ColorYellowBackground, block.BlockColor())
+	assert.Equal(t, ColorRedBackground, block.InlineContent[1].Highlight)
+	assert.False(t, block.InlineContent[1].IsPlain())
+
+	header := parseTestBlock(t, `{
+	"id": "7f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "sub_header",
+	"properties": { "title": [["Title"]] },
+	"format": { "block_color": "blue" }
+}`)
+	assert.Equal(t, ColorBlue, header.BlockColor())
+	item := parseTestBlock(t, `{
+	"id": "8f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c",
+	"type": "bulleted_list",
+	"properties": { "title": [["item"]] },
+	"format": { "block_color": "teal" }
+}`)
+	assert.Equal(t, ColorTeal, item.BlockColor())
+	assert.Equal(t, "", textBlock(BlockText, "plain").BlockColor())
+
+	page := testPage(block, header, item)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<p class="block-color-yellow_background"><strong>warning: </strong><mark class="highlight-red_background">read this</mark></p>`)
+	assert.Contains(t, got, `<h2 id="title-4f258a" class="block-color-blue">Title</h2>`)
+	assert.Contains(t, got, `<li class="block-color-teal">item</li>`)
+	got = string(ToHTML(page, &HTMLOptions{NotionCompatible: true}))
+	assert.Contains(t, got, `<p id="6f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c" class="block-color-yellow_background">`)
+	assert.Contains(t, got, `<ul id="8f8a2b4c-1d2e-4f3a-9b8c-7d6e5f4a3b2c" class="bulleted-list block-color-teal">`)
+}
+
+func TestParentPointers(t *testing.T) {
+	idToBlock := map[string]*Block{}
+	add := func(id string, parentID string, content ...string) *Block {
+		b := &Block{
+			ID:          id,
+			Type:        BlockText,
+			Alive:       true,
+			ContentIDs:  content,
+			ParentID:    parentID,
+			ParentTable: TableBlock,
+		}
+		idToBlock[id] = b
+		return b
+	}
+	root := add("root", "parent-of-root", "a")
+	root.Type = BlockPage
+	a := add("a", "root", "b")
+	b := add("b", "a")
+	// a block the server sent without its parent, e.g. a synced block
+	// from another page
+	c := add("c", "not-downloaded")
+	d := add("d", "not-downloaded")
+	err := resolveBlocks(root, idToBlock, nil)
+	assert.NoError(t, err)
+
+	assert.Nil(t, root.Parent)
+	assert.Equal(t, root, a.Parent)
+	assert.Equal(t, a, b.Parent)
+	assert.False(t, a.IsStub)
+	assert.True(t, c.Parent.IsStub)
+	assert.Equal(t, "not-downloaded", c.Parent.ID)
+	assert.Equal(t, c.Parent, d.Parent)
+
+	// Parent is not serialized
+	_, err = json.Marshal(root)
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	err = SavePage(&buf, &Page{ID: "root", Root: root})
+	assert.NoError(t, err)
+	page2, err := LoadPage(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", page2.Root.Content[0].Content[0].Parent.ID)
+}
@@ -0,0 +1,80 @@
+package notionapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// /api/v3/getSignedFileUrls request
+type getSignedFileURLsRequest struct {
+	URLs []signedURLRequest `json:"urls"`
+}
+
+type signedURLRequest struct {
+	URL              string           `json:"url"`
+	PermissionRecord permissionRecord `json:"permissionRecord"`
+}
+
+// permissionRecord tells the server which record's permissions
+// to check
+type permissionRecord struct {
+	Table string `json:"table"`
+	ID    string `json:"id"`
+}
+
+// GetSignedFileURLsResponse is a response to /api/v3/getSignedFileUrls api
+type GetSignedFileURLsResponse struct {
+	SignedURLs []string `json:"signedUrls"`
+}
+
+// GetSignedURLs returns signed urls for files uploaded to Notion
+// (they're in secure.notion-static.com) which can be downloaded without
+// authentication. blockIDs[i] is id of the block that owns urls[i]
+func (c *Client) GetSignedURLs(urls []string, blockIDs []string) ([]string, error) {
+	return c.GetSignedURLsCtx(context.Background(), urls, blockIDs)
+}
+
+// GetSignedURLsCtx is like GetSignedURLs but can be canceled with ctx
+func (c *Client) GetSignedURLsCtx(ctx context.Context, urls []string, blockIDs []string) ([]string, error) {
+	if len(urls) != len(blockIDs) {
+		return nil, fmt.Errorf("got %d urls and %d block ids, must be the same", len(urls), len(blockIDs))
+	}
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	req := &getSignedFileURLsRequest{}
+	for i, uri := range urls {
+		v := signedURLRequest{
+			URL: uri,
+			PermissionRecord: permissionRecord{
+				Table: TableBlock,
+				ID:    blockIDs[i],
+			},
+		}
+		req.URLs = append(req.URLs, v)
+	}
+	apiURL := "/api/v3/getSignedFileUrls"
+	var rsp GetSignedFileURLsResponse
+	err := doNotionAPI(ctx, c, apiURL, req, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	if len(rsp.SignedURLs) != len(urls) {
+		return nil, fmt.Errorf("asked for %d signed urls, got %d", len(urls), len(rsp.SignedURLs))
+	}
+	return rsp.SignedURLs, nil
+}
+
+// GetSignedSource returns url from which Source of BlockFile, BlockImage
+// etc. can be downloaded. For files uploaded to Notion it's a signed url,
+// other urls are returned unchanged
+func (b *Block) GetSignedSource(client *Client) (string, error) {
+	if !isNotionAttachment(b.Source) {
+		return b.Source, nil
+	}
+	urls, err := client.GetSignedURLs([]string{b.Source}, []string{b.ID})
+	if err != nil {
+		return "", err
+	}
+	return urls[0], nil
+}
@@ -0,0 +1,99 @@
+package notionapi
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSignedURLs(t *testing.T) {
+	c, transport := newStaticClient(200, `{"signedUrls":["https://s3.example.com/doc.pdf?X-Amz-Signature=abc"]}`)
+	block := &Block{
+		ID:     "e802296a-b0dc-41a8-8aa3-cf4212c3da0b",
+		Type:   BlockFile,
+		Source: "https://s3-us-west-2.amazonaws.com/secure.notion-static.com/8b3930e3/doc.pdf",
+	}
+	uri, err := block.GetSignedSource(c)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://s3.example.com/doc.pdf?X-Amz-Signature=abc", uri)
+	exp := `{"urls":[{"url":"https://s3-us-west-2.amazonaws.com/secure.notion-static.com/8b3930e3/doc.pdf","permissionRecord":{"table":"block","id":"e802296a-b0dc-41a8-8aa3-cf4212c3da0b"}}]}`
+	assert.Equal(t, []string{exp}, transport.requests)
+
+	// public urls don't need signing
+	block.Source = "https://example.com/doc.pdf"
+	uri, err = block.GetSignedSource(c)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/doc.pdf", uri)
+	assert.Equal(t, 1, len(transport.requests))
+
+	_, err = c.GetSignedURLs([]string{"a", "b"}, []string{"id"})
+	assert.Error(t, err)
+}
+
+func TestMakeImageURLAttachment(t *testing.T) {
+	uri := "https://s3-us-west-2.amazonaws.com/secure.notion-static.com/8b3930e3/image.png"
+	assert.Equal(t, uri, makeImageURL(uri))
+	assert.Equal(t, "https://www.notion.so/image/https:%2F%2Fwww.notion.so%2Fimages%2Fpage-cover%2Fwoodcuts_1.jpg", makeImageURL("/images/page-cover/woodcuts_1.jpg"))
+}
+
+func TestMakeImageURL(t *testing.T) {
+	const attachment = "https://s3-us-west-2.amazonaws.com/secure.notion-static.com/8b3930e3/image.png"
+	tests := []struct {
+		uri  string
+		opts *ImageURLOptions
+		exp  string
+	}{
+		{"", nil, ""},
+		// already proxied
+		{"https://www.notion.so/image/https%3A%2F%2Fexample.com%2Fa.png", nil, "https://www.notion.so/image/https%3A%2F%2Fexample.com%2Fa.png"},
+		{"https://www.notion.so/image/https%3A%2F%2Fexample.com%2Fa.png?table=block", &ImageURLOptions{Width: 800}, "https://www.notion.so/image/https%3A%2F%2Fexample.com%2Fa.png?table=block&width=800"},
+		// relative cover
+		{"/images/page-cover/woodcuts_1.jpg", nil, "https://www.notion.so/image/https:%2F%2Fwww.notion.so%2Fimages%2Fpage-cover%2Fwoodcuts_1.jpg"},
+		{"/images/page-cover/woodcuts_1.jpg", &ImageURLOptions{Width: 1200}, "https://www.notion.so/image/https:%2F%2Fwww.notion.so%2Fimages%2Fpage-cover%2Fwoodcuts_1.jpg?width=1200"},
+		// attachments are only proxied with id of the block
+		{attachment, nil, attachment},
+		{attachment, &ImageURLOptions{BlockID: "0367c2db-381a-4f8b-9ce3-60f388a6b2e3"}, "https://www.notion.so/image/https:%2F%2Fs3-us-west-2.amazonaws.com%2Fsecure.notion-static.com%2F8b3930e3%2Fimage.png?id=0367c2db-381a-4f8b-9ce3-60f388a6b2e3&table=block"},
+		{attachment, &ImageURLOptions{BlockID: "0367c2db-381a-4f8b-9ce3-60f388a6b2e3", SpaceID: "bc202e06-6caa-4e3f-81eb-f226ab5deef7", Width: 400}, "https://www.notion.so/image/https:%2F%2Fs3-us-west-2.amazonaws.com%2Fsecure.notion-static.com%2F8b3930e3%2Fimage.png?id=0367c2db-381a-4f8b-9ce3-60f388a6b2e3&spaceId=bc202e06-6caa-4e3f-81eb-f226ab5deef7&table=block&width=400"},
+		// pre-encoded urls are not encoded twice
+		{"https://example.com/my%20image.png", nil, "https://www.notion.so/image/https:%2F%2Fexample.com%2Fmy%20image.png"},
+		{"https://example.com/my image.png", nil, "https://www.notion.so/image/https:%2F%2Fexample.com%2Fmy%20image.png"},
+		// not a valid escape, used as is
+		{"https://example.com/100%.png", nil, "https://www.notion.so/image/https:%2F%2Fexample.com%2F100%25.png"},
+	}
+	for _, test := range tests {
+		got := MakeImageURL(test.uri, test.opts)
+		assert.Equal(t, test.exp, got, "uri: %s", test.uri)
+	}
+}
+
+func TestMakeImageURLShared(t *testing.T) {
+	const source = "https://images.unsplash.com/photo-1?ixlib=rb-1.2.1&q=80"
+	a := &Block{Type: BlockImage, Properties: map[string]interface{}{"source": []interface{}{[]interface{}{source}}}}
+	b := &Block{Type: BlockImage, Properties: map[string]interface{}{"source": []interface{}{[]interface{}{source}}}}
+	assert.NoError(t, parseProperties(a))
+	assert.NoError(t, parseProperties(b))
+	assert.Equal(t, MakeImageURL(source, nil), a.ImageURL)
+	// blocks with the same image share the url
+	assert.True(t, unsafe.StringData(a.ImageURL) == unsafe.StringData(b.ImageURL))
+
+	DefaultImageWidth = 640
+	defer func() { DefaultImageWidth = 0 }()
+	assert.Equal(t, MakeImageURL(source, &ImageURLOptions{Width: 640}), makeImageURL(source))
+}
+
+func BenchmarkMakeImageURL(b *testing.B) {
+	const source = "https://images.unsplash.com/photo-1?ixlib=rb-1.2.1&q=80&fm=jpg&crop=entropy&cs=tinysrgb"
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			MakeImageURL(source, nil)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			makeImageURL(source)
+		}
+	})
+}
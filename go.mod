@@ -1,5 +1,7 @@
 module github.com/kjk/notionapi
 
+go 1.20
+
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
@@ -0,0 +1,73 @@
+package notionapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+)
+
+const (
+	// request bodies smaller than that are not worth compressing
+	gzipRequestThreshold = 32 * 1024
+)
+
+// gzipBytes returns d compressed with gzip
+func gzipBytes(d []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(d)
+	if err != nil {
+		return nil, err
+	}
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gzipRequestBody compresses the body of api request if Client.GzipRequests
+// is set and it's large enough. Returns the body to send and value of
+// Content-Encoding header
+func (c *Client) gzipRequestBody(js []byte) ([]byte, string) {
+	if !c.GzipRequests || len(js) < gzipRequestThreshold {
+		return js, ""
+	}
+	d, err := gzipBytes(js)
+	if err != nil || len(d) >= len(js) {
+		return js, ""
+	}
+	return d, "gzip"
+}
+
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (r *gzipReadCloser) Close() error {
+	r.Reader.Close()
+	return r.body.Close()
+}
+
+// ungzipResponse replaces gzip-compressed body of rsp with uncompressed
+// content. http.Transport only does it if it sent Accept-Encoding header
+// itself, which is not the case if we send it or if the client uses
+// a custom http.RoundTripper
+func ungzipResponse(rsp *http.Response) error {
+	if rsp.Header.Get("Content-Encoding") != "gzip" {
+		return nil
+	}
+	r, err := gzip.NewReader(rsp.Body)
+	if err != nil {
+		rsp.Body.Close()
+		return err
+	}
+	rsp.Body = &gzipReadCloser{Reader: r, body: rsp.Body}
+	rsp.Header.Del("Content-Encoding")
+	rsp.Header.Del("Content-Length")
+	rsp.ContentLength = -1
+	rsp.Uncompressed = true
+	return nil
+}
@@ -0,0 +1,126 @@
+package notionapi
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gzipTransport returns gzip-compressed canned response and records
+// requests
+type gzipTransport struct {
+	body            []byte
+	acceptEncoding  string
+	contentEncoding string
+	requestBody     string
+}
+
+func (t *gzipTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.acceptEncoding = r.Header.Get("Accept-Encoding")
+	t.contentEncoding = r.Header.Get("Content-Encoding")
+	t.requestBody, _ = readRequestBody(r)
+	d, _ := gzipBytes(t.body)
+	header := http.Header{}
+	header.Set("Content-Encoding", "gzip")
+	return &http.Response{
+		StatusCode: 200,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewReader(d)),
+		Request:    r,
+	}, nil
+}
+
+// largePageJSON returns loadPageChunk response with n text blocks
+func largePageJSON(n int) []byte {
+	blocks := map[string]interface{}{}
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("0d6b5fcc-3f80-4b8c-8b76-%012d", i)
+		blocks[id] = map[string]interface{}{
+			"role": RoleReader,
+			"value": map[string]interface{}{
+				"alive":            true,
+				"id":               id,
+				"type":             BlockText,
+				"parent_id":        "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+				"parent_table":     TableBlock,
+				"created_by":       "bb760e2d-d679-4b64-b2a9-03005b21870a",
+				"last_edited_by":   "bb760e2d-d679-4b64-b2a9-03005b21870a",
+				"created_time":     1531024380041,
+				"last_edited_time": 1531024387094,
+				"version":          12,
+				"properties": map[string]interface{}{
+					"title": [][]string{{fmt.Sprintf("This is paragraph number %d of a large page", i)}},
+				},
+			},
+		}
+	}
+	d, _ := json.Marshal(map[string]interface{}{
+		"recordMap": map[string]interface{}{"block": blocks},
+		"cursor":    map[string]interface{}{"stack": []interface{}{}},
+	})
+	return d
+}
+
+func TestGzipResponse(t *testing.T) {
+	transport := &gzipTransport{body: largePageJSON(10)}
+	c := &Client{
+		HTTPClient: &http.Client{Transport: transport},
+	}
+	rsp, err := c.LoadPageChunk("4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", 0, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, len(rsp.RecordMap.Blocks))
+	assert.Equal(t, "gzip", transport.acceptEncoding)
+	// small requests are not compressed
+	assert.Equal(t, "", transport.contentEncoding)
+}
+
+func TestGzipRequest(t *testing.T) {
+	var ops []*Operation
+	for i := 0; i < 1000; i++ {
+		ops = append(ops, buildSetTitleOp("4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", "title"))
+	}
+	transport := &gzipTransport{body: []byte(`{}`)}
+	c := &Client{
+		HTTPClient: &http.Client{Transport: transport},
+	}
+	err := c.SubmitTransaction(ops)
+	assert.NoError(t, err)
+	assert.Equal(t, "", transport.contentEncoding)
+
+	c.GzipRequests = true
+	err = c.SubmitTransaction(ops)
+	assert.NoError(t, err)
+	assert.Equal(t, "gzip", transport.contentEncoding)
+	var req submitTransactionRequest
+	err = json.Unmarshal([]byte(transport.requestBody), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, len(req.Operations))
+}
+
+func BenchmarkLargePageResponse(b *testing.B) {
+	d := largePageJSON(5000)
+	compressed, _ := gzipBytes(d)
+	b.Run("plain", func(b *testing.B) {
+		b.SetBytes(int64(len(d)))
+		for i := 0; i < b.N; i++ {
+			var rsp LoadPageChunkResponse
+			json.Unmarshal(d, &rsp)
+		}
+		b.ReportMetric(float64(len(d)), "wire-bytes")
+	})
+	b.Run("gzip", func(b *testing.B) {
+		b.SetBytes(int64(len(d)))
+		for i := 0; i < b.N; i++ {
+			r, _ := gzip.NewReader(bytes.NewReader(compressed))
+			var rsp LoadPageChunkResponse
+			json.NewDecoder(r).Decode(&rsp)
+		}
+		b.ReportMetric(float64(len(compressed)), "wire-bytes")
+	})
+}
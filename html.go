@@ -0,0 +1,653 @@
+package notionapi
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// HTMLOptions allows customizing ToHTML
+type HTMLOptions struct {
+	// RenderBlockOverride, if set, is called before rendering every block.
+	// If it returns true, the block was rendered by the callback and
+	// we skip default rendering
+	RenderBlockOverride func(w io.Writer, block *Block) bool
+	// Renderers are consulted in order, after RenderBlockOverride, before
+	// default rendering of a block
+	Renderers []BlockRenderer
+	// RenderUnknownBlock, if set, renders blocks we don't know how to render,
+	// like blocks of types added by Notion after this library was written
+	// (see Block.IsUnknownType). By default we write an html comment
+	RenderUnknownBlock func(w io.Writer, block *Block)
+	// Math sets delimiters of equations. Default is MathDelimitersLaTeX
+	Math *MathDelimiters
+	// RewriteImageURL, if set, returns url of the image to use in html
+	// e.g. to point to a local copy of the image
+	RewriteImageURL func(block *Block, uri string) string
+	// RewriteFileURL, if set, returns url of the file attachment to use
+	RewriteFileURL func(block *Block, uri string) string
+	// PageURL, if set, returns url used for links to a page with a given id.
+	// By default we link to the page on notion.so
+	PageURL func(pageID string) string
+	// if true, links to sub-pages and linked pages are not included
+	SkipSubPages bool
+	// CodeLanguages overrides or extends mapping of Notion code block
+	// languages to highlighter names (see CodeLanguageToHighlightName)
+	CodeLanguages map[string]string
+	// if true, we only generate html for the content of the page, without
+	// <html>, <head> and <body>
+	Fragment bool
+	// if true, generated html has the same structure and class names as
+	// html generated by Notion's "Export as HTML"
+	NotionCompatible bool
+}
+
+func escapeHTML(s string) string {
+	return html.EscapeString(s)
+}
+
+// InlineToHTML converts inline blocks to html. Mentions of pages are
+// links to notion.so
+func InlineToHTML(blocks []*InlineBlock) string {
+	return inlineToHTML(resolvePageMentions(blocks, nil, notionPageURL), nil)
+}
+
+func inlineToHTML(blocks []*InlineBlock, math *MathDelimiters) string {
+	var buf bytes.Buffer
+	for _, b := range blocks {
+		buf.WriteString(inlineBlockToHTML(b, math))
+	}
+	return buf.String()
+}
+
+func inlineBlockToHTML(b *InlineBlock, math *MathDelimiters) string {
+	var s string
+	switch {
+	case b.Equation != "":
+		s = inlineEquationToHTML(b.Equation, math)
+	case b.UserID != "":
+		s = fmt.Sprintf(`<span class="user">@%s</span>`, escapeHTML(b.UserID))
+	case b.Date != nil:
+		s = fmt.Sprintf(`<span class="date">%s</span>`, escapeHTML(dateToString(b.Date)))
+	default:
+		s = escapeHTML(b.Text)
+	}
+	if b.AttrFlags&AttrCode != 0 {
+		s = "<code>" + s + "</code>"
+	}
+	if b.AttrFlags&AttrStrikeThrought != 0 {
+		s = "<del>" + s + "</del>"
+	}
+	if b.AttrFlags&AttrItalic != 0 {
+		s = "<em>" + s + "</em>"
+	}
+	if b.AttrFlags&AttrBold != 0 {
+		s = "<strong>" + s + "</strong>"
+	}
+	if b.Highlight != "" {
+		s = fmt.Sprintf(`<mark class="highlight-%s">%s</mark>`, escapeHTML(b.Highlight), s)
+	}
+	if b.Link != "" {
+		s = fmt.Sprintf(`<a href="%s">%s</a>`, escapeHTML(b.Link), s)
+	}
+	return s
+}
+
+// colorClass returns class attribute for a block with Block.BlockColor
+func colorClass(block *Block) string {
+	color := block.BlockColor()
+	if color == "" {
+		return ""
+	}
+	return fmt.Sprintf(` class="block-color-%s"`, escapeHTML(color))
+}
+
+type htmlConverter struct {
+	page *Page
+	opts *HTMLOptions
+	buf  *bytes.Buffer
+	// maps normalized block id to blocks of the page, for links to blocks
+	blocks map[string]*Block
+}
+
+// ToHTML converts a page to html
+func ToHTML(p *Page, opts *HTMLOptions) []byte {
+	if opts == nil {
+		opts = &HTMLOptions{}
+	}
+	c := &htmlConverter{
+		page: p,
+		opts: opts,
+		buf:  &bytes.Buffer{},
+	}
+	return c.convert()
+}
+
+func (c *htmlConverter) convert() []byte {
+	if c.opts.NotionCompatible {
+		return c.convertNotion()
+	}
+	root := c.page.Root
+	title := escapeHTML(root.Title)
+	if !c.opts.Fragment {
+		c.printf(`<!doctype html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+</head>
+<body>
+`, title)
+	}
+	renderPageStart(c.opts.Renderers, c.buf, c.page)
+	c.printf("<article>\n")
+	if title != "" {
+		c.printf("<h1 class=\"page-title\">%s</h1>\n", title)
+	}
+	c.renderBlocks(root.Content)
+	c.printf("</article>\n")
+	renderPageEnd(c.opts.Renderers, c.buf, c.page)
+	if !c.opts.Fragment {
+		c.printf("</body>\n</html>\n")
+	}
+	return c.buf.Bytes()
+}
+
+func (c *htmlConverter) inline(blocks []*InlineBlock) string {
+	blocks = resolvePageMentions(blocks, c.page.mentionedPageTitle, c.pageURL)
+	return inlineToHTML(c.rewriteLinks(blocks), c.opts.Math)
+}
+
+func (c *htmlConverter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(c.buf, format, args...)
+}
+
+func (c *htmlConverter) pageURL(pageID string) string {
+	if c.opts.PageURL != nil {
+		return c.opts.PageURL(pageID)
+	}
+	return "https://www.notion.so/" + strings.Replace(pageID, "-", "", -1)
+}
+
+func (c *htmlConverter) imageURL(block *Block, uri string) string {
+	if c.opts.RewriteImageURL != nil {
+		return c.opts.RewriteImageURL(block, uri)
+	}
+	return uri
+}
+
+func (c *htmlConverter) fileURL(block *Block, uri string) string {
+	if c.opts.RewriteFileURL != nil {
+		return c.opts.RewriteFileURL(block, uri)
+	}
+	return uri
+}
+
+// returns "ul" or "ol" if block is an item of a list
+func htmlListTag(b *Block) string {
+	switch b.Type {
+	case BlockBulletedList, BlockTodo:
+		return "ul"
+	case BlockNumberedList:
+		return "ol"
+	}
+	return ""
+}
+
+// renderBlocks renders blocks, wrapping consecutive list items in <ul> or <ol>
+func (c *htmlConverter) renderBlocks(blocks []*Block) {
+	currList := ""
+	for _, block := range blocks {
+		if block == nil {
+			continue
+		}
+		tag := htmlListTag(block)
+		if tag != currList {
+			if currList != "" {
+				c.printf("</%s>\n", currList)
+			}
+			if tag == "ol" && block.ListIndex > 1 {
+				c.printf("<ol start=\"%d\">\n", block.ListIndex)
+			} else if tag != "" {
+				c.printf("<%s>\n", tag)
+			}
+			currList = tag
+		}
+		c.renderBlock(block)
+	}
+	if currList != "" {
+		c.printf("</%s>\n", currList)
+	}
+}
+
+func (c *htmlConverter) renderInline(tag string, block *Block) {
+	c.printf("<%s%s>%s</%s>\n", tag, colorClass(block), c.inline(block.InlineContent), tag)
+}
+
+// toggleable headers are rendered like toggles, with header as a summary
+func (c *htmlConverter) renderHeader(tag string, block *Block) {
+	anchor := escapeHTML(HeaderAnchor(block))
+	if !block.IsToggleable() {
+		c.printf("<%s id=\"%s\"%s>%s</%s>\n", tag, anchor, colorClass(block), c.inline(block.InlineContent), tag)
+		return
+	}
+	c.printf("<details>\n<summary><%s id=\"%s\"%s>%s</%s></summary>\n", tag, anchor, colorClass(block), c.inline(block.InlineContent), tag)
+	c.renderBlocks(block.Content)
+	c.printf("</details>\n")
+}
+
+func (c *htmlConverter) renderListItem(block *Block, prefix string) {
+	c.printf("<li%s>%s%s", colorClass(block), prefix, c.inline(block.InlineContent))
+	if len(block.Content) > 0 {
+		c.printf("\n")
+		c.renderBlocks(block.Content)
+	}
+	c.printf("</li>\n")
+}
+
+func (c *htmlConverter) renderTable(table *Table) {
+	cols := table.VisibleColumns()
+	c.printf("<table class=\"collection\">\n")
+	if table.Collection != nil {
+		if name := table.Collection.Name(); name != "" {
+			c.printf("<caption>%s</caption>\n", escapeHTML(name))
+		}
+	}
+	c.printf("<thead>\n<tr>")
+	for _, col := range cols {
+		c.printf("<th>%s</th>", escapeHTML(col.Name))
+	}
+	c.printf("</tr>\n</thead>\n<tbody>\n")
+	for _, row := range table.Rows() {
+		c.printf("<tr>")
+		for _, col := range cols {
+			c.printf("<td>%s</td>", escapeHTML(row.ValueString(col)))
+		}
+		c.printf("</tr>\n")
+	}
+	c.printf("</tbody>\n</table>\n")
+}
+
+// renderSimpleTable renders BlockTable (as opposed to a database)
+func (c *htmlConverter) renderSimpleTable(block *Block) {
+	f := block.FormatTableBlock
+	colHeader := f != nil && f.TableBlockColumnHeader
+	rowHeader := f != nil && f.TableBlockRowHeader
+	c.printf("<table class=\"simple-table\">\n")
+	for i, row := range block.TableCells() {
+		if i == 0 && colHeader {
+			c.printf("<thead>\n")
+		}
+		c.printf("<tr>")
+		for j, cell := range row {
+			tag := "td"
+			if (i == 0 && colHeader) || (j == 0 && rowHeader) {
+				tag = "th"
+			}
+			c.printf("<%s>%s</%s>", tag, c.inline(cell), tag)
+		}
+		c.printf("</tr>\n")
+		if i == 0 && colHeader {
+			c.printf("</thead>\n")
+		}
+	}
+	c.printf("</table>\n")
+}
+
+// columnWidth returns width of a column in percent, see
+// Block.EffectiveRatio
+func columnWidth(col *Block, nColumns int) float64 {
+	if col.EffectiveRatio > 0 {
+		return col.EffectiveRatio * 100
+	}
+	// page wasn't created by resolveBlocks
+	return columnRatio(col, nColumns) * 100
+}
+
+func (c *htmlConverter) renderColumnList(block *Block) {
+	n := countColumns(block)
+	c.printf("<div class=\"column-list\" style=\"display:flex\">\n")
+	for _, col := range block.Content {
+		if col == nil {
+			continue
+		}
+		if col.Type != BlockColumn {
+			c.renderBlock(col)
+			continue
+		}
+		c.printf("<div class=\"column\" style=\"width:%.4g%%\">\n", columnWidth(col, n))
+		c.renderBlocks(col.Content)
+		c.printf("</div>\n")
+	}
+	c.printf("</div>\n")
+}
+
+// renderBookmark renders a bookmark as a card with title, description,
+// favicon and cover image of the bookmarked page
+func (c *htmlConverter) renderBookmark(block *Block) {
+	uri := escapeHTML(block.BookmarkURL())
+	if !block.hasBookmarkMetadata() {
+		c.printf("<p class=\"bookmark\"><a href=\"%s\">%s</a></p>\n", uri, uri)
+		return
+	}
+	title := c.inline(block.InlineContent)
+	if title == "" {
+		title = uri
+	}
+	c.printf("<a class=\"bookmark\" href=\"%s\">\n<div class=\"bookmark-info\">\n", uri)
+	c.printf("<div class=\"bookmark-title\">%s</div>\n", title)
+	if block.Description != "" {
+		c.printf("<div class=\"bookmark-description\">%s</div>\n", escapeHTML(block.Description))
+	}
+	c.printf("<div class=\"bookmark-href\">")
+	f := block.FormatBookmark
+	if f != nil && f.BookmarkIcon != "" {
+		c.printf("<img class=\"bookmark-icon\" src=\"%s\">", escapeHTML(f.BookmarkIcon))
+	}
+	c.printf("%s</div>\n</div>\n", uri)
+	if f != nil && f.BookmarkCoverURL != "" {
+		c.printf("<img class=\"bookmark-cover\" src=\"%s\">\n", escapeHTML(c.imageURL(block, f.BookmarkCoverURL)))
+	}
+	c.printf("</a>\n")
+}
+
+// renderPDF renders BlockPDF as an embedded viewer with a link to the file
+// for browsers that can't show it
+func (c *htmlConverter) renderPDF(block *Block) {
+	uri := escapeHTML(c.fileURL(block, block.Source))
+	height := ""
+	if f := block.FormatPDF; f != nil && f.BlockHeight > 0 {
+		height = fmt.Sprintf(" height=\"%d\"", int64(f.BlockHeight))
+	}
+	c.printf("<figure class=\"pdf\">\n<embed src=\"%s\" type=\"application/pdf\"%s>\n", uri, height)
+	c.printf("<figcaption><a href=\"%s\">%s</a></figcaption>\n</figure>\n", uri, escapeHTML(block.fileName()))
+}
+
+// renderDrive renders BlockDrive as a card with title, icon and thumbnail
+// of the file, like a bookmark
+func (c *htmlConverter) renderDrive(block *Block) {
+	title, uri := block.driveTitleAndURL()
+	uri = escapeHTML(uri)
+	c.printf("<a class=\"drive\" href=\"%s\">\n<div class=\"drive-info\">\n<div class=\"drive-title\">", uri)
+	p := block.driveProperties()
+	if p != nil && p.Icon != "" {
+		c.printf("<img class=\"drive-icon\" src=\"%s\">", escapeHTML(p.Icon))
+	}
+	c.printf("%s</div>\n", escapeHTML(title))
+	if p != nil && p.UserName != "" {
+		c.printf("<div class=\"drive-user\">%s</div>\n", escapeHTML(p.UserName))
+	}
+	c.printf("</div>\n")
+	if p != nil && p.ThumbnailURL != "" {
+		c.printf("<img class=\"drive-thumbnail\" src=\"%s\">\n", escapeHTML(c.imageURL(block, p.ThumbnailURL)))
+	}
+	c.printf("</a>\n")
+}
+
+// gistScriptURL returns url of the script that embeds a gist e.g.
+// https://gist.github.com/kjk/abc.js for https://gist.github.com/kjk/abc
+func gistScriptURL(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	u.RawQuery = ""
+	u.Fragment = ""
+	u.Path = strings.TrimSuffix(u.Path, "/") + ".js"
+	return u.String()
+}
+
+// renderGist renders BlockGist as the standard script embed, with a link
+// for when scripts are disabled
+func (c *htmlConverter) renderGist(block *Block) {
+	uri := block.GistURL()
+	src := escapeHTML(uri)
+	if script := gistScriptURL(uri); script != "" {
+		c.printf("<script src=\"%s\"></script>\n", escapeHTML(script))
+	}
+	c.printf("<noscript><p class=\"gist\"><a href=\"%s\">%s</a></p></noscript>\n", src, src)
+}
+
+// figmaEmbedURL returns url of Figma's viewer of a design at uri
+func figmaEmbedURL(uri string) string {
+	return "https://www.figma.com/embed?embed_host=notion&url=" + url.QueryEscape(uri)
+}
+
+// codepenEmbedURL returns url of Codepen's embed of a pen at uri e.g.
+// https://codepen.io/kjk/embed/abc for https://codepen.io/kjk/pen/abc
+func codepenEmbedURL(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return uri
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 3 || parts[1] != "pen" {
+		return uri
+	}
+	parts[1] = "embed"
+	u.Path = "/" + strings.Join(parts, "/")
+	u.RawQuery = "default-tab=result"
+	return u.String()
+}
+
+// renderIframe renders an embed like BlockFigma as an iframe showing src,
+// with the height set in the block
+func (c *htmlConverter) renderIframe(block *Block, src string) {
+	height := ""
+	if f := block.FormatEmbed; f != nil && f.BlockHeight > 0 {
+		height = fmt.Sprintf(" height=\"%d\"", int64(f.BlockHeight))
+	}
+	c.printf("<figure class=\"%s\">\n<iframe src=\"%s\"%s allowfullscreen></iframe>\n</figure>\n", block.Type, escapeHTML(src), height)
+}
+
+// videoSize returns width and height attributes for BlockVideo
+func videoSize(block *Block) string {
+	f := block.FormatVideo
+	s := ""
+	if f.BlockWidth > 0 {
+		s += fmt.Sprintf(" width=\"%d\"", int64(f.BlockWidth))
+	}
+	if f.BlockHeight > 0 {
+		s += fmt.Sprintf(" height=\"%d\"", int64(f.BlockHeight))
+	}
+	return s
+}
+
+// videoTag returns <video> for uploaded BlockVideo and an iframe for
+// videos from youtube etc. Returns "" if we don't know the url
+func (c *htmlConverter) videoTag(block *Block) string {
+	f := block.FormatVideo
+	if f == nil || f.VideoURL == "" {
+		return ""
+	}
+	if f.IsUpload {
+		src := escapeHTML(c.fileURL(block, f.VideoURL))
+		return fmt.Sprintf("<video controls src=\"%s\"%s></video>", src, videoSize(block))
+	}
+	return fmt.Sprintf("<iframe src=\"%s\"%s allowfullscreen></iframe>", escapeHTML(f.VideoURL), videoSize(block))
+}
+
+func (c *htmlConverter) renderVideo(block *Block) {
+	tag := c.videoTag(block)
+	if tag == "" {
+		src := escapeHTML(block.Source)
+		c.printf("<p class=\"video\"><a href=\"%s\">%s</a></p>\n", src, src)
+		return
+	}
+	c.printf("<figure class=\"video\">\n%s\n</figure>\n", tag)
+}
+
+// renderBreadcrumb renders Page.Ancestors and the page, with links to pages
+func (c *htmlConverter) renderBreadcrumb() {
+	var parts []string
+	for _, a := range c.page.Ancestors {
+		title := escapeHTML(ancestorTitle(a))
+		if a.Table == TableBlock {
+			title = fmt.Sprintf("<a href=\"%s\">%s</a>", escapeHTML(c.pageURL(a.ID)), title)
+		}
+		parts = append(parts, title)
+	}
+	title := c.page.Root.Title
+	if title == "" {
+		title = "Untitled"
+	}
+	parts = append(parts, escapeHTML(title))
+	c.printf("<nav class=\"breadcrumb\">%s</nav>\n", strings.Join(parts, " / "))
+}
+
+func (c *htmlConverter) renderUnsupported(block *Block) {
+	if c.opts.RenderUnknownBlock != nil {
+		c.opts.RenderUnknownBlock(c.buf, block)
+		return
+	}
+	c.printf("<!-- unsupported block type '%s', id: %s -->\n", escapeHTML(block.Type), block.ID)
+}
+
+// renderOverride returns true if the block was rendered by
+// RenderBlockOverride or one of the Renderers
+func (c *htmlConverter) renderOverride(block *Block) bool {
+	if c.opts.RenderBlockOverride != nil && c.opts.RenderBlockOverride(c.buf, block) {
+		return true
+	}
+	return renderWithRenderers(c.opts.Renderers, c.buf, block)
+}
+
+func (c *htmlConverter) renderBlock(block *Block) {
+	if c.renderOverride(block) {
+		return
+	}
+	switch block.Type {
+	case BlockText:
+		c.renderInline("p", block)
+		if len(block.Content) > 0 {
+			c.printf("<div class=\"indented\">\n")
+			c.renderBlocks(block.Content)
+			c.printf("</div>\n")
+		}
+	case BlockHeader:
+		c.renderHeader("h1", block)
+	case BlockSubHeader:
+		c.renderHeader("h2", block)
+	case BlockSubSubHeader:
+		c.renderHeader("h3", block)
+	case BlockBulletedList, BlockNumberedList:
+		c.renderListItem(block, "")
+	case BlockTodo:
+		checked := ""
+		if block.IsChecked {
+			checked = " checked"
+		}
+		c.renderListItem(block, fmt.Sprintf(`<input type="checkbox" disabled%s> `, checked))
+	case BlockToggle:
+		c.printf("<details%s>\n<summary>%s</summary>\n", colorClass(block), c.inline(block.InlineContent))
+		c.renderBlocks(block.Content)
+		c.printf("</details>\n")
+	case BlockQuote:
+		if len(block.Content) == 0 {
+			c.renderInline("blockquote", block)
+			return
+		}
+		c.printf("<blockquote%s>%s\n", colorClass(block), c.inline(block.InlineContent))
+		c.renderBlocks(block.Content)
+		c.printf("</blockquote>\n")
+	case BlockCallout:
+		c.printf("<div class=\"callout\">")
+		if icon := calloutIcon(block); icon != "" {
+			c.renderNotionIcon(icon)
+			c.printf(" ")
+		}
+		c.printf("%s\n", c.inline(block.InlineContent))
+		c.renderBlocks(block.Content)
+		c.printf("</div>\n")
+	case BlockCode:
+		lang := codeLanguageName(block.CodeLanguage, c.opts.CodeLanguages)
+		c.printf("<pre><code class=\"language-%s\">%s</code></pre>\n", escapeHTML(lang), escapeHTML(block.Code))
+	case BlockEquation:
+		// placeholder equation blocks are empty
+		if block.Equation != "" {
+			c.printf("%s\n", blockEquationToHTML(block.Equation, c.opts.Math))
+		}
+	case BlockDivider:
+		c.printf("<hr>\n")
+	case BlockImage:
+		uri := c.imageURL(block, block.ImageURL)
+		c.printf("<figure>\n<img src=\"%s\">\n</figure>\n", escapeHTML(uri))
+	case BlockBookmark:
+		c.renderBookmark(block)
+	case BlockFile:
+		uri := escapeHTML(c.fileURL(block, block.Source))
+		c.printf("<p class=\"file\"><a href=\"%s\">%s</a></p>\n", uri, escapeHTML(block.Source))
+	case BlockAudio:
+		uri := escapeHTML(c.fileURL(block, block.Source))
+		c.printf("<figure class=\"audio\">\n<audio controls src=\"%s\"></audio>\n</figure>\n", uri)
+	case BlockPDF:
+		c.renderPDF(block)
+	case BlockDrive:
+		c.renderDrive(block)
+	case BlockFigma:
+		c.renderIframe(block, figmaEmbedURL(block.Source))
+	case BlockCodepen:
+		c.renderIframe(block, codepenEmbedURL(block.Source))
+	case BlockTweet:
+		// hydrated by https://platform.twitter.com/widgets.js
+		src := escapeHTML(block.Source)
+		c.printf("<blockquote class=\"twitter-tweet\"><a href=\"%s\">%s</a></blockquote>\n", src, src)
+	case BlockGist:
+		c.renderGist(block)
+	case BlockVideo:
+		c.renderVideo(block)
+	case BlockEmbed:
+		src := escapeHTML(block.Source)
+		c.printf("<p class=\"%s\"><a href=\"%s\">%s</a></p>\n", block.Type, src, src)
+	case BlockPage:
+		if c.opts.SkipSubPages && block.AliasPageID == "" {
+			return
+		}
+		title := block.Title
+		if title == "" {
+			title = "Untitled"
+		}
+		c.printf("<p class=\"page-link\"><a href=\"%s\">%s</a></p>\n", escapeHTML(c.pageURL(block.ID)), escapeHTML(title))
+	case BlockAlias:
+		title := block.AliasTitle
+		if title == "" {
+			title = "Untitled"
+		}
+		icon := ""
+		if block.AliasIcon != "" && !isIconURL(block.AliasIcon) {
+			icon = escapeHTML(block.AliasIcon) + " "
+		}
+		c.printf("<p class=\"page-link\"><a href=\"%s\">%s%s</a></p>\n", escapeHTML(c.pageURL(block.AliasPageID)), icon, escapeHTML(title))
+	case BlockColumnList:
+		c.renderColumnList(block)
+	case BlockColumn:
+		// columns outside of column list
+		c.renderBlocks(block.Content)
+	case BlockTransclusionContainer, BlockTransclusionReference:
+		c.renderBlocks(block.Content)
+	case BlockTable:
+		c.renderSimpleTable(block)
+	case BlockCollectionView:
+		for _, info := range block.CollectionViews {
+			c.renderTable(tableFromCollectionViewInfo(info))
+		}
+	case BlockTableOfContents:
+		c.renderTableOfContents(block)
+	case BlockBreadcrumb:
+		c.renderBreadcrumb()
+	case BlockFactory:
+		// templates are not part of the content
+		c.printf("<p class=\"factory\"><button>%s</button></p>\n", c.inline(block.InlineContent))
+	case BlockComment:
+		// comments are not part of the content
+	default:
+		if block.IsEmbedBlock() {
+			c.renderIframe(block, block.embedURL())
+			return
+		}
+		c.renderUnsupported(block)
+	}
+}
@@ -0,0 +1,170 @@
+package notionapi
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"strings"
+)
+
+func isHeaderBlock(b *Block) bool {
+	switch b.Type {
+	case BlockHeader, BlockSubHeader, BlockSubSubHeader:
+		return true
+	}
+	return false
+}
+
+// HeaderAnchor returns id of a header block used in exported html. It's
+// a slug of header text followed by a short hash of block id so that it
+// doesn't change across exports and is unique even if headers have the
+// same text
+func HeaderAnchor(block *Block) string {
+	sha := sha1.Sum([]byte(block.ID))
+	hash := hex.EncodeToString(sha[:])[:6]
+	slug := Slugify(InlineToText(block.InlineContent))
+	if slug == "" {
+		return "h-" + hash
+	}
+	return slug + "-" + hash
+}
+
+// parseNotionBlockLink parses links to a block inside a page, like
+// https://www.notion.so/Title-c969c9455d7c4dd79c7f860f3ace6429#0367c2db381a4f8b9ce360f388a6b2e3
+// Page id is empty for links that only have a fragment
+func parseNotionBlockLink(uri string) (string, string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Fragment == "" {
+		return "", "", false
+	}
+	blockID, ok := NormalizeID(u.Fragment)
+	if !ok {
+		return "", "", false
+	}
+	if u.Host == "" && u.Path == "" {
+		return "", blockID, true
+	}
+	if u.Host != "" && u.Host != "www.notion.so" && u.Host != "notion.so" {
+		return "", "", false
+	}
+	last := path.Base(u.Path)
+	if len(last) < 32 {
+		return "", "", false
+	}
+	pageID, ok := NormalizeID(last[len(last)-32:])
+	if !ok {
+		return "", "", false
+	}
+	return pageID, blockID, true
+}
+
+// collectBlocksByID maps normalized ids of blocks of the page to blocks.
+// We don't descend into sub-pages because they're not part of the page
+func collectBlocksByID(blocks []*Block, res map[string]*Block) {
+	for _, b := range blocks {
+		if b == nil {
+			continue
+		}
+		if id, ok := NormalizeID(b.ID); ok {
+			res[id] = b
+		}
+		if b.Type != BlockPage {
+			collectBlocksByID(b.Content, res)
+		}
+	}
+}
+
+// findHeaders returns headers of the page in document order
+func findHeaders(blocks []*Block, res []*Block) []*Block {
+	for _, b := range blocks {
+		if b == nil || b.Type == BlockPage {
+			continue
+		}
+		if isHeaderBlock(b) {
+			res = append(res, b)
+		}
+		res = findHeaders(b.Content, res)
+	}
+	return res
+}
+
+// rewriteLink returns a link to an anchor for links to blocks in the
+// same page. Other links are returned unchanged
+func (c *htmlConverter) rewriteLink(uri string) string {
+	pageID, blockID, ok := parseNotionBlockLink(uri)
+	if !ok {
+		return uri
+	}
+	if rootID, _ := NormalizeID(c.page.Root.ID); pageID != "" && pageID != rootID {
+		return uri
+	}
+	if c.blocks == nil {
+		c.blocks = map[string]*Block{}
+		collectBlocksByID(c.page.Root.Content, c.blocks)
+	}
+	block := c.blocks[blockID]
+	if block == nil {
+		return uri
+	}
+	if c.opts.NotionCompatible {
+		// in this mode every block has its id as html id
+		return "#" + block.ID
+	}
+	if isHeaderBlock(block) {
+		return "#" + HeaderAnchor(block)
+	}
+	return uri
+}
+
+// rewriteLinks returns blocks with links to blocks in the same page
+// rewritten to anchors
+func (c *htmlConverter) rewriteLinks(blocks []*InlineBlock) []*InlineBlock {
+	var res []*InlineBlock
+	for i, b := range blocks {
+		if b.Link == "" || !strings.Contains(b.Link, "#") {
+			continue
+		}
+		link := c.rewriteLink(b.Link)
+		if link == b.Link {
+			continue
+		}
+		if res == nil {
+			res = append([]*InlineBlock{}, blocks...)
+		}
+		rewritten := *b
+		rewritten.Link = link
+		res[i] = &rewritten
+	}
+	if res == nil {
+		return blocks
+	}
+	return res
+}
+
+func tocIndent(b *Block) int {
+	switch b.Type {
+	case BlockSubHeader:
+		return 1
+	case BlockSubSubHeader:
+		return 2
+	}
+	return 0
+}
+
+// renderTableOfContents renders links to all headers of the page
+func (c *htmlConverter) renderTableOfContents(block *Block) {
+	class := "table_of_contents"
+	if f := block.FormatTableOfContents; f != nil && f.BlockColor != nil {
+		class += " block-color-" + *f.BlockColor
+	}
+	c.printf("<nav class=\"%s\">\n", escapeHTML(class))
+	for _, h := range findHeaders(c.page.Root.Content, nil) {
+		anchor := HeaderAnchor(h)
+		if c.opts.NotionCompatible {
+			anchor = h.ID
+		}
+		c.printf("<div class=\"table_of_contents-item table_of_contents-indent-%d\"><a href=\"#%s\">%s</a></div>\n", tocIndent(h), escapeHTML(anchor), escapeHTML(InlineToText(h.InlineContent)))
+	}
+	c.printf("</nav>\n")
+}
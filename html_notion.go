@@ -0,0 +1,284 @@
+package notionapi
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// This file generates html with the same structure as Notion's own
+// "Export as HTML" so that css and scripts written for those exports
+// work with our html.
+
+func (c *htmlConverter) convertNotion() []byte {
+	root := c.page.Root
+	title := escapeHTML(root.Title)
+	if !c.opts.Fragment {
+		c.printf(`<html><head><meta http-equiv="Content-Type" content="text/html; charset=utf-8"/><title>%s</title></head><body>`, title)
+	}
+	renderPageStart(c.opts.Renderers, c.buf, c.page)
+	c.printf(`<article id="%s" class="page sans">`, root.ID)
+	c.printf(`<header>`)
+	if f := root.FormatPage; f != nil {
+		if f.PageCoverURL != "" {
+			pos := (1 - f.PageCoverPosition) * 100
+			c.printf(`<img class="page-cover-image" src="%s" style="object-position:center %g%%"/>`, escapeHTML(f.PageCoverURL), pos)
+		}
+		if f.PageIcon != "" {
+			icon := f.PageIcon
+			if f.PageIconURL != "" {
+				icon = f.PageIconURL
+			}
+			cls := "undefined"
+			if f.PageCoverURL != "" {
+				cls = "page-header-icon-with-cover"
+			}
+			c.printf(`<div class="page-header-icon %s">`, cls)
+			c.renderNotionIcon(icon)
+			c.printf(`</div>`)
+		}
+	}
+	c.printf(`<h1 class="page-title">%s</h1></header>`, title)
+	c.printf(`<div class="page-body">`)
+	c.renderNotionBlocks(root.Content)
+	c.printf(`</div></article>`)
+	renderPageEnd(c.opts.Renderers, c.buf, c.page)
+	if !c.opts.Fragment {
+		c.printf(`</body></html>`)
+	}
+	return c.buf.Bytes()
+}
+
+// icon is either an emoji or url of an image
+// isIconURL returns true if icon (of a page or a callout) is an url of an
+// image and not an emoji
+func isIconURL(icon string) bool {
+	u, err := url.Parse(icon)
+	return err == nil && u.Scheme != ""
+}
+
+func (c *htmlConverter) renderNotionIcon(icon string) {
+	if isIconURL(icon) {
+		c.printf(`<img class="icon" src="%s"/>`, escapeHTML(icon))
+		return
+	}
+	c.printf(`<span class="icon">%s</span>`, escapeHTML(icon))
+}
+
+func (c *htmlConverter) renderNotionBlocks(blocks []*Block) {
+	for _, block := range blocks {
+		if block != nil {
+			c.renderNotionBlock(block)
+		}
+	}
+}
+
+// children of a block are rendered inside <div class="indented">
+func (c *htmlConverter) renderNotionChildren(block *Block) {
+	if len(block.Content) == 0 {
+		return
+	}
+	c.printf(`<div class="indented">`)
+	c.renderNotionBlocks(block.Content)
+	c.printf(`</div>`)
+}
+
+// Notion wraps every list item in its own list
+func (c *htmlConverter) renderNotionListItem(block *Block, tag string, cls string) {
+	if color := block.BlockColor(); color != "" {
+		cls += " block-color-" + escapeHTML(color)
+	}
+	c.printf(`<%s id="%s" class="%s"><li>%s`, tag, block.ID, cls, c.inline(block.InlineContent))
+	c.renderNotionChildren(block)
+	c.printf(`</li></%s>`, tag)
+}
+
+func (c *htmlConverter) renderNotionInline(tag string, block *Block) {
+	cls := ""
+	if color := block.BlockColor(); color != "" {
+		cls = "block-color-" + escapeHTML(color)
+	}
+	c.printf(`<%s id="%s" class="%s">%s</%s>`, tag, block.ID, cls, c.inline(block.InlineContent), tag)
+}
+
+func (c *htmlConverter) renderNotionHeader(tag string, block *Block) {
+	if !block.IsToggleable() {
+		c.renderNotionInline(tag, block)
+		return
+	}
+	c.printf(`<details open=""><summary>`)
+	c.renderNotionInline(tag, block)
+	c.printf(`</summary>`)
+	c.renderNotionChildren(block)
+	c.printf(`</details>`)
+}
+
+// calloutIcon returns the icon of a callout block
+func calloutIcon(block *Block) string {
+	if block.FormatCallout != nil {
+		return block.FormatCallout.PageIcon
+	}
+	icon, _ := block.FormatValue("page_icon")
+	s, _ := icon.(string)
+	return s
+}
+
+func (c *htmlConverter) renderNotionBlock(block *Block) {
+	if c.renderOverride(block) {
+		return
+	}
+	switch block.Type {
+	case BlockText:
+		c.renderNotionInline("p", block)
+		c.renderNotionChildren(block)
+	case BlockHeader:
+		c.renderNotionHeader("h1", block)
+	case BlockSubHeader:
+		c.renderNotionHeader("h2", block)
+	case BlockSubSubHeader:
+		c.renderNotionHeader("h3", block)
+	case BlockBulletedList:
+		c.renderNotionListItem(block, "ul", "bulleted-list")
+	case BlockNumberedList:
+		c.renderNotionListItem(block, "ol", "numbered-list")
+	case BlockTodo:
+		checkbox, children := "checkbox-off", "to-do-children-unchecked"
+		if block.IsChecked {
+			checkbox, children = "checkbox-on", "to-do-children-checked"
+		}
+		c.printf(`<ul id="%s" class="to-do-list"><li><div class="checkbox %s"></div> <span class="%s">%s</span>`, block.ID, checkbox, children, c.inline(block.InlineContent))
+		c.renderNotionChildren(block)
+		c.printf(`</li></ul>`)
+	case BlockToggle:
+		c.printf(`<ul id="%s" class="toggle"><li><details open=""><summary>%s</summary>`, block.ID, c.inline(block.InlineContent))
+		c.renderNotionBlocks(block.Content)
+		c.printf(`</details></li></ul>`)
+	case BlockQuote:
+		c.renderNotionInline("blockquote", block)
+	case BlockCallout:
+		cls := "callout"
+		if color := block.BlockColor(); color != "" {
+			cls = "block-color-" + escapeHTML(color) + " callout"
+		}
+		c.printf(`<figure class="%s" style="white-space:pre-wrap;display:flex" id="%s">`, cls, block.ID)
+		c.printf(`<div style="font-size:1.5em">`)
+		c.renderNotionIcon(calloutIcon(block))
+		c.printf(`</div><div style="width:100%%">%s</div></figure>`, c.inline(block.InlineContent))
+	case BlockCode:
+		lang := codeLanguageName(block.CodeLanguage, c.opts.CodeLanguages)
+		c.printf(`<pre id="%s" class="code"><code class="language-%s">%s</code></pre>`, block.ID, escapeHTML(lang), escapeHTML(block.Code))
+	case BlockEquation:
+		if block.Equation != "" {
+			c.printf(`<figure id="%s" class="equation">%s</figure>`, block.ID, blockEquationToHTML(block.Equation, c.opts.Math))
+		}
+	case BlockDivider:
+		c.printf(`<hr id="%s"/>`, block.ID)
+	case BlockImage:
+		uri := escapeHTML(c.imageURL(block, block.ImageURL))
+		c.printf(`<figure id="%s" class="image"><a href="%s"><img src="%s"/></a></figure>`, block.ID, uri, uri)
+	case BlockBookmark:
+		link := escapeHTML(block.BookmarkURL())
+		title := c.inline(block.InlineContent)
+		if title == "" {
+			title = link
+		}
+		c.printf(`<figure id="%s"><a href="%s" class="bookmark source"><div class="bookmark-info"><div class="bookmark-text"><div class="bookmark-title">%s</div><div class="bookmark-description">%s</div></div><div class="bookmark-href">`, block.ID, link, title, escapeHTML(block.Description))
+		f := block.FormatBookmark
+		if f != nil && f.BookmarkIcon != "" {
+			c.printf(`<img src="%s" class="icon bookmark-icon"/>`, escapeHTML(f.BookmarkIcon))
+		}
+		c.printf(`%s</div></div>`, link)
+		if f != nil && f.BookmarkCoverURL != "" {
+			c.printf(`<img src="%s" class="bookmark-image"/>`, escapeHTML(c.imageURL(block, f.BookmarkCoverURL)))
+		}
+		c.printf(`</a></figure>`)
+	case BlockFile:
+		uri := escapeHTML(c.fileURL(block, block.Source))
+		c.printf(`<figure id="%s"><div class="source"><a href="%s">%s</a></div></figure>`, block.ID, uri, escapeHTML(block.Source))
+	case BlockAudio:
+		uri := escapeHTML(c.fileURL(block, block.Source))
+		c.printf(`<figure id="%s" class="audio"><audio controls src="%s"></audio></figure>`, block.ID, uri)
+	case BlockPDF:
+		uri := escapeHTML(c.fileURL(block, block.Source))
+		c.printf(`<figure id="%s"><div class="source"><a href="%s">%s</a></div></figure>`, block.ID, uri, escapeHTML(block.fileName()))
+	case BlockDrive:
+		title, uri := block.driveTitleAndURL()
+		c.printf(`<figure id="%s"><a href="%s" class="bookmark source"><div class="bookmark-info"><div class="bookmark-text"><div class="bookmark-title">`, block.ID, escapeHTML(uri))
+		if p := block.driveProperties(); p != nil && p.Icon != "" {
+			c.printf(`<img src="%s" class="icon bookmark-icon"/>`, escapeHTML(p.Icon))
+		}
+		c.printf(`%s</div></div></div></a></figure>`, escapeHTML(title))
+	case BlockTweet:
+		src := escapeHTML(block.Source)
+		c.printf(`<figure id="%s"><blockquote class="twitter-tweet"><a href="%s">%s</a></blockquote></figure>`, block.ID, src, src)
+	case BlockCodepen:
+		c.printf(`<figure id="%s"><div class="source"><iframe src="%s" allowfullscreen></iframe></div></figure>`, block.ID, escapeHTML(codepenEmbedURL(block.Source)))
+	case BlockFigma:
+		c.printf(`<figure id="%s"><div class="source"><iframe src="%s" allowfullscreen></iframe></div></figure>`, block.ID, escapeHTML(figmaEmbedURL(block.Source)))
+	case BlockGist:
+		src := escapeHTML(block.GistURL())
+		c.printf(`<figure id="%s"><div class="source"><a href="%s">%s</a></div></figure>`, block.ID, src, src)
+	case BlockVideo:
+		tag := c.videoTag(block)
+		if tag == "" {
+			src := escapeHTML(block.Source)
+			tag = fmt.Sprintf(`<a href="%s">%s</a>`, src, src)
+		}
+		c.printf(`<figure id="%s"><div class="source">%s</div></figure>`, block.ID, tag)
+	case BlockEmbed:
+		src := escapeHTML(block.Source)
+		c.printf(`<figure id="%s"><div class="source"><a href="%s">%s</a></div></figure>`, block.ID, src, src)
+	case BlockPage:
+		if c.opts.SkipSubPages && block.AliasPageID == "" {
+			return
+		}
+		title := block.Title
+		if title == "" {
+			title = "Untitled"
+		}
+		c.printf(`<figure id="%s" class="link-to-page"><a href="%s">%s</a></figure>`, block.ID, escapeHTML(c.pageURL(block.ID)), escapeHTML(title))
+	case BlockAlias:
+		title := block.AliasTitle
+		if title == "" {
+			title = "Untitled"
+		}
+		c.printf(`<figure id="%s" class="link-to-page"><a href="%s">%s</a></figure>`, block.ID, escapeHTML(c.pageURL(block.AliasPageID)), escapeHTML(title))
+	case BlockColumnList:
+		n := countColumns(block)
+		c.printf(`<div id="%s" class="column-list">`, block.ID)
+		for _, col := range block.Content {
+			if col == nil {
+				continue
+			}
+			if col.Type != BlockColumn {
+				c.renderNotionBlock(col)
+				continue
+			}
+			c.printf(`<div id="%s" style="width:%.4g%%" class="column">`, col.ID, columnWidth(col, n))
+			c.renderNotionBlocks(col.Content)
+			c.printf(`</div>`)
+		}
+		c.printf(`</div>`)
+	case BlockColumn, BlockTransclusionContainer, BlockTransclusionReference:
+		c.renderNotionBlocks(block.Content)
+	case BlockTable:
+		c.renderSimpleTable(block)
+	case BlockCollectionView:
+		for _, info := range block.CollectionViews {
+			c.renderTable(tableFromCollectionViewInfo(info))
+		}
+	case BlockTableOfContents:
+		c.renderTableOfContents(block)
+	case BlockBreadcrumb:
+		c.renderBreadcrumb()
+	case BlockFactory:
+		c.printf(`<div id="%s" class="factory"><button>%s</button></div>`, block.ID, c.inline(block.InlineContent))
+	case BlockComment:
+		// comments are not part of the content
+	default:
+		if block.IsEmbedBlock() {
+			c.printf(`<figure id="%s"><div class="source"><iframe src="%s" allowfullscreen></iframe></div></figure>`, block.ID, escapeHTML(block.embedURL()))
+			return
+		}
+		c.renderUnsupported(block)
+	}
+}
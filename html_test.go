@@ -0,0 +1,316 @@
+package notionapi
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToHTML(t *testing.T) {
+	code := &Block{
+		Type:         BlockCode,
+		Code:         "if a < b {}",
+		CodeLanguage: "Go",
+	}
+	image := &Block{
+		ID:       "2",
+		Type:     BlockImage,
+		ImageURL: "https://www.notion.so/image/foo.png",
+	}
+	page := testPage(
+		textBlock(BlockSubHeader, "Header"),
+		textBlock(BlockBulletedList, "one", textBlock(BlockBulletedList, "nested")),
+		textBlock(BlockBulletedList, "two"),
+		textBlock(BlockNumberedList, "first"),
+		textBlock(BlockToggle, "toggle", textBlock(BlockText, "hidden")),
+		code,
+		image,
+	)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	exp := `<article>
+<h1 class="page-title">Test page</h1>
+<h2 id="header-da39a3">Header</h2>
+<ul>
+<li>one
+<ul>
+<li>nested</li>
+</ul>
+</li>
+<li>two</li>
+</ul>
+<ol>
+<li>first</li>
+</ol>
+<details>
+<summary>toggle</summary>
+<p>hidden</p>
+</details>
+<pre><code class="language-go">if a &lt; b {}</code></pre>
+<figure>
+<img src="https://www.notion.so/image/foo.png">
+</figure>
+</article>
+`
+	assert.Equal(t, exp, got)
+
+	opts := &HTMLOptions{
+		Fragment: true,
+		RenderBlockOverride: func(w io.Writer, block *Block) bool {
+			if block.Type != BlockImage {
+				return false
+			}
+			fmt.Fprintf(w, "<img src=\"https://cdn.example.com/%s.png\">\n", block.ID)
+			return true
+		},
+	}
+	got = string(ToHTML(page, opts))
+	assert.Contains(t, got, `<img src="https://cdn.example.com/2.png">`)
+	assert.NotContains(t, got, "<figure>")
+}
+
+func TestToHTMLTable(t *testing.T) {
+	table := parseTestTable(t)
+	block := &Block{
+		Type: BlockCollectionView,
+		CollectionViews: []*CollectionViewInfo{
+			{
+				CollectionView: table.CollectionView,
+				Collection:     table.Collection,
+				CollectionRows: []*Block{testRow("1", "Done")},
+			},
+		},
+	}
+	got := string(ToHTML(testPage(block), &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, "<caption>My Tasks</caption>")
+	assert.Contains(t, got, "<tr><th>Name</th><th>Status</th></tr>")
+	assert.Contains(t, got, "<tr><td>Row 1</td><td>Done</td></tr>")
+}
+
+func TestToHTMLNotionCompatible(t *testing.T) {
+	header := textBlock(BlockHeader, "Header")
+	header.ID = "1"
+	item := textBlock(BlockBulletedList, "one")
+	item.ID = "2"
+	todo := textBlock(BlockTodo, "done")
+	todo.ID = "3"
+	todo.IsChecked = true
+	callout := textBlock(BlockCallout, "note")
+	callout.ID = "4"
+	callout.FormatRaw = []byte(`{"page_icon":"💡"}`)
+	page := testPage(header, item, todo, callout)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true, NotionCompatible: true}))
+	exp := `<article id="c969c945-5d7c-4dd7-9c7f-860f3ace6429" class="page sans"><header><h1 class="page-title">Test page</h1></header><div class="page-body">` +
+		`<h1 id="1" class="">Header</h1>` +
+		`<ul id="2" class="bulleted-list"><li>one</li></ul>` +
+		`<ul id="3" class="to-do-list"><li><div class="checkbox checkbox-on"></div> <span class="to-do-children-checked">done</span></li></ul>` +
+		`<figure class="callout" style="white-space:pre-wrap;display:flex" id="4"><div style="font-size:1.5em"><span class="icon">💡</span></div><div style="width:100%">note</div></figure>` +
+		`</div></article>`
+	assert.Equal(t, exp, got)
+}
+
+var (
+	htmlTagRx   = regexp.MustCompile(`<(/?)([a-z0-9]+)([^>]*?)(/?)>`)
+	htmlClassRx = regexp.MustCompile(`class="([^"]*)"`)
+	htmlIDRx    = regexp.MustCompile(`id="([^"]*)"`)
+)
+
+// htmlStructure returns elements inside <article> as tag#id.class lines
+// indented by nesting. Text and other attributes are ignored
+func htmlStructure(s string) string {
+	start := strings.Index(s, "<article")
+	end := strings.Index(s, "</article>")
+	if start < 0 || end < 0 {
+		return ""
+	}
+	var lines []string
+	depth := 0
+	for _, m := range htmlTagRx.FindAllStringSubmatch(s[start:end+len("</article>")], -1) {
+		if m[1] == "/" {
+			depth--
+			continue
+		}
+		line := strings.Repeat("  ", depth) + m[2]
+		if id := htmlIDRx.FindStringSubmatch(m[3]); id != nil {
+			line += "#" + id[1]
+		}
+		if cls := htmlClassRx.FindStringSubmatch(m[3]); cls != nil {
+			classes := strings.Fields(cls[1])
+			sort.Strings(classes)
+			for _, c := range classes {
+				line += "." + c
+			}
+		}
+		lines = append(lines, line)
+		if m[4] == "" && m[2] != "img" && m[2] != "hr" {
+			depth++
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// testdata/notion_export_page.html has the structure of Notion's
+// "Export as HTML" of the page in testdata/notion_export_page.json
+func TestToHTMLNotionCompatibleExport(t *testing.T) {
+	d, err := ioutil.ReadFile("testdata/notion_export_page.json")
+	assert.NoError(t, err)
+	// the response has all blocks so it works for both syncRecordValues
+	// and loadPageChunk
+	c, _ := newStaticClient(200, string(d))
+	page, err := c.DownloadPage("6d5d4a448d5b4b6b9c518e1a3c2b7f10")
+	assert.NoError(t, err)
+	exp, err := ioutil.ReadFile("testdata/notion_export_page.html")
+	assert.NoError(t, err)
+	got := string(ToHTML(page, &HTMLOptions{NotionCompatible: true}))
+	assert.Equal(t, htmlStructure(string(exp)), htmlStructure(got))
+	assert.Contains(t, htmlStructure(got), "\n    div.indented\n")
+}
+
+func TestToHTMLToggleableHeader(t *testing.T) {
+	header := textBlock(BlockHeader, "Header", textBlock(BlockToggle, "toggle", textBlock(BlockText, "hidden")))
+	header.FormatHeader = &FormatHeader{Toggleable: true}
+	page := testPage(header)
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	exp := `<article>
+<h1 class="page-title">Test page</h1>
+<details>
+<summary><h1 id="header-da39a3">Header</h1></summary>
+<details>
+<summary>toggle</summary>
+<p>hidden</p>
+</details>
+</details>
+</article>
+`
+	assert.Equal(t, exp, got)
+}
+
+func testColumnsPage() *Page {
+	column := func(ratio float64, children ...*Block) *Block {
+		b := &Block{Type: BlockColumn, Content: children}
+		if ratio > 0 {
+			b.FormatColumn = &FormatColumn{ColumnRation: Number(ratio)}
+		}
+		return b
+	}
+	nested := &Block{
+		Type:    BlockColumnList,
+		Content: []*Block{column(0, textBlock(BlockText, "a")), column(0, textBlock(BlockText, "b"))},
+	}
+	list := &Block{
+		Type:    BlockColumnList,
+		Content: []*Block{column(0.25, textBlock(BlockText, "left")), column(0.75, nested)},
+	}
+	return testPage(list)
+}
+
+func TestToHTMLColumns(t *testing.T) {
+	got := string(ToHTML(testColumnsPage(), &HTMLOptions{Fragment: true}))
+	exp := `<article>
+<h1 class="page-title">Test page</h1>
+<div class="column-list" style="display:flex">
+<div class="column" style="width:25%">
+<p>left</p>
+</div>
+<div class="column" style="width:75%">
+<div class="column-list" style="display:flex">
+<div class="column" style="width:50%">
+<p>a</p>
+</div>
+<div class="column" style="width:50%">
+<p>b</p>
+</div>
+</div>
+</div>
+</div>
+</article>
+`
+	assert.Equal(t, exp, got)
+}
+
+func TestToHTMLAnchors(t *testing.T) {
+	header := textBlock(BlockHeader, "Intro")
+	header.ID = "0367c2db-381a-4f8b-9ce3-60f388a6b2e3"
+	sub := textBlock(BlockSubHeader, "Intro")
+	sub.ID = "1367c2db-381a-4f8b-9ce3-60f388a6b2e3"
+	link := &Block{
+		Type: BlockText,
+		InlineContent: []*InlineBlock{
+			{Text: "see", Link: "https://www.notion.so/Test-page-c969c9455d7c4dd79c7f860f3ace6429#1367c2db381a4f8b9ce360f388a6b2e3"},
+			{Text: " and "},
+			{Text: "other", Link: "https://www.notion.so/Other-d969c9455d7c4dd79c7f860f3ace6429#1367c2db381a4f8b9ce360f388a6b2e3"},
+		},
+	}
+	toc := &Block{Type: BlockTableOfContents}
+	page := testPage(toc, header, sub, link)
+
+	a1, a2 := HeaderAnchor(header), HeaderAnchor(sub)
+	assert.Equal(t, "intro-", a1[:6])
+	assert.NotEqual(t, a1, a2)
+
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<h1 id="`+a1+`">Intro</h1>`)
+	assert.Contains(t, got, `<h2 id="`+a2+`">Intro</h2>`)
+	assert.Contains(t, got, `<a href="#`+a2+`">see</a>`)
+	assert.Contains(t, got, `<a href="https://www.notion.so/Other-d969c9455d7c4dd79c7f860f3ace6429#1367c2db381a4f8b9ce360f388a6b2e3">other</a>`)
+	assert.Contains(t, got, `<div class="table_of_contents-item table_of_contents-indent-1"><a href="#`+a2+`">Intro</a></div>`)
+
+	got = string(ToHTML(page, &HTMLOptions{Fragment: true, NotionCompatible: true}))
+	assert.Contains(t, got, `<a href="#`+sub.ID+`">see</a>`)
+}
+
+func testBookmarks() (*Block, *Block) {
+	rich := &Block{
+		Type:          BlockBookmark,
+		Link:          "https://blog.kowalczyk.info",
+		InlineContent: []*InlineBlock{{Text: "Blog"}},
+		Description:   "Articles & notes",
+		FormatBookmark: &FormatBookmark{
+			BookmarkIcon:     "https://blog.kowalczyk.info/favicon.ico",
+			BookmarkCoverURL: "https://www.notion.so/image/cover.png",
+		},
+	}
+	plain := &Block{
+		Type: BlockBookmark,
+		Link: "https://example.com",
+	}
+	return rich, plain
+}
+
+func TestToHTMLBookmark(t *testing.T) {
+	rich, plain := testBookmarks()
+	got := string(ToHTML(testPage(rich, plain), &HTMLOptions{Fragment: true}))
+	exp := `<article>
+<h1 class="page-title">Test page</h1>
+<a class="bookmark" href="https://blog.kowalczyk.info">
+<div class="bookmark-info">
+<div class="bookmark-title">Blog</div>
+<div class="bookmark-description">Articles &amp; notes</div>
+<div class="bookmark-href"><img class="bookmark-icon" src="https://blog.kowalczyk.info/favicon.ico">https://blog.kowalczyk.info</div>
+</div>
+<img class="bookmark-cover" src="https://www.notion.so/image/cover.png">
+</a>
+<p class="bookmark"><a href="https://example.com">https://example.com</a></p>
+</article>
+`
+	assert.Equal(t, exp, got)
+}
+
+func TestToHTMLPageMention(t *testing.T) {
+	page := testMentionPage()
+	got := string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `See <a href="https://www.notion.so/4c6a54c68b3e4ea2af9cfaabcc88d58d">Sub page</a> and <a href="https://www.notion.so/bb760e2dd6794b64b2a903005b21870a"><strong>Untitled</strong></a>`)
+	assert.NotContains(t, got, InlineAt)
+
+	got = string(ToHTML(page, &HTMLOptions{Fragment: true, NotionCompatible: true}))
+	assert.Contains(t, got, `See <a href="https://www.notion.so/4c6a54c68b3e4ea2af9cfaabcc88d58d">Sub page</a>`)
+	assert.NotContains(t, got, InlineAt)
+
+	got = InlineToHTML(page.Root.Content[0].InlineContent)
+	assert.Equal(t, `See <a href="https://www.notion.so/4c6a54c68b3e4ea2af9cfaabcc88d58d">Untitled</a> and <a href="https://www.notion.so/bb760e2dd6794b64b2a903005b21870a"><strong>Untitled</strong></a>`, got)
+}
@@ -0,0 +1,289 @@
+package notionapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidID is returned (wrapped) when a string is not a valid Notion id
+var ErrInvalidID = errors.New("invalid Notion id")
+
+// isHexDigits returns true if s only has hex digits
+func isHexDigits(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsValidNoDashID returns true if s is an id without dashes like
+// 2131b10cebf64938a1277089ff02dbe4
+func IsValidNoDashID(s string) bool {
+	return len(s) == 32 && isHexDigits(s)
+}
+
+// IsValidDashID returns true if s is an id with dashes like
+// 2131b10c-ebf6-4938-a127-7089ff02dbe4
+func IsValidDashID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for _, i := range []int{8, 13, 18, 23} {
+		if s[i] != '-' {
+			return false
+		}
+	}
+	return IsValidNoDashID(strings.Replace(s, "-", "", -1))
+}
+
+// ToNoDashID converts an id with or without dashes to a lower case id
+// without dashes, e.g. 2131b10cebf64938a1277089ff02dbe4. Returns an error
+// wrapping ErrInvalidID if s is not a valid id
+func ToNoDashID(s string) (string, error) {
+	if !IsValidDashID(s) && !IsValidNoDashID(s) {
+		return "", fmt.Errorf("%w '%s': must be 32 hex digits, optionally with dashes", ErrInvalidID, s)
+	}
+	return strings.ToLower(strings.Replace(s, "-", "", -1)), nil
+}
+
+// ToDashID converts an id with or without dashes to a lower case id with
+// dashes, e.g. 2131b10c-ebf6-4938-a127-7089ff02dbe4, which is the format
+// used by Notion API. Returns an error wrapping ErrInvalidID if s is not
+// a valid id
+func ToDashID(s string) (string, error) {
+	id, err := ToNoDashID(s)
+	if err != nil {
+		return "", err
+	}
+	return id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:], nil
+}
+
+// isValidID returns true if s is a Notion id, with or without dashes
+func isValidID(s string) bool {
+	return IsValidDashID(s) || IsValidNoDashID(s)
+}
+
+// mustNormalizeID returns id in the format of ToDashID, or id as is if
+// it's not valid
+func mustNormalizeID(id string) string {
+	if res, err := ToDashID(id); err == nil {
+		return res
+	}
+	return id
+}
+
+// SameID returns true if a and b are the same id, ignoring case and
+// dashes, e.g. 2131B10CEBF64938A1277089FF02DBE4 and
+// 2131b10c-ebf6-4938-a127-7089ff02dbe4. Strings that are not valid ids
+// must be equal
+func SameID(a string, b string) bool {
+	return mustNormalizeID(a) == mustNormalizeID(b)
+}
+
+// NoIDInURLError is returned by ExtractNoDashIDFromNotionURL when the url
+// doesn't have a Notion id
+type NoIDInURLError struct {
+	URL string
+}
+
+func (e *NoIDInURLError) Error() string {
+	return fmt.Sprintf("no Notion id in url '%s'", e.URL)
+}
+
+// idSuffix returns id at the end of s, like in Page-Title-<id>, with or
+// without dashes. Returns "" if s doesn't end with an id
+func idSuffix(s string) string {
+	if len(s) >= 36 && IsValidDashID(s[len(s)-36:]) {
+		return s[len(s)-36:]
+	}
+	if len(s) >= 32 && IsValidNoDashID(s[len(s)-32:]) {
+		return s[len(s)-32:]
+	}
+	return ""
+}
+
+// ExtractNoDashIDFromNotionURL returns id of a page (in ToNoDashID format)
+// from Notion url, like https://www.notion.so/Workspace/Page-Title-<id>,
+// notion.so/<id> or https://<workspace>.notion.site/<id>. For urls of
+// a page opened as a peek (with ?p=<id>), it's id of the peeked page.
+// A bare id is also accepted. Returns *NoIDInURLError if the url doesn't
+// have an id
+func ExtractNoDashIDFromNotionURL(uri string) (string, error) {
+	s := strings.TrimSpace(uri)
+	if id, err := ToNoDashID(s); err == nil {
+		return id, nil
+	}
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", &NoIDInURLError{URL: uri}
+	}
+	if p := u.Query().Get("p"); p != "" {
+		if id, err := ToNoDashID(p); err == nil {
+			return id, nil
+		}
+	}
+	last := path.Base(strings.TrimRight(u.Path, "/"))
+	if id := idSuffix(last); id != "" {
+		return ToNoDashID(id)
+	}
+	return "", &NoIDInURLError{URL: uri}
+}
+
+// ParsedURL describes parts of a Notion url. Ids are in ToDashID format
+// and are "" if not in the url
+type ParsedURL struct {
+	// id of the page, for databases it's the page with the database
+	PageID string
+	// id of the view of a database in ?v=
+	CollectionViewID string
+	// id of a page opened as a peek in ?p=
+	PeekPageID string
+	// workspace in notion.so/<workspace>/<page> or
+	// <workspace>.notion.site/<page> urls
+	Workspace string
+}
+
+// ParseNotionURL returns ids from Notion url, like
+// https://www.notion.so/Workspace/<id>?v=<view id>&p=<peek page id>.
+// A bare page id is also accepted. Returns *NoIDInURLError if the url
+// has no page id, and an error wrapping ErrInvalidID if ?v= or ?p= are
+// not valid ids
+func ParseNotionURL(uri string) (*ParsedURL, error) {
+	s := strings.TrimSpace(uri)
+	if id, err := ToDashID(s); err == nil {
+		return &ParsedURL{PageID: id}, nil
+	}
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, &NoIDInURLError{URL: uri}
+	}
+	res := &ParsedURL{}
+	host := strings.ToLower(u.Hostname())
+	if strings.HasSuffix(host, ".notion.site") {
+		res.Workspace = strings.TrimSuffix(host, ".notion.site")
+	}
+	var parts []string
+	for _, part := range strings.Split(u.Path, "/") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) > 0 {
+		if id := idSuffix(parts[len(parts)-1]); id != "" {
+			res.PageID, _ = ToDashID(id)
+		}
+	}
+	if len(parts) > 1 && res.Workspace == "" {
+		res.Workspace = parts[0]
+	}
+	q := u.Query()
+	if v := q.Get("v"); v != "" {
+		if res.CollectionViewID, err = ToDashID(v); err != nil {
+			return nil, fmt.Errorf("invalid view id (?v=) in url '%s': %w", uri, err)
+		}
+	}
+	if p := q.Get("p"); p != "" {
+		if res.PeekPageID, err = ToDashID(p); err != nil {
+			return nil, fmt.Errorf("invalid peek page id (?p=) in url '%s': %w", uri, err)
+		}
+	}
+	if res.PageID == "" && res.PeekPageID == "" {
+		return nil, &NoIDInURLError{URL: uri}
+	}
+	return res, nil
+}
+
+// maxURLSlugLen is the maximum length, in characters, of title part of
+// urls made by pageURL
+const maxURLSlugLen = 60
+
+// urlSlug returns title in the form used in Notion urls: letters and
+// digits, in their original case, separated by "-". Emoji and punctuation
+// are removed
+func urlSlug(title string) string {
+	words := strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	res := strings.Join(words, "-")
+	if utf8.RuneCountInString(res) > maxURLSlugLen {
+		runes := []rune(res)[:maxURLSlugLen]
+		res = strings.TrimRight(string(runes), "-")
+	}
+	return res
+}
+
+// pageURL returns url of a page with a given title and id, like the one
+// Notion shows
+func pageURL(title string, id string) string {
+	noDash, err := ToNoDashID(id)
+	if err != nil {
+		noDash = strings.Replace(id, "-", "", -1)
+	}
+	if slug := urlSlug(title); slug != "" {
+		return "https://www.notion.so/" + slug + "-" + noDash
+	}
+	return "https://www.notion.so/" + noDash
+}
+
+// URL returns url of the page on notion.so like
+// https://www.notion.so/Page-Title-c969c9455d7c4dd79c7f860f3ace6429
+func (p *Page) URL() string {
+	return pageURL(pageTitle(p.Root), p.Root.ID)
+}
+
+// URL returns url that opens page p scrolled to the block, like
+// https://www.notion.so/Page-Title-<page id>#<block id>. For the root of
+// the page and sub-pages it's url of the page
+func (b *Block) URL(p *Page) string {
+	if b.Type == BlockPage || SameID(b.ID, p.Root.ID) {
+		return pageURL(pageTitle(b), b.ID)
+	}
+	noDash, err := ToNoDashID(b.ID)
+	if err != nil {
+		noDash = strings.Replace(b.ID, "-", "", -1)
+	}
+	return p.URL() + "#" + noDash
+}
+
+// pageIDArg returns page id, in ToDashID format, given to DownloadPage.
+// It can be an id or a Notion url. The error explains what is wrong
+// with the id
+func pageIDArg(s string) (string, error) {
+	if id, err := ToDashID(s); err == nil {
+		return id, nil
+	}
+	if strings.Contains(s, "/") || strings.Contains(s, "notion.") {
+		id, err := ExtractNoDashIDFromNotionURL(s)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrInvalidID, err)
+		}
+		return ToDashID(id)
+	}
+	_, err := ToDashID(s)
+	noDash := strings.Replace(strings.TrimSpace(s), "-", "", -1)
+	if len(noDash) < 32 && isHexDigits(noDash) {
+		return "", fmt.Errorf("%w, it has %d hex digits instead of 32. Was it truncated?", err, len(noDash))
+	}
+	return "", err
+}
+
+// isCollectionViewID returns true if id is an id of a collection view we
+// can access
+func (c *Client) isCollectionViewID(ctx context.Context, id string) bool {
+	rsp, err := c.GetRecordValuesCtx(ctx, []RecordRequest{{Table: TableCollectionView, ID: id}})
+	return err == nil && rsp.Results[0].CollectionView != nil
+}
@@ -0,0 +1,170 @@
+package notionapi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDs(t *testing.T) {
+	const dashID = "2131b10c-ebf6-4938-a127-7089ff02dbe4"
+	const noDashID = "2131b10cebf64938a1277089ff02dbe4"
+	assert.True(t, IsValidDashID(dashID))
+	assert.False(t, IsValidDashID(noDashID))
+	assert.False(t, IsValidDashID("2131b10ce-bf6-4938-a127-7089ff02dbe4"))
+	assert.True(t, IsValidNoDashID(noDashID))
+	assert.True(t, IsValidNoDashID("2131B10CEBF64938A1277089FF02DBE4"))
+	assert.False(t, IsValidNoDashID(dashID))
+	assert.False(t, IsValidNoDashID("2131b10cebf64938a1277089ff02dbeg"))
+
+	for _, s := range []string{dashID, noDashID, "2131B10C-EBF6-4938-A127-7089FF02DBE4"} {
+		id, err := ToDashID(s)
+		assert.NoError(t, err)
+		assert.Equal(t, dashID, id)
+		id, err = ToNoDashID(s)
+		assert.NoError(t, err)
+		assert.Equal(t, noDashID, id)
+	}
+	for _, s := range []string{"", "abc", "2131b10cebf64938a1277089ff02dbe", "2131b10cebf64938a1277089ff02dbex", "2131-b10cebf64938a1277089ff02dbe4"} {
+		_, err := ToDashID(s)
+		assert.True(t, errors.Is(err, ErrInvalidID), s)
+		assert.Contains(t, err.Error(), "'"+s+"'")
+		_, err = ToNoDashID(s)
+		assert.True(t, errors.Is(err, ErrInvalidID), s)
+	}
+
+	// invalid ids are rejected before talking to the server
+	c, transport := newStaticClient(200, `{}`)
+	_, err := c.DownloadPage("2131b10cebf64938a1277089ff02dbex")
+	assert.True(t, errors.Is(err, ErrInvalidID))
+	assert.Equal(t, 0, len(transport.requests))
+}
+
+func TestSameID(t *testing.T) {
+	assert.True(t, SameID("2131B10CEBF64938A1277089FF02DBE4", "2131b10c-ebf6-4938-a127-7089ff02dbe4"))
+	assert.True(t, SameID("2131b10c-ebf6-4938-a127-7089ff02dbe4", "2131b10cebf64938a1277089ff02dbe4"))
+	assert.False(t, SameID("2131b10c-ebf6-4938-a127-7089ff02dbe4", "2131b10c-ebf6-4938-a127-7089ff02dbe5"))
+	// not valid ids are compared as is
+	assert.True(t, SameID("row1", "row1"))
+	assert.False(t, SameID("row1", "ROW1"))
+
+	page := &Page{Users: []*User{{ID: "bb760e2d-d679-4b64-b2a9-03005b21870a", Email: "kkowalczyk@gmail.com"}}}
+	u := page.findUser("BB760E2DD6794B64B2A903005B21870A")
+	if assert.NotNil(t, u) {
+		assert.Equal(t, "kkowalczyk@gmail.com", u.Email)
+	}
+	b := &Block{CreatedByID: "BB760E2D-D679-4B64-B2A9-03005B21870A"}
+	assert.Equal(t, u, b.CreatedByUser(page))
+}
+
+func TestExtractNoDashIDFromNotionURL(t *testing.T) {
+	const id = "c969c9455d7c4dd79c7f860f3ace6429"
+	const peekID = "0367c2db381a4f8b9ce360f388a6b2e3"
+	urls := map[string]string{
+		"https://www.notion.so/Workspace-Name/Page-Title-c969c9455d7c4dd79c7f860f3ace6429":                                             id,
+		"https://www.notion.so/c969c9455d7c4dd79c7f860f3ace6429":                                                                       id,
+		"notion.so/c969c9455d7c4dd79c7f860f3ace6429":                                                                                   id,
+		"www.notion.so/Some-Page-c969c9455d7c4dd79c7f860f3ace6429/":                                                                    id,
+		"https://www.notion.so/Title-C969C9455D7C4DD79C7F860F3ACE6429":                                                                 id,
+		"https://www.notion.so/c969c945-5d7c-4dd7-9c7f-860f3ace6429":                                                                   id,
+		"https://team.notion.site/Page-c969c9455d7c4dd79c7f860f3ace6429#0367c2db381a4f8b9ce360f388a6b2e3":                              id,
+		"https://www.notion.so/c969c9455d7c4dd79c7f860f3ace6429?v=9e0c2b1f1b6d4b4d8b5d1a3c2e3f4a5b":                                    id,
+		"https://www.notion.so/c969c9455d7c4dd79c7f860f3ace6429?v=9e0c2b1f1b6d4b4d8b5d1a3c2e3f4a5b&p=0367c2db381a4f8b9ce360f388a6b2e3": peekID,
+		"  c969c945-5d7c-4dd7-9c7f-860f3ace6429 ":                                                                                      id,
+	}
+	for uri, exp := range urls {
+		got, err := ExtractNoDashIDFromNotionURL(uri)
+		assert.NoError(t, err, uri)
+		assert.Equal(t, exp, got, uri)
+	}
+	for _, uri := range []string{"", "https://www.notion.so/", "https://www.notion.so/Some-Page", "https://www.notion.so/Page-c969c9455d7c4dd79c7f860f3ace642"} {
+		_, err := ExtractNoDashIDFromNotionURL(uri)
+		var noID *NoIDInURLError
+		assert.True(t, errors.As(err, &noID), uri)
+	}
+}
+
+func TestPageURL(t *testing.T) {
+	assert.Equal(t, "Hello-World", urlSlug("🚀 Hello,   World!"))
+	assert.Equal(t, "Zażółć-gęślą", urlSlug("Zażółć gęślą"))
+	assert.Equal(t, "", urlSlug("!!! 🎉"))
+	long := urlSlug("A very long title that is going to be cut because it is longer than the limit")
+	assert.Equal(t, "A-very-long-title-that-is-going-to-be-cut-because-it-is-long", long)
+
+	b := textBlock(BlockText, "text")
+	b.ID = "0367c2db-381a-4f8b-9ce3-60f388a6b2e3"
+	sub := &Block{ID: "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", Type: BlockPage, Title: "Sub page"}
+	page := testPage(b, sub)
+	assert.Equal(t, "https://www.notion.so/Test-page-c969c9455d7c4dd79c7f860f3ace6429", page.URL())
+	assert.Equal(t, "https://www.notion.so/Test-page-c969c9455d7c4dd79c7f860f3ace6429#0367c2db381a4f8b9ce360f388a6b2e3", b.URL(page))
+	assert.Equal(t, page.URL(), page.Root.URL(page))
+	assert.Equal(t, "https://www.notion.so/Sub-page-4c6a54c68b3e4ea2af9cfaabcc88d58d", sub.URL(page))
+
+	page.Root.Title = "🎉"
+	assert.Equal(t, "https://www.notion.so/c969c9455d7c4dd79c7f860f3ace6429", page.URL())
+	// urls we make can be parsed back
+	id, err := ExtractNoDashIDFromNotionURL(b.URL(page))
+	assert.NoError(t, err)
+	assert.Equal(t, "c969c9455d7c4dd79c7f860f3ace6429", id)
+}
+
+func TestDownloadPageIDArg(t *testing.T) {
+	id, err := pageIDArg("https://www.notion.so/Test-page-c969c9455d7c4dd79c7f860f3ace6429?pvs=4")
+	assert.NoError(t, err)
+	assert.Equal(t, "c969c945-5d7c-4dd7-9c7f-860f3ace6429", id)
+
+	_, err = pageIDArg("c969c9455d7c4dd79c7f860f3ace64")
+	assert.True(t, errors.Is(err, ErrInvalidID))
+	assert.Contains(t, err.Error(), "30 hex digits instead of 32")
+
+	_, err = pageIDArg("https://www.notion.so/Test-page")
+	assert.True(t, errors.Is(err, ErrInvalidID))
+	var noID *NoIDInURLError
+	assert.True(t, errors.As(err, &noID))
+
+	// the server only knows the id as a view of a database
+	c, transport := newStaticClient(200, `{
+	"recordMap": {
+		"collection_view": {
+			"c969c945-5d7c-4dd7-9c7f-860f3ace6429": {
+				"role": "editor",
+				"value": { "id": "c969c945-5d7c-4dd7-9c7f-860f3ace6429", "type": "table", "alive": true }
+			}
+		}
+	}
+}`)
+	_, err = c.DownloadPage("https://www.notion.so/c969c9455d7c4dd79c7f860f3ace6429")
+	assert.True(t, IsNotFound(err))
+	assert.Contains(t, err.Error(), "database view")
+	assert.Contains(t, transport.requests[0], "c969c945-5d7c-4dd7-9c7f-860f3ace6429")
+}
+
+func TestParseNotionURL(t *testing.T) {
+	const id = "c969c945-5d7c-4dd7-9c7f-860f3ace6429"
+	const viewID = "a3fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e1"
+	const peekID = "0367c2db-381a-4f8b-9ce3-60f388a6b2e3"
+	tests := []struct {
+		uri string
+		exp ParsedURL
+	}{
+		{"c969c9455d7c4dd79c7f860f3ace6429", ParsedURL{PageID: id}},
+		{"https://www.notion.so/c969c9455d7c4dd79c7f860f3ace6429", ParsedURL{PageID: id}},
+		{"https://www.notion.so/kjk/Tasks-C969C9455D7C4DD79C7F860F3ACE6429?v=a3fe8f7d1b2e4a4b9b2b2c55d0f3a7e1", ParsedURL{PageID: id, CollectionViewID: viewID, Workspace: "kjk"}},
+		{"notion.so/kjk/c969c9455d7c4dd79c7f860f3ace6429?v=a3fe8f7d1b2e4a4b9b2b2c55d0f3a7e1&p=0367c2db381a4f8b9ce360f388a6b2e3&pm=s", ParsedURL{PageID: id, CollectionViewID: viewID, PeekPageID: peekID, Workspace: "kjk"}},
+		{"https://Team.notion.site/Tasks-c969c9455d7c4dd79c7f860f3ace6429/", ParsedURL{PageID: id, Workspace: "team"}},
+		{"https://www.notion.so/kjk?p=0367c2db381a4f8b9ce360f388a6b2e3", ParsedURL{PeekPageID: peekID}},
+	}
+	for _, test := range tests {
+		got, err := ParseNotionURL(test.uri)
+		if assert.NoError(t, err, test.uri) {
+			assert.Equal(t, test.exp, *got, test.uri)
+		}
+	}
+
+	_, err := ParseNotionURL("https://www.notion.so/kjk/Tasks")
+	var noID *NoIDInURLError
+	assert.True(t, errors.As(err, &noID))
+	_, err = ParseNotionURL("https://www.notion.so/c969c9455d7c4dd79c7f860f3ace6429?v=abc")
+	assert.True(t, errors.Is(err, ErrInvalidID))
+}
@@ -1,13 +1,16 @@
 package notionapi
 
 import (
-	"encoding/json"
 	"fmt"
 )
 
 const (
-	// InlineAt is what Notion uses for text to represent @user and @date blocks
+	// InlineAt is what Notion uses for text to represent @user, @date and
+	// page mention blocks
 	InlineAt = "‣"
+	// InlineEquation is what Notion uses for text to represent inline
+	// equations
+	InlineEquation = "⁍"
 )
 
 // AttrFlag is a compact description of some flags
@@ -33,12 +36,29 @@ type InlineBlock struct {
 	// only one of those is set on a given InlineBlock
 	Link   string `json:"Link,omitempty"`   // represents link attribute
 	UserID string `json:"UserID,omitempty"` // represents user attribute
+	PageID string `json:"PageID,omitempty"` // represents page mention attribute
 	Date   *Date  `json:"Date,omitempty"`   // represents date attribute
+	// LaTeX of an inline equation
+	Equation string `json:"Equation,omitempty"`
+	// color of highlighted text, one of Color* constants
+	Highlight string `json:"Highlight,omitempty"`
 }
 
 // IsPlain returns true if this InlineBlock is plain text i.e. has no attributes
 func (b *InlineBlock) IsPlain() bool {
-	return b.AttrFlags == 0 && b.Link == "" && b.UserID == "" && b.Date == nil
+	return b.AttrFlags == 0 && b.Link == "" && b.UserID == "" && b.PageID == "" && b.Date == nil && b.Equation == "" && b.Highlight == ""
+}
+
+// dateToString formats a date for display e.g. "2018-07-17 15:00"
+func dateToString(d *Date) string {
+	if d == nil {
+		return ""
+	}
+	s := d.StartDate
+	if d.StartTime != nil {
+		s += " " + *d.StartTime
+	}
+	return s
 }
 
 func parseAttribute(b *InlineBlock, a []interface{}) error {
@@ -71,35 +91,102 @@ func parseAttribute(b *InlineBlock, a []interface{}) error {
 	}
 
 	switch s {
-	case "a", "u":
+	case "a", "u", "p", "e", "h":
 		v, ok := a[1].(string)
 		if !ok {
-			return fmt.Errorf("value for 'a' attribute is not string. Type: %T, value: %#v", v, v)
+			return fmt.Errorf("value for '%s' attribute is not string. Type: %T, value: %#v", s, a[1], a[1])
 		}
-		if s == "a" {
+		switch s {
+		case "a":
 			b.Link = v
-		} else if s == "u" {
+		case "u":
 			b.UserID = v
+		case "p":
+			b.PageID = v
+		case "e":
+			b.Equation = v
+		case "h":
+			b.Highlight = v
 		}
 	case "d":
-		v := a[1].(map[string]interface{})
-		js, err := json.MarshalIndent(v, "", "  ")
-		if err != nil {
-			panic(err.Error())
+		v, ok := a[1].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("value for 'd' attribute is not map[string]interface{}. Type: %T, value: %#v", a[1], a[1])
 		}
-		//dbg("date in js:\n%s\n", string(js))
-		var d Date
-		err = json.Unmarshal(js, &d)
+		d, err := parseDate(v)
 		if err != nil {
-			panic(err.Error())
+			return err
 		}
-		b.Date = &d
+		b.Date = d
 	default:
 		return fmt.Errorf("unexpected attribute '%s'", s)
 	}
 	return nil
 }
 
+// dateAlloc has Date and values its pointers point to so that parsing
+// a date needs a single allocation
+type dateAlloc struct {
+	date       Date
+	reminder   Reminder
+	startTime  string
+	timeZone   string
+	timeFormat string
+}
+
+// parseDate converts a date decoded from json to Date. It's much faster
+// than encoding it back to json and decoding it to Date
+func parseDate(v map[string]interface{}) (*Date, error) {
+	alloc := &dateAlloc{}
+	d := &alloc.date
+	for k, val := range v {
+		if val == nil {
+			continue
+		}
+		if k == "reminder" {
+			m, ok := val.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("reminder of date is not map[string]interface{}. Type: %T, value: %#v", val, val)
+			}
+			r := &alloc.reminder
+			r.Time, _ = m["time"].(string)
+			r.Unit, _ = m["unit"].(string)
+			if n, ok := m["value"].(float64); ok {
+				r.Value = int64(n)
+			}
+			d.Reminder = r
+			continue
+		}
+		s, ok := val.(string)
+		if !ok {
+			// unknown fields can have any type
+			switch k {
+			case "date_format", "start_date", "start_time", "time_zone", "time_format", "type":
+				return nil, fmt.Errorf("'%s' of date is not string. Type: %T, value: %#v", k, val, val)
+			}
+			continue
+		}
+		switch k {
+		case "date_format":
+			d.DateFormat = s
+		case "start_date":
+			d.StartDate = s
+		case "start_time":
+			alloc.startTime = s
+			d.StartTime = &alloc.startTime
+		case "time_zone":
+			alloc.timeZone = s
+			d.TimeZone = &alloc.timeZone
+		case "time_format":
+			alloc.timeFormat = s
+			d.TimeFormat = &alloc.timeFormat
+		case "type":
+			d.Type = s
+		}
+	}
+	return d, nil
+}
+
 func parseAttributes(b *InlineBlock, a []interface{}) error {
 	for _, rawAttr := range a {
 		attrList, ok := rawAttr.([]interface{})
@@ -114,44 +201,47 @@ func parseAttributes(b *InlineBlock, a []interface{}) error {
 	return nil
 }
 
-func parseInlineBlock(a []interface{}) (*InlineBlock, error) {
+// parseInlineBlockTo parses a into res
+func parseInlineBlockTo(res *InlineBlock, a []interface{}) error {
 	if len(a) == 0 {
-		return nil, fmt.Errorf("a is empty")
+		return fmt.Errorf("a is empty")
 	}
 
 	if len(a) == 1 {
 		s, ok := a[0].(string)
 		if !ok {
-			return nil, fmt.Errorf("a is of length 1 but not string. a[0] el type: %T, el value: '%#v'", a[0], a[0])
+			return fmt.Errorf("a is of length 1 but not string. a[0] el type: %T, el value: '%#v'", a[0], a[0])
 		}
-		return &InlineBlock{
-			Text: s,
-		}, nil
+		res.Text = s
+		return nil
 	}
 	if len(a) != 2 {
-		return nil, fmt.Errorf("a is of length != 2. a value: '%#v'", a)
+		return fmt.Errorf("a is of length != 2. a value: '%#v'", a)
 	}
 
 	s, ok := a[0].(string)
 	if !ok {
-		return nil, fmt.Errorf("a[0] is not string. a[0] type: %T, value: '%#v'", a[0], a[0])
-	}
-	res := &InlineBlock{
-		Text: s,
+		return fmt.Errorf("a[0] is not string. a[0] type: %T, value: '%#v'", a[0], a[0])
 	}
-	a, ok = a[1].([]interface{})
+	res.Text = s
+	attrs, ok := a[1].([]interface{})
 	if !ok {
-		return nil, fmt.Errorf("a[1] is not []interface{}. a[1] type: %T, value: '%#v'", a[1], a[1])
+		return fmt.Errorf("a[1] is not []interface{}. a[1] type: %T, value: '%#v'", a[1], a[1])
 	}
-	err := parseAttributes(res, a)
-	if err != nil {
+	return parseAttributes(res, attrs)
+}
+
+func parseInlineBlock(a []interface{}) (*InlineBlock, error) {
+	res := &InlineBlock{}
+	if err := parseInlineBlockTo(res, a); err != nil {
 		return nil, err
 	}
 	return res, nil
 }
 
-func parseInlineBlocks(raw interface{}) ([]*InlineBlock, error) {
-	var res []*InlineBlock
+// inlineBlocksRaw returns parts of raw value of a property with inline
+// blocks
+func inlineBlocksRaw(raw interface{}) ([]interface{}, error) {
 	a, ok := raw.([]interface{})
 	if !ok {
 		return nil, fmt.Errorf("raw is not of []interface{}. raw type: %T, value: '%#v'", raw, raw)
@@ -159,16 +249,103 @@ func parseInlineBlocks(raw interface{}) ([]*InlineBlock, error) {
 	if len(a) == 0 {
 		return nil, fmt.Errorf("raw is empty")
 	}
-	for _, v := range a {
+	return a, nil
+}
+
+func parseInlineBlocks(raw interface{}) ([]*InlineBlock, error) {
+	a, err := inlineBlocksRaw(raw)
+	if err != nil {
+		return nil, err
+	}
+	// allocating all blocks at once is much faster for long texts
+	blocks := make([]InlineBlock, len(a))
+	res := make([]*InlineBlock, len(a))
+	for i, v := range a {
 		a2, ok := v.([]interface{})
 		if !ok {
 			return nil, fmt.Errorf("v is not []interface{}. v type: %T, value: '%#v'", v, v)
 		}
-		block, err := parseInlineBlock(a2)
-		if err != nil {
+		if err := parseInlineBlockTo(&blocks[i], a2); err != nil {
 			return nil, err
 		}
-		res = append(res, block)
+		res[i] = &blocks[i]
 	}
 	return res, nil
 }
+
+// firstInlineText returns text of the first inline block of raw value of
+// a property. It's the same as text of the first block returned by
+// parseInlineBlocks but doesn't allocate the blocks
+func firstInlineText(raw interface{}) (string, error) {
+	a, err := inlineBlocksRaw(raw)
+	if err != nil {
+		return "", err
+	}
+	var text string
+	for i, v := range a {
+		a2, ok := v.([]interface{})
+		if !ok {
+			return "", fmt.Errorf("v is not []interface{}. v type: %T, value: '%#v'", v, v)
+		}
+		var b InlineBlock
+		if err := parseInlineBlockTo(&b, a2); err != nil {
+			return "", err
+		}
+		if i == 0 {
+			text = b.Text
+		}
+	}
+	return text, nil
+}
+
+// inlineBlockAttributes returns attributes of b in the format used by
+// Notion e.g. [["b"], ["a", "https://blog.kowalczyk.info"]]
+func inlineBlockAttributes(b *InlineBlock) []interface{} {
+	var res []interface{}
+	if b.AttrFlags&AttrBold != 0 {
+		res = append(res, []interface{}{"b"})
+	}
+	if b.AttrFlags&AttrItalic != 0 {
+		res = append(res, []interface{}{"i"})
+	}
+	if b.AttrFlags&AttrStrikeThrought != 0 {
+		res = append(res, []interface{}{"s"})
+	}
+	if b.AttrFlags&AttrCode != 0 {
+		res = append(res, []interface{}{"c"})
+	}
+	if b.Link != "" {
+		res = append(res, []interface{}{"a", b.Link})
+	}
+	if b.UserID != "" {
+		res = append(res, []interface{}{"u", b.UserID})
+	}
+	if b.PageID != "" {
+		res = append(res, []interface{}{"p", b.PageID})
+	}
+	if b.Date != nil {
+		res = append(res, []interface{}{"d", b.Date})
+	}
+	if b.Equation != "" {
+		res = append(res, []interface{}{"e", b.Equation})
+	}
+	if b.Highlight != "" {
+		res = append(res, []interface{}{"h", b.Highlight})
+	}
+	return res
+}
+
+// InlineBlocksToJSON is the reverse of parsing inline blocks. It returns
+// blocks in the format used by Notion for values of properties like "title"
+func InlineBlocksToJSON(blocks []*InlineBlock) []interface{} {
+	res := []interface{}{}
+	for _, b := range blocks {
+		attrs := inlineBlockAttributes(b)
+		if len(attrs) == 0 {
+			res = append(res, []interface{}{b.Text})
+			continue
+		}
+		res = append(res, []interface{}{b.Text, attrs})
+	}
+	return res
+}
@@ -54,6 +54,49 @@ func TestParseInlineBlock2(t *testing.T) {
 	assert.False(t, b.IsPlain())
 }
 
+const titlePageMention = `{
+	"title": [
+	  [ "see " ],
+	  [ "‣", [ [ "p", "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d" ] ] ]
+	]
+}`
+
+func TestParseInlineBlockPageMention(t *testing.T) {
+	blocks := parseBlocks(t, titlePageMention)
+	assert.Equal(t, 2, len(blocks))
+	b := blocks[1]
+	assert.Equal(t, InlineAt, b.Text)
+	assert.Equal(t, "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", b.PageID)
+	assert.False(t, b.IsPlain())
+
+	js := InlineBlocksToJSON(blocks)
+	got, err := parseInlineBlocks(js)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks, got)
+}
+
+const titleHighlight = `{
+	"title": [
+	  [ "plain " ],
+	  [ "highlighted", [ [ "b" ], [ "h", "yellow_background" ] ] ],
+	  [ " red", [ [ "h", "red" ] ] ]
+	]
+}`
+
+func TestParseInlineBlockHighlightRoundTrip(t *testing.T) {
+	blocks := parseBlocks(t, titleHighlight)
+	assert.Equal(t, 3, len(blocks))
+	assert.Equal(t, ColorYellowBackground, blocks[1].Highlight)
+	assert.Equal(t, AttrBold, blocks[1].AttrFlags)
+	assert.Equal(t, "red", blocks[2].Highlight)
+
+	// highlights must survive writing text back
+	d, err := json.Marshal(map[string]interface{}{"title": InlineBlocksToJSON(blocks)})
+	assert.NoError(t, err)
+	got := parseBlocks(t, string(d))
+	assert.Equal(t, blocks, got)
+}
+
 const title3 = `{
 	"title": [
 		["Text block with "],
@@ -230,3 +273,43 @@ func TestParseInlineBlockBig(t *testing.T) {
 	blocks := parseBlocks(t, titleBig)
 	assert.Equal(t, 17, len(blocks))
 }
+
+func TestParseDate(t *testing.T) {
+	const js = `{
+		"date_format": "relative",
+		"start_date": "2018-07-17",
+		"start_time": "15:00",
+		"time_zone": "America/Los_Angeles",
+		"time_format": "H:mm",
+		"type": "datetime",
+		"reminder": {"time": "09:00", "unit": "day", "value": 1},
+		"end_date": "2018-07-18"
+	}`
+	var v map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(js), &v))
+	got, err := parseDate(v)
+	assert.NoError(t, err)
+	// must be the same as decoding json
+	var exp Date
+	assert.NoError(t, json.Unmarshal([]byte(js), &exp))
+	assert.Equal(t, &exp, got)
+
+	_, err = parseDate(map[string]interface{}{"start_date": 5.0})
+	assert.Error(t, err)
+	_, err = parseInlineBlocks([]interface{}{[]interface{}{InlineAt, []interface{}{[]interface{}{"d", "2018-07-17"}}}})
+	assert.Error(t, err)
+}
+
+func TestFirstInlineText(t *testing.T) {
+	var m map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(titleBig), &m))
+	s, err := firstInlineText(m["title"])
+	assert.NoError(t, err)
+	assert.Equal(t, "Text block with ", s)
+
+	// invalid parts after the first are errors, like in parseInlineBlocks
+	_, err = firstInlineText([]interface{}{[]interface{}{"a"}, []interface{}{5.0}})
+	assert.Error(t, err)
+	_, err = firstInlineText([]interface{}{})
+	assert.Error(t, err)
+}
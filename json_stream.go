@@ -0,0 +1,159 @@
+package notionapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+)
+
+// streamDecoder is implemented by api responses that can be decoded one
+// record at a time, without reading the whole response into memory first.
+// Responses to loadPageChunk for large pages are tens of MB
+type streamDecoder interface {
+	decodeStream(dec *json.Decoder) error
+}
+
+// isLogging returns true if responses are logged, which requires reading
+// them into memory
+func isLogging(client *Client) bool {
+	return client.Logger != nil || client.LeveledLogger != nil
+}
+
+// decodeStreamResponse decodes json body of rsp into result
+func decodeStreamResponse(c *Client, uri string, rsp *http.Response, result streamDecoder) error {
+	r := bufio.NewReader(rsp.Body)
+	// instead of an error, the server sometimes sends a login page
+	start, _ := r.Peek(512)
+	if isHTML(rsp, start) {
+		return fmt.Errorf("%s returned html page instead of json: %w", uri, ErrUnauthorized)
+	}
+	err := result.decodeStream(json.NewDecoder(r))
+	if err != nil {
+		logError(c, "Error: decoding json response of %s failed with %s\n", uri, err)
+		return err
+	}
+	// read the rest so that the connection can be re-used
+	_, err = io.Copy(ioutil.Discard, r)
+	return err
+}
+
+// decodeObject calls fn for every key of json object. fn must decode the
+// value with dec. null is the same as empty object
+func decodeObject(dec *json.Decoder, fn func(key string) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return fmt.Errorf("expected json object, got '%v'", tok)
+	}
+	for dec.More() {
+		tok, err = dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("expected key of json object, got '%v'", tok)
+		}
+		if err = fn(key); err != nil {
+			return err
+		}
+	}
+	// closing '}'
+	_, err = dec.Token()
+	return err
+}
+
+// skipValue skips the next json value
+func skipValue(dec *json.Decoder) error {
+	var v json.RawMessage
+	return dec.Decode(&v)
+}
+
+func (rsp *LoadPageChunkResponse) decodeStream(dec *json.Decoder) error {
+	return decodeObject(dec, func(key string) error {
+		switch key {
+		case "recordMap":
+			return rsp.RecordMap.decodeStream(dec)
+		case "cursor":
+			return dec.Decode(&rsp.Cursor)
+		}
+		return skipValue(dec)
+	})
+}
+
+// decodeStream decodes records of recordMap one at a time
+func (rm *RecordMap) decodeStream(dec *json.Decoder) error {
+	return decodeObject(dec, func(table string) error {
+		switch table {
+		case TableBlock:
+			if rm.Blocks == nil {
+				rm.Blocks = map[string]*BlockWithRole{}
+			}
+			return decodeObject(dec, func(id string) error {
+				var v *BlockWithRole
+				err := dec.Decode(&v)
+				rm.Blocks[id] = v
+				return err
+			})
+		case TableSpace:
+			if rm.Space == nil {
+				rm.Space = map[string]*SpaceWithRole{}
+			}
+			return decodeObject(dec, func(id string) error {
+				var v *SpaceWithRole
+				err := dec.Decode(&v)
+				rm.Space[id] = v
+				return err
+			})
+		case TableUser:
+			if rm.Users == nil {
+				rm.Users = map[string]*UserWithRole{}
+			}
+			return decodeObject(dec, func(id string) error {
+				var v *UserWithRole
+				err := dec.Decode(&v)
+				rm.Users[id] = v
+				return err
+			})
+		case TableBot:
+			if rm.Bots == nil {
+				rm.Bots = map[string]*UserWithRole{}
+			}
+			return decodeObject(dec, func(id string) error {
+				var v *UserWithRole
+				err := dec.Decode(&v)
+				rm.Bots[id] = v
+				return err
+			})
+		case TableCollection:
+			if rm.Collections == nil {
+				rm.Collections = map[string]*CollectionWithRole{}
+			}
+			return decodeObject(dec, func(id string) error {
+				var v *CollectionWithRole
+				err := dec.Decode(&v)
+				rm.Collections[id] = v
+				return err
+			})
+		case TableCollectionView:
+			if rm.CollectionViews == nil {
+				rm.CollectionViews = map[string]*CollectionViewWithRole{}
+			}
+			return decodeObject(dec, func(id string) error {
+				var v *CollectionViewWithRole
+				err := dec.Decode(&v)
+				rm.CollectionViews[id] = v
+				return err
+			})
+		}
+		return skipValue(dec)
+	})
+}
@@ -0,0 +1,125 @@
+package notionapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const recordMapStreamJSON = `{
+	"recordMap": {
+		"block": {
+			"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d": {
+				"role": "reader",
+				"value": { "id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", "type": "page", "alive": true, "version": 3, "content": ["a1b1c1d1-0000-4000-8000-000000000001"] }
+			},
+			"a1b1c1d1-0000-4000-8000-000000000001": { "role": "none" },
+			"a1b1c1d1-0000-4000-8000-000000000002": {
+				"role": "reader",
+				"value": { "id": "a1b1c1d1-0000-4000-8000-000000000002", "type": "future_block", "alive": true }
+			}
+		},
+		"space": {
+			"bc202e06-6caa-4e3f-81eb-f226ab5deef7": { "role": "reader", "value": { "id": "bc202e06-6caa-4e3f-81eb-f226ab5deef7", "name": "Team" } }
+		},
+		"notion_user": {
+			"bb760e2d-d679-4b64-b2a9-03005b21870a": { "role": "reader", "value": { "id": "bb760e2d-d679-4b64-b2a9-03005b21870a", "email": "kkowalczyk@gmail.com" } }
+		},
+		"bot": {
+			"2f3e4d5c-6b7a-4c8d-9e0f-1a2b3c4d5e6f": { "role": "reader", "value": { "id": "2f3e4d5c-6b7a-4c8d-9e0f-1a2b3c4d5e6f", "name": "Bot" } }
+		},
+		"collection": {
+			"e0b3c1a2-7d4f-4b8e-9a6c-1f2e3d4c5b60": { "role": "reader", "value": { "id": "e0b3c1a2-7d4f-4b8e-9a6c-1f2e3d4c5b60", "alive": true } }
+		},
+		"collection_view": {
+			"a3fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e1": { "role": "reader", "value": { "id": "a3fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e1", "type": "table", "alive": true } }
+		},
+		"discussion": {
+			"d1d1d1d1-0000-4000-8000-000000000001": { "role": "reader", "value": { "id": "d1d1d1d1-0000-4000-8000-000000000001", "comments": [] } }
+		}
+	},
+	"cursor": { "stack": [[{ "table": "block", "id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", "index": 1 }]] },
+	"extra": [1, {"a": null}]
+}`
+
+func TestLoadPageChunkStreamDecode(t *testing.T) {
+	for _, js := range []string{recordMapStreamJSON, string(largePageJSON(10)), `{"recordMap": null, "cursor": {"stack": []}}`} {
+		var exp LoadPageChunkResponse
+		assert.NoError(t, json.Unmarshal([]byte(js), &exp))
+		var got LoadPageChunkResponse
+		assert.NoError(t, got.decodeStream(json.NewDecoder(bytes.NewBufferString(js))))
+		if exp.RecordMap.Blocks == nil {
+			// null is decoded as empty
+			assert.Empty(t, got.RecordMap.Blocks)
+			continue
+		}
+		assert.Equal(t, exp, got)
+	}
+
+	var rsp LoadPageChunkResponse
+	err := rsp.decodeStream(json.NewDecoder(bytes.NewBufferString(`{"recordMap": {"block": []}}`)))
+	assert.Error(t, err)
+	err = rsp.decodeStream(json.NewDecoder(bytes.NewBufferString(`{"recordMap": {"block": {`)))
+	assert.Error(t, err)
+}
+
+func TestLoadPageChunkStreamHTML(t *testing.T) {
+	c, _ := newStaticClient(200, "\n<html><body>Log in</body></html>")
+	_, err := c.LoadPageChunk("4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", 0, nil)
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+
+	// with logging the response is read into memory first
+	c.Logger = ioutil.Discard
+	_, err = c.LoadPageChunk("4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", 0, nil)
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+}
+
+// largePageTransport returns the same large response for every request
+type largePageTransport struct {
+	body []byte
+}
+
+func (t *largePageTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewReader(t.body)),
+		Request:    r,
+	}, nil
+}
+
+// compares memory used by decoding a large loadPageChunk response when
+// it's streamed and when it's read into memory first, which is done when
+// responses are logged
+func BenchmarkLoadPageChunkDecode(b *testing.B) {
+	d := largePageJSON(5000)
+	bench := func(b *testing.B, c *Client) {
+		b.ReportAllocs()
+		b.SetBytes(int64(len(d)))
+		for i := 0; i < b.N; i++ {
+			_, err := c.LoadPageChunk("4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", 0, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	newClient := func() *Client {
+		return &Client{
+			HTTPClient:  &http.Client{Transport: &largePageTransport{body: d}},
+			RateLimiter: NewTokenBucket(1e9, 1000),
+		}
+	}
+	b.Run("stream", func(b *testing.B) {
+		bench(b, newClient())
+	})
+	b.Run("buffered", func(b *testing.B) {
+		c := newClient()
+		c.Logger = ioutil.Discard
+		bench(b, c)
+	})
+}
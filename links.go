@@ -0,0 +1,146 @@
+package notionapi
+
+import (
+	"net/url"
+	"sort"
+)
+
+const (
+	// LinkCategoryLink is LinkRef.Category of links in text, bookmarks and
+	// url properties
+	LinkCategoryLink = "link"
+	// LinkCategoryImage is LinkRef.Category of images, page covers and
+	// icons
+	LinkCategoryImage = "image"
+	// LinkCategoryFile is LinkRef.Category of files, pdfs, audio and
+	// uploaded videos
+	LinkCategoryFile = "file"
+	// LinkCategoryEmbed is LinkRef.Category of embeds, e.g. youtube videos
+	// or tweets
+	LinkCategoryEmbed = "embed"
+)
+
+// LinkRef is an url that a block of a page links to
+type LinkRef struct {
+	URL string
+	// block that has the link, a block of the page or a row of a table
+	BlockID string
+	// LinkCategoryLink, LinkCategoryImage, LinkCategoryFile or
+	// LinkCategoryEmbed
+	Category string
+	// if the link is in a property of the block (e.g. a column of a row
+	// or a caption), its name
+	Property string
+}
+
+// isExternalURL returns true for absolute urls, as opposed to links to
+// other pages like "/4c6a54c68b3e4ea2af9cfaabcc88d58d"
+func isExternalURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Scheme != "attachment"
+}
+
+type linkCollector struct {
+	links []LinkRef
+	seen  map[LinkRef]bool
+}
+
+func (c *linkCollector) add(b *Block, s string, category string, prop string) {
+	if !isExternalURL(s) {
+		return
+	}
+	link := LinkRef{URL: s, BlockID: b.ID, Category: category, Property: prop}
+	if c.seen[link] {
+		return
+	}
+	c.seen[link] = true
+	c.links = append(c.links, link)
+}
+
+// addProperties adds links in inline text of properties of the block,
+// e.g. the title or a caption. Properties are processed in sorted order
+// so that the result is stable
+func (c *linkCollector) addProperties(b *Block, urlColumns map[string]bool) {
+	var names []string
+	for name := range b.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop := name
+		if prop == "title" {
+			prop = ""
+		}
+		inline := getInlineProp(b, name)
+		if urlColumns[name] {
+			c.add(b, InlineToText(inline), LinkCategoryLink, prop)
+		}
+		for _, ib := range inline {
+			if ib.Link != "" {
+				c.add(b, ib.Link, LinkCategoryLink, prop)
+			}
+		}
+	}
+}
+
+func (c *linkCollector) addBlock(b *Block) {
+	for _, ib := range b.InlineContent {
+		if ib.Link != "" {
+			c.add(b, ib.Link, LinkCategoryLink, "")
+		}
+	}
+	c.addProperties(b, nil)
+	if f := b.FormatPage; f != nil {
+		c.add(b, f.PageCover, LinkCategoryImage, "")
+		c.add(b, f.PageIcon, LinkCategoryImage, "")
+	}
+	switch {
+	case b.Type == BlockBookmark:
+		c.add(b, b.BookmarkURL(), LinkCategoryLink, "")
+	case b.Type == BlockImage:
+		c.add(b, b.Source, LinkCategoryImage, "")
+	case b.Type == BlockFile || b.Type == BlockPDF || b.Type == BlockAudio:
+		c.add(b, b.Source, LinkCategoryFile, "")
+	case b.Type == BlockVideo:
+		if b.FormatVideo != nil && b.FormatVideo.IsUpload {
+			c.add(b, b.Source, LinkCategoryFile, "")
+		} else {
+			c.add(b, b.Source, LinkCategoryEmbed, "")
+		}
+	case b.IsEmbedBlock():
+		c.add(b, b.Source, LinkCategoryEmbed, "")
+		c.add(b, b.embedURL(), LinkCategoryEmbed, "")
+	}
+}
+
+// ExternalLinks returns urls that the page links to: links in text,
+// bookmarks, embeds, sources of images and files, page covers and icons
+// and links in properties of rows of tables, including values of url
+// columns. Links to other Notion pages by id are not included. Content
+// of sub-pages is not part of the page
+func (p *Page) ExternalLinks() []LinkRef {
+	c := &linkCollector{seen: map[LinkRef]bool{}}
+	p.ForEachBlockWithParent(func(b, parent *Block) bool {
+		c.addBlock(b)
+		return parent == nil || b.Type != BlockPage
+	})
+	seenRows := map[string]bool{}
+	for _, t := range p.Tables {
+		urlColumns := map[string]bool{}
+		if t.Collection != nil {
+			for id, col := range t.Collection.CollectionSchema {
+				if col.Type == ColumnTypeURL {
+					urlColumns[id] = true
+				}
+			}
+		}
+		for _, row := range t.Data {
+			if row == nil || seenRows[row.ID] {
+				continue
+			}
+			seenRows[row.ID] = true
+			c.addProperties(row, urlColumns)
+		}
+	}
+	return c.links
+}
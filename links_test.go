@@ -0,0 +1,64 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExternalLinks(t *testing.T) {
+	text := &Block{
+		ID:   "text",
+		Type: BlockText,
+		InlineContent: []*InlineBlock{
+			{Text: "site", Link: "https://example.com"},
+			{Text: "page", Link: "/4c6a54c68b3e4ea2af9cfaabcc88d58d"},
+		},
+	}
+	image := &Block{
+		ID:     "image",
+		Type:   BlockImage,
+		Source: "https://example.com/a.png",
+		Properties: map[string]interface{}{
+			"caption": []interface{}{[]interface{}{"by me", []interface{}{[]interface{}{"a", "https://me.com"}}}},
+		},
+	}
+	bookmark := &Block{ID: "bookmark", Type: BlockBookmark, Link: "https://blog.com"}
+	file := &Block{ID: "file", Type: BlockFile, Source: "https://s3.com/f.zip"}
+	tweet := &Block{ID: "tweet", Type: BlockTweet, Source: "https://twitter.com/x/status/1"}
+	sub := &Block{ID: "sub", Type: BlockPage, Content: []*Block{
+		{ID: "in-sub", Type: BlockBookmark, Link: "https://not-included.com"},
+	}}
+	page := testPage(text, image, bookmark, file, tweet, sub)
+	page.Root.FormatPage = &FormatPage{PageCover: "https://example.com/cover.jpg", PageIcon: "🚀"}
+
+	row := testRow("row", "")
+	row.Properties["url"] = []interface{}{[]interface{}{"https://row.com"}}
+	row.Properties["notes"] = []interface{}{
+		[]interface{}{"see", []interface{}{[]interface{}{"a", "https://notes.com"}}},
+	}
+	page.Tables = []*Table{
+		{
+			Collection: &Collection{
+				CollectionSchema: map[string]*CollectionColumnInfo{
+					"url":   {Name: "URL", Type: ColumnTypeURL},
+					"notes": {Name: "Notes", Type: ColumnTypeText},
+				},
+			},
+			Data: []*Block{row, row},
+		},
+	}
+
+	exp := []LinkRef{
+		{URL: "https://example.com/cover.jpg", BlockID: page.Root.ID, Category: LinkCategoryImage},
+		{URL: "https://example.com", BlockID: "text", Category: LinkCategoryLink},
+		{URL: "https://me.com", BlockID: "image", Category: LinkCategoryLink, Property: "caption"},
+		{URL: "https://example.com/a.png", BlockID: "image", Category: LinkCategoryImage},
+		{URL: "https://blog.com", BlockID: "bookmark", Category: LinkCategoryLink},
+		{URL: "https://s3.com/f.zip", BlockID: "file", Category: LinkCategoryFile},
+		{URL: "https://twitter.com/x/status/1", BlockID: "tweet", Category: LinkCategoryEmbed},
+		{URL: "https://notes.com", BlockID: "row", Category: LinkCategoryLink, Property: "notes"},
+		{URL: "https://row.com", BlockID: "row", Category: LinkCategoryLink, Property: "url"},
+	}
+	assert.Equal(t, exp, page.ExternalLinks())
+}
@@ -0,0 +1,117 @@
+package notionapi
+
+import "context"
+
+// HasUnloadedChildren returns true if some blocks in ContentIDs of the
+// block are not in Content because we didn't get their records, see
+// LoadChildren
+func (b *Block) HasUnloadedChildren() bool {
+	return len(b.MissingContentIDs) > 0
+}
+
+// LoadChildren gets children of the block that are missing from Content
+// (see HasUnloadedChildren) and inserts them into Content in the right
+// place. Children that the server doesn't return or that are deleted are
+// dropped. Children of loaded blocks might also be missing, in which case
+// they have HasUnloadedChildren
+func (b *Block) LoadChildren(client *Client) error {
+	return b.LoadChildrenCtx(context.Background(), client)
+}
+
+// LoadChildrenCtx is like LoadChildren but can be canceled with ctx
+func (b *Block) LoadChildrenCtx(ctx context.Context, client *Client) error {
+	if !b.HasUnloadedChildren() {
+		return nil
+	}
+	rsp, err := client.GetBlockRecordsCtx(ctx, b.MissingContentIDs)
+	if err != nil {
+		return err
+	}
+	idToBlock := map[string]*Block{}
+	for _, res := range rsp.Results {
+		if res.Value != nil && res.Value.Alive {
+			idToBlock[res.Value.ID] = res.Value
+		}
+	}
+	for _, child := range b.Content {
+		if child != nil {
+			idToBlock[child.ID] = child
+		}
+	}
+
+	var content []*Block
+	var ids []string
+	for _, id := range b.allContentIDs {
+		child := idToBlock[id]
+		if child == nil {
+			continue
+		}
+		content = append(content, child)
+		ids = append(ids, id)
+	}
+	for _, id := range b.MissingContentIDs {
+		child := idToBlock[id]
+		if child == nil {
+			continue
+		}
+		if child.Type == BlockPage && child.ParentID != "" && child.ParentID != b.ID {
+			// a link to a page that is a child of another block
+			child.AliasPageID = child.ID
+		}
+		if isListItem(child) && isListItem(b) {
+			child.ListNesting = b.ListNesting + 1
+		}
+		err = resolveBlocksRec(child, idToBlock, nil, map[string]bool{b.ID: true})
+		if err != nil {
+			return err
+		}
+		setParents(child, idToBlock)
+		child.Parent = b
+	}
+	b.Content = content
+	b.ContentIDs = ids
+	b.MissingContentIDs = nil
+	b.allContentIDs = nil
+	setListIndexes(b.Content)
+	if b.Type == BlockColumnList {
+		setColumnRatios(b)
+	}
+	for i, child := range b.Content {
+		setPositionsAt(child, b.Depth+1, i)
+	}
+	return nil
+}
+
+// loadMissingChildren calls LoadChildren for blocks of the page until
+// there are no blocks with unloaded children
+func (p *Page) loadMissingChildren(ctx context.Context, client *Client) error {
+	loaded := false
+	for {
+		var blocks []*Block
+		p.ForEachBlockWithParent(func(b, parent *Block) bool {
+			if b.HasUnloadedChildren() {
+				blocks = append(blocks, b)
+			}
+			// content of sub-pages is not part of this page
+			return parent == nil || b.Type != BlockPage
+		})
+		if len(blocks) == 0 {
+			break
+		}
+		for _, b := range blocks {
+			if err := b.LoadChildrenCtx(ctx, client); err != nil {
+				return err
+			}
+		}
+		loaded = true
+	}
+	if loaded {
+		// add loaded blocks to the index used by BlockByID
+		orphans := map[string]*Block{}
+		for _, b := range p.OrphanBlocks() {
+			orphans[b.ID] = b
+		}
+		p.setBlockIndex(orphans)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	loadChildrenRootID    = "c969c945-5d7c-4dd7-9c7f-860f3ace6429"
+	loadChildrenMissingID = "00000000-0000-0000-0000-000000000002"
+	loadChildrenGoneID    = "00000000-0000-0000-0000-000000000004"
+
+	loadChildrenJSON = `{
+	"recordMap": {
+		"block": {
+			"00000000-0000-0000-0000-000000000002": {
+				"role": "reader",
+				"value": {
+					"alive": true,
+					"id": "00000000-0000-0000-0000-000000000002",
+					"type": "toggle",
+					"parent_id": "c969c945-5d7c-4dd7-9c7f-860f3ace6429",
+					"parent_table": "block",
+					"properties": {"title": [["loaded"]]},
+					"content": ["00000000-0000-0000-0000-000000000005"]
+				}
+			}
+		}
+	}
+}`
+)
+
+func TestLoadChildren(t *testing.T) {
+	root := &Block{
+		ID:   loadChildrenRootID,
+		Type: BlockPage,
+		ContentIDs: []string{
+			"00000000-0000-0000-0000-000000000001",
+			loadChildrenMissingID,
+			loadChildrenGoneID,
+			"00000000-0000-0000-0000-000000000003",
+		},
+	}
+	first := &Block{ID: "00000000-0000-0000-0000-000000000001", Type: BlockText, ParentID: root.ID, ParentTable: TableBlock, Alive: true}
+	last := &Block{ID: "00000000-0000-0000-0000-000000000003", Type: BlockText, ParentID: root.ID, ParentTable: TableBlock, Alive: true}
+	idToBlock := map[string]*Block{root.ID: root, first.ID: first, last.ID: last}
+	assert.NoError(t, resolveBlocks(root, idToBlock, nil))
+	assert.True(t, root.HasUnloadedChildren())
+	assert.Equal(t, []string{loadChildrenMissingID, loadChildrenGoneID}, root.MissingContentIDs)
+	assert.Equal(t, []*Block{first, last}, root.Content)
+
+	c, _ := newStaticClient(200, loadChildrenJSON)
+	assert.NoError(t, root.LoadChildren(c))
+	assert.False(t, root.HasUnloadedChildren())
+	assert.Equal(t, 3, len(root.Content))
+	assert.Equal(t, []string{first.ID, loadChildrenMissingID, last.ID}, root.ContentIDs)
+
+	loaded := root.Content[1]
+	assert.Equal(t, loadChildrenMissingID, loaded.ID)
+	assert.Equal(t, "loaded", InlineToText(loaded.InlineContent))
+	assert.Equal(t, root, loaded.Parent)
+	assert.Equal(t, 1, loaded.Depth)
+	assert.Equal(t, 1, loaded.SiblingIndex)
+	assert.Equal(t, 2, last.SiblingIndex)
+	// its child wasn't returned and can be loaded later
+	assert.True(t, loaded.HasUnloadedChildren())
+
+	// nothing to do
+	assert.NoError(t, first.LoadChildren(c))
+}
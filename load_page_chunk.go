@@ -1,14 +1,29 @@
 package notionapi
 
+import "context"
+
 // /api/v3/loadPageChunk request
 type loadPageChunkRequest struct {
 	PageID          string `json:"pageId"`
-	ChunkNumber int `json:"chunkNumber"`
+	ChunkNumber     int    `json:"chunkNumber"`
 	Limit           int    `json:"limit"`
 	Cursor          cursor `json:"cursor"`
 	VerticalColumns bool   `json:"verticalColumns"`
 }
 
+// /api/v3/loadCachedPageChunk request
+type loadCachedPageChunkRequest struct {
+	Page            pageRef `json:"page"`
+	ChunkNumber     int     `json:"chunkNumber"`
+	Limit           int     `json:"limit"`
+	Cursor          cursor  `json:"cursor"`
+	VerticalColumns bool    `json:"verticalColumns"`
+}
+
+type pageRef struct {
+	ID string `json:"id"`
+}
+
 type cursor struct {
 	Stack [][]stack `json:"stack"`
 }
@@ -30,6 +45,7 @@ type RecordMap struct {
 	Blocks          map[string]*BlockWithRole          `json:"block"`
 	Space           map[string]*SpaceWithRole          `json:"space"`
 	Users           map[string]*UserWithRole           `json:"notion_user"`
+	Bots            map[string]*UserWithRole           `json:"bot"`
 	Collections     map[string]*CollectionWithRole     `json:"collection"`
 	CollectionViews map[string]*CollectionViewWithRole `json:"collection_view"`
 	// TDOO: there might be more records types
@@ -69,6 +85,17 @@ type CollectionView struct {
 	Query       *CollectionViewQuery  `json:"query"`
 	Type        string                `json:"type"`
 	Version     int                   `json:"version"`
+
+	// calculated by us from Format.TableProperties
+	TableProperties []TablePropertyFormat `json:"table_properties_resolved,omitempty"`
+}
+
+// TablePropertyFormat describes width, visibility and position of a column
+// in a table view. Columns are in the order they are displayed.
+type TablePropertyFormat struct {
+	PropertyID string `json:"property"`
+	Visible    bool   `json:"visible"`
+	Width      int    `json:"width"`
 }
 
 // CollectionViewFormat describes a fomrat of a collection view
@@ -80,6 +107,8 @@ type CollectionViewFormat struct {
 // CollectionViewQuery describes a query
 type CollectionViewQuery struct {
 	Aggregate []*AggregateQuery `json:"aggregate"`
+	// id of the column by which rows are grouped, e.g. in a board view
+	GroupBy string `json:"group_by,omitempty"`
 }
 
 // AggregateQuery describes an aggregate query
@@ -99,19 +128,50 @@ type CollectionWithRole struct {
 
 // Collection describes a collection
 type Collection struct {
-	Alive            bool                             `json:"alive"`
-	Format           *CollectionFormat                `json:"format"`
-	ID               string                           `json:"id"`
-	Name             [][]string                       `json:"name"`
+	Alive  bool              `json:"alive"`
+	Format *CollectionFormat `json:"format"`
+	ID     string            `json:"id"`
+	// it's an array of inline blocks, use Name() to get the text
+	NameRaw interface{} `json:"name"`
+	// emoji or url of the icon
+	Icon string `json:"icon,omitempty"`
+	// /images/page-cover/gradients_11.jpg or url of the cover image
+	Cover string `json:"cover,omitempty"`
+	// it's an array of inline blocks, parsed into Description
+	DescriptionRaw   interface{}                      `json:"description,omitempty"`
 	ParentID         string                           `json:"parent_id"`
 	ParentTable      string                           `json:"parent_table"`
 	CollectionSchema map[string]*CollectionColumnInfo `json:"schema"`
-	Version          int                              `json:"version"`
+	// ids of page blocks that are templates for new rows
+	TemplateIDs []string `json:"template_pages,omitempty"`
+	Version     int      `json:"version"`
+
+	// calculated by us
+	CoverURL    string         `json:"cover_url,omitempty"`
+	Description []*InlineBlock `json:"description_resolved,omitempty"`
+}
+
+// Name returns the name of the collection, including all its inline blocks
+func (c *Collection) Name() string {
+	if c.NameRaw == nil {
+		return ""
+	}
+	inline, err := parseInlineBlocks(c.NameRaw)
+	if err != nil {
+		return ""
+	}
+	s := ""
+	for _, b := range inline {
+		s += b.Text
+	}
+	return s
 }
 
 // CollectionFormat describes format of a collection
 type CollectionFormat struct {
 	CollectionPageProperties []*CollectionPageProperty `json:"collection_page_properties"`
+	// e.g. 0.6
+	CollectionCoverPosition float64 `json:"collection_cover_position,omitempty"`
 }
 
 // CollectionPageProperty describes properties of a collection
@@ -125,6 +185,9 @@ type CollectionColumnInfo struct {
 	Name    string                    `json:"name"`
 	Options []*CollectionColumnOption `json:"options"`
 	Type    string                    `json:"type"`
+
+	// calculated by us: the key of this column in Collection.CollectionSchema
+	ID string `json:"id,omitempty"`
 }
 
 // CollectionColumnOption describes options for a collection column
@@ -142,16 +205,32 @@ type UserWithRole struct {
 
 // User describes a user
 type User struct {
-	Email                     string `json:"email"`
-	FamilyName                string `json:"family_name"`
-	GivenName                 string `json:"given_name"`
-	ID                        string `json:"id"`
-	Locale                    string `json:"locale"`
-	MobileOnboardingCompleted bool   `json:"mobile_onboarding_completed"`
-	OnboardingCompleted       bool   `json:"onboarding_completed"`
-	ProfilePhoto              string `json:"profile_photo"`
-	TimeZone                  string `json:"time_zone"`
-	Version                   int    `json:"version"`
+	// not sent for users other than the current one, unless they share
+	// a workspace with us
+	Email      string `json:"email"`
+	FamilyName string `json:"family_name"`
+	GivenName  string `json:"given_name"`
+	// newer records have the full name here instead of GivenName and
+	// FamilyName
+	Name                       string `json:"name,omitempty"`
+	ID                         string `json:"id"`
+	Locale                     string `json:"locale"`
+	MobileOnboardingCompleted  bool   `json:"mobile_onboarding_completed"`
+	OnboardingCompleted        bool   `json:"onboarding_completed"`
+	ClipperOnboardingCompleted bool   `json:"clipper_onboarding_completed,omitempty"`
+	// url of the photo as stored by Notion, use ProfilePhotoURL
+	// to get a url that can be downloaded
+	ProfilePhoto string `json:"profile_photo"`
+	TimeZone     string `json:"time_zone"`
+	Version      int    `json:"version"`
+
+	// true if this is a bot (a record in TableBot), e.g. of an
+	// integration. Bots only have Name
+	IsBot bool `json:"is_bot,omitempty"`
+
+	// true if the user is a guest (not a member) of the workspace. Only
+	// set by Client.GetSpaceUsers
+	IsGuest bool `json:"-"`
 }
 
 // Date describes a date
@@ -180,26 +259,75 @@ type Reminder struct {
 
 // LoadPageChunk executes a raw API call /api/v3/loadPageChunk
 func (c *Client) LoadPageChunk(pageID string, chunkNo int, cur *cursor) (*LoadPageChunkResponse, error) {
+	return c.LoadPageChunkCtx(context.Background(), pageID, chunkNo, cur)
+}
+
+// LoadPageChunkCtx is like LoadPageChunk but can be canceled with ctx
+func (c *Client) LoadPageChunkCtx(ctx context.Context, pageID string, chunkNo int, cur *cursor) (*LoadPageChunkResponse, error) {
+	return c.loadPageChunk(ctx, pageID, chunkNo, cur, 0)
+}
+
+// isExhausted returns true if there are no more chunks to load
+func (c *cursor) isExhausted() bool {
+	for _, s := range c.Stack {
+		if len(s) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// loadPageChunk loads a chunk of up to limit blocks. If limit is 0 we use
+// the same limits as notion website
+func (c *Client) loadPageChunk(ctx context.Context, pageID string, chunkNo int, cur *cursor, limit int) (*LoadPageChunkResponse, error) {
 	// emulating notion's website api usage: 50 items on first request,
 	// 30 on subsequent requests
-	limit := 30
-	apiURL := "/api/v3/loadPageChunk"
+	defaultLimit := 30
 	if cur == nil {
 		cur = &cursor{
 			// to mimic browser api which sends empty array for this argment
 			Stack: make([][]stack, 0),
 		}
-		limit = 50
+		defaultLimit = 50
+	}
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+	if c.getCompat().UseLoadCachedPageChunk {
+		return c.loadCachedPageChunk(ctx, pageID, chunkNo, cur, limit)
 	}
 	req := &loadPageChunkRequest{
 		PageID:          pageID,
-		ChunkNumber: chunkNo,
+		ChunkNumber:     chunkNo,
+		Limit:           limit,
+		Cursor:          *cur,
+		VerticalColumns: false,
+	}
+	apiURL := "/api/v3/loadPageChunk"
+	var rsp LoadPageChunkResponse
+	err := doNotionAPI(ctx, c, apiURL, req, &rsp)
+	if err != nil {
+		if isAPIGone(err) && c.detectCompat(err, apiURL, func(compat *Compat) { compat.UseLoadCachedPageChunk = true }) {
+			return c.loadCachedPageChunk(ctx, pageID, chunkNo, cur, limit)
+		}
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// loadCachedPageChunk is like loadPageChunk but uses newer
+// /api/v3/loadCachedPageChunk api which returns the same response
+func (c *Client) loadCachedPageChunk(ctx context.Context, pageID string, chunkNo int, cur *cursor, limit int) (*LoadPageChunkResponse, error) {
+	req := &loadCachedPageChunkRequest{
+		Page:            pageRef{ID: pageID},
+		ChunkNumber:     chunkNo,
 		Limit:           limit,
 		Cursor:          *cur,
 		VerticalColumns: false,
 	}
+	apiURL := "/api/v3/loadCachedPageChunk"
 	var rsp LoadPageChunkResponse
-	err := doNotionAPI(c, apiURL, req, &rsp)
+	err := doNotionAPI(ctx, c, apiURL, req, &rsp)
 	if err != nil {
 		return nil, err
 	}
@@ -1,7 +1,12 @@
 package notionapi
 
 import (
+	"bytes"
 	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -151,3 +156,105 @@ func TestLoadPageChunk1(t *testing.T) {
 
 	}
 }
+
+const (
+	chunkedPageRootJSON = `{
+	"results": [
+		{
+			"role": "reader",
+			"value": {
+				"alive": true,
+				"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+				"content": [
+					"0d6b5fcc-3f80-4b8c-8b76-3c5b2c2f0001",
+					"0d6b5fcc-3f80-4b8c-8b76-3c5b2c2f0002",
+					"0d6b5fcc-3f80-4b8c-8b76-3c5b2c2f0003"
+				],
+				"parent_table": "block",
+				"type": "page"
+			}
+		}
+	]
+}`
+)
+
+// chunkedPageTransport serves a page whose blocks come in 3 chunks,
+// one block per chunk
+type chunkedPageTransport struct {
+	chunkRequests []*loadPageChunkRequest
+}
+
+func (t *chunkedPageTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, _ := readRequestBody(r)
+	s := toRecordMap(TableBlock, chunkedPageRootJSON)
+	if strings.HasSuffix(r.URL.Path, "/loadPageChunk") {
+		var req loadPageChunkRequest
+		json.Unmarshal([]byte(body), &req)
+		t.chunkRequests = append(t.chunkRequests, &req)
+		n := req.ChunkNumber + 1
+		stack := `[[{"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", "index": ` + strconv.Itoa(n) + `, "table": "block"}]]`
+		if n == 3 {
+			stack = `[]`
+		}
+		s = `{
+	"recordMap": {
+		"block": {
+			"0d6b5fcc-3f80-4b8c-8b76-3c5b2c2f000` + strconv.Itoa(n) + `": {
+				"role": "reader",
+				"value": {
+					"alive": true,
+					"id": "0d6b5fcc-3f80-4b8c-8b76-3c5b2c2f000` + strconv.Itoa(n) + `",
+					"parent_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+					"parent_table": "block",
+					"type": "text",
+					"properties": { "title": [["chunk ` + strconv.Itoa(n) + `"]] }
+				}
+			}
+		}
+	},
+	"cursor": { "stack": ` + stack + ` }
+}`
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(s)),
+		Request:    r,
+	}, nil
+}
+
+func TestDownloadPageManyChunks(t *testing.T) {
+	transport := &chunkedPageTransport{}
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RateLimiter: NewTokenBucket(1000, 10),
+	}
+	var progress []int
+	var done []bool
+	opts := &DownloadPageOptions{
+		ChunkSize: 1,
+		Progress: func(downloadedBlocks int, isDone bool) {
+			progress = append(progress, downloadedBlocks)
+			done = append(done, isDone)
+		},
+	}
+	page, err := c.DownloadPageWithOptions("4c6a54c68b3e4ea2af9cfaabcc88d58d", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(transport.chunkRequests))
+	for i, req := range transport.chunkRequests {
+		assert.Equal(t, i, req.ChunkNumber)
+		assert.Equal(t, 1, req.Limit)
+	}
+	assert.Equal(t, 3, len(page.Root.Content))
+	for i, block := range page.Root.Content {
+		assert.Equal(t, "chunk "+strconv.Itoa(i+1), block.InlineContent[0].Text)
+	}
+	assert.Equal(t, []int{1, 2, 3, 3}, progress)
+	assert.Equal(t, []bool{false, false, false, true}, done)
+}
+
+func TestCursorIsExhausted(t *testing.T) {
+	assert.True(t, (&cursor{}).isExhausted())
+	assert.True(t, (&cursor{Stack: [][]stack{{}}}).isExhausted())
+	assert.False(t, (&cursor{Stack: [][]stack{{{ID: "a"}}}}).isExhausted())
+}
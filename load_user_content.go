@@ -0,0 +1,79 @@
+package notionapi
+
+import (
+	"context"
+	"errors"
+	"sort"
+)
+
+var (
+	// ErrNoAuthToken is returned by calls that require Client.AuthToken
+	ErrNoAuthToken = errors.New("Client.AuthToken is not set")
+	// ErrInvalidToken is returned when the server rejects Client.AuthToken
+	// e.g. because it expired
+	ErrInvalidToken = errors.New("invalid or expired token_v2")
+)
+
+// LoadUserContentResponse is a response to /api/v3/loadUserContent api
+type LoadUserContentResponse struct {
+	RecordMap RecordMap `json:"recordMap"`
+}
+
+// LoadUserContent executes a raw API call /api/v3/loadUserContent
+func (c *Client) LoadUserContent() (*LoadUserContentResponse, error) {
+	return c.LoadUserContentCtx(context.Background())
+}
+
+// LoadUserContentCtx is like LoadUserContent but can be canceled with ctx
+func (c *Client) LoadUserContentCtx(ctx context.Context) (*LoadUserContentResponse, error) {
+	req := struct{}{}
+	apiURL := "/api/v3/loadUserContent"
+	var rsp LoadUserContentResponse
+	err := doNotionAPI(ctx, c, apiURL, req, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	return &rsp, nil
+}
+
+// GetCurrentUser returns the user that Client.AuthToken belongs to and
+// workspaces the user is a member of, sorted by name. It's a cheap way to
+// verify that the token is valid. Returns ErrInvalidToken if it isn't
+func (c *Client) GetCurrentUser() (*User, []*Space, error) {
+	return c.GetCurrentUserCtx(context.Background())
+}
+
+// GetCurrentUserCtx is like GetCurrentUser but can be canceled with ctx
+func (c *Client) GetCurrentUserCtx(ctx context.Context) (*User, []*Space, error) {
+	if c.AuthToken == "" {
+		return nil, nil, ErrNoAuthToken
+	}
+	rsp, err := c.LoadUserContentCtx(ctx)
+	if err != nil {
+		if IsUnauthorized(err) {
+			return nil, nil, ErrInvalidToken
+		}
+		return nil, nil, err
+	}
+	// for invalid token the server returns empty recordMap
+	var user *User
+	for _, u := range rsp.RecordMap.Users {
+		if u.Value != nil {
+			user = u.Value
+			break
+		}
+	}
+	if user == nil {
+		return nil, nil, ErrInvalidToken
+	}
+	var spaces []*Space
+	for _, s := range rsp.RecordMap.Space {
+		if s.Value != nil {
+			spaces = append(spaces, s.Value)
+		}
+	}
+	sort.Slice(spaces, func(i, j int) bool {
+		return spaces[i].Name < spaces[j].Name
+	})
+	return user, spaces, nil
+}
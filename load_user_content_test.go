@@ -0,0 +1,70 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	loadUserContentJSON = `{
+	"recordMap": {
+		"notion_user": {
+			"bb760e2d-d679-4b64-b2a9-03005b21870a": {
+				"role": "reader",
+				"value": {
+					"id": "bb760e2d-d679-4b64-b2a9-03005b21870a",
+					"email": "kkowalczyk@gmail.com",
+					"given_name": "Krzysztof",
+					"family_name": "Kowalczyk",
+					"locale": "en",
+					"time_zone": "America/Los_Angeles"
+				}
+			}
+		},
+		"space": {
+			"bc202e06-6caa-4e3f-81eb-f226ab5deef7": {
+				"role": "editor",
+				"value": {
+					"id": "bc202e06-6caa-4e3f-81eb-f226ab5deef7",
+					"name": "Work",
+					"pages": ["300db9dc-27c8-4958-a08b-8d0c37f4cfe5"]
+				}
+			},
+			"6a38ad16-f7f5-4a4a-9c2d-5b5ac9d1a2e1": {
+				"role": "editor",
+				"value": {
+					"id": "6a38ad16-f7f5-4a4a-9c2d-5b5ac9d1a2e1",
+					"name": "Personal"
+				}
+			}
+		}
+	}
+}`
+)
+
+func TestGetCurrentUser(t *testing.T) {
+	c, _ := newStaticClient(200, loadUserContentJSON)
+	user, spaces, err := c.GetCurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, "bb760e2d-d679-4b64-b2a9-03005b21870a", user.ID)
+	assert.Equal(t, "kkowalczyk@gmail.com", user.Email)
+	assert.Equal(t, 2, len(spaces))
+	assert.Equal(t, "Personal", spaces[0].Name)
+	assert.Equal(t, "Work", spaces[1].Name)
+	assert.Equal(t, "bc202e06-6caa-4e3f-81eb-f226ab5deef7", spaces[1].ID)
+}
+
+func TestGetCurrentUserInvalidToken(t *testing.T) {
+	c, _ := newStaticClient(401, `{"errorId":"x","name":"UnauthorizedError"}`)
+	_, _, err := c.GetCurrentUser()
+	assert.Equal(t, ErrInvalidToken, err)
+
+	c, _ = newStaticClient(200, `{"recordMap":{}}`)
+	_, _, err = c.GetCurrentUser()
+	assert.Equal(t, ErrInvalidToken, err)
+
+	c.AuthToken = ""
+	_, _, err = c.GetCurrentUser()
+	assert.Equal(t, ErrNoAuthToken, err)
+}
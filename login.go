@@ -0,0 +1,102 @@
+package notionapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrWrongCredentials is returned by LoginWithEmail when email or
+	// password is not correct
+	ErrWrongCredentials = errors.New("wrong email or password")
+	// ErrLoginNotSupported is returned by LoginWithEmail for accounts that
+	// require signing in with SSO (e.g. Google or SAML) or two-factor
+	// authentication, which we can't do
+	ErrLoginNotSupported = errors.New("account requires SSO or two-factor authentication which is not supported")
+)
+
+type loginWithEmailRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// isUnsupportedLoginMessage returns true if the message from the server
+// says that the account requires a sign in method we don't support
+func isUnsupportedLoginMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, s := range []string{"sso", "saml", "single sign-on", "google", "apple", "two-factor", "2fa", "verification code"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// LoginWithEmail signs in with email and password and returns the value
+// of token_v2 cookie which can be used as Client.AuthToken.
+// Returns an error wrapping ErrWrongCredentials or ErrLoginNotSupported
+// (use errors.Is) if the sign in failed
+func (c *Client) LoginWithEmail(email, password string) (string, error) {
+	return c.LoginWithEmailCtx(context.Background(), email, password)
+}
+
+// LoginWithEmailCtx is like LoginWithEmail but can be canceled with ctx
+func (c *Client) LoginWithEmailCtx(ctx context.Context, email, password string) (string, error) {
+	js, err := json.Marshal(&loginWithEmailRequest{
+		Email:    email,
+		Password: password,
+	})
+	if err != nil {
+		return "", err
+	}
+	uri := c.apiEndpointURL(apiLoginWithEmail)
+	// don't log the request, it contains the password
+	log(c, "POST %s\n", uri)
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", uri, bytes.NewReader(js))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent)
+		req.Header.Set("Accept-Language", acceptLang)
+		return req, nil
+	}
+	rsp, err := c.doHTTP(ctx, newRequest, false, c.getRequestTimeout())
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+	d, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return "", err
+	}
+	if rsp.StatusCode != 200 {
+		errRsp := newAPIError(uri, rsp.StatusCode, d)
+		logError(c, "Error: login failed with status code %s, message: '%s'\n", rsp.Status, errRsp.Message)
+		if rsp.StatusCode >= 500 {
+			return "", errRsp
+		}
+		if isUnsupportedLoginMessage(errRsp.Message) {
+			return "", fmt.Errorf("%w: %s", ErrLoginNotSupported, errRsp.Message)
+		}
+		if errRsp.Message != "" {
+			return "", fmt.Errorf("%w: %s", ErrWrongCredentials, errRsp.Message)
+		}
+		return "", ErrWrongCredentials
+	}
+	for _, cookie := range rsp.Cookies() {
+		if cookie.Name == "token_v2" && cookie.Value != "" {
+			return cookie.Value, nil
+		}
+	}
+	// the server accepted the password but didn't sign us in, which
+	// happens when it wants another login step
+	return "", ErrLoginNotSupported
+}
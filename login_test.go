@@ -0,0 +1,65 @@
+package notionapi
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type loginTransport struct {
+	statusCode int
+	body       string
+	cookie     string
+}
+
+func (t *loginTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	header := http.Header{}
+	if t.cookie != "" {
+		header.Add("Set-Cookie", t.cookie)
+	}
+	return &http.Response{
+		StatusCode: t.statusCode,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(t.body)),
+		Request:    r,
+	}, nil
+}
+
+func loginWith(t *loginTransport) (string, error) {
+	c := &Client{
+		HTTPClient: &http.Client{Transport: t},
+	}
+	return c.LoginWithEmail("me@example.com", "password")
+}
+
+func TestLoginWithEmail(t *testing.T) {
+	token, err := loginWith(&loginTransport{
+		statusCode: 200,
+		body:       `{}`,
+		cookie:     "token_v2=abc123; Path=/; HttpOnly",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", token)
+
+	_, err = loginWith(&loginTransport{
+		statusCode: 400,
+		body:       `{"errorId":"1","name":"UserValidationError","message":"Incorrect password."}`,
+	})
+	assert.True(t, errors.Is(err, ErrWrongCredentials))
+
+	_, err = loginWith(&loginTransport{
+		statusCode: 400,
+		body:       `{"errorId":"2","name":"UserValidationError","message":"Please sign in with SAML SSO."}`,
+	})
+	assert.True(t, errors.Is(err, ErrLoginNotSupported))
+
+	_, err = loginWith(&loginTransport{
+		statusCode: 200,
+		body:       `{}`,
+	})
+	assert.Equal(t, ErrLoginNotSupported, err)
+}
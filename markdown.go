@@ -0,0 +1,646 @@
+package notionapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// MarkdownOptions allows customizing ToMarkdown
+type MarkdownOptions struct {
+	// RewriteImageURL, if set, returns url of the image to use in markdown
+	// e.g. to point to a local copy of the image
+	RewriteImageURL func(block *Block, uri string) string
+	// RewriteFileURL, if set, returns url of the file attachment to use
+	RewriteFileURL func(block *Block, uri string) string
+	// PageURL, if set, returns url used for links to a page with a given id.
+	// By default we link to the page on notion.so
+	PageURL func(pageID string) string
+	// if true, links to sub-pages and linked pages are not included
+	SkipSubPages bool
+	// CodeLanguages overrides or extends mapping of Notion code block
+	// languages to highlighter names (see CodeLanguageToHighlightName)
+	CodeLanguages map[string]string
+	// if > 0, tables show at most this many rows
+	MaxTableRows int
+	// if true, toggles and toggleable headers are rendered as html
+	// <details> element. By default a toggle is a list item with bold text
+	// and its content is indented under it
+	ToggleAsDetails bool
+	// Renderers are consulted in order before default rendering of
+	// a block. Their output is indented to match nesting of the block
+	Renderers []BlockRenderer
+	// RenderUnknownBlock, if set, renders blocks we don't know how to render,
+	// like blocks of types added by Notion after this library was written
+	// (see Block.IsUnknownType). Its output is indented like output of
+	// Renderers. By default we write an html comment
+	RenderUnknownBlock func(w io.Writer, block *Block)
+	// Math sets delimiters of equations. Default is MathDelimitersDollars
+	Math *MathDelimiters
+	// if true, markdown starts with yaml front matter (see FrontMatter)
+	FrontMatter bool
+	// FrontMatterTable, if set, is a table whose collection has the page
+	// as a row. Values of its columns are included in front matter
+	FrontMatterTable *Table
+}
+
+var (
+	markdownEscaper = strings.NewReplacer(
+		`\`, `\\`,
+		"*", `\*`,
+		"_", `\_`,
+		"`", "\\`",
+		"[", `\[`,
+		"]", `\]`,
+	)
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// InlineToMarkdown converts inline blocks to markdown. Mentions of pages
+// are links to notion.so
+func InlineToMarkdown(blocks []*InlineBlock) string {
+	return inlineToMarkdown(resolvePageMentions(blocks, nil, notionPageURL), nil)
+}
+
+func inlineToMarkdown(blocks []*InlineBlock, math *MathDelimiters) string {
+	var buf bytes.Buffer
+	for _, b := range blocks {
+		buf.WriteString(inlineBlockToMarkdown(b, math))
+	}
+	return buf.String()
+}
+
+func inlineBlockToMarkdown(b *InlineBlock, math *MathDelimiters) string {
+	var s string
+	switch {
+	case b.Equation != "":
+		s = inlineEquationToMarkdown(b.Equation, math)
+	case b.UserID != "":
+		s = "@" + b.UserID
+	case b.Date != nil:
+		s = dateToString(b.Date)
+	case b.AttrFlags&AttrCode != 0:
+		s = b.Text
+	default:
+		s = escapeMarkdown(b.Text)
+	}
+	if s == "" {
+		return ""
+	}
+	// markdown doesn't allow spaces inside emphasis markers so we move
+	// them outside
+	trimmed := strings.TrimSpace(s)
+	if trimmed == "" {
+		return s
+	}
+	idx := strings.Index(s, trimmed)
+	prefix, suffix := s[:idx], s[idx+len(trimmed):]
+	s = trimmed
+	if b.AttrFlags&AttrCode != 0 {
+		s = "`" + s + "`"
+	}
+	if b.AttrFlags&AttrStrikeThrought != 0 {
+		s = "~~" + s + "~~"
+	}
+	if b.AttrFlags&AttrItalic != 0 {
+		s = "_" + s + "_"
+	}
+	if b.AttrFlags&AttrBold != 0 {
+		s = "**" + s + "**"
+	}
+	if b.Link != "" {
+		s = "[" + s + "](" + b.Link + ")"
+	}
+	return prefix + s + suffix
+}
+
+type markdownConverter struct {
+	page *Page
+	opts *MarkdownOptions
+	buf  *bytes.Buffer
+	// prefix for every line, grows when rendering nested blocks
+	indent string
+}
+
+// ToMarkdown converts a page to markdown
+func ToMarkdown(p *Page, opts *MarkdownOptions) []byte {
+	if opts == nil {
+		opts = &MarkdownOptions{}
+	}
+	c := &markdownConverter{
+		page: p,
+		opts: opts,
+		buf:  &bytes.Buffer{},
+	}
+	return c.convert()
+}
+
+func (c *markdownConverter) convert() []byte {
+	root := c.page.Root
+	if c.opts.FrontMatter {
+		c.buf.Write(FrontMatter(c.page, c.opts.FrontMatterTable))
+		c.buf.WriteString("\n")
+	}
+	renderPageStart(c.opts.Renderers, c.buf, c.page)
+	if root.Title != "" {
+		c.printf("# %s\n\n", escapeMarkdown(root.Title))
+	}
+	c.renderBlocks(root.Content)
+	renderPageEnd(c.opts.Renderers, c.buf, c.page)
+	return c.buf.Bytes()
+}
+
+func (c *markdownConverter) inline(blocks []*InlineBlock) string {
+	blocks = resolvePageMentions(blocks, c.page.mentionedPageTitle, c.pageURL)
+	return inlineToMarkdown(blocks, c.opts.Math)
+}
+
+func (c *markdownConverter) printf(format string, args ...interface{}) {
+	fmt.Fprintf(c.buf, format, args...)
+}
+
+// writeLines writes s with c.indent at the start of every line
+func (c *markdownConverter) writeLines(s string) {
+	s = strings.TrimRight(s, "\n")
+	for _, line := range strings.Split(s, "\n") {
+		if line == "" {
+			c.buf.WriteString(strings.TrimRight(c.indent, " ") + "\n")
+			continue
+		}
+		c.buf.WriteString(c.indent + line + "\n")
+	}
+}
+
+func (c *markdownConverter) pageURL(pageID string) string {
+	if c.opts.PageURL != nil {
+		return c.opts.PageURL(pageID)
+	}
+	return "https://www.notion.so/" + strings.Replace(pageID, "-", "", -1)
+}
+
+func (c *markdownConverter) imageURL(block *Block, uri string) string {
+	if c.opts.RewriteImageURL != nil {
+		return c.opts.RewriteImageURL(block, uri)
+	}
+	return uri
+}
+
+func (c *markdownConverter) fileURL(block *Block, uri string) string {
+	if c.opts.RewriteFileURL != nil {
+		return c.opts.RewriteFileURL(block, uri)
+	}
+	return uri
+}
+
+func (c *markdownConverter) isListBlock(b *Block) bool {
+	switch b.Type {
+	case BlockBulletedList, BlockNumberedList, BlockTodo:
+		return true
+	case BlockToggle:
+		return !c.opts.ToggleAsDetails
+	}
+	return false
+}
+
+func (c *markdownConverter) renderBlocks(blocks []*Block) {
+	listNo := 0
+	for i, block := range blocks {
+		if block == nil {
+			continue
+		}
+		if block.Type == BlockNumberedList {
+			listNo++
+			if block.ListIndex > 0 {
+				listNo = block.ListIndex
+			}
+		} else {
+			listNo = 0
+		}
+		c.renderBlock(block, listNo)
+		// items of the same list are not separated by an empty line
+		if c.isListBlock(block) && i+1 < len(blocks) {
+			next := blocks[i+1]
+			if next != nil && next.Type == block.Type {
+				continue
+			}
+		}
+		c.emptyLine()
+	}
+}
+
+// emptyLine separates blocks with an empty line, unless there already is one
+func (c *markdownConverter) emptyLine() {
+	empty := strings.TrimRight(c.indent, " ") + "\n"
+	d := c.buf.Bytes()
+	if len(d) == 0 || bytes.HasSuffix(d, []byte("\n"+empty)) {
+		return
+	}
+	c.buf.WriteString(empty)
+}
+
+// renders content of a block indented under a list item
+func (c *markdownConverter) renderChildren(block *Block, indent string) {
+	if len(block.Content) == 0 {
+		return
+	}
+	prev := c.indent
+	c.indent += indent
+	c.renderBlocks(block.Content)
+	c.indent = prev
+}
+
+// renderQuote renders text and blocks prefixed with "> "
+func (c *markdownConverter) renderQuote(text string, blocks []*Block) {
+	prev := c.indent
+	c.indent += "> "
+	c.writeLines(text)
+	if len(blocks) > 0 {
+		c.emptyLine()
+		c.renderBlocks(blocks)
+		// remove the empty line (i.e. ">") after the last block
+		empty := strings.TrimRight(c.indent, " ") + "\n"
+		if d := c.buf.Bytes(); bytes.HasSuffix(d, []byte("\n"+empty)) {
+			c.buf.Truncate(len(d) - len(empty))
+		}
+	}
+	c.indent = prev
+}
+
+// renderDetails renders toggleable block as <details> element. Markdown
+// inside html blocks must be separated by empty lines
+func (c *markdownConverter) renderDetails(block *Block, summary string) {
+	c.writeLines("<details>")
+	c.writeLines("<summary>" + summary + "</summary>")
+	c.writeLines("")
+	c.renderBlocks(block.Content)
+	c.emptyLine()
+	c.writeLines("</details>")
+}
+
+func (c *markdownConverter) renderHeader(block *Block, tag string, prefix string) {
+	if !block.IsToggleable() {
+		c.writeLines(prefix + c.inline(block.InlineContent))
+		return
+	}
+	if c.opts.ToggleAsDetails {
+		summary := fmt.Sprintf("<%s>%s</%s>", tag, InlineToHTML(block.InlineContent), tag)
+		c.renderDetails(block, summary)
+		return
+	}
+	// markdown headers can't have indented content so it follows the header
+	c.writeLines(prefix + c.inline(block.InlineContent))
+	if len(block.Content) > 0 {
+		c.emptyLine()
+		c.renderBlocks(block.Content)
+	}
+}
+
+func (c *markdownConverter) renderListItem(block *Block, marker string) {
+	c.writeLines(marker + c.inline(block.InlineContent))
+	c.renderChildren(block, "    ")
+}
+
+// escapes text so that it can be a cell in a markdown table
+func escapeMarkdownTableCell(s string) string {
+	return markdownTableCell(escapeMarkdown(s))
+}
+
+// markdownTableCell makes markdown s safe to use in a table cell
+func markdownTableCell(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	s = strings.Replace(s, "\r\n", "<br>", -1)
+	return strings.Replace(s, "\n", "<br>", -1)
+}
+
+func (c *markdownConverter) renderTable(table *Table) {
+	cols := table.VisibleColumns()
+	if len(cols) == 0 {
+		return
+	}
+	if table.Collection != nil {
+		if name := table.Collection.Name(); name != "" {
+			c.writeLines("**" + escapeMarkdown(name) + "**")
+			c.writeLines("")
+		}
+	}
+	var names, seps []string
+	for _, col := range cols {
+		names = append(names, escapeMarkdownTableCell(col.Name))
+		seps = append(seps, "---")
+	}
+	c.writeLines("| " + strings.Join(names, " | ") + " |")
+	c.writeLines("| " + strings.Join(seps, " | ") + " |")
+	rows := table.Rows()
+	nRows := len(rows)
+	if c.opts.MaxTableRows > 0 && nRows > c.opts.MaxTableRows {
+		rows = rows[:c.opts.MaxTableRows]
+	}
+	for _, row := range rows {
+		var cells []string
+		for _, col := range cols {
+			cells = append(cells, escapeMarkdownTableCell(row.ValueString(col)))
+		}
+		c.writeLines("| " + strings.Join(cells, " | ") + " |")
+	}
+	if n := nRows - len(rows); n > 0 {
+		c.writeLines("")
+		c.writeLines(fmt.Sprintf("… %d more rows", n))
+	}
+}
+
+// renderSimpleTable renders BlockTable (as opposed to a database). Markdown
+// tables must have a header so if the table doesn't have one, it's empty
+func (c *markdownConverter) renderSimpleTable(block *Block) {
+	rows := block.TableCells()
+	if len(rows) == 0 || len(rows[0]) == 0 {
+		return
+	}
+	toCells := func(row [][]*InlineBlock) []string {
+		var res []string
+		for _, cell := range row {
+			res = append(res, markdownTableCell(c.inline(cell)))
+		}
+		return res
+	}
+	header := make([]string, len(rows[0]))
+	if f := block.FormatTableBlock; f != nil && f.TableBlockColumnHeader {
+		header = toCells(rows[0])
+		rows = rows[1:]
+	}
+	seps := make([]string, len(header))
+	for i := range seps {
+		seps[i] = "---"
+	}
+	c.writeLines("| " + strings.Join(header, " | ") + " |")
+	c.writeLines("| " + strings.Join(seps, " | ") + " |")
+	for _, row := range rows {
+		c.writeLines("| " + strings.Join(toCells(row), " | ") + " |")
+	}
+}
+
+// bookmark is rendered as a quote with a link and description
+func (c *markdownConverter) renderBookmark(block *Block) {
+	uri := block.BookmarkURL()
+	if !block.hasBookmarkMetadata() {
+		c.writeLines(fmt.Sprintf("[%s](%s)", escapeMarkdown(uri), uri))
+		return
+	}
+	title := c.inline(block.InlineContent)
+	if title == "" {
+		title = escapeMarkdown(uri)
+	}
+	prev := c.indent
+	c.indent += "> "
+	c.writeLines(fmt.Sprintf("**[%s](%s)**", title, uri))
+	if block.Description != "" {
+		c.writeLines("")
+		c.writeLines(escapeMarkdown(block.Description))
+	}
+	c.indent = prev
+}
+
+// markdown doesn't have columns so we render them one after another,
+// marked with comments so that tools can detect them
+func (c *markdownConverter) renderColumnList(block *Block) {
+	n := countColumns(block)
+	c.writeLines("<!-- column-list -->")
+	c.writeLines("")
+	for _, col := range block.Content {
+		if col == nil {
+			continue
+		}
+		if col.Type != BlockColumn {
+			c.renderBlock(col, 0)
+			c.emptyLine()
+			continue
+		}
+		c.writeLines(fmt.Sprintf("<!-- column width:%.4g%% -->", columnWidth(col, n)))
+		c.writeLines("")
+		c.renderBlocks(col.Content)
+		c.emptyLine()
+	}
+	c.writeLines("<!-- /column-list -->")
+}
+
+func (c *markdownConverter) renderUnsupported(block *Block) {
+	if c.opts.RenderUnknownBlock != nil {
+		var buf bytes.Buffer
+		c.opts.RenderUnknownBlock(&buf, block)
+		if buf.Len() > 0 {
+			c.writeLines(buf.String())
+		}
+		return
+	}
+	c.writeLines(fmt.Sprintf("<!-- unsupported block type '%s', id: %s -->", block.Type, block.ID))
+}
+
+// renderWithRenderers returns true if one of the renderers rendered the
+// block. Renderers can also write content before a block without handling it
+func (c *markdownConverter) renderWithRenderers(block *Block) bool {
+	if len(c.opts.Renderers) == 0 {
+		return false
+	}
+	var buf bytes.Buffer
+	handled := renderWithRenderers(c.opts.Renderers, &buf, block)
+	if buf.Len() > 0 {
+		c.writeLines(buf.String())
+	}
+	return handled
+}
+
+func (c *markdownConverter) renderBlock(block *Block, listNo int) {
+	if c.renderWithRenderers(block) {
+		return
+	}
+	switch block.Type {
+	case BlockText:
+		if len(block.InlineContent) > 0 {
+			c.writeLines(c.inline(block.InlineContent))
+		}
+		if len(block.Content) > 0 {
+			c.emptyLine()
+			c.renderBlocks(block.Content)
+		}
+	case BlockHeader:
+		c.renderHeader(block, "h1", "# ")
+	case BlockSubHeader:
+		c.renderHeader(block, "h2", "## ")
+	case BlockSubSubHeader:
+		c.renderHeader(block, "h3", "### ")
+	case BlockBulletedList:
+		c.renderListItem(block, "- ")
+	case BlockToggle:
+		if c.opts.ToggleAsDetails {
+			c.renderDetails(block, InlineToHTML(block.InlineContent))
+			return
+		}
+		c.writeLines("- **" + c.inline(block.InlineContent) + "**")
+		c.renderChildren(block, "    ")
+	case BlockNumberedList:
+		c.renderListItem(block, fmt.Sprintf("%d. ", listNo))
+	case BlockTodo:
+		marker := "- [ ] "
+		if block.IsChecked {
+			marker = "- [x] "
+		}
+		c.renderListItem(block, marker)
+	case BlockQuote:
+		c.renderQuote(c.inline(block.InlineContent), block.Content)
+	case BlockCallout:
+		// rendered as a quote, images can't be used as an icon
+		text := c.inline(block.InlineContent)
+		if icon := calloutIcon(block); icon != "" && !isIconURL(icon) {
+			text = icon + " " + text
+		}
+		c.renderQuote(text, block.Content)
+	case BlockCode:
+		fence := codeFence(block.Code)
+		c.writeLines(fence + codeLanguageName(block.CodeLanguage, c.opts.CodeLanguages))
+		c.writeLines(block.Code)
+		c.writeLines(fence)
+	case BlockEquation:
+		// placeholder equation blocks are empty
+		if block.Equation != "" {
+			c.writeLines(blockEquationToMarkdown(block.Equation, c.opts.Math))
+		}
+	case BlockDivider:
+		c.writeLines("---")
+	case BlockImage:
+		uri := c.imageURL(block, block.ImageURL)
+		c.writeLines(fmt.Sprintf("![](%s)", uri))
+	case BlockBookmark:
+		c.renderBookmark(block)
+	case BlockFile, BlockAudio:
+		uri := c.fileURL(block, block.Source)
+		c.writeLines(fmt.Sprintf("[%s](%s)", escapeMarkdown(block.Source), uri))
+	case BlockPDF:
+		uri := c.fileURL(block, block.Source)
+		c.writeLines(fmt.Sprintf("[%s](%s)", escapeMarkdown(block.fileName()), uri))
+	case BlockDrive:
+		title, uri := block.driveTitleAndURL()
+		c.writeLines(fmt.Sprintf("[%s](%s)", escapeMarkdown(title), uri))
+	case BlockGist:
+		uri := block.GistURL()
+		c.writeLines(fmt.Sprintf("[%s](%s)", escapeMarkdown(uri), uri))
+	case BlockEmbed, BlockVideo, BlockFigma, BlockTweet, BlockMaps, BlockCodepen:
+		c.writeLines(fmt.Sprintf("[%s](%s)", escapeMarkdown(block.Source), block.Source))
+	case BlockPage:
+		if c.opts.SkipSubPages && block.AliasPageID == "" {
+			return
+		}
+		title := block.Title
+		if title == "" {
+			title = "Untitled"
+		}
+		c.writeLines(fmt.Sprintf("[%s](%s)", escapeMarkdown(title), c.pageURL(block.ID)))
+	case BlockAlias:
+		title := block.AliasTitle
+		if title == "" {
+			title = "Untitled"
+		}
+		c.writeLines(fmt.Sprintf("[%s](%s)", escapeMarkdown(title), c.pageURL(block.AliasPageID)))
+	case BlockColumnList:
+		c.renderColumnList(block)
+	case BlockColumn, BlockTransclusionContainer, BlockTransclusionReference:
+		c.renderBlocks(block.Content)
+	case BlockTable:
+		c.renderSimpleTable(block)
+	case BlockCollectionView:
+		for i, info := range block.CollectionViews {
+			if i > 0 {
+				c.writeLines("")
+			}
+			c.renderTable(tableFromCollectionViewInfo(info))
+		}
+	case BlockTableOfContents:
+		c.renderTableOfContents()
+	case BlockBreadcrumb:
+		c.renderBreadcrumb()
+	case BlockFactory:
+		// templates are not part of the content
+		c.writeLines(c.inline(block.InlineContent))
+	case BlockComment:
+		// comments are not part of the content
+	default:
+		if block.IsEmbedBlock() {
+			c.writeLines(fmt.Sprintf("[%s](%s)", escapeMarkdown(block.Source), block.Source))
+			return
+		}
+		c.renderUnsupported(block)
+	}
+}
+
+// renderBreadcrumb renders Page.Ancestors and the page, with links to pages
+func (c *markdownConverter) renderBreadcrumb() {
+	var parts []string
+	for _, a := range c.page.Ancestors {
+		title := escapeMarkdown(ancestorTitle(a))
+		if a.Table == TableBlock {
+			title = fmt.Sprintf("[%s](%s)", title, c.pageURL(a.ID))
+		}
+		parts = append(parts, title)
+	}
+	title := c.page.Root.Title
+	if title == "" {
+		title = "Untitled"
+	}
+	parts = append(parts, escapeMarkdown(title))
+	c.writeLines(strings.Join(parts, " / "))
+}
+
+// markdownAnchor returns anchor of a header with text s, the same as
+// generated by GitHub. seen counts anchors so far, to make them unique
+func markdownAnchor(s string, seen map[string]int) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	anchor := b.String()
+	n := seen[anchor]
+	seen[anchor] = n + 1
+	if n > 0 {
+		return fmt.Sprintf("%s-%d", anchor, n)
+	}
+	return anchor
+}
+
+// renderTableOfContents renders links to all headers of the page as
+// a nested list
+func (c *markdownConverter) renderTableOfContents() {
+	seen := map[string]int{}
+	for _, h := range findHeaders(c.page.Root.Content, nil) {
+		text := InlineToText(h.InlineContent)
+		indent := strings.Repeat("    ", tocIndent(h))
+		c.writeLines(fmt.Sprintf("%s- [%s](#%s)", indent, escapeMarkdown(text), markdownAnchor(text, seen)))
+	}
+}
+
+// codeFence returns a fence for a code block that is longer than any run
+// of backticks in code, so that code can't end the block
+func codeFence(code string) string {
+	longest, n := 0, 0
+	for _, r := range code {
+		if r != '`' {
+			n = 0
+			continue
+		}
+		n++
+		if n > longest {
+			longest = n
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
@@ -0,0 +1,469 @@
+package notionapi
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	mdHeadingRx   = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)(?:[ \t]+#+)?[ \t]*$`)
+	mdListItemRx  = regexp.MustCompile(`^( *)([-*+]|\d{1,9}[.)])(?:( +)(.*))?$`)
+	mdImageRx     = regexp.MustCompile(`^!\[([^\]]*)\]\(\s*(\S+?)(?:\s+"[^"]*")?\s*\)$`)
+	mdTableSepRx  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	mdTaskPrefixs = []string{"[ ] ", "[x] ", "[X] "}
+)
+
+// ImportMarkdown converts markdown to blocks that can be created with
+// Page.AppendBlocks. It supports headings, paragraphs, nested lists, task
+// lists, fenced code blocks, quotes, thematic breaks, images and inline
+// emphasis, code and links. Tables are imported as code blocks
+func ImportMarkdown(md []byte) ([]*NewBlock, error) {
+	s := strings.Replace(string(md), "\r\n", "\n", -1)
+	s = strings.Replace(s, "\t", "    ", -1)
+	return parseMarkdownBlocks(strings.Split(s, "\n")), nil
+}
+
+// AppendMarkdown converts markdown to blocks with ImportMarkdown and
+// creates them at the end of the page
+func (p *Page) AppendMarkdown(md []byte) error {
+	blocks, err := ImportMarkdown(md)
+	if err != nil {
+		return err
+	}
+	return p.AppendBlocks(blocks)
+}
+
+func mdIndent(s string) int {
+	return len(s) - len(strings.TrimLeft(s, " "))
+}
+
+func isMdBlank(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
+
+// returns fence ("```" or "~~~" of any length) and language of code block
+func mdCodeFence(trimmed string) (string, string, bool) {
+	for _, c := range []string{"`", "~"} {
+		if !strings.HasPrefix(trimmed, c+c+c) {
+			continue
+		}
+		fence := trimmed[:len(trimmed)-len(strings.TrimLeft(trimmed, c))]
+		lang := strings.TrimSpace(trimmed[len(fence):])
+		if c == "`" && strings.Contains(lang, "`") {
+			return "", "", false
+		}
+		if fields := strings.Fields(lang); len(fields) > 0 {
+			lang = fields[0]
+		}
+		return fence, lang, true
+	}
+	return "", "", false
+}
+
+func isMdThematicBreak(trimmed string) bool {
+	s := strings.Replace(trimmed, " ", "", -1)
+	if len(s) < 3 {
+		return false
+	}
+	c := s[0]
+	if c != '-' && c != '*' && c != '_' {
+		return false
+	}
+	return strings.Count(s, string(c)) == len(s)
+}
+
+type mdListItem struct {
+	ordered bool
+	// position of the text of the list item
+	contentIndent int
+	text          string
+}
+
+func parseMdListItem(line string) *mdListItem {
+	m := mdListItemRx.FindStringSubmatch(line)
+	if m == nil {
+		return nil
+	}
+	spaces := len(m[3])
+	if m[4] == "" && spaces > 1 {
+		spaces = 1
+	}
+	// 5 or more spaces after the marker means indented code, which we
+	// don't support, so treat it as a single space
+	if spaces > 4 {
+		spaces = 1
+	}
+	marker := m[2]
+	return &mdListItem{
+		ordered:       marker[0] >= '0' && marker[0] <= '9',
+		contentIndent: len(m[1]) + len(marker) + spaces,
+		text:          m[4],
+	}
+}
+
+// returns true if line starts a block other than a paragraph
+func isMdBlockStart(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if _, _, ok := mdCodeFence(trimmed); ok {
+		return true
+	}
+	return mdHeadingRx.MatchString(trimmed) || isMdThematicBreak(trimmed) ||
+		strings.HasPrefix(trimmed, ">") || parseMdListItem(line) != nil
+}
+
+func newMdTextBlock(typ string, text string) *NewBlock {
+	return &NewBlock{
+		Type:          typ,
+		InlineContent: parseMarkdownInline(text),
+	}
+}
+
+func parseMarkdownBlocks(lines []string) []*NewBlock {
+	var res []*NewBlock
+	i := 0
+	for i < len(lines) {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			i++
+			continue
+		}
+
+		if fence, lang, ok := mdCodeFence(trimmed); ok {
+			indent := mdIndent(line)
+			var code []string
+			i++
+			for i < len(lines) {
+				l := lines[i]
+				if strings.HasPrefix(strings.TrimSpace(l), fence) && strings.Trim(strings.TrimSpace(l), fence[:1]) == "" {
+					i++
+					break
+				}
+				// remove indentation of the opening fence
+				n := mdIndent(l)
+				if n > indent {
+					n = indent
+				}
+				code = append(code, l[n:])
+				i++
+			}
+			b := &NewBlock{
+				Type:         BlockCode,
+				Code:         strings.Join(code, "\n"),
+				CodeLanguage: CodeLanguageFromHighlightName(lang),
+			}
+			res = append(res, b)
+			continue
+		}
+
+		if m := mdHeadingRx.FindStringSubmatch(trimmed); m != nil {
+			typ := BlockSubSubHeader
+			switch len(m[1]) {
+			case 1:
+				typ = BlockHeader
+			case 2:
+				typ = BlockSubHeader
+			}
+			res = append(res, newMdTextBlock(typ, m[2]))
+			i++
+			continue
+		}
+
+		if isMdThematicBreak(trimmed) {
+			res = append(res, &NewBlock{Type: BlockDivider})
+			i++
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, ">") {
+			var quote []string
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">") {
+				l := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quote = append(quote, strings.TrimPrefix(l, " "))
+				i++
+			}
+			res = append(res, newMdTextBlock(BlockQuote, strings.Join(quote, "\n")))
+			continue
+		}
+
+		if item := parseMdListItem(line); item != nil {
+			var b *NewBlock
+			b, i = parseMdListItemBlock(lines, i, item)
+			res = append(res, b)
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && mdTableSepRx.MatchString(strings.TrimSpace(lines[i+1])) {
+			// tables are not supported so we import them as code to
+			// not lose the data
+			var table []string
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|") {
+				table = append(table, strings.TrimSpace(lines[i]))
+				i++
+			}
+			b := &NewBlock{
+				Type:         BlockCode,
+				Code:         strings.Join(table, "\n"),
+				CodeLanguage: "Plain Text",
+			}
+			res = append(res, b)
+			continue
+		}
+
+		if m := mdImageRx.FindStringSubmatch(trimmed); m != nil {
+			res = append(res, &NewBlock{Type: BlockImage, Source: m[2]})
+			i++
+			continue
+		}
+
+		// paragraph continues until an empty line or a start of a different
+		// block. Lines are joined with a space, like markdown renderers do
+		var para []string
+		for i < len(lines) && !isMdBlank(lines[i]) {
+			if len(para) > 0 && isMdBlockStart(lines[i]) {
+				break
+			}
+			para = append(para, strings.TrimSpace(lines[i]))
+			i++
+		}
+		res = append(res, newMdTextBlock(BlockText, strings.Join(para, " ")))
+	}
+	return res
+}
+
+// parseMdListItemBlock parses list item starting at lines[i] together with
+// its nested content. Returns the block and index of the next line
+func parseMdListItemBlock(lines []string, i int, item *mdListItem) (*NewBlock, int) {
+	texts := []string{item.text}
+	var children []string
+	i++
+	for i < len(lines) {
+		l := lines[i]
+		if isMdBlank(l) {
+			// blank line is part of the item only if followed by content
+			// indented under the item
+			j := i + 1
+			for j < len(lines) && isMdBlank(lines[j]) {
+				j++
+			}
+			if j == len(lines) || mdIndent(lines[j]) < item.contentIndent {
+				break
+			}
+			children = append(children, "")
+			i++
+			continue
+		}
+		if mdIndent(l) >= item.contentIndent {
+			children = append(children, l[item.contentIndent:])
+			i++
+			continue
+		}
+		// lazy continuation of the text of the item
+		if len(children) == 0 && !isMdBlockStart(l) {
+			texts = append(texts, strings.TrimSpace(l))
+			i++
+			continue
+		}
+		break
+	}
+
+	text := strings.Join(texts, " ")
+	b := &NewBlock{Type: BlockBulletedList}
+	if item.ordered {
+		b.Type = BlockNumberedList
+	} else {
+		for _, prefix := range mdTaskPrefixs {
+			if strings.HasPrefix(text+" ", prefix) {
+				b.Type = BlockTodo
+				b.IsChecked = prefix != "[ ] "
+				text = strings.TrimPrefix(text, prefix[:3])
+				text = strings.TrimPrefix(text, " ")
+				break
+			}
+		}
+	}
+	b.InlineContent = parseMarkdownInline(text)
+	b.Children = parseMarkdownBlocks(children)
+	return b, i
+}
+
+func isMdPunct(c byte) bool {
+	return strings.IndexByte("\\`*_{}[]()#+-.!~|<>$\"'", c) >= 0
+}
+
+func isMdAlnum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// addInline appends text with given formatting, merging it with the
+// previous block if it has the same formatting
+func addInline(res []*InlineBlock, text string, flags AttrFlag, link string) []*InlineBlock {
+	if text == "" {
+		return res
+	}
+	if n := len(res); n > 0 {
+		last := res[n-1]
+		if last.AttrFlags == flags && last.Link == link {
+			last.Text += text
+			return res
+		}
+	}
+	b := &InlineBlock{
+		Text:      text,
+		AttrFlags: flags,
+		Link:      link,
+	}
+	return append(res, b)
+}
+
+// findMdClosingDelim returns position of delim closing emphasis that starts
+// at s[start] or -1
+func findMdClosingDelim(s string, start int, delim string) int {
+	for j := start; j < len(s); j++ {
+		c := s[j]
+		if c == '\\' {
+			j++
+			continue
+		}
+		if c == '`' {
+			if end := strings.IndexByte(s[j+1:], '`'); end >= 0 {
+				j += end + 1
+			}
+			continue
+		}
+		if !strings.HasPrefix(s[j:], delim) {
+			continue
+		}
+		if len(delim) == 1 && j+1 < len(s) && s[j+1] == delim[0] {
+			// part of a double delimiter e.g. ** inside *italic*
+			j++
+			continue
+		}
+		if j > start && s[j-1] != ' ' {
+			return j
+		}
+	}
+	return -1
+}
+
+// findMdClosingBracket returns position of ']' matching '[' at s[start]
+func findMdClosingBracket(s string, start int) int {
+	depth := 0
+	for j := start; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// parseMarkdownInline converts markdown inline formatting to inline blocks
+func parseMarkdownInline(s string) []*InlineBlock {
+	return parseMarkdownInlineInto(nil, s, 0, "")
+}
+
+func parseMarkdownInlineInto(res []*InlineBlock, s string, flags AttrFlag, link string) []*InlineBlock {
+	var text strings.Builder
+	flush := func() {
+		res = addInline(res, text.String(), flags, link)
+		text.Reset()
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch c {
+		case '\\':
+			if i+1 < len(s) && isMdPunct(s[i+1]) {
+				text.WriteByte(s[i+1])
+				i += 2
+				continue
+			}
+		case '`':
+			n := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+			delim := s[i : i+n]
+			if end := strings.Index(s[i+n:], delim); end >= 0 {
+				flush()
+				code := s[i+n : i+n+end]
+				if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				res = addInline(res, code, flags|AttrCode, link)
+				i += n + end + n
+				continue
+			}
+		case '<':
+			if end := strings.IndexByte(s[i:], '>'); end > 0 {
+				uri := s[i+1 : i+end]
+				if (strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://")) && !strings.Contains(uri, " ") {
+					flush()
+					res = addInline(res, uri, flags, uri)
+					i += end + 1
+					continue
+				}
+			}
+		case '!', '[':
+			// images inside text become links
+			start := i
+			if c == '!' {
+				start = i + 1
+			}
+			if start < len(s) && s[start] == '[' {
+				end := findMdClosingBracket(s, start)
+				if end > 0 && end+1 < len(s) && s[end+1] == '(' {
+					if paren := strings.IndexByte(s[end+2:], ')'); paren >= 0 {
+						uri := strings.TrimSpace(s[end+2 : end+2+paren])
+						if fields := strings.Fields(uri); len(fields) > 0 {
+							uri = strings.Trim(fields[0], "<>")
+						}
+						flush()
+						res = parseMarkdownInlineInto(res, s[start+1:end], flags, uri)
+						i = end + 2 + paren + 1
+						continue
+					}
+				}
+			}
+		case '*', '_', '~':
+			delim := ""
+			var flag AttrFlag
+			if i+1 < len(s) && s[i+1] == c {
+				delim = s[i : i+2]
+				flag = AttrBold
+				if c == '~' {
+					flag = AttrStrikeThrought
+				}
+			} else if c != '~' {
+				delim = s[i : i+1]
+				flag = AttrItalic
+			}
+			// _ inside a word like snake_case is not emphasis
+			intraword := c == '_' && i > 0 && isMdAlnum(s[i-1])
+			if delim != "" && !intraword {
+				start := i + len(delim)
+				if start < len(s) && s[start] != ' ' {
+					if end := findMdClosingDelim(s, start, delim); end > 0 {
+						flush()
+						res = parseMarkdownInlineInto(res, s[start:end], flags|flag, link)
+						i = end + len(delim)
+						continue
+					}
+				}
+			}
+			if delim != "" {
+				text.WriteString(delim)
+				i += len(delim)
+				continue
+			}
+		}
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+	return res
+}
@@ -0,0 +1,119 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMarkdownInline(t *testing.T) {
+	got := parseMarkdownInline("Text with **bold [link](https://a.com)**, _italic_, ~~gone~~, `a*b`, snake_case and \\*stars\\*")
+	exp := []*InlineBlock{
+		{Text: "Text with "},
+		{Text: "bold ", AttrFlags: AttrBold},
+		{Text: "link", AttrFlags: AttrBold, Link: "https://a.com"},
+		{Text: ", "},
+		{Text: "italic", AttrFlags: AttrItalic},
+		{Text: ", "},
+		{Text: "gone", AttrFlags: AttrStrikeThrought},
+		{Text: ", "},
+		{Text: "a*b", AttrFlags: AttrCode},
+		{Text: ", snake_case and *stars*"},
+	}
+	assert.Equal(t, exp, got)
+
+	got = parseMarkdownInline("*a **b** c* and 2 * 3")
+	exp = []*InlineBlock{
+		{Text: "a ", AttrFlags: AttrItalic},
+		{Text: "b", AttrFlags: AttrItalic | AttrBold},
+		{Text: " c", AttrFlags: AttrItalic},
+		{Text: " and 2 * 3"},
+	}
+	assert.Equal(t, exp, got)
+}
+
+func TestInlineBlocksToJSON(t *testing.T) {
+	blocks := parseMarkdownInline("plain **bold** [link](https://a.com)")
+	d, err := json.Marshal(InlineBlocksToJSON(blocks))
+	assert.NoError(t, err)
+	assert.Equal(t, `[["plain "],["bold",[["b"]]],[" "],["link",[["a","https://a.com"]]]]`, string(d))
+
+	// parsing serialized blocks returns the original blocks
+	var raw interface{}
+	err = json.Unmarshal(d, &raw)
+	assert.NoError(t, err)
+	parsed, err := parseInlineBlocks(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, blocks, parsed)
+}
+
+const markdownToImport = `# Title
+
+First paragraph
+continues here.
+
+- item
+  - nested **item**
+- [x] done
+- [ ] todo
+
+1. one
+2. two
+
+> quote
+
+` + "```go\nfmt.Println(1)\n```" + `
+
+---
+
+![image](https://a.com/i.png)
+
+| a | b |
+|---|---|
+| 1 | 2 |
+`
+
+func TestImportMarkdown(t *testing.T) {
+	blocks, err := ImportMarkdown([]byte(markdownToImport))
+	assert.NoError(t, err)
+	var types []string
+	for _, b := range blocks {
+		types = append(types, b.Type)
+	}
+	exp := []string{
+		BlockHeader, BlockText, BlockBulletedList, BlockTodo, BlockTodo,
+		BlockNumberedList, BlockNumberedList, BlockQuote, BlockCode,
+		BlockDivider, BlockImage, BlockCode,
+	}
+	assert.Equal(t, exp, types)
+
+	assert.Equal(t, "First paragraph continues here.", InlineToText(blocks[1].InlineContent))
+	list := blocks[2]
+	assert.Equal(t, 1, len(list.Children))
+	assert.Equal(t, BlockBulletedList, list.Children[0].Type)
+	assert.Equal(t, "nested item", InlineToText(list.Children[0].InlineContent))
+	assert.True(t, blocks[3].IsChecked)
+	assert.Equal(t, "done", InlineToText(blocks[3].InlineContent))
+	assert.False(t, blocks[4].IsChecked)
+	assert.Equal(t, "fmt.Println(1)", blocks[8].Code)
+	assert.Equal(t, "Go", blocks[8].CodeLanguage)
+	assert.Equal(t, "https://a.com/i.png", blocks[10].Source)
+	assert.Equal(t, "| a | b |\n|---|---|\n| 1 | 2 |", blocks[11].Code)
+}
+
+func TestBuildNewBlocksOps(t *testing.T) {
+	blocks, err := ImportMarkdown([]byte("- a\n    - b\n\ntext"))
+	assert.NoError(t, err)
+	ops := buildNewBlocksOps("parent", blocks)
+	assert.Equal(t, 6, len(ops))
+	assert.Equal(t, "set", ops[0].Command)
+	assert.Equal(t, "listAfter", ops[1].Command)
+	assert.Equal(t, "parent", ops[1].ID)
+	// nested item is created inside the first item
+	parentID := ops[0].ID
+	assert.Equal(t, parentID, ops[2].Args.(map[string]interface{})["parent_id"])
+	assert.Equal(t, parentID, ops[3].ID)
+	assert.Equal(t, "parent", ops[5].ID)
+	assert.Equal(t, 36, len(parentID))
+}
@@ -0,0 +1,296 @@
+package notionapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func textBlock(typ string, s string, children ...*Block) *Block {
+	return &Block{
+		Type:          typ,
+		InlineContent: []*InlineBlock{{Text: s}},
+		Content:       children,
+	}
+}
+
+func testPage(blocks ...*Block) *Page {
+	root := &Block{
+		ID:      "c969c945-5d7c-4dd7-9c7f-860f3ace6429",
+		Type:    BlockPage,
+		Title:   "Test page",
+		Content: blocks,
+	}
+	return &Page{
+		ID:   root.ID,
+		Root: root,
+	}
+}
+
+func TestInlineToMarkdown(t *testing.T) {
+	blocks := parseBlocks(t, titleBig)
+	got := InlineToMarkdown(blocks)
+	exp := "Text block with **bold** [**link inside bold**](https://www.google.com) **text**, _italic text_, ~~strikethrough text~~, `code part`, [link part](http://blog.kowalczyk.info) , @bb760e2d-d679-4b64-b2a9-03005b21870a and 2018-07-17 15:00 and that's it."
+	assert.Equal(t, exp, got)
+}
+
+func TestToMarkdown(t *testing.T) {
+	todo := textBlock(BlockTodo, "done")
+	todo.IsChecked = true
+	code := &Block{
+		Type:         BlockCode,
+		Code:         "fmt.Println(\"hi\")",
+		CodeLanguage: "Go",
+	}
+	page := testPage(
+		textBlock(BlockHeader, "Header"),
+		textBlock(BlockText, "Some *text*"),
+		textBlock(BlockBulletedList, "one", textBlock(BlockBulletedList, "nested")),
+		textBlock(BlockBulletedList, "two"),
+		textBlock(BlockNumberedList, "first"),
+		textBlock(BlockNumberedList, "second"),
+		todo,
+		textBlock(BlockTodo, "not done"),
+		textBlock(BlockQuote, "quote"),
+		code,
+		&Block{Type: BlockDivider},
+		&Block{Type: BlockPage, ID: "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", Title: "Sub page"},
+		&Block{Type: "new_fancy_block", ID: "1"},
+	)
+	got := string(ToMarkdown(page, nil))
+	exp := `# Test page
+
+# Header
+
+Some \*text\*
+
+- one
+    - nested
+
+- two
+
+1. first
+2. second
+
+- [x] done
+- [ ] not done
+
+> quote
+
+` + "```go\nfmt.Println(\"hi\")\n```" + `
+
+---
+
+[Sub page](https://www.notion.so/4c6a54c68b3e4ea2af9cfaabcc88d58d)
+
+<!-- unsupported block type 'new_fancy_block', id: 1 -->
+
+`
+	assert.Equal(t, exp, got)
+
+	got = string(ToMarkdown(page, &MarkdownOptions{SkipSubPages: true}))
+	assert.NotContains(t, got, "Sub page")
+}
+
+func TestToMarkdownTable(t *testing.T) {
+	table := parseTestTable(t)
+	row := testRow("2", "Todo")
+	row.Properties["title"] = []interface{}{[]interface{}{"a | b\nnext line"}}
+	block := &Block{
+		Type: BlockCollectionView,
+		CollectionViews: []*CollectionViewInfo{
+			{
+				CollectionView: table.CollectionView,
+				Collection:     table.Collection,
+				CollectionRows: []*Block{testRow("1", "Done"), row, testRow("3", "")},
+			},
+		},
+	}
+	got := string(ToMarkdown(testPage(block), &MarkdownOptions{MaxTableRows: 2}))
+	exp := `# Test page
+
+**My Tasks**
+
+| Name | Status |
+| --- | --- |
+| Row 1 | Done |
+| a \| b<br>next line | Todo |
+
+… 1 more rows
+
+`
+	assert.Equal(t, exp, got)
+}
+
+func TestToMarkdownToggle(t *testing.T) {
+	inner := textBlock(BlockToggle, "inner", textBlock(BlockText, "hidden"))
+	header := textBlock(BlockSubHeader, "Header", textBlock(BlockText, "under header"))
+	header.FormatHeader = &FormatHeader{Toggleable: true}
+	page := testPage(
+		textBlock(BlockToggle, "outer", inner),
+		header,
+	)
+	got := string(ToMarkdown(page, nil))
+	exp := `# Test page
+
+- **outer**
+    - **inner**
+        hidden
+
+## Header
+
+under header
+
+`
+	assert.Equal(t, exp, got)
+
+	got = string(ToMarkdown(page, &MarkdownOptions{ToggleAsDetails: true}))
+	exp = `# Test page
+
+<details>
+<summary>outer</summary>
+
+<details>
+<summary>inner</summary>
+
+hidden
+
+</details>
+
+</details>
+
+<details>
+<summary><h2>Header</h2></summary>
+
+under header
+
+</details>
+
+`
+	assert.Equal(t, exp, got)
+}
+
+func TestToMarkdownColumns(t *testing.T) {
+	got := string(ToMarkdown(testColumnsPage(), nil))
+	exp := `# Test page
+
+<!-- column-list -->
+
+<!-- column width:25% -->
+
+left
+
+<!-- column width:75% -->
+
+<!-- column-list -->
+
+<!-- column width:50% -->
+
+a
+
+<!-- column width:50% -->
+
+b
+
+<!-- /column-list -->
+
+<!-- /column-list -->
+
+`
+	assert.Equal(t, exp, got)
+}
+
+func TestToMarkdownBookmark(t *testing.T) {
+	rich, plain := testBookmarks()
+	got := string(ToMarkdown(testPage(rich, plain), nil))
+	exp := `# Test page
+
+> **[Blog](https://blog.kowalczyk.info)**
+>
+> Articles & notes
+
+[https://example.com](https://example.com)
+
+`
+	assert.Equal(t, exp, got)
+}
+
+func TestToMarkdownCodeFence(t *testing.T) {
+	code := &Block{
+		Type:         BlockCode,
+		Code:         "Use ``` to start\n````\ncode",
+		CodeLanguage: "Markdown",
+	}
+	got := string(ToMarkdown(testPage(code), nil))
+	exp := "# Test page\n\n`````markdown\nUse ``` to start\n````\ncode\n`````\n\n"
+	assert.Equal(t, exp, got)
+
+	assert.Equal(t, "```", codeFence("a `b` ``c``"))
+	assert.Equal(t, "````", codeFence("```"))
+}
+
+func TestToMarkdownTableOfContents(t *testing.T) {
+	toc := parseTestBlock(t, `{"id": "1", "type": "table_of_contents", "alive": true, "format": {"block_color": "gray"}}`)
+	assert.Equal(t, "gray", *toc.FormatTableOfContents.BlockColor)
+	page := testPage(
+		toc,
+		textBlock(BlockHeader, "Intro"),
+		textBlock(BlockSubHeader, "Intro"),
+		textBlock(BlockSubSubHeader, "Set up & run"),
+	)
+	got := string(ToMarkdown(page, nil))
+	exp := `# Test page
+
+- [Intro](#intro)
+    - [Intro](#intro-1)
+        - [Set up & run](#set-up--run)
+
+# Intro
+`
+	assert.True(t, strings.HasPrefix(got, exp), "got:\n%s", got)
+
+	got = string(ToHTML(page, &HTMLOptions{Fragment: true}))
+	assert.Contains(t, got, `<nav class="table_of_contents block-color-gray">`)
+}
+
+const (
+	mentionedPageID = "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"
+	unknownPageID   = "bb760e2d-d679-4b64-b2a9-03005b21870a"
+)
+
+// testMentionPage returns a page with a sub-page and a paragraph that
+// mentions it and a page that is not part of the page
+func testMentionPage() *Page {
+	sub := textBlock(BlockPage, "")
+	sub.ID = mentionedPageID
+	sub.Title = "Sub page"
+	text := &Block{
+		ID:   "1",
+		Type: BlockText,
+		InlineContent: []*InlineBlock{
+			{Text: "See "},
+			{Text: InlineAt, PageID: mentionedPageID},
+			{Text: " and "},
+			{Text: InlineAt, PageID: unknownPageID, AttrFlags: AttrBold},
+		},
+	}
+	return testPage(text, sub)
+}
+
+func TestToMarkdownPageMention(t *testing.T) {
+	got := string(ToMarkdown(testMentionPage(), nil))
+	assert.Contains(t, got, "See [Sub page](https://www.notion.so/4c6a54c68b3e4ea2af9cfaabcc88d58d) and [**Untitled**](https://www.notion.so/bb760e2dd6794b64b2a903005b21870a)\n")
+	assert.NotContains(t, got, InlineAt)
+
+	opts := &MarkdownOptions{
+		PageURL: func(pageID string) string {
+			return "/" + pageID
+		},
+	}
+	got = string(ToMarkdown(testMentionPage(), opts))
+	assert.Contains(t, got, "See [Sub page](/"+mentionedPageID+")")
+
+	got = InlineToMarkdown(testMentionPage().Root.Content[0].InlineContent)
+	assert.Equal(t, "See [Untitled](https://www.notion.so/4c6a54c68b3e4ea2af9cfaabcc88d58d) and [**Untitled**](https://www.notion.so/bb760e2dd6794b64b2a903005b21870a)", got)
+}
@@ -0,0 +1,109 @@
+package notionapi
+
+// forEachInlineBlock calls fn for inline blocks of text of the block and
+// of its properties (e.g. a caption or a person or relation column of
+// a row) until fn returns false
+func forEachInlineBlock(b *Block, fn func(ib *InlineBlock) bool) {
+	for _, ib := range b.InlineContent {
+		if !fn(ib) {
+			return
+		}
+	}
+	for name := range b.Properties {
+		for _, ib := range getInlineProp(b, name) {
+			if !fn(ib) {
+				return
+			}
+		}
+	}
+}
+
+// hasMention returns true if inline text of the block or any of its
+// properties has a mention matching fn
+func hasMention(b *Block, fn func(ib *InlineBlock) bool) bool {
+	found := false
+	forEachInlineBlock(b, func(ib *InlineBlock) bool {
+		found = fn(ib)
+		return !found
+	})
+	return found
+}
+
+// mentions returns blocks of the page and rows of its tables that have a
+// mention matching fn, in document order with rows last
+func (p *Page) mentions(fn func(ib *InlineBlock) bool) []*Block {
+	res := p.FindBlocks(func(b *Block) bool {
+		return hasMention(b, fn)
+	})
+	for _, ref := range p.RowPages() {
+		if hasMention(ref.Block, fn) {
+			res = append(res, ref.Block)
+		}
+	}
+	return res
+}
+
+// MentionsOfUser returns blocks that mention a user with a given id in
+// their text or properties, including rows of tables in the page that
+// have the user in a person column
+func (p *Page) MentionsOfUser(userID string) []*Block {
+	return p.mentions(func(ib *InlineBlock) bool {
+		return ib.UserID != "" && SameID(ib.UserID, userID)
+	})
+}
+
+// MentionsOfPage returns blocks that mention a page with a given id in
+// their text or properties, including rows of tables in the page that
+// link to the page in a relation column
+func (p *Page) MentionsOfPage(pageID string) []*Block {
+	return p.mentions(func(ib *InlineBlock) bool {
+		return ib.PageID != "" && SameID(ib.PageID, pageID)
+	})
+}
+
+// mentionedPageTitle returns title of a page mentioned in p, "" if the
+// page is not part of p
+func (p *Page) mentionedPageTitle(pageID string) string {
+	if b := p.BlockByID(pageID); b != nil {
+		return pageTitle(b)
+	}
+	return ""
+}
+
+// resolvePageMentions returns blocks with page mentions replaced by text
+// with title of the page (or "Untitled" if title returns "" or is nil),
+// linked to url of the page if url is not nil
+func resolvePageMentions(blocks []*InlineBlock, title func(pageID string) string, url func(pageID string) string) []*InlineBlock {
+	var res []*InlineBlock
+	for i, b := range blocks {
+		if b.PageID == "" {
+			continue
+		}
+		if res == nil {
+			res = append([]*InlineBlock{}, blocks...)
+		}
+		resolved := *b
+		resolved.PageID = ""
+		resolved.Text = ""
+		if title != nil {
+			resolved.Text = title(b.PageID)
+		}
+		if resolved.Text == "" {
+			resolved.Text = "Untitled"
+		}
+		if url != nil {
+			resolved.Link = url(b.PageID)
+		}
+		res[i] = &resolved
+	}
+	if res == nil {
+		return blocks
+	}
+	return res
+}
+
+// notionPageURL returns url of a page on notion.so, for mentions of pages
+// when we don't know a better url
+func notionPageURL(pageID string) string {
+	return pageURL("", pageID)
+}
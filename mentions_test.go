@@ -0,0 +1,40 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMentions(t *testing.T) {
+	const userID = "bb760e2d-d679-4b64-b2a9-03005b21870a"
+	const pageID = "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"
+	mentionUser := &Block{ID: "user", Type: BlockText, InlineContent: []*InlineBlock{
+		{Text: "cc "}, {Text: InlineAt, UserID: userID},
+	}}
+	mentionPage := &Block{ID: "page", Type: BlockText, InlineContent: []*InlineBlock{
+		{Text: InlineAt, PageID: pageID},
+	}}
+	caption := &Block{ID: "image", Type: BlockImage, Properties: map[string]interface{}{
+		"caption": []interface{}{[]interface{}{InlineAt, []interface{}{[]interface{}{"u", userID}}}},
+	}}
+	page := testPage(mentionUser, textBlock(BlockToggle, "toggle", mentionPage), caption, textBlock(BlockText, "no"))
+
+	assigned := testRow("assigned", "")
+	assigned.Properties["person"] = []interface{}{[]interface{}{InlineAt, []interface{}{[]interface{}{"u", userID}}}}
+	related := testRow("related", "")
+	related.Properties["relation"] = []interface{}{[]interface{}{InlineAt, []interface{}{[]interface{}{"p", pageID}}}}
+	page.Tables = []*Table{{Data: []*Block{assigned, related, testRow("other", "")}}}
+
+	ids := func(blocks []*Block) []string {
+		var res []string
+		for _, b := range blocks {
+			res = append(res, b.ID)
+		}
+		return res
+	}
+	// id without dashes matches
+	assert.Equal(t, []string{"user", "image", "assigned"}, ids(page.MentionsOfUser("bb760e2dd6794b64b2a903005b21870a")))
+	assert.Equal(t, []string{"page", "related"}, ids(page.MentionsOfPage(pageID)))
+	assert.Empty(t, page.MentionsOfUser("someone"))
+}
@@ -0,0 +1,130 @@
+package notionapi
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Metrics receives information about every http request sent by Client,
+// including retries, e.g. to export it to a monitoring system.
+// Responses from Cassette or HTTPInterceptor are not reported
+type Metrics interface {
+	// RequestDone is called when the body of the response is closed or,
+	// with status 0, when sending the request failed.
+	// endpoint is the url of the request without the query, dur includes
+	// reading the response, sizes are what was sent over the network
+	// (i.e. compressed) and retry is true if the request is a retry
+	RequestDone(endpoint string, status int, dur time.Duration, reqBytes, respBytes int64, retry bool)
+}
+
+// ClientStats has totals of http requests sent by Client
+type ClientStats struct {
+	// number of http requests, including retries
+	Requests int64
+	// number of requests that were retries of failed requests
+	Retries int64
+	// number of requests that failed or returned status code other
+	// than 200
+	Failed        int64
+	BytesSent     int64
+	BytesReceived int64
+	// sum of durations of all requests
+	Duration time.Duration
+}
+
+// protects Client.stats
+var statsMu sync.Mutex
+
+// Stats returns totals of http requests sent so far
+func (c *Client) Stats() ClientStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	return c.stats
+}
+
+// requestMetrics measures a single http request
+type requestMetrics struct {
+	client   *Client
+	endpoint string
+	start    time.Time
+	reqBytes int64
+	retry    bool
+	// page whose download sent the request, if progress is reported
+	progress *pageProgress
+}
+
+func (c *Client) startRequest(req *http.Request, retry bool) *requestMetrics {
+	u := *req.URL
+	// query of signed urls has credentials
+	u.RawQuery = ""
+	u.Fragment = ""
+	reqBytes := req.ContentLength
+	if reqBytes < 0 {
+		reqBytes = 0
+	}
+	return &requestMetrics{
+		client:   c,
+		endpoint: u.String(),
+		start:    time.Now(),
+		reqBytes: reqBytes,
+		retry:    retry,
+		progress: progressFromContext(req.Context()),
+	}
+}
+
+func (m *requestMetrics) done(status int, respBytes int64) {
+	dur := time.Since(m.start)
+	c := m.client
+	statsMu.Lock()
+	c.stats.Requests++
+	if m.retry {
+		c.stats.Retries++
+	}
+	if status != http.StatusOK {
+		c.stats.Failed++
+	}
+	c.stats.BytesSent += m.reqBytes
+	c.stats.BytesReceived += respBytes
+	c.stats.Duration += dur
+	statsMu.Unlock()
+	if m.progress != nil {
+		m.progress.addBytes(respBytes)
+	}
+	if c.Metrics != nil {
+		c.Metrics.RequestDone(m.endpoint, status, dur, m.reqBytes, respBytes, m.retry)
+	}
+}
+
+// countBody replaces body of rsp with a reader that counts bytes and
+// reports the request as done when closed
+func (m *requestMetrics) countBody(rsp *http.Response) {
+	rsp.Body = &countingBody{
+		ReadCloser: rsp.Body,
+		metrics:    m,
+		status:     rsp.StatusCode,
+	}
+}
+
+type countingBody struct {
+	io.ReadCloser
+	metrics *requestMetrics
+	status  int
+	n       int64
+	once    sync.Once
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		b.metrics.done(b.status, b.n)
+	})
+	return err
+}
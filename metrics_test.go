@@ -0,0 +1,79 @@
+package notionapi
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRequest struct {
+	endpoint  string
+	status    int
+	reqBytes  int64
+	respBytes int64
+	retry     bool
+}
+
+type testMetrics struct {
+	mu       sync.Mutex
+	requests []testRequest
+}
+
+func (m *testMetrics) RequestDone(endpoint string, status int, dur time.Duration, reqBytes, respBytes int64, retry bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, testRequest{endpoint, status, reqBytes, respBytes, retry})
+}
+
+func TestMetrics(t *testing.T) {
+	metrics := &testMetrics{}
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: &flakyTransport{statusCodes: []int{502}}},
+		RetryPolicy: testRetryPolicy(),
+		Metrics:     metrics,
+	}
+	_, err := c.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(metrics.requests))
+	first, second := metrics.requests[0], metrics.requests[1]
+	assert.Equal(t, "https://www.notion.so/api/v3/syncRecordValues", first.endpoint)
+	assert.Equal(t, 502, first.status)
+	assert.False(t, first.retry)
+	assert.Equal(t, 200, second.status)
+	assert.True(t, second.retry)
+	assert.True(t, second.reqBytes > 0)
+	assert.Equal(t, int64(len(`{}`)), second.respBytes)
+
+	stats := c.Stats()
+	assert.Equal(t, int64(2), stats.Requests)
+	assert.Equal(t, int64(1), stats.Retries)
+	assert.Equal(t, int64(1), stats.Failed)
+	assert.Equal(t, 2*second.reqBytes, stats.BytesSent)
+	assert.Equal(t, int64(4), stats.BytesReceived)
+}
+
+func TestMetricsFailedAndCompressed(t *testing.T) {
+	metrics := &testMetrics{}
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: failingTransport{}},
+		RetryPolicy: &NoRetryPolicy,
+		Metrics:     metrics,
+	}
+	_, err := c.DownloadFile("https://example.com/file.png?signature=secret", nil)
+	assert.Error(t, err)
+	assert.Equal(t, []testRequest{{endpoint: "https://example.com/file.png"}}, metrics.requests)
+	assert.Equal(t, int64(1), c.Stats().Failed)
+
+	d := largePageJSON(100)
+	transport := &gzipTransport{body: d}
+	c = &Client{
+		HTTPClient: &http.Client{Transport: transport},
+	}
+	_, err = c.LoadPageChunk("4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", 0, nil)
+	assert.NoError(t, err)
+	compressed, _ := gzipBytes(d)
+	assert.Equal(t, int64(len(compressed)), c.Stats().BytesReceived)
+}
@@ -0,0 +1,149 @@
+package notionapi
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+const (
+	// Notion rejects transactions that are too large so we split
+	// operations into multiple transactions
+	maxOperationsPerTransaction = 100
+)
+
+// NewBlock describes a block to be created with Page.AppendBlocks
+type NewBlock struct {
+	// type of the block e.g. BlockText, BlockHeader
+	Type string
+	// for text blocks, headers, lists, to-dos, toggles and quotes
+	InlineContent []*InlineBlock
+	// for BlockTodo
+	IsChecked bool
+	// for BlockCode
+	Code         string
+	CodeLanguage string
+	// for BlockImage it's url of the image
+	Source string
+	// blocks nested inside this block e.g. items of a nested list
+	Children []*NewBlock
+}
+
+// newBlockID returns a random (version 4) uuid
+func newBlockID() string {
+	var b [16]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		panic(err.Error())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func newBlockProperties(b *NewBlock) map[string]interface{} {
+	props := map[string]interface{}{}
+	switch b.Type {
+	case BlockCode:
+		props["title"] = []interface{}{[]interface{}{b.Code}}
+		props["language"] = []interface{}{[]interface{}{b.CodeLanguage}}
+	case BlockImage:
+		props["source"] = []interface{}{[]interface{}{b.Source}}
+	case BlockDivider:
+		// no properties
+	default:
+		if len(b.InlineContent) > 0 {
+			props["title"] = InlineBlocksToJSON(b.InlineContent)
+		}
+	}
+	if b.Type == BlockTodo && b.IsChecked {
+		props["checked"] = checkedToJSON(true)
+	}
+	return props
+}
+
+// buildCreateBlockOp creates a block with a given id as a child of
+// parentID. It must be followed by buildAppendContentOp to make the block
+// visible in the parent
+func buildCreateBlockOp(id string, parentID string, b *NewBlock) *Operation {
+	now := notionTimeNow()
+	args := map[string]interface{}{
+		"id":               id,
+		"type":             b.Type,
+		"version":          1,
+		"alive":            true,
+		"parent_id":        parentID,
+		"parent_table":     TableBlock,
+		"created_time":     now,
+		"last_edited_time": now,
+		"properties":       newBlockProperties(b),
+	}
+	if b.Type == BlockImage {
+		args["format"] = map[string]interface{}{
+			"display_source": b.Source,
+		}
+	}
+	return &Operation{
+		ID:      id,
+		Table:   TableBlock,
+		Path:    []string{},
+		Command: "set",
+		Args:    args,
+	}
+}
+
+// buildAppendContentOp adds block id at the end of content of parentID
+func buildAppendContentOp(parentID string, id string) *Operation {
+	return &Operation{
+		ID:      parentID,
+		Table:   TableBlock,
+		Path:    []string{"content"},
+		Command: "listAfter",
+		Args: map[string]interface{}{
+			"id": id,
+		},
+	}
+}
+
+// buildNewBlocksOps returns operations that create blocks, and their
+// children, at the end of content of parentID. Parents are created before
+// their children
+func buildNewBlocksOps(parentID string, blocks []*NewBlock) []*Operation {
+	var res []*Operation
+	for _, b := range blocks {
+		id := newBlockID()
+		res = append(res, buildCreateBlockOp(id, parentID, b))
+		res = append(res, buildAppendContentOp(parentID, id))
+		res = append(res, buildNewBlocksOps(id, b.Children)...)
+	}
+	return res
+}
+
+// submitOperations submits operations in transactions of at most
+// maxOperationsPerTransaction operations
+func (c *Client) submitOperations(ops []*Operation) error {
+	for len(ops) > 0 {
+		n := len(ops)
+		if n > maxOperationsPerTransaction {
+			n = maxOperationsPerTransaction
+		}
+		err := c.SubmitTransaction(ops[:n])
+		if err != nil {
+			return err
+		}
+		ops = ops[n:]
+	}
+	return nil
+}
+
+// AppendBlocks creates blocks at the end of the page
+func (p *Page) AppendBlocks(blocks []*NewBlock) error {
+	if p.client == nil {
+		return ErrOfflinePage
+	}
+	ops := buildNewBlocksOps(p.Root.ID, blocks)
+	if len(ops) == 0 {
+		return nil
+	}
+	ops = append(ops, buildLastEditedTimeOp(p.Root.ID))
+	return p.client.submitOperations(ops)
+}
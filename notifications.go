@@ -0,0 +1,244 @@
+package notionapi
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+const (
+	// NotificationMention is a notification about being mentioned
+	NotificationMention = "mention"
+	// NotificationComment is a notification about a comment
+	NotificationComment = "comment"
+	// NotificationEdit is a notification about changes in a page
+	NotificationEdit = "edit"
+)
+
+// Notification is an item of user's notifications (the "Updates"
+// panel in Notion), see Client.GetNotifications
+type Notification struct {
+	ID string
+	// NotificationMention, NotificationComment, NotificationEdit or, for
+	// other kinds of activity, ActivityType
+	Type string
+	// type of activity as sent by the server e.g. "user-mentioned",
+	// "commented", "block-edited"
+	ActivityType string
+	// page to open to see the change
+	PageID string
+	// block that was changed, commented on or has the mention, if known
+	BlockID string
+	// for NotificationComment
+	DiscussionID string
+	CommentID    string
+	// ids of users who did it, in order
+	ActorIDs []string
+	// users who did it, if the server sent their records
+	Actors []*User
+	Read   bool
+	// when the activity started and ended, in milliseconds
+	StartTime int64
+	EndTime   int64
+}
+
+// StartedOn returns the time the activity started
+func (n *Notification) StartedOn() time.Time {
+	return notionTime(n.StartTime)
+}
+
+// EndedOn returns the time the activity ended e.g. the last edit in
+// a series of edits
+func (n *Notification) EndedOn() time.Time {
+	return notionTime(n.EndTime)
+}
+
+// /api/v3/getNotificationLog request
+type getNotificationLogRequest struct {
+	SpaceID string `json:"spaceId"`
+	Size    int    `json:"size"`
+	Type    string `json:"type"`
+	Variant string `json:"variant"`
+}
+
+type notificationRecord struct {
+	ID         string `json:"id"`
+	ActivityID string `json:"activity_id"`
+	Read       bool   `json:"read"`
+}
+
+type activityAuthor struct {
+	ID    string `json:"id"`
+	Table string `json:"table"`
+}
+
+type activityEdit struct {
+	Type    string            `json:"type"`
+	BlockID string            `json:"block_id"`
+	Authors []*activityAuthor `json:"authors"`
+	// sent as a string or a number
+	Timestamp json.Number `json:"timestamp"`
+}
+
+type activityRecord struct {
+	ID               string `json:"id"`
+	Type             string `json:"type"`
+	ParentID         string `json:"parent_id"`
+	ParentTable      string `json:"parent_table"`
+	NavigableBlockID string `json:"navigable_block_id"`
+	MentionedBlockID string `json:"mentioned_block_id"`
+	DiscussionID     string `json:"discussion_id"`
+	CommentID        string `json:"comment_id"`
+	// sent as a string or a number
+	StartTime json.Number     `json:"start_time"`
+	EndTime   json.Number     `json:"end_time"`
+	Edits     []*activityEdit `json:"edits"`
+}
+
+// activityRecordMap is recordMap of responses to activity apis
+type activityRecordMap struct {
+	Notifications map[string]*struct {
+		Value *notificationRecord `json:"value"`
+	} `json:"notification"`
+	Activities map[string]*struct {
+		Value *activityRecord `json:"value"`
+	} `json:"activity"`
+	Users map[string]*UserWithRole `json:"notion_user"`
+}
+
+// activity returns activity with a given id, nil if it's not there
+func (rm *activityRecordMap) activity(id string) *activityRecord {
+	if a := rm.Activities[id]; a != nil {
+		return a.Value
+	}
+	return nil
+}
+
+// users returns users in the record map by their id, in the format of
+// mustNormalizeID
+func (rm *activityRecordMap) users() map[string]*User {
+	res := map[string]*User{}
+	for id, u := range rm.Users {
+		if u != nil && u.Value != nil {
+			res[mustNormalizeID(id)] = u.Value
+		}
+	}
+	return res
+}
+
+type getNotificationLogResponse struct {
+	NotificationIDs []string          `json:"notificationIds"`
+	RecordMap       activityRecordMap `json:"recordMap"`
+}
+
+// actors returns ids of users who did the activity and those of them
+// that are in idToUser
+func (a *activityRecord) actors(idToUser map[string]*User) ([]string, []*User) {
+	var ids []string
+	var users []*User
+	seen := map[string]bool{}
+	for _, e := range a.Edits {
+		for _, author := range e.Authors {
+			if author.Table != "" && author.Table != TableUser {
+				continue
+			}
+			if !seen[author.ID] {
+				seen[author.ID] = true
+				ids = append(ids, author.ID)
+				if u := idToUser[mustNormalizeID(author.ID)]; u != nil {
+					users = append(users, u)
+				}
+			}
+		}
+	}
+	return ids, users
+}
+
+// notificationType returns Notification.Type for a type of activity
+func notificationType(activityType string) string {
+	switch activityType {
+	case "user-mentioned", "user-invited-to-mention":
+		return NotificationMention
+	case "commented":
+		return NotificationComment
+	case "block-edited", "collection-edited", "collection-view-edited", "collection-row-created":
+		return NotificationEdit
+	}
+	return activityType
+}
+
+// newNotification creates Notification from records sent by the server
+func newNotification(n *notificationRecord, a *activityRecord, idToUser map[string]*User) *Notification {
+	res := &Notification{
+		ID:           n.ID,
+		Type:         notificationType(a.Type),
+		ActivityType: a.Type,
+		PageID:       a.NavigableBlockID,
+		BlockID:      a.MentionedBlockID,
+		DiscussionID: a.DiscussionID,
+		CommentID:    a.CommentID,
+		Read:         n.Read,
+	}
+	res.StartTime, _ = a.StartTime.Int64()
+	res.EndTime, _ = a.EndTime.Int64()
+	for _, e := range a.Edits {
+		if res.BlockID == "" {
+			res.BlockID = e.BlockID
+		}
+	}
+	res.ActorIDs, res.Actors = a.actors(idToUser)
+	if res.BlockID == "" && a.ParentTable == TableBlock {
+		res.BlockID = a.ParentID
+	}
+	if res.PageID == "" {
+		res.PageID = res.BlockID
+	}
+	return res
+}
+
+// GetNotifications returns up to limit most recent notifications of the
+// current user in a workspace, newest first. If spaceID is "", we use
+// Client.SpaceID or the first workspace of the user. Default limit is 20
+func (c *Client) GetNotifications(spaceID string, limit int) ([]*Notification, error) {
+	return c.GetNotificationsCtx(context.Background(), spaceID, limit)
+}
+
+// GetNotificationsCtx is like GetNotifications but can be canceled with ctx
+func (c *Client) GetNotificationsCtx(ctx context.Context, spaceID string, limit int) ([]*Notification, error) {
+	if spaceID == "" {
+		var err error
+		spaceID, err = c.resolveSpaceID(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if limit <= 0 {
+		limit = 20
+	}
+	req := &getNotificationLogRequest{
+		SpaceID: spaceID,
+		Size:    limit,
+		Type:    "unread_and_read",
+		Variant: "no_grouping",
+	}
+	var rsp getNotificationLogResponse
+	err := doNotionAPI(ctx, c, "/api/v3/getNotificationLog", req, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	rm := &rsp.RecordMap
+	idToUser := rm.users()
+	var res []*Notification
+	for _, id := range rsp.NotificationIDs {
+		n := rm.Notifications[id]
+		if n == nil || n.Value == nil {
+			continue
+		}
+		a := rm.activity(n.Value.ActivityID)
+		if a == nil {
+			continue
+		}
+		res = append(res, newNotification(n.Value, a, idToUser))
+	}
+	return res, nil
+}
@@ -0,0 +1,81 @@
+package notionapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const notificationLogJSON = `{
+	"notificationIds": ["n1", "n2", "n3"],
+	"recordMap": {
+		"notification": {
+			"n1": { "role": "editor", "value": { "id": "n1", "activity_id": "a1", "read": false } },
+			"n2": { "role": "editor", "value": { "id": "n2", "activity_id": "a2", "read": true } },
+			"n3": { "role": "editor", "value": { "id": "n3", "activity_id": "missing" } }
+		},
+		"activity": {
+			"a1": {
+				"role": "editor",
+				"value": {
+					"id": "a1",
+					"type": "commented",
+					"parent_id": "block1",
+					"parent_table": "block",
+					"navigable_block_id": "page1",
+					"discussion_id": "d1",
+					"comment_id": "c1",
+					"start_time": "1600000000000",
+					"end_time": "1600000060000",
+					"edits": [{ "type": "comment-created", "authors": [{ "id": "u1", "table": "notion_user" }] }]
+				}
+			},
+			"a2": {
+				"role": "editor",
+				"value": {
+					"id": "a2",
+					"type": "block-edited",
+					"navigable_block_id": "page2",
+					"start_time": 1600000000000,
+					"end_time": 1600000000000,
+					"edits": [
+						{ "block_id": "block2", "authors": [{ "id": "u2", "table": "notion_user" }] },
+						{ "block_id": "block3", "authors": [{ "id": "u2", "table": "notion_user" }, { "id": "bot", "table": "bot" }] }
+					]
+				}
+			}
+		},
+		"notion_user": {
+			"u1": { "role": "reader", "value": { "id": "u1", "given_name": "Jane" } }
+		}
+	}
+}`
+
+func TestGetNotifications(t *testing.T) {
+	c, transport := newStaticClient(200, notificationLogJSON)
+	res, err := c.GetNotifications("space", 0)
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(transport.requests[0], `"spaceId":"space","size":20`), transport.requests[0])
+	// notifications without activity are skipped
+	assert.Equal(t, 2, len(res))
+
+	n := res[0]
+	assert.Equal(t, NotificationComment, n.Type)
+	assert.Equal(t, "commented", n.ActivityType)
+	assert.Equal(t, "page1", n.PageID)
+	assert.Equal(t, "block1", n.BlockID)
+	assert.Equal(t, "d1", n.DiscussionID)
+	assert.Equal(t, []string{"u1"}, n.ActorIDs)
+	assert.Equal(t, "Jane", n.Actors[0].FullName())
+	assert.False(t, n.Read)
+	assert.Equal(t, int64(1600000060), n.EndedOn().Unix())
+
+	n = res[1]
+	assert.Equal(t, NotificationEdit, n.Type)
+	assert.Equal(t, "block2", n.BlockID)
+	assert.Equal(t, []string{"u2"}, n.ActorIDs)
+	assert.Empty(t, n.Actors)
+	assert.True(t, n.Read)
+	assert.Equal(t, int64(1600000000), n.StartedOn().Unix())
+}
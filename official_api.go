@@ -0,0 +1,866 @@
+package notionapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Support for the official API (https://developers.notion.com).
+// When Client.IntegrationToken is set, DownloadPage gets the page with
+// the official API and converts it to the same Page, Block and Table
+// structures we get from the unofficial API, so code that renders pages
+// works with both.
+
+const (
+	// OfficialAPIBaseURL is the url of the official API used if
+	// Client.OfficialAPIBaseURL is not set
+	OfficialAPIBaseURL = "https://api.notion.com/v1"
+	// version of the official API we understand
+	officialAPIVersion = "2022-06-28"
+	// maximum page size of lists in the official API
+	officialMaxPageSize = 100
+)
+
+var (
+	// ErrNotSupportedByOfficialAPI is returned by functions that modify
+	// data when Client.IntegrationToken is set
+	ErrNotSupportedByOfficialAPI = errors.New("not supported with the official API (Client.IntegrationToken is set)")
+)
+
+// official block types that have a different name in the unofficial API.
+// Types not listed here have the same name
+var officialBlockTypes = map[string]string{
+	"paragraph":          BlockText,
+	"heading_1":          BlockHeader,
+	"heading_2":          BlockSubHeader,
+	"heading_3":          BlockSubSubHeader,
+	"bulleted_list_item": BlockBulletedList,
+	"numbered_list_item": BlockNumberedList,
+	"link_preview":       BlockBookmark,
+	"child_page":         BlockPage,
+	"child_database":     BlockCollectionView,
+}
+
+// official types of database properties that have a different name
+// in the unofficial API
+var officialColumnTypes = map[string]string{
+	"rich_text": ColumnTypeText,
+	"people":    ColumnTypePerson,
+	"status":    ColumnTypeSelect,
+	"files":     "file",
+}
+
+type officialUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type officialParent struct {
+	Type       string `json:"type"`
+	PageID     string `json:"page_id,omitempty"`
+	DatabaseID string `json:"database_id,omitempty"`
+	BlockID    string `json:"block_id,omitempty"`
+}
+
+type officialLink struct {
+	URL string `json:"url"`
+}
+
+type officialDate struct {
+	Start    string  `json:"start"`
+	End      *string `json:"end"`
+	TimeZone *string `json:"time_zone"`
+}
+
+type officialRichText struct {
+	Type        string  `json:"type"`
+	PlainText   string  `json:"plain_text"`
+	Href        *string `json:"href"`
+	Annotations struct {
+		Bold          bool `json:"bold"`
+		Italic        bool `json:"italic"`
+		Strikethrough bool `json:"strikethrough"`
+		Code          bool `json:"code"`
+	} `json:"annotations"`
+	Text *struct {
+		Content string        `json:"content"`
+		Link    *officialLink `json:"link"`
+	} `json:"text,omitempty"`
+	Mention *struct {
+		Type string        `json:"type"`
+		User *officialUser `json:"user,omitempty"`
+		Date *officialDate `json:"date,omitempty"`
+		Page *struct {
+			ID string `json:"id"`
+		} `json:"page,omitempty"`
+	} `json:"mention,omitempty"`
+	Equation *struct {
+		Expression string `json:"expression"`
+	} `json:"equation,omitempty"`
+}
+
+// officialFile is a file, an image or an icon
+type officialFile struct {
+	// "external", "file" or, for icons, "emoji"
+	Type     string        `json:"type"`
+	External *officialLink `json:"external,omitempty"`
+	File     *officialLink `json:"file,omitempty"`
+	Emoji    string        `json:"emoji,omitempty"`
+}
+
+// url returns url of the file or emoji of the icon
+func (f *officialFile) url() string {
+	switch {
+	case f == nil:
+		return ""
+	case f.External != nil:
+		return f.External.URL
+	case f.File != nil:
+		return f.File.URL
+	}
+	return f.Emoji
+}
+
+// officialBlockContent is the value of the field named like the type of
+// the block, e.g. "paragraph": {...}. Only fields relevant for a given type
+// are set
+type officialBlockContent struct {
+	officialFile
+	RichText     []*officialRichText `json:"rich_text"`
+	Color        string              `json:"color"`
+	Checked      bool                `json:"checked"`
+	Language     string              `json:"language"`
+	Caption      []*officialRichText `json:"caption"`
+	IsToggleable bool                `json:"is_toggleable"`
+	Icon         *officialFile       `json:"icon"`
+	Title        string              `json:"title"`
+	URL          string              `json:"url"`
+	Expression   string              `json:"expression"`
+	Name         string              `json:"name"`
+}
+
+type officialBlock struct {
+	ID             string          `json:"id"`
+	Type           string          `json:"type"`
+	CreatedTime    string          `json:"created_time"`
+	LastEditedTime string          `json:"last_edited_time"`
+	CreatedBy      officialUser    `json:"created_by"`
+	LastEditedBy   officialUser    `json:"last_edited_by"`
+	Parent         officialParent  `json:"parent"`
+	HasChildren    bool            `json:"has_children"`
+	Archived       bool            `json:"archived"`
+	InTrash        bool            `json:"in_trash"`
+	Content        json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes the block and remembers JSON of its content
+func (b *officialBlock) UnmarshalJSON(d []byte) error {
+	type block officialBlock
+	if err := json.Unmarshal(d, (*block)(b)); err != nil {
+		return err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(d, &m); err != nil {
+		return err
+	}
+	b.Content = m[b.Type]
+	return nil
+}
+
+type officialOption struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type officialPropertyValue struct {
+	ID          string              `json:"id"`
+	Type        string              `json:"type"`
+	Title       []*officialRichText `json:"title"`
+	RichText    []*officialRichText `json:"rich_text"`
+	Number      *float64            `json:"number"`
+	Select      *officialOption     `json:"select"`
+	Status      *officialOption     `json:"status"`
+	MultiSelect []*officialOption   `json:"multi_select"`
+	Date        *officialDate       `json:"date"`
+	People      []*officialUser     `json:"people"`
+	Checkbox    bool                `json:"checkbox"`
+	URL         *string             `json:"url"`
+	Email       *string             `json:"email"`
+	PhoneNumber *string             `json:"phone_number"`
+}
+
+type officialPage struct {
+	ID             string                            `json:"id"`
+	CreatedTime    string                            `json:"created_time"`
+	LastEditedTime string                            `json:"last_edited_time"`
+	CreatedBy      officialUser                      `json:"created_by"`
+	LastEditedBy   officialUser                      `json:"last_edited_by"`
+	Parent         officialParent                    `json:"parent"`
+	Archived       bool                              `json:"archived"`
+	InTrash        bool                              `json:"in_trash"`
+	Icon           *officialFile                     `json:"icon"`
+	Cover          *officialFile                     `json:"cover"`
+	Properties     map[string]*officialPropertyValue `json:"properties"`
+}
+
+type officialOptions struct {
+	Options []*officialOption `json:"options"`
+}
+
+type officialPropertySchema struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Type        string           `json:"type"`
+	Select      *officialOptions `json:"select"`
+	Status      *officialOptions `json:"status"`
+	MultiSelect *officialOptions `json:"multi_select"`
+}
+
+type officialDatabase struct {
+	ID          string                             `json:"id"`
+	Title       []*officialRichText                `json:"title"`
+	Description []*officialRichText                `json:"description"`
+	Icon        *officialFile                      `json:"icon"`
+	Cover       *officialFile                      `json:"cover"`
+	Parent      officialParent                     `json:"parent"`
+	Archived    bool                               `json:"archived"`
+	Properties  map[string]*officialPropertySchema `json:"properties"`
+}
+
+type officialBlockList struct {
+	Results    []*officialBlock `json:"results"`
+	HasMore    bool             `json:"has_more"`
+	NextCursor string           `json:"next_cursor"`
+}
+
+type officialPageList struct {
+	Results    []*officialPage `json:"results"`
+	HasMore    bool            `json:"has_more"`
+	NextCursor string          `json:"next_cursor"`
+}
+
+type officialQueryRequest struct {
+	PageSize    int    `json:"page_size"`
+	StartCursor string `json:"start_cursor,omitempty"`
+}
+
+// officialEndpointURL returns full url of the official api endpoint
+// e.g. "blocks/${id}/children"
+func (c *Client) officialEndpointURL(path string) string {
+	base := c.OfficialAPIBaseURL
+	if base == "" {
+		base = OfficialAPIBaseURL
+	}
+	return joinURL(base, path)
+}
+
+// newOfficialAPIError creates APIError from body of the error response
+// of the official api, which is {"code": "...", "message": "..."}
+func newOfficialAPIError(endpoint string, statusCode int, body []byte) *APIError {
+	var v struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(body, &v)
+	return &APIError{
+		Endpoint:   endpoint,
+		StatusCode: statusCode,
+		Name:       v.Code,
+		Message:    v.Message,
+	}
+}
+
+func doOfficialAPI(ctx context.Context, c *Client, method string, path string, requestData interface{}, result interface{}) error {
+	var js []byte
+	var err error
+	if requestData != nil {
+		js, err = json.Marshal(requestData)
+		if err != nil {
+			return err
+		}
+	}
+	uri := c.officialEndpointURL(path)
+	log(c, "%s %s\n", method, uri)
+	if len(js) > 0 {
+		logJSON(c, js)
+	}
+	newRequest := func() (*http.Request, error) {
+		var body io.Reader
+		if js != nil {
+			body = bytes.NewReader(js)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, uri, body)
+		if err != nil {
+			return nil, err
+		}
+		if js != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("Authorization", "Bearer "+c.IntegrationToken)
+		req.Header.Set("Notion-Version", officialAPIVersion)
+		return req, nil
+	}
+	// we only read data so it's always safe to retry
+	rsp, err := c.doHTTP(ctx, newRequest, true, c.getRequestTimeout())
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	d, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		logError(c, "Error: ioutil.ReadAll() failed with %s\n", err)
+		return err
+	}
+	if rsp.StatusCode != 200 {
+		logError(c, "Error: status code %s\nBody:\n%s\n", rsp.Status, ppJSON(d))
+		return newOfficialAPIError(uri, rsp.StatusCode, d)
+	}
+	logJSON(c, d)
+	err = json.Unmarshal(d, result)
+	if err != nil {
+		logError(c, "Error: json.Unmarshal() failed with %s\n. Body:\n%s\n", err, string(d))
+	}
+	return err
+}
+
+// officialTime converts time in ISO 8601 format to milliseconds since epoch
+// used by the unofficial API
+func officialTime(s string) int64 {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// officialParentRef returns id and table of the parent as used in
+// Block.ParentID and Block.ParentTable
+func officialParentRef(p officialParent) (string, string) {
+	switch p.Type {
+	case "page_id":
+		return p.PageID, TableBlock
+	case "block_id":
+		return p.BlockID, TableBlock
+	case "database_id":
+		return p.DatabaseID, TableCollection
+	}
+	return "", TableSpace
+}
+
+func officialDateToDate(d *officialDate) *Date {
+	res := &Date{
+		Type:       DateTypeDate,
+		DateFormat: "relative",
+		StartDate:  d.Start,
+		TimeZone:   d.TimeZone,
+	}
+	if len(d.Start) > len("2006-01-02") {
+		if t, err := time.Parse(time.RFC3339, d.Start); err == nil {
+			res.StartDate = t.Format("2006-01-02")
+			startTime := t.Format("15:04")
+			res.StartTime = &startTime
+			res.Type = DateTypeDateTime
+		}
+	}
+	return res
+}
+
+// officialRichTextToInline converts rich text to inline blocks
+func officialRichTextToInline(richText []*officialRichText) []*InlineBlock {
+	var res []*InlineBlock
+	for _, rt := range richText {
+		b := &InlineBlock{
+			Text: rt.PlainText,
+		}
+		if rt.Annotations.Bold {
+			b.AttrFlags |= AttrBold
+		}
+		if rt.Annotations.Italic {
+			b.AttrFlags |= AttrItalic
+		}
+		if rt.Annotations.Strikethrough {
+			b.AttrFlags |= AttrStrikeThrought
+		}
+		if rt.Annotations.Code {
+			b.AttrFlags |= AttrCode
+		}
+		switch {
+		case rt.Mention != nil && rt.Mention.User != nil:
+			b.Text = InlineAt
+			b.UserID = rt.Mention.User.ID
+		case rt.Mention != nil && rt.Mention.Date != nil:
+			b.Text = InlineAt
+			b.Date = officialDateToDate(rt.Mention.Date)
+		case rt.Mention != nil && rt.Mention.Page != nil:
+			b.Text = InlineAt
+			b.PageID = rt.Mention.Page.ID
+		case rt.Equation != nil:
+			b.Text = InlineEquation
+			b.Equation = rt.Equation.Expression
+		case rt.Href != nil:
+			b.Link = *rt.Href
+		}
+		res = append(res, b)
+	}
+	return res
+}
+
+// officialRichTextToJSON converts rich text to the value of a property
+func officialRichTextToJSON(richText []*officialRichText) []interface{} {
+	return InlineBlocksToJSON(officialRichTextToInline(richText))
+}
+
+func textToJSON(s string) []interface{} {
+	return []interface{}{[]interface{}{s}}
+}
+
+// officialPropertyToJSON converts a value of a database property to
+// the value of a property of a row block. Returns nil for empty values
+// and for types we don't support
+func officialPropertyToJSON(p *officialPropertyValue) []interface{} {
+	var blocks []*InlineBlock
+	switch p.Type {
+	case "title":
+		blocks = officialRichTextToInline(p.Title)
+	case "rich_text":
+		blocks = officialRichTextToInline(p.RichText)
+	case "number":
+		if p.Number != nil {
+			return textToJSON(strconv.FormatFloat(*p.Number, 'f', -1, 64))
+		}
+	case "select", "status":
+		opt := p.Select
+		if p.Type == "status" {
+			opt = p.Status
+		}
+		if opt != nil {
+			return textToJSON(opt.Name)
+		}
+	case "multi_select":
+		var names []string
+		for _, opt := range p.MultiSelect {
+			names = append(names, opt.Name)
+		}
+		if len(names) > 0 {
+			return textToJSON(strings.Join(names, ","))
+		}
+	case "checkbox":
+		if p.Checkbox {
+			return textToJSON("Yes")
+		}
+		return textToJSON("No")
+	case "date":
+		if p.Date != nil {
+			blocks = []*InlineBlock{{Text: InlineAt, Date: officialDateToDate(p.Date)}}
+		}
+	case "people":
+		for _, u := range p.People {
+			if len(blocks) > 0 {
+				blocks = append(blocks, &InlineBlock{Text: ","})
+			}
+			blocks = append(blocks, &InlineBlock{Text: InlineAt, UserID: u.ID})
+		}
+	case "url", "email", "phone_number":
+		s := p.URL
+		if p.Type == "email" {
+			s = p.Email
+		} else if p.Type == "phone_number" {
+			s = p.PhoneNumber
+		}
+		if s != nil && *s != "" {
+			return textToJSON(*s)
+		}
+	}
+	if len(blocks) == 0 {
+		return nil
+	}
+	return InlineBlocksToJSON(blocks)
+}
+
+// fromJSONValue converts v, built from maps and slices in the format of
+// the unofficial API, to dst by serializing it to JSON
+func fromJSONValue(v interface{}, dst interface{}) error {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(d, dst)
+}
+
+// officialPageToBlock converts a page to a page block. Properties of pages
+// in a database are keyed by property id, like columns in
+// Collection.CollectionSchema
+func officialPageToBlock(p *officialPage) (*Block, error) {
+	parentID, parentTable := officialParentRef(p.Parent)
+	props := map[string]interface{}{}
+	for _, prop := range p.Properties {
+		v := officialPropertyToJSON(prop)
+		if v == nil {
+			continue
+		}
+		key := prop.ID
+		if prop.Type == "title" {
+			key = "title"
+		}
+		props[key] = v
+	}
+	format := map[string]interface{}{}
+	if icon := p.Icon.url(); icon != "" {
+		format["page_icon"] = icon
+	}
+	if cover := p.Cover.url(); cover != "" {
+		format["page_cover"] = cover
+	}
+	v := map[string]interface{}{
+		"id":               p.ID,
+		"type":             BlockPage,
+		"alive":            !p.Archived && !p.InTrash,
+		"created_time":     officialTime(p.CreatedTime),
+		"last_edited_time": officialTime(p.LastEditedTime),
+		"created_by":       p.CreatedBy.ID,
+		"last_edited_by":   p.LastEditedBy.ID,
+		"parent_id":        parentID,
+		"parent_table":     parentTable,
+		"properties":       props,
+	}
+	if len(format) > 0 {
+		v["format"] = format
+	}
+	var res Block
+	err := fromJSONValue(v, &res)
+	return &res, err
+}
+
+// officialBlockToBlock converts a block. contentIDs are ids of its
+// children
+func officialBlockToBlock(ob *officialBlock, contentIDs []string) (*Block, error) {
+	var content officialBlockContent
+	if len(ob.Content) > 0 {
+		err := json.Unmarshal(ob.Content, &content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode content of block %s of type '%s': %s", ob.ID, ob.Type, err)
+		}
+	}
+	typ := officialBlockTypes[ob.Type]
+	if typ == "" {
+		typ = ob.Type
+	}
+	props := map[string]interface{}{}
+	format := map[string]interface{}{}
+	if len(content.RichText) > 0 {
+		props["title"] = officialRichTextToJSON(content.RichText)
+	}
+	if len(content.Caption) > 0 {
+		props["caption"] = officialRichTextToJSON(content.Caption)
+	}
+	switch ob.Type {
+	case "to_do":
+		props["checked"] = checkedToJSON(content.Checked)
+	case "code":
+		props["language"] = textToJSON(content.Language)
+	case "equation":
+		props["title"] = textToJSON(content.Expression)
+	case "child_page", "child_database":
+		props["title"] = textToJSON(content.Title)
+	case "bookmark", "link_preview":
+		props["link"] = textToJSON(content.URL)
+		props["source"] = textToJSON(content.URL)
+	case "embed":
+		props["source"] = textToJSON(content.URL)
+	case "image", "video", "file", "pdf", "audio":
+		props["source"] = textToJSON(content.url())
+		if content.Name != "" {
+			props["title"] = textToJSON(content.Name)
+		}
+	case "callout":
+		if icon := content.Icon.url(); icon != "" {
+			format["page_icon"] = icon
+		}
+	case "heading_1", "heading_2", "heading_3":
+		if content.IsToggleable {
+			format["toggleable"] = true
+		}
+	}
+	if content.Color != "" && content.Color != "default" {
+		format["block_color"] = content.Color
+	}
+	parentID, parentTable := officialParentRef(ob.Parent)
+	v := map[string]interface{}{
+		"id":               ob.ID,
+		"type":             typ,
+		"alive":            !ob.Archived && !ob.InTrash,
+		"created_time":     officialTime(ob.CreatedTime),
+		"last_edited_time": officialTime(ob.LastEditedTime),
+		"created_by":       ob.CreatedBy.ID,
+		"last_edited_by":   ob.LastEditedBy.ID,
+		"parent_id":        parentID,
+		"parent_table":     parentTable,
+	}
+	if len(contentIDs) > 0 {
+		v["content"] = contentIDs
+	}
+	if len(props) > 0 {
+		v["properties"] = props
+	}
+	if len(format) > 0 {
+		v["format"] = format
+	}
+	var res Block
+	err := fromJSONValue(v, &res)
+	return &res, err
+}
+
+// officialDatabaseToCollection converts database to a collection
+func officialDatabaseToCollection(db *officialDatabase) (*Collection, error) {
+	schema := map[string]interface{}{}
+	for name, prop := range db.Properties {
+		typ := officialColumnTypes[prop.Type]
+		if typ == "" {
+			typ = prop.Type
+		}
+		col := map[string]interface{}{
+			"name": name,
+			"type": typ,
+		}
+		opts := prop.Select
+		if opts == nil {
+			opts = prop.MultiSelect
+		}
+		if opts == nil {
+			opts = prop.Status
+		}
+		if opts != nil {
+			var options []interface{}
+			for _, o := range opts.Options {
+				options = append(options, map[string]interface{}{
+					"id":    o.ID,
+					"color": o.Color,
+					"value": o.Name,
+				})
+			}
+			col["options"] = options
+		}
+		key := prop.ID
+		if prop.Type == "title" {
+			key = "title"
+		}
+		schema[key] = col
+	}
+	parentID, parentTable := officialParentRef(db.Parent)
+	v := map[string]interface{}{
+		"id":           db.ID,
+		"alive":        !db.Archived,
+		"name":         officialRichTextToJSON(db.Title),
+		"parent_id":    parentID,
+		"parent_table": parentTable,
+		"schema":       schema,
+	}
+	if len(db.Description) > 0 {
+		v["description"] = officialRichTextToJSON(db.Description)
+	}
+	if icon := db.Icon.url(); icon != "" {
+		v["icon"] = icon
+	}
+	if cover := db.Cover.url(); cover != "" {
+		v["cover"] = cover
+	}
+	var res Collection
+	err := fromJSONValue(v, &res)
+	if err != nil {
+		return nil, err
+	}
+	parseCollection(&res)
+	return &res, nil
+}
+
+// officialPageDownloader downloads a page with the official api
+type officialPageDownloader struct {
+	client    *Client
+	opts      *DownloadPageOptions
+	page      *Page
+	idToBlock map[string]*Block
+}
+
+func (d *officialPageDownloader) pageSize() int {
+	n := d.opts.ChunkSize
+	if n <= 0 || n > officialMaxPageSize {
+		n = officialMaxPageSize
+	}
+	return n
+}
+
+// downloadChildren downloads children of a block, recursively, and
+// returns their ids
+func (d *officialPageDownloader) downloadChildren(ctx context.Context, blockID string) ([]string, error) {
+	var ids []string
+	cursor := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		path := fmt.Sprintf("blocks/%s/children?page_size=%d", blockID, d.pageSize())
+		if cursor != "" {
+			path += "&start_cursor=" + url.QueryEscape(cursor)
+		}
+		var rsp officialBlockList
+		err := doOfficialAPI(ctx, d.client, "GET", path, nil, &rsp)
+		if err != nil {
+			return nil, err
+		}
+		for _, ob := range rsp.Results {
+			var contentIDs []string
+			// sub-pages are only links, like in the unofficial API
+			if ob.HasChildren && ob.Type != "child_page" && ob.Type != "child_database" {
+				contentIDs, err = d.downloadChildren(ctx, ob.ID)
+				if err != nil {
+					return nil, err
+				}
+			}
+			block, err := officialBlockToBlock(ob, contentIDs)
+			if err != nil {
+				return nil, err
+			}
+			if ob.Type == "child_database" {
+				err = d.downloadDatabase(ctx, block)
+				if err != nil {
+					return nil, err
+				}
+			}
+			d.idToBlock[block.ID] = block
+			ids = append(ids, block.ID)
+		}
+		d.opts.chunkFetched(ctx, len(d.idToBlock))
+		if err := d.opts.checkMaxBlocks(d.page.ID, len(d.idToBlock)); err != nil {
+			return nil, err
+		}
+		if !rsp.HasMore || rsp.NextCursor == "" {
+			return ids, nil
+		}
+		cursor = rsp.NextCursor
+	}
+}
+
+// downloadDatabase downloads schema and rows of a database shown in
+// block and adds it as a table with a single view
+func (d *officialPageDownloader) downloadDatabase(ctx context.Context, block *Block) error {
+	var db officialDatabase
+	err := doOfficialAPI(ctx, d.client, "GET", "databases/"+block.ID, nil, &db)
+	if err != nil {
+		return err
+	}
+	collection, err := officialDatabaseToCollection(&db)
+	if err != nil {
+		return err
+	}
+	var rows []*Block
+	req := &officialQueryRequest{PageSize: d.pageSize()}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var rsp officialPageList
+		err = doOfficialAPI(ctx, d.client, "POST", "databases/"+block.ID+"/query", req, &rsp)
+		if err != nil {
+			return err
+		}
+		for _, p := range rsp.Results {
+			row, err := officialPageToBlock(p)
+			if err != nil {
+				return err
+			}
+			err = parseProperties(row)
+			if err != nil {
+				return err
+			}
+			parseFormat(row)
+			rows = append(rows, row)
+		}
+		if !rsp.HasMore || rsp.NextCursor == "" {
+			break
+		}
+		req.StartCursor = rsp.NextCursor
+	}
+	// the official api doesn't expose views so we make one that shows
+	// all columns
+	view := &CollectionView{
+		ID:          block.ID,
+		Alive:       true,
+		Type:        "table",
+		ParentID:    block.ID,
+		ParentTable: TableBlock,
+	}
+	block.CollectionID = collection.ID
+	block.ViewIDs = []string{view.ID}
+	info := &CollectionViewInfo{
+		CollectionView: view,
+		Collection:     collection,
+		CollectionRows: rows,
+	}
+	block.CollectionViews = append(block.CollectionViews, info)
+	table := &Table{
+		CollectionView: view,
+		Collection:     collection,
+		Data:           rows,
+		client:         d.client,
+	}
+	d.page.Tables = append(d.page.Tables, table)
+	return nil
+}
+
+// downloadPageOfficial downloads a page using the official api
+func (c *Client) downloadPageOfficial(ctx context.Context, pageID string, opts *DownloadPageOptions) (*Page, error) {
+	var p officialPage
+	err := doOfficialAPI(ctx, c, "GET", "pages/"+pageID, nil, &p)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, fmt.Errorf("Couldn't retrieve page with id %s: %w", pageID, ErrNotFound)
+		}
+		return nil, err
+	}
+	root, err := officialPageToBlock(&p)
+	if err != nil {
+		return nil, err
+	}
+	page := &Page{
+		ID:     root.ID,
+		Root:   root,
+		client: c,
+	}
+	d := &officialPageDownloader{
+		client:    c,
+		opts:      opts,
+		page:      page,
+		idToBlock: map[string]*Block{},
+	}
+	root.ContentIDs, err = d.downloadChildren(ctx, root.ID)
+	if err != nil {
+		return nil, err
+	}
+	err = resolveBlocks(root, d.idToBlock, &page.Warnings)
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range d.idToBlock {
+		// files from the official api are signed urls that don't work
+		// with notion image proxy
+		if block.IsImage() && block.Source != "" {
+			block.ImageURL = block.Source
+		}
+	}
+	if opts.Progress != nil {
+		opts.Progress(len(d.idToBlock), true)
+	}
+	return page, nil
+}
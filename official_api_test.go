@@ -0,0 +1,254 @@
+package notionapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	officialPageJSON = `{
+	"object": "page",
+	"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+	"created_time": "2022-03-01T19:05:00.000Z",
+	"last_edited_time": "2022-03-02T10:00:00.000Z",
+	"created_by": { "object": "user", "id": "bb760e2d-d679-4b64-b2a9-03005b21870a" },
+	"last_edited_by": { "object": "user", "id": "bb760e2d-d679-4b64-b2a9-03005b21870a" },
+	"parent": { "type": "workspace", "workspace": true },
+	"archived": false,
+	"icon": { "type": "emoji", "emoji": "📝" },
+	"cover": null,
+	"properties": {
+		"title": {
+			"id": "title",
+			"type": "title",
+			"title": [
+				{ "type": "text", "text": { "content": "Test page", "link": null }, "annotations": { "bold": false }, "plain_text": "Test page", "href": null }
+			]
+		}
+	}
+}`
+
+	officialChildrenJSON1 = `{
+	"object": "list",
+	"results": [
+		{
+			"object": "block",
+			"id": "00000000-0000-0000-0000-000000000001",
+			"type": "paragraph",
+			"created_time": "2022-03-01T19:05:00.000Z",
+			"last_edited_time": "2022-03-01T19:05:00.000Z",
+			"parent": { "type": "page_id", "page_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d" },
+			"has_children": false,
+			"paragraph": {
+				"color": "red",
+				"rich_text": [
+					{ "type": "text", "text": { "content": "bold", "link": null }, "annotations": { "bold": true }, "plain_text": "bold", "href": null },
+					{ "type": "text", "text": { "content": " link", "link": { "url": "https://blog.kowalczyk.info" } }, "annotations": {}, "plain_text": " link", "href": "https://blog.kowalczyk.info" },
+					{ "type": "mention", "mention": { "type": "user", "user": { "object": "user", "id": "bb760e2d-d679-4b64-b2a9-03005b21870a" } }, "annotations": {}, "plain_text": "@Krzysztof", "href": null },
+					{ "type": "equation", "equation": { "expression": "x^2" }, "annotations": {}, "plain_text": "x^2", "href": null }
+				]
+			}
+		},
+		{
+			"object": "block",
+			"id": "00000000-0000-0000-0000-000000000002",
+			"type": "toggle",
+			"parent": { "type": "page_id", "page_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d" },
+			"has_children": true,
+			"toggle": {
+				"rich_text": [{ "type": "text", "text": { "content": "toggle" }, "annotations": {}, "plain_text": "toggle" }]
+			}
+		}
+	],
+	"has_more": true,
+	"next_cursor": "cursor-2"
+}`
+
+	officialChildrenJSON2 = `{
+	"object": "list",
+	"results": [
+		{
+			"object": "block",
+			"id": "00000000-0000-0000-0000-000000000003",
+			"type": "image",
+			"has_children": false,
+			"image": {
+				"type": "file",
+				"file": { "url": "https://prod-files-secure.s3.us-west-2.amazonaws.com/image.png?X-Amz-Signature=abc" },
+				"caption": []
+			}
+		},
+		{
+			"object": "block",
+			"id": "00000000-0000-0000-0000-000000000004",
+			"type": "child_database",
+			"has_children": false,
+			"child_database": { "title": "Tasks" }
+		}
+	],
+	"has_more": false,
+	"next_cursor": null
+}`
+
+	officialToggleChildrenJSON = `{
+	"object": "list",
+	"results": [
+		{
+			"object": "block",
+			"id": "00000000-0000-0000-0000-000000000005",
+			"type": "to_do",
+			"has_children": false,
+			"to_do": {
+				"rich_text": [{ "type": "text", "text": { "content": "buy milk" }, "annotations": {}, "plain_text": "buy milk" }],
+				"checked": true
+			}
+		},
+		{
+			"object": "block",
+			"id": "00000000-0000-0000-0000-000000000006",
+			"type": "heading_2",
+			"has_children": false,
+			"heading_2": {
+				"rich_text": [{ "type": "text", "text": { "content": "heading" }, "annotations": {}, "plain_text": "heading" }],
+				"is_toggleable": true
+			}
+		}
+	],
+	"has_more": false,
+	"next_cursor": null
+}`
+
+	officialDatabaseJSON = `{
+	"object": "database",
+	"id": "00000000-0000-0000-0000-000000000004",
+	"title": [{ "type": "text", "text": { "content": "Tasks" }, "annotations": {}, "plain_text": "Tasks" }],
+	"parent": { "type": "page_id", "page_id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d" },
+	"properties": {
+		"Name": { "id": "title", "name": "Name", "type": "title", "title": {} },
+		"Estimate": { "id": "%3AUPp", "name": "Estimate", "type": "number", "number": { "format": "number" } },
+		"Status": { "id": "s%3Dt", "name": "Status", "type": "select", "select": { "options": [{ "id": "1", "name": "Done", "color": "green" }, { "id": "2", "name": "Todo", "color": "red" }] } },
+		"Tags": { "id": "tg", "name": "Tags", "type": "multi_select", "multi_select": { "options": [{ "id": "3", "name": "a", "color": "blue" }, { "id": "4", "name": "b", "color": "gray" }] } },
+		"Urgent": { "id": "ur", "name": "Urgent", "type": "checkbox", "checkbox": {} },
+		"Due": { "id": "du", "name": "Due", "type": "date", "date": {} },
+		"Owner": { "id": "ow", "name": "Owner", "type": "people", "people": {} }
+	}
+}`
+
+	officialQueryJSON = `{
+	"object": "list",
+	"results": [
+		{
+			"object": "page",
+			"id": "00000000-0000-0000-0000-000000000007",
+			"parent": { "type": "database_id", "database_id": "00000000-0000-0000-0000-000000000004" },
+			"properties": {
+				"Name": { "id": "title", "type": "title", "title": [{ "type": "text", "text": { "content": "Write code" }, "annotations": {}, "plain_text": "Write code" }] },
+				"Estimate": { "id": "%3AUPp", "type": "number", "number": 2.5 },
+				"Status": { "id": "s%3Dt", "type": "select", "select": { "id": "1", "name": "Done", "color": "green" } },
+				"Tags": { "id": "tg", "type": "multi_select", "multi_select": [{ "id": "3", "name": "a" }, { "id": "4", "name": "b" }] },
+				"Urgent": { "id": "ur", "type": "checkbox", "checkbox": true },
+				"Due": { "id": "du", "type": "date", "date": { "start": "2022-03-04T15:30:00.000+00:00", "end": null, "time_zone": null } },
+				"Owner": { "id": "ow", "type": "people", "people": [{ "object": "user", "id": "bb760e2d-d679-4b64-b2a9-03005b21870a" }] }
+			}
+		}
+	],
+	"has_more": false,
+	"next_cursor": null
+}`
+)
+
+func newOfficialAPIServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret_token", r.Header.Get("Authorization"))
+		assert.Equal(t, officialAPIVersion, r.Header.Get("Notion-Version"))
+		s := ""
+		switch r.URL.Path {
+		case "/v1/pages/4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d":
+			s = officialPageJSON
+		case "/v1/blocks/4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d/children":
+			s = officialChildrenJSON1
+			if r.URL.Query().Get("start_cursor") == "cursor-2" {
+				s = officialChildrenJSON2
+			}
+		case "/v1/blocks/00000000-0000-0000-0000-000000000002/children":
+			s = officialToggleChildrenJSON
+		case "/v1/databases/00000000-0000-0000-0000-000000000004":
+			s = officialDatabaseJSON
+		case "/v1/databases/00000000-0000-0000-0000-000000000004/query":
+			assert.Equal(t, "POST", r.Method)
+			s = officialQueryJSON
+		default:
+			w.WriteHeader(404)
+			s = `{"object":"error","status":404,"code":"object_not_found","message":"Could not find page."}`
+		}
+		w.Write([]byte(s))
+	}))
+}
+
+func TestOfficialAPIDownloadPage(t *testing.T) {
+	server := newOfficialAPIServer(t)
+	defer server.Close()
+	c := &Client{
+		IntegrationToken:   "secret_token",
+		OfficialAPIBaseURL: server.URL + "/v1",
+		RateLimiter:        NewTokenBucket(1000, 100),
+	}
+	page, err := c.DownloadPage("4c6a54c68b3e4ea2af9cfaabcc88d58d")
+	assert.NoError(t, err)
+	root := page.Root
+	assert.Equal(t, "Test page", root.Title)
+	assert.Equal(t, "📝", root.FormatPage.PageIcon)
+	assert.Equal(t, int64(1646161500000), root.CreatedTime)
+	assert.Equal(t, 4, len(root.Content))
+
+	para := root.Content[0]
+	assert.Equal(t, BlockText, para.Type)
+	assert.Equal(t, "red", *para.FormatText.BlockColor)
+	inline := para.InlineContent
+	assert.Equal(t, 4, len(inline))
+	assert.Equal(t, AttrBold, inline[0].AttrFlags)
+	assert.Equal(t, "https://blog.kowalczyk.info", inline[1].Link)
+	assert.Equal(t, "bb760e2d-d679-4b64-b2a9-03005b21870a", inline[2].UserID)
+	assert.Equal(t, "x^2", inline[3].Equation)
+
+	toggle := root.Content[1]
+	assert.Equal(t, BlockToggle, toggle.Type)
+	assert.Equal(t, 2, len(toggle.Content))
+	assert.Equal(t, BlockTodo, toggle.Content[0].Type)
+	assert.True(t, toggle.Content[0].IsChecked)
+	assert.Equal(t, BlockSubHeader, toggle.Content[1].Type)
+	assert.True(t, toggle.Content[1].FormatHeader.Toggleable)
+
+	image := root.Content[2]
+	assert.Equal(t, BlockImage, image.Type)
+	assert.True(t, strings.HasPrefix(image.ImageURL, "https://prod-files-secure.s3"))
+
+	assert.Equal(t, BlockCollectionView, root.Content[3].Type)
+	assert.Equal(t, 1, len(page.Tables))
+	table := page.Tables[0]
+	assert.Equal(t, "Tasks", table.Collection.Name())
+	rows := table.Rows()
+	assert.Equal(t, 1, len(rows))
+	row := rows[0]
+	assert.Equal(t, "Write code", row.Block.Title)
+	assert.Equal(t, "Write code", row.Text("Name"))
+	n, ok := row.Number("Estimate")
+	assert.True(t, ok)
+	assert.Equal(t, 2.5, n)
+	assert.Equal(t, "Done", row.Select("Status"))
+	assert.Equal(t, []string{"a", "b"}, row.MultiSelect("Tags"))
+	assert.True(t, row.Checkbox("Urgent"))
+	assert.Equal(t, "2022-03-04 15:30", dateToString(row.Date("Due")))
+	assert.Equal(t, []string{"bb760e2d-d679-4b64-b2a9-03005b21870a"}, row.Persons("Owner"))
+	assert.Equal(t, 2, len(table.Column("Status").Options))
+
+	err = page.SetTitle("new title")
+	assert.Equal(t, ErrNotSupportedByOfficialAPI, err)
+
+	_, err = c.DownloadPage("00000000000000000000000000000099")
+	assert.True(t, IsNotFound(err))
+}
@@ -0,0 +1,63 @@
+package notionapi
+
+// OutlineItem is a header of the page, see Page.Outline
+type OutlineItem struct {
+	// 1 for BlockHeader, 2 for BlockSubHeader, 3 for BlockSubSubHeader
+	Level   int
+	Title   string
+	BlockID string
+	Block   *Block
+	// headers with higher level that follow this header, until the next
+	// header with the same or lower level
+	Children []*OutlineItem
+}
+
+// OutlineOptions describes options for Page.OutlineWithOptions
+type OutlineOptions struct {
+	// if true, headers inside toggles and toggleable headers are skipped
+	SkipToggles bool
+}
+
+// Outline returns headers of the page in document order, nested by level
+func (p *Page) Outline() []*OutlineItem {
+	return p.OutlineWithOptions(nil)
+}
+
+// OutlineWithOptions is like Outline but with options. Headers in
+// templates of BlockFactory and in sub-pages are never included
+func (p *Page) OutlineWithOptions(opts *OutlineOptions) []*OutlineItem {
+	if opts == nil {
+		opts = &OutlineOptions{}
+	}
+	var res []*OutlineItem
+	// the last item of each level that can have children
+	var stack []*OutlineItem
+	p.ForEachBlockWithParent(func(b, parent *Block) bool {
+		if parent == nil {
+			return true
+		}
+		if b.Type == BlockPage {
+			return false
+		}
+		if isHeaderBlock(b) {
+			item := &OutlineItem{
+				Level:   tocIndent(b) + 1,
+				Title:   InlineToText(b.InlineContent),
+				BlockID: b.ID,
+				Block:   b,
+			}
+			for len(stack) > 0 && stack[len(stack)-1].Level >= item.Level {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 {
+				res = append(res, item)
+			} else {
+				top := stack[len(stack)-1]
+				top.Children = append(top.Children, item)
+			}
+			stack = append(stack, item)
+		}
+		return !opts.SkipToggles || !b.IsToggleable()
+	})
+	return res
+}
@@ -0,0 +1,61 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutline(t *testing.T) {
+	header := func(typ string, s string, children ...*Block) *Block {
+		b := textBlock(typ, s, children...)
+		b.ID = s
+		return b
+	}
+	toggleable := header(BlockSubHeader, "toggleable", header(BlockSubSubHeader, "in toggleable"))
+	toggleable.FormatHeader = &FormatHeader{Toggleable: true}
+	factory := &Block{
+		Type:            BlockFactory,
+		TemplateContent: []*Block{header(BlockHeader, "template")},
+	}
+	page := testPage(
+		header(BlockHeader, "one"),
+		header(BlockSubSubHeader, "one.a"),
+		header(BlockSubHeader, "one.b"),
+		textBlock(BlockToggle, "toggle", header(BlockSubSubHeader, "in toggle")),
+		header(BlockHeader, "two"),
+		toggleable,
+		factory,
+		textBlock(BlockPage, "sub page", header(BlockHeader, "in sub page")),
+	)
+
+	var dump func(items []*OutlineItem) []interface{}
+	dump = func(items []*OutlineItem) []interface{} {
+		var res []interface{}
+		for _, item := range items {
+			assert.Equal(t, item.Title, item.BlockID)
+			res = append(res, item.Level, item.Title)
+			if len(item.Children) > 0 {
+				res = append(res, dump(item.Children))
+			}
+		}
+		return res
+	}
+	exp := []interface{}{
+		1, "one", []interface{}{
+			3, "one.a",
+			2, "one.b", []interface{}{3, "in toggle"},
+		},
+		1, "two", []interface{}{
+			2, "toggleable", []interface{}{3, "in toggleable"},
+		},
+	}
+	assert.Equal(t, exp, dump(page.Outline()))
+
+	exp = []interface{}{
+		1, "one", []interface{}{3, "one.a", 2, "one.b"},
+		1, "two", []interface{}{2, "toggleable"},
+	}
+	assert.Equal(t, exp, dump(page.OutlineWithOptions(&OutlineOptions{SkipToggles: true})))
+	assert.Nil(t, testPage().Outline())
+}
@@ -5,14 +5,26 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 var (
-	// TODO: add more values, see FormatPage struct
+	// keys of FormatPage
 	validFormatValues = map[string]struct{}{
-		"page_full_width": struct{}{},
-		"page_small_text": struct{}{},
+		"page_cover":              struct{}{},
+		"page_cover_position":     struct{}{},
+		"page_font":               struct{}{},
+		"page_full_width":         struct{}{},
+		"page_icon":               struct{}{},
+		"page_small_text":         struct{}{},
+		"block_color":             struct{}{},
+		"block_locked":            struct{}{},
+		"block_locked_by":         struct{}{},
+		"page_section_visibility": struct{}{},
 	}
 )
 
@@ -24,8 +36,58 @@ type Page struct {
 	// Users allows to find users that Page refers to by their ID
 	Users  []*User
 	Tables []*Table
+	// Ancestors are pages, databases and the workspace that contain the
+	// page, starting with the workspace. Only set by DownloadPage if the page
+	// has BlockBreadcrumb, see Client.GetAncestors
+	Ancestors []*Ancestor
+	// comment threads on blocks of the page. Only set if the page was
+	// downloaded with DownloadPageOptions.IncludeDiscussions
+	Discussions []*Discussion
+	// problems found when parsing the page that didn't prevent
+	// downloading it, like a block with invalid format
+	Warnings []*Warning
 
 	client *Client
+	// true if Discussions were downloaded
+	discussionsLoaded bool
+	// index for BlockByID, see setBlockIndex
+	blocks  map[string]*Block
+	orphans map[string]bool
+}
+
+// Warning describes a non-fatal problem with a block
+type Warning struct {
+	BlockID string
+	Message string
+	// set if the problem is invalid format of the block
+	ParseWarning *ParseWarning
+}
+
+// ParseWarning describes a block whose format couldn't be decoded. The
+// block is still part of the page but its Format* field is nil
+type ParseWarning struct {
+	BlockID   string
+	BlockType string
+	// FormatRaw of the block
+	Raw json.RawMessage
+	Err error
+}
+
+// ParseWarnings returns blocks of the page whose format couldn't be
+// decoded
+func (p *Page) ParseWarnings() []ParseWarning {
+	var res []ParseWarning
+	for _, w := range p.Warnings {
+		if w.ParseWarning != nil {
+			res = append(res, *w.ParseWarning)
+		}
+	}
+	return res
+}
+
+// String returns a text version of the warning
+func (w *Warning) String() string {
+	return fmt.Sprintf("block %s: %s", w.BlockID, w.Message)
 }
 
 // Table represents a table (i.e. CollectionView)
@@ -33,18 +95,72 @@ type Table struct {
 	CollectionView *CollectionView `json:"collection_view"`
 	Collection     *Collection     `json:"collection"`
 	Data           []*Block
+	// for grouped views (e.g. board) rows in Data split into groups. Only
+	// set for views grouped by a select or multi_select column and if
+	// the server supports reducer queries (see Compat.UseReducerQuery)
+	Groups []*TableGroup `json:"groups,omitempty"`
+	// set if querying the collection view failed when downloading the
+	// page. Data is empty then
+	Err error `json:"-"`
+
+	client *Client
+}
+
+// LastEdited returns the most recent time the page or any of its blocks
+// was edited. Editing a block doesn't always update last_edited_time of
+// the page so this is better for checking if the page changed
+func (p *Page) LastEdited() time.Time {
+	var res int64
+	p.ForEachBlockWithParent(func(b, parent *Block) bool {
+		// sub-pages are separate pages and editing them changes
+		// their last_edited_time
+		if parent != nil && b.Type == BlockPage {
+			return false
+		}
+		if b.LastEditedTime > res {
+			res = b.LastEditedTime
+		}
+		return true
+	})
+	return notionTime(res)
+}
+
+// UnknownTypes returns sorted types of blocks in the page that this library
+// doesn't know about (see Block.IsUnknownType)
+func (p *Page) UnknownTypes() []string {
+	seen := map[string]bool{}
+	p.ForEachBlock(func(b *Block) {
+		if b.IsUnknownType() {
+			seen[b.Type] = true
+		}
+	})
+	var res []string
+	for typ := range seen {
+		res = append(res, typ)
+	}
+	sort.Strings(res)
+	return res
 }
 
 // SetTitle changes page title
 func (p *Page) SetTitle(s string) error {
+	if p.client == nil {
+		return ErrOfflinePage
+	}
 	op := buildSetTitleOp(p.Root.ID, s)
 	ops := []*Operation{op}
 	return p.client.SubmitTransaction(ops)
 }
 
 // SetFormat changes format properties of a page. Valid values are:
-// page_full_width (bool), page_small_text (bool)
+// page_cover (string), page_cover_position (float64), page_font (string),
+// page_full_width (bool), page_icon (string), page_small_text (bool),
+// block_color (string), block_locked (bool), block_locked_by (string) and
+// page_section_visibility (map[string]string)
 func (p *Page) SetFormat(args map[string]interface{}) error {
+	if p.client == nil {
+		return ErrOfflinePage
+	}
 	if len(args) == 0 {
 		return errors.New("args can't be empty")
 	}
@@ -66,11 +182,7 @@ func getFirstInline(inline []*InlineBlock) string {
 }
 
 func getFirstInlineBlock(v interface{}) (string, error) {
-	inline, err := parseInlineBlocks(v)
-	if err != nil {
-		return "", err
-	}
-	return getFirstInline(inline), nil
+	return firstInlineText(v)
 }
 
 func getProp(block *Block, name string, toSet *string) bool {
@@ -95,6 +207,8 @@ func parseProperties(block *Block) error {
 			block.Title, err = getFirstInlineBlock(title)
 		} else if block.Type == BlockCode {
 			block.Code, err = getFirstInlineBlock(title)
+		} else if block.Type == BlockEquation {
+			block.Equation, err = getFirstInlineBlock(title)
 		} else {
 			block.InlineContent, err = parseInlineBlocks(title)
 		}
@@ -104,11 +218,9 @@ func parseProperties(block *Block) error {
 	}
 
 	if BlockTodo == block.Type {
-		if checked, ok := props["checked"]; ok {
-			s, _ := getFirstInlineBlock(checked)
-			// fmt.Printf("checked: '%s'\n", s)
-			block.IsChecked = strings.EqualFold(s, "Yes")
-		}
+		checked, ok := props["checked"]
+		block.HasCheckedProperty = ok
+		block.IsChecked = ok && parseChecked(checked)
 	}
 
 	// for BlockBookmark
@@ -116,7 +228,8 @@ func parseProperties(block *Block) error {
 	// for BlockBookmark
 	getProp(block, "link", &block.Link)
 
-	// for BlockBookmark, BlockImage, BlockGist, BlockFile, BlockEmbed
+	// for BlockBookmark, BlockImage, BlockGist, BlockFile, BlockEmbed,
+	// BlockAudio, BlockPDF
 	// don't over-write if was already set from "source" json field
 	if block.Source == "" {
 		getProp(block, "source", &block.Source)
@@ -126,17 +239,96 @@ func parseProperties(block *Block) error {
 		block.ImageURL = makeImageURL(block.Source)
 	}
 
+	if block.Type == BlockTweet {
+		block.TweetID = tweetIDFromURL(block.Source)
+	}
+	if block.Type == BlockMaps {
+		block.Lat, block.Lng = mapCoordinatesFromURL(block.Source)
+	}
+
 	// for BlockCode
 	getProp(block, "language", &block.CodeLanguage)
 
-	// for BlockFile
-	if block.Type == BlockFile {
+	// for BlockFile, BlockAudio and BlockPDF
+	if block.Type == BlockFile || block.Type == BlockAudio || block.Type == BlockPDF {
 		getProp(block, "size", &block.FileSize)
 	}
 
 	return nil
 }
 
+// tweetIDFromURL returns id of the tweet from url like
+// https://twitter.com/${user}/status/${id}, "" if it's not a tweet url
+func tweetIDFromURL(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if parts[i] == "status" || parts[i] == "statuses" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// parseLatLng parses "${lat},${lng}"
+func parseLatLng(s string) (*float64, *float64) {
+	parts := strings.Split(s, ",")
+	if len(parts) < 2 {
+		return nil, nil
+	}
+	lat, err1 := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	lng, err2 := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err1 != nil || err2 != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return nil, nil
+	}
+	return &lat, &lng
+}
+
+// mapCoordinatesFromURL returns coordinates from Google Maps url like
+// https://www.google.com/maps/place/Paris/@48.8588,2.2770,12z or
+// https://maps.google.com/?q=48.8588,2.2770. Returns nils if url doesn't
+// have them
+func mapCoordinatesFromURL(uri string) (*float64, *float64) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, nil
+	}
+	for _, part := range strings.Split(u.Path, "/") {
+		if strings.HasPrefix(part, "@") {
+			if lat, lng := parseLatLng(part[1:]); lat != nil {
+				return lat, lng
+			}
+		}
+	}
+	q := u.Query()
+	for _, name := range []string{"q", "ll", "center"} {
+		if lat, lng := parseLatLng(q.Get(name)); lat != nil {
+			return lat, lng
+		}
+	}
+	return nil, nil
+}
+
+// ImageProxyURL is the prefix of urls of images proxied via notion server
+// (see Block.ImageURL)
+var ImageProxyURL = "https://www.notion.so/image/"
+
+// isNotionAttachment returns true if uri is a file uploaded to Notion.
+// Those can only be accessed with signed urls
+func isNotionAttachment(uri string) bool {
+	return strings.Contains(uri, "secure.notion-static.com/")
+}
+
+// notionSignedURL returns url that redirects to a signed url of a file
+// uploaded to Notion. It only works with cookies of a user that can
+// access the block
+func notionSignedURL(uri string, blockID string) string {
+	return "https://www.notion.so/signed/" + url.PathEscape(uri) + "?table=block&id=" + blockID
+}
+
 // sometimes image url in "source" is not accessible but can
 // be accessed when proxied via notion server as
 // www.notion.so/image/${source}
@@ -145,17 +337,158 @@ func parseProperties(block *Block) error {
 // from: /images/page-cover/met_vincent_van_gogh_cradle.jpg
 // =>
 // https://www.notion.so/image/https%3A%2F%2Fwww.notion.so%2Fimages%2Fpage-cover%2Fmet_vincent_van_gogh_cradle.jpg?width=3290
+//
+// Files uploaded to Notion (see isNotionAttachment) are not changed because
+// the proxy requires authentication for them.
+//
+// All urls of images set when parsing pages (Block.ImageURL,
+// FormatPage.PageCoverURL etc.) and used by renderers are made here, with
+// DefaultImageWidth, so that the same source always gives the same url
 func makeImageURL(uri string) string {
-	if uri == "" || strings.Contains(uri, "//www.notion.so/image/") {
+	if uri == "" {
+		return ""
+	}
+	return imageURLs.get(uri, DefaultImageWidth)
+}
+
+// DefaultImageWidth, if > 0, is width of images in urls set when parsing
+// pages, like Block.ImageURL. The proxy resizes images to this width
+var DefaultImageWidth = 0
+
+// maxImageURLCacheSize is the maximum number of urls remembered by
+// imageURLCache. When reached, the cache is cleared
+const maxImageURLCacheSize = 4096
+
+type imageURLKey struct {
+	proxy string
+	uri   string
+	width int
+}
+
+// imageURLCache remembers urls made by makeImageURL. Pages often use the
+// same image many times, e.g. as icons, and this saves escaping it again
+// and memory because the blocks share the url
+type imageURLCache struct {
+	mu   sync.Mutex
+	urls map[imageURLKey]string
+}
+
+var imageURLs = &imageURLCache{}
+
+func (c *imageURLCache) get(uri string, width int) string {
+	key := imageURLKey{
+		proxy: ImageProxyURL,
+		uri:   uri,
+		width: width,
+	}
+	c.mu.Lock()
+	res, ok := c.urls[key]
+	c.mu.Unlock()
+	if ok {
+		return res
+	}
+	res = MakeImageURL(uri, &ImageURLOptions{Width: width})
+	c.mu.Lock()
+	if c.urls == nil || len(c.urls) >= maxImageURLCacheSize {
+		c.urls = map[imageURLKey]string{}
+	}
+	c.urls[key] = res
+	c.mu.Unlock()
+	return res
+}
+
+// ImageURLOptions describes options for MakeImageURL
+type ImageURLOptions struct {
+	// if > 0, the proxy resizes the image to this width
+	Width int
+	// id of the block with the image (or the page with the cover). Files
+	// uploaded to Notion are only proxied if it's set
+	BlockID string
+	// optional id of the workspace of the block
+	SpaceID string
+}
+
+// MakeImageURL returns url of an image proxied via notion server (see
+// ImageProxyURL). Urls that are already proxied are only changed to add
+// width. Relative urls, like those of built-in covers, are made absolute.
+// Files uploaded to Notion are proxied only if opts.BlockID is set, the
+// proxy needs it to check access (and a logged in user). opts can be nil
+func MakeImageURL(uri string, opts *ImageURLOptions) string {
+	if opts == nil {
+		opts = &ImageURLOptions{}
+	}
+	if uri == "" {
+		return ""
+	}
+	if strings.HasPrefix(uri, ImageProxyURL) || strings.Contains(uri, "//www.notion.so/image/") {
+		return addImageWidth(uri, opts.Width)
+	}
+	isAttachment := isNotionAttachment(uri)
+	if isAttachment && opts.BlockID == "" {
 		return uri
 	}
 	// if the url has https://, it's already in s3.
 	// If not, it's only a relative URL (like those for built-in
 	// cover pages)
-	if !strings.HasPrefix(uri, "https://") {
+	if !strings.HasPrefix(uri, "https://") && !strings.HasPrefix(uri, "http://") {
 		uri = "https://www.notion.so" + uri
 	}
-	return "https://www.notion.so/image/" + url.PathEscape(uri)
+	// don't double-encode urls that are already encoded
+	if unescaped, err := url.PathUnescape(uri); err == nil {
+		uri = unescaped
+	}
+	res := joinURL(ImageProxyURL, url.PathEscape(uri))
+	if isAttachment {
+		q := url.Values{}
+		q.Set("table", TableBlock)
+		q.Set("id", opts.BlockID)
+		if opts.SpaceID != "" {
+			q.Set("spaceId", opts.SpaceID)
+		}
+		res += "?" + q.Encode()
+	}
+	return addImageWidth(res, opts.Width)
+}
+
+// addImageWidth adds width argument to url of proxied image
+func addImageWidth(uri string, width int) string {
+	if width <= 0 {
+		return uri
+	}
+	sep := "?"
+	if strings.Contains(uri, "?") {
+		sep = "&"
+	}
+	return uri + sep + "width=" + strconv.Itoa(width)
+}
+
+// setVideoURL sets VideoURL and IsUpload of format of BlockVideo. Embeds
+// are used as they are, the image proxy doesn't work for youtube etc.
+func setVideoURL(block *Block, format *FormatVideo) {
+	format.IsUpload = isNotionAttachment(block.Source) || isNotionAttachment(format.DisplaySource)
+	if format.IsUpload {
+		format.VideoURL = block.Source
+		if format.VideoURL == "" {
+			format.VideoURL = format.DisplaySource
+		}
+		return
+	}
+	format.VideoURL = format.DisplaySource
+	if format.VideoURL == "" {
+		format.VideoURL = block.Source
+	}
+}
+
+// hasTypedFormat returns true if format of the block was decoded into one
+// of Format* structs
+func (b *Block) hasTypedFormat() bool {
+	return b.FormatPage != nil || b.FormatBookmark != nil || b.FormatImage != nil ||
+		b.FormatColumn != nil || b.FormatText != nil || b.FormatHeader != nil ||
+		b.FormatTable != nil || b.FormatVideo != nil || b.FormatAudio != nil ||
+		b.FormatPDF != nil || b.FormatDrive != nil || b.FormatAlias != nil ||
+		b.FormatEmbed != nil || b.FormatCallout != nil || b.FormatList != nil ||
+		b.FormatTableOfContents != nil || b.FormatTransclusionReference != nil ||
+		b.FormatTableBlock != nil
 }
 
 func parseFormat(block *Block) error {
@@ -167,59 +500,127 @@ func parseFormat(block *Block) error {
 	switch block.Type {
 	case BlockPage:
 		var format FormatPage
-		err = json.Unmarshal(block.FormatRaw, &format)
+		err = block.DecodeFormat(&format)
 		if err == nil {
 			format.PageCoverURL = makeImageURL(format.PageCover)
+			if isIconURL(format.PageIcon) {
+				format.PageIconURL = makeImageURL(format.PageIcon)
+			}
 			block.FormatPage = &format
 		}
 	case BlockBookmark:
 		var format FormatBookmark
-		err = json.Unmarshal(block.FormatRaw, &format)
+		err = block.DecodeFormat(&format)
 		if err == nil {
+			format.BookmarkCoverURL = makeImageURL(format.BookmarkCover)
 			block.FormatBookmark = &format
 		}
 	case BlockImage:
 		var format FormatImage
-		err = json.Unmarshal(block.FormatRaw, &format)
+		err = block.DecodeFormat(&format)
 		if err == nil {
 			format.ImageURL = makeImageURL(format.DisplaySource)
 			block.FormatImage = &format
 		}
 	case BlockColumn:
 		var format FormatColumn
-		err = json.Unmarshal(block.FormatRaw, &format)
+		err = block.DecodeFormat(&format)
 		if err == nil {
 			block.FormatColumn = &format
 		}
 	case BlockTable:
 		var format FormatTable
-		err = json.Unmarshal(block.FormatRaw, &format)
+		var formatBlock FormatTableBlock
+		err = block.DecodeFormat(&format)
+		if err == nil {
+			err = block.DecodeFormat(&formatBlock)
+		}
 		if err == nil {
 			block.FormatTable = &format
+			block.FormatTableBlock = &formatBlock
 		}
-	case BlockText:
+	case BlockText, BlockToggle, BlockQuote:
 		var format FormatText
-		err = json.Unmarshal(block.FormatRaw, &format)
+		err = block.DecodeFormat(&format)
 		if err == nil {
 			block.FormatText = &format
 		}
+	case BlockBulletedList, BlockNumberedList:
+		var format FormatList
+		err = block.DecodeFormat(&format)
+		if err == nil {
+			block.FormatList = &format
+		}
+	case BlockTableOfContents:
+		var format FormatTableOfContents
+		err = block.DecodeFormat(&format)
+		if err == nil {
+			block.FormatTableOfContents = &format
+		}
+	case BlockCallout:
+		var format FormatCallout
+		err = block.DecodeFormat(&format)
+		if err == nil {
+			block.FormatCallout = &format
+		}
+	case BlockHeader, BlockSubHeader, BlockSubSubHeader:
+		var format FormatHeader
+		err = block.DecodeFormat(&format)
+		if err == nil {
+			block.FormatHeader = &format
+		}
 	case BlockVideo:
 		var format FormatVideo
-		err = json.Unmarshal(block.FormatRaw, &format)
+		err = block.DecodeFormat(&format)
 		if err == nil {
+			setVideoURL(block, &format)
 			block.FormatVideo = &format
 		}
-	case BlockEmbed:
-		var format FormatEmbed
-		err = json.Unmarshal(block.FormatRaw, &format)
+	case BlockAudio:
+		var format FormatAudio
+		err = block.DecodeFormat(&format)
+		if err == nil {
+			block.FormatAudio = &format
+		}
+	case BlockPDF:
+		var format FormatPDF
+		err = block.DecodeFormat(&format)
+		if err == nil {
+			block.FormatPDF = &format
+		}
+	case BlockAlias:
+		var format FormatAlias
+		err = block.DecodeFormat(&format)
+		if err == nil {
+			block.FormatAlias = &format
+			if p := format.AliasPointer; p != nil {
+				block.AliasPageID = p.ID
+			}
+		}
+	case BlockTransclusionReference:
+		var format FormatTransclusionReference
+		err = block.DecodeFormat(&format)
+		if err == nil {
+			block.FormatTransclusionReference = &format
+		}
+	case BlockDrive:
+		var format FormatDrive
+		err = block.DecodeFormat(&format)
 		if err == nil {
-			block.FormatEmbed = &format
+			if p := format.DriveProperties; p != nil && p.Thumbnail != "" {
+				p.ThumbnailURL = makeImageURL(p.Thumbnail)
+			}
+			block.FormatDrive = &format
+		}
+	default:
+		if block.IsEmbedBlock() {
+			var format FormatEmbed
+			err = block.DecodeFormat(&format)
+			if err == nil {
+				block.FormatEmbed = &format
+			}
 		}
 	}
 
-	if err != nil {
-		fmt.Printf("parseFormat: json.Unamrshal() failed with '%s', format: '%s'\n", err, string(block.FormatRaw))
-		return err
-	}
-	return nil
+	return err
 }
@@ -0,0 +1,393 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+)
+
+const (
+	// ChangeAdded is BlockChange.Type of a block that was added
+	ChangeAdded = "added"
+	// ChangeRemoved is BlockChange.Type of a block that was removed
+	ChangeRemoved = "removed"
+	// ChangeModified is BlockChange.Type of a block that was modified
+	ChangeModified = "modified"
+	// ChangeMoved is BlockChange.Type of a block that was moved to a
+	// different parent or position
+	ChangeMoved = "moved"
+)
+
+const (
+	// FieldTitle is in BlockChange.Fields if text of the block changed
+	FieldTitle = "title"
+	// FieldProperties is in BlockChange.Fields if properties other than
+	// the title changed, e.g. values of a row
+	FieldProperties = "properties"
+	// FieldFormat is in BlockChange.Fields if format of the block changed
+	FieldFormat = "format"
+)
+
+// BlockChange describes a block that is different in two versions of a page
+type BlockChange struct {
+	BlockID string
+	// ChangeAdded, ChangeRemoved, ChangeModified or ChangeMoved
+	Type string
+	// nil for ChangeAdded
+	Old *Block
+	// nil for ChangeRemoved
+	New *Block
+
+	// for ChangeModified, FieldTitle, FieldProperties and FieldFormat
+	// depending on what changed
+	Fields []string
+	// for ChangeModified with FieldTitle, changes of the text
+	TextDiff []*TextChange
+
+	// for ChangeMoved, parent and index in Content of the parent in both
+	// versions
+	OldParentID string
+	NewParentID string
+	OldIndex    int
+	NewIndex    int
+}
+
+// PageDiff describes changes between two versions of a page. Blocks are
+// matched by id. A block can be both moved and modified
+type PageDiff struct {
+	// in the order they appear in the new version
+	Added []*BlockChange
+	// in the order they appeared in the old version
+	Removed []*BlockChange
+	// in the order they appear in the new version
+	Moved []*BlockChange
+	// in the order they appear in the new version
+	Modified []*BlockChange
+	// rows of tables that were added, removed or modified. Only set if
+	// both versions have Tables
+	Rows []*BlockChange
+
+	// all changes, see Changes
+	changes []*BlockChange
+}
+
+// TextChange is a part of text of a block that was added, removed or
+// didn't change
+type TextChange struct {
+	// ChangeAdded, ChangeRemoved or "" if the text didn't change
+	Type string
+	Text string
+}
+
+// IsEmpty returns true if nothing changed
+func (d *PageDiff) IsEmpty() bool {
+	return len(d.changes) == 0
+}
+
+// Changes returns all changes: added, modified and moved blocks in the
+// order they appear in the new version, then removed blocks, then changes
+// of rows
+func (d *PageDiff) Changes() []*BlockChange {
+	return d.changes
+}
+
+// position of a block in a version of the page
+type diffBlock struct {
+	block    *Block
+	parentID string
+	index    int
+}
+
+// diffBlocks returns blocks reachable from the root in depth first order
+// and their positions
+func diffBlocks(page *Page) ([]*Block, map[string]*diffBlock) {
+	var blocks []*Block
+	idToBlock := map[string]*diffBlock{}
+	var add func(b *Block, parentID string, idx int)
+	add = func(b *Block, parentID string, idx int) {
+		if b == nil || idToBlock[b.ID] != nil {
+			return
+		}
+		idToBlock[b.ID] = &diffBlock{block: b, parentID: parentID, index: idx}
+		blocks = append(blocks, b)
+		for i, child := range b.Content {
+			add(child, b.ID, i)
+		}
+	}
+	if page != nil {
+		add(page.Root, "", 0)
+	}
+	return blocks, idToBlock
+}
+
+// movedBlocks returns ids of blocks that have the same parent in both
+// versions but changed order relative to their siblings. Blocks that
+// only shifted because a sibling was added or removed are not moved
+func movedBlocks(oldBlocks map[string]*diffBlock, newBlocks []*Block, newIDToBlock map[string]*diffBlock) map[string]bool {
+	res := map[string]bool{}
+	for _, parent := range newBlocks {
+		prev := oldBlocks[parent.ID]
+		if prev == nil || len(parent.Content) == 0 {
+			continue
+		}
+		// children that are in this parent in both versions
+		inBoth := func(children []*Block, idToBlock map[string]*diffBlock) []string {
+			var ids []string
+			for _, child := range children {
+				if child == nil {
+					continue
+				}
+				o := oldBlocks[child.ID]
+				n := newIDToBlock[child.ID]
+				if o != nil && n != nil && o.parentID == parent.ID && n.parentID == parent.ID && idToBlock[child.ID].block == child {
+					ids = append(ids, child.ID)
+				}
+			}
+			return ids
+		}
+		a := inBoth(prev.block.Content, oldBlocks)
+		b := inBoth(parent.Content, newIDToBlock)
+		_, inLCS := lcsMatches(len(a), len(b), func(i, j int) bool {
+			return a[i] == b[j]
+		})
+		for i, id := range b {
+			if !inLCS[i] {
+				res[id] = true
+			}
+		}
+	}
+	return res
+}
+
+// lcsMatches finds the longest common subsequence of sequences of length
+// n and m and returns which elements of each are part of it
+func lcsMatches(n, m int, eq func(i, j int) bool) ([]bool, []bool) {
+	// lengths[i][j] is the length of lcs of a[i:] and b[j:]
+	lengths := make([][]int, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if eq(i, j) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	inA := make([]bool, n)
+	inB := make([]bool, m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case eq(i, j):
+			inA[i] = true
+			inB[j] = true
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return inA, inB
+}
+
+var reTextTokens = regexp.MustCompile(`\s+|[^\s]+`)
+
+// DiffText returns changes between two versions of a text, by words
+func DiffText(old string, new string) []*TextChange {
+	var res []*TextChange
+	add := func(typ string, s string) {
+		if s == "" {
+			return
+		}
+		if n := len(res); n > 0 && res[n-1].Type == typ {
+			res[n-1].Text += s
+			return
+		}
+		res = append(res, &TextChange{Type: typ, Text: s})
+	}
+	a := reTextTokens.FindAllString(old, -1)
+	b := reTextTokens.FindAllString(new, -1)
+	// common prefix and suffix don't need lcs
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	for _, s := range a[:prefix] {
+		add("", s)
+	}
+	midA := a[prefix : len(a)-suffix]
+	midB := b[prefix : len(b)-suffix]
+	inA, inB := lcsMatches(len(midA), len(midB), func(i, j int) bool {
+		return midA[i] == midB[j]
+	})
+	i, j := 0, 0
+	for i < len(midA) || j < len(midB) {
+		switch {
+		case i < len(midA) && !inA[i]:
+			add(ChangeRemoved, midA[i])
+			i++
+		case j < len(midB) && !inB[j]:
+			add(ChangeAdded, midB[j])
+			j++
+		default:
+			add("", midB[j])
+			i++
+			j++
+		}
+	}
+	for _, s := range a[len(a)-suffix:] {
+		add("", s)
+	}
+	return res
+}
+
+func blockText(b *Block) string {
+	if len(b.InlineContent) > 0 {
+		return InlineToText(b.InlineContent)
+	}
+	return pageTitle(b)
+}
+
+// propertiesWithoutTitle returns properties of the block other than the
+// title, which is compared as text
+func propertiesWithoutTitle(b *Block) map[string]interface{} {
+	res := map[string]interface{}{}
+	for k, v := range b.Properties {
+		if k != "title" {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+func isJSONEqual(a, b json.RawMessage) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return string(a) == string(b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// modifiedBlock returns ChangeModified change if title, properties or format
+// of the block changed, nil otherwise
+func modifiedBlock(old *Block, new *Block) *BlockChange {
+	var fields []string
+	var textDiff []*TextChange
+	if oldText, newText := blockText(old), blockText(new); oldText != newText {
+		fields = append(fields, FieldTitle)
+		textDiff = DiffText(oldText, newText)
+	}
+	if !reflect.DeepEqual(propertiesWithoutTitle(old), propertiesWithoutTitle(new)) {
+		fields = append(fields, FieldProperties)
+	}
+	if !isJSONEqual(old.FormatRaw, new.FormatRaw) {
+		fields = append(fields, FieldFormat)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return &BlockChange{
+		BlockID:  new.ID,
+		Type:     ChangeModified,
+		Old:      old,
+		New:      new,
+		Fields:   fields,
+		TextDiff: textDiff,
+	}
+}
+
+// tableRows returns rows of tables in the page, a row in many tables once
+func tableRows(page *Page) ([]*Block, map[string]*Block) {
+	var rows []*Block
+	idToRow := map[string]*Block{}
+	for _, ref := range page.RowPages() {
+		rows = append(rows, ref.Block)
+		idToRow[ref.ID] = ref.Block
+	}
+	return rows, idToRow
+}
+
+func diffRows(old *Page, page *Page) []*BlockChange {
+	var res []*BlockChange
+	oldRows, oldIDToRow := tableRows(old)
+	newRows, newIDToRow := tableRows(page)
+	for _, row := range newRows {
+		prev := oldIDToRow[row.ID]
+		if prev == nil {
+			res = append(res, &BlockChange{BlockID: row.ID, Type: ChangeAdded, New: row})
+		} else if change := modifiedBlock(prev, row); change != nil {
+			res = append(res, change)
+		}
+	}
+	for _, row := range oldRows {
+		if newIDToRow[row.ID] == nil {
+			res = append(res, &BlockChange{BlockID: row.ID, Type: ChangeRemoved, Old: row})
+		}
+	}
+	return res
+}
+
+// DiffPages returns changes in page compared to old version of the page.
+// Blocks are added, removed, moved to a different parent or position
+// among siblings, or modified if their title, properties or format
+// changed. Rows of tables are compared if both versions have Tables
+func DiffPages(old *Page, page *Page) *PageDiff {
+	res := &PageDiff{}
+	oldBlocks, oldIDToBlock := diffBlocks(old)
+	newBlocks, newIDToBlock := diffBlocks(page)
+	moved := movedBlocks(oldIDToBlock, newBlocks, newIDToBlock)
+	for _, b := range newBlocks {
+		prev := oldIDToBlock[b.ID]
+		if prev == nil {
+			change := &BlockChange{BlockID: b.ID, Type: ChangeAdded, New: b}
+			res.Added = append(res.Added, change)
+			res.changes = append(res.changes, change)
+			continue
+		}
+		if change := modifiedBlock(prev.block, b); change != nil {
+			res.Modified = append(res.Modified, change)
+			res.changes = append(res.changes, change)
+		}
+		curr := newIDToBlock[b.ID]
+		if prev.parentID != curr.parentID || moved[b.ID] {
+			change := &BlockChange{
+				BlockID:     b.ID,
+				Type:        ChangeMoved,
+				Old:         prev.block,
+				New:         b,
+				OldParentID: prev.parentID,
+				NewParentID: curr.parentID,
+				OldIndex:    prev.index,
+				NewIndex:    curr.index,
+			}
+			res.Moved = append(res.Moved, change)
+			res.changes = append(res.changes, change)
+		}
+	}
+	for _, b := range oldBlocks {
+		if newIDToBlock[b.ID] == nil {
+			change := &BlockChange{BlockID: b.ID, Type: ChangeRemoved, Old: b}
+			res.Removed = append(res.Removed, change)
+			res.changes = append(res.changes, change)
+		}
+	}
+	if old != nil && page != nil && len(old.Tables) > 0 && len(page.Tables) > 0 {
+		res.Rows = diffRows(old, page)
+		res.changes = append(res.changes, res.Rows...)
+	}
+	return res
+}
@@ -0,0 +1,88 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffText(t *testing.T) {
+	got := DiffText("the quick brown fox", "the slow brown fox jumps")
+	exp := []*TextChange{
+		{Text: "the "},
+		{Type: ChangeRemoved, Text: "quick"},
+		{Type: ChangeAdded, Text: "slow"},
+		{Text: " brown fox"},
+		{Type: ChangeAdded, Text: " jumps"},
+	}
+	assert.Equal(t, exp, got)
+	assert.Equal(t, []*TextChange{{Text: "same"}}, DiffText("same", "same"))
+}
+
+func TestDiffPages(t *testing.T) {
+	newPage := func(second string, format string) *Page {
+		block := func(id string, s string, children ...*Block) *Block {
+			b := textBlock(BlockText, s, children...)
+			b.ID = id
+			return b
+		}
+		a := block("a", "first")
+		b := block("b", second)
+		b.FormatRaw = json.RawMessage(format)
+		c := block("c", "third")
+		d := block("d", "fourth")
+		if second == "second" {
+			// old version: a, b, c, list(d), removed
+			return testPage(a, b, c, block("list", "", d), block("removed", "gone"))
+		}
+		// new version: b, a, c, list(), added(d)
+		return testPage(b, a, c, block("list", ""), block("added", "new", d))
+	}
+	old := newPage("second", `{"block_color":"red"}`)
+	page := newPage("second block", `{ "block_color": "blue" }`)
+	diff := DiffPages(old, page)
+	assert.False(t, diff.IsEmpty())
+
+	var changes []string
+	for _, c := range diff.Changes() {
+		changes = append(changes, c.BlockID+" "+c.Type)
+	}
+	// b and a swapped, c only shifted
+	exp := []string{"b modified", "a moved", "added added", "d moved", "removed removed"}
+	assert.Equal(t, exp, changes)
+
+	mod := diff.Modified[0]
+	assert.Equal(t, []string{FieldTitle, FieldFormat}, mod.Fields)
+	assert.Equal(t, []*TextChange{{Text: "second"}, {Type: ChangeAdded, Text: " block"}}, mod.TextDiff)
+	moved := diff.Moved[1]
+	assert.Equal(t, "d", moved.BlockID)
+	assert.Equal(t, "list", moved.OldParentID)
+	assert.Equal(t, "added", moved.NewParentID)
+	assert.Nil(t, diff.Rows)
+
+	assert.True(t, DiffPages(old, newPage("second", `{"block_color": "red"}`)).IsEmpty())
+}
+
+func TestDiffPagesRows(t *testing.T) {
+	table := func(rows ...*Block) *Page {
+		page := testPage()
+		page.Tables = []*Table{{Data: rows}}
+		return page
+	}
+	old := table(testRow("1", "Todo"), testRow("2", "Todo"), testRow("3", ""))
+	page := table(testRow("1", "Done"), testRow("3", ""), testRow("4", ""))
+	page.Tables[0].Data[1].Properties["title"] = []interface{}{[]interface{}{"Row three"}}
+	diff := DiffPages(old, page)
+	var changes []string
+	for _, c := range diff.Rows {
+		changes = append(changes, c.BlockID+" "+c.Type)
+	}
+	assert.Equal(t, []string{"1 modified", "3 modified", "4 added", "2 removed"}, changes)
+	assert.Equal(t, []string{FieldProperties}, diff.Rows[0].Fields)
+	assert.Equal(t, []string{FieldTitle}, diff.Rows[1].Fields)
+	assert.Equal(t, diff.Rows, diff.Changes())
+
+	// rows are not compared if one version doesn't have tables
+	assert.True(t, DiffPages(testPage(), page).IsEmpty())
+}
@@ -0,0 +1,44 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+)
+
+// loadLargePage returns blocks of testdata/large_page.json, a response to
+// loadPageChunk for a page with 1000 blocks of different types
+func loadLargePage(tb testing.TB, d []byte) (*Block, map[string]*Block) {
+	var rsp LoadPageChunkResponse
+	if err := json.Unmarshal(d, &rsp); err != nil {
+		tb.Fatal(err)
+	}
+	var root *Block
+	idToBlock := map[string]*Block{}
+	for id, v := range rsp.RecordMap.Blocks {
+		idToBlock[id] = v.Value
+		if v.Value.Type == BlockPage {
+			root = v.Value
+		}
+	}
+	return root, idToBlock
+}
+
+// measures parsing of properties and formats of blocks of a page, without
+// decoding json
+func BenchmarkParsePage(b *testing.B) {
+	d, err := ioutil.ReadFile("testdata/large_page.json")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		root, idToBlock := loadLargePage(b, d)
+		b.StartTimer()
+		var warnings []*Warning
+		if err := resolveBlocks(root, idToBlock, &warnings); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,349 @@
+package notionapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// publicPermission returns permission of the page for everyone, nil if
+// the page is not shared publicly
+func (p *Page) publicPermission() *Permission {
+	if p.Root == nil {
+		return nil
+	}
+	for i := range p.Root.Permissions {
+		perm := &p.Root.Permissions[i]
+		if perm.Type == PermissionTypePublic && perm.Role != RoleNone {
+			return perm
+		}
+	}
+	return nil
+}
+
+// IsPublic returns true if the page is shared publicly. Permissions are
+// only in the root of the page that was shared, not in its sub-pages
+func (p *Page) IsPublic() bool {
+	return p.publicPermission() != nil
+}
+
+// UserRole returns a role (RoleEditor, RoleReader etc.) of a user in the
+// page based on permissions of the root of the page for the user or, if
+// there are none, for everyone. Returns "" if the user doesn't have
+// access to the page or gets access in a different way, e.g. as a member
+// of the space
+func (p *Page) UserRole(userID string) string {
+	if p.Root == nil {
+		return ""
+	}
+	for _, perm := range p.Root.Permissions {
+		if perm.Type == PermissionTypeUser && perm.UserID != "" && SameID(perm.UserID, userID) {
+			return perm.Role
+		}
+	}
+	if perm := p.publicPermission(); perm != nil {
+		return perm.Role
+	}
+	return ""
+}
+
+// ErrPublicSharingDisabled is returned (wrapped, together with APIError)
+// by Page.SetPublic when the space doesn't allow sharing pages publicly
+var ErrPublicSharingDisabled = errors.New("public sharing is disabled in the space")
+
+// PublicShareOptions describes options for Page.SetPublic
+type PublicShareOptions struct {
+	// role of everyone, RoleReader (the default), RoleCommentOnly or
+	// RoleReadAndWrite
+	Role string
+	// if true, search engines can index the page
+	AllowSearchEngineIndexing bool
+	// if true, anyone can duplicate the page as a template
+	AllowDuplicateAsTemplate bool
+}
+
+// permissionToJSON returns permission in the format used by
+// setPermissionItem operation
+func permissionToJSON(perm *Permission) map[string]interface{} {
+	res := map[string]interface{}{
+		"type": perm.Type,
+		"role": perm.Role,
+	}
+	if perm.UserID != "" {
+		res["user_id"] = perm.UserID
+	}
+	if perm.Type == PermissionTypePublic && perm.Role != RoleNone {
+		res["allow_duplicate"] = perm.AllowDuplicateAsTemplate
+		res["allow_search_engine_indexing"] = perm.AllowSearchEngineIndexing
+	}
+	return res
+}
+
+// buildSetPermissionOp returns operation used by the web client to change
+// who can access a block. Role RoleNone removes the permission
+func buildSetPermissionOp(id string, perm *Permission) *Operation {
+	return &Operation{
+		ID:      id,
+		Table:   TableBlock,
+		Path:    []string{"permissions"},
+		Command: "setPermissionItem",
+		Args:    permissionToJSON(perm),
+	}
+}
+
+// isPublicSharingDisabledError returns true if the server rejected
+// sharing a page because the space doesn't allow it
+func isPublicSharingDisabledError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.StatusCode < 400 || apiErr.StatusCode >= 500 {
+		return false
+	}
+	msg := strings.ToLower(apiErr.Message)
+	return strings.Contains(msg, "public") && (strings.Contains(msg, "disabled") || strings.Contains(msg, "not allowed"))
+}
+
+// SetPublic shares the page publicly ("Share to web" in Notion) or stops
+// sharing it. opts are only used when enabling and can be nil. Returns
+// an error wrapping ErrPublicSharingDisabled if the space doesn't allow
+// it
+func (p *Page) SetPublic(enabled bool, opts *PublicShareOptions) error {
+	if p.client == nil {
+		return ErrOfflinePage
+	}
+	if opts == nil {
+		opts = &PublicShareOptions{}
+	}
+	perm := Permission{
+		Type: PermissionTypePublic,
+		Role: RoleNone,
+	}
+	if enabled {
+		perm.Role = opts.Role
+		if perm.Role == "" {
+			perm.Role = RoleReader
+		}
+		perm.AllowSearchEngineIndexing = opts.AllowSearchEngineIndexing
+		perm.AllowDuplicateAsTemplate = opts.AllowDuplicateAsTemplate
+	}
+	ops := []*Operation{
+		buildSetPermissionOp(p.Root.ID, &perm),
+		buildLastEditedTimeOp(p.Root.ID),
+	}
+	err := p.client.SubmitTransaction(ops)
+	if err != nil {
+		if isPublicSharingDisabledError(err) {
+			return fmt.Errorf("%w: %w", ErrPublicSharingDisabled, err)
+		}
+		return err
+	}
+	var perms []Permission
+	for _, v := range p.Root.Permissions {
+		if v.Type != PermissionTypePublic {
+			perms = append(perms, v)
+		}
+	}
+	if enabled {
+		perms = append(perms, perm)
+	}
+	p.Root.Permissions = perms
+	return nil
+}
+
+// ErrInvalidRole is returned (wrapped) when a role is not one that can
+// be given to a user
+var ErrInvalidRole = errors.New("invalid role")
+
+// validateUserRole returns an error wrapping ErrInvalidRole if role
+// can't be given to a user
+func validateUserRole(role string) error {
+	switch role {
+	case RoleReader, RoleCommentOnly, RoleReadAndWrite, RoleEditor:
+		return nil
+	}
+	return fmt.Errorf("%w '%s', must be one of %s, %s, %s or %s", ErrInvalidRole, role, RoleReader, RoleCommentOnly, RoleReadAndWrite, RoleEditor)
+}
+
+// setLocalUserPermission updates permissions of the page root after
+// changing user's role on the server. Role RoleNone removes the
+// permission
+func (p *Page) setLocalUserPermission(userID string, role string) {
+	var perms []Permission
+	updated := false
+	for _, v := range p.Root.Permissions {
+		if v.Type == PermissionTypeUser && SameID(v.UserID, userID) {
+			if role == RoleNone || updated {
+				continue
+			}
+			v.Role = role
+			updated = true
+		}
+		perms = append(perms, v)
+	}
+	if !updated && role != RoleNone {
+		perms = append(perms, Permission{
+			Type:   PermissionTypeUser,
+			Role:   role,
+			UserID: userID,
+		})
+	}
+	p.Root.Permissions = perms
+}
+
+// InviteUser gives a user with a given email access to the page with a
+// role (RoleReader, RoleCommentOnly, RoleReadAndWrite or RoleEditor).
+// Like the web client, if there's no Notion account with this email,
+// Notion creates one and sends an invitation email. If the user already
+// has access to the page, their role is changed
+func (p *Page) InviteUser(email string, role string) error {
+	return p.InviteUserCtx(context.Background(), email, role)
+}
+
+// InviteUserCtx is like InviteUser but can be canceled with ctx
+func (p *Page) InviteUserCtx(ctx context.Context, email string, role string) error {
+	if p.client == nil {
+		return ErrOfflinePage
+	}
+	if err := validateUserRole(role); err != nil {
+		return err
+	}
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return errors.New("email is empty")
+	}
+	user, err := p.client.FindUserByEmailCtx(ctx, email)
+	if err != nil {
+		return err
+	}
+	var userID string
+	if user != nil {
+		userID = user.ID
+	} else {
+		userID, err = p.client.createEmailUser(ctx, email)
+		if err != nil {
+			return err
+		}
+	}
+	if err = p.submitUserRole(ctx, userID, role); err != nil {
+		return err
+	}
+	if user != nil && p.findUser(user.ID) == nil {
+		p.Users = append(p.Users, user)
+	}
+	return nil
+}
+
+// submitUserRole changes the role of a user in the page on the server and
+// in p.Root.Permissions. Role RoleNone removes user's access
+func (p *Page) submitUserRole(ctx context.Context, userID string, role string) error {
+	perm := Permission{
+		Type:   PermissionTypeUser,
+		Role:   role,
+		UserID: userID,
+	}
+	ops := []*Operation{
+		buildSetPermissionOp(p.Root.ID, &perm),
+		buildLastEditedTimeOp(p.Root.ID),
+	}
+	if err := p.client.SubmitTransactionCtx(ctx, ops); err != nil {
+		return err
+	}
+	p.setLocalUserPermission(userID, role)
+	return nil
+}
+
+// ErrLastEditor is returned by Page.SetUserRole and
+// Page.RemoveUserPermission when the change would leave the page without
+// anyone who can manage access to it. Use UserPermissionOptions.Force to
+// do it anyway
+var ErrLastEditor = errors.New("can't remove the last editor of the page")
+
+// UserPermissionOptions describes options for Page.SetUserRoleWithOptions
+// and Page.RemoveUserPermissionWithOptions
+type UserPermissionOptions struct {
+	// if true, allows removing or downgrading the last editor of the page
+	Force bool
+}
+
+// isLastEditor returns true if userID is the only editor of the page,
+// either directly or as members of the space
+func (p *Page) isLastEditor(userID string) bool {
+	isEditor := false
+	nEditors := 0
+	for _, perm := range p.Root.Permissions {
+		if perm.Role != RoleEditor {
+			continue
+		}
+		switch perm.Type {
+		case PermissionTypeUser:
+			nEditors++
+			if SameID(perm.UserID, userID) {
+				isEditor = true
+			}
+		case PermissionTypeSpace:
+			nEditors++
+		}
+	}
+	return isEditor && nEditors == 1
+}
+
+// SetUserRole changes the role of a user in the page to RoleReader,
+// RoleCommentOnly, RoleReadAndWrite or RoleEditor. Refuses to downgrade
+// the last editor with ErrLastEditor
+func (p *Page) SetUserRole(userID string, role string) error {
+	return p.SetUserRoleWithOptionsCtx(context.Background(), userID, role, nil)
+}
+
+// SetUserRoleWithOptions is like SetUserRole but with options. opts can be nil
+func (p *Page) SetUserRoleWithOptions(userID string, role string, opts *UserPermissionOptions) error {
+	return p.SetUserRoleWithOptionsCtx(context.Background(), userID, role, opts)
+}
+
+// SetUserRoleWithOptionsCtx is like SetUserRoleWithOptions but can be
+// canceled with ctx
+func (p *Page) SetUserRoleWithOptionsCtx(ctx context.Context, userID string, role string, opts *UserPermissionOptions) error {
+	if err := validateUserRole(role); err != nil {
+		return err
+	}
+	return p.changeUserRole(ctx, userID, role, opts)
+}
+
+// RemoveUserPermission removes user's access to the page. Refuses to
+// remove the last editor with ErrLastEditor
+func (p *Page) RemoveUserPermission(userID string) error {
+	return p.RemoveUserPermissionWithOptionsCtx(context.Background(), userID, nil)
+}
+
+// RemoveUserPermissionWithOptions is like RemoveUserPermission but with
+// options. opts can be nil
+func (p *Page) RemoveUserPermissionWithOptions(userID string, opts *UserPermissionOptions) error {
+	return p.RemoveUserPermissionWithOptionsCtx(context.Background(), userID, opts)
+}
+
+// RemoveUserPermissionWithOptionsCtx is like RemoveUserPermissionWithOptions
+// but can be canceled with ctx
+func (p *Page) RemoveUserPermissionWithOptionsCtx(ctx context.Context, userID string, opts *UserPermissionOptions) error {
+	return p.changeUserRole(ctx, userID, RoleNone, opts)
+}
+
+// changeUserRole validates and submits change of user's role, RoleNone
+// removes user's access
+func (p *Page) changeUserRole(ctx context.Context, userID string, role string, opts *UserPermissionOptions) error {
+	if p.client == nil {
+		return ErrOfflinePage
+	}
+	if opts == nil {
+		opts = &UserPermissionOptions{}
+	}
+	id, err := ToDashID(userID)
+	if err != nil {
+		return err
+	}
+	if role != RoleEditor && !opts.Force && p.isLastEditor(id) {
+		return ErrLastEditor
+	}
+	return p.submitUserRole(ctx, id, role)
+}
@@ -0,0 +1,175 @@
+package notionapi
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissions(t *testing.T) {
+	block := parseTestBlock(t, `{
+		"id": "c969c945-5d7c-4dd7-9c7f-860f3ace6429",
+		"type": "page",
+		"alive": true,
+		"permissions": [
+			{"role": "editor", "type": "user_permission", "user_id": "bb760e2d-d679-4b64-b2a9-03005b21870a"},
+			{"role": "read_and_write", "type": "space_permission"},
+			{"role": {"read_content": true}, "type": "user_permission", "user_id": "someone"},
+			{"role": "reader", "type": "public_permission", "allow_duplicate": true, "allow_search_engine_indexing": true}
+		]
+	}`)
+	exp := []Permission{
+		{Role: RoleEditor, Type: PermissionTypeUser, UserID: "bb760e2d-d679-4b64-b2a9-03005b21870a"},
+		{Role: RoleReadAndWrite, Type: PermissionTypeSpace},
+		{Type: PermissionTypeUser, UserID: "someone"},
+		{Role: RoleReader, Type: PermissionTypePublic, AllowDuplicateAsTemplate: true, AllowSearchEngineIndexing: true},
+	}
+	assert.Equal(t, exp, block.Permissions)
+
+	page := &Page{ID: block.ID, Root: block}
+	assert.True(t, page.IsPublic())
+	assert.Equal(t, RoleEditor, page.UserRole("bb760e2dd6794b64b2a903005b21870a"))
+	assert.Equal(t, RoleReader, page.UserRole("other"))
+
+	block.Permissions = block.Permissions[:3]
+	assert.False(t, page.IsPublic())
+	assert.Equal(t, "", page.UserRole("other"))
+
+	block.Permissions = append(block.Permissions, Permission{Role: RoleNone, Type: PermissionTypePublic})
+	assert.False(t, page.IsPublic())
+}
+
+func TestSetPublic(t *testing.T) {
+	c, transport := newStaticClient(200, `{}`)
+	page := testPage()
+	page.Root.Permissions = []Permission{{Role: RoleEditor, Type: PermissionTypeUser, UserID: "user"}}
+	assert.Equal(t, ErrOfflinePage, page.SetPublic(true, nil))
+
+	page.client = c
+	err := page.SetPublic(true, &PublicShareOptions{AllowSearchEngineIndexing: true})
+	assert.NoError(t, err)
+	assert.True(t, page.IsPublic())
+	exp := Permission{Role: RoleReader, Type: PermissionTypePublic, AllowSearchEngineIndexing: true}
+	assert.Equal(t, exp, page.Root.Permissions[1])
+	body := transport.requests[0]
+	assert.True(t, strings.Contains(body, `"path":["permissions"],"command":"setPermissionItem","args":{"allow_duplicate":false,"allow_search_engine_indexing":true,"role":"reader","type":"public_permission"}`), body)
+
+	err = page.SetPublic(false, nil)
+	assert.NoError(t, err)
+	assert.False(t, page.IsPublic())
+	assert.Equal(t, 1, len(page.Root.Permissions))
+	body = transport.requests[1]
+	assert.True(t, strings.Contains(body, `"args":{"role":"none","type":"public_permission"}`), body)
+
+	c, _ = newStaticClient(400, `{"errorId":"1","name":"ValidationError","message":"Public sharing is disabled for this workspace."}`)
+	page.client = c
+	err = page.SetPublic(true, nil)
+	assert.True(t, errors.Is(err, ErrPublicSharingDisabled), err)
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.False(t, page.IsPublic())
+}
+
+// inviteTransport answers findUser with user (none if empty) and records
+// paths and bodies of requests
+type inviteTransport struct {
+	user     string
+	paths    []string
+	requests []string
+}
+
+func (t *inviteTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, _ := readRequestBody(r)
+	t.paths = append(t.paths, path.Base(r.URL.Path))
+	t.requests = append(t.requests, body)
+	s := `{}`
+	switch path.Base(r.URL.Path) {
+	case "findUser":
+		if t.user != "" {
+			s = `{"value":{"role":"reader","value":` + t.user + `}}`
+		}
+	case "createEmailUser":
+		s = `{"userId":"22222222-2222-2222-2222-222222222222"}`
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(s)),
+		Request:    r,
+	}, nil
+}
+
+func TestInviteUser(t *testing.T) {
+	page := testPage()
+	assert.Equal(t, ErrOfflinePage, page.InviteUser("a@example.com", RoleReader))
+
+	transport := &inviteTransport{user: `{"id":"11111111-1111-1111-1111-111111111111","email":"a@example.com"}`}
+	page.client = &Client{HTTPClient: &http.Client{Transport: transport}, RetryPolicy: &NoRetryPolicy}
+	err := page.InviteUser("a@example.com", "owner")
+	assert.True(t, errors.Is(err, ErrInvalidRole), err)
+	assert.Equal(t, 0, len(transport.paths))
+
+	err = page.InviteUser("a@example.com", RoleCommentOnly)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"findUser", "submitTransaction"}, transport.paths)
+	assert.True(t, strings.Contains(transport.requests[1], `"args":{"role":"comment_only","type":"user_permission","user_id":"11111111-1111-1111-1111-111111111111"}`), transport.requests[1])
+	assert.Equal(t, RoleCommentOnly, page.UserRole("11111111111111111111111111111111"))
+	assert.Equal(t, 1, len(page.Users))
+
+	// inviting again changes the role
+	err = page.InviteUser("a@example.com", RoleEditor)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(page.Root.Permissions))
+	assert.Equal(t, RoleEditor, page.UserRole("11111111-1111-1111-1111-111111111111"))
+	assert.Equal(t, 1, len(page.Users))
+
+	// unknown email gets an invitation
+	transport.user = ""
+	transport.paths = nil
+	err = page.InviteUser("new@example.com", RoleReader)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"findUser", "createEmailUser", "submitTransaction"}, transport.paths)
+	assert.Equal(t, RoleReader, page.UserRole("22222222-2222-2222-2222-222222222222"))
+	assert.Equal(t, 2, len(page.Root.Permissions))
+}
+
+func TestSetUserRole(t *testing.T) {
+	const owner = "11111111-1111-1111-1111-111111111111"
+	const guest = "22222222-2222-2222-2222-222222222222"
+	page := testPage()
+	page.Root.Permissions = []Permission{
+		{Role: RoleEditor, Type: PermissionTypeUser, UserID: owner},
+		{Role: RoleReader, Type: PermissionTypeUser, UserID: guest},
+	}
+	assert.Equal(t, ErrOfflinePage, page.SetUserRole(guest, RoleEditor))
+
+	c, transport := newStaticClient(200, `{}`)
+	page.client = c
+	err := page.SetUserRole(guest, "admin")
+	assert.True(t, errors.Is(err, ErrInvalidRole), err)
+
+	err = page.SetUserRole("22222222222222222222222222222222", RoleCommentOnly)
+	assert.NoError(t, err)
+	assert.Equal(t, RoleCommentOnly, page.UserRole(guest))
+	assert.True(t, strings.Contains(transport.requests[0], `"args":{"role":"comment_only","type":"user_permission","user_id":"`+guest+`"}`), transport.requests[0])
+
+	assert.Equal(t, ErrLastEditor, page.RemoveUserPermission(owner))
+	assert.Equal(t, ErrLastEditor, page.SetUserRole(owner, RoleReader))
+	assert.Equal(t, 1, len(transport.requests))
+
+	err = page.RemoveUserPermission(guest)
+	assert.NoError(t, err)
+	assert.Equal(t, "", page.UserRole(guest))
+	assert.Equal(t, 1, len(page.Root.Permissions))
+	assert.True(t, strings.Contains(transport.requests[1], `"args":{"role":"none","type":"user_permission","user_id":"`+guest+`"}`), transport.requests[1])
+
+	err = page.RemoveUserPermissionWithOptions(owner, &UserPermissionOptions{Force: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(page.Root.Permissions))
+}
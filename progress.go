@@ -0,0 +1,111 @@
+package notionapi
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// ProgressEventType is the type of ProgressEvent
+type ProgressEventType int
+
+const (
+	// ProgressPageStarted is sent before the first request for a page
+	ProgressPageStarted ProgressEventType = iota + 1
+	// ProgressChunkFetched is sent after each request for blocks of a page
+	ProgressChunkFetched
+	// ProgressPageFinished is sent after a page was downloaded or failed
+	// to download, in which case ProgressEvent.Err is set
+	ProgressPageFinished
+)
+
+func (t ProgressEventType) String() string {
+	switch t {
+	case ProgressPageStarted:
+		return "page started"
+	case ProgressChunkFetched:
+		return "chunk fetched"
+	case ProgressPageFinished:
+		return "page finished"
+	}
+	return "unknown"
+}
+
+// ProgressEvent describes progress of downloading a page, see
+// DownloadPageOptions.ProgressFunc
+type ProgressEvent struct {
+	Type   ProgressEventType
+	PageID string
+	// number of blocks of the page downloaded so far
+	Blocks int
+	// number of bytes of http responses received for the page so far,
+	// as sent over the network. Like in Metrics, responses from Cassette
+	// or HTTPInterceptor are not counted
+	Bytes int64
+	// for ProgressPageFinished, the reason the download failed
+	Err error
+}
+
+// pageProgress reports progress of downloading a single page.
+// It's passed to requests for the page in their context so
+// that we can count bytes of their responses
+type pageProgress struct {
+	fn     func(ev ProgressEvent)
+	pageID string
+	// accessed atomically, updated when a response body is closed
+	bytes  int64
+	blocks int
+}
+
+type progressCtxKey struct{}
+
+func withPageProgress(ctx context.Context, p *pageProgress) context.Context {
+	return context.WithValue(ctx, progressCtxKey{}, p)
+}
+
+// progressFromContext returns progress of a page being downloaded with ctx,
+// nil if progress is not reported
+func progressFromContext(ctx context.Context) *pageProgress {
+	p, _ := ctx.Value(progressCtxKey{}).(*pageProgress)
+	return p
+}
+
+func (p *pageProgress) addBytes(n int64) {
+	atomic.AddInt64(&p.bytes, n)
+}
+
+func (p *pageProgress) send(typ ProgressEventType, err error) {
+	p.fn(ProgressEvent{
+		Type:   typ,
+		PageID: p.pageID,
+		Blocks: p.blocks,
+		Bytes:  atomic.LoadInt64(&p.bytes),
+		Err:    err,
+	})
+}
+
+// chunkFetched reports that we've got blocks of a page, blocks is the
+// number of blocks downloaded so far
+func (opts *DownloadPageOptions) chunkFetched(ctx context.Context, blocks int) {
+	if opts.Progress != nil {
+		opts.Progress(blocks, false)
+	}
+	if p := progressFromContext(ctx); p != nil {
+		p.blocks = blocks
+		p.send(ProgressChunkFetched, nil)
+	}
+}
+
+// serializeProgress returns ProgressFunc that doesn't call fn from
+// multiple goroutines at the same time
+func serializeProgress(fn func(ev ProgressEvent)) func(ev ProgressEvent) {
+	if fn == nil {
+		return nil
+	}
+	var mu sync.Mutex
+	return func(ev ProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		fn(ev)
+	}
+}
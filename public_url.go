@@ -0,0 +1,91 @@
+package notionapi
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxPublicPageHeadSize is how much of html of a public page we read
+// when looking for id of the page
+const maxPublicPageHeadSize = 512 * 1024
+
+var (
+	// <link rel="canonical" href="..."> and <meta property="og:url" content="...">
+	rxCanonicalURL = regexp.MustCompile(`(?i)<link[^>]+rel=["']canonical["'][^>]*>`)
+	rxOgURL        = regexp.MustCompile(`(?i)<meta[^>]+property=["']og:url["'][^>]*>`)
+	rxAttrURL      = regexp.MustCompile(`(?i)(?:href|content)=["']([^"']+)["']`)
+)
+
+// IsNotionURL returns true if uri is on notion.so or on a notion.site
+// domain of a workspace with published pages
+func IsNotionURL(uri string) bool {
+	if !strings.Contains(uri, "://") {
+		uri = "https://" + uri
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	return isNotionHost(strings.ToLower(u.Hostname()))
+}
+
+// ResolvePublicURL returns id of a page, in ToDashID format, from url of
+// a public page, including those on <workspace>.notion.site and custom
+// domains. If the url doesn't have the id, we follow the redirects and
+// look for it in canonical url of the page.
+// Returns *NoIDInURLError if the id can't be found
+func (c *Client) ResolvePublicURL(uri string) (string, error) {
+	return c.ResolvePublicURLCtx(context.Background(), uri)
+}
+
+// ResolvePublicURLCtx is like ResolvePublicURL but can be canceled with ctx
+func (c *Client) ResolvePublicURLCtx(ctx context.Context, uri string) (string, error) {
+	if id, err := ExtractNoDashIDFromNotionURL(uri); err == nil {
+		return ToDashID(id)
+	}
+	s := strings.TrimSpace(uri)
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	rsp, err := c.httpGet(ctx, s)
+	if err != nil {
+		return "", err
+	}
+	defer rsp.Body.Close()
+	if rsp.Request != nil && rsp.Request.URL != nil {
+		if id, err := ExtractNoDashIDFromNotionURL(rsp.Request.URL.String()); err == nil {
+			return ToDashID(id)
+		}
+	}
+	d, err := io.ReadAll(io.LimitReader(rsp.Body, maxPublicPageHeadSize))
+	if err != nil {
+		return "", err
+	}
+	if id := pageIDFromHTMLHead(string(d)); id != "" {
+		return ToDashID(id)
+	}
+	return "", &NoIDInURLError{URL: uri}
+}
+
+// pageIDFromHTMLHead returns id of a page from canonical or og:url url
+// in html of a public page. Returns "" if not found
+func pageIDFromHTMLHead(html string) string {
+	if i := strings.Index(strings.ToLower(html), "</head>"); i >= 0 {
+		html = html[:i]
+	}
+	for _, rx := range []*regexp.Regexp{rxCanonicalURL, rxOgURL} {
+		for _, tag := range rx.FindAllString(html, -1) {
+			m := rxAttrURL.FindStringSubmatch(tag)
+			if m == nil {
+				continue
+			}
+			if id, err := ExtractNoDashIDFromNotionURL(m[1]); err == nil {
+				return id
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,63 @@
+package notionapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsNotionURL(t *testing.T) {
+	assert.True(t, IsNotionURL("https://www.notion.so/Page-c969c9455d7c4dd79c7f860f3ace6429"))
+	assert.True(t, IsNotionURL("notion.so/c969c9455d7c4dd79c7f860f3ace6429"))
+	assert.True(t, IsNotionURL("https://Team.Notion.Site/Page"))
+	assert.False(t, IsNotionURL("https://docs.example.com/page"))
+	assert.False(t, IsNotionURL("https://notion.site.example.com/page"))
+	assert.False(t, IsNotionURL(""))
+}
+
+func TestResolvePublicURL(t *testing.T) {
+	const id = "c969c945-5d7c-4dd7-9c7f-860f3ace6429"
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/short":
+			http.Redirect(w, r, "/Test-page-c969c9455d7c4dd79c7f860f3ace6429", http.StatusFound)
+		case "/docs":
+			w.Write([]byte(`<html><head><title>Docs</title>
+<meta property="og:url" content="https://team.notion.site/Test-page-c969c9455d7c4dd79c7f860f3ace6429">
+</head><body></body></html>`))
+		case "/canonical":
+			w.Write([]byte(`<html><head><link rel="canonical" href="https://www.notion.so/c969c9455d7c4dd79c7f860f3ace6429"/></head></html>`))
+		case "/body":
+			w.Write([]byte(`<html><head></head><body><a href="https://www.notion.so/c969c9455d7c4dd79c7f860f3ace6429" rel="canonical"></a></body></html>`))
+		default:
+			w.Write([]byte(`<html><head><title>Nothing here</title></head></html>`))
+		}
+	}))
+	defer server.Close()
+	c := &Client{RetryPolicy: &NoRetryPolicy}
+
+	// the id is in the url, nothing is fetched
+	got, err := c.ResolvePublicURL("https://team.notion.site/Test-page-c969c9455d7c4dd79c7f860f3ace6429")
+	require.NoError(t, err)
+	assert.Equal(t, id, got)
+	assert.Empty(t, paths)
+
+	for _, path := range []string{"/short", "/docs", "/canonical"} {
+		got, err = c.ResolvePublicURL(server.URL + path)
+		require.NoError(t, err, path)
+		assert.Equal(t, id, got, path)
+	}
+
+	for _, path := range []string{"/body", "/nothing"} {
+		_, err = c.ResolvePublicURL(server.URL + path)
+		var noID *NoIDInURLError
+		require.True(t, errors.As(err, &noID), path)
+		assert.Equal(t, server.URL+path, noID.URL)
+	}
+}
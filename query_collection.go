@@ -1,5 +1,19 @@
 package notionapi
 
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultQueryConcurrency is the default number of collection views
+	// queried at the same time by DownloadPage
+	DefaultQueryConcurrency = 4
+)
+
 // /api/v3/queryCollection request
 type queryCollectionRequest struct {
 	CollectionID     string           `json:"collectionId"`
@@ -18,14 +32,29 @@ type queryCollectionRequest struct {
   }
 */
 type Loader struct {
-	Type  string `json:"type"`
-	Limit int    `json:"limit"`
+	// "table" or "reducer"
+	Type string `json:"type"`
+	// for "table" loader
+	Limit int `json:"limit,omitempty"`
+	// for "reducer" loader
+	Reducers map[string]*LoaderReducer `json:"reducers,omitempty"`
 	// from User.TimeZone
 	UserTimeZone string `json:"userTimeZone"`
 	// from User.Locale
 	UserLocale string `json:"userLocale"`
 }
 
+// LoaderReducer describes what a "reducer" loader should return
+type LoaderReducer struct {
+	// "results" or, for grouped views, "groups"
+	Type  string `json:"type"`
+	Limit int    `json:"limit"`
+	// for "groups", the column rows are grouped by
+	GroupBy interface{} `json:"groupBy,omitempty"`
+	// for "results" of a group, selects rows in the group
+	Filter interface{} `json:"filter,omitempty"`
+}
+
 // CollectionQuery describes a collection query
 type CollectionQuery struct {
 	// copy from CollectionView.Query
@@ -45,11 +74,146 @@ type QueryCollectionResponse struct {
 }
 
 // QueryCollectionResult is part of response for /api/v3/queryCollection
+// Older responses have "type": "table" and row ids in BlockIDS.
+// Newer responses have "type": "reducer" and row ids in ReducerResults.
 type QueryCollectionResult struct {
 	Type               string               `json:"type"`
 	BlockIDS           []string             `json:"blockIds"`
 	AggregationResults []*AggregationResult `json:"aggregationResults"`
 	Total              int                  `json:"total"`
+	// for "reducer" responses. The key is the name of the reducer such as
+	// "collection_group_results" or, for board views, "board_columns"
+	// and "results:select:Done"
+	ReducerResults map[string]*ReducerResult `json:"reducerResults,omitempty"`
+}
+
+// ReducerResult is a result of a single reducer in a "reducer" response
+type ReducerResult struct {
+	// "results", "groups" or "aggregation"
+	Type string `json:"type"`
+	// for "results"
+	BlockIDs []string `json:"blockIds,omitempty"`
+	HasMore  bool     `json:"hasMore,omitempty"`
+	// for "groups", in the order they are shown
+	Groups []*ReducerGroup `json:"results,omitempty"`
+	// for "aggregation"
+	AggregationResult *ReducerAggregation `json:"aggregationResult,omitempty"`
+}
+
+// ReducerGroup describes a group (e.g. a column in board view)
+type ReducerGroup struct {
+	Value             *ReducerGroupValue  `json:"value"`
+	AggregationResult *ReducerAggregation `json:"aggregationResult,omitempty"`
+}
+
+// ReducerGroupValue is a value shared by all rows in a group. Value is
+// missing for the group of rows without a value
+type ReducerGroupValue struct {
+	// type of the column e.g. "select"
+	Type  string      `json:"type"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ReducerAggregation is a result of aggregation in a "reducer" response
+type ReducerAggregation struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+const (
+	reducerCollectionGroupResults = "collection_group_results"
+	reducerBoardColumns           = "board_columns"
+	reducerResultsPrefix          = "results:"
+)
+
+// QueryCollectionGroup describes a group of rows in a grouped (e.g. board)
+// collection view
+type QueryCollectionGroup struct {
+	// type of the column e.g. "select"
+	Type string
+	// nil for rows without a value
+	Value    interface{}
+	BlockIDs []string
+}
+
+func groupReducerKey(v *ReducerGroupValue) string {
+	if v == nil {
+		return ""
+	}
+	if v.Value == nil {
+		return reducerResultsPrefix + v.Type + ":uncategorized"
+	}
+	return fmt.Sprintf("%s%s:%v", reducerResultsPrefix, v.Type, v.Value)
+}
+
+// Groups returns rows grouped as shown in a grouped view (e.g. board).
+// Returns nil if the view is not grouped.
+func (r *QueryCollectionResult) Groups() []*QueryCollectionGroup {
+	var res []*QueryCollectionGroup
+	board := r.ReducerResults[reducerBoardColumns]
+	if board == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	for _, g := range board.Groups {
+		key := groupReducerKey(g.Value)
+		v := r.ReducerResults[key]
+		if v == nil || seen[key] {
+			continue
+		}
+		seen[key] = true
+		group := &QueryCollectionGroup{
+			Type:     g.Value.Type,
+			Value:    g.Value.Value,
+			BlockIDs: v.BlockIDs,
+		}
+		res = append(res, group)
+	}
+	// results for groups not listed in board_columns, in a stable order
+	var keys []string
+	for key := range r.ReducerResults {
+		if strings.HasPrefix(key, reducerResultsPrefix) && !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		parts := strings.SplitN(key, ":", 3)
+		group := &QueryCollectionGroup{
+			BlockIDs: r.ReducerResults[key].BlockIDs,
+		}
+		if len(parts) == 3 {
+			group.Type = parts[1]
+			if parts[2] != "uncategorized" {
+				group.Value = parts[2]
+			}
+		}
+		res = append(res, group)
+	}
+	return res
+}
+
+// RowIDs returns ids of blocks for rows in the collection, regardless
+// of the format of the response. Rows of grouped views are flattened in
+// the order of groups.
+func (r *QueryCollectionResult) RowIDs() []string {
+	if len(r.BlockIDS) > 0 {
+		return r.BlockIDS
+	}
+	if v := r.ReducerResults[reducerCollectionGroupResults]; v != nil {
+		return v.BlockIDs
+	}
+	var res []string
+	seen := map[string]bool{}
+	for _, g := range r.Groups() {
+		for _, id := range g.BlockIDs {
+			if !seen[id] {
+				seen[id] = true
+				res = append(res, id)
+			}
+		}
+	}
+	return res
 }
 
 // AggregationResult represents result of aggregation
@@ -60,6 +224,17 @@ type AggregationResult struct {
 
 // QueryCollection executes a raw API call /api/v3/queryCollection
 func (c *Client) QueryCollection(collectionID, collectionViewID string, aggregateQuery []*AggregateQuery, user *User) (*QueryCollectionResponse, error) {
+	return c.QueryCollectionCtx(context.Background(), collectionID, collectionViewID, aggregateQuery, user)
+}
+
+// QueryCollectionCtx is like QueryCollection but can be canceled with ctx
+func (c *Client) QueryCollectionCtx(ctx context.Context, collectionID, collectionViewID string, aggregateQuery []*AggregateQuery, user *User) (*QueryCollectionResponse, error) {
+	return c.queryCollection(ctx, collectionID, collectionViewID, aggregateQuery, user, nil)
+}
+
+// queryCollection is like QueryCollectionCtx. If groupBy is set, a reducer
+// query also returns rows in groups of a grouped (e.g. board) view
+func (c *Client) queryCollection(ctx context.Context, collectionID, collectionViewID string, aggregateQuery []*AggregateQuery, user *User, groupBy *collectionGroupBy) (*QueryCollectionResponse, error) {
 	req := &queryCollectionRequest{
 		CollectionID:     collectionID,
 		CollectionViewID: collectionViewID,
@@ -70,18 +245,171 @@ func (c *Client) QueryCollection(collectionID, collectionViewID string, aggregat
 			FilterOperator: "and",
 		}
 	}
-	req.Loader = &Loader{
-		Type:         "table",
-		Limit:        70,
-		UserLocale:   user.Locale,
-		UserTimeZone: user.TimeZone,
-	}
+	useReducer := c.getCompat().UseReducerQuery
+	req.Loader = newQueryCollectionLoader(useReducer, user, groupBy)
 
 	apiURL := "/api/v3/queryCollection"
 	var rsp QueryCollectionResponse
-	err := doNotionAPI(c, apiURL, req, &rsp)
+	err := doNotionAPI(ctx, c, apiURL, req, &rsp)
+	if err != nil && !useReducer && isAPIRejected(err) {
+		if c.detectCompat(err, apiURL, func(compat *Compat) { compat.UseReducerQuery = true }) {
+			req.Loader = newQueryCollectionLoader(true, user, groupBy)
+			err = doNotionAPI(ctx, c, apiURL, req, &rsp)
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &rsp, nil
 }
+
+// collectionGroupBy describes a column by which rows of a grouped view
+// are grouped, with possible values
+type collectionGroupBy struct {
+	property string
+	// type of the column e.g. "select"
+	columnType string
+	values     []string
+}
+
+// newCollectionGroupBy returns the grouping of a view which has group_by
+// in its query. Only select and multi_select columns are supported
+// because we need to know their values up front
+func newCollectionGroupBy(view *CollectionView, collection *Collection) *collectionGroupBy {
+	if view == nil || view.Query == nil || view.Query.GroupBy == "" || collection == nil {
+		return nil
+	}
+	col := collection.CollectionSchema[view.Query.GroupBy]
+	if col == nil {
+		return nil
+	}
+	switch col.Type {
+	case ColumnTypeSelect, ColumnMultiSelect:
+	default:
+		return nil
+	}
+	res := &collectionGroupBy{
+		property:   view.Query.GroupBy,
+		columnType: col.Type,
+	}
+	for _, o := range col.Options {
+		res.values = append(res.values, o.Value)
+	}
+	return res
+}
+
+// reducers returns reducers for groups and for rows in each group. Keys
+// of results reducers are the ones used by groupReducerKey
+func (g *collectionGroupBy) reducers(limit int) map[string]*LoaderReducer {
+	res := map[string]*LoaderReducer{
+		reducerBoardColumns: {
+			Type:  "groups",
+			Limit: limit,
+			GroupBy: map[string]interface{}{
+				"type":     g.columnType,
+				"property": g.property,
+			},
+		},
+	}
+	operator := "enum_is"
+	if g.columnType == ColumnMultiSelect {
+		operator = "enum_contains"
+	}
+	filter := func(f map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"operator": "and",
+			"filters": []interface{}{
+				map[string]interface{}{"property": g.property, "filter": f},
+			},
+		}
+	}
+	key := groupReducerKey(&ReducerGroupValue{Type: g.columnType})
+	res[key] = &LoaderReducer{
+		Type:   "results",
+		Limit:  limit,
+		Filter: filter(map[string]interface{}{"operator": "is_empty"}),
+	}
+	for _, v := range g.values {
+		key = groupReducerKey(&ReducerGroupValue{Type: g.columnType, Value: v})
+		res[key] = &LoaderReducer{
+			Type:  "results",
+			Limit: limit,
+			Filter: filter(map[string]interface{}{
+				"operator": operator,
+				"value":    map[string]interface{}{"type": "exact", "value": v},
+			}),
+		}
+	}
+	return res
+}
+
+func newQueryCollectionLoader(useReducer bool, user *User, groupBy *collectionGroupBy) *Loader {
+	const limit = 70
+	if useReducer {
+		loader := &Loader{
+			Type: "reducer",
+			Reducers: map[string]*LoaderReducer{
+				reducerCollectionGroupResults: {
+					Type:  "results",
+					Limit: limit,
+				},
+			},
+			UserLocale:   user.Locale,
+			UserTimeZone: user.TimeZone,
+		}
+		if groupBy != nil {
+			for key, r := range groupBy.reducers(limit) {
+				loader.Reducers[key] = r
+			}
+		}
+		return loader
+	}
+	return &Loader{
+		Type:         "table",
+		Limit:        limit,
+		UserLocale:   user.Locale,
+		UserTimeZone: user.TimeZone,
+	}
+}
+
+// collectionQuery is a query of a collection view of a page being
+// downloaded, and its result
+type collectionQuery struct {
+	block            *Block
+	collectionID     string
+	collectionViewID string
+	collection       *Collection
+	collectionView   *CollectionView
+	user             *User
+
+	res *QueryCollectionResponse
+	err error
+}
+
+// queryCollections runs queries, up to concurrency at the same time, and
+// sets their results. A failed query doesn't stop the others
+func (c *Client) queryCollections(ctx context.Context, queries []*collectionQuery, concurrency int) {
+	concurrency = c.concurrency(concurrency, DefaultQueryConcurrency)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, q := range queries {
+		wg.Add(1)
+		go func(q *collectionQuery) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				q.err = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+			var agg []*AggregateQuery
+			if q.collectionView.Query != nil {
+				agg = q.collectionView.Query.Aggregate
+			}
+			groupBy := newCollectionGroupBy(q.collectionView, q.collection)
+			q.res, q.err = c.queryCollection(ctx, q.collectionID, q.collectionViewID, agg, q.user, groupBy)
+		}(q)
+	}
+	wg.Wait()
+}
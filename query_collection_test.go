@@ -0,0 +1,345 @@
+package notionapi
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	recordedCollectionID     = "9a8b7c6d-5e4f-4a3b-8c2d-1e0f9a8b7c01"
+	recordedCollectionViewID = "1f2e3d4c-5b6a-4978-8695-a4b3c2d1e002"
+)
+
+// queryRecordedCollection queries recorded collection by replaying
+// cassette at path
+func queryRecordedCollection(t *testing.T, path string, useReducer bool) *QueryCollectionResponse {
+	cassette, err := LoadCassette(path)
+	assert.NoError(t, err)
+	c := &Client{
+		HTTPClient: &http.Client{Transport: failingTransport{}},
+		Cassette:   cassette,
+	}
+	c.Compat.UseReducerQuery = useReducer
+	user := &User{Locale: "en", TimeZone: "Europe/Warsaw"}
+	rsp, err := c.QueryCollection(recordedCollectionID, recordedCollectionViewID, nil, user)
+	assert.NoError(t, err)
+	assert.NotNil(t, rsp.Result)
+	return rsp
+}
+
+func TestQueryCollectionLegacy(t *testing.T) {
+	// older response format with row ids directly in "blockIds"
+	rsp := queryRecordedCollection(t, "testdata/query_collection_legacy.json", false)
+	res := rsp.Result
+	assert.Equal(t, "table", res.Type)
+	ids := res.RowIDs()
+	assert.Equal(t, 2, len(ids))
+	assert.Equal(t, "7b1c2d3e-4f5a-4b6c-8d7e-9f0a1b2c3d02", ids[1])
+	assert.Nil(t, res.Groups())
+	for _, id := range ids {
+		assert.NotNil(t, rsp.RecordMap.Blocks[id])
+	}
+}
+
+func TestQueryCollectionReducer(t *testing.T) {
+	// newer response format with row ids in reducerResults
+	rsp := queryRecordedCollection(t, "testdata/query_collection_reducer.json", true)
+	res := rsp.Result
+	assert.Equal(t, "reducer", res.Type)
+	ids := res.RowIDs()
+	assert.Equal(t, 2, len(ids))
+	assert.Equal(t, "3f3a6a2e-8d4e-4b7e-9a0b-2f6d1c8e4a01", ids[0])
+	assert.Nil(t, res.Groups())
+	for _, id := range ids {
+		assert.NotNil(t, rsp.RecordMap.Blocks[id])
+	}
+}
+
+// recordedBoardCollection is the collection queried in
+// testdata/query_collection_board.json
+func recordedBoardCollection() *Collection {
+	return &Collection{
+		ID: recordedCollectionID,
+		CollectionSchema: map[string]*CollectionColumnInfo{
+			"title": {Name: "Name", Type: ColumnTypeTitle},
+			"Xq;a": {
+				Name: "Status",
+				Type: ColumnTypeSelect,
+				Options: []*CollectionColumnOption{
+					{ID: "1", Value: "Todo"},
+					{ID: "2", Value: "Done"},
+				},
+			},
+		},
+	}
+}
+
+// recordedBoardView is a board view grouped by Status column
+func recordedBoardView() *CollectionView {
+	return &CollectionView{
+		ID:    recordedCollectionViewID,
+		Type:  "board",
+		Query: &CollectionViewQuery{GroupBy: "Xq;a"},
+	}
+}
+
+func TestQueryCollectionBoard(t *testing.T) {
+	// newer response format for a board view grouped by select column,
+	// with a reducer for each group
+	cassette, err := LoadCassette("testdata/query_collection_board.json")
+	assert.NoError(t, err)
+	c := &Client{
+		HTTPClient: &http.Client{Transport: failingTransport{}},
+		Cassette:   cassette,
+	}
+	c.Compat.UseReducerQuery = true
+	groupBy := newCollectionGroupBy(recordedBoardView(), recordedBoardCollection())
+	user := &User{Locale: "en", TimeZone: "Europe/Warsaw"}
+	rsp, err := c.queryCollection(context.Background(), recordedCollectionID, recordedCollectionViewID, nil, user, groupBy)
+	assert.NoError(t, err)
+	res := rsp.Result
+	groups := res.Groups()
+	assert.Equal(t, 3, len(groups))
+	assert.Nil(t, groups[0].Value)
+	assert.Equal(t, 1, len(groups[0].BlockIDs))
+	assert.Equal(t, "Todo", groups[1].Value)
+	assert.Equal(t, 2, len(groups[1].BlockIDs))
+	assert.Equal(t, "Done", groups[2].Value)
+	assert.Equal(t, 0, len(groups[2].BlockIDs))
+
+	ids := res.RowIDs()
+	assert.Equal(t, 3, len(ids))
+	assert.Equal(t, "c1d2e3f4-a5b6-4c7d-8e9f-0a1b2c3d4e03", ids[0])
+	for _, id := range ids {
+		assert.NotNil(t, rsp.RecordMap.Blocks[id])
+	}
+}
+
+func TestNewCollectionGroupBy(t *testing.T) {
+	collection := recordedBoardCollection()
+	groupBy := newCollectionGroupBy(recordedBoardView(), collection)
+	reducers := groupBy.reducers(70)
+	assert.Equal(t, 4, len(reducers))
+	assert.Equal(t, "groups", reducers[reducerBoardColumns].Type)
+	for _, key := range []string{"results:select:uncategorized", "results:select:Todo", "results:select:Done"} {
+		assert.Equal(t, "results", reducers[key].Type, key)
+	}
+
+	// not grouped or grouped by a column whose values we don't know
+	assert.Nil(t, newCollectionGroupBy(&CollectionView{}, collection))
+	assert.Nil(t, newCollectionGroupBy(&CollectionView{Query: &CollectionViewQuery{GroupBy: "title"}}, collection))
+	assert.Nil(t, newCollectionGroupBy(&CollectionView{Query: &CollectionViewQuery{GroupBy: "none"}}, collection))
+}
+
+// a page with a database with three views. Rows depend on the view
+const threeViewsPageJSON = `{
+	"recordMap": {
+		"block": {
+			"5a1d3c2e-7c4f-4d0e-8b6a-0e9c1b2a3f40": {
+				"role": "editor",
+				"value": {
+					"id": "5a1d3c2e-7c4f-4d0e-8b6a-0e9c1b2a3f40",
+					"type": "page",
+					"alive": true,
+					"version": 1,
+					"content": ["6b1d3c2e-7c4f-4d0e-8b6a-0e9c1b2a3f41"],
+					"properties": { "title": [["Tasks page"]] }
+				}
+			},
+			"6b1d3c2e-7c4f-4d0e-8b6a-0e9c1b2a3f41": {
+				"role": "editor",
+				"value": {
+					"id": "6b1d3c2e-7c4f-4d0e-8b6a-0e9c1b2a3f41",
+					"type": "collection_view",
+					"alive": true,
+					"version": 1,
+					"parent_id": "5a1d3c2e-7c4f-4d0e-8b6a-0e9c1b2a3f40",
+					"parent_table": "block",
+					"collection_id": "e0b3c1a2-7d4f-4b8e-9a6c-1f2e3d4c5b60",
+					"view_ids": [
+						"a3fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e1",
+						"b4fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e2",
+						"c5fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e3"
+					]
+				}
+			}
+		},
+		"collection": {
+			"e0b3c1a2-7d4f-4b8e-9a6c-1f2e3d4c5b60": {
+				"role": "editor",
+				"value": {
+					"id": "e0b3c1a2-7d4f-4b8e-9a6c-1f2e3d4c5b60",
+					"alive": true,
+					"name": [["Tasks"]],
+					"schema": { "title": { "name": "Name", "type": "title" } }
+				}
+			}
+		},
+		"collection_view": {
+			"a3fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e1": {
+				"role": "editor",
+				"value": { "id": "a3fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e1", "type": "table", "name": "All", "alive": true }
+			},
+			"b4fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e2": {
+				"role": "editor",
+				"value": { "id": "b4fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e2", "type": "board", "name": "Board", "alive": true }
+			},
+			"c5fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e3": {
+				"role": "editor",
+				"value": { "id": "c5fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e3", "type": "list", "name": "List", "alive": true }
+			}
+		},
+		"notion_user": {
+			"bb760e2d-d679-4b64-b2a9-03005b21870a": {
+				"role": "reader",
+				"value": { "id": "bb760e2d-d679-4b64-b2a9-03005b21870a", "email": "kkowalczyk@gmail.com" }
+			}
+		}
+	},
+	"cursor": { "stack": [] }
+}`
+
+// threeViewsTransport serves threeViewsPageJSON. Views in rows return
+// those rows and views in failing fail
+type threeViewsTransport struct {
+	rows    map[string][]string
+	failing map[string]bool
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	// closed when all views are being queried
+	allInFlight chan struct{}
+}
+
+func (t *threeViewsTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, _ := readRequestBody(r)
+	rsp := func(code int, s string) *http.Response {
+		return &http.Response{
+			StatusCode: code,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader(s)),
+			Request:    r,
+		}
+	}
+	if strings.HasSuffix(r.URL.Path, "/syncRecordValues") {
+		d, _ := json.Marshal(map[string]interface{}{
+			"recordMap": map[string]interface{}{
+				"block": map[string]interface{}{
+					"5a1d3c2e-7c4f-4d0e-8b6a-0e9c1b2a3f40": map[string]interface{}{
+						"role":  RoleEditor,
+						"value": map[string]interface{}{"id": "5a1d3c2e-7c4f-4d0e-8b6a-0e9c1b2a3f40", "type": BlockPage, "alive": true, "version": 1, "content": []string{"6b1d3c2e-7c4f-4d0e-8b6a-0e9c1b2a3f41"}},
+					},
+				},
+			},
+		})
+		return rsp(200, string(d)), nil
+	}
+	if !strings.HasSuffix(r.URL.Path, "/queryCollection") {
+		return rsp(200, threeViewsPageJSON), nil
+	}
+
+	var req queryCollectionRequest
+	json.Unmarshal([]byte(body), &req)
+	t.mu.Lock()
+	t.inFlight++
+	if t.inFlight > t.maxInFlight {
+		t.maxInFlight = t.inFlight
+	}
+	if t.inFlight == 3 {
+		close(t.allInFlight)
+	}
+	t.mu.Unlock()
+	// wait for other queries, to check they're sent at the same time
+	select {
+	case <-t.allInFlight:
+	case <-time.After(time.Second):
+	}
+	t.mu.Lock()
+	t.inFlight--
+	t.mu.Unlock()
+
+	if t.failing[req.CollectionViewID] {
+		return rsp(500, `{"errorId":"1","name":"InternalServerError","message":"failed"}`), nil
+	}
+	records := map[string]interface{}{}
+	for _, id := range t.rows[req.CollectionViewID] {
+		records[id] = map[string]interface{}{
+			"role": RoleEditor,
+			"value": map[string]interface{}{
+				"id": id, "type": BlockPage, "alive": true, "version": 1,
+				"parent_id": "e0b3c1a2-7d4f-4b8e-9a6c-1f2e3d4c5b60", "parent_table": TableCollection,
+				"properties": map[string]interface{}{"title": [][]string{{"Row " + id[:1]}}},
+			},
+		}
+	}
+	d, _ := json.Marshal(map[string]interface{}{
+		"recordMap": map[string]interface{}{"block": records},
+		"result": map[string]interface{}{
+			"type":     "table",
+			"blockIds": t.rows[req.CollectionViewID],
+			"total":    len(t.rows[req.CollectionViewID]),
+		},
+	})
+	return rsp(200, string(d)), nil
+}
+
+func TestDownloadPageQueriesViewsConcurrently(t *testing.T) {
+	const (
+		row1 = "1f3a6a2e-8d4e-4b7e-9a0b-2f6d1c8e4a01"
+		row2 = "2f3a6a2e-8d4e-4b7e-9a0b-2f6d1c8e4a02"
+		all  = "a3fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e1"
+		list = "c5fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e3"
+	)
+	download := func(failing map[string]bool) (*Page, *threeViewsTransport) {
+		transport := &threeViewsTransport{
+			rows: map[string][]string{
+				all:                                    {row1, row2},
+				"b4fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e2": {row2},
+				list:                                   {row1},
+			},
+			failing:     failing,
+			allInFlight: make(chan struct{}),
+		}
+		c := &Client{
+			AuthToken:   "token",
+			HTTPClient:  &http.Client{Transport: transport},
+			RetryPolicy: &NoRetryPolicy,
+			RateLimiter: NewTokenBucket(1000, 100),
+		}
+		page, err := c.DownloadPage("5a1d3c2e7c4f4d0e8b6a0e9c1b2a3f40")
+		assert.NoError(t, err)
+		return page, transport
+	}
+
+	page, transport := download(nil)
+	assert.Equal(t, 3, transport.maxInFlight)
+	// tables are in the order of views
+	var names []string
+	for _, table := range page.Tables {
+		names = append(names, table.CollectionView.Name)
+		assert.NoError(t, table.Err)
+	}
+	assert.Equal(t, []string{"All", "Board", "List"}, names)
+	assert.Equal(t, 2, len(page.Tables[0].Data))
+	assert.Equal(t, row2, page.Tables[1].Data[0].ID)
+	assert.Equal(t, row1, page.Tables[2].Data[0].ID)
+	assert.Equal(t, 3, len(page.Root.Content[0].CollectionViews))
+
+	// a failed view doesn't fail the download
+	page, _ = download(map[string]bool{all: true})
+	assert.Equal(t, 3, len(page.Tables))
+	assert.Error(t, page.Tables[0].Err)
+	assert.Empty(t, page.Tables[0].Data)
+	assert.NoError(t, page.Tables[2].Err)
+	assert.Equal(t, row1, page.Tables[2].Data[0].ID)
+	assert.Equal(t, 1, len(page.Warnings))
+}
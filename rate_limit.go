@@ -0,0 +1,144 @@
+package notionapi
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultRequestsPerSecond is the rate of requests of a Client that
+	// doesn't have RateLimiter set
+	DefaultRequestsPerSecond = 3
+	// DefaultRequestsBurst is the burst of requests of a Client that
+	// doesn't have RateLimiter set
+	DefaultRequestsBurst = 3
+)
+
+// RateLimiter limits the rate of http requests sent to the server.
+// The same RateLimiter can be used by multiple clients to limit the rate
+// of requests of the whole process. Requests to other hosts, e.g. when
+// downloading images hosted elsewhere, are not limited.
+type RateLimiter interface {
+	// Wait blocks until a request can be sent or ctx is done
+	Wait(ctx context.Context) error
+	// Throttled is called when the server responds with 429 Too Many
+	// Requests. retryAfter is the delay requested by the server or 0
+	Throttled(retryAfter time.Duration)
+}
+
+// TokenBucket is a RateLimiter that allows sending requests at a given
+// rate, with bursts of up to a given size.
+// When throttled by the server, it halves the rate and then slowly
+// recovers back to the original rate.
+type TokenBucket struct {
+	mu sync.Mutex
+	// requests per second we're configured with
+	maxRate float64
+	// current requests per second, lower than maxRate after being throttled
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+// NewTokenBucket returns a RateLimiter allowing requestsPerSecond requests
+// with bursts of up to burst requests
+func NewTokenBucket(requestsPerSecond float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		maxRate: requestsPerSecond,
+		rate:    requestsPerSecond,
+		burst:   float64(burst),
+		tokens:  float64(burst),
+	}
+}
+
+// Rate returns current rate in requests per second
+func (b *TokenBucket) Rate() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rate
+}
+
+// reserve takes a token and returns how long to wait before using it
+func (b *TokenBucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.last.IsZero() {
+		elapsed := now.Sub(b.last).Seconds()
+		b.tokens += elapsed * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		// recover 10% of the original rate every second
+		if b.rate < b.maxRate {
+			b.rate += elapsed * b.maxRate / 10
+			if b.rate > b.maxRate {
+				b.rate = b.maxRate
+			}
+		}
+	}
+	b.last = now
+	b.tokens--
+	var wait time.Duration
+	if b.tokens < 0 {
+		wait = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	if pause := b.pausedUntil.Sub(now); pause > wait {
+		wait = pause
+	}
+	return wait
+}
+
+// Wait blocks until a request can be sent or ctx is done
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	if b.maxRate <= 0 {
+		return ctx.Err()
+	}
+	wait := b.reserve(time.Now())
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	return sleepCtx(ctx, wait)
+}
+
+// Throttled halves the rate and, if retryAfter is given, pauses sending
+// requests for that long
+func (b *TokenBucket) Throttled(retryAfter time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rate /= 2
+	if minRate := b.maxRate / 16; b.rate < minRate {
+		b.rate = minRate
+	}
+	if retryAfter > 0 {
+		until := time.Now().Add(retryAfter)
+		if until.After(b.pausedUntil) {
+			b.pausedUntil = until
+		}
+	}
+}
+
+// isRateLimited returns true if requests to u are limited by RateLimiter
+// i.e. they're sent to notion server
+func (c *Client) isRateLimited(u *url.URL) bool {
+	return c.isAPIHost(u.String()) || isNotionHost(strings.ToLower(u.Hostname()))
+}
+
+// protects lazy creation of default rate limiter in getRateLimiter
+var rateLimiterMu sync.Mutex
+
+func (c *Client) getRateLimiter() RateLimiter {
+	rateLimiterMu.Lock()
+	defer rateLimiterMu.Unlock()
+	if c.RateLimiter == nil {
+		c.RateLimiter = NewTokenBucket(DefaultRequestsPerSecond, DefaultRequestsBurst)
+	}
+	return c.RateLimiter
+}
@@ -0,0 +1,89 @@
+package notionapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucketReserve(t *testing.T) {
+	b := NewTokenBucket(10, 2)
+	now := time.Now()
+	// burst is available immediately
+	assert.Equal(t, time.Duration(0), b.reserve(now))
+	assert.Equal(t, time.Duration(0), b.reserve(now))
+	// then we're limited to 10 per second
+	assert.Equal(t, 100*time.Millisecond, b.reserve(now))
+	assert.Equal(t, 200*time.Millisecond, b.reserve(now))
+	// tokens are refilled over time
+	now = now.Add(time.Second)
+	assert.Equal(t, time.Duration(0), b.reserve(now))
+}
+
+func TestTokenBucketThrottled(t *testing.T) {
+	b := NewTokenBucket(10, 1)
+	b.Throttled(0)
+	assert.Equal(t, float64(5), b.Rate())
+	for i := 0; i < 10; i++ {
+		b.Throttled(0)
+	}
+	// never goes below 1/16 of the original rate
+	assert.Equal(t, 10.0/16, b.Rate())
+
+	b = NewTokenBucket(10, 1)
+	b.Throttled(time.Hour)
+	assert.True(t, b.reserve(time.Now()) > 59*time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, b.Wait(ctx))
+}
+
+func TestRateLimiterSharedByClients(t *testing.T) {
+	limiter := NewTokenBucket(1000, 1)
+	transport := &flakyTransport{statusCodes: []int{429}}
+	httpClient := &http.Client{Transport: transport}
+	c1 := &Client{
+		HTTPClient:  httpClient,
+		RetryPolicy: &NoRetryPolicy,
+		RateLimiter: limiter,
+	}
+	c2 := &Client{
+		HTTPClient:  httpClient,
+		RetryPolicy: &NoRetryPolicy,
+		RateLimiter: limiter,
+	}
+	_, err := c1.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	assert.Error(t, err)
+	// 429 slows down the limiter used by both clients
+	assert.Equal(t, float64(500), limiter.Rate())
+	_, err = c2.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, transport.nRequests)
+}
+
+func TestDefaultRateLimiter(t *testing.T) {
+	c := &Client{}
+	limiter, ok := c.getRateLimiter().(*TokenBucket)
+	assert.True(t, ok)
+	assert.Equal(t, float64(DefaultRequestsPerSecond), limiter.Rate())
+	assert.Equal(t, limiter, c.getRateLimiter())
+}
+
+func TestRateLimitedHosts(t *testing.T) {
+	c := &Client{BaseURL: "http://127.0.0.1:1234/api/v3"}
+	limited := func(uri string) bool {
+		u, err := url.Parse(uri)
+		assert.NoError(t, err)
+		return c.isRateLimited(u)
+	}
+	assert.True(t, limited("https://www.notion.so/api/v3/loadPageChunk"))
+	assert.True(t, limited("https://www.notion.so/image/https%3A%2F%2Fexample.com%2Fa.png"))
+	assert.True(t, limited("https://kjk.notion.site/Page-c969c9455d7c4dd79c7f860f3ace6429"))
+	assert.True(t, limited("http://127.0.0.1:1234/api/v3/search"))
+	assert.False(t, limited("https://example.com/a.png"))
+	assert.False(t, limited("https://s3-us-west-2.amazonaws.com/secure.notion-static.com/8b3930e3/doc.pdf"))
+}
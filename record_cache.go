@@ -0,0 +1,142 @@
+package notionapi
+
+import (
+	"container/list"
+	"sync"
+)
+
+const (
+	// DefaultRecordCacheSize is the maximum number of records kept by
+	// RecordCache created with size 0
+	DefaultRecordCacheSize = 10000
+)
+
+// RecordCache keeps user and collection records so that pages downloaded
+// with the same Client (see Client.RecordCache) share them instead of
+// each page having its own copy, and users that a page doesn't have can
+// be found without asking the server. A record is replaced when a newer
+// version of it is downloaded. When there are more records than the
+// maximum, the least recently used are removed.
+// It's safe for concurrent use. Records in the cache are shared and must
+// not be modified
+type RecordCache struct {
+	mu      sync.Mutex
+	max     int
+	lru     *list.List
+	records map[recordKey]*list.Element
+}
+
+type recordKey struct {
+	table string
+	id    string
+}
+
+type recordCacheEntry struct {
+	key     recordKey
+	version int
+	// *User or *Collection
+	value interface{}
+}
+
+// NewRecordCache returns a cache that keeps up to maxEntries records.
+// If maxEntries is 0, it's DefaultRecordCacheSize
+func NewRecordCache(maxEntries int) *RecordCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultRecordCacheSize
+	}
+	return &RecordCache{
+		max:     maxEntries,
+		lru:     list.New(),
+		records: map[recordKey]*list.Element{},
+	}
+}
+
+// Len returns the number of records in the cache
+func (c *RecordCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// get returns a record, nil if it's not cached
+func (c *RecordCache) get(table, id string) interface{} {
+	if c == nil {
+		return nil
+	}
+	key := recordKey{table: table, id: mustNormalizeID(id)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el := c.records[key]
+	if el == nil {
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	return el.Value.(*recordCacheEntry).value
+}
+
+// share returns the cached record if it has the same version as v.
+// Otherwise v is cached, unless we have a newer version, and returned
+func (c *RecordCache) share(table, id string, version int, v interface{}) interface{} {
+	if c == nil {
+		return v
+	}
+	key := recordKey{table: table, id: mustNormalizeID(id)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el := c.records[key]; el != nil {
+		e := el.Value.(*recordCacheEntry)
+		if e.version == version {
+			c.lru.MoveToFront(el)
+			return e.value
+		}
+		if e.version > version {
+			return v
+		}
+		e.version = version
+		e.value = v
+		c.lru.MoveToFront(el)
+		return v
+	}
+	e := &recordCacheEntry{
+		key:     key,
+		version: version,
+		value:   v,
+	}
+	c.records[key] = c.lru.PushFront(e)
+	for c.lru.Len() > c.max {
+		el := c.lru.Back()
+		c.lru.Remove(el)
+		delete(c.records, el.Value.(*recordCacheEntry).key)
+	}
+	return v
+}
+
+// shareUser is share for users and bots
+func (c *RecordCache) shareUser(u *User) *User {
+	if u == nil {
+		return nil
+	}
+	table := TableUser
+	if u.IsBot {
+		table = TableBot
+	}
+	return c.share(table, u.ID, u.Version, u).(*User)
+}
+
+// shareCollection is share for collections. c must be already parsed
+// (see parseCollection) because it can be used by other goroutines
+func (c *RecordCache) shareCollection(coll *Collection) *Collection {
+	if coll == nil {
+		return nil
+	}
+	return c.share(TableCollection, coll.ID, coll.Version, coll).(*Collection)
+}
+
+// user returns a cached user or bot, nil if it's not cached
+func (c *RecordCache) user(id string, table string) *User {
+	u, _ := c.get(userTable(table), id).(*User)
+	return u
+}
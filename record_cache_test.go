@@ -0,0 +1,79 @@
+package notionapi
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordCache(t *testing.T) {
+	c := NewRecordCache(2)
+	u1 := &User{ID: "bb760e2d-d679-4b64-b2a9-03005b21870a", Version: 1}
+	assert.True(t, u1 == c.shareUser(u1))
+	// the same version is shared
+	u1b := &User{ID: "bb760e2dd6794b64b2a903005b21870a", Version: 1}
+	assert.True(t, u1 == c.shareUser(u1b))
+	// newer version replaces the older, older versions are not cached
+	u2 := &User{ID: u1.ID, Version: 2}
+	assert.True(t, u2 == c.shareUser(u2))
+	assert.True(t, u1 == c.shareUser(u1))
+	assert.True(t, u2 == c.user(u1.ID, TableUser))
+	// bots are a separate table
+	assert.Nil(t, c.user(u1.ID, TableBot))
+
+	coll := &Collection{ID: "e0b3c1a2-7d4f-4b8e-9a6c-1f2e3d4c5b60", Version: 3}
+	c.shareCollection(coll)
+	assert.Equal(t, 2, c.Len())
+	// the least recently used record is removed
+	c.user(u1.ID, TableUser)
+	c.shareUser(&User{ID: "cc760e2d-d679-4b64-b2a9-03005b21870a", IsBot: true})
+	assert.Equal(t, 2, c.Len())
+	assert.Nil(t, c.get(TableCollection, coll.ID))
+	assert.NotNil(t, c.user(u1.ID, TableUser))
+
+	// nil cache doesn't cache
+	var nilCache *RecordCache
+	assert.True(t, u1 == nilCache.shareUser(u1))
+	assert.Nil(t, nilCache.user(u1.ID, TableUser))
+	assert.Equal(t, 0, nilCache.Len())
+}
+
+func TestRecordCacheConcurrent(t *testing.T) {
+	c := NewRecordCache(50)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				id := fmt.Sprintf("00000000-0000-0000-0000-%012d", j)
+				c.shareUser(&User{ID: id, Version: i})
+				c.user(id, TableUser)
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, 50, c.Len())
+}
+
+func TestDownloadPageSharesRecords(t *testing.T) {
+	c, transport := newStaticClient(200, fullPageDatabaseJSON)
+	c.RateLimiter = NewTokenBucket(1000, 100)
+	c.RecordCache = NewRecordCache(0)
+	page1, err := c.DownloadPage("c969c9455d7c4dd79c7f860f3ace6429")
+	assert.NoError(t, err)
+	page2, err := c.DownloadPage("c969c9455d7c4dd79c7f860f3ace6429")
+	assert.NoError(t, err)
+	assert.True(t, page1.Tables[0].Collection == page2.Tables[0].Collection)
+	assert.True(t, page1.Users[0] == page2.Users[0])
+	assert.Equal(t, "Tasks", page2.Tables[0].Collection.Name())
+
+	// users missing in a page are found without a request
+	page := &Page{client: c}
+	requests := len(transport.requests)
+	u := page.user("bb760e2dd6794b64b2a903005b21870a", TableUser)
+	assert.True(t, u == page1.Users[0])
+	assert.Equal(t, requests, len(transport.requests))
+}
@@ -0,0 +1,166 @@
+package notionapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// limit the size of a single syncRecordValues request
+	maxRecordsPerRequest = 100
+)
+
+// RecordRequest identifies a record to get with Client.GetRecordValues
+type RecordRequest struct {
+	// TableBlock, TableCollection, TableCollectionView, TableSpace,
+	// TableUser, TableBot, TableDiscussion or TableComment
+	Table string `json:"table"`
+	ID    string `json:"id"`
+	// optional id of the space the record belongs to. Only used by
+	// syncRecordValues
+	SpaceID string `json:"spaceId,omitempty"`
+}
+
+// RecordValue is a record returned by Client.GetRecordValues. Depending on
+// Table, one of Block, Collection, CollectionView, Space, User (also for
+// TableBot), Discussion or Comment is set.
+// All are nil if the record doesn't exist or we don't have access to it
+type RecordValue struct {
+	Table string
+	ID    string
+	Role  string
+
+	Block          *Block
+	Collection     *Collection
+	CollectionView *CollectionView
+	Space          *Space
+	User           *User
+	Discussion     *Discussion
+	Comment        *Comment
+
+	// JSON of the record as sent by the server
+	Raw json.RawMessage
+}
+
+// RecordValues is a result of Client.GetRecordValues
+type RecordValues struct {
+	// results in the same order as requests
+	Results []*RecordValue
+}
+
+type recordWithRole struct {
+	Role  string          `json:"role"`
+	Value json.RawMessage `json:"value"`
+}
+
+type rawRecordValuesResponse struct {
+	Results []*recordWithRole `json:"results"`
+}
+
+func decodeRecordValue(rv *RecordValue) error {
+	if len(rv.Raw) == 0 || string(rv.Raw) == "null" {
+		return nil
+	}
+	var err error
+	switch rv.Table {
+	case TableBlock:
+		var v Block
+		if err = json.Unmarshal(rv.Raw, &v); err == nil {
+			if err = parseProperties(&v); err == nil {
+				// invalid format is not fatal
+				parseFormat(&v)
+				rv.Block = &v
+			}
+		}
+	case TableCollection:
+		var v Collection
+		if err = json.Unmarshal(rv.Raw, &v); err == nil {
+			parseCollection(&v)
+			rv.Collection = &v
+		}
+	case TableCollectionView:
+		var v CollectionView
+		if err = json.Unmarshal(rv.Raw, &v); err == nil {
+			parseCollectionView(&v)
+			rv.CollectionView = &v
+		}
+	case TableSpace:
+		var v Space
+		if err = json.Unmarshal(rv.Raw, &v); err == nil {
+			rv.Space = &v
+		}
+	case TableUser, TableBot:
+		var v User
+		if err = json.Unmarshal(rv.Raw, &v); err == nil {
+			v.IsBot = rv.Table == TableBot
+			rv.User = &v
+		}
+	case TableDiscussion:
+		var v Discussion
+		if err = json.Unmarshal(rv.Raw, &v); err == nil {
+			rv.Discussion = &v
+		}
+	case TableComment:
+		var v Comment
+		if err = json.Unmarshal(rv.Raw, &v); err == nil {
+			if err = parseComment(&v); err == nil {
+				rv.Comment = &v
+			}
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode %s record with id '%s': %s", rv.Table, rv.ID, err)
+	}
+	return nil
+}
+
+// GetRecordValues returns records of any type, like blocks, collections or
+// users, using /api/v3/syncRecordValues api (or /api/v3/getRecordValues,
+// see Client.UseGetRecordValues). It's a cheap way to get
+// a record without downloading the whole page. Large number of requests
+// is split into multiple api calls
+func (c *Client) GetRecordValues(requests []RecordRequest) (*RecordValues, error) {
+	return c.GetRecordValuesCtx(context.Background(), requests)
+}
+
+// GetRecordValuesCtx is like GetRecordValues but can be canceled with ctx
+func (c *Client) GetRecordValuesCtx(ctx context.Context, requests []RecordRequest) (*RecordValues, error) {
+	res := &RecordValues{}
+	for len(requests) > 0 {
+		n := len(requests)
+		if n > maxRecordsPerRequest {
+			n = maxRecordsPerRequest
+		}
+		var toGet []RecordRequest
+		for _, r := range requests[:n] {
+			r.ID = mustNormalizeID(r.ID)
+			toGet = append(toGet, r)
+		}
+		records, err := c.getRecords(ctx, toGet)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) != n {
+			return nil, fmt.Errorf("asked for %d records, got %d", n, len(records))
+		}
+		for i, r := range records {
+			rv := &RecordValue{
+				Table: toGet[i].Table,
+				ID:    toGet[i].ID,
+			}
+			if r != nil {
+				rv.Role = r.Role
+				rv.Raw = r.Value
+			}
+			if err := decodeRecordValue(rv); err != nil {
+				return nil, err
+			}
+			c.RecordCache.shareUser(rv.User)
+			c.RecordCache.shareCollection(rv.Collection)
+			res.Results = append(res.Results, rv)
+		}
+		requests = requests[n:]
+	}
+	return res, nil
+}
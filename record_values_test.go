@@ -0,0 +1,233 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	// getRecordValues response
+	recordValuesJSON = `{
+	"results": [
+		{
+			"role": "reader",
+			"value": {
+				"alive": true,
+				"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+				"type": "page",
+				"properties": {
+					"title": [["Test page text"]]
+				},
+				"version": 34
+			}
+		},
+		{
+			"role": "reader",
+			"value": {
+				"id": "bb760e2d-d679-4b64-b2a9-03005b21870a",
+				"email": "kkowalczyk@gmail.com",
+				"given_name": "Krzysztof"
+			}
+		},
+		{
+			"role": "editor",
+			"value": {
+				"id": "bc202e06-6caa-4e3f-81eb-f226ab5deef7",
+				"name": "Work"
+			}
+		},
+		{
+			"role": "none"
+		}
+	]
+}`
+
+	// syncRecordValues response for the same records
+	syncRecordValuesJSON = `{
+	"recordMap": {
+		"block": {
+			"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d": {
+				"role": "reader",
+				"value": {
+					"alive": true,
+					"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+					"type": "page",
+					"properties": {
+						"title": [["Test page text"]]
+					},
+					"version": 34
+				}
+			}
+		},
+		"notion_user": {
+			"bb760e2d-d679-4b64-b2a9-03005b21870a": {
+				"role": "reader",
+				"value": {
+					"id": "bb760e2d-d679-4b64-b2a9-03005b21870a",
+					"email": "kkowalczyk@gmail.com",
+					"given_name": "Krzysztof"
+				}
+			}
+		},
+		"space": {
+			"bc202e06-6caa-4e3f-81eb-f226ab5deef7": {
+				"role": "editor",
+				"value": {
+					"id": "bc202e06-6caa-4e3f-81eb-f226ab5deef7",
+					"name": "Work"
+				}
+			}
+		},
+		"collection": {
+			"00000000-0000-0000-0000-000000000000": {
+				"role": "none"
+			}
+		}
+	}
+}`
+)
+
+var testRecordRequests = []RecordRequest{
+	{Table: TableBlock, ID: "4c6a54c68b3e4ea2af9cfaabcc88d58d"},
+	{Table: TableUser, ID: "bb760e2d-d679-4b64-b2a9-03005b21870a"},
+	{Table: TableSpace, ID: "bc202e06-6caa-4e3f-81eb-f226ab5deef7"},
+	{Table: TableCollection, ID: "00000000-0000-0000-0000-000000000000"},
+}
+
+func checkTestRecordValues(t *testing.T, res *RecordValues) {
+	assert.Equal(t, 4, len(res.Results))
+
+	block := res.Results[0]
+	assert.Equal(t, TableBlock, block.Table)
+	assert.Equal(t, "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", block.ID)
+	assert.Equal(t, "Test page text", block.Block.Title)
+	assert.Equal(t, int64(34), block.Block.Version)
+
+	user := res.Results[1]
+	assert.Equal(t, "kkowalczyk@gmail.com", user.User.Email)
+	assert.Nil(t, user.Block)
+
+	assert.Equal(t, "Work", res.Results[2].Space.Name)
+
+	missing := res.Results[3]
+	assert.Equal(t, "none", missing.Role)
+	assert.Nil(t, missing.Collection)
+}
+
+func TestGetRecordValues(t *testing.T) {
+	c, transport := newStaticClient(200, syncRecordValuesJSON)
+	res, err := c.GetRecordValues(testRecordRequests)
+	assert.NoError(t, err)
+	checkTestRecordValues(t, res)
+
+	var req syncRecordValuesRequest
+	err = json.Unmarshal([]byte(transport.requests[0]), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", req.Requests[0].Pointer.ID)
+	assert.Equal(t, TableUser, req.Requests[1].Pointer.Table)
+	assert.Equal(t, int64(-1), req.Requests[1].Version)
+}
+
+func TestGetRecordValuesFallback(t *testing.T) {
+	c, transport := newStaticClient(200, recordValuesJSON)
+	c.Compat.UseGetRecordValues = true
+	res, err := c.GetRecordValues(testRecordRequests)
+	assert.NoError(t, err)
+	checkTestRecordValues(t, res)
+
+	var req getRecordValuesRequest
+	err = json.Unmarshal([]byte(transport.requests[0]), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d", req.Requests[0].ID)
+	assert.Equal(t, TableUser, req.Requests[1].Table)
+}
+
+// toRecordMap converts getRecordValues response s to syncRecordValues
+// response with records in table, so that the same fixture can be used
+// for both apis
+func toRecordMap(table string, s string) string {
+	var rsp rawRecordValuesResponse
+	if err := json.Unmarshal([]byte(s), &rsp); err != nil {
+		panic(err)
+	}
+	records := map[string]*recordWithRole{}
+	for _, rec := range rsp.Results {
+		var v struct {
+			ID string `json:"id"`
+		}
+		json.Unmarshal(rec.Value, &v)
+		// syncRecordValues doesn't return records we don't have access to
+		if v.ID != "" {
+			records[v.ID] = rec
+		}
+	}
+	d, err := json.Marshal(map[string]interface{}{
+		"recordMap": map[string]interface{}{table: records},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return string(d)
+}
+
+func newRecordedClient(t *testing.T, path string) *Client {
+	d, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	c, _ := newStaticClient(200, string(d))
+	return c
+}
+
+func checkRecordedRecordValues(t *testing.T, res *RecordValues) {
+	assert.Equal(t, 4, len(res.Results))
+
+	block := res.Results[0]
+	assert.Equal(t, "reader", block.Role)
+	assert.Equal(t, "Test page text", block.Block.Title)
+	assert.Equal(t, int64(34), block.Block.Version)
+	assert.Equal(t, "300db9dc-27c8-4958-a08b-8d0c37f4cfe5", block.Block.ParentID)
+	assert.Equal(t, "📝", block.Block.FormatPage.PageIcon)
+	assert.True(t, block.Block.FormatPage.PageFullWidth)
+
+	user := res.Results[1]
+	assert.Equal(t, "reader", user.Role)
+	assert.Equal(t, "user@example.com", user.User.Email)
+	assert.Equal(t, "Test", user.User.GivenName)
+
+	space := res.Results[2]
+	assert.Equal(t, "editor", space.Role)
+	assert.Equal(t, "Work", space.Space.Name)
+	assert.Equal(t, 2, len(space.Space.Pages))
+
+	assert.Nil(t, res.Results[3].Collection)
+}
+
+func TestGetRecordValuesRecorded(t *testing.T) {
+	// records are nested as {"value": {"value": ..., "role": ...}} and
+	// recordMap has "__version__"
+	c := newRecordedClient(t, "testdata/sync_record_values.json")
+	res, err := c.GetRecordValues(testRecordRequests)
+	assert.NoError(t, err)
+	checkRecordedRecordValues(t, res)
+	// records we don't have access to are not in the response
+	assert.Equal(t, "", res.Results[3].Role)
+
+	c = newRecordedClient(t, "testdata/get_record_values.json")
+	c.Compat.UseGetRecordValues = true
+	res, err = c.GetRecordValues(testRecordRequests)
+	assert.NoError(t, err)
+	checkRecordedRecordValues(t, res)
+	assert.Equal(t, "none", res.Results[3].Role)
+}
+
+func TestGetRecordValuesRecordedNoAutoDetect(t *testing.T) {
+	c := newRecordedClient(t, "testdata/sync_record_values.json")
+	c.Compat.DisableAutoDetect = true
+	res, err := c.GetRecordValues(testRecordRequests[:1])
+	assert.NoError(t, err)
+	// without unwrapping role is in the nested value
+	assert.Equal(t, "", res.Results[0].Role)
+	assert.Equal(t, "", res.Results[0].Block.ID)
+}
@@ -0,0 +1,59 @@
+package notionapi
+
+import (
+	"io"
+)
+
+// BlockRenderer allows customizing how ToMarkdown and ToHTML render blocks.
+// RenderBlock is called before default rendering of every block. If it
+// returns true, the block was rendered by RenderBlock and default
+// rendering is skipped. A renderer that returns false can still write
+// content e.g. an anchor before a header
+type BlockRenderer interface {
+	RenderBlock(w io.Writer, block *Block) (handled bool)
+}
+
+// PageRenderer can be optionally implemented by a BlockRenderer to write
+// content at the start and end of a page
+type PageRenderer interface {
+	RenderPageStart(w io.Writer, page *Page)
+	RenderPageEnd(w io.Writer, page *Page)
+}
+
+// BlockRendererFunc is an adapter that allows using a function as
+// a BlockRenderer
+type BlockRendererFunc func(w io.Writer, block *Block) bool
+
+// RenderBlock calls f(w, block)
+func (f BlockRendererFunc) RenderBlock(w io.Writer, block *Block) bool {
+	return f(w, block)
+}
+
+// renderWithRenderers calls renderers in order until one of them handles
+// the block
+func renderWithRenderers(renderers []BlockRenderer, w io.Writer, block *Block) bool {
+	for _, r := range renderers {
+		if r.RenderBlock(w, block) {
+			return true
+		}
+	}
+	return false
+}
+
+func renderPageStart(renderers []BlockRenderer, w io.Writer, page *Page) {
+	for _, r := range renderers {
+		if pr, ok := r.(PageRenderer); ok {
+			pr.RenderPageStart(w, page)
+		}
+	}
+}
+
+// page end hooks are called in reverse order so that renderers can wrap
+// the page
+func renderPageEnd(renderers []BlockRenderer, w io.Writer, page *Page) {
+	for i := len(renderers) - 1; i >= 0; i-- {
+		if pr, ok := renderers[i].(PageRenderer); ok {
+			pr.RenderPageEnd(w, page)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package notionapi
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testAnchorRenderer injects an anchor before headers and wraps the page
+type testAnchorRenderer struct{}
+
+func (testAnchorRenderer) RenderBlock(w io.Writer, block *Block) bool {
+	if block.Type == BlockHeader {
+		fmt.Fprintf(w, "<a name=\"%s\"></a>\n", block.ID)
+	}
+	return false
+}
+
+func (testAnchorRenderer) RenderPageStart(w io.Writer, page *Page) {
+	fmt.Fprint(w, "<!-- start -->\n")
+}
+
+func (testAnchorRenderer) RenderPageEnd(w io.Writer, page *Page) {
+	fmt.Fprint(w, "<!-- end -->\n")
+}
+
+func TestBlockRenderers(t *testing.T) {
+	header := textBlock(BlockHeader, "Header")
+	header.ID = "h1"
+	image := &Block{
+		ID:       "2",
+		Type:     BlockImage,
+		ImageURL: "https://www.notion.so/image/foo.png",
+	}
+	page := testPage(header, textBlock(BlockBulletedList, "item", image))
+	cdn := BlockRendererFunc(func(w io.Writer, block *Block) bool {
+		if block.Type != BlockImage {
+			return false
+		}
+		fmt.Fprintf(w, "![](https://cdn.example.com/%s.png)\n", block.ID)
+		return true
+	})
+	never := BlockRendererFunc(func(w io.Writer, block *Block) bool {
+		assert.NotEqual(t, BlockImage, block.Type)
+		return false
+	})
+	renderers := []BlockRenderer{testAnchorRenderer{}, cdn, never}
+	got := string(ToMarkdown(page, &MarkdownOptions{Renderers: renderers}))
+	exp := `<!-- start -->
+# Test page
+
+<a name="h1"></a>
+# Header
+
+- item
+    ![](https://cdn.example.com/2.png)
+
+<!-- end -->
+`
+	assert.Equal(t, exp, got)
+
+	got = string(ToHTML(page, &HTMLOptions{Fragment: true, Renderers: renderers}))
+	assert.Contains(t, got, "<!-- start -->\n<article>")
+	assert.Contains(t, got, "<a name=\"h1\"></a>\n<h1 id=\"header-ac4ae9\">Header</h1>")
+	assert.Contains(t, got, "https://cdn.example.com/2.png")
+	assert.NotContains(t, got, "<figure>")
+	assert.Contains(t, got, "</article>\n<!-- end -->")
+}
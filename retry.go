@@ -0,0 +1,234 @@
+package notionapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how failed http requests are retried
+type RetryPolicy struct {
+	// maximum number of attempts, including the first one. 1 or less
+	// disables retries
+	MaxAttempts int
+	// delay before the first retry
+	BaseDelay time.Duration
+	// delay is multiplied by Multiplier after each retry
+	Multiplier float64
+	// maximum delay between retries. Doesn't apply to delay requested by
+	// the server with Retry-After header
+	MaxDelay time.Duration
+	// randomizes the delay by +/- Jitter fraction of it e.g. 0.2 means the
+	// delay is between 80% and 120% of computed value
+	Jitter float64
+	// http status codes that are retried. Network errors are always retried
+	RetryableStatusCodes []int
+	// if true, we also retry SubmitTransaction. It's off by default because
+	// if the server applied the operations but we didn't get the response,
+	// retrying applies them again
+	RetryTransactions bool
+}
+
+// DefaultRetryPolicy is used by Client if Client.RetryPolicy is not set
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:          4,
+	BaseDelay:            500 * time.Millisecond,
+	Multiplier:           2,
+	MaxDelay:             10 * time.Second,
+	Jitter:               0.2,
+	RetryableStatusCodes: []int{429, 500, 502, 503, 504},
+}
+
+// NoRetryPolicy disables retries
+var NoRetryPolicy = RetryPolicy{
+	MaxAttempts: 1,
+}
+
+const (
+	apiSubmitTransaction = "/api/v3/submitTransaction"
+	apiLoginWithEmail    = "/api/v3/loginWithEmail"
+)
+
+// apis that are not safe to retry because they modify data
+var nonIdempotentAPIs = map[string]bool{
+	apiSubmitTransaction: true,
+	// too many failed attempts can lock the account
+	apiLoginWithEmail: true,
+}
+
+func (c *Client) getRetryPolicy() *RetryPolicy {
+	if c.RetryPolicy != nil {
+		return c.RetryPolicy
+	}
+	return &DefaultRetryPolicy
+}
+
+func (p *RetryPolicy) isRetryableStatus(statusCode int) bool {
+	for _, code := range p.RetryableStatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// delay returns how long to wait before retry number n (starting with 1)
+func (p *RetryPolicy) delay(n int) time.Duration {
+	d := float64(p.BaseDelay)
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 1
+	}
+	d *= math.Pow(mult, float64(n-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(d)
+}
+
+// parseRetryAfter parses Retry-After header which is either a number of
+// seconds or http date. Returns 0 if not set or invalid
+func parseRetryAfter(s string, now time.Time) time.Duration {
+	if s == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(s); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	t, err := http.ParseTime(s)
+	if err != nil || t.Before(now) {
+		return 0
+	}
+	return t.Sub(now)
+}
+
+// sleepCtx waits for d or until ctx is done
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// discardBody reads the rest of the body so that connection can be re-used
+func discardBody(rsp *http.Response) {
+	io.Copy(ioutil.Discard, rsp.Body)
+	rsp.Body.Close()
+}
+
+// doHTTP sends a request created by newRequest, retrying according to
+// retry policy if canRetry is true. newRequest is called for every attempt
+// because request body can only be read once. Each attempt, including
+// reading the response body, must finish within timeout (if > 0)
+func (c *Client) doHTTP(ctx context.Context, newRequest func() (*http.Request, error), canRetry bool, timeout time.Duration) (*http.Response, error) {
+	policy := c.getRetryPolicy()
+	maxAttempts := policy.MaxAttempts
+	if !canRetry || maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	for attempt := 1; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		c.prepareRequest(req)
+		var rsp *http.Response
+		if c.Cassette != nil && c.Cassette.Mode == CassetteReplay {
+			rsp, err = c.Cassette.replay(req)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if rsp == nil && c.HTTPIntercept != nil {
+			rsp = c.HTTPIntercept.OnRequest(req)
+		}
+		if rsp == nil && c.Offline {
+			return nil, fmt.Errorf("%s %s%s: %w", req.Method, req.URL.Host, req.URL.Path, ErrOffline)
+		}
+		if rsp == nil {
+			var release func()
+			release, err = c.acquireRequestSlot(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if c.isRateLimited(req.URL) {
+				if err := c.getRateLimiter().Wait(ctx); err != nil {
+					release()
+					return nil, err
+				}
+			}
+			metrics := c.startRequest(req, attempt > 1)
+			rsp, err = c.sendWithTimeout(req, timeout)
+			if err != nil {
+				release()
+				metrics.done(0, 0)
+			} else {
+				rsp.Body = &releaseOnClose{ReadCloser: rsp.Body, release: release}
+				metrics.countBody(rsp)
+				err = ungzipResponse(rsp)
+			}
+			if err == nil && c.Cassette != nil && c.Cassette.Mode == CassetteRecord {
+				err = c.Cassette.record(req, rsp)
+			}
+			if err == nil && c.HTTPIntercept != nil {
+				c.HTTPIntercept.OnResponse(rsp)
+			}
+			if err != nil && rsp != nil {
+				// e.g. recording the response failed. Closing the body
+				// frees the connection and the slot of
+				// MaxConcurrentRequests
+				rsp.Body.Close()
+				rsp = nil
+			}
+		}
+		var retryAfter time.Duration
+		if err == nil {
+			retryAfter = parseRetryAfter(rsp.Header.Get("Retry-After"), time.Now())
+			if rsp.StatusCode == http.StatusTooManyRequests && c.isRateLimited(req.URL) {
+				c.getRateLimiter().Throttled(retryAfter)
+			}
+		}
+		if err != nil {
+			log(c, "httpClient.Do() failed with %s\n", err)
+			// report cancellation as such and not as a generic url.Error
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if attempt >= maxAttempts {
+				return nil, &TransportError{Endpoint: req.URL.String(), Err: err}
+			}
+		} else if attempt >= maxAttempts || !policy.isRetryableStatus(rsp.StatusCode) {
+			return rsp, nil
+		}
+
+		delay := policy.delay(attempt)
+		if rsp != nil {
+			if retryAfter > 0 {
+				delay = retryAfter
+			}
+			logWarn(c, "%s %s returned status code %d, retrying in %s\n", req.Method, req.URL, rsp.StatusCode, delay)
+			discardBody(rsp)
+		} else {
+			logWarn(c, "retrying %s %s in %s\n", req.Method, req.URL, delay)
+		}
+		if err := sleepCtx(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package notionapi
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyTransport returns statusCodes in order, then 200
+type flakyTransport struct {
+	statusCodes []int
+	retryAfter  string
+	nRequests   int
+}
+
+func (t *flakyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.nRequests++
+	code := 200
+	if len(t.statusCodes) > 0 {
+		code = t.statusCodes[0]
+		t.statusCodes = t.statusCodes[1:]
+	}
+	header := http.Header{}
+	if t.retryAfter != "" {
+		header.Set("Retry-After", t.retryAfter)
+	}
+	return &http.Response{
+		StatusCode: code,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+		Request:    r,
+	}, nil
+}
+
+func testRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:          3,
+		BaseDelay:            time.Millisecond,
+		Multiplier:           2,
+		RetryableStatusCodes: []int{429, 502, 504},
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := &RetryPolicy{
+		BaseDelay:  100 * time.Millisecond,
+		Multiplier: 2,
+		MaxDelay:   time.Second,
+	}
+	assert.Equal(t, 100*time.Millisecond, p.delay(1))
+	assert.Equal(t, 200*time.Millisecond, p.delay(2))
+	assert.Equal(t, 400*time.Millisecond, p.delay(3))
+	assert.Equal(t, time.Second, p.delay(5))
+
+	p.Jitter = 0.5
+	for i := 0; i < 20; i++ {
+		d := p.delay(1)
+		assert.True(t, d >= 50*time.Millisecond && d <= 150*time.Millisecond)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2019, 3, 1, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("foo", now))
+	assert.Equal(t, 5*time.Second, parseRetryAfter("Fri, 01 Mar 2019 10:00:05 GMT", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Fri, 01 Mar 2019 09:00:00 GMT", now))
+}
+
+func TestRetryQuery(t *testing.T) {
+	transport := &flakyTransport{statusCodes: []int{502, 429}, retryAfter: "0"}
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RetryPolicy: testRetryPolicy(),
+	}
+	_, err := c.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, transport.nRequests)
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	transport := &flakyTransport{statusCodes: []int{502, 502, 502, 502}}
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RetryPolicy: testRetryPolicy(),
+	}
+	_, err := c.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	assert.Error(t, err)
+	assert.Equal(t, 3, transport.nRequests)
+}
+
+func TestRetryNonRetryableStatus(t *testing.T) {
+	transport := &flakyTransport{statusCodes: []int{400}}
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RetryPolicy: testRetryPolicy(),
+	}
+	_, err := c.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	assert.Error(t, err)
+	assert.Equal(t, 1, transport.nRequests)
+}
+
+func TestRetryTransactions(t *testing.T) {
+	transport := &flakyTransport{statusCodes: []int{502}}
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RetryPolicy: testRetryPolicy(),
+	}
+	err := c.SubmitTransaction(nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, transport.nRequests)
+
+	transport = &flakyTransport{statusCodes: []int{502}}
+	c.HTTPClient = &http.Client{Transport: transport}
+	c.RetryPolicy.RetryTransactions = true
+	err = c.SubmitTransaction(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, transport.nRequests)
+}
@@ -0,0 +1,325 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+const (
+	// version of the format written by SavePage
+	savedPageVersion = 1
+)
+
+var (
+	// ErrOfflinePage is returned when trying to modify a page that was
+	// loaded with LoadPage and therefore has no client
+	ErrOfflinePage = errors.New("offline page: page was loaded with LoadPage and can't be modified")
+)
+
+// savedTable describes a view of a collection_view block
+type savedTable struct {
+	BlockID          string             `json:"block_id"`
+	CollectionID     string             `json:"collection_id"`
+	CollectionViewID string             `json:"collection_view_id"`
+	RowIDs           []string           `json:"row_ids"`
+	Groups           []*savedTableGroup `json:"groups,omitempty"`
+}
+
+type savedTableGroup struct {
+	Type   string      `json:"type"`
+	Value  interface{} `json:"value,omitempty"`
+	RowIDs []string    `json:"row_ids"`
+}
+
+// savedDiscussion is a discussion with its comments
+type savedDiscussion struct {
+	Discussion *Discussion `json:"discussion"`
+	BlockID    string      `json:"block_id"`
+	Comments   []*Comment  `json:"comments,omitempty"`
+}
+
+// savedPage is the format written by SavePage. It only contains records
+// as returned by Notion server. Everything else is re-calculated on load
+type savedPage struct {
+	Version int    `json:"version"`
+	ID      string `json:"id"`
+	// blocks of unknown type are saved as sent by the server, other
+	// blocks as returned by rawBlock
+	Blocks          []json.RawMessage `json:"blocks"`
+	Users           []*User           `json:"users,omitempty"`
+	Collections     []*Collection     `json:"collections,omitempty"`
+	CollectionViews []*CollectionView `json:"collection_views,omitempty"`
+	Tables          []*savedTable     `json:"tables,omitempty"`
+	Ancestors       []*Ancestor       `json:"ancestors,omitempty"`
+	// true if discussions were downloaded, even if there are none
+	DiscussionsLoaded bool               `json:"discussions_loaded,omitempty"`
+	Discussions       []*savedDiscussion `json:"discussions,omitempty"`
+}
+
+// rawBlock returns a copy of the block with only the values that come
+// from Notion server. Title and icon of an alias target are kept because
+// the target is usually not part of the page
+func rawBlock(b *Block) *Block {
+	return &Block{
+		AliasIcon:         b.AliasIcon,
+		AliasTitle:        b.AliasTitle,
+		Alive:             b.Alive,
+		ContentIDs:        b.ContentIDs,
+		CopiedFrom:        b.CopiedFrom,
+		CopiedFromPointer: b.CopiedFromPointer,
+		CollectionID:      b.CollectionID,
+		CreatedBy:         b.CreatedBy,
+		CreatedByID:       b.CreatedByID,
+		CreatedByTable:    b.CreatedByTable,
+		CreatedTime:       b.CreatedTime,
+		DiscussionIDs:     b.DiscussionIDs,
+		FileIDs:           b.FileIDs,
+		FormatRaw:         b.FormatRaw,
+		ID:                b.ID,
+		IgnoreBlockCount:  b.IgnoreBlockCount,
+		IsTemplate:        b.IsTemplate,
+		LastEditedBy:      b.LastEditedBy,
+		LastEditedByID:    b.LastEditedByID,
+		LastEditedByTable: b.LastEditedByTable,
+		LastEditedTime:    b.LastEditedTime,
+		ParentID:          b.ParentID,
+		ParentTable:       b.ParentTable,
+		Permissions:       b.Permissions,
+		Properties:        b.Properties,
+		Type:              b.Type,
+		Version:           b.Version,
+		ViewIDs:           b.ViewIDs,
+	}
+}
+
+func collectBlocks(blocks []*Block, idToBlock map[string]*Block) {
+	for _, b := range blocks {
+		if b == nil {
+			continue
+		}
+		if _, ok := idToBlock[b.ID]; ok {
+			continue
+		}
+		idToBlock[b.ID] = b
+		collectBlocks(b.Content, idToBlock)
+		collectBlocks(b.TemplateContent, idToBlock)
+	}
+}
+
+func blockIDs(blocks []*Block) []string {
+	var res []string
+	for _, b := range blocks {
+		res = append(res, b.ID)
+	}
+	return res
+}
+
+// SavePage writes all records of the page to w in a stable json format.
+// Use LoadPage to re-create the page
+func SavePage(w io.Writer, p *Page) error {
+	idToBlock := map[string]*Block{}
+	collectBlocks([]*Block{p.Root}, idToBlock)
+	idToCollection := map[string]*Collection{}
+	idToCollectionView := map[string]*CollectionView{}
+	res := &savedPage{
+		Version:           savedPageVersion,
+		ID:                p.Root.ID,
+		Users:             p.Users,
+		Ancestors:         p.Ancestors,
+		DiscussionsLoaded: p.discussionsLoaded,
+	}
+	for _, d := range p.Discussions {
+		sd := &savedDiscussion{
+			Discussion: d,
+			BlockID:    d.BlockID,
+			Comments:   d.Comments,
+		}
+		res.Discussions = append(res.Discussions, sd)
+	}
+	for _, block := range p.collectionBlocks() {
+		if block.Type != BlockCollectionView && block.Type != BlockCollectionViewPage {
+			continue
+		}
+		for _, info := range block.CollectionViews {
+			if info.Collection == nil || info.CollectionView == nil {
+				continue
+			}
+			idToCollection[info.Collection.ID] = info.Collection
+			idToCollectionView[info.CollectionView.ID] = info.CollectionView
+			collectBlocks(info.CollectionRows, idToBlock)
+			table := &savedTable{
+				BlockID:          block.ID,
+				CollectionID:     info.Collection.ID,
+				CollectionViewID: info.CollectionView.ID,
+				RowIDs:           blockIDs(info.CollectionRows),
+			}
+			for _, t := range p.Tables {
+				if t.CollectionView != info.CollectionView {
+					continue
+				}
+				for _, g := range t.Groups {
+					sg := &savedTableGroup{
+						Type:   g.Type,
+						Value:  g.Value,
+						RowIDs: blockIDs(g.Data),
+					}
+					table.Groups = append(table.Groups, sg)
+				}
+			}
+			res.Tables = append(res.Tables, table)
+		}
+	}
+
+	var blocks []*Block
+	for _, b := range idToBlock {
+		blocks = append(blocks, b)
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].ID < blocks[j].ID
+	})
+	for _, b := range blocks {
+		d := b.RawJSON
+		if len(d) == 0 {
+			var err error
+			d, err = json.Marshal(rawBlock(b))
+			if err != nil {
+				return err
+			}
+		}
+		res.Blocks = append(res.Blocks, d)
+	}
+	for _, c := range idToCollection {
+		res.Collections = append(res.Collections, c)
+	}
+	sort.Slice(res.Collections, func(i, j int) bool {
+		return res.Collections[i].ID < res.Collections[j].ID
+	})
+	for _, cv := range idToCollectionView {
+		res.CollectionViews = append(res.CollectionViews, cv)
+	}
+	sort.Slice(res.CollectionViews, func(i, j int) bool {
+		return res.CollectionViews[i].ID < res.CollectionViews[j].ID
+	})
+
+	d, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(d)
+	return err
+}
+
+// LoadPage re-creates a page saved with SavePage. The page can't be
+// modified because it has no client
+func LoadPage(r io.Reader) (*Page, error) {
+	d, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var saved savedPage
+	err = json.Unmarshal(d, &saved)
+	if err != nil {
+		return nil, err
+	}
+	if saved.Version != savedPageVersion {
+		return nil, fmt.Errorf("unsupported version %d of saved page", saved.Version)
+	}
+	idToBlock := map[string]*Block{}
+	for _, d := range saved.Blocks {
+		var b Block
+		err = json.Unmarshal(d, &b)
+		if err != nil {
+			return nil, err
+		}
+		idToBlock[b.ID] = &b
+	}
+	root := idToBlock[saved.ID]
+	if root == nil {
+		return nil, fmt.Errorf("saved page doesn't have root block with id '%s'", saved.ID)
+	}
+	page := &Page{
+		ID:                root.ID,
+		Root:              root,
+		Users:             saved.Users,
+		Ancestors:         saved.Ancestors,
+		discussionsLoaded: saved.DiscussionsLoaded,
+	}
+	for _, sd := range saved.Discussions {
+		if sd.Discussion == nil {
+			return nil, fmt.Errorf("saved page has discussion on block '%s' without a record", sd.BlockID)
+		}
+		for _, comment := range sd.Comments {
+			if err := parseComment(comment); err != nil {
+				return nil, err
+			}
+		}
+		d := sd.Discussion
+		d.BlockID = sd.BlockID
+		d.Comments = sd.Comments
+		page.Discussions = append(page.Discussions, d)
+	}
+	err = resolveBlocks(root, idToBlock, &page.Warnings)
+	if err != nil {
+		return nil, err
+	}
+
+	idToCollection := map[string]*Collection{}
+	for _, c := range saved.Collections {
+		parseCollection(c)
+		idToCollection[c.ID] = c
+	}
+	idToCollectionView := map[string]*CollectionView{}
+	for _, cv := range saved.CollectionViews {
+		parseCollectionView(cv)
+		idToCollectionView[cv.ID] = cv
+	}
+	rowsFromIDs := func(ids []string) ([]*Block, error) {
+		var res []*Block
+		for _, id := range ids {
+			b := idToBlock[id]
+			if b == nil {
+				return nil, fmt.Errorf("saved page doesn't have row block with id '%s'", id)
+			}
+			res = append(res, b)
+		}
+		return res, nil
+	}
+	for _, st := range saved.Tables {
+		block := idToBlock[st.BlockID]
+		collection := idToCollection[st.CollectionID]
+		collectionView := idToCollectionView[st.CollectionViewID]
+		if block == nil || collection == nil || collectionView == nil {
+			return nil, fmt.Errorf("saved page has incomplete table for block '%s'", st.BlockID)
+		}
+		rows, err := rowsFromIDs(st.RowIDs)
+		if err != nil {
+			return nil, err
+		}
+		info := &CollectionViewInfo{
+			CollectionView: collectionView,
+			Collection:     collection,
+			CollectionRows: rows,
+		}
+		block.CollectionViews = append(block.CollectionViews, info)
+		table := tableFromCollectionViewInfo(info)
+		for _, sg := range st.Groups {
+			groupRows, err := rowsFromIDs(sg.RowIDs)
+			if err != nil {
+				return nil, err
+			}
+			group := &TableGroup{
+				Type:  sg.Type,
+				Value: sg.Value,
+				Data:  groupRows,
+			}
+			table.Groups = append(table.Groups, group)
+		}
+		page.Tables = append(page.Tables, table)
+	}
+	page.setBlockIndex(idToBlock)
+	return page, nil
+}
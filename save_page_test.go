@@ -0,0 +1,174 @@
+package notionapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pageFromLoadPageChunk(t *testing.T, s string, pageID string) *Page {
+	var rsp LoadPageChunkResponse
+	err := json.Unmarshal([]byte(s), &rsp)
+	assert.NoError(t, err)
+	idToBlock := map[string]*Block{}
+	for id, v := range rsp.RecordMap.Blocks {
+		idToBlock[id] = v.Value
+	}
+	root := idToBlock[pageID]
+	err = resolveBlocks(root, idToBlock, nil)
+	assert.NoError(t, err)
+	page := &Page{
+		ID:   pageID,
+		Root: root,
+	}
+	for _, v := range rsp.RecordMap.Users {
+		page.Users = append(page.Users, v.Value)
+	}
+	return page
+}
+
+func TestSaveLoadPage(t *testing.T) {
+	page := pageFromLoadPageChunk(t, loadPageJSON1, "300db9dc-27c8-4958-a08b-8d0c37f4cfe5")
+	table := parseTestTable(t)
+	cvBlock := &Block{
+		ID:   "ccc1c5b3-2a45-4d4e-8a3b-9ecb2e1f0c3d",
+		Type: BlockCollectionView,
+		CollectionViews: []*CollectionViewInfo{
+			{
+				CollectionView: table.CollectionView,
+				Collection:     table.Collection,
+				CollectionRows: []*Block{testRow("1", "Done")},
+			},
+		},
+	}
+	page.Root.Content = append(page.Root.Content, cvBlock)
+	page.Root.ContentIDs = append(page.Root.ContentIDs, cvBlock.ID)
+
+	var buf bytes.Buffer
+	err := SavePage(&buf, page)
+	assert.NoError(t, err)
+	saved := buf.String()
+
+	page2, err := LoadPage(bytes.NewBufferString(saved))
+	assert.NoError(t, err)
+	assert.Equal(t, page.ID, page2.ID)
+	assert.Equal(t, "Import Jun 27, 2018", page2.Root.Title)
+	assert.Equal(t, 2, len(page2.Root.Content))
+	assert.Equal(t, "Test page text", page2.Root.Content[0].Title)
+	sub := page2.Root.Content[0]
+	assert.Equal(t, "This is a simple text.", sub.Content[0].InlineContent[0].Text)
+	assert.Equal(t, 1, len(page2.Users))
+	assert.Equal(t, 1, len(page2.Tables))
+	assert.Equal(t, "My Tasks", page2.Tables[0].Collection.Name())
+	assert.Equal(t, "Done", page2.Tables[0].Rows()[0].Select("Status"))
+
+	// saving a loaded page produces the same output
+	buf.Reset()
+	err = SavePage(&buf, page2)
+	assert.NoError(t, err)
+	assert.Equal(t, saved, buf.String())
+
+	err = page2.SetTitle("new title")
+	assert.Equal(t, ErrOfflinePage, err)
+}
+
+func TestSaveLoadCollectionViewPage(t *testing.T) {
+	table := parseTestTable(t)
+	root := &Block{
+		ID:           "ccc1c5b3-2a45-4d4e-8a3b-9ecb2e1f0c3d",
+		Type:         BlockCollectionViewPage,
+		Alive:        true,
+		CollectionID: table.Collection.ID,
+		ViewIDs:      []string{table.CollectionView.ID},
+		CollectionViews: []*CollectionViewInfo{
+			{
+				CollectionView: table.CollectionView,
+				Collection:     table.Collection,
+				CollectionRows: []*Block{testRow("1", "Done")},
+			},
+		},
+	}
+	page := &Page{ID: root.ID, Root: root, Tables: []*Table{table}}
+
+	var buf bytes.Buffer
+	err := SavePage(&buf, page)
+	assert.NoError(t, err)
+	page2, err := LoadPage(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(page2.Tables))
+	assert.Equal(t, 1, len(page2.Root.CollectionViews))
+	assert.Equal(t, "Done", page2.Tables[0].Rows()[0].Select("Status"))
+}
+
+func TestSaveLoadPageDiscussions(t *testing.T) {
+	page := pageFromLoadPageChunk(t, loadPageJSON1, "300db9dc-27c8-4958-a08b-8d0c37f4cfe5")
+	b := page.Root.Content[0]
+	page.Ancestors = []*Ancestor{
+		{Table: TableSpace, ID: "bc202e06-6caa-4e3f-81eb-f226ab5deef7", Title: "Work"},
+	}
+	comment := &Comment{
+		ID:      "c1000000-0000-0000-0000-000000000001",
+		Alive:   true,
+		TextRaw: []interface{}{[]interface{}{"looks good"}},
+	}
+	page.Discussions = []*Discussion{
+		{
+			ID:         "d1000000-0000-0000-0000-000000000001",
+			Alive:      true,
+			CommentIDs: []string{comment.ID},
+			BlockID:    b.ID,
+			Comments:   []*Comment{comment},
+		},
+	}
+	page.discussionsLoaded = true
+
+	var buf bytes.Buffer
+	err := SavePage(&buf, page)
+	assert.NoError(t, err)
+	page2, err := LoadPage(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, page.Ancestors, page2.Ancestors)
+	assert.True(t, page2.discussionsLoaded)
+	assert.Equal(t, 1, len(page2.Discussions))
+	d := page2.Discussions[0]
+	assert.Equal(t, b.ID, d.BlockID)
+	assert.Equal(t, 1, len(d.Comments))
+	assert.Equal(t, "looks good", InlineToText(d.Comments[0].Text))
+}
+
+func TestSaveLoadPageUnknownBlock(t *testing.T) {
+	s := `{"id":"1","type":"ai_block","alive":true,"parent_id":"c969c945-5d7c-4dd7-9c7f-860f3ace6429","parent_table":"block","properties":{"title":[["hi"]]},"ai_prompt":"summarize"}`
+	var unknown Block
+	err := json.Unmarshal([]byte(s), &unknown)
+	assert.NoError(t, err)
+	alias := &Block{
+		ID:          "2",
+		Type:        BlockAlias,
+		AliasPageID: "3",
+		AliasTitle:  "Linked page",
+		AliasIcon:   "🔗",
+	}
+	page := testPage(&unknown, alias)
+	page.Root.ContentIDs = []string{"1", "2"}
+
+	var buf bytes.Buffer
+	err = SavePage(&buf, page)
+	assert.NoError(t, err)
+	page2, err := LoadPage(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(page2.Root.Content))
+	b := page2.Root.Content[0]
+	assert.True(t, b.IsUnknownType())
+	// fields we don't model are kept
+	var m map[string]interface{}
+	err = json.Unmarshal(b.RawJSON, &m)
+	assert.NoError(t, err)
+	assert.Equal(t, "summarize", m["ai_prompt"])
+	assert.Equal(t, []string{"ai_block"}, page2.UnknownTypes())
+
+	b = page2.Root.Content[1]
+	assert.Equal(t, "Linked page", b.AliasTitle)
+	assert.Equal(t, "🔗", b.AliasIcon)
+}
@@ -0,0 +1,146 @@
+package notionapi
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	// notion marks matched parts of search results with this tag
+	searchHighlightTag = "gzkNfoUU"
+)
+
+// SearchOptions allows customizing Client.Search
+type SearchOptions struct {
+	// id of the workspace to search. If not set, we search
+	// Client.SpaceID or the first workspace of the user (see GetSpaces)
+	SpaceID string
+	// if set, only pages inside this page are returned
+	AncestorID string
+	// maximum number of results. Default is 20
+	Limit int
+}
+
+// SearchResult describes a single result of Client.Search
+type SearchResult struct {
+	// id of the block (usually a page) that matched the query
+	ID string
+	// title with matched parts marked with <em> and </em>
+	HighlightedTitle string
+	// text of the matched block with matched parts marked with <em>
+	// and </em>. Usually empty if title matched
+	HighlightedText string
+	Score           float64
+	// the block, if returned by the server. Use Client.DownloadPage(ID)
+	// to get the content of the page
+	Block *Block
+}
+
+type searchFilters struct {
+	IsDeletedOnly          bool     `json:"isDeletedOnly"`
+	ExcludeTemplates       bool     `json:"excludeTemplates"`
+	IsNavigableOnly        bool     `json:"isNavigableOnly"`
+	RequireEditPermissions bool     `json:"requireEditPermissions"`
+	Ancestors              []string `json:"ancestors"`
+	CreatedBy              []string `json:"createdBy"`
+	EditedBy               []string `json:"editedBy"`
+	LastEditedTime         struct{} `json:"lastEditedTime"`
+	CreatedTime            struct{} `json:"createdTime"`
+}
+
+// /api/v3/search request
+type searchRequest struct {
+	Type    string        `json:"type"`
+	Query   string        `json:"query"`
+	SpaceID string        `json:"spaceId"`
+	Limit   int           `json:"limit"`
+	Filters searchFilters `json:"filters"`
+	Sort    string        `json:"sort"`
+	Source  string        `json:"source"`
+}
+
+// SearchResponse is a response to /api/v3/search api
+type SearchResponse struct {
+	Results   []*searchResultRaw `json:"results"`
+	Total     int                `json:"total"`
+	RecordMap RecordMap          `json:"recordMap"`
+}
+
+type searchResultRaw struct {
+	ID          string  `json:"id"`
+	IsNavigable bool    `json:"isNavigable"`
+	Score       float64 `json:"score"`
+	Highlight   struct {
+		Title string `json:"title"`
+		Text  string `json:"text"`
+	} `json:"highlight"`
+}
+
+// convertSearchHighlight replaces notion's highlight markup with <em>
+func convertSearchHighlight(s string) string {
+	s = strings.Replace(s, "<"+searchHighlightTag+">", "<em>", -1)
+	return strings.Replace(s, "</"+searchHighlightTag+">", "</em>", -1)
+}
+
+// Search returns pages in the workspace that match the query
+func (c *Client) Search(query string, opts *SearchOptions) ([]*SearchResult, error) {
+	return c.SearchCtx(context.Background(), query, opts)
+}
+
+// SearchCtx is like Search but can be canceled with ctx
+func (c *Client) SearchCtx(ctx context.Context, query string, opts *SearchOptions) ([]*SearchResult, error) {
+	if opts == nil {
+		opts = &SearchOptions{}
+	}
+	spaceID := opts.SpaceID
+	if spaceID == "" {
+		var err error
+		spaceID, err = c.resolveSpaceID(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	req := &searchRequest{
+		Type:    "BlocksInSpace",
+		Query:   query,
+		SpaceID: spaceID,
+		Limit:   opts.Limit,
+		Sort:    "Relevance",
+		Source:  "quick_find",
+		Filters: searchFilters{
+			Ancestors: []string{},
+			CreatedBy: []string{},
+			EditedBy:  []string{},
+		},
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if opts.AncestorID != "" {
+		req.Filters.Ancestors = []string{mustNormalizeID(opts.AncestorID)}
+	}
+	apiURL := "/api/v3/search"
+	var rsp SearchResponse
+	err := doNotionAPI(ctx, c, apiURL, req, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	var res []*SearchResult
+	for _, r := range rsp.Results {
+		sr := &SearchResult{
+			ID:               r.ID,
+			HighlightedTitle: convertSearchHighlight(r.Highlight.Title),
+			HighlightedText:  convertSearchHighlight(r.Highlight.Text),
+			Score:            r.Score,
+		}
+		if b, ok := rsp.RecordMap.Blocks[r.ID]; ok && b.Value != nil {
+			sr.Block = b.Value
+			if err := parseProperties(sr.Block); err != nil {
+				return nil, err
+			}
+			parseFormat(sr.Block)
+		}
+		res = append(res, sr)
+	}
+	return res, nil
+}
@@ -0,0 +1,64 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	searchJSON = `{
+	"results": [
+		{
+			"id": "300db9dc-27c8-4958-a08b-8d0c37f4cfe5",
+			"isNavigable": true,
+			"score": 12.5,
+			"highlight": {
+				"title": "<gzkNfoUU>Test</gzkNfoUU> page"
+			}
+		}
+	],
+	"total": 1,
+	"recordMap": {
+		"block": {
+			"300db9dc-27c8-4958-a08b-8d0c37f4cfe5": {
+				"role": "reader",
+				"value": {
+					"alive": true,
+					"id": "300db9dc-27c8-4958-a08b-8d0c37f4cfe5",
+					"type": "page",
+					"properties": {
+						"title": [["Test page"]]
+					}
+				}
+			}
+		}
+	}
+}`
+)
+
+func TestSearch(t *testing.T) {
+	c, transport := newStaticClient(200, searchJSON)
+	opts := &SearchOptions{
+		SpaceID:    "bc202e06-6caa-4e3f-81eb-f226ab5deef7",
+		AncestorID: "4c6a54c68b3e4ea2af9cfaabcc88d58d",
+		Limit:      5,
+	}
+	res, err := c.Search("test", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(res))
+	r := res[0]
+	assert.Equal(t, "300db9dc-27c8-4958-a08b-8d0c37f4cfe5", r.ID)
+	assert.Equal(t, "<em>Test</em> page", r.HighlightedTitle)
+	assert.Equal(t, 12.5, r.Score)
+	assert.Equal(t, "Test page", r.Block.Title)
+
+	var req searchRequest
+	err = json.Unmarshal([]byte(transport.requests[0]), &req)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", req.Query)
+	assert.Equal(t, "bc202e06-6caa-4e3f-81eb-f226ab5deef7", req.SpaceID)
+	assert.Equal(t, 5, req.Limit)
+	assert.Equal(t, []string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"}, req.Filters.Ancestors)
+}
@@ -0,0 +1,282 @@
+package notionapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SelectorError is returned by CompileSelector for invalid selectors
+type SelectorError struct {
+	Selector string
+	// position in Selector, in bytes
+	Pos int
+	Msg string
+}
+
+func (e *SelectorError) Error() string {
+	return fmt.Sprintf("invalid selector '%s': %s at position %d", e.Selector, e.Msg, e.Pos)
+}
+
+// selectorAttrs are attributes that can be used in [attr=value]
+var selectorAttrs = map[string]bool{
+	"language": true,
+	"checked":  true,
+	"color":    true,
+}
+
+type selectorAttr struct {
+	name string
+	// "" for [attr] which checks that the attribute is set
+	value string
+}
+
+// selectorPart is a type with attributes e.g. code[language=Go]
+type selectorPart struct {
+	// block type or "*" for any block
+	typ   string
+	attrs []selectorAttr
+	// if true, the part must match the parent of the block that matches
+	// the next part. Otherwise any ancestor
+	isChild bool
+}
+
+// Selector is a compiled selector, see CompileSelector
+type Selector struct {
+	s     string
+	parts []*selectorPart
+}
+
+type selectorParser struct {
+	s   string
+	pos int
+}
+
+func (p *selectorParser) errorf(format string, args ...interface{}) error {
+	return &SelectorError{Selector: p.s, Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *selectorParser) skipSpaces() bool {
+	start := p.pos
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t' || p.s[p.pos] == '\n') {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func isSelectorNameChar(c byte) bool {
+	return c == '_' || c == '-' || c == '.' || c == '+' || c == '#' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func (p *selectorParser) name() string {
+	start := p.pos
+	for p.pos < len(p.s) && isSelectorNameChar(p.s[p.pos]) {
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+// value parses a value of an attribute, a name or a quoted string
+func (p *selectorParser) value() (string, error) {
+	if p.pos < len(p.s) && (p.s[p.pos] == '"' || p.s[p.pos] == '\'') {
+		quote := p.s[p.pos]
+		end := strings.IndexByte(p.s[p.pos+1:], quote)
+		if end < 0 {
+			return "", p.errorf("unterminated string")
+		}
+		v := p.s[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return v, nil
+	}
+	v := p.name()
+	if v == "" {
+		return "", p.errorf("expected a value")
+	}
+	return v, nil
+}
+
+func (p *selectorParser) attr() (selectorAttr, error) {
+	// we're after '['
+	p.skipSpaces()
+	start := p.pos
+	var a selectorAttr
+	a.name = p.name()
+	if a.name == "" {
+		return a, p.errorf("expected an attribute name")
+	}
+	if !selectorAttrs[a.name] {
+		p.pos = start
+		return a, p.errorf("unknown attribute '%s'", a.name)
+	}
+	p.skipSpaces()
+	if p.pos < len(p.s) && p.s[p.pos] == '=' {
+		p.pos++
+		p.skipSpaces()
+		v, err := p.value()
+		if err != nil {
+			return a, err
+		}
+		a.value = v
+		p.skipSpaces()
+	}
+	if p.pos >= len(p.s) || p.s[p.pos] != ']' {
+		return a, p.errorf("expected ']'")
+	}
+	p.pos++
+	return a, nil
+}
+
+func (p *selectorParser) part() (*selectorPart, error) {
+	part := &selectorPart{}
+	if p.pos < len(p.s) && p.s[p.pos] == '*' {
+		part.typ = "*"
+		p.pos++
+	} else {
+		part.typ = p.name()
+	}
+	if part.typ == "" && (p.pos >= len(p.s) || p.s[p.pos] != '[') {
+		return nil, p.errorf("expected a block type")
+	}
+	if part.typ == "" {
+		part.typ = "*"
+	}
+	for p.pos < len(p.s) && p.s[p.pos] == '[' {
+		p.pos++
+		a, err := p.attr()
+		if err != nil {
+			return nil, err
+		}
+		part.attrs = append(part.attrs, a)
+	}
+	return part, nil
+}
+
+// CompileSelector parses a selector like "page > toggle code[language=Go]".
+// A selector is a list of block types (e.g. BlockToggle) or "*" for any
+// block, optionally with attributes in brackets, separated by space (the
+// next block is anywhere inside the previous one) or '>' (the next block
+// is a child of the previous one). Attributes are language of BlockCode,
+// checked of BlockTodo ("true" or "false") and color (see Block.BlockColor).
+// [attr] without a value matches blocks that have the attribute
+func CompileSelector(s string) (*Selector, error) {
+	p := &selectorParser{s: s}
+	res := &Selector{s: s}
+	p.skipSpaces()
+	isChild := false
+	for {
+		part, err := p.part()
+		if err != nil {
+			return nil, err
+		}
+		part.isChild = isChild
+		res.parts = append(res.parts, part)
+		hadSpace := p.skipSpaces()
+		if p.pos >= len(p.s) {
+			break
+		}
+		isChild = false
+		if p.s[p.pos] == '>' {
+			isChild = true
+			p.pos++
+			p.skipSpaces()
+			if p.pos >= len(p.s) {
+				return nil, p.errorf("expected a block type after '>'")
+			}
+		} else if !hadSpace {
+			return nil, p.errorf("unexpected '%c'", p.s[p.pos])
+		}
+	}
+	return res, nil
+}
+
+// String returns the selector
+func (s *Selector) String() string {
+	return s.s
+}
+
+func (a *selectorAttr) matches(b *Block) bool {
+	switch a.name {
+	case "language":
+		if b.Type != BlockCode {
+			return false
+		}
+		if a.value == "" {
+			return b.CodeLanguage != ""
+		}
+		return CodeLanguageToHighlightName(b.CodeLanguage) == CodeLanguageToHighlightName(a.value)
+	case "checked":
+		if b.Type != BlockTodo {
+			return false
+		}
+		if a.value == "" {
+			return b.IsChecked
+		}
+		return b.IsChecked == parseChecked(a.value)
+	case "color":
+		color := b.BlockColor()
+		if a.value == "" {
+			return color != ""
+		}
+		return strings.EqualFold(color, a.value)
+	}
+	return false
+}
+
+func (part *selectorPart) matches(b *Block) bool {
+	if part.typ != "*" && part.typ != b.Type {
+		return false
+	}
+	for i := range part.attrs {
+		if !part.attrs[i].matches(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// matches returns true if parts[:i+1] match b and its ancestors
+func (s *Selector) matches(i int, b *Block, parents map[*Block]*Block) bool {
+	part := s.parts[i]
+	if !part.matches(b) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	for parent := parents[b]; parent != nil; parent = parents[parent] {
+		if s.matches(i-1, parent, parents) {
+			return true
+		}
+		if part.isChild {
+			break
+		}
+	}
+	return false
+}
+
+// Match returns blocks of the page, including the root, that match the
+// selector, in document order
+func (s *Selector) Match(p *Page) []*Block {
+	var res []*Block
+	parents := map[*Block]*Block{}
+	last := len(s.parts) - 1
+	p.ForEachBlockWithParent(func(b, parent *Block) bool {
+		if parent != nil {
+			parents[b] = parent
+		}
+		if s.matches(last, b, parents) {
+			res = append(res, b)
+		}
+		return true
+	})
+	return res
+}
+
+// Select returns blocks of the page that match a selector, see
+// CompileSelector
+func (p *Page) Select(selector string) ([]*Block, error) {
+	s, err := CompileSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	return s.Match(p), nil
+}
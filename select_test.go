@@ -0,0 +1,73 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelect(t *testing.T) {
+	code := func(id string, lang string) *Block {
+		return &Block{ID: id, Type: BlockCode, CodeLanguage: lang}
+	}
+	todo := func(id string, checked bool) *Block {
+		return &Block{ID: id, Type: BlockTodo, IsChecked: checked}
+	}
+	toggle := func(id string, children ...*Block) *Block {
+		return &Block{ID: id, Type: BlockToggle, Content: children}
+	}
+	red := ColorRed
+	colored := &Block{ID: "red", Type: BlockText, FormatText: &FormatText{BlockColor: &red}}
+	page := testPage(
+		toggle("t1", code("go1", "Go"), toggle("t2", code("go2", "Go"), code("py", "Python"))),
+		code("go3", "Go"),
+		todo("done", true),
+		todo("not-done", false),
+		colored,
+	)
+	ids := func(selector string) []string {
+		blocks, err := page.Select(selector)
+		assert.NoError(t, err, selector)
+		var res []string
+		for _, b := range blocks {
+			res = append(res, b.ID)
+		}
+		return res
+	}
+	assert.Equal(t, []string{"go1", "go2", "go3"}, ids("code[language=Go]"))
+	assert.Equal(t, []string{"go1"}, ids("page > toggle > code[language=go]"))
+	assert.Equal(t, []string{"go1", "go2"}, ids("page > toggle code[language=Go]"))
+	assert.Equal(t, []string{"go2", "py"}, ids("toggle toggle code"))
+	assert.Equal(t, []string{"py"}, ids(`code[language="Python"]`))
+	assert.Equal(t, []string{"t1", "t2"}, ids("toggle"))
+	assert.Equal(t, []string{"done"}, ids("to_do[checked=true]"))
+	assert.Equal(t, []string{"not-done"}, ids("to_do[checked=false]"))
+	assert.Equal(t, []string{"red"}, ids("[color=red]"))
+	assert.Equal(t, []string{"red"}, ids("*[color]"))
+	assert.Equal(t, []string{page.Root.ID}, ids("page"))
+	assert.Nil(t, ids("code > toggle"))
+
+	tests := []struct {
+		s   string
+		pos int
+	}{
+		{"", 0},
+		{"code[", 5},
+		{"code[size=1]", 5},
+		{"code[language=Go", 16},
+		{`code[language="Go]`, 14},
+		{"toggle >", 8},
+		{"toggle,code", 6},
+	}
+	for _, test := range tests {
+		_, err := CompileSelector(test.s)
+		selErr, ok := err.(*SelectorError)
+		if assert.True(t, ok, test.s) {
+			assert.Equal(t, test.pos, selErr.Pos, test.s)
+		}
+	}
+	s, err := CompileSelector("page > code")
+	assert.NoError(t, err)
+	assert.Equal(t, "page > code", s.String())
+	assert.Equal(t, 1, len(s.Match(page)))
+}
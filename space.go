@@ -0,0 +1,184 @@
+package notionapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// protects Client.defaultSpaceID
+var spaceMu sync.Mutex
+
+// GetSpaces returns workspaces the user is a member of, sorted by name
+func (c *Client) GetSpaces() ([]*Space, error) {
+	return c.GetSpacesCtx(context.Background())
+}
+
+// GetSpacesCtx is like GetSpaces but can be canceled with ctx
+func (c *Client) GetSpacesCtx(ctx context.Context) ([]*Space, error) {
+	_, spaces, err := c.GetCurrentUserCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(spaces) > 0 {
+		spaceMu.Lock()
+		c.defaultSpaceID = spaces[0].ID
+		spaceMu.Unlock()
+	}
+	return spaces, nil
+}
+
+// knownSpaceID returns Client.SpaceID or, if it was already resolved,
+// the id of the first workspace of the user. Returns "" if neither is known
+func (c *Client) knownSpaceID() string {
+	if c.SpaceID != "" {
+		return c.SpaceID
+	}
+	spaceMu.Lock()
+	defer spaceMu.Unlock()
+	return c.defaultSpaceID
+}
+
+// resolveSpaceID is like knownSpaceID but asks the server for workspaces
+// of the user if it doesn't know the id yet
+func (c *Client) resolveSpaceID(ctx context.Context) (string, error) {
+	if spaceID := c.knownSpaceID(); spaceID != "" {
+		return spaceID, nil
+	}
+	spaces, err := c.GetSpacesCtx(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(spaces) == 0 {
+		return "", errors.New("user doesn't have any workspaces")
+	}
+	return spaces[0].ID, nil
+}
+
+// GetTopLevelPages returns pages at the top level of the workspace (those
+// shown in a sidebar), in the order of the sidebar. Blocks only have
+// properties and format parsed (e.g. Title and FormatPage.PageIcon),
+// use DownloadPage to get their content
+func (c *Client) GetTopLevelPages(spaceID string) ([]*Block, error) {
+	return c.GetTopLevelPagesCtx(context.Background(), spaceID)
+}
+
+// GetTopLevelPagesCtx is like GetTopLevelPages but can be canceled with ctx
+func (c *Client) GetTopLevelPagesCtx(ctx context.Context, spaceID string) ([]*Block, error) {
+	rsp, err := c.GetRecordValuesCtx(ctx, []RecordRequest{{Table: TableSpace, ID: spaceID}})
+	if err != nil {
+		return nil, err
+	}
+	space := rsp.Results[0].Space
+	if space == nil {
+		return nil, fmt.Errorf("couldn't retrieve space with id %s: %w", spaceID, ErrNotFound)
+	}
+	var requests []RecordRequest
+	for _, id := range space.Pages {
+		requests = append(requests, RecordRequest{Table: TableBlock, ID: id, SpaceID: space.ID})
+	}
+	rsp, err = c.GetRecordValuesCtx(ctx, requests)
+	if err != nil {
+		return nil, err
+	}
+	var res []*Block
+	for _, r := range rsp.Results {
+		// skip pages we can't access and deleted pages
+		if r.Block == nil || !r.Block.Alive {
+			continue
+		}
+		res = append(res, r.Block)
+	}
+	return res, nil
+}
+
+// /api/v3/getVisibleUsers request
+type getVisibleUsersRequest struct {
+	SpaceID string `json:"spaceId"`
+}
+
+type getVisibleUsersResponse struct {
+	Users []struct {
+		UserID string `json:"userId"`
+		Role   string `json:"role"`
+	} `json:"users"`
+}
+
+// visibleUserIDs returns ids of all users of a workspace the current user
+// can see, including guests. Returns nil if the server doesn't support
+// the api
+func (c *Client) visibleUserIDs(ctx context.Context, spaceID string) ([]string, error) {
+	req := &getVisibleUsersRequest{
+		SpaceID: spaceID,
+	}
+	var rsp getVisibleUsersResponse
+	err := doNotionAPI(ctx, c, "/api/v3/getVisibleUsers", req, &rsp)
+	if err != nil {
+		if isAPIGone(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var res []string
+	for _, u := range rsp.Users {
+		res = append(res, u.UserID)
+	}
+	return res, nil
+}
+
+// GetSpaceUsers returns members of a workspace followed by its guests
+// (User.IsGuest is true for them). Members are those in permissions of
+// the workspace. Users we can't get are skipped
+func (c *Client) GetSpaceUsers(spaceID string) ([]*User, error) {
+	return c.GetSpaceUsersCtx(context.Background(), spaceID)
+}
+
+// GetSpaceUsersCtx is like GetSpaceUsers but can be canceled with ctx
+func (c *Client) GetSpaceUsersCtx(ctx context.Context, spaceID string) ([]*User, error) {
+	rsp, err := c.GetRecordValuesCtx(ctx, []RecordRequest{{Table: TableSpace, ID: spaceID}})
+	if err != nil {
+		return nil, err
+	}
+	space := rsp.Results[0].Space
+	if space == nil {
+		return nil, fmt.Errorf("couldn't retrieve space with id %s: %w", spaceID, ErrNotFound)
+	}
+	seen := map[string]bool{}
+	var ids []string
+	if space.Permissions != nil {
+		for _, perm := range *space.Permissions {
+			if perm.Type == PermissionTypeUser && perm.UserID != "" && !seen[perm.UserID] {
+				seen[perm.UserID] = true
+				ids = append(ids, perm.UserID)
+			}
+		}
+	}
+	nMembers := len(ids)
+	visible, err := c.visibleUserIDs(ctx, space.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range visible {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	users, err := c.GetUsersCtx(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	var res []*User
+	for i, u := range users {
+		if u == nil {
+			continue
+		}
+		u.IsGuest = i >= nMembers
+		res = append(res, u)
+	}
+	return res, nil
+}
@@ -0,0 +1,216 @@
+package notionapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	spaceRecordJSON = `{
+	"results": [
+		{
+			"role": "editor",
+			"value": {
+				"id": "bc202e06-6caa-4e3f-81eb-f226ab5deef7",
+				"name": "Work",
+				"pages": [
+					"300db9dc-27c8-4958-a08b-8d0c37f4cfe5",
+					"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+					"0367c2db-381a-4f8b-9ce3-60f388a6b2e3"
+				]
+			}
+		}
+	]
+}`
+
+	topLevelPagesJSON = `{
+	"results": [
+		{
+			"role": "editor",
+			"value": {
+				"alive": true,
+				"id": "300db9dc-27c8-4958-a08b-8d0c37f4cfe5",
+				"type": "page",
+				"properties": { "title": [["Notes"]] },
+				"format": { "page_icon": "📝" }
+			}
+		},
+		{
+			"role": "editor",
+			"value": {
+				"alive": false,
+				"id": "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d",
+				"type": "page"
+			}
+		},
+		{
+			"role": "editor",
+			"value": {
+				"alive": true,
+				"id": "0367c2db-381a-4f8b-9ce3-60f388a6b2e3",
+				"type": "page",
+				"properties": { "title": [["Projects"]] }
+			}
+		}
+	]
+}`
+)
+
+// spaceTransport returns space record for requests for space records
+// and pages for other syncRecordValues requests
+type spaceTransport struct{}
+
+func (spaceTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, _ := readRequestBody(r)
+	var req syncRecordValuesRequest
+	json.Unmarshal([]byte(body), &req)
+	s := toRecordMap(TableBlock, topLevelPagesJSON)
+	if len(req.Requests) > 0 && req.Requests[0].Pointer.Table == TableSpace {
+		s = toRecordMap(TableSpace, spaceRecordJSON)
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(s)),
+		Request:    r,
+	}, nil
+}
+
+func TestGetTopLevelPages(t *testing.T) {
+	c := &Client{
+		HTTPClient: &http.Client{Transport: spaceTransport{}},
+	}
+	pages, err := c.GetTopLevelPages("bc202e06-6caa-4e3f-81eb-f226ab5deef7")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(pages))
+	assert.Equal(t, "Notes", pages[0].Title)
+	assert.Equal(t, "📝", pages[0].FormatPage.PageIcon)
+	assert.Equal(t, "Projects", pages[1].Title)
+}
+
+// userSpacesTransport returns loadUserContentJSON for loadUserContent and
+// searchJSON for other requests
+type userSpacesTransport struct {
+	urls     []string
+	requests []string
+}
+
+func (t *userSpacesTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	body, _ := readRequestBody(r)
+	t.urls = append(t.urls, r.URL.Path)
+	t.requests = append(t.requests, body)
+	s := searchJSON
+	if strings.HasSuffix(r.URL.Path, "/loadUserContent") {
+		s = loadUserContentJSON
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(s)),
+		Request:    r,
+	}, nil
+}
+
+func TestGetSpaces(t *testing.T) {
+	c, _ := newStaticClient(200, loadUserContentJSON)
+	spaces, err := c.GetSpaces()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(spaces))
+	assert.Equal(t, "Personal", spaces[0].Name)
+	assert.Equal(t, "6a38ad16-f7f5-4a4a-9c2d-5b5ac9d1a2e1", c.knownSpaceID())
+}
+
+func TestDefaultSpaceID(t *testing.T) {
+	transport := &userSpacesTransport{}
+	c := &Client{
+		AuthToken:  "token",
+		HTTPClient: &http.Client{Transport: transport},
+	}
+	_, err := c.Search("test", nil)
+	assert.NoError(t, err)
+	_, err = c.Search("test", nil)
+	assert.NoError(t, err)
+	// the default space is resolved only once
+	exp := []string{"/api/v3/loadUserContent", "/api/v3/search", "/api/v3/search"}
+	assert.Equal(t, exp, transport.urls)
+	var req searchRequest
+	json.Unmarshal([]byte(transport.requests[2]), &req)
+	assert.Equal(t, "6a38ad16-f7f5-4a4a-9c2d-5b5ac9d1a2e1", req.SpaceID)
+
+	// Client.SpaceID is used for search and sent with syncRecordValues
+	transport.urls = nil
+	c.SpaceID = "bc202e06-6caa-4e3f-81eb-f226ab5deef7"
+	_, err = c.Search("test", nil)
+	assert.NoError(t, err)
+	_, err = c.GetRecordValues([]RecordRequest{{Table: TableBlock, ID: "300db9dc-27c8-4958-a08b-8d0c37f4cfe5"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/api/v3/search", "/api/v3/syncRecordValues"}, transport.urls)
+	json.Unmarshal([]byte(transport.requests[3]), &req)
+	assert.Equal(t, c.SpaceID, req.SpaceID)
+	var syncReq syncRecordValuesRequest
+	json.Unmarshal([]byte(transport.requests[4]), &syncReq)
+	assert.Equal(t, c.SpaceID, syncReq.Requests[0].Pointer.SpaceID)
+}
+
+// spaceUsersJSON is both a response to syncRecordValues and getVisibleUsers
+const spaceUsersJSON = `{
+	"recordMap": {
+		"space": {
+			"bc202e06-6caa-4e3f-81eb-f226ab5deef7": {
+				"role": "editor",
+				"value": {
+					"id": "bc202e06-6caa-4e3f-81eb-f226ab5deef7",
+					"name": "Work",
+					"permissions": [
+						{ "role": "editor", "type": "user_permission", "user_id": "11111111-1111-1111-1111-111111111111" },
+						{ "role": "read_and_write", "type": "user_permission", "user_id": "22222222-2222-2222-2222-222222222222" }
+					]
+				}
+			}
+		},
+		"notion_user": {
+			"11111111-1111-1111-1111-111111111111": {
+				"role": "reader",
+				"value": { "id": "11111111-1111-1111-1111-111111111111", "given_name": "Owner" }
+			},
+			"22222222-2222-2222-2222-222222222222": {
+				"role": "reader",
+				"value": { "id": "22222222-2222-2222-2222-222222222222", "given_name": "Member" }
+			},
+			"33333333-3333-3333-3333-333333333333": {
+				"role": "reader",
+				"value": { "id": "33333333-3333-3333-3333-333333333333", "given_name": "Guest" }
+			}
+		}
+	},
+	"users": [
+		{ "userId": "11111111-1111-1111-1111-111111111111", "role": "editor" },
+		{ "userId": "33333333-3333-3333-3333-333333333333", "role": "reader" }
+	]
+}`
+
+func TestGetSpaceUsers(t *testing.T) {
+	c, transport := newStaticClient(200, spaceUsersJSON)
+	users, err := c.GetSpaceUsers("bc202e06-6caa-4e3f-81eb-f226ab5deef7")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(users))
+	var names []string
+	for _, u := range users {
+		names = append(names, u.FullName())
+	}
+	assert.Equal(t, []string{"Owner", "Member", "Guest"}, names)
+	assert.False(t, users[1].IsGuest)
+	assert.True(t, users[2].IsGuest)
+	// users are requested in one batch after the space and visible users
+	assert.Equal(t, 3, len(transport.requests))
+
+	c, _ = newStaticClient(200, `{"recordMap": {}}`)
+	_, err = c.GetSpaceUsers("bc202e06-6caa-4e3f-81eb-f226ab5deef7")
+	assert.True(t, IsNotFound(err))
+}
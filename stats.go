@@ -0,0 +1,101 @@
+package notionapi
+
+import (
+	"strconv"
+	"strings"
+)
+
+// PageStats describes content of a page, see Page.Stats
+type PageStats struct {
+	// number of blocks of a given type, not counting the root. Content of
+	// sub-pages is not counted
+	BlockCounts map[string]int
+	// number of words in text of the page, see Page.ToText
+	Words int
+	// number of BlockImage
+	Images int
+	// number of BlockFile, BlockAudio and BlockPDF
+	Files int
+	// sum of FileSize of Files, in bytes. Files with unknown size are
+	// not counted
+	FilesSize int64
+	// number of BlockTodo that are checked and not
+	TodosChecked   int
+	TodosUnchecked int
+	// number of child pages, not counting links to other pages
+	SubPages int
+	// depth of the most nested block, 0 if the page is empty
+	MaxDepth int
+}
+
+// parseFileSize parses FileSize like "340KB" or "1.2MB"
+func parseFileSize(s string) (int64, bool) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	units := []struct {
+		suffix string
+		n      float64
+	}{
+		{"KB", 1024},
+		{"MB", 1024 * 1024},
+		{"GB", 1024 * 1024 * 1024},
+		{"B", 1},
+	}
+	mul := float64(1)
+	for _, u := range units {
+		if strings.HasSuffix(s, u.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+			mul = u.n
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return int64(n * mul), true
+}
+
+// Stats returns statistics of the page
+func (p *Page) Stats() *PageStats {
+	res := &PageStats{
+		BlockCounts: map[string]int{},
+	}
+	if p.Root == nil {
+		return res
+	}
+	it := p.Iterator()
+	for it.Next() {
+		b := it.Block()
+		depth := it.Depth()
+		if depth == 0 {
+			continue
+		}
+		res.BlockCounts[b.Type]++
+		if depth > res.MaxDepth {
+			res.MaxDepth = depth
+		}
+		switch b.Type {
+		case BlockImage:
+			res.Images++
+		case BlockFile, BlockAudio, BlockPDF:
+			res.Files++
+			if size, ok := parseFileSize(b.FileSize); ok {
+				res.FilesSize += size
+			}
+		case BlockTodo:
+			if b.IsChecked {
+				res.TodosChecked++
+			} else {
+				res.TodosUnchecked++
+			}
+		case BlockPage:
+			if b.AliasPageID == "" {
+				res.SubPages++
+			}
+			// content of sub-pages is not part of this page
+			it.SkipChildren()
+		}
+	}
+	res.Words = len(strings.Fields(p.ToText()))
+	return res
+}
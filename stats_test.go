@@ -0,0 +1,70 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFileSize(t *testing.T) {
+	tests := []struct {
+		s   string
+		exp int64
+		ok  bool
+	}{
+		{"340KB", 340 * 1024, true},
+		{"1.5MB", 1536 * 1024, true},
+		{"12 B", 12, true},
+		{"2gb", 2 * 1024 * 1024 * 1024, true},
+		{"100", 100, true},
+		{"", 0, false},
+		{"big", 0, false},
+	}
+	for _, test := range tests {
+		got, ok := parseFileSize(test.s)
+		assert.Equal(t, test.ok, ok, test.s)
+		assert.Equal(t, test.exp, got, test.s)
+	}
+}
+
+func TestPageStats(t *testing.T) {
+	checked := textBlock(BlockTodo, "done")
+	checked.IsChecked = true
+	file := &Block{Type: BlockFile, FileSize: "2KB"}
+	pdf := &Block{Type: BlockPDF, FileSize: "1KB"}
+	audio := &Block{Type: BlockAudio}
+	page := testPage(
+		textBlock(BlockHeader, "Two words"),
+		textBlock(BlockToggle, "toggle",
+			textBlock(BlockTodo, "not done", checked),
+		),
+		&Block{Type: BlockImage},
+		file, pdf, audio,
+		textBlock(BlockPage, "Sub page", textBlock(BlockText, "not counted")),
+	)
+	stats := page.Stats()
+	exp := map[string]int{
+		BlockHeader: 1,
+		BlockToggle: 1,
+		BlockTodo:   2,
+		BlockImage:  1,
+		BlockFile:   1,
+		BlockPDF:    1,
+		BlockAudio:  1,
+		BlockPage:   1,
+	}
+	assert.Equal(t, exp, stats.BlockCounts)
+	assert.Equal(t, 1, stats.Images)
+	assert.Equal(t, 3, stats.Files)
+	assert.Equal(t, int64(3*1024), stats.FilesSize)
+	assert.Equal(t, 1, stats.TodosChecked)
+	assert.Equal(t, 1, stats.TodosUnchecked)
+	assert.Equal(t, 1, stats.SubPages)
+	assert.Equal(t, 3, stats.MaxDepth)
+	// "Test page", "Two words", "toggle", "not done", "done"
+	assert.Equal(t, 8, stats.Words)
+
+	empty := testPage().Stats()
+	assert.Equal(t, 0, empty.MaxDepth)
+	assert.Empty(t, empty.BlockCounts)
+}
@@ -0,0 +1,83 @@
+package notionapi
+
+// SubPageRef describes a page that is a child of a page or is linked from it
+type SubPageRef struct {
+	// id of the page
+	ID string
+	// title of the page, "" if we don't know it
+	Title string
+	// true if the page is a child of the page, false if it's only a link
+	// to a page in another place (BlockAlias or BlockPage with AliasPageID)
+	IsChild bool
+	// BlockPage or BlockAlias in the page
+	Block *Block
+}
+
+// pageTitle returns title of BlockPage, also for rows of collections that
+// didn't go through parseProperties
+func pageTitle(b *Block) string {
+	if b.Title != "" {
+		return b.Title
+	}
+	var title string
+	getProp(b, "title", &title)
+	return title
+}
+
+// SubPages returns child pages of the page and pages linked from it, in
+// document order. Pages that are rows of tables are not included, see
+// RowPages
+func (p *Page) SubPages() []*SubPageRef {
+	var res []*SubPageRef
+	p.ForEachBlockWithParent(func(b, parent *Block) bool {
+		if parent == nil {
+			return true
+		}
+		switch b.Type {
+		case BlockPage:
+			ref := &SubPageRef{
+				ID:      b.ID,
+				Title:   pageTitle(b),
+				IsChild: b.AliasPageID == "",
+				Block:   b,
+			}
+			res = append(res, ref)
+			// content of sub-pages is not part of this page
+			return false
+		case BlockAlias:
+			if b.AliasPageID != "" {
+				ref := &SubPageRef{
+					ID:    b.AliasPageID,
+					Title: b.AliasTitle,
+					Block: b,
+				}
+				res = append(res, ref)
+			}
+		}
+		return true
+	})
+	return res
+}
+
+// RowPages returns pages that are rows of tables in the page. A row that
+// is in many tables (views of the same collection) is returned once
+func (p *Page) RowPages() []*SubPageRef {
+	var res []*SubPageRef
+	seen := map[string]bool{}
+	for _, t := range p.Tables {
+		for _, row := range t.Data {
+			if row == nil || seen[row.ID] {
+				continue
+			}
+			seen[row.ID] = true
+			ref := &SubPageRef{
+				ID:      row.ID,
+				Title:   pageTitle(row),
+				IsChild: true,
+				Block:   row,
+			}
+			res = append(res, ref)
+		}
+	}
+	return res
+}
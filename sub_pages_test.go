@@ -0,0 +1,57 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubPages(t *testing.T) {
+	idToBlock := map[string]*Block{}
+	add := func(id string, typ string, parentID string, title string, content ...string) *Block {
+		b := &Block{
+			ID:          id,
+			Type:        typ,
+			Alive:       true,
+			ContentIDs:  content,
+			ParentID:    parentID,
+			ParentTable: TableBlock,
+			Properties:  map[string]interface{}{"title": []interface{}{[]interface{}{title}}},
+		}
+		idToBlock[id] = b
+		return b
+	}
+	root := add("root", BlockPage, "", "Root", "child", "toggle", "cousin")
+	add("child", BlockPage, "root", "Child", "grandchild")
+	add("grandchild", BlockPage, "child", "Grandchild")
+	add("toggle", BlockToggle, "root", "more", "alias")
+	alias := add("alias", BlockAlias, "toggle", "")
+	alias.FormatRaw = json.RawMessage(`{"alias_pointer": {"id": "aliased", "table": "block"}}`)
+	// we know the page linked from alias but it's not part of this page
+	add("aliased", BlockPage, "elsewhere", "Aliased")
+	// a page that is a child of another page, linked from this one
+	add("cousin", BlockPage, "uncle", "Cousin")
+	err := resolveBlocks(root, idToBlock, nil)
+	assert.NoError(t, err)
+
+	page := &Page{ID: "root", Root: root}
+	page.Tables = []*Table{
+		{Data: []*Block{testRow("row1", ""), testRow("row2", "")}},
+		{Data: []*Block{testRow("row1", "")}},
+	}
+	refs := page.SubPages()
+	assert.Equal(t, 3, len(refs))
+	assert.Equal(t, &SubPageRef{ID: "child", Title: "Child", IsChild: true, Block: idToBlock["child"]}, refs[0])
+	assert.Equal(t, "aliased", refs[1].ID)
+	assert.Equal(t, "Aliased", refs[1].Title)
+	assert.False(t, refs[1].IsChild)
+	assert.Equal(t, "cousin", refs[2].ID)
+	assert.False(t, refs[2].IsChild)
+
+	rows := page.RowPages()
+	assert.Equal(t, 2, len(rows))
+	assert.Equal(t, "row1", rows[0].ID)
+	assert.Equal(t, "Row row1", rows[0].Title)
+	assert.True(t, rows[0].IsChild)
+}
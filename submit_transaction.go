@@ -1,8 +1,12 @@
 package notionapi
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type submitTransactionRequest struct {
+	RequestID  string       `json:"requestId,omitempty"`
 	Operations []*Operation `json:"operations"`
 }
 
@@ -13,16 +17,55 @@ type Operation struct {
 	Path    []string    `json:"path"`    // e.g. ["properties", "title"]
 	Command string      `json:"command"` // "set", "update"
 	Args    interface{} `json:"args"`
+	// id of the workspace. If not set, Client.SpaceID is used
+	SpaceID string `json:"spaceId,omitempty"`
 }
 
+// SubmitTransaction applies operations. Each call gets a new transaction
+// id. If it fails, the error is *TransactionError with the id, which can
+// be passed to SubmitTransactionIdempotent to safely try again.
+// If Client.Batcher is set, operations are sent with it, combined with
+// operations of other callers, and it returns when they were committed
 func (c *Client) SubmitTransaction(ops []*Operation) error {
+	return c.SubmitTransactionCtx(context.Background(), ops)
+}
+
+// SubmitTransactionCtx is like SubmitTransaction but can be canceled with ctx
+func (c *Client) SubmitTransactionCtx(ctx context.Context, ops []*Operation) error {
+	if c.Batcher != nil {
+		return c.Batcher.SubmitCtx(ctx, ops)
+	}
+	return c.submitTransactionNow(ctx, ops)
+}
+
+// submitTransactionNow is SubmitTransactionCtx that doesn't use Batcher
+func (c *Client) submitTransactionNow(ctx context.Context, ops []*Operation) error {
+	id := NewTransactionID()
+	canRetry := c.getRetryPolicy().RetryTransactions
+	err := c.submitTransaction(ctx, ops, id, canRetry)
+	if err != nil && err != ErrNotSupportedByOfficialAPI {
+		return &TransactionError{ID: id, Err: err}
+	}
+	return err
+}
+
+func (c *Client) submitTransaction(ctx context.Context, ops []*Operation, id string, canRetry bool) error {
+	if c.IntegrationToken != "" {
+		return ErrNotSupportedByOfficialAPI
+	}
+	spaceID := c.knownSpaceID()
+	for _, op := range ops {
+		if op.SpaceID == "" {
+			op.SpaceID = spaceID
+		}
+	}
 	req := &submitTransactionRequest{
+		RequestID:  id,
 		Operations: ops,
 	}
 	// response is empty, as far as I can tell
 	var rsp map[string]interface{}
-	apiURL := "/api/v3/submitTransaction"
-	return doNotionAPI(c, apiURL, req, &rsp)
+	return doNotionAPIWithRetry(ctx, c, apiSubmitTransaction, req, &rsp, canRetry)
 }
 
 // this is title for
@@ -0,0 +1,118 @@
+package notionapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	apiGetRecordValues  = "/api/v3/getRecordValues"
+	apiSyncRecordValues = "/api/v3/syncRecordValues"
+)
+
+// recordPointer identifies a record in /api/v3/syncRecordValues request
+type recordPointer struct {
+	Table string `json:"table"`
+	ID    string `json:"id"`
+	// optional, but the server is faster if it's provided
+	SpaceID string `json:"spaceId,omitempty"`
+}
+
+type syncRecordValuesRequestInner struct {
+	Pointer recordPointer `json:"pointer"`
+	// version of the record we have, -1 means we don't have it
+	Version int64 `json:"version"`
+}
+
+// /api/v3/syncRecordValues request
+type syncRecordValuesRequest struct {
+	Requests []syncRecordValuesRequestInner `json:"requests"`
+}
+
+// /api/v3/syncRecordValues response
+// Records are grouped by table and then by id, like in loadPageChunk.
+// Besides tables recordMap has "__version__" number so tables are decoded
+// only when needed
+type syncRecordValuesResponse struct {
+	RecordMap map[string]json.RawMessage `json:"recordMap"`
+}
+
+// getRecords returns records in the same order as requests using
+// /api/v3/syncRecordValues or, if Compat.UseGetRecordValues is set,
+// /api/v3/getRecordValues
+func (c *Client) getRecords(ctx context.Context, requests []RecordRequest) ([]*recordWithRole, error) {
+	if c.getCompat().UseGetRecordValues {
+		return c.getRecordValues(ctx, requests)
+	}
+	res, err := c.syncRecordValues(ctx, requests)
+	if err != nil && isAPIGone(err) {
+		if c.detectCompat(err, apiSyncRecordValues, func(compat *Compat) { compat.UseGetRecordValues = true }) {
+			return c.getRecordValues(ctx, requests)
+		}
+	}
+	return res, err
+}
+
+func (c *Client) getRecordValues(ctx context.Context, requests []RecordRequest) ([]*recordWithRole, error) {
+	req := &getRecordValuesRequest{}
+	for _, r := range requests {
+		v := getRecordValuesRequestInner{
+			Table: r.Table,
+			ID:    r.ID,
+		}
+		req.Requests = append(req.Requests, v)
+	}
+	var rsp rawRecordValuesResponse
+	err := doNotionAPI(ctx, c, apiGetRecordValues, req, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	return rsp.Results, nil
+}
+
+func (c *Client) syncRecordValues(ctx context.Context, requests []RecordRequest) ([]*recordWithRole, error) {
+	req := &syncRecordValuesRequest{}
+	spaceID := c.knownSpaceID()
+	for _, r := range requests {
+		if r.SpaceID == "" {
+			r.SpaceID = spaceID
+		}
+		v := syncRecordValuesRequestInner{
+			Pointer: recordPointer{
+				Table:   r.Table,
+				ID:      r.ID,
+				SpaceID: r.SpaceID,
+			},
+			Version: -1,
+		}
+		req.Requests = append(req.Requests, v)
+	}
+	var rsp syncRecordValuesResponse
+	err := doNotionAPI(ctx, c, apiSyncRecordValues, req, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	tables := map[string]map[string]*recordWithRole{}
+	res := make([]*recordWithRole, len(requests))
+	for i, r := range requests {
+		records, ok := tables[r.Table]
+		if !ok {
+			err = json.Unmarshal(rsp.RecordMap[r.Table], &records)
+			if err != nil && rsp.RecordMap[r.Table] != nil {
+				return nil, fmt.Errorf("failed to decode %s records: %s", r.Table, err)
+			}
+			tables[r.Table] = records
+		}
+		// the server doesn't return records we don't have access to
+		rec := records[r.ID]
+		if rec == nil {
+			rec = &recordWithRole{}
+		}
+		if !c.Compat.DisableAutoDetect {
+			rec = unwrapRecord(rec)
+		}
+		res[i] = rec
+	}
+	return res, nil
+}
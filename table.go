@@ -0,0 +1,475 @@
+package notionapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TableGroup is a group of rows in a grouped view (e.g. a column in board view)
+type TableGroup struct {
+	// type of the column the view is grouped by e.g. "select"
+	Type string `json:"type"`
+	// value shared by rows in this group. nil for rows without a value
+	Value interface{} `json:"value,omitempty"`
+	Data  []*Block    `json:"data"`
+}
+
+// parseCollection calculates values derived from the raw json of a collection
+func parseCollection(c *Collection) {
+	if c == nil {
+		return
+	}
+	for id, col := range c.CollectionSchema {
+		if col != nil {
+			col.ID = id
+		}
+	}
+	c.CoverURL = makeImageURL(c.Cover)
+	c.Description = nil
+	if c.DescriptionRaw != nil {
+		// description is optional so a malformed one is not fatal
+		c.Description, _ = parseInlineBlocks(c.DescriptionRaw)
+	}
+}
+
+// isTemplate returns true if block is a template for new rows
+func (c *Collection) isTemplate(b *Block) bool {
+	if b == nil {
+		return false
+	}
+	if b.IsTemplate {
+		return true
+	}
+	for _, id := range c.TemplateIDs {
+		if id == b.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// DownloadTemplates downloads pages that are templates for new rows
+func (c *Collection) DownloadTemplates(client *Client) ([]*Page, error) {
+	var res []*Page
+	for _, id := range c.TemplateIDs {
+		page, err := client.DownloadPage(id)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, page)
+	}
+	return res, nil
+}
+
+// DownloadCollection downloads a database given its url, like
+// https://www.notion.so/<id>?v=<view id>, or id of the page with the
+// database. Returns the table for the view in ?v= or, if the url doesn't
+// have it, the first table of the page
+func (c *Client) DownloadCollection(uri string) (*Table, error) {
+	return c.DownloadCollectionCtx(context.Background(), uri)
+}
+
+// DownloadCollectionCtx is like DownloadCollection but can be canceled
+// with ctx
+func (c *Client) DownloadCollectionCtx(ctx context.Context, uri string) (*Table, error) {
+	parsed, err := ParseNotionURL(uri)
+	if err != nil {
+		return nil, err
+	}
+	if parsed.PageID == "" {
+		return nil, &NoIDInURLError{URL: uri}
+	}
+	page, err := c.DownloadPageCtx(ctx, parsed.PageID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range page.Tables {
+		if parsed.CollectionViewID == "" || SameID(t.CollectionView.ID, parsed.CollectionViewID) {
+			return t, nil
+		}
+	}
+	if parsed.CollectionViewID != "" {
+		return nil, fmt.Errorf("page %s doesn't have a database view with id %s: %w", parsed.PageID, parsed.CollectionViewID, ErrNotFound)
+	}
+	return nil, fmt.Errorf("page %s doesn't have a database: %w", parsed.PageID, ErrNotFound)
+}
+
+// parseCollectionView calculates values derived from the raw json of a
+// collection view
+func parseCollectionView(cv *CollectionView) {
+	if cv == nil || cv.Format == nil {
+		return
+	}
+	cv.TableProperties = nil
+	for _, tp := range cv.Format.TableProperties {
+		if tp == nil {
+			continue
+		}
+		v := TablePropertyFormat{
+			PropertyID: tp.Property,
+			Visible:    tp.Visible,
+			Width:      tp.Width,
+		}
+		cv.TableProperties = append(cv.TableProperties, v)
+	}
+}
+
+// if a view doesn't specify column order, we show the title column first
+// followed by remaining columns sorted by id, for stable output
+func allColumns(schema map[string]*CollectionColumnInfo) []*CollectionColumnInfo {
+	var res []*CollectionColumnInfo
+	for _, col := range schema {
+		res = append(res, col)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		ti := res[i].Type == ColumnTypeTitle
+		tj := res[j].Type == ColumnTypeTitle
+		if ti != tj {
+			return ti
+		}
+		return res[i].ID < res[j].ID
+	})
+	return res
+}
+
+// VisibleColumns returns columns of the table that are visible in the view,
+// in the order they are displayed. Columns that the view doesn't list
+// (e.g. added after the view was last edited) are shown after the listed
+// ones, like Notion does
+func (t *Table) VisibleColumns() []*CollectionColumnInfo {
+	if t.Collection == nil {
+		return nil
+	}
+	schema := t.Collection.CollectionSchema
+	if t.CollectionView == nil || len(t.CollectionView.TableProperties) == 0 {
+		return allColumns(schema)
+	}
+	var res []*CollectionColumnInfo
+	listed := map[string]bool{}
+	for _, tp := range t.CollectionView.TableProperties {
+		listed[tp.PropertyID] = true
+		if !tp.Visible {
+			continue
+		}
+		col := schema[tp.PropertyID]
+		if col == nil {
+			continue
+		}
+		res = append(res, col)
+	}
+	for _, col := range allColumns(schema) {
+		if !listed[col.ID] {
+			res = append(res, col)
+		}
+	}
+	return res
+}
+
+// Row is a single row of a table with accessors for values of its columns
+type Row struct {
+	Table *Table
+	// Block is a page block that stores values of columns in its Properties
+	Block *Block
+}
+
+// Rows returns rows of the table
+func (t *Table) Rows() []*Row {
+	var res []*Row
+	for _, b := range t.Data {
+		res = append(res, &Row{Table: t, Block: b})
+	}
+	return res
+}
+
+// Column returns info about a column given its name or id
+func (t *Table) Column(nameOrID string) *CollectionColumnInfo {
+	if t.Collection == nil {
+		return nil
+	}
+	schema := t.Collection.CollectionSchema
+	if col, ok := schema[nameOrID]; ok {
+		return col
+	}
+	for _, col := range schema {
+		if col.Name == nameOrID {
+			return col
+		}
+	}
+	return nil
+}
+
+func (r *Row) columnID(nameOrID string) string {
+	col := r.Table.Column(nameOrID)
+	if col == nil {
+		return nameOrID
+	}
+	return col.ID
+}
+
+// Inline returns value of a column as inline blocks
+func (r *Row) Inline(column string) []*InlineBlock {
+	v, ok := r.Block.Properties[r.columnID(column)]
+	if !ok {
+		return nil
+	}
+	inline, err := parseInlineBlocks(v)
+	if err != nil {
+		return nil
+	}
+	return inline
+}
+
+// Text returns value of a column as plain text
+func (r *Row) Text(column string) string {
+	s := ""
+	for _, b := range r.Inline(column) {
+		s += b.Text
+	}
+	return s
+}
+
+// Checkbox returns value of a checkbox column
+func (r *Row) Checkbox(column string) bool {
+	v, ok := r.Block.Properties[r.columnID(column)]
+	return ok && parseChecked(v)
+}
+
+// Select returns value of a select column
+func (r *Row) Select(column string) string {
+	return r.Text(column)
+}
+
+// MultiSelect returns values of a multi-select column
+func (r *Row) MultiSelect(column string) []string {
+	s := r.Text(column)
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// Persons returns ids of users in a person column
+func (r *Row) Persons(column string) []string {
+	var res []string
+	for _, b := range r.Inline(column) {
+		if b.UserID != "" {
+			res = append(res, b.UserID)
+		}
+	}
+	return res
+}
+
+// Date returns value of a date column or nil if not set
+func (r *Row) Date(column string) *Date {
+	for _, b := range r.Inline(column) {
+		if b.Date != nil {
+			return b.Date
+		}
+	}
+	return nil
+}
+
+// Number returns value of a number column. Returns false if not set
+func (r *Row) Number(column string) (float64, bool) {
+	s := r.Text(column)
+	if s == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// GroupBy groups rows by values in a select, multi-select, person or
+// checkbox column. Returns groups and their keys in order: options of
+// select columns are in schema order, checkboxes are "Yes" then "No",
+// users in order of appearance. Rows without value are in "" group, last.
+// Keys of select and checkbox columns include values without rows.
+// Rows with multiple values (multi-select, person) are in multiple groups.
+func (t *Table) GroupBy(columnName string) (map[string][]*Row, []string, error) {
+	col := t.Column(columnName)
+	if col == nil {
+		return nil, nil, fmt.Errorf("no column named '%s'", columnName)
+	}
+	var keys []string
+	switch col.Type {
+	case ColumnTypeSelect, ColumnMultiSelect:
+		for _, opt := range col.Options {
+			keys = append(keys, opt.Value)
+		}
+	case ColumnTypeCheckbox:
+		keys = []string{"Yes", "No"}
+	case ColumnTypePerson:
+		// keys are added as we see them
+	default:
+		return nil, nil, fmt.Errorf("can't group by column '%s' of type '%s'", columnName, col.Type)
+	}
+
+	groups := map[string][]*Row{}
+	add := func(key string, row *Row) {
+		if _, ok := groups[key]; !ok && col.Type == ColumnTypePerson && key != "" {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], row)
+	}
+	for _, row := range t.Rows() {
+		var values []string
+		switch col.Type {
+		case ColumnTypeSelect:
+			if v := row.Select(col.ID); v != "" {
+				values = []string{v}
+			}
+		case ColumnMultiSelect:
+			values = row.MultiSelect(col.ID)
+		case ColumnTypeCheckbox:
+			values = []string{"No"}
+			if row.Checkbox(col.ID) {
+				values = []string{"Yes"}
+			}
+		case ColumnTypePerson:
+			values = row.Persons(col.ID)
+		}
+		if len(values) == 0 {
+			add("", row)
+		}
+		for _, v := range values {
+			add(v, row)
+		}
+	}
+
+	// a value not in schema options (e.g. an option that was deleted)
+	// is still a group, added after known options
+	known := map[string]bool{}
+	for _, k := range keys {
+		known[k] = true
+	}
+	var extra []string
+	for k := range groups {
+		if k != "" && !known[k] {
+			extra = append(extra, k)
+		}
+	}
+	sort.Strings(extra)
+	keys = append(keys, extra...)
+	if _, ok := groups[""]; ok {
+		keys = append(keys, "")
+	}
+	return groups, keys, nil
+}
+
+// ValueString returns value of a column formatted for display: dates
+// formatted, checkboxes as ✓/✗, multiple values separated by ", "
+func (r *Row) ValueString(col *CollectionColumnInfo) string {
+	if col == nil {
+		return ""
+	}
+	switch col.Type {
+	case ColumnTypeCheckbox:
+		if r.Checkbox(col.ID) {
+			return "✓"
+		}
+		return "✗"
+	case ColumnMultiSelect:
+		return strings.Join(r.MultiSelect(col.ID), ", ")
+	case ColumnTypePerson:
+		return strings.Join(r.Persons(col.ID), ", ")
+	case ColumnTypeDate:
+		return dateToString(r.Date(col.ID))
+	}
+	return r.Text(col.ID)
+}
+
+// tableFromCollectionViewInfo creates a table for a view of a
+// collection_view block
+func tableFromCollectionViewInfo(info *CollectionViewInfo) *Table {
+	return &Table{
+		CollectionView: info.CollectionView,
+		Collection:     info.Collection,
+		Data:           info.CollectionRows,
+	}
+}
+
+// parses dates like "2019-01-02" or "2019-01-02 15:04"
+func parseDateValue(s string) (*Date, error) {
+	d := &Date{
+		Type:       DateTypeDate,
+		DateFormat: "relative",
+	}
+	parts := strings.Fields(s)
+	if len(parts) == 0 || len(parts) > 2 {
+		return nil, fmt.Errorf("'%s' is not a valid date", s)
+	}
+	if _, err := time.Parse("2006-01-02", parts[0]); err != nil {
+		return nil, fmt.Errorf("'%s' is not a valid date", s)
+	}
+	d.StartDate = parts[0]
+	if len(parts) == 2 {
+		if _, err := time.Parse("15:04", parts[1]); err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid date", s)
+		}
+		d.Type = DateTypeDateTime
+		d.StartTime = &parts[1]
+	}
+	return d, nil
+}
+
+// encodePropertyValue converts s to a value of a property of a row for
+// a column col, in the format used by Notion. Returns nil for empty s
+func encodePropertyValue(col *CollectionColumnInfo, s string) (interface{}, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	text := func(s string) interface{} {
+		return []interface{}{[]interface{}{s}}
+	}
+	switch col.Type {
+	case ColumnTypeNumber:
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid number", s)
+		}
+		return text(s), nil
+	case ColumnTypeCheckbox:
+		switch strings.ToLower(s) {
+		case "yes", "true", "1", "x", "✓":
+			return text("Yes"), nil
+		case "no", "false", "0", "✗":
+			return text("No"), nil
+		}
+		return nil, fmt.Errorf("'%s' is not a valid checkbox value", s)
+	case ColumnMultiSelect:
+		var values []string
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return text(strings.Join(values, ",")), nil
+	case ColumnTypeDate:
+		d, err := parseDateValue(s)
+		if err != nil {
+			return nil, err
+		}
+		return InlineBlocksToJSON([]*InlineBlock{{Text: InlineAt, Date: d}}), nil
+	case ColumnTypePerson:
+		var blocks []*InlineBlock
+		for _, id := range strings.Split(s, ",") {
+			userID, ok := NormalizeID(strings.TrimSpace(id))
+			if !ok {
+				return nil, fmt.Errorf("'%s' is not a valid user id", id)
+			}
+			if len(blocks) > 0 {
+				blocks = append(blocks, &InlineBlock{Text: ","})
+			}
+			blocks = append(blocks, &InlineBlock{Text: InlineAt, UserID: userID})
+		}
+		return InlineBlocksToJSON(blocks), nil
+	}
+	return text(s), nil
+}
@@ -0,0 +1,284 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	collectionViewJSON1 = `{
+	"id": "a3fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e1",
+	"alive": true,
+	"format": {
+		"table_wrap": true,
+		"table_properties": [
+			{ "width": 276, "visible": true, "property": "title" },
+			{ "width": 120, "visible": false, "property": "Ma{X" },
+			{ "width": 200, "visible": true, "property": "%E7]" }
+		]
+	},
+	"name": "Default View",
+	"parent_id": "ccc1c5b3-2a45-4d4e-8a3b-9ecb2e1f0c3d",
+	"parent_table": "block",
+	"type": "table",
+	"version": 3
+}`
+
+	collectionJSON1 = `{
+	"alive": true,
+	"id": "9d1e8c1c-3b6f-4b5e-b3c2-1e3d1b4a5f6e",
+	"name": [ [ "My " ], [ "Tasks", [ [ "b" ] ] ] ],
+	"icon": "✅",
+	"cover": "/images/page-cover/gradients_11.jpg",
+	"description": [ [ "All the " ], [ "things", [ [ "i" ] ] ], [ " to do" ] ],
+	"parent_id": "ccc1c5b3-2a45-4d4e-8a3b-9ecb2e1f0c3d",
+	"parent_table": "block",
+	"schema": {
+		"title": { "name": "Name", "type": "title" },
+		"Ma{X": { "name": "Notes", "type": "text" },
+		"%E7]": {
+			"name": "Status",
+			"type": "select",
+			"options": [
+				{ "id": "1", "color": "red", "value": "Todo" },
+				{ "id": "2", "color": "green", "value": "Done" }
+			]
+		}
+	},
+	"template_pages": [ "5e0c7b1a-2d3f-4e5a-8b6c-7d8e9f0a1b04" ],
+	"version": 7
+}`
+)
+
+func parseTestTable(t *testing.T) *Table {
+	var cv CollectionView
+	err := json.Unmarshal([]byte(collectionViewJSON1), &cv)
+	assert.NoError(t, err)
+	var c Collection
+	err = json.Unmarshal([]byte(collectionJSON1), &c)
+	assert.NoError(t, err)
+	parseCollectionView(&cv)
+	parseCollection(&c)
+	return &Table{
+		CollectionView: &cv,
+		Collection:     &c,
+	}
+}
+
+func TestTableVisibleColumns(t *testing.T) {
+	table := parseTestTable(t)
+	props := table.CollectionView.TableProperties
+	assert.Equal(t, 3, len(props))
+	assert.Equal(t, "Ma{X", props[1].PropertyID)
+	assert.False(t, props[1].Visible)
+	assert.Equal(t, 120, props[1].Width)
+
+	cols := table.VisibleColumns()
+	assert.Equal(t, 2, len(cols))
+	assert.Equal(t, "Name", cols[0].Name)
+	assert.Equal(t, "title", cols[0].ID)
+	assert.Equal(t, "Status", cols[1].Name)
+	assert.Equal(t, "%E7]", cols[1].ID)
+
+	// columns the view doesn't list are shown after the listed ones
+	schema := table.Collection.CollectionSchema
+	schema["b"] = &CollectionColumnInfo{ID: "b", Name: "B", Type: ColumnTypeText}
+	schema["a"] = &CollectionColumnInfo{ID: "a", Name: "A", Type: ColumnTypeText}
+	cols = table.VisibleColumns()
+	assert.Equal(t, 4, len(cols))
+	assert.Equal(t, "%E7]", cols[1].ID)
+	assert.Equal(t, "a", cols[2].ID)
+	assert.Equal(t, "b", cols[3].ID)
+}
+
+func TestTableVisibleColumnsNoFormat(t *testing.T) {
+	table := parseTestTable(t)
+	table.CollectionView.TableProperties = nil
+	cols := table.VisibleColumns()
+	assert.Equal(t, 3, len(cols))
+	assert.Equal(t, ColumnTypeTitle, cols[0].Type)
+}
+
+func TestCollectionIconCoverDescription(t *testing.T) {
+	table := parseTestTable(t)
+	c := table.Collection
+	assert.Equal(t, "My Tasks", c.Name())
+	assert.Equal(t, "✅", c.Icon)
+	assert.Equal(t, "https://www.notion.so/image/https:%2F%2Fwww.notion.so%2Fimages%2Fpage-cover%2Fgradients_11.jpg", c.CoverURL)
+	assert.Equal(t, 3, len(c.Description))
+	assert.Equal(t, "things", c.Description[1].Text)
+	assert.Equal(t, AttrItalic, c.Description[1].AttrFlags)
+}
+
+func testRow(id string, status string) *Block {
+	props := map[string]interface{}{
+		"title": []interface{}{[]interface{}{"Row " + id}},
+	}
+	if status != "" {
+		props["%E7]"] = []interface{}{[]interface{}{status}}
+	}
+	return &Block{
+		ID:         id,
+		Type:       BlockPage,
+		Properties: props,
+	}
+}
+
+func TestTableGroupBy(t *testing.T) {
+	table := parseTestTable(t)
+	table.Data = []*Block{
+		testRow("1", "Done"),
+		testRow("2", ""),
+		testRow("3", "Todo"),
+		testRow("4", "Done"),
+	}
+	rows := table.Rows()
+	assert.Equal(t, "Row 1", rows[0].Text("Name"))
+	assert.Equal(t, "Done", rows[0].Select("Status"))
+
+	groups, keys, err := table.GroupBy("Status")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Todo", "Done", ""}, keys)
+	assert.Equal(t, 1, len(groups["Todo"]))
+	assert.Equal(t, 2, len(groups["Done"]))
+	assert.Equal(t, "4", groups["Done"][1].Block.ID)
+	assert.Equal(t, "2", groups[""][0].Block.ID)
+
+	_, _, err = table.GroupBy("Notes")
+	assert.Error(t, err)
+	_, _, err = table.GroupBy("Missing")
+	assert.Error(t, err)
+}
+
+// rows with every encoding of checkbox values we've seen
+const checkboxRowsJSON = `[
+	{ "id": "1", "properties": { "title": [ [ "web client" ] ], "Dn}e": [ [ "Yes" ] ] } },
+	{ "id": "2", "properties": { "title": [ [ "web client" ] ], "Dn}e": [ [ "No" ] ] } },
+	{ "id": "3", "properties": { "title": [ [ "older records" ] ], "Dn}e": [ [ "yes" ] ] } },
+	{ "id": "4", "properties": { "title": [ [ "older records" ] ], "Dn}e": [ [ "no" ] ] } },
+	{ "id": "5", "properties": { "title": [ [ "bool" ] ], "Dn}e": true } },
+	{ "id": "6", "properties": { "title": [ [ "bool" ] ], "Dn}e": false } },
+	{ "id": "7", "properties": { "title": [ [ "string" ] ], "Dn}e": "true" } },
+	{ "id": "8", "properties": { "title": [ [ "string" ] ], "Dn}e": "false" } },
+	{ "id": "9", "properties": { "title": [ [ "not set" ] ] } }
+]`
+
+func TestRowCheckbox(t *testing.T) {
+	var c Collection
+	err := json.Unmarshal([]byte(`{
+		"id": "9d1e8c1c-3b6f-4b5e-b3c2-1e3d1b4a5f6e",
+		"schema": {
+			"title": { "name": "Name", "type": "title" },
+			"Dn}e": { "name": "Done", "type": "checkbox" }
+		}
+	}`), &c)
+	assert.NoError(t, err)
+	parseCollection(&c)
+	table := &Table{Collection: &c}
+	err = json.Unmarshal([]byte(checkboxRowsJSON), &table.Data)
+	assert.NoError(t, err)
+
+	var got []bool
+	for _, row := range table.Rows() {
+		got = append(got, row.Checkbox("Done"))
+	}
+	exp := []bool{true, false, true, false, true, false, true, false, false}
+	assert.Equal(t, exp, got)
+	// by column id
+	assert.True(t, table.Rows()[0].Checkbox("Dn}e"))
+}
+
+func TestCollectionTemplates(t *testing.T) {
+	table := parseTestTable(t)
+	c := table.Collection
+	assert.Equal(t, []string{"5e0c7b1a-2d3f-4e5a-8b6c-7d8e9f0a1b04"}, c.TemplateIDs)
+	assert.True(t, c.isTemplate(&Block{ID: "5e0c7b1a-2d3f-4e5a-8b6c-7d8e9f0a1b04"}))
+	assert.True(t, c.isTemplate(&Block{ID: "1", IsTemplate: true}))
+	assert.False(t, c.isTemplate(&Block{ID: "1"}))
+}
+
+const fullPageDatabaseJSON = `{
+	"recordMap": {
+		"block": {
+			"c969c945-5d7c-4dd7-9c7f-860f3ace6429": {
+				"role": "editor",
+				"value": {
+					"id": "c969c945-5d7c-4dd7-9c7f-860f3ace6429",
+					"type": "collection_view_page",
+					"alive": true,
+					"version": 1,
+					"collection_id": "e0b3c1a2-7d4f-4b8e-9a6c-1f2e3d4c5b60",
+					"view_ids": ["a3fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e1", "b4fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e2"]
+				}
+			},
+			"3f3a6a2e-8d4e-4b7e-9a0b-2f6d1c8e4a01": {
+				"role": "editor",
+				"value": {
+					"id": "3f3a6a2e-8d4e-4b7e-9a0b-2f6d1c8e4a01",
+					"type": "page",
+					"alive": true,
+					"version": 1,
+					"parent_id": "e0b3c1a2-7d4f-4b8e-9a6c-1f2e3d4c5b60",
+					"parent_table": "collection",
+					"properties": { "title": [["Row 1"]] }
+				}
+			}
+		},
+		"collection": {
+			"e0b3c1a2-7d4f-4b8e-9a6c-1f2e3d4c5b60": {
+				"role": "editor",
+				"value": {
+					"id": "e0b3c1a2-7d4f-4b8e-9a6c-1f2e3d4c5b60",
+					"alive": true,
+					"name": [["Tasks"]],
+					"schema": { "title": { "name": "Name", "type": "title" } }
+				}
+			}
+		},
+		"collection_view": {
+			"a3fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e1": {
+				"role": "editor",
+				"value": { "id": "a3fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e1", "type": "table", "name": "All", "alive": true }
+			},
+			"b4fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e2": {
+				"role": "editor",
+				"value": { "id": "b4fe8f7d-1b2e-4a4b-9b2b-2c55d0f3a7e2", "type": "board", "name": "Board", "alive": true }
+			}
+		},
+		"notion_user": {
+			"bb760e2d-d679-4b64-b2a9-03005b21870a": {
+				"role": "reader",
+				"value": { "id": "bb760e2d-d679-4b64-b2a9-03005b21870a", "email": "kkowalczyk@gmail.com" }
+			}
+		}
+	},
+	"cursor": { "stack": [] },
+	"result": {
+		"type": "table",
+		"blockIds": ["3f3a6a2e-8d4e-4b7e-9a0b-2f6d1c8e4a01"],
+		"total": 1
+	}
+}`
+
+func TestDownloadCollection(t *testing.T) {
+	c, _ := newStaticClient(200, fullPageDatabaseJSON)
+	c.RateLimiter = NewTokenBucket(1000, 100)
+	table, err := c.DownloadCollection("https://www.notion.so/kjk/c969c9455d7c4dd79c7f860f3ace6429?v=b4fe8f7d1b2e4a4b9b2b2c55d0f3a7e2")
+	assert.NoError(t, err)
+	if assert.NotNil(t, table) {
+		assert.Equal(t, "Board", table.CollectionView.Name)
+		assert.Equal(t, 1, len(table.Data))
+	}
+
+	// without ?v= it's the first view
+	table, err = c.DownloadCollection("c969c9455d7c4dd79c7f860f3ace6429")
+	assert.NoError(t, err)
+	if assert.NotNil(t, table) {
+		assert.Equal(t, "All", table.CollectionView.Name)
+	}
+
+	_, err = c.DownloadCollection("https://www.notion.so/c969c9455d7c4dd79c7f860f3ace6429?v=d4fe8f7d1b2e4a4b9b2b2c55d0f3a7e2")
+	assert.True(t, IsNotFound(err))
+}
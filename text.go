@@ -0,0 +1,196 @@
+package notionapi
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// InlineToText converts inline blocks to plain text. Mentions of pages
+// are "Untitled" because we don't know their titles
+func InlineToText(blocks []*InlineBlock) string {
+	return inlineToText(resolvePageMentions(blocks, nil, nil))
+}
+
+func inlineToText(blocks []*InlineBlock) string {
+	var buf bytes.Buffer
+	for _, b := range blocks {
+		switch {
+		case b.UserID != "":
+			buf.WriteString("@" + b.UserID)
+		case b.Date != nil:
+			buf.WriteString(dateToString(b.Date))
+		case b.Equation != "":
+			buf.WriteString(b.Equation)
+		default:
+			buf.WriteString(b.Text)
+		}
+	}
+	return buf.String()
+}
+
+// getInlineProp returns a property of the block parsed as inline blocks
+func getInlineProp(block *Block, name string) []*InlineBlock {
+	v, ok := block.Properties[name]
+	if !ok {
+		return nil
+	}
+	inline, err := parseInlineBlocks(v)
+	if err != nil {
+		return nil
+	}
+	return inline
+}
+
+type textConverter struct {
+	page  *Page
+	lines []string
+	// if true, list items start with "-" or their number and to-dos
+	// with "[ ]" or "[x]"
+	markers bool
+}
+
+// inline returns text of inline blocks with titles of mentioned pages
+func (c *textConverter) inline(blocks []*InlineBlock) string {
+	return inlineToText(resolvePageMentions(blocks, c.page.mentionedPageTitle, nil))
+}
+
+func (c *textConverter) add(s string) {
+	s = strings.TrimSpace(s)
+	if s != "" {
+		c.lines = append(c.lines, s)
+	}
+}
+
+// addTables adds rows of all views of a collection view block. Views of
+// the same collection share rows, so each row is added only once
+func (c *textConverter) addTables(block *Block) {
+	seen := map[string]bool{}
+	for _, info := range block.CollectionViews {
+		c.addTable(tableFromCollectionViewInfo(info), seen)
+	}
+}
+
+func (c *textConverter) addTable(table *Table, seen map[string]bool) {
+	cols := table.VisibleColumns()
+	for _, row := range table.Rows() {
+		if row.Block == nil || seen[row.Block.ID] {
+			continue
+		}
+		seen[row.Block.ID] = true
+		var cells []string
+		for _, col := range cols {
+			if s := row.ValueString(col); s != "" {
+				cells = append(cells, s)
+			}
+		}
+		c.add(strings.Join(cells, "\t"))
+	}
+}
+
+func (c *textConverter) addBlock(block *Block) {
+	switch block.Type {
+	case BlockPage:
+		c.add(block.Title)
+		// content of sub-pages is not part of this page
+		return
+	case BlockAlias:
+		c.add(block.AliasTitle)
+	case BlockCode:
+		c.add(block.Code)
+	case BlockEquation:
+		c.add(block.Equation)
+	case BlockBookmark:
+		c.add(c.inline(block.InlineContent))
+		c.add(block.Description)
+		c.add(block.BookmarkURL())
+	case BlockTable:
+		for _, row := range block.TableCells() {
+			var cells []string
+			for _, cell := range row {
+				if s := c.inline(cell); s != "" {
+					cells = append(cells, s)
+				}
+			}
+			c.add(strings.Join(cells, "\t"))
+		}
+		// cells are in rows, which we already added
+		return
+	case BlockDrive:
+		title, uri := block.driveTitleAndURL()
+		c.add(title)
+		if uri != title {
+			c.add(uri)
+		}
+	case BlockCollectionView:
+		c.addTables(block)
+	case BlockDivider, BlockColumnList, BlockColumn, BlockBreadcrumb:
+		// purely structural
+	case BlockBulletedList, BlockNumberedList, BlockTodo:
+		s := c.inline(block.InlineContent)
+		if c.markers {
+			s = listMarker(block) + s
+		}
+		c.add(s)
+	default:
+		c.add(c.inline(block.InlineContent))
+	}
+	c.add(c.inline(getInlineProp(block, "caption")))
+	c.addBlocks(block.Content)
+}
+
+// listMarker returns a prefix of a list item or a to-do in plain text
+func listMarker(block *Block) string {
+	switch block.Type {
+	case BlockBulletedList:
+		return "- "
+	case BlockNumberedList:
+		if block.ListIndex > 0 {
+			return strconv.Itoa(block.ListIndex) + ". "
+		}
+		return "1. "
+	case BlockTodo:
+		if block.IsChecked {
+			return "[x] "
+		}
+		return "[ ] "
+	}
+	return ""
+}
+
+func (c *textConverter) addBlocks(blocks []*Block) {
+	for _, block := range blocks {
+		if block != nil {
+			c.addBlock(block)
+		}
+	}
+}
+
+// ToText returns textual content of the page, one block per line.
+// Blocks are visited depth-first and purely structural blocks (dividers,
+// columns) are skipped
+func (p *Page) ToText() string {
+	return p.toText(false)
+}
+
+// TextContent is like ToText but list items and to-dos start with a marker
+// like "- ", "2. " or "[x] ". Text of everything in the page (captions,
+// children of toggles and callouts, cells of tables) is included, in
+// document order, so it can be used e.g. for a full-text search index
+func (p *Page) TextContent() string {
+	return p.toText(true)
+}
+
+func (p *Page) toText(markers bool) string {
+	c := &textConverter{page: p, markers: markers}
+	c.add(p.Root.Title)
+	if p.Root.Type == BlockCollectionViewPage {
+		// a full page database has rows instead of content
+		c.addTables(p.Root)
+	}
+	c.addBlocks(p.Root.Content)
+	if len(c.lines) == 0 {
+		return ""
+	}
+	return strings.Join(c.lines, "\n") + "\n"
+}
@@ -0,0 +1,104 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPageToText(t *testing.T) {
+	image := &Block{
+		Type: BlockImage,
+		Properties: map[string]interface{}{
+			"caption": []interface{}{[]interface{}{"A caption"}},
+		},
+	}
+	columns := &Block{
+		Type: BlockColumnList,
+		Content: []*Block{
+			{Type: BlockColumn, Content: []*Block{textBlock(BlockText, "left")}},
+			{Type: BlockColumn, Content: []*Block{textBlock(BlockText, "right")}},
+		},
+	}
+	page := testPage(
+		textBlock(BlockHeader, "Header"),
+		textBlock(BlockToggle, "toggle", textBlock(BlockText, "inside toggle")),
+		&Block{Type: BlockDivider},
+		image,
+		columns,
+		&Block{Type: BlockCode, Code: "x := 1"},
+	)
+	exp := `Test page
+Header
+toggle
+inside toggle
+A caption
+left
+right
+x := 1
+`
+	assert.Equal(t, exp, page.ToText())
+}
+
+func TestPageTextContent(t *testing.T) {
+	item := func(typ string, s string, index int) *Block {
+		b := textBlock(typ, s)
+		b.ListIndex = index
+		return b
+	}
+	done := textBlock(BlockTodo, "done")
+	done.IsChecked = true
+	callout := textBlock(BlockCallout, "note", textBlock(BlockText, "inside callout"))
+	page := testPage(
+		item(BlockBulletedList, "bullet", 0),
+		item(BlockNumberedList, "first", 1),
+		item(BlockNumberedList, "second", 2),
+		done,
+		textBlock(BlockTodo, "not done"),
+		callout,
+	)
+	exp := `Test page
+- bullet
+1. first
+2. second
+[x] done
+[ ] not done
+note
+inside callout
+`
+	assert.Equal(t, exp, page.TextContent())
+	// ToText doesn't have markers
+	assert.Contains(t, page.ToText(), "\nbullet\nfirst\n")
+}
+
+func TestPageToTextPageMention(t *testing.T) {
+	page := testMentionPage()
+	assert.Equal(t, "Test page\nSee Sub page and Untitled\nSub page\n", page.ToText())
+	assert.Equal(t, "See Untitled and Untitled", InlineToText(page.Root.Content[0].InlineContent))
+}
+
+func TestPageToTextCollectionViews(t *testing.T) {
+	table := parseTestTable(t)
+	view := func(rows ...*Block) *CollectionViewInfo {
+		return &CollectionViewInfo{
+			CollectionView: table.CollectionView,
+			Collection:     table.Collection,
+			CollectionRows: rows,
+		}
+	}
+	row1 := testRow("1", "Done")
+	row2 := testRow("2", "Todo")
+	// two views of the same collection, e.g. a table and a board
+	block := &Block{
+		Type:            BlockCollectionView,
+		CollectionViews: []*CollectionViewInfo{view(row1, row2), view(row2, row1)},
+	}
+	exp := "Test page\nRow 1\tDone\nRow 2\tTodo\n"
+	assert.Equal(t, exp, testPage(block).ToText())
+
+	// full page database
+	page := testPage()
+	page.Root.Type = BlockCollectionViewPage
+	page.Root.CollectionViews = block.CollectionViews
+	assert.Equal(t, exp, page.ToText())
+}
@@ -0,0 +1,60 @@
+package notionapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultRequestTimeout is used if Client.RequestTimeout is not set
+	DefaultRequestTimeout = 2 * time.Minute
+	// DefaultFileDownloadTimeout is used if Client.FileDownloadTimeout is
+	// not set
+	DefaultFileDownloadTimeout = 30 * time.Minute
+)
+
+func (c *Client) getRequestTimeout() time.Duration {
+	if c.RequestTimeout == 0 {
+		return DefaultRequestTimeout
+	}
+	return c.RequestTimeout
+}
+
+func (c *Client) getFileDownloadTimeout() time.Duration {
+	if c.FileDownloadTimeout == 0 {
+		return DefaultFileDownloadTimeout
+	}
+	return c.FileDownloadTimeout
+}
+
+// cancelOnClose cancels the context of the request when the body of
+// response is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (r *cancelOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.cancel()
+	return err
+}
+
+// sendWithTimeout sends the request which, including reading the body of
+// the response, must finish within timeout. The context of req still
+// applies: whichever expires first wins
+func (c *Client) sendWithTimeout(req *http.Request, timeout time.Duration) (*http.Response, error) {
+	if timeout <= 0 {
+		return c.getHTTPClient().Do(req)
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	rsp, err := c.getHTTPClient().Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	rsp.Body = &cancelOnClose{ReadCloser: rsp.Body, cancel: cancel}
+	return rsp, nil
+}
@@ -0,0 +1,64 @@
+package notionapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSlowServer(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+		}
+		w.Write([]byte(`{"results":[]}`))
+	}))
+}
+
+func TestRequestTimeout(t *testing.T) {
+	server := newSlowServer(2 * time.Second)
+	defer server.Close()
+	c := &Client{
+		BaseURL:        server.URL,
+		RequestTimeout: 50 * time.Millisecond,
+		RetryPolicy:    &NoRetryPolicy,
+	}
+	start := time.Now()
+	_, err := c.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	var transportErr *TransportError
+	assert.True(t, errors.As(err, &transportErr))
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestRequestTimeoutContextWins(t *testing.T) {
+	server := newSlowServer(2 * time.Second)
+	defer server.Close()
+	c := &Client{
+		BaseURL:        server.URL,
+		RequestTimeout: time.Minute,
+		RetryPolicy:    &NoRetryPolicy,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.GetBlockRecordsCtx(ctx, []string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestRequestTimeoutNotExceeded(t *testing.T) {
+	server := newSlowServer(0)
+	defer server.Close()
+	c := &Client{
+		BaseURL:        server.URL,
+		RequestTimeout: time.Minute,
+	}
+	_, err := c.GetBlockRecords([]string{"4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultFileDownloadTimeout, c.getFileDownloadTimeout())
+}
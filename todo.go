@@ -0,0 +1,68 @@
+package notionapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseChecked returns value of "checked" property of BlockTodo. The web
+// client sends [["Yes"]] or [["No"]] but older records have lowercase
+// values and some records written after API changes have a bool or
+// "true" / "false" instead
+func parseChecked(v interface{}) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		s := strings.TrimSpace(v)
+		return strings.EqualFold(s, "Yes") || strings.EqualFold(s, "true")
+	case []interface{}:
+		// [["Yes"]], the first text of inline blocks
+		if len(v) == 0 {
+			return false
+		}
+		return parseChecked(v[0])
+	}
+	return false
+}
+
+// checkedToJSON returns value of "checked" property of BlockTodo, the same
+// as sent by the web client
+func checkedToJSON(checked bool) []interface{} {
+	s := "No"
+	if checked {
+		s = "Yes"
+	}
+	return []interface{}{[]interface{}{s}}
+}
+
+func buildSetTodoCheckedOp(id string, checked bool) *Operation {
+	return &Operation{
+		ID:      id,
+		Table:   TableBlock,
+		Path:    []string{"properties", "checked"},
+		Command: "set",
+		Args:    checkedToJSON(checked),
+	}
+}
+
+// SetTodoChecked checks or unchecks BlockTodo of the page
+func (p *Page) SetTodoChecked(block *Block, checked bool) error {
+	if p.client == nil {
+		return ErrOfflinePage
+	}
+	if block.Type != BlockTodo {
+		return fmt.Errorf("block %s is '%s', not '%s'", block.ID, block.Type, BlockTodo)
+	}
+	ops := []*Operation{
+		buildSetTodoCheckedOp(block.ID, checked),
+		buildLastEditedTimeOp(block.ID),
+	}
+	err := p.client.SubmitTransaction(ops)
+	if err != nil {
+		return err
+	}
+	block.IsChecked = checked
+	block.HasCheckedProperty = true
+	return nil
+}
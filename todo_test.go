@@ -0,0 +1,61 @@
+package notionapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTodoChecked(t *testing.T) {
+	tests := []struct {
+		checked     string
+		isChecked   bool
+		hasProperty bool
+	}{
+		{`[["Yes"]]`, true, true},
+		{`[["yes"]]`, true, true},
+		{`[["No"]]`, false, true},
+		{`[["no"]]`, false, true},
+		{`true`, true, true},
+		{`false`, false, true},
+		{`"true"`, true, true},
+		{`[[true]]`, true, true},
+		{`[]`, false, true},
+		{``, false, false},
+	}
+	for _, test := range tests {
+		props := `"title": [["buy milk"]]`
+		if test.checked != "" {
+			props += `, "checked": ` + test.checked
+		}
+		block := parseTestBlock(t, `{
+	"id": "5d1e4c1a-0f6b-4d2e-9a3c-8b7e6f5d4c3b",
+	"type": "to_do",
+	"alive": true,
+	"properties": {`+props+`}
+}`)
+		assert.Equal(t, test.isChecked, block.IsChecked, test.checked)
+		assert.Equal(t, test.hasProperty, block.HasCheckedProperty, test.checked)
+	}
+}
+
+func TestSetTodoChecked(t *testing.T) {
+	c, transport := newStaticClient(200, `{}`)
+	todo := &Block{ID: "5d1e4c1a-0f6b-4d2e-9a3c-8b7e6f5d4c3b", Type: BlockTodo, IsChecked: true}
+	page := testPage(todo)
+	err := page.SetTodoChecked(todo, false)
+	assert.Equal(t, ErrOfflinePage, err)
+
+	page.client = c
+	err = page.SetTodoChecked(todo, false)
+	assert.NoError(t, err)
+	assert.False(t, todo.IsChecked)
+	assert.True(t, todo.HasCheckedProperty)
+	assert.Equal(t, 1, len(transport.requests))
+	body := transport.requests[0]
+	assert.True(t, strings.Contains(body, `"path":["properties","checked"],"command":"set","args":[["No"]]`), body)
+
+	err = page.SetTodoChecked(page.Root, true)
+	assert.Error(t, err)
+}
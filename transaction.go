@@ -0,0 +1,215 @@
+package notionapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+)
+
+// TransactionError is returned by SubmitTransaction when it fails.
+// We might not know if the server applied the operations (e.g. when the
+// request timed out), so instead of SubmitTransaction call
+// SubmitTransactionIdempotent with the same operations and ID, which
+// checks that first
+type TransactionError struct {
+	// id of the transaction
+	ID  string
+	Err error
+}
+
+func (e *TransactionError) Error() string {
+	return fmt.Sprintf("transaction %s failed: %s", e.ID, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *TransactionError) Unwrap() error {
+	return e.Err
+}
+
+// NewTransactionID returns a new, random id of a transaction for
+// SubmitTransactionIdempotent
+func NewTransactionID() string {
+	return newBlockID()
+}
+
+// isAmbiguousError returns true if err doesn't tell us if the server
+// applied the transaction
+func isAmbiguousError(err error) bool {
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return true
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500 || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// SubmitTransactionIdempotent applies operations as a transaction with
+// the given id. The id is sent as requestId, so that the same logical
+// transaction has the same id even across restarts of the program
+// (persist the id together with the operations).
+//
+// The server doesn't document that it ignores repeated request ids so
+// we don't rely on it. When a request fails in a way that doesn't tell
+// us if the operations were applied (a network error, a timeout or 5xx
+// status), we check with VerifyTransactionApplied and only send the
+// operations again if they were not applied, up to RetryPolicy.MaxAttempts
+// times
+func (c *Client) SubmitTransactionIdempotent(ops []*Operation, id string) error {
+	return c.SubmitTransactionIdempotentCtx(context.Background(), ops, id)
+}
+
+// SubmitTransactionIdempotentCtx is like SubmitTransactionIdempotent but
+// can be canceled with ctx
+func (c *Client) SubmitTransactionIdempotentCtx(ctx context.Context, ops []*Operation, id string) error {
+	policy := c.getRetryPolicy()
+	for attempt := 1; ; attempt++ {
+		// the first attempt might have been done by SubmitTransaction
+		// so we always check first if the operations were applied
+		applied, err := c.VerifyTransactionAppliedCtx(ctx, ops)
+		if err != nil {
+			return &TransactionError{ID: id, Err: err}
+		}
+		if applied {
+			return nil
+		}
+		err = c.submitTransaction(ctx, ops, id, false)
+		if err == nil {
+			return nil
+		}
+		if err == ErrNotSupportedByOfficialAPI {
+			return err
+		}
+		if ctx.Err() != nil || attempt >= policy.MaxAttempts || !isAmbiguousError(err) {
+			return &TransactionError{ID: id, Err: err}
+		}
+		delay := policy.delay(attempt)
+		logWarn(c, "transaction %s failed with %s, checking if it was applied in %s\n", id, err, delay)
+		if err := sleepCtx(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// toJSONValue converts v to a value as decoded by json.Unmarshal so that
+// values can be compared with reflect.DeepEqual
+func toJSONValue(v interface{}) (interface{}, error) {
+	d, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var res interface{}
+	err = json.Unmarshal(d, &res)
+	return res, err
+}
+
+// valueAtPath returns value in v at path, nil if it's not there
+func valueAtPath(v interface{}, path []string) interface{} {
+	for _, key := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// listHasID returns true if list (e.g. content of a block) has id
+func listHasID(list interface{}, id interface{}) bool {
+	a, _ := list.([]interface{})
+	for _, v := range a {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// isOperationApplied returns true if op is reflected in record
+func isOperationApplied(record interface{}, op *Operation) (bool, error) {
+	args, err := toJSONValue(op.Args)
+	if err != nil {
+		return false, err
+	}
+	v := valueAtPath(record, op.Path)
+	switch op.Command {
+	case "set":
+		return reflect.DeepEqual(v, args), nil
+	case "update":
+		m, _ := v.(map[string]interface{})
+		updates, _ := args.(map[string]interface{})
+		for key, want := range updates {
+			if !reflect.DeepEqual(m[key], want) {
+				return false, nil
+			}
+		}
+		return true, nil
+	case "listAfter", "listBefore":
+		return listHasID(v, valueAtPath(args, []string{"id"})), nil
+	case "listRemove":
+		return !listHasID(v, valueAtPath(args, []string{"id"})), nil
+	}
+	return false, fmt.Errorf("can't verify operation with command '%s'", op.Command)
+}
+
+// VerifyTransactionApplied returns true if current values of records
+// changed by ops match the values set by ops, which tells that a
+// transaction with ops was applied.
+//
+// It can be fooled by other changes to the same records, so use it only
+// right after a failed SubmitTransaction. Operations setting values that
+// are changed by the server (e.g. last_edited_time) are never reported
+// as applied after a later edit
+func (c *Client) VerifyTransactionApplied(ops []*Operation) (bool, error) {
+	return c.VerifyTransactionAppliedCtx(context.Background(), ops)
+}
+
+// VerifyTransactionAppliedCtx is like VerifyTransactionApplied but can be
+// canceled with ctx
+func (c *Client) VerifyTransactionAppliedCtx(ctx context.Context, ops []*Operation) (bool, error) {
+	var requests []RecordRequest
+	seen := map[RecordRequest]int{}
+	for _, op := range ops {
+		r := RecordRequest{Table: op.Table, ID: op.ID, SpaceID: op.SpaceID}
+		if _, ok := seen[r]; !ok {
+			seen[r] = len(requests)
+			requests = append(requests, r)
+		}
+	}
+	if len(requests) == 0 {
+		return true, nil
+	}
+	records, err := c.getRecords(ctx, requests)
+	if err != nil {
+		return false, err
+	}
+	values := make([]interface{}, len(records))
+	for i, rec := range records {
+		if len(rec.Value) == 0 {
+			continue
+		}
+		err = json.Unmarshal(rec.Value, &values[i])
+		if err != nil {
+			return false, err
+		}
+	}
+	for _, op := range ops {
+		r := RecordRequest{Table: op.Table, ID: op.ID, SpaceID: op.SpaceID}
+		record := values[seen[r]]
+		if record == nil {
+			// e.g. the record was not created
+			return false, nil
+		}
+		applied, err := isOperationApplied(record, op)
+		if err != nil || !applied {
+			return false, err
+		}
+	}
+	return true, nil
+}
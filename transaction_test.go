@@ -0,0 +1,136 @@
+package notionapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const transactionBlockID = "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"
+
+// transactionTransport stores title of a block set by submitTransaction.
+// The first nFailures submitTransaction requests fail with 502 after
+// applying operations if applyOnFailure is true
+type transactionTransport struct {
+	mu             sync.Mutex
+	title          string
+	nFailures      int
+	applyOnFailure bool
+	requestIDs     []string
+	nSyncRecords   int
+}
+
+func (t *transactionTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	code := 200
+	s := `{}`
+	if strings.HasSuffix(r.URL.Path, "/submitTransaction") {
+		body, _ := readRequestBody(r)
+		var req submitTransactionRequest
+		json.Unmarshal([]byte(body), &req)
+		t.requestIDs = append(t.requestIDs, req.RequestID)
+		fail := t.nFailures > 0
+		if fail {
+			t.nFailures--
+			code = 502
+		}
+		if !fail || t.applyOnFailure {
+			title := req.Operations[0].Args.([]interface{})[0].([]interface{})[0]
+			t.title = title.(string)
+		}
+	} else {
+		t.nSyncRecords++
+		value := map[string]interface{}{
+			"alive": true, "id": transactionBlockID, "type": BlockPage,
+			"properties": map[string]interface{}{"title": [][]string{{t.title}}},
+		}
+		d, _ := json.Marshal(map[string]interface{}{
+			"recordMap": map[string]interface{}{
+				"block": map[string]interface{}{
+					transactionBlockID: map[string]interface{}{"role": RoleEditor, "value": value},
+				},
+			},
+		})
+		s = string(d)
+	}
+	return &http.Response{
+		StatusCode: code,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewBufferString(s)),
+		Request:    r,
+	}, nil
+}
+
+func newTransactionClient(transport *transactionTransport) *Client {
+	return &Client{
+		AuthToken:   "token",
+		HTTPClient:  &http.Client{Transport: transport},
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond},
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+}
+
+func TestSubmitTransactionError(t *testing.T) {
+	transport := &transactionTransport{title: "old", nFailures: 1, applyOnFailure: true}
+	c := newTransactionClient(transport)
+	ops := []*Operation{buildSetTitleOp(transactionBlockID, "new")}
+	err := c.SubmitTransaction(ops)
+	var txErr *TransactionError
+	assert.True(t, errors.As(err, &txErr))
+	assert.Equal(t, []string{txErr.ID}, transport.requestIDs)
+
+	// the transaction was applied so it's not sent again
+	err = c.SubmitTransactionIdempotent(ops, txErr.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(transport.requestIDs))
+	assert.Equal(t, 1, transport.nSyncRecords)
+}
+
+func TestSubmitTransactionIdempotent(t *testing.T) {
+	transport := &transactionTransport{title: "old", nFailures: 1}
+	c := newTransactionClient(transport)
+	ops := []*Operation{buildSetTitleOp(transactionBlockID, "new")}
+	id := NewTransactionID()
+	err := c.SubmitTransactionIdempotent(ops, id)
+	assert.NoError(t, err)
+	// the failed transaction was not applied so it was sent again
+	// with the same id
+	assert.Equal(t, []string{id, id}, transport.requestIDs)
+	assert.Equal(t, "new", transport.title)
+
+	applied, err := c.VerifyTransactionApplied(ops)
+	assert.NoError(t, err)
+	assert.True(t, applied)
+	applied, err = c.VerifyTransactionApplied([]*Operation{buildSetTitleOp(transactionBlockID, "other")})
+	assert.NoError(t, err)
+	assert.False(t, applied)
+}
+
+func TestIsOperationApplied(t *testing.T) {
+	var record interface{}
+	json.Unmarshal([]byte(`{"content":["a","b"],"format":{"page_icon":"x","page_full_width":true}}`), &record)
+	ops := map[*Operation]bool{
+		buildAppendContentOp("p", "b"):                                                              true,
+		buildAppendContentOp("p", "c"):                                                              false,
+		buildSetPageFormat("p", map[string]interface{}{"page_full_width": true}):                    true,
+		buildSetPageFormat("p", map[string]interface{}{"page_icon": "y"}):                           false,
+		{Path: []string{"content"}, Command: "listRemove", Args: map[string]interface{}{"id": "c"}}: true,
+		{Path: []string{"content"}, Command: "set", Args: []string{"a", "b"}}:                       true,
+	}
+	for op, exp := range ops {
+		applied, err := isOperationApplied(record, op)
+		assert.NoError(t, err)
+		assert.Equal(t, exp, applied, "%s %v", op.Command, op.Args)
+	}
+	_, err := isOperationApplied(record, &Operation{Command: "unknown"})
+	assert.Error(t, err)
+}
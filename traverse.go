@@ -0,0 +1,213 @@
+package notionapi
+
+// ForEachBlockOptions describes options for Page.ForEachBlockWithOptions
+type ForEachBlockOptions struct {
+	// if true, rows of tables (CollectionViewInfo.CollectionRows) are
+	// visited after BlockCollectionView and BlockCollectionViewPage
+	// blocks, as their children. Rows are not in Content
+	IncludeTableRows bool
+}
+
+// ForEachBlock calls fn for the root of the page and all blocks in it, in
+// document order
+func (p *Page) ForEachBlock(fn func(b *Block)) {
+	p.ForEachBlockWithOptions(nil, func(b, parent *Block) bool {
+		fn(b)
+		return true
+	})
+}
+
+// ForEachBlockWithParent is like ForEachBlock but fn also gets the parent
+// of the block, nil for the root. If fn returns false, children of the
+// block are skipped
+func (p *Page) ForEachBlockWithParent(fn func(b, parent *Block) bool) {
+	p.ForEachBlockWithOptions(nil, fn)
+}
+
+// ForEachBlockWithOptions is like ForEachBlockWithParent but with options
+func (p *Page) ForEachBlockWithOptions(opts *ForEachBlockOptions, fn func(b, parent *Block) bool) {
+	if opts == nil {
+		opts = &ForEachBlockOptions{}
+	}
+	if p.Root == nil {
+		return
+	}
+	forEachBlock(p.Root, nil, opts, fn)
+}
+
+func forEachBlock(b *Block, parent *Block, opts *ForEachBlockOptions, fn func(b, parent *Block) bool) {
+	if !fn(b, parent) {
+		return
+	}
+	for _, child := range b.Content {
+		if child != nil {
+			forEachBlock(child, b, opts, fn)
+		}
+	}
+	if !opts.IncludeTableRows {
+		return
+	}
+	// the same rows are in every view of the collection
+	seen := map[string]bool{}
+	for _, view := range b.CollectionViews {
+		for _, row := range view.CollectionRows {
+			if row != nil && !seen[row.ID] {
+				seen[row.ID] = true
+				forEachBlock(row, b, opts, fn)
+			}
+		}
+	}
+}
+
+// FindBlocks returns blocks of the page, including the root, for which
+// pred returns true, in document order
+func (p *Page) FindBlocks(pred func(b *Block) bool) []*Block {
+	var res []*Block
+	p.ForEachBlock(func(b *Block) {
+		if pred(b) {
+			res = append(res, b)
+		}
+	})
+	return res
+}
+
+// BlocksOfType returns blocks of the page with one of given types (e.g.
+// BlockImage), in document order
+func (p *Page) BlocksOfType(types ...string) []*Block {
+	return p.FindBlocks(func(b *Block) bool {
+		for _, typ := range types {
+			if b.Type == typ {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// setPositions sets Depth and SiblingIndex of root and blocks in it. A block
+// can be in many places (content of a synced block), we use the first
+func setPositions(root *Block) {
+	setPositionsAt(root, 0, 0)
+}
+
+// setPositionsAt is like setPositions for a block at a given depth and
+// index in its parent
+func setPositionsAt(block *Block, depth int, index int) {
+	seen := map[*Block]bool{}
+	var visit func(b *Block, depth int, index int)
+	visit = func(b *Block, depth int, index int) {
+		if seen[b] {
+			return
+		}
+		seen[b] = true
+		b.Depth = depth
+		b.SiblingIndex = index
+		for i, child := range b.Content {
+			if child != nil {
+				visit(child, depth+1, i)
+			}
+		}
+	}
+	visit(block, depth, index)
+}
+
+// UpdatePositions re-calculates Depth, SiblingIndex and ListIndex of
+// blocks. Call it after changing Content of blocks
+func (p *Page) UpdatePositions() {
+	if p.Root == nil {
+		return
+	}
+	setPositions(p.Root)
+	p.ForEachBlock(func(b *Block) {
+		setListIndexes(b.Content)
+	})
+}
+
+// BlockIterator visits blocks of a page in the same order as
+// Page.ForEachBlock but lets the caller skip children of a block or stop:
+//
+//	it := page.Iterator()
+//	for it.Next() {
+//		if it.Block().Type == BlockPage && it.Depth() > 0 {
+//			it.SkipChildren()
+//		}
+//	}
+type BlockIterator struct {
+	stack []iteratorFrame
+	curr  *Block
+	skip  bool
+	done  bool
+}
+
+// blocks being visited and their parent
+type iteratorFrame struct {
+	parent *Block
+	blocks []*Block
+	next   int
+}
+
+// Iterator returns an iterator over the root of the page and all blocks
+// in it
+func (p *Page) Iterator() *BlockIterator {
+	it := &BlockIterator{}
+	if p.Root != nil {
+		it.stack = append(it.stack, iteratorFrame{blocks: []*Block{p.Root}})
+	}
+	return it
+}
+
+// Next advances to the next block. Returns false if there are no more
+// blocks or Stop was called
+func (it *BlockIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	if it.curr != nil && !it.skip && len(it.curr.Content) > 0 {
+		it.stack = append(it.stack, iteratorFrame{parent: it.curr, blocks: it.curr.Content})
+	}
+	it.curr = nil
+	it.skip = false
+	for len(it.stack) > 0 {
+		top := &it.stack[len(it.stack)-1]
+		if top.next >= len(top.blocks) {
+			it.stack = it.stack[:len(it.stack)-1]
+			continue
+		}
+		b := top.blocks[top.next]
+		top.next++
+		if b != nil {
+			it.curr = b
+			return true
+		}
+	}
+	it.done = true
+	return false
+}
+
+// Block returns the current block
+func (it *BlockIterator) Block() *Block {
+	return it.curr
+}
+
+// Parent returns parent of the current block, nil for the root
+func (it *BlockIterator) Parent() *Block {
+	if len(it.stack) == 0 {
+		return nil
+	}
+	return it.stack[len(it.stack)-1].parent
+}
+
+// Depth returns depth of the current block, 0 for the root
+func (it *BlockIterator) Depth() int {
+	return len(it.stack) - 1
+}
+
+// SkipChildren makes Next skip blocks in Content of the current block
+func (it *BlockIterator) SkipChildren() {
+	it.skip = true
+}
+
+// Stop ends the iteration, the next call to Next returns false
+func (it *BlockIterator) Stop() {
+	it.done = true
+}
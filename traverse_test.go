@@ -0,0 +1,154 @@
+package notionapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTraversePage() *Page {
+	block := func(id string, typ string, children ...*Block) *Block {
+		return &Block{ID: id, Type: typ, Content: children}
+	}
+	rows := []*Block{testRow("row1", ""), testRow("row2", "")}
+	cv := block("cv", BlockCollectionView)
+	cv.CollectionViews = []*CollectionViewInfo{
+		{CollectionRows: rows},
+		// the same rows in another view
+		{CollectionRows: rows},
+	}
+	return testPage(
+		block("text", BlockText, block("nested", BlockText)),
+		block("list", BlockColumnList,
+			block("col1", BlockColumn, block("toggle", BlockToggle, block("in-toggle", BlockText))),
+			block("col2", BlockColumn, block("sub", BlockPage, block("in-sub", BlockText))),
+		),
+		cv,
+	)
+}
+
+func TestForEachBlock(t *testing.T) {
+	page := testTraversePage()
+	var ids []string
+	page.ForEachBlock(func(b *Block) {
+		ids = append(ids, b.ID)
+	})
+	exp := []string{page.Root.ID, "text", "nested", "list", "col1", "toggle", "in-toggle", "col2", "sub", "in-sub", "cv"}
+	assert.Equal(t, exp, ids)
+
+	ids = nil
+	parents := map[string]string{}
+	page.ForEachBlockWithParent(func(b, parent *Block) bool {
+		ids = append(ids, b.ID)
+		if parent != nil {
+			parents[b.ID] = parent.ID
+		}
+		return b.Type != BlockColumnList
+	})
+	exp = []string{page.Root.ID, "text", "nested", "list", "cv"}
+	assert.Equal(t, exp, ids)
+	assert.Equal(t, "text", parents["nested"])
+	assert.Equal(t, page.Root.ID, parents["cv"])
+
+	ids = nil
+	opts := &ForEachBlockOptions{IncludeTableRows: true}
+	page.ForEachBlockWithOptions(opts, func(b, parent *Block) bool {
+		if parent != nil && parent.ID == "cv" {
+			ids = append(ids, b.ID)
+		}
+		return true
+	})
+	assert.Equal(t, []string{"row1", "row2"}, ids)
+}
+
+func TestFindBlocks(t *testing.T) {
+	page := testTraversePage()
+	ids := func(blocks []*Block) []string {
+		var res []string
+		for _, b := range blocks {
+			res = append(res, b.ID)
+		}
+		return res
+	}
+	assert.Equal(t, []string{"text", "nested", "in-toggle", "in-sub"}, ids(page.BlocksOfType(BlockText)))
+	assert.Equal(t, []string{page.Root.ID, "toggle", "sub"}, ids(page.BlocksOfType(BlockPage, BlockToggle)))
+	assert.Nil(t, page.BlocksOfType(BlockImage))
+
+	got := page.FindBlocks(func(b *Block) bool {
+		return len(b.Content) == 1
+	})
+	assert.Equal(t, []string{"text", "col1", "toggle", "col2", "sub"}, ids(got))
+}
+
+func TestBlockPositions(t *testing.T) {
+	idToBlock := map[string]*Block{}
+	add := func(id string, typ string, content ...string) *Block {
+		b := &Block{ID: id, Type: typ, Alive: true, ContentIDs: content}
+		idToBlock[id] = b
+		return b
+	}
+	root := add("root", BlockPage, "text", "list")
+	add("text", BlockText)
+	add("list", BlockColumnList, "col1", "col2")
+	add("col1", BlockColumn)
+	add("col2", BlockColumn, "toggle")
+	add("toggle", BlockToggle, "a", "b")
+	add("a", BlockNumberedList)
+	add("b", BlockNumberedList)
+	err := resolveBlocks(root, idToBlock, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 0, root.Depth)
+	assert.Equal(t, 1, idToBlock["list"].Depth)
+	assert.Equal(t, 1, idToBlock["list"].SiblingIndex)
+	assert.Equal(t, 2, idToBlock["col2"].Depth)
+	assert.Equal(t, 1, idToBlock["col2"].SiblingIndex)
+	assert.Equal(t, 4, idToBlock["b"].Depth)
+	assert.Equal(t, 1, idToBlock["b"].SiblingIndex)
+	assert.Equal(t, 2, idToBlock["b"].ListIndex)
+
+	// move b before a
+	toggle := idToBlock["toggle"]
+	toggle.Content = []*Block{idToBlock["b"], idToBlock["a"], {ID: "c", Type: BlockNumberedList}}
+	page := &Page{ID: "root", Root: root}
+	page.UpdatePositions()
+	assert.Equal(t, 0, idToBlock["b"].SiblingIndex)
+	assert.Equal(t, 1, idToBlock["b"].ListIndex)
+	assert.Equal(t, 1, idToBlock["a"].SiblingIndex)
+	assert.Equal(t, 4, toggle.Content[2].Depth)
+	assert.Equal(t, 3, toggle.Content[2].ListIndex)
+}
+
+func TestBlockIterator(t *testing.T) {
+	page := testTraversePage()
+	var ids []string
+	it := page.Iterator()
+	for it.Next() {
+		b := it.Block()
+		ids = append(ids, fmt.Sprintf("%s:%d", b.ID, it.Depth()))
+		if b.ID == "col1" {
+			assert.Equal(t, "list", it.Parent().ID)
+		}
+		if b.Type == BlockPage && it.Depth() > 0 {
+			it.SkipChildren()
+		}
+	}
+	exp := []string{page.Root.ID + ":0", "text:1", "nested:2", "list:1", "col1:2", "toggle:3", "in-toggle:4", "col2:2", "sub:3", "cv:1"}
+	assert.Equal(t, exp, ids)
+	assert.False(t, it.Next())
+
+	it = page.Iterator()
+	var found *Block
+	for it.Next() {
+		if it.Block().Type == BlockToggle {
+			found = it.Block()
+			it.Stop()
+		}
+	}
+	assert.Equal(t, "toggle", found.ID)
+	assert.False(t, it.Next())
+
+	it = (&Page{}).Iterator()
+	assert.False(t, it.Next())
+}
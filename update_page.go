@@ -0,0 +1,134 @@
+package notionapi
+
+import (
+	"context"
+	"sort"
+)
+
+// UpdatePage updates a page downloaded with DownloadPage to the current
+// version by re-downloading only blocks whose version changed, and new
+// blocks they refer to. Blocks are changed in place so existing pointers
+// to them stay valid. Returns ids of changed blocks, in document order,
+// followed by ids of new blocks. Rows of tables, discussions and users
+// are not updated, use DownloadPage for that
+func (c *Client) UpdatePage(page *Page) ([]string, error) {
+	return c.UpdatePageCtx(context.Background(), page)
+}
+
+// UpdatePageCtx is like UpdatePage but can be canceled with ctx
+func (c *Client) UpdatePageCtx(ctx context.Context, page *Page) ([]string, error) {
+	if page.blocks == nil {
+		page.setBlockIndex(nil)
+	}
+	depth := map[string]int{}
+	var ids []string
+	page.ForEachBlockWithOptions(nil, func(b, parent *Block) bool {
+		if _, ok := depth[b.ID]; !ok {
+			depth[b.ID] = b.Depth
+			ids = append(ids, b.ID)
+		}
+		return true
+	})
+	versions, err := c.GetBlockVersionsCtx(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	idToBlock := map[string]*Block{}
+	for id, b := range page.blocks {
+		idToBlock[id] = b
+	}
+	var changed []string
+	for _, id := range ids {
+		v, ok := versions[id]
+		if !ok {
+			// deleted blocks are removed when we update their parent
+			delete(idToBlock, mustNormalizeID(id))
+			continue
+		}
+		if v != idToBlock[mustNormalizeID(id)].Version {
+			changed = append(changed, id)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	// download changed blocks and blocks newly added to them
+	fresh := map[string]*Block{}
+	var added []string
+	toGet := append([]string{}, changed...)
+	for len(toGet) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// limit the size of a single request
+		n := len(toGet)
+		if n > 100 {
+			n = 100
+		}
+		rsp, err := c.GetBlockRecordsCtx(ctx, toGet[:n])
+		if err != nil {
+			return nil, err
+		}
+		var next []string
+		for _, r := range rsp.Results {
+			if r == nil || r.Value == nil || !r.Value.Alive {
+				continue
+			}
+			b := r.Value
+			fresh[b.ID] = b
+			for _, id := range b.ContentIDs {
+				if idToBlock[id] == nil && fresh[id] == nil && !containsString(next, id) && !containsString(toGet, id) {
+					next = append(next, id)
+					added = append(added, id)
+				}
+			}
+		}
+		toGet = append(toGet[n:], next...)
+	}
+
+	for id, b := range fresh {
+		old := idToBlock[id]
+		if old == nil {
+			idToBlock[id] = b
+			continue
+		}
+		// values set from the parent or not from the block record
+		b.ListIndex = old.ListIndex
+		b.ListNesting = old.ListNesting
+		b.EffectiveRatio = old.EffectiveRatio
+		b.CollectionViews = old.CollectionViews
+		if b.AliasPageID == "" {
+			b.AliasPageID = old.AliasPageID
+		}
+		*old = *b
+	}
+	// resolve parents before their children so that values derived from
+	// the parent are correct
+	sort.SliceStable(changed, func(i, j int) bool {
+		return depth[changed[i]] < depth[changed[j]]
+	})
+	for _, id := range changed {
+		b := idToBlock[id]
+		if err := resolveBlocksRec(b, idToBlock, &page.Warnings, map[string]bool{}); err != nil {
+			return nil, err
+		}
+	}
+	setParents(page.Root, idToBlock)
+	setFactoryIDs(idToBlock)
+	setPositions(page.Root)
+	page.setBlockIndex(idToBlock)
+
+	var res []string
+	for _, id := range ids {
+		if fresh[id] != nil {
+			res = append(res, id)
+		}
+	}
+	for _, id := range added {
+		if fresh[id] != nil {
+			res = append(res, id)
+		}
+	}
+	return res, nil
+}
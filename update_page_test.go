@@ -0,0 +1,125 @@
+package notionapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	updatePageID = "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"
+	updateTextID = "00000000-0000-0000-0000-000000000001"
+	updateListID = "00000000-0000-0000-0000-000000000002"
+	updateNewID  = "00000000-0000-0000-0000-000000000003"
+	updateSubID  = "00000000-0000-0000-0000-000000000004"
+)
+
+// updateTransport serves all blocks in blocks for every request
+type updateTransport struct {
+	mu     sync.Mutex
+	blocks map[string]map[string]interface{}
+}
+
+func (t *updateTransport) setBlock(id string, v map[string]interface{}) {
+	t.mu.Lock()
+	t.blocks[id] = v
+	t.mu.Unlock()
+}
+
+func (t *updateTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	blocks := map[string]interface{}{}
+	for id, v := range t.blocks {
+		blocks[id] = map[string]interface{}{"role": RoleReader, "value": v}
+	}
+	t.mu.Unlock()
+	d, _ := json.Marshal(map[string]interface{}{
+		"recordMap": map[string]interface{}{"block": blocks},
+		"cursor":    map[string]interface{}{"stack": []interface{}{}},
+	})
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewReader(d)),
+		Request:    r,
+	}, nil
+}
+
+func updateBlock(id string, typ string, version int64, parentID string, title string, content ...string) map[string]interface{} {
+	res := map[string]interface{}{
+		"alive": true, "id": id, "type": typ, "version": version, "content": content,
+		"parent_id": parentID, "parent_table": TableBlock,
+		"properties": map[string]interface{}{
+			"title": []interface{}{[]interface{}{title}},
+		},
+	}
+	return res
+}
+
+func TestUpdatePage(t *testing.T) {
+	transport := &updateTransport{blocks: map[string]map[string]interface{}{
+		updatePageID: updateBlock(updatePageID, BlockPage, 1, "", "Page", updateTextID, updateListID),
+		updateTextID: updateBlock(updateTextID, BlockText, 1, updatePageID, "one"),
+		updateListID: updateBlock(updateListID, BlockNumberedList, 1, updatePageID, "item"),
+	}}
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+	page, err := c.DownloadPage(updatePageID)
+	assert.NoError(t, err)
+	text := page.BlockByID(updateTextID)
+	list := page.BlockByID(updateListID)
+	assert.Equal(t, 1, list.ListIndex)
+
+	// nothing changed
+	changed, err := c.UpdatePage(page)
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+
+	transport.setBlock(updateTextID, updateBlock(updateTextID, BlockText, 2, updatePageID, "uno"))
+	transport.setBlock(updatePageID, updateBlock(updatePageID, BlockPage, 2, "", "Page", updateTextID, updateListID, updateNewID))
+	transport.setBlock(updateNewID, updateBlock(updateNewID, BlockToggle, 1, updatePageID, "new", updateSubID))
+	transport.setBlock(updateSubID, updateBlock(updateSubID, BlockText, 1, updateNewID, "sub"))
+	changed, err = c.UpdatePage(page)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{updatePageID, updateTextID, updateNewID, updateSubID}, changed)
+
+	// existing blocks are updated in place
+	assert.Equal(t, text, page.BlockByID(updateTextID))
+	assert.Equal(t, "uno", text.InlineContent[0].Text)
+	assert.Equal(t, int64(2), page.Root.Version)
+	assert.Equal(t, 3, len(page.Root.Content))
+	assert.Equal(t, text, page.Root.Content[0])
+	assert.Equal(t, list, page.Root.Content[1])
+	assert.Equal(t, 1, list.ListIndex)
+
+	added := page.BlockByID(updateNewID)
+	sub := page.BlockByID(updateSubID)
+	if assert.NotNil(t, added) && assert.NotNil(t, sub) {
+		assert.Equal(t, page.Root, added.Parent)
+		assert.Equal(t, added, sub.Parent)
+		assert.Equal(t, []*Block{sub}, added.Content)
+		assert.Equal(t, 2, sub.Depth)
+		assert.Equal(t, 2, added.SiblingIndex)
+	}
+	assert.Empty(t, page.OrphanBlocks())
+
+	// deleted blocks are removed
+	deleted := updateBlock(updateListID, BlockNumberedList, 2, updatePageID, "item")
+	deleted["alive"] = false
+	transport.setBlock(updateListID, deleted)
+	transport.setBlock(updatePageID, updateBlock(updatePageID, BlockPage, 3, "", "Page", updateTextID, updateNewID))
+	changed, err = c.UpdatePage(page)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{updatePageID}, changed)
+	assert.Equal(t, []*Block{text, added}, page.Root.Content)
+	assert.Equal(t, 1, added.SiblingIndex)
+	assert.Nil(t, page.BlockByID(updateListID))
+	assert.Empty(t, page.OrphanBlocks())
+}
@@ -0,0 +1,279 @@
+package notionapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// creatorID returns id of the user who created the block
+func (b *Block) creatorID() string {
+	if b.CreatedByID != "" {
+		return b.CreatedByID
+	}
+	return b.CreatedBy
+}
+
+// lastEditorID returns id of the user who last edited the block
+func (b *Block) lastEditorID() string {
+	if b.LastEditedByID != "" {
+		return b.LastEditedByID
+	}
+	return b.LastEditedBy
+}
+
+// findUser returns user with a given id from p.Users, nil if not there
+func (p *Page) findUser(id string) *User {
+	if id == "" {
+		return nil
+	}
+	for _, u := range p.Users {
+		if SameID(u.ID, id) {
+			return u
+		}
+	}
+	return nil
+}
+
+// CreatedByUser returns the user who created the block, nil if
+// it's not in p.Users (see Page.FetchMissingUsers)
+func (b *Block) CreatedByUser(p *Page) *User {
+	return p.findUser(b.creatorID())
+}
+
+// LastEditedByUser returns the user who last edited the block, nil if
+// it's not in p.Users (see Page.FetchMissingUsers)
+func (b *Block) LastEditedByUser(p *Page) *User {
+	return p.findUser(b.lastEditorID())
+}
+
+// GetUsers returns users with given ids. A user is nil if it
+// doesn't exist or we don't have access to it
+func (c *Client) GetUsers(ids []string) ([]*User, error) {
+	return c.GetUsersCtx(context.Background(), ids)
+}
+
+// GetUsersCtx is like GetUsers but can be canceled with ctx
+func (c *Client) GetUsersCtx(ctx context.Context, ids []string) ([]*User, error) {
+	var requests []RecordRequest
+	for _, id := range ids {
+		requests = append(requests, RecordRequest{Table: TableUser, ID: id})
+	}
+	rsp, err := c.GetRecordValuesCtx(ctx, requests)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*User, len(ids))
+	for i, rv := range rsp.Results {
+		res[i] = rv.User
+	}
+	return res, nil
+}
+
+// userTable returns table of a user record for created_by_table etc.
+// Older records don't have it and only refer to users
+func userTable(table string) string {
+	if table == TableBot {
+		return TableBot
+	}
+	return TableUser
+}
+
+// referencedUsers returns users and bots that blocks of the page and rows
+// of its tables refer to: creators, last editors, mentioned users and
+// users in person columns, as well as authors of comments
+func (p *Page) referencedUsers() []RecordRequest {
+	seen := map[string]bool{}
+	var res []RecordRequest
+	add := func(id string, table string) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			res = append(res, RecordRequest{Table: userTable(table), ID: id})
+		}
+	}
+	visit := func(b *Block) {
+		add(b.creatorID(), b.CreatedByTable)
+		add(b.lastEditorID(), b.LastEditedByTable)
+		forEachInlineBlock(b, func(ib *InlineBlock) bool {
+			add(ib.UserID, TableUser)
+			return true
+		})
+	}
+	p.ForEachBlock(visit)
+	for _, ref := range p.RowPages() {
+		visit(ref.Block)
+	}
+	for _, d := range p.Discussions {
+		for _, c := range d.Comments {
+			add(c.CreatedByID, c.CreatedByTable)
+		}
+	}
+	return res
+}
+
+// FetchMissingUsers adds to Users users that the page refers to (creators
+// and last editors of blocks, mentioned users, person columns of rows and
+// authors of comments) but DownloadPage didn't get. They're requested in
+// a single batch. Bots are included, with User.IsBot set
+func (p *Page) FetchMissingUsers() error {
+	return p.FetchMissingUsersCtx(context.Background())
+}
+
+// FetchMissingUsersCtx is like FetchMissingUsers but can be canceled with ctx
+func (p *Page) FetchMissingUsersCtx(ctx context.Context) error {
+	if p.client == nil {
+		return ErrOfflinePage
+	}
+	var missing []RecordRequest
+	for _, r := range p.referencedUsers() {
+		if p.findUser(r.ID) != nil {
+			continue
+		}
+		if u := p.client.RecordCache.user(r.ID, r.Table); u != nil {
+			p.Users = append(p.Users, u)
+			continue
+		}
+		missing = append(missing, r)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	rsp, err := p.client.GetRecordValuesCtx(ctx, missing)
+	if err != nil {
+		return err
+	}
+	for _, rv := range rsp.Results {
+		if rv.User != nil {
+			p.Users = append(p.Users, rv.User)
+		}
+	}
+	return nil
+}
+
+// /api/v3/findUser request
+type findUserRequest struct {
+	Email string `json:"email"`
+}
+
+type findUserResponse struct {
+	Value *struct {
+		Role  string `json:"role"`
+		Value *User  `json:"value"`
+	} `json:"value"`
+}
+
+// FindUserByEmail returns a Notion user with a given email. Returns nil
+// user (and no error) if there's no user with this email
+func (c *Client) FindUserByEmail(email string) (*User, error) {
+	return c.FindUserByEmailCtx(context.Background(), email)
+}
+
+// FindUserByEmailCtx is like FindUserByEmail but can be canceled with ctx
+func (c *Client) FindUserByEmailCtx(ctx context.Context, email string) (*User, error) {
+	req := &findUserRequest{
+		Email: email,
+	}
+	var rsp findUserResponse
+	err := doNotionAPI(ctx, c, "/api/v3/findUser", req, &rsp)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.Value == nil {
+		return nil, nil
+	}
+	return rsp.Value.Value, nil
+}
+
+// /api/v3/createEmailUser request
+type createEmailUserRequest struct {
+	Email           string `json:"email"`
+	PreferredLocale string `json:"preferredLocale"`
+}
+
+type createEmailUserResponse struct {
+	UserID string `json:"userId"`
+}
+
+// createEmailUser creates a placeholder user for an email that doesn't
+// have a Notion account (Notion sends an invitation email to it) and
+// returns its id. This is what the web client does when inviting an
+// unknown email
+func (c *Client) createEmailUser(ctx context.Context, email string) (string, error) {
+	req := &createEmailUserRequest{
+		Email:           email,
+		PreferredLocale: "en-US",
+	}
+	var rsp createEmailUserResponse
+	err := doNotionAPI(ctx, c, "/api/v3/createEmailUser", req, &rsp)
+	if err != nil {
+		return "", err
+	}
+	if rsp.UserID == "" {
+		return "", fmt.Errorf("createEmailUser didn't return id of the user for '%s'", email)
+	}
+	return rsp.UserID, nil
+}
+
+// FullName returns the name of the user as shown by Notion: given and
+// family name or, if not known, Name or Email
+func (u *User) FullName() string {
+	name := strings.TrimSpace(u.GivenName + " " + u.FamilyName)
+	if name != "" {
+		return name
+	}
+	if name = strings.TrimSpace(u.Name); name != "" {
+		return name
+	}
+	return u.Email
+}
+
+// ProfilePhotoURL returns url of user's photo that can be downloaded,
+// "" if the user doesn't have one. Like images in pages, it goes through
+// the Notion image proxy (see makeImageURL)
+func (u *User) ProfilePhotoURL() string {
+	return makeImageURL(u.ProfilePhoto)
+}
+
+// user returns user (or bot, depending on table) with a given id from
+// Users or, if it's not there and the page was downloaded, gets it from the
+// server and adds it to Users. Returns nil if the user can't be found
+func (p *Page) user(id string, table string) *User {
+	if u := p.findUser(id); u != nil || id == "" || p.client == nil {
+		return u
+	}
+	if u := p.client.RecordCache.user(id, table); u != nil {
+		p.Users = append(p.Users, u)
+		return u
+	}
+	rsp, err := p.client.GetRecordValues([]RecordRequest{{Table: userTable(table), ID: id}})
+	if err != nil || rsp.Results[0].User == nil {
+		return nil
+	}
+	u := rsp.Results[0].User
+	p.Users = append(p.Users, u)
+	return u
+}
+
+// CreatedBy returns the user who created the page, nil if not known.
+// If the user is not in Users, we try to get it from the server
+func (p *Page) CreatedBy() *User {
+	return p.user(p.Root.creatorID(), p.Root.CreatedByTable)
+}
+
+// LastEditedBy returns the user who last edited the page, nil if not
+// known. If the user is not in Users, we try to get it from the server
+func (p *Page) LastEditedBy() *User {
+	return p.user(p.Root.lastEditorID(), p.Root.LastEditedByTable)
+}
+
+// CreatedOn returns the time the page was created, zero time if not known
+func (p *Page) CreatedOn() time.Time {
+	return p.Root.CreatedOn()
+}
+
+// LastEditedOn returns the time the page was last edited, zero time if
+// not known
+func (p *Page) LastEditedOn() time.Time {
+	return p.Root.UpdatedOn()
+}
@@ -0,0 +1,214 @@
+package notionapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockUsers(t *testing.T) {
+	c, transport := newStaticClient(200, `{
+	"recordMap": {
+		"notion_user": {
+			"bb760e2d-d679-4b64-b2a9-03005b21870a": {
+				"role": "reader",
+				"value": { "id": "bb760e2d-d679-4b64-b2a9-03005b21870a", "given_name": "Jane" }
+			}
+		}
+	}
+}`)
+	var block Block
+	err := json.Unmarshal([]byte(`{
+	"id": "1",
+	"type": "text",
+	"created_by": "old-id",
+	"created_by_id": "bb760e2d-d679-4b64-b2a9-03005b21870a",
+	"last_edited_by": "bb760e2d-d679-4b64-b2a9-03005b21870a"
+}`), &block)
+	assert.NoError(t, err)
+	page := testPage(&block)
+	page.client = c
+	assert.Nil(t, block.CreatedByUser(page))
+
+	err = page.FetchMissingUsers()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(transport.requests))
+	assert.Equal(t, "Jane", block.CreatedByUser(page).GivenName)
+	// older records only have last_edited_by
+	assert.Equal(t, "Jane", block.LastEditedByUser(page).GivenName)
+
+	// all users are known so we don't ask again
+	err = page.FetchMissingUsers()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(transport.requests))
+	assert.True(t, strings.Contains(transport.requests[0], TableUser))
+}
+
+func TestReferencedUsers(t *testing.T) {
+	mention := &Block{ID: "mention", Type: BlockText, CreatedByID: "creator", InlineContent: []*InlineBlock{
+		{Text: "cc "}, {Text: InlineAt, UserID: "mentioned"},
+	}}
+	page := testPage(mention)
+	page.Root.CreatedByID = "creator"
+	row := testRow("row", "")
+	row.Properties["person"] = []interface{}{[]interface{}{InlineAt, []interface{}{[]interface{}{"u", "assignee"}}}}
+	page.Tables = []*Table{{Data: []*Block{row}}}
+	page.Discussions = []*Discussion{{Comments: []*Comment{
+		{CreatedByID: "commenter", CreatedByTable: TableUser},
+		{CreatedByID: "bot", CreatedByTable: "bot"},
+	}}}
+	exp := []RecordRequest{
+		{Table: TableUser, ID: "creator"},
+		{Table: TableUser, ID: "mentioned"},
+		{Table: TableUser, ID: "assignee"},
+		{Table: TableUser, ID: "commenter"},
+		{Table: TableBot, ID: "bot"},
+	}
+	assert.Equal(t, exp, page.referencedUsers())
+
+	c, transport := newStaticClient(200, `{"recordMap": {}}`)
+	page.client = c
+	page.Users = []*User{{ID: "creator"}}
+	err := page.FetchMissingUsers()
+	assert.NoError(t, err)
+	// all missing users are requested at once
+	assert.Equal(t, 1, len(transport.requests))
+	body := transport.requests[0]
+	assert.False(t, strings.Contains(body, `"creator"`), body)
+	assert.True(t, strings.Contains(body, `"assignee"`), body)
+}
+
+func TestUserRecord(t *testing.T) {
+	var human User
+	err := json.Unmarshal([]byte(`{
+	"id": "bb760e2d-d679-4b64-b2a9-03005b21870a",
+	"version": 12,
+	"email": "jane@example.com",
+	"given_name": "Jane",
+	"family_name": "Doe",
+	"profile_photo": "https://s3-us-west-2.amazonaws.com/public.notion-static.com/1f6c2c75/jane.png",
+	"onboarding_completed": true,
+	"mobile_onboarding_completed": false,
+	"clipper_onboarding_completed": true
+}`), &human)
+	assert.NoError(t, err)
+	assert.Equal(t, "Jane Doe", human.FullName())
+	assert.Equal(t, "jane@example.com", human.Email)
+	assert.True(t, human.OnboardingCompleted)
+	assert.True(t, human.ClipperOnboardingCompleted)
+	assert.Equal(t, "https://www.notion.so/image/https:%2F%2Fs3-us-west-2.amazonaws.com%2Fpublic.notion-static.com%2F1f6c2c75%2Fjane.png", human.ProfilePhotoURL())
+
+	var bot User
+	err = json.Unmarshal([]byte(`{
+	"id": "5a7e0e2c-12a4-4c5e-9a43-1f2b0b6b3a11",
+	"version": 1,
+	"name": "GitHub Sync",
+	"profile_photo": ""
+}`), &bot)
+	assert.NoError(t, err)
+	assert.Equal(t, "GitHub Sync", bot.FullName())
+	assert.Equal(t, "", bot.ProfilePhotoURL())
+
+	assert.Equal(t, "only@example.com", (&User{Email: "only@example.com"}).FullName())
+	assert.Equal(t, "Jane", (&User{GivenName: "Jane"}).FullName())
+}
+
+func TestPageAuthors(t *testing.T) {
+	c, transport := newStaticClient(200, `{
+	"recordMap": {
+		"notion_user": {
+			"bb760e2d-d679-4b64-b2a9-03005b21870a": {
+				"role": "reader",
+				"value": { "id": "bb760e2d-d679-4b64-b2a9-03005b21870a", "given_name": "Jane" }
+			}
+		}
+	}
+}`)
+	page := testPage()
+	page.Root.CreatedByID = "creator"
+	page.Root.LastEditedByID = "bb760e2d-d679-4b64-b2a9-03005b21870a"
+	page.Root.CreatedTime = 1546300800000
+	page.Root.LastEditedTime = 1546304400000
+	page.Users = []*User{{ID: "creator", GivenName: "Creator"}}
+	// offline pages only use Users
+	assert.Equal(t, "Creator", page.CreatedBy().FullName())
+	assert.Nil(t, page.LastEditedBy())
+
+	page.client = c
+	assert.Equal(t, "Jane", page.LastEditedBy().FullName())
+	assert.Equal(t, 2, len(page.Users))
+	assert.Equal(t, 1, len(transport.requests))
+	// the fetched user is remembered
+	assert.Equal(t, "Jane", page.LastEditedBy().FullName())
+	assert.Equal(t, 1, len(transport.requests))
+
+	assert.Equal(t, int64(1546300800), page.CreatedOn().Unix())
+	assert.Equal(t, int64(1546304400), page.LastEditedOn().Unix())
+	assert.True(t, testPage().CreatedOn().IsZero())
+}
+
+func TestBotUsers(t *testing.T) {
+	// a page edited by an integration
+	rsp := &LoadPageChunkResponse{}
+	err := json.Unmarshal([]byte(`{
+	"recordMap": {
+		"bot": {
+			"8d5c5d2b-6a41-4c0f-9a0e-3c3c3b2d2a01": {
+				"role": "reader",
+				"value": {
+					"id": "8d5c5d2b-6a41-4c0f-9a0e-3c3c3b2d2a01",
+					"version": 3,
+					"name": "GitHub Sync",
+					"type": "integration",
+					"parent_table": "space",
+					"parent_id": "bc202e06-6caa-4e3f-81eb-f226ab5deef7"
+				}
+			}
+		}
+	}
+}`), rsp)
+	assert.NoError(t, err)
+	bot := rsp.RecordMap.Bots["8d5c5d2b-6a41-4c0f-9a0e-3c3c3b2d2a01"].Value
+	assert.Equal(t, "GitHub Sync", bot.FullName())
+
+	c, transport := newStaticClient(200, `{
+	"recordMap": {
+		"bot": {
+			"8d5c5d2b-6a41-4c0f-9a0e-3c3c3b2d2a01": {
+				"role": "reader",
+				"value": { "id": "8d5c5d2b-6a41-4c0f-9a0e-3c3c3b2d2a01", "name": "GitHub Sync" }
+			}
+		}
+	}
+}`)
+	var block Block
+	err = json.Unmarshal([]byte(`{
+	"id": "1",
+	"type": "text",
+	"created_by_id": "8d5c5d2b-6a41-4c0f-9a0e-3c3c3b2d2a01",
+	"created_by_table": "bot",
+	"last_edited_by_id": "unknown-bot",
+	"last_edited_by_table": "bot"
+}`), &block)
+	assert.NoError(t, err)
+	page := testPage(&block)
+	page.Root.CreatedByID = block.CreatedByID
+	page.Root.CreatedByTable = TableBot
+	assert.Nil(t, block.CreatedByUser(page))
+	assert.Nil(t, block.LastEditedByUser(page))
+
+	page.client = c
+	created := page.CreatedBy()
+	assert.True(t, created.IsBot)
+	assert.Equal(t, "GitHub Sync", created.FullName())
+	assert.True(t, strings.Contains(transport.requests[0], `"table":"bot"`), transport.requests[0])
+
+	err = page.FetchMissingUsers()
+	assert.NoError(t, err)
+	assert.True(t, block.CreatedByUser(page).IsBot)
+	// bots the server doesn't return don't break lookups
+	assert.Nil(t, block.LastEditedByUser(page))
+	assert.Equal(t, 1, len(page.Users))
+}
@@ -0,0 +1,194 @@
+package notionapi
+
+import (
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+const (
+	// LinkNotInSet is BrokenLink.Reason of a link to a page that is not
+	// one of the validated pages
+	LinkNotInSet = "not in set"
+	// LinkNotAlive is BrokenLink.Reason of a link to a page or a block
+	// that is deleted (e.g. in trash)
+	LinkNotAlive = "not alive"
+	// LinkMalformedID is BrokenLink.Reason of a link with an invalid id
+	LinkMalformedID = "malformed id"
+)
+
+// BrokenLink describes a link to a Notion page or block that would be
+// broken after exporting a set of pages, see ValidateLinks
+type BrokenLink struct {
+	// id of the page that has the link
+	PageID string
+	// id of the block that has the link
+	BlockID string
+	// id of the target page or, for inline links, the url
+	Target string
+	// LinkNotInSet, LinkNotAlive or LinkMalformedID
+	Reason string
+}
+
+func isNotionHost(host string) bool {
+	return host == "" || host == "notion.so" || host == "www.notion.so" || strings.HasSuffix(host, ".notion.site")
+}
+
+// notionLinkTarget returns id of a page and a block (from the fragment)
+// that uri links to. isNotion is false if it's not a link to Notion.
+// pageID is "" for links to a block in the same page
+func notionLinkTarget(uri string) (pageID string, blockID string, isNotion bool) {
+	u, err := url.Parse(uri)
+	if err != nil || (u.Scheme != "" && u.Scheme != "http" && u.Scheme != "https") || !isNotionHost(u.Host) {
+		return "", "", false
+	}
+	if u.Host == "" && !strings.HasPrefix(u.Path, "/") && u.Path != "" {
+		// relative link to a file, not a page
+		return "", "", false
+	}
+	if u.Host == "" && u.Path == "" && u.Fragment == "" {
+		return "", "", false
+	}
+	blockID = u.Fragment
+	if p := u.Query().Get("p"); p != "" {
+		// a page opened as a peek, in another page
+		return p, blockID, true
+	}
+	last := path.Base(u.Path)
+	if u.Path == "" || last == "/" {
+		return "", blockID, true
+	}
+	if len(last) > 32 && !isValidID(last) {
+		// Title-c969c9455d7c4dd79c7f860f3ace6429
+		last = last[len(last)-32:]
+	}
+	return last, blockID, true
+}
+
+type linkValidator struct {
+	// normalized ids of validated pages
+	pages map[string]*Page
+	// blocks of all pages, by normalized id
+	blocks map[string]*Block
+	res    []BrokenLink
+}
+
+func (v *linkValidator) add(page *Page, b *Block, target string, reason string) {
+	link := BrokenLink{
+		PageID:  page.ID,
+		BlockID: b.ID,
+		Target:  target,
+		Reason:  reason,
+	}
+	v.res = append(v.res, link)
+}
+
+// checkPage checks a link to a page with a given id. target is what we
+// report, the id or the url. Returns false if the link is broken
+func (v *linkValidator) checkPage(page *Page, b *Block, pageID string, target string) bool {
+	if !isValidID(pageID) {
+		v.add(page, b, target, LinkMalformedID)
+		return false
+	}
+	id := mustNormalizeID(pageID)
+	if linked := v.blocks[id]; linked != nil && !linked.Alive {
+		v.add(page, b, target, LinkNotAlive)
+		return false
+	}
+	if v.pages[id] == nil {
+		v.add(page, b, target, LinkNotInSet)
+		return false
+	}
+	return true
+}
+
+func (v *linkValidator) checkLink(page *Page, b *Block, uri string) {
+	pageID, blockID, isNotion := notionLinkTarget(uri)
+	if !isNotion {
+		return
+	}
+	if pageID != "" && !v.checkPage(page, b, pageID, uri) {
+		return
+	}
+	if blockID == "" {
+		return
+	}
+	if !isValidID(blockID) {
+		v.add(page, b, uri, LinkMalformedID)
+		return
+	}
+	linked := v.blocks[mustNormalizeID(blockID)]
+	switch {
+	case linked == nil:
+		v.add(page, b, uri, LinkNotInSet)
+	case !linked.Alive:
+		v.add(page, b, uri, LinkNotAlive)
+	}
+}
+
+func (v *linkValidator) checkInline(page *Page, b *Block, blocks []*InlineBlock) {
+	for _, ib := range blocks {
+		if ib.PageID != "" {
+			v.checkPage(page, b, ib.PageID, ib.PageID)
+		}
+		if ib.Link != "" {
+			v.checkLink(page, b, ib.Link)
+		}
+	}
+}
+
+func (v *linkValidator) checkBlock(page *Page, b *Block) {
+	if len(b.Properties) > 0 {
+		var names []string
+		for name := range b.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			v.checkInline(page, b, getInlineProp(b, name))
+		}
+	} else {
+		v.checkInline(page, b, b.InlineContent)
+	}
+	if b.Type == BlockAlias {
+		v.checkPage(page, b, b.AliasPageID, b.AliasPageID)
+	}
+}
+
+// ValidateLinks checks links between pages that are exported together.
+// It reports page mentions, links to pages (BlockAlias) and inline links
+// to Notion pages or blocks that point to a page that is not one of the
+// pages, to a deleted page or block, or have a malformed id
+func ValidateLinks(pages []*Page) []BrokenLink {
+	v := &linkValidator{
+		pages:  map[string]*Page{},
+		blocks: map[string]*Block{},
+	}
+	for _, page := range pages {
+		if page == nil || page.Root == nil {
+			continue
+		}
+		v.pages[mustNormalizeID(page.Root.ID)] = page
+		page.ForEachBlock(func(b *Block) {
+			if isValidID(b.ID) {
+				id := mustNormalizeID(b.ID)
+				// a sub-page block is less complete than the page
+				if v.blocks[id] == nil || b == page.Root {
+					v.blocks[id] = b
+				}
+			}
+		})
+	}
+	for _, page := range pages {
+		if page == nil || page.Root == nil {
+			continue
+		}
+		page.ForEachBlockWithParent(func(b, parent *Block) bool {
+			v.checkBlock(page, b)
+			// content of sub-pages is checked as part of their pages
+			return parent == nil || b.Type != BlockPage
+		})
+	}
+	return v.res
+}
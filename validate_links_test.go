@@ -0,0 +1,78 @@
+package notionapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotionLinkTarget(t *testing.T) {
+	tests := []struct {
+		uri      string
+		pageID   string
+		blockID  string
+		isNotion bool
+	}{
+		{"https://www.notion.so/Title-c969c9455d7c4dd79c7f860f3ace6429", "c969c9455d7c4dd79c7f860f3ace6429", "", true},
+		{"/c969c9455d7c4dd79c7f860f3ace6429#0367c2db381a4f8b9ce360f388a6b2e3", "c969c9455d7c4dd79c7f860f3ace6429", "0367c2db381a4f8b9ce360f388a6b2e3", true},
+		{"https://kjk.notion.site/abc?p=4c6a54c68b3e4ea2af9cfaabcc88d58d", "4c6a54c68b3e4ea2af9cfaabcc88d58d", "", true},
+		{"#0367c2db381a4f8b9ce360f388a6b2e3", "", "0367c2db381a4f8b9ce360f388a6b2e3", true},
+		{"/c969c945-5d7c-4dd7-9c7f-860f3ace6429", "c969c945-5d7c-4dd7-9c7f-860f3ace6429", "", true},
+		{"https://www.notion.so/broken", "broken", "", true},
+		{"https://example.com/c969c9455d7c4dd79c7f860f3ace6429", "", "", false},
+		{"mailto:me@example.com", "", "", false},
+		{"file.pdf", "", "", false},
+	}
+	for _, test := range tests {
+		pageID, blockID, isNotion := notionLinkTarget(test.uri)
+		assert.Equal(t, test.pageID, pageID, test.uri)
+		assert.Equal(t, test.blockID, blockID, test.uri)
+		assert.Equal(t, test.isNotion, isNotion, test.uri)
+	}
+}
+
+func TestValidateLinks(t *testing.T) {
+	const (
+		page1ID   = "c969c945-5d7c-4dd7-9c7f-860f3ace6429"
+		page2ID   = "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"
+		missingID = "0367c2db-381a-4f8b-9ce3-60f388a6b2e3"
+		trashedID = "2131b10c-ebf6-4938-a127-7089ff02dbe4"
+	)
+	link := func(id string, uri string) *Block {
+		return &Block{ID: id, Type: BlockText, Alive: true, InlineContent: []*InlineBlock{{Text: "link", Link: uri}}}
+	}
+	mention := &Block{ID: "mention", Type: BlockText, Alive: true, InlineContent: []*InlineBlock{
+		{Text: InlineAt, PageID: page2ID},
+		{Text: InlineAt, PageID: missingID},
+	}}
+	trashed := &Block{ID: trashedID, Type: BlockPage, Alive: false}
+	page1 := testPage(
+		mention,
+		&Block{ID: "alias", Type: BlockAlias, Alive: true, AliasPageID: trashedID},
+		&Block{ID: "bad-alias", Type: BlockAlias, Alive: true, AliasPageID: "xyz"},
+		link("ok", "https://www.notion.so/Page-4c6a54c68b3e4ea2af9cfaabcc88d58d"),
+		link("external", "https://example.com"),
+		link("missing-block", "/4c6a54c68b3e4ea2af9cfaabcc88d58d#c969c9455d7c4dd79c7f860f3ace6420"),
+		link("malformed", "https://www.notion.so/broken"),
+		trashed,
+	)
+	page1.Root.Alive = true
+	page2 := &Page{
+		ID:   page2ID,
+		Root: &Block{ID: page2ID, Type: BlockPage, Alive: true, Content: []*Block{link("back", "/"+page1ID)}},
+	}
+
+	got := ValidateLinks([]*Page{page1, page2})
+	exp := []BrokenLink{
+		{PageID: page1ID, BlockID: "mention", Target: missingID, Reason: LinkNotInSet},
+		{PageID: page1ID, BlockID: "alias", Target: trashedID, Reason: LinkNotAlive},
+		{PageID: page1ID, BlockID: "bad-alias", Target: "xyz", Reason: LinkMalformedID},
+		{PageID: page1ID, BlockID: "missing-block", Target: "/4c6a54c68b3e4ea2af9cfaabcc88d58d#c969c9455d7c4dd79c7f860f3ace6420", Reason: LinkNotInSet},
+		{PageID: page1ID, BlockID: "malformed", Target: "https://www.notion.so/broken", Reason: LinkMalformedID},
+	}
+	assert.Equal(t, exp, got)
+
+	// without page2 links to it are broken
+	got = ValidateLinks([]*Page{page1})
+	assert.Equal(t, 7, len(got))
+}
@@ -0,0 +1,120 @@
+package notionapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PageChange is sent by WatchPage when the page changed or checking it
+// failed
+type PageChange struct {
+	// new version of the page, nil if Err is set
+	Page *Page
+	// blocks that changed since the previous version, in the order of
+	// PageDiff.Changes
+	Changes []*BlockChange
+	// changes since the previous version
+	Diff *PageDiff
+	// Err is set if we failed to check or download the page. The watcher
+	// keeps going and tries again after interval
+	Err error
+}
+
+// WatchPage checks every interval if the page changed and, if it did,
+// downloads it and sends PageChange with the new version and changed
+// blocks. Only the version of the root block is checked, which is cheap.
+// The first version is downloaded when the watch starts and is not sent.
+// If pageID or interval is invalid, PageChange with Err is sent and the
+// channel is closed. Call the returned function to stop watching, which closes the channel
+func (c *Client) WatchPage(pageID string, interval time.Duration) (<-chan PageChange, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan PageChange)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer close(ch)
+		c.watchPage(ctx, pageID, interval, ch)
+	}()
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}
+	return ch, stop
+}
+
+func (c *Client) watchPage(ctx context.Context, pageID string, interval time.Duration, ch chan PageChange) {
+	send := func(change PageChange) bool {
+		// errors caused by stopping the watch are not reported
+		if ctx.Err() != nil {
+			return false
+		}
+		select {
+		case ch <- change:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	normalizedID, err := ToDashID(pageID)
+	if err != nil {
+		send(PageChange{Err: err})
+		return
+	}
+	pageID = normalizedID
+	if interval <= 0 {
+		send(PageChange{Err: fmt.Errorf("interval of watching page %s must be > 0, is %s", pageID, interval)})
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var prev *Page
+	for {
+		changed := prev == nil
+		if prev != nil {
+			versions, err := c.GetBlockVersionsCtx(ctx, []string{pageID})
+			if err == nil {
+				version, ok := versions[pageID]
+				if ok {
+					changed = version != prev.Root.Version
+				} else {
+					err = fmt.Errorf("page %s: %w", pageID, ErrNotFound)
+				}
+			}
+			if err != nil && !send(PageChange{Err: err}) {
+				return
+			}
+		}
+		if changed {
+			page, err := c.DownloadPageCtx(ctx, pageID)
+			if err != nil {
+				if !send(PageChange{Err: err}) {
+					return
+				}
+			} else {
+				if prev != nil {
+					diff := DiffPages(prev, page)
+					change := PageChange{
+						Page:    page,
+						Changes: diff.Changes(),
+						Diff:    diff,
+					}
+					if !send(change) {
+						return
+					}
+				}
+				prev = page
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
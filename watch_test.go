@@ -0,0 +1,141 @@
+package notionapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	watchPageID = "4c6a54c6-8b3e-4ea2-af9c-faabcc88d58d"
+	watchTextID = "00000000-0000-0000-0000-000000000001"
+	watchNewID  = "00000000-0000-0000-0000-000000000002"
+)
+
+// watchTransport serves a page whose content depends on version: version
+// 2 changes the text block and adds a new block
+type watchTransport struct {
+	mu      sync.Mutex
+	version int64
+}
+
+func (t *watchTransport) setVersion(v int64) {
+	t.mu.Lock()
+	t.version = v
+	t.mu.Unlock()
+}
+
+func (t *watchTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	version := t.version
+	t.mu.Unlock()
+	content := []string{watchTextID}
+	if version > 1 {
+		content = append(content, watchNewID)
+	}
+	blocks := map[string]interface{}{
+		watchPageID: map[string]interface{}{
+			"role": RoleReader,
+			"value": map[string]interface{}{
+				"alive": true, "id": watchPageID, "type": BlockPage, "version": version, "content": content,
+			},
+		},
+	}
+	if strings.HasSuffix(r.URL.Path, "/loadPageChunk") {
+		text := map[string]interface{}{
+			"alive": true, "id": watchTextID, "type": BlockText, "version": version,
+		}
+		if version > 1 {
+			text["properties"] = map[string]interface{}{
+				"title": []interface{}{[]interface{}{"changed"}},
+			}
+		}
+		blocks[watchTextID] = map[string]interface{}{
+			"role":  RoleReader,
+			"value": text,
+		}
+		if version > 1 {
+			blocks[watchNewID] = map[string]interface{}{
+				"role": RoleReader,
+				"value": map[string]interface{}{
+					"alive": true, "id": watchNewID, "type": BlockText, "version": 1,
+				},
+			}
+		}
+	}
+	d, _ := json.Marshal(map[string]interface{}{
+		"recordMap": map[string]interface{}{"block": blocks},
+		"cursor":    map[string]interface{}{"stack": []interface{}{}},
+	})
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(bytes.NewReader(d)),
+		Request:    r,
+	}, nil
+}
+
+func TestWatchPage(t *testing.T) {
+	transport := &watchTransport{version: 1}
+	c := &Client{
+		HTTPClient:  &http.Client{Transport: transport},
+		RateLimiter: NewTokenBucket(1000, 100),
+	}
+	ch, stop := c.WatchPage(watchPageID, 5*time.Millisecond)
+	// give the watcher time to download the first version
+	time.Sleep(50 * time.Millisecond)
+	transport.setVersion(2)
+
+	select {
+	case change := <-ch:
+		assert.NoError(t, change.Err)
+		assert.Equal(t, int64(2), change.Page.Root.Version)
+		var types []string
+		for _, c := range change.Changes {
+			types = append(types, c.BlockID+" "+c.Type)
+		}
+		exp := []string{
+			watchTextID + " " + ChangeModified,
+			watchNewID + " " + ChangeAdded,
+		}
+		assert.Equal(t, exp, types)
+		assert.Equal(t, []string{FieldTitle}, change.Diff.Modified[0].Fields)
+	case <-time.After(5 * time.Second):
+		t.Fatal("didn't get a change")
+	}
+
+	stop()
+	_, ok := <-ch
+	assert.False(t, ok)
+	// calling it again is fine
+	stop()
+}
+
+func TestWatchPageInvalidID(t *testing.T) {
+	c := &Client{}
+	ch, stop := c.WatchPage("not-an-id", time.Second)
+	defer stop()
+	change := <-ch
+	assert.Error(t, change.Err)
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func TestWatchPageInvalidInterval(t *testing.T) {
+	c := &Client{}
+	for _, interval := range []time.Duration{0, -time.Second} {
+		ch, stop := c.WatchPage(watchPageID, interval)
+		change := <-ch
+		assert.Error(t, change.Err)
+		_, ok := <-ch
+		assert.False(t, ok)
+		stop()
+	}
+}